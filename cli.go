@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/hnipps/refresharr/internal/arr"
+	"github.com/hnipps/refresharr/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// subcommand is a command that runs with the loaded configuration
+type subcommand struct {
+	use     string
+	short   string
+	aliases []string
+	run     func(ctx context.Context, cfg *config.Config)
+
+	// ownFlags is set for commands that parse the flags after their first argument themselves,
+	// e.g. history stats --since 30d
+	ownFlags bool
+}
+
+// subcommands are the commands that share the global flags, in the order help lists them. The
+// first one runs when no command is given.
+var subcommands = []subcommand{
+	{use: "cleanup", short: "Clean up missing file references in *arr databases (default)", run: runCleanupCommand},
+	{use: "verify", short: "Check files and sizes and report, guaranteed to make no changes", run: runVerifyCommand},
+	{use: "fix-imports", short: "Fix stuck Sonarr imports (already imported issues)", run: runFixImportsCommand},
+	{use: "compare-plex <tmdb-id>", short: "Compare Radarr file status with Plex library availability", run: runComparePlexCommand},
+	{use: "compare-kodi [movie|series] <id>", short: "Compare Radarr/Sonarr file status with the Kodi library", run: runCompareKodiCommand},
+	{use: "serve", short: "Run an HTTP server that queues cleanup runs triggered via API", aliases: []string{"daemon"}, run: runServeCommand},
+	{use: "history list|show <run-id>|stats", short: "Query past runs: history list|show <run-id>|stats [--since 30d]", ownFlags: true,
+		run: func(_ context.Context, cfg *config.Config) { runHistoryCommand(cfg) }},
+	{use: "notify test", short: "Send a sample alert through every configured notifier", run: runNotifyCommand},
+	{use: "init", short: "Set up Sonarr/Radarr interactively and write a .env file", run: runInitCommand},
+	{use: "migrate-paths", short: "Point series/movies at new folders after a storage migration (--path-mappings)", run: runMigratePathsCommand},
+	{use: "export [file]", short: "Write every episode/movie file record to a JSON or CSV snapshot", run: runExportCommand},
+	{use: "compare-snapshot <old.json>", short: "Diff the file records against an earlier export", run: runCompareSnapshotCommand},
+}
+
+// newRootCommand builds the command line: the global flags, which may come before or after the
+// command, and a subcommand per command. Without a command, cleanup runs.
+func newRootCommand(ctx context.Context) *cobra.Command {
+	return buildRootCommand(ctx, subcommands, runConfigCommand)
+}
+
+// buildRootCommand is newRootCommand with the commands and the config command's handler given,
+// so tests can see which command runs with what
+func buildRootCommand(ctx context.Context, commands []subcommand, runConfig func(ctx context.Context, args []string)) *cobra.Command {
+	flags := config.NewFlagSet()
+	root := &cobra.Command{
+		Use:   "refresharr [command]",
+		Short: "RefreshArr - Missing File Cleanup Service",
+		Args:  cobra.NoArgs,
+		Run:   withConfig(ctx, flags, commands[0].run),
+		// The usage lists every flag; an unknown command or flag says where to find it instead
+		SilenceUsage: true,
+	}
+	root.PersistentFlags().AddGoFlagSet(flags)
+
+	for _, sub := range commands {
+		cmd := &cobra.Command{
+			Use:     sub.use,
+			Short:   sub.short,
+			Aliases: sub.aliases,
+			Run:     withConfig(ctx, flags, sub.run),
+		}
+		if sub.ownFlags {
+			cmd.Flags().SetInterspersed(false)
+		}
+		root.AddCommand(cmd)
+	}
+
+	// config runs before the configuration is loaded, so validate can report what's wrong with it
+	root.AddCommand(&cobra.Command{
+		Use:                "config init|validate",
+		Short:              "Write an example .env or check the configuration: config init [path]|validate [--online] [path]",
+		DisableFlagParsing: true,
+		Run: func(_ *cobra.Command, args []string) {
+			runConfig(ctx, args)
+		},
+	})
+
+	// The environment is where most settings come from, so help lists it too
+	defaultHelp := root.HelpFunc()
+	root.SetHelpFunc(func(cmd *cobra.Command, args []string) {
+		defaultHelp(cmd, args)
+		if cmd == root {
+			config.PrintEnvironment(cmd.OutOrStdout())
+		}
+	})
+	return root
+}
+
+// execute runs the command line args and returns the exit code for what cobra rejects: an
+// unknown command, flag or argument. The commands exit themselves otherwise.
+func execute(root *cobra.Command, args []string) int {
+	root.SetArgs(args)
+	if err := root.Execute(); err != nil {
+		return exitConfig
+	}
+	return exitSuccess
+}
+
+// withConfig returns a cobra Run function that loads the configuration from the parsed global
+// flags and the command's arguments, and then runs the command with it
+func withConfig(ctx context.Context, flags *flag.FlagSet, run func(ctx context.Context, cfg *config.Config)) func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		cfg, err := config.LoadConfigFromFlags(flags, args)
+		if err != nil {
+			log.Printf("Failed to load configuration: %v", err)
+			os.Exit(exitConfig)
+		}
+
+		// Handle version flag
+		if cfg.ShowVersion {
+			fmt.Printf("RefreshArr version %s\n", version)
+			fmt.Println("Missing File Cleanup Service for Sonarr and Radarr")
+			os.Exit(0)
+		}
+
+		// Without a command, cleanup runs
+		command := cmd.Name()
+		if cmd == cmd.Root() {
+			command = "cleanup"
+		}
+		if err := checkOutput(command, cfg, cmd.Flags().Changed("output")); err != nil {
+			log.Printf("Failed to load configuration: %v", err)
+			os.Exit(exitConfig)
+		}
+
+		// Identify RefreshArr to the *arr apps
+		if cfg.UserAgent == "" {
+			cfg.UserAgent = "refresharr/" + version
+		}
+		arr.SetUserAgent(cfg.UserAgent)
+		arr.SetHTTPTrace(arr.HTTPTrace{Enabled: cfg.TraceHTTP, Bodies: cfg.TraceHTTPBodies})
+
+		// Write a crash report and exit with exitCrashed on a panic
+		defer recoverCrash(cfg, command)
+
+		run(ctx, cfg)
+	}
+}
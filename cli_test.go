@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hnipps/refresharr/internal/config"
+)
+
+// recordedRun is a command the root command ran, with the configuration or arguments it got
+type recordedRun struct {
+	command string
+	cfg     *config.Config
+	args    []string
+}
+
+// recordingRoot builds the root command with every command recording its run instead of
+// doing anything
+func recordingRoot(t *testing.T, runs *[]recordedRun) func(args ...string) int {
+	t.Helper()
+
+	commands := make([]subcommand, len(subcommands))
+	for i, sub := range subcommands {
+		name := strings.Fields(sub.use)[0]
+		sub.run = func(_ context.Context, cfg *config.Config) {
+			*runs = append(*runs, recordedRun{command: name, cfg: cfg})
+		}
+		commands[i] = sub
+	}
+	runConfig := func(_ context.Context, args []string) {
+		*runs = append(*runs, recordedRun{command: "config", args: args})
+	}
+
+	return func(args ...string) int {
+		root := buildRootCommand(context.Background(), commands, runConfig)
+		root.SetOut(io.Discard)
+		root.SetErr(io.Discard)
+		return execute(root, args)
+	}
+}
+
+func TestRootCommand_GlobalFlagsBeforeAndAfterCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		command string
+	}{
+		{name: "no command", args: []string{"--dry-run", "--service", "radarr"}, command: "cleanup"},
+		{name: "flags before", args: []string{"--dry-run", "--service", "radarr", "verify"}, command: "verify"},
+		{name: "flags after", args: []string{"verify", "--dry-run", "--service", "radarr"}, command: "verify"},
+		{name: "flags around", args: []string{"--dry-run", "fix-imports", "--service", "radarr"}, command: "fix-imports"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var runs []recordedRun
+			if code := recordingRoot(t, &runs)(tt.args...); code != exitSuccess {
+				t.Fatalf("Expected exit code %d, got %d", exitSuccess, code)
+			}
+			if len(runs) != 1 || runs[0].command != tt.command {
+				t.Fatalf("Expected %s to run, got %+v", tt.command, runs)
+			}
+			if cfg := runs[0].cfg; !cfg.DryRun || cfg.Service != "radarr" {
+				t.Errorf("Expected the global flags to apply, got dry run %v and service %q", cfg.DryRun, cfg.Service)
+			}
+		})
+	}
+}
+
+func TestRootCommand_DaemonAlias(t *testing.T) {
+	var runs []recordedRun
+	if code := recordingRoot(t, &runs)("daemon", "--listen", ":9090"); code != exitSuccess {
+		t.Fatalf("Expected exit code %d, got %d", exitSuccess, code)
+	}
+	if len(runs) != 1 || runs[0].command != "serve" || runs[0].cfg.ListenAddr != ":9090" {
+		t.Errorf("Expected daemon to run serve with its flags, got %+v", runs)
+	}
+}
+
+func TestRootCommand_HistoryKeepsItsOwnFlags(t *testing.T) {
+	var runs []recordedRun
+	if code := recordingRoot(t, &runs)("--log-level", "DEBUG", "history", "stats", "--since", "30d"); code != exitSuccess {
+		t.Fatalf("Expected exit code %d, got %d", exitSuccess, code)
+	}
+	if len(runs) != 1 || runs[0].command != "history" {
+		t.Fatalf("Expected history to run, got %+v", runs)
+	}
+	// --since isn't a global flag, so it's left for history to parse
+	if cfg := runs[0].cfg; !reflect.DeepEqual(cfg.Args, []string{"stats", "--since", "30d"}) || cfg.LogLevel != "DEBUG" {
+		t.Errorf("Expected history to get its own flags and the global ones to apply, got args %q and log level %q", cfg.Args, cfg.LogLevel)
+	}
+}
+
+func TestRootCommand_ConfigGetsRawArguments(t *testing.T) {
+	var runs []recordedRun
+	if code := recordingRoot(t, &runs)("config", "validate", "--online", "prod.env"); code != exitSuccess {
+		t.Fatalf("Expected exit code %d, got %d", exitSuccess, code)
+	}
+	// config parses its flags itself, before any configuration is loaded
+	want := []recordedRun{{command: "config", args: []string{"validate", "--online", "prod.env"}}}
+	if !reflect.DeepEqual(runs, want) {
+		t.Errorf("Expected %+v, got %+v", want, runs)
+	}
+}
+
+func TestRootCommand_RejectsUnknownCommandsAndFlags(t *testing.T) {
+	for _, args := range [][]string{
+		{"bogus"},
+		{"--bogus"},
+		{"verify", "--bogus"},
+	} {
+		var runs []recordedRun
+		if code := recordingRoot(t, &runs)(args...); code != exitConfig {
+			t.Errorf("%q: expected exit code %d, got %d", args, exitConfig, code)
+		}
+		if len(runs) != 0 {
+			t.Errorf("%q: expected nothing to run, got %+v", args, runs)
+		}
+	}
+}
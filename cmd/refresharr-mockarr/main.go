@@ -0,0 +1,91 @@
+// Command refresharr-mockarr serves a fixture over an emulated Sonarr or Radarr v3 API, so
+// RefreshArr can be run end to end against realistic request flows without a live instance.
+//
+//	refresharr-mockarr -service sonarr -fixtures ./fixtures -listen :8989 -api-key test
+//
+// The fixtures directory uses the same sonarr.json/radarr.json files as --simulate.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/hnipps/refresharr/internal/arr"
+	"github.com/hnipps/refresharr/internal/mockarr"
+)
+
+func main() {
+	service := flag.String("service", "sonarr", "Service to emulate: sonarr or radarr")
+	fixtures := flag.String("fixtures", ".", "Directory containing sonarr.json or radarr.json")
+	listen := flag.String("listen", "", "Address to listen on (default :8989 for sonarr, :7878 for radarr)")
+	apiKey := flag.String("api-key", "", "API key clients must send (empty accepts any request)")
+	logRequests := flag.Bool("log-requests", false, "Log every request received")
+	flag.Parse()
+
+	if *service != "sonarr" && *service != "radarr" {
+		fmt.Fprintf(os.Stderr, "invalid service %q: must be sonarr or radarr\n", *service)
+		os.Exit(2)
+	}
+
+	addr := *listen
+	if addr == "" {
+		addr = ":8989"
+		if *service == "radarr" {
+			addr = ":7878"
+		}
+	}
+
+	fixture, err := arr.LoadSimulationFixture(*fixtures, *service)
+	if err != nil {
+		log.Fatalf("Failed to load fixture: %v", err)
+	}
+
+	var handler http.Handler = mockarr.NewServer(*service, fixture, *apiKey)
+	if *logRequests {
+		next := handler
+		handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			log.Printf("%s %s", r.Method, r.URL.RequestURI())
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		log.Printf("Emulating %s on %s with fixtures from %s", *service, addr, *fixtures)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+		close(serverErr)
+	}()
+
+	select {
+	case <-ctx.Done():
+	case err := <-serverErr:
+		if err != nil {
+			log.Fatalf("Server failed: %v", err)
+		}
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Shutdown failed: %v", err)
+	}
+}
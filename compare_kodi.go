@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/hnipps/refresharr/internal/arr"
+	"github.com/hnipps/refresharr/internal/config"
+	"github.com/hnipps/refresharr/internal/kodi"
+)
+
+// runCompareKodiCommand handles the compare-kodi command:
+//
+//	compare-kodi [movie] <tmdb-id>   compare a Radarr movie with the Kodi library
+//	compare-kodi series <tvdb-id>    compare a Sonarr series' episodes with the Kodi library
+func runCompareKodiCommand(ctx context.Context, cfg *config.Config) {
+	// Create logger
+	logger := newLogger(cfg)
+	logger.Info("Starting RefreshArr %s - Kodi Comparison Tool", version)
+
+	args := cfg.Args
+	mediaType := "movie"
+	if len(args) > 0 && (args[0] == "movie" || args[0] == "series") {
+		mediaType = args[0]
+		args = args[1:]
+	}
+	if len(args) < 1 {
+		logger.Error("An ID is required as argument")
+		logger.Error("Usage: refresharr compare-kodi [movie] <tmdb-id> | compare-kodi series <tvdb-id>")
+		logger.Error("Example: refresharr compare-kodi 603")
+		os.Exit(exitConfig)
+	}
+
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		logger.Error("Invalid ID '%s': must be a number", args[0])
+		os.Exit(exitConfig)
+	}
+
+	// Validate Kodi configuration
+	if !cfg.Kodi.Configured() {
+		logger.Error("Kodi must be configured to use the compare-kodi command")
+		logger.Error("Please set the KODI_URL environment variable or use --kodi-url")
+		os.Exit(exitConfig)
+	}
+	if err := cfg.Kodi.Validate(); err != nil {
+		logger.Error("Invalid Kodi configuration: %s", err.Error())
+		os.Exit(exitConfig)
+	}
+
+	// Create Kodi client
+	kodiClient := kodi.NewKodiClient(&cfg.Kodi, cfg.RequestTimeout, logger)
+
+	// Test Kodi connection
+	if err := kodiClient.TestConnection(ctx); err != nil {
+		logger.Error("Failed to connect to Kodi: %s", err.Error())
+		os.Exit(exitConnection)
+	}
+
+	if mediaType == "series" {
+		err = compareKodiSeries(ctx, cfg, kodiClient, logger, id)
+	} else {
+		err = compareKodiMovie(ctx, cfg, kodiClient, logger, id)
+	}
+	if err != nil {
+		logger.Error("%s", err.Error())
+		os.Exit(1)
+	}
+}
+
+// compareKodiMovie compares a Radarr movie's file status with the Kodi library
+func compareKodiMovie(ctx context.Context, cfg *config.Config, kodiClient *kodi.KodiClient, logger arr.Logger, tmdbID int) error {
+	if cfg.Radarr.URL == "" || cfg.Radarr.APIKey == "" {
+		return fmt.Errorf("Radarr must be configured to compare movies (set RADARR_URL and RADARR_API_KEY)")
+	}
+
+	radarrClient := newRadarrClient(cfg, &cfg.Radarr, nil, logger)
+	if err := radarrClient.TestConnection(ctx); err != nil {
+		return fmt.Errorf("failed to connect to Radarr: %w", err)
+	}
+
+	// Get movie from Radarr by TMDB ID
+	logger.Info("🔍 Looking up movie with TMDB ID %d in Radarr...", tmdbID)
+	radarrMovie, err := radarrClient.GetMovieByTMDBID(ctx, tmdbID)
+	if err != nil {
+		return fmt.Errorf("❌ Movie with TMDB ID %d does not exist in Radarr", tmdbID)
+	}
+	logger.Info("✅ Found movie in Radarr: %s (%d)", radarrMovie.Title, radarrMovie.Year)
+
+	var radarrFilePath string
+	if radarrMovie.HasFile && radarrMovie.MovieFileID != nil {
+		if movieFile, err := radarrClient.GetMovieFile(ctx, *radarrMovie.MovieFileID); err == nil {
+			radarrFilePath = movieFile.Path
+		} else {
+			logger.Warn("⚠️  Could not get movie file details from Radarr: %s", err.Error())
+		}
+	}
+
+	// Get movie from Kodi
+	logger.Info("🔍 Looking up movie with TMDB ID %d in Kodi...", tmdbID)
+	kodiMovie, err := kodiClient.GetMovieByIDs(ctx, tmdbID, radarrMovie.IMDBID)
+	kodiAvailable := err == nil && kodiMovie.Available
+
+	logger.Info("\n📊 COMPARISON REPORT")
+	logger.Info("==================")
+	logger.Info("Movie: %s (%d)", radarrMovie.Title, radarrMovie.Year)
+	logger.Info("TMDB ID: %d", tmdbID)
+	logger.Info("Radarr Status: %s", getFileStatusText(radarrMovie.HasFile))
+	if err != nil {
+		logger.Info("Kodi Status: Not Found")
+	} else {
+		logger.Info("Kodi Status: %s", getAvailabilityStatusText(kodiAvailable))
+		logger.Info("📄 Kodi file path: %s", kodiMovie.File)
+	}
+
+	if radarrMovie.HasFile == kodiAvailable {
+		logger.Info("Match Status: ✅ MATCH - Both services agree")
+		return nil
+	}
+
+	logger.Info("Match Status: ❌ MISMATCH - Services disagree")
+	if radarrMovie.HasFile {
+		if err != nil {
+			logger.Info("⚠️  Radarr shows file available but movie not found in Kodi")
+			logger.Info("💡 Suggestion: Check that the Kodi source covers this directory and update the library")
+		} else {
+			logger.Info("⚠️  Radarr shows file available but Kodi cannot resolve its file")
+			logger.Info("💡 Suggestion: Update the Kodi library so it picks up the current file")
+		}
+		if radarrFilePath != "" {
+			logger.Info("📄 Check file at: %s", radarrFilePath)
+		}
+	} else {
+		logger.Info("⚠️  Kodi shows movie available but Radarr shows no file")
+		logger.Info("💡 Suggestion: Check if Radarr needs to scan for existing files")
+	}
+	return nil
+}
+
+// compareKodiSeries compares a Sonarr series' episode files with the Kodi library
+func compareKodiSeries(ctx context.Context, cfg *config.Config, kodiClient *kodi.KodiClient, logger arr.Logger, tvdbID int) error {
+	if cfg.Sonarr.URL == "" || cfg.Sonarr.APIKey == "" {
+		return fmt.Errorf("Sonarr must be configured to compare series (set SONARR_URL and SONARR_API_KEY)")
+	}
+
+	sonarrClient := arr.NewSonarrClient(&cfg.Sonarr, cfg.RequestTimeout, logger)
+	if err := sonarrClient.TestConnection(ctx); err != nil {
+		return fmt.Errorf("failed to connect to Sonarr: %w", err)
+	}
+
+	// Get series from Sonarr by TVDB ID
+	logger.Info("🔍 Looking up series with TVDB ID %d in Sonarr...", tvdbID)
+	series, err := sonarrClient.GetSeriesByTVDBID(ctx, tvdbID)
+	if err != nil || series == nil {
+		return fmt.Errorf("❌ Series with TVDB ID %d does not exist in Sonarr", tvdbID)
+	}
+	logger.Info("✅ Found series in Sonarr: %s", series.Title)
+
+	episodes, err := sonarrClient.GetEpisodesForSeries(ctx, series.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get episodes from Sonarr: %w", err)
+	}
+
+	// Get series episodes from Kodi
+	logger.Info("🔍 Looking up series with TVDB ID %d in Kodi...", tvdbID)
+	inKodi := make(map[[2]int]bool)
+	show, err := kodiClient.GetTVShowByTVDBID(ctx, tvdbID)
+	if err != nil {
+		logger.Warn("⚠️  %s", err.Error())
+	} else {
+		kodiEpisodes, err := kodiClient.GetEpisodes(ctx, show.TVShowID)
+		if err != nil {
+			return err
+		}
+		for _, episode := range kodiEpisodes {
+			inKodi[[2]int{episode.Season, episode.Episode}] = true
+		}
+	}
+
+	var withFile, missingInKodi, onlyInKodi int
+	logger.Info("\n📊 COMPARISON REPORT")
+	logger.Info("==================")
+	logger.Info("Series: %s", series.Title)
+	logger.Info("TVDB ID: %d", tvdbID)
+	for _, episode := range episodes {
+		found := inKodi[[2]int{episode.SeasonNumber, episode.EpisodeNumber}]
+		switch {
+		case episode.HasFile:
+			withFile++
+			if !found {
+				missingInKodi++
+				logger.Info("❌ S%02dE%02d %s: Sonarr has a file but Kodi does not list it", episode.SeasonNumber, episode.EpisodeNumber, episode.Title)
+			}
+		case found:
+			onlyInKodi++
+			logger.Info("❌ S%02dE%02d %s: Kodi lists it but Sonarr has no file", episode.SeasonNumber, episode.EpisodeNumber, episode.Title)
+		}
+	}
+
+	logger.Info("Episodes with files in Sonarr: %d", withFile)
+	logger.Info("Missing from Kodi: %d", missingInKodi)
+	logger.Info("In Kodi without a Sonarr file: %d", onlyInKodi)
+	if missingInKodi == 0 && onlyInKodi == 0 {
+		logger.Info("Match Status: ✅ MATCH - Both services agree")
+	} else {
+		logger.Info("Match Status: ❌ MISMATCH - Services disagree")
+		logger.Info("💡 Suggestion: Update the Kodi library, or rescan the series in Sonarr")
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/hnipps/refresharr/internal/arr"
+	"github.com/hnipps/refresharr/internal/config"
+	"github.com/hnipps/refresharr/internal/history"
+	"github.com/hnipps/refresharr/internal/report"
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+// runCompareSnapshotCommand handles the compare-snapshot command:
+//
+//	compare-snapshot <old.json>   diff the current file records against an earlier export
+//
+// Only the records are compared; nothing is checked on disk.
+func runCompareSnapshotCommand(ctx context.Context, cfg *config.Config) {
+	// Create logger
+	logger := newLogger(cfg)
+	logger.Info("Starting RefreshArr %s - Snapshot Comparison", version)
+
+	if len(cfg.Args) < 1 {
+		logger.Error("A snapshot file is required as argument")
+		logger.Error("Usage: refresharr compare-snapshot <snapshot.json>")
+		logger.Error("Example: refresharr compare-snapshot reports/library-snapshot-20240101-120000.json")
+		os.Exit(1)
+	}
+
+	startedAt := time.Now().UTC()
+	earlier, err := report.LoadSnapshot(cfg.Args[0])
+	if err != nil {
+		logger.Error("%s", err.Error())
+		os.Exit(1)
+	}
+
+	// Compare the services the snapshot has that are configured now
+	var services []ServiceInfo
+	for _, service := range determineServices(cfg, logger) {
+		if slices.Contains(earlier.Services, service.Label()) {
+			services = append(services, service)
+		} else {
+			logger.Info("⏭️  Skipping %s: not in the snapshot", service.Label())
+		}
+	}
+	if len(services) == 0 {
+		logger.Error("None of the snapshot's services (%v) are configured", earlier.Services)
+		os.Exit(1)
+	}
+
+	current, err := exportSnapshot(ctx, services, logger)
+	if err != nil {
+		logger.Error("%s", err.Error())
+		os.Exit(1)
+	}
+
+	diff := models.DiffSnapshots(earlier, current)
+	diff.GeneratedAt = time.Now().Format(time.RFC3339)
+	printSnapshotDiff(diff, logger)
+
+	reportPath, err := report.NewGeneratorWithOptions(logger, report.GeneratorOptions{Dir: cfg.ReportDir}).SaveSnapshotDiff(diff)
+	if err != nil {
+		logger.Warn("Failed to save snapshot comparison: %s", err.Error())
+	}
+	outcome := diff.RunResult(history.NewRunID(), startedAt, time.Now().UTC(), reportPath)
+	publishRunOutcome(cfg, "compare-snapshot", []*models.RunResult{outcome}, nil, logger)
+}
+
+// printSnapshotDiff logs what changed since the snapshot
+func printSnapshotDiff(diff *models.SnapshotDiff, logger arr.Logger) {
+	logger.Info("")
+	logger.Info("📊 SNAPSHOT COMPARISON")
+	logger.Info("=====================")
+	logger.Info("Snapshot taken: %s", diff.SnapshotAt)
+	logger.Info("File records compared: %d", diff.Compared)
+
+	if !diff.HasChanges() {
+		logger.Info("✅ No changes since the snapshot")
+		return
+	}
+
+	if len(diff.Disappeared) > 0 {
+		logger.Info("")
+		logger.Info("❌ Disappeared (%d):", len(diff.Disappeared))
+		for _, file := range diff.Disappeared {
+			logger.Info("  %s: %s", snapshotFileName(file), file.Path)
+		}
+	}
+	if len(diff.PathChanged) > 0 {
+		logger.Info("")
+		logger.Info("📁 Path changed (%d):", len(diff.PathChanged))
+		for _, change := range diff.PathChanged {
+			logger.Info("  %s: %s → %s", snapshotFileName(change.Old), change.Old.Path, change.New.Path)
+		}
+	}
+	if len(diff.Added) > 0 {
+		logger.Info("")
+		logger.Info("➕ Added: %d file record(s)", len(diff.Added))
+	}
+}
+
+// snapshotFileName describes the movie or episodes of a snapshot record
+func snapshotFileName(file models.SnapshotFile) string {
+	if file.MediaType != "episode" || len(file.Episodes) == 0 {
+		return file.MediaName
+	}
+	if len(file.Episodes) == 1 {
+		return fmt.Sprintf("%s S%02dE%02d", file.MediaName, file.Season, file.Episodes[0])
+	}
+	return fmt.Sprintf("%s S%02dE%02d-E%02d", file.MediaName, file.Season, file.Episodes[0], file.Episodes[len(file.Episodes)-1])
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hnipps/refresharr/internal/arr"
+	"github.com/hnipps/refresharr/internal/config"
+	"github.com/hnipps/refresharr/internal/kodi"
+	"github.com/hnipps/refresharr/internal/plex"
+	"github.com/hnipps/refresharr/internal/prowlarr"
+	"github.com/joho/godotenv"
+)
+
+// runConfigCommand handles the config command. It runs before the configuration is loaded, as
+// validate has to report what is wrong with it rather than fail on it.
+//
+//	config init [--force] [path]        write a commented example configuration (default .env, - for stdout)
+//	config validate [--online] [path]   check the configuration, optionally loaded from path
+func runConfigCommand(ctx context.Context, args []string) {
+	if err := configCommand(ctx, args, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// configCommand runs a config subcommand and writes the output to w
+func configCommand(ctx context.Context, args []string, w io.Writer) error {
+	usage := fmt.Errorf("usage: refresharr config init [--force] [path] | refresharr config validate [--online] [path]")
+	if len(args) == 0 {
+		return usage
+	}
+
+	fs := flag.NewFlagSet("config "+args[0], flag.ContinueOnError)
+	switch args[0] {
+	case "init":
+		force := fs.Bool("force", false, "Overwrite an existing file")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		path := initEnvFile
+		if fs.NArg() > 0 {
+			path = fs.Arg(0)
+		}
+		return configInit(w, path, *force)
+	case "validate":
+		online := fs.Bool("online", false, "Also connect to every configured service")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		return configValidate(ctx, w, fs.Arg(0), *online)
+	default:
+		return usage
+	}
+}
+
+// configInit writes the example configuration to path, or to w when path is "-"
+func configInit(w io.Writer, path string, force bool) error {
+	if path == "-" {
+		_, err := io.WriteString(w, config.Example)
+		return err
+	}
+	if _, err := os.Stat(path); err == nil && !force {
+		return fmt.Errorf("%s already exists; pass --force to overwrite it or give another path", path)
+	}
+	// The file will hold API keys
+	if err := os.WriteFile(path, []byte(config.Example), 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	fmt.Fprintf(w, "✅ Wrote example configuration to %s\n", path)
+	fmt.Fprintf(w, "Fill in the API keys, then run: refresharr config validate --online\n")
+	fmt.Fprintf(w, "Or have refresharr init ask for them and test the connections\n")
+	return nil
+}
+
+// configValidate checks the configuration of the environment and .env, with the file at path
+// taking precedence when one is given. With online, every configured service is connected to.
+func configValidate(ctx context.Context, w io.Writer, path string, online bool) error {
+	if path != "" {
+		if err := godotenv.Overload(path); err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+	}
+
+	cfg, err := config.LoadEnvConfig()
+	if err != nil {
+		fmt.Fprintf(w, "❌ %s\n", err.Error())
+		return errors.New("the configuration is invalid")
+	}
+
+	problems := append([]string(nil), cfg.Ignored...)
+	if err := cfg.Validate(); err != nil {
+		problems = append(problems, err.Error())
+	}
+	for _, problem := range problems {
+		fmt.Fprintf(w, "❌ %s\n", problem)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("the configuration has %d problem(s)", len(problems))
+	}
+	fmt.Fprintf(w, "✅ Configuration is valid\n")
+
+	if !online {
+		return nil
+	}
+	if failed := checkConnections(ctx, cfg, w); failed > 0 {
+		return fmt.Errorf("%d service(s) could not be reached", failed)
+	}
+	return nil
+}
+
+// connectionCheck is a configured service validate --online connects to
+type connectionCheck struct {
+	name string
+	envs string // Settings to check when the connection fails
+	test func(ctx context.Context) error
+}
+
+// checkConnections connects to every configured service, writes the outcome of each to w and
+// returns how many failed
+func checkConnections(ctx context.Context, cfg *config.Config, w io.Writer) int {
+	// Failures are reported below, with the settings to check
+	logger := arr.NewStandardLogger("ERROR")
+
+	var checks []connectionCheck
+	for _, service := range determineServices(cfg, logger) {
+		prefix := strings.ToUpper(strings.ReplaceAll(service.Label(), "-", "_"))
+		checks = append(checks, connectionCheck{
+			name: service.Label(),
+			envs: prefix + "_URL and " + prefix + "_API_KEY",
+			test: service.Client.TestConnection,
+		})
+	}
+	if cfg.Prowlarr.Configured() {
+		client := prowlarr.NewClient(&cfg.Prowlarr, cfg.RequestTimeout, logger)
+		checks = append(checks, connectionCheck{name: "prowlarr", envs: "PROWLARR_URL and PROWLARR_API_KEY", test: client.TestConnection})
+	}
+	if cfg.Plex.Configured() {
+		client := plex.NewPlexClient(&cfg.Plex, cfg.RequestTimeout, logger)
+		checks = append(checks, connectionCheck{name: "plex", envs: "PLEX_URL and PLEX_TOKEN", test: client.TestConnection})
+	}
+	if cfg.Kodi.Configured() {
+		client := kodi.NewKodiClient(&cfg.Kodi, cfg.RequestTimeout, logger)
+		checks = append(checks, connectionCheck{name: "kodi", envs: "KODI_URL, KODI_USERNAME and KODI_PASSWORD", test: client.TestConnection})
+	}
+
+	failed := 0
+	for _, check := range checks {
+		if err := check.test(ctx); err != nil {
+			fmt.Fprintf(w, "❌ %s: %v (check %s, and that it is running)\n", check.name, err, check.envs)
+			failed++
+			continue
+		}
+		fmt.Fprintf(w, "✅ %s: connected\n", check.name)
+	}
+	return failed
+}
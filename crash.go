@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hnipps/refresharr/internal/arr"
+	"github.com/hnipps/refresharr/internal/config"
+	"github.com/hnipps/refresharr/internal/errtrack"
+	"github.com/hnipps/refresharr/internal/history"
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+// errCrashed is returned by runCleanup when a service's run panicked. The remaining services
+// are skipped, as whatever caused the panic may affect them too.
+var errCrashed = errors.New("run crashed")
+
+// crashReport is written to the crashes directory when a run or command panics
+type crashReport struct {
+	Time    time.Time            `json:"time"`
+	Version string               `json:"version"`
+	Command string               `json:"command"`
+	Service string               `json:"service,omitempty"`
+	RunID   string               `json:"runId,omitempty"`
+	Panic   string               `json:"panic"`
+	Stack   string               `json:"stack"`
+	Stats   *models.CleanupStats `json:"stats,omitempty"` // What the run counted before the panic
+	Config  map[string]string    `json:"config"`          // Settings of the run, without secrets
+}
+
+// crashDir returns where crash reports are kept
+func crashDir(cfg *config.Config) string {
+	return filepath.Join(cfg.StateDir, "crashes")
+}
+
+// writeCrashReport writes a crash report for the panic, and the run it ended if there was one,
+// and returns its path
+func writeCrashReport(cfg *config.Config, command string, run *history.Run, panicErr *arr.PanicError) (string, error) {
+	report := crashReport{
+		Time:    time.Now().UTC(),
+		Version: version,
+		Command: command,
+		Panic:   panicErr.Error(),
+		Stack:   panicErr.Stack,
+		Config:  errtrack.ConfigContext(cfg),
+	}
+	id := history.NewRunID()
+	if run != nil {
+		report.Service = run.Service
+		report.RunID = run.ID
+		report.Stats = &run.Stats
+		id = run.ID
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal crash report: %w", err)
+	}
+	if err := os.MkdirAll(crashDir(cfg), 0755); err != nil {
+		return "", fmt.Errorf("failed to create crash directory: %w", err)
+	}
+	path := filepath.Join(crashDir(cfg), fmt.Sprintf("crash-%s.json", id))
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write crash report: %w", err)
+	}
+	return path, nil
+}
+
+// handleRunCrash records a run's panic: it writes the crash report, notes it on the run and
+// reports it to the error trackers. The returned error wraps errCrashed.
+func handleRunCrash(cfg *config.Config, run *history.Run, panicErr *arr.PanicError, logger arr.Logger) error {
+	path, err := writeCrashReport(cfg, run.Command, run, panicErr)
+	if err != nil {
+		// The stack would be lost otherwise
+		logger.Error("%s\n%s", err.Error(), panicErr.Stack)
+	} else {
+		logger.Error("💥 %s run %s crashed, crash report written to %s", run.Service, run.ID, path)
+		run.CrashReport = path
+	}
+	reportPanic(cfg, run.Command, run, panicErr, logger)
+	return fmt.Errorf("%w: %s %s", errCrashed, run.Service, panicErr.Error())
+}
+
+// recoverCrash is deferred by main to catch panics outside a service's run. It writes a crash
+// report, reports the panic and exits with exitCrashed instead of crashing with a bare stack trace.
+func recoverCrash(cfg *config.Config, command string) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+
+	logger := newLogger(cfg)
+	panicErr := arr.NewPanicError(recovered)
+	if path, err := writeCrashReport(cfg, command, nil, panicErr); err != nil {
+		logger.Error("%s", err.Error())
+	} else {
+		logger.Error("💥 %s crashed, crash report written to %s", command, path)
+	}
+	fmt.Fprintf(os.Stderr, "%s\n\n%s", panicErr.Error(), panicErr.Stack)
+	reportPanic(cfg, command, nil, panicErr, logger)
+	os.Exit(exitCrashed)
+}
+
+// recoverJob is deferred by serve's job runner to fail the job on a panic outside a service's
+// run, rather than take down the server and every queued job with it
+func recoverJob(cfg *config.Config, command string, err *error, logger arr.Logger) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+
+	panicErr := arr.NewPanicError(recovered)
+	if path, writeErr := writeCrashReport(cfg, command, nil, panicErr); writeErr != nil {
+		logger.Error("%s\n%s", writeErr.Error(), panicErr.Stack)
+	} else {
+		logger.Error("💥 %s job crashed, crash report written to %s", command, path)
+	}
+	reportPanic(cfg, command, nil, panicErr, logger)
+	*err = fmt.Errorf("%w: %s", errCrashed, panicErr.Error())
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hnipps/refresharr/internal/arr"
+	"github.com/hnipps/refresharr/internal/config"
+	"github.com/hnipps/refresharr/internal/errtrack"
+	"github.com/hnipps/refresharr/internal/history"
+)
+
+// reportFailures sends the failures of a command to the error trackers: an event for each run
+// that failed outright, or one for the command when it failed before any run could. Partial
+// successes and runs that only had item errors are left to the report and alerts.
+func reportFailures(ctx context.Context, cfg *config.Config, command string, runs []*history.Run, err error, logger arr.Logger) {
+	if err == nil || errors.Is(err, errCompletedWithWarnings) || !cfg.ErrorTracking.Configured() {
+		return
+	}
+
+	var events []*errtrack.Event
+	for _, run := range runs {
+		if run.Error == "" || run.CrashReport != "" {
+			// Panics were reported when they happened
+			continue
+		}
+		event := errtrack.NewEvent(errtrack.LevelError, fmt.Sprintf("%s %s run failed: %s", run.Service, command, run.Error), command, version, cfg)
+		event.Service = run.Service
+		event.RunID = run.ID
+		events = append(events, event)
+	}
+	if len(events) == 0 && len(runs) == 0 {
+		events = append(events, errtrack.NewEvent(errtrack.LevelError, fmt.Sprintf("%s failed: %s", command, err.Error()), command, version, cfg))
+	}
+
+	trackers := errtrack.Trackers(&cfg.ErrorTracking, cfg.RequestTimeout, logger)
+	for _, event := range events {
+		errtrack.CaptureAll(ctx, trackers, event, logger)
+	}
+}
+
+// reportPanic sends a panic to the error trackers, with the run it ended if there was one
+func reportPanic(cfg *config.Config, command string, run *history.Run, panicErr *arr.PanicError, logger arr.Logger) {
+	if !cfg.ErrorTracking.Configured() {
+		return
+	}
+
+	event := errtrack.NewEvent(errtrack.LevelFatal, panicErr.Error(), command, version, cfg)
+	event.Stack = panicErr.Stack
+	if run != nil {
+		event.Service = run.Service
+		event.RunID = run.ID
+	}
+	// The command's context may be why it panicked, so the report gets its own
+	errtrack.CaptureAll(context.Background(), errtrack.Trackers(&cfg.ErrorTracking, cfg.RequestTimeout, logger), event, logger)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/hnipps/refresharr/internal/arr"
+	"github.com/hnipps/refresharr/internal/config"
+	"github.com/hnipps/refresharr/internal/report"
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+// runExportCommand handles the export command:
+//
+//	export [file]   write every episode file and movie file record to file (.json or .csv)
+//
+// Without a file, the snapshot is written to reports/library-snapshot-<timestamp>.json.
+func runExportCommand(ctx context.Context, cfg *config.Config) {
+	// Create logger
+	logger := newLogger(cfg)
+	logger.Info("Starting RefreshArr %s - Library Export", version)
+
+	var path string
+	if len(cfg.Args) > 0 {
+		path = cfg.Args[0]
+	}
+
+	services := determineServices(cfg, logger)
+	if len(services) == 0 {
+		logger.Error("No services configured. Please set SONARR_URL/SONARR_API_KEY, RADARR_URL/RADARR_API_KEY or READARR_URL/READARR_API_KEY")
+		os.Exit(exitConfig)
+	}
+
+	snapshot, err := exportSnapshot(ctx, services, logger)
+	if err != nil {
+		logger.Error("%s", err.Error())
+		os.Exit(1)
+	}
+
+	if _, err := report.NewGeneratorWithOptions(logger, report.GeneratorOptions{Dir: cfg.ReportDir}).SaveSnapshot(snapshot, path); err != nil {
+		logger.Error("%s", err.Error())
+		os.Exit(1)
+	}
+}
+
+// exportSnapshot collects the file records of every service into a snapshot
+func exportSnapshot(ctx context.Context, services []ServiceInfo, logger arr.Logger) (*models.LibrarySnapshot, error) {
+	snapshot := &models.LibrarySnapshot{
+		GeneratedAt: time.Now().Format(time.RFC3339),
+		Files:       []models.SnapshotFile{},
+	}
+
+	for _, service := range services {
+		if err := service.Client.TestConnection(ctx); err != nil {
+			return nil, err
+		}
+
+		logger.Info("📦 Exporting %s file records...", service.Label())
+		files, err := arr.ExportFiles(ctx, service.Client, logger)
+		if err != nil {
+			return nil, err
+		}
+		// Records of extra instances are told apart from the default one's by their label
+		for i := range files {
+			files[i].Service = service.Label()
+		}
+		logger.Info("✅ Exported %d %s file record(s)", len(files), service.Label())
+
+		snapshot.Services = append(snapshot.Services, service.Label())
+		snapshot.Files = append(snapshot.Files, files...)
+	}
+	snapshot.TotalFiles = len(snapshot.Files)
+
+	return snapshot, nil
+}
@@ -4,7 +4,12 @@ go 1.24.5
 
 require (
 	github.com/joho/godotenv v1.5.1
+	github.com/spf13/cobra v1.10.2
 	golift.io/starr v1.2.1-0.20250830065754-91cade991fa0
 )
 
-require golang.org/x/net v0.37.0 // indirect
+require (
+	github.com/inconshreveable/mousetrap v1.1.0 // indirect
+	github.com/spf13/pflag v1.0.9 // indirect
+	golang.org/x/net v0.37.0 // indirect
+)
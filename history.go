@@ -0,0 +1,295 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/hnipps/refresharr/internal/config"
+	"github.com/hnipps/refresharr/internal/history"
+)
+
+// runHistoryCommand handles the history command: list, show <run-id> and stats
+func runHistoryCommand(cfg *config.Config) {
+	store := history.NewStoreWithLogger(cfg.StateDir, newLogger(cfg))
+	if err := historyCommand(cfg.Args, store, os.Stdout, time.Now()); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// historyCommand runs a history subcommand against the store and writes the output to w
+func historyCommand(args []string, store *history.Store, w io.Writer, now time.Time) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: refresharr history list|show <run-id>|stats [--since 30d] [--service sonarr|radarr]")
+	}
+
+	subcommand := args[0]
+	fs := flag.NewFlagSet("history "+subcommand, flag.ContinueOnError)
+	fs.SetOutput(w)
+	since := fs.String("since", "", "Only include runs started within this window (e.g. 12h, 30d, 2w)")
+	service := fs.String("service", "", "Only include runs for this service")
+	limit := fs.Int("limit", 20, "Maximum number of runs to list (0 for all)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	switch subcommand {
+	case "list":
+		runs, err := loadRuns(store, *since, *service, now)
+		if err != nil {
+			return err
+		}
+		printRunList(w, runs, *limit)
+		return nil
+
+	case "show":
+		if fs.NArg() < 1 {
+			return fmt.Errorf("usage: refresharr history show <run-id>")
+		}
+		run, err := store.Get(fs.Arg(0))
+		if errors.Is(err, history.ErrRunNotFound) {
+			return fmt.Errorf("no run with ID %s in %s", fs.Arg(0), store.Path())
+		}
+		if err != nil {
+			return err
+		}
+		previous, err := store.Previous(run)
+		if err != nil {
+			return err
+		}
+		// Runs that spilled their missing files recorded none of them; their reports have them all
+		printRun(w, history.WithReportedMissingFiles(run), history.WithReportedMissingFiles(previous))
+		return nil
+
+	case "stats":
+		runs, err := loadRuns(store, *since, *service, now)
+		if err != nil {
+			return err
+		}
+		printRunStats(w, runs, *since)
+		return nil
+
+	default:
+		return fmt.Errorf("unknown history subcommand %q (expected list, show or stats)", subcommand)
+	}
+}
+
+// loadRuns reads runs from the store, applying the --since and --service filters
+func loadRuns(store *history.Store, since, service string, now time.Time) ([]history.Run, error) {
+	runs, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	if since != "" {
+		window, err := history.ParseSince(since)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --since value: %w", err)
+		}
+		runs = history.Since(runs, now.Add(-window))
+	}
+
+	if service != "" {
+		filtered := runs[:0]
+		for _, run := range runs {
+			if run.Service == service {
+				filtered = append(filtered, run)
+			}
+		}
+		runs = filtered
+	}
+
+	return runs, nil
+}
+
+// printRunList prints runs newest first as a table
+func printRunList(w io.Writer, runs []history.Run, limit int) {
+	if len(runs) == 0 {
+		fmt.Fprintln(w, "No runs recorded.")
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "RUN ID\tSTARTED\tSERVICE\tMODE\tSTATUS\tCHECKED\tMISSING\tDELETED\tERRORS\tDURATION")
+
+	printed := 0
+	for i := len(runs) - 1; i >= 0; i-- {
+		if limit > 0 && printed >= limit {
+			break
+		}
+		run := runs[i]
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%d\t%d\t%d\t%d\t%s\n",
+			run.ID,
+			run.StartedAt.Local().Format("2006-01-02 15:04"),
+			run.Service,
+			runMode(run),
+			runStatus(run),
+			run.Stats.TotalItemsChecked,
+			run.Stats.MissingFiles,
+			run.Stats.DeletedRecords,
+			run.Stats.Errors,
+			run.Duration().Round(time.Second),
+		)
+		printed++
+	}
+	tw.Flush()
+
+	if printed < len(runs) {
+		fmt.Fprintf(w, "\nShowing %d of %d runs (use --limit 0 to show all)\n", printed, len(runs))
+	}
+}
+
+// printRun prints the details of a single run and its missing file delta against the previous run
+func printRun(w io.Writer, run *history.Run, previous *history.Run) {
+	fmt.Fprintf(w, "Run:       %s\n", run.ID)
+	fmt.Fprintf(w, "Command:   %s\n", run.Command)
+	fmt.Fprintf(w, "Service:   %s\n", run.Service)
+	fmt.Fprintf(w, "Mode:      %s\n", runMode(*run))
+	fmt.Fprintf(w, "Status:    %s\n", runStatus(*run))
+	if run.Error != "" {
+		fmt.Fprintf(w, "Error:     %s\n", run.Error)
+	}
+	fmt.Fprintf(w, "Started:   %s\n", run.StartedAt.Local().Format(time.RFC3339))
+	fmt.Fprintf(w, "Duration:  %s\n", run.Duration().Round(time.Second))
+	if run.ReportPath != "" {
+		fmt.Fprintf(w, "Report:    %s\n", run.ReportPath)
+	}
+
+	fmt.Fprintf(w, "\nStats:\n")
+	fmt.Fprintf(w, "  Items checked:   %d\n", run.Stats.TotalItemsChecked)
+	fmt.Fprintf(w, "  Missing files:   %d\n", run.Stats.MissingFiles)
+	fmt.Fprintf(w, "  Records deleted: %d\n", run.Stats.DeletedRecords)
+	fmt.Fprintf(w, "  Errors:          %d\n", run.Stats.Errors)
+
+	// Runs recorded before timings were tracked have no phase data
+	if run.Stats.Duration > 0 {
+		phases := run.Stats.Phases
+		fmt.Fprintf(w, "\nTiming:\n")
+		fmt.Fprintf(w, "  Fetch:           %s\n", phases.Fetch.Round(time.Millisecond))
+		fmt.Fprintf(w, "  Symlink scan:    %s\n", phases.SymlinkScan.Round(time.Millisecond))
+		fmt.Fprintf(w, "  Verification:    %s\n", phases.Verification.Round(time.Millisecond))
+		fmt.Fprintf(w, "  Deletion:        %s\n", phases.Deletion.Round(time.Millisecond))
+		fmt.Fprintf(w, "  Refresh:         %s\n", phases.Refresh.Round(time.Millisecond))
+		fmt.Fprintf(w, "  API calls:       %d\n", run.Stats.APICalls)
+	}
+
+	if len(run.Recovered) > 0 {
+		fmt.Fprintf(w, "\nRecovered since earlier runs: %d\n", len(run.Recovered))
+		for _, entry := range run.Recovered {
+			fmt.Fprintf(w, "    ✓ %s (missing since %s)\n", entry.FilePath, entry.MissingSince)
+		}
+	}
+
+	if run.Truncated() && len(run.MissingFiles) == 0 {
+		fmt.Fprintf(w, "\nMissing files were not recorded in history for this run; see the report.\n")
+		return
+	}
+	if run.Truncated() {
+		fmt.Fprintf(w, "\nOnly the first %d of %d missing files were recorded in history; see the report for the rest.\n", len(run.MissingFiles), run.Stats.MissingFiles)
+	}
+
+	delta := history.ComputeDelta(previous, run)
+	if delta.Incomplete {
+		fmt.Fprintf(w, "\nChanges since run %s can't be listed: only part of the missing files of one of the runs were recorded.\n", previous.ID)
+		return
+	}
+	if previous != nil {
+		fmt.Fprintf(w, "\nChanges since run %s:\n", previous.ID)
+	} else {
+		fmt.Fprintf(w, "\nNo previous %s run to compare against.\n", run.Service)
+	}
+	fmt.Fprintf(w, "  New missing files: %d\n", len(delta.New))
+	for _, entry := range delta.New {
+		fmt.Fprintf(w, "    + %s\n", entry.FilePath)
+	}
+	if previous != nil {
+		fmt.Fprintf(w, "  Resolved:          %d\n", len(delta.Resolved))
+		for _, entry := range delta.Resolved {
+			fmt.Fprintf(w, "    - %s\n", entry.FilePath)
+		}
+	}
+}
+
+// printRunStats prints aggregated statistics per service
+func printRunStats(w io.Writer, runs []history.Run, since string) {
+	if len(runs) == 0 {
+		fmt.Fprintln(w, "No runs recorded.")
+		return
+	}
+
+	if since != "" {
+		fmt.Fprintf(w, "Runs in the last %s: %d\n\n", since, len(runs))
+	} else {
+		fmt.Fprintf(w, "Runs: %d\n\n", len(runs))
+	}
+
+	summaries := history.Summarize(runs)
+	services := make([]string, 0, len(summaries))
+	for service := range summaries {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SERVICE\tRUNS\tOK\tFAILED\tDRY-RUNS\tMISSING\tDELETED\tERRORS\tAVG DURATION\tRECOVERED\tMTTR")
+	for _, service := range services {
+		summary := summaries[service]
+		avg := summary.TotalDuration / time.Duration(summary.Runs)
+		mttr := "-"
+		if summary.Recovered > 0 {
+			mttr = summary.MeanTimeToRecovery().Round(time.Minute).String()
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%s\t%d\t%s\n",
+			service,
+			summary.Runs,
+			summary.Successful,
+			summary.Failed,
+			summary.DryRuns,
+			summary.MissingFiles,
+			summary.DeletedRecords,
+			summary.Errors,
+			avg.Round(time.Second),
+			summary.Recovered,
+			mttr,
+		)
+	}
+	tw.Flush()
+
+	// Missing file trend per service: first vs latest run in the window
+	fmt.Fprintln(w)
+	for _, service := range services {
+		var first, last *history.Run
+		for i := range runs {
+			if runs[i].Service != service {
+				continue
+			}
+			if first == nil {
+				first = &runs[i]
+			}
+			last = &runs[i]
+		}
+		fmt.Fprintf(w, "%s missing files: %d -> %d\n", service, first.Stats.MissingFiles, last.Stats.MissingFiles)
+	}
+}
+
+// runMode returns a human-readable run mode
+func runMode(run history.Run) string {
+	if run.DryRun {
+		return "dry-run"
+	}
+	return "real-run"
+}
+
+// runStatus returns a human-readable run status
+func runStatus(run history.Run) string {
+	if run.Success {
+		return "success"
+	}
+	return "failed"
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/hnipps/refresharr/internal/arr"
+	"github.com/hnipps/refresharr/internal/config"
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+// initEnvFile is where init writes the configuration; it's loaded from the working directory on every run
+const initEnvFile = ".env"
+
+// runInitCommand handles the init command: an interactive first-run setup
+func runInitCommand(ctx context.Context, cfg *config.Config) {
+	// Client errors are shown by the prompts, so only log what goes wrong beyond them
+	logger := arr.NewStandardLogger("ERROR")
+	connect := func(service, url, apiKey string) arr.Client {
+		if service == "sonarr" {
+			return arr.NewSonarrClient(&config.SonarrConfig{URL: url, APIKey: apiKey}, cfg.RequestTimeout, logger)
+		}
+		return newRadarrClient(cfg, &config.RadarrConfig{URL: url, APIKey: apiKey}, nil, logger)
+	}
+
+	if err := initCommand(ctx, cfg, os.Stdin, os.Stdout, initEnvFile, connect); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// initService describes a service init asks about
+type initService struct {
+	name       string // sonarr or radarr
+	title      string
+	urlEnv     string
+	keyEnv     string
+	defaultURL string
+	currentURL string
+	currentKey string
+}
+
+// initCommand prompts for the Sonarr and Radarr URLs and API keys, tests each connection, lets
+// the user pick the quality profile for added movies and series, and writes the answers to path.
+// The current configuration, from the environment or an earlier init, gives the defaults. An
+// existing file is updated in place, so settings init doesn't ask about are kept.
+func initCommand(ctx context.Context, cfg *config.Config, in io.Reader, w io.Writer, path string, connect func(service, url, apiKey string) arr.Client) error {
+	p := &prompter{in: bufio.NewReader(in), w: w}
+
+	fmt.Fprintf(w, "RefreshArr setup\n")
+	fmt.Fprintf(w, "Press Enter to keep the value in brackets.\n\n")
+
+	if _, err := os.Stat(path); err == nil {
+		fmt.Fprintf(w, "%s already exists: the settings below are updated in it, and the others kept.\n\n", path)
+	}
+
+	services := []initService{
+		{name: "sonarr", title: "Sonarr", urlEnv: "SONARR_URL", keyEnv: "SONARR_API_KEY", defaultURL: "http://127.0.0.1:8989", currentURL: cfg.Sonarr.URL, currentKey: cfg.Sonarr.APIKey},
+		{name: "radarr", title: "Radarr", urlEnv: "RADARR_URL", keyEnv: "RADARR_API_KEY", defaultURL: "http://127.0.0.1:7878", currentURL: cfg.Radarr.URL, currentKey: cfg.Radarr.APIKey},
+	}
+
+	var env [][2]string
+	var connected []arr.Client
+	for _, service := range services {
+		url, key, client, err := p.askService(ctx, service, connect)
+		if err != nil {
+			return err
+		}
+		if key == "" {
+			continue
+		}
+		env = append(env, [2]string{service.urlEnv, url}, [2]string{service.keyEnv, key})
+		if client != nil {
+			connected = append(connected, client)
+		}
+	}
+	if len(env) == 0 {
+		return fmt.Errorf("no service configured: RefreshArr needs Sonarr or Radarr")
+	}
+
+	profileID, err := p.askQualityProfile(ctx, connected, cfg.QualityProfileID)
+	if err != nil {
+		return err
+	}
+	if profileID > 0 {
+		env = append(env, [2]string{"QUALITY_PROFILE_ID", strconv.Itoa(profileID)})
+	}
+
+	if err := writeEnvFile(path, env); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "\n✅ Configuration written to %s\n", path)
+	fmt.Fprintf(w, "Try it with: refresharr --dry-run\n")
+	return nil
+}
+
+// askService prompts for a service's URL and API key and tests the connection. It returns an
+// empty key when the service is skipped, and a nil client when the connection failed but the
+// settings are kept anyway.
+func (p *prompter) askService(ctx context.Context, service initService, connect func(service, url, apiKey string) arr.Client) (string, string, arr.Client, error) {
+	fmt.Fprintf(p.w, "%s\n", service.title)
+	if service.currentKey == "" {
+		fmt.Fprintf(p.w, "  Leave the API key blank to skip %s\n", service.title)
+	}
+	for {
+		defaultURL := service.currentURL
+		if defaultURL == "" {
+			defaultURL = service.defaultURL
+		}
+		url, err := p.ask("  URL", defaultURL)
+		if err != nil {
+			return "", "", nil, err
+		}
+		key, err := p.askSecret(fmt.Sprintf("  API key (%s Settings > General)", service.title), service.currentKey)
+		if err != nil {
+			return "", "", nil, err
+		}
+		if key == "" {
+			fmt.Fprintf(p.w, "  Skipping %s\n\n", service.title)
+			return "", "", nil, nil
+		}
+
+		client := connect(service.name, url, key)
+		err = client.TestConnection(ctx)
+		if err == nil {
+			fmt.Fprintf(p.w, "  ✅ Connected to %s\n\n", service.title)
+			return url, key, client, nil
+		}
+		fmt.Fprintf(p.w, "  ❌ Could not connect to %s: %v\n", service.title, err)
+
+		retry, err := p.confirm("  Try again?", true)
+		if err != nil {
+			return "", "", nil, err
+		}
+		if retry {
+			service.currentURL, service.currentKey = url, key
+			continue
+		}
+		keep, err := p.confirm(fmt.Sprintf("  Save the %s settings anyway?", service.title), false)
+		if err != nil {
+			return "", "", nil, err
+		}
+		fmt.Fprintln(p.w)
+		if !keep {
+			return "", "", nil, nil
+		}
+		return url, key, nil, nil
+	}
+}
+
+// askQualityProfile lists the quality profiles of the connected services, Radarr's first as
+// QUALITY_PROFILE_ID mostly applies to added movies, and asks which one new items get. It
+// returns 0 when no profiles could be fetched.
+func (p *prompter) askQualityProfile(ctx context.Context, clients []arr.Client, current int) (int, error) {
+	var profiles []models.QualityProfile
+	var service string
+	for i := len(clients) - 1; i >= 0 && len(profiles) == 0; i-- {
+		fetched, err := clients[i].GetQualityProfiles(ctx)
+		if err != nil {
+			fmt.Fprintf(p.w, "⚠️  Could not fetch %s quality profiles: %v\n", clients[i].GetName(), err)
+			continue
+		}
+		profiles, service = fetched, clients[i].GetName()
+	}
+	if len(profiles) == 0 {
+		return 0, nil
+	}
+
+	fmt.Fprintf(p.w, "Quality profiles in %s:\n", service)
+	defaultID := profiles[0].ID
+	for _, profile := range profiles {
+		fmt.Fprintf(p.w, "  %d) %s\n", profile.ID, profile.Name)
+		if profile.ID == current {
+			defaultID = current
+		}
+	}
+
+	for {
+		answer, err := p.ask("Quality profile for movies and series added from broken symlinks", strconv.Itoa(defaultID))
+		if err != nil {
+			return 0, err
+		}
+		id, err := strconv.Atoi(answer)
+		if err == nil {
+			for _, profile := range profiles {
+				if profile.ID == id {
+					return id, nil
+				}
+			}
+		}
+		fmt.Fprintf(p.w, "  Enter one of the profile IDs above\n")
+	}
+}
+
+// writeEnvFile writes the settings as KEY=value lines. A new file starts out as the example
+// configuration `config init` writes. The lines of the settings are replaced where they are and
+// the rest of the file, comments included, is kept; settings it doesn't set yet replace their
+// commented-out line, or are added at the end. The file holds API keys, so a new one is
+// readable only by its owner.
+func writeEnvFile(path string, env [][2]string) error {
+	existing, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		existing = []byte(config.Example)
+	case err != nil:
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(existing), "\n"), "\n")
+
+	values := make(map[string]string, len(env))
+	for _, kv := range env {
+		values[kv[0]] = fmt.Sprintf("%s=%s", kv[0], strconv.Quote(kv[1]))
+	}
+	for i, line := range lines {
+		key, ok := envLineKey(line)
+		if !ok {
+			continue
+		}
+		if value, ok := values[key]; ok {
+			lines[i] = value
+			delete(values, key)
+		}
+	}
+	for i, line := range lines {
+		commented, ok := strings.CutPrefix(strings.TrimSpace(line), "#")
+		if !ok {
+			continue
+		}
+		key, ok := envLineKey(commented)
+		if !ok {
+			continue
+		}
+		if value, ok := values[key]; ok {
+			lines[i] = value
+			delete(values, key)
+		}
+	}
+	for _, kv := range env {
+		if value, ok := values[kv[0]]; ok {
+			lines = append(lines, value)
+		}
+	}
+
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// envLineKey returns the key a .env line sets, allowing for an export prefix. Comments, blank
+// lines and lines that don't start with a variable name set none.
+func envLineKey(line string) (string, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", false
+	}
+	line = strings.TrimPrefix(line, "export ")
+	key, _, ok := strings.Cut(line, "=")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" || strings.TrimFunc(key, isEnvNameRune) != "" {
+		return "", false
+	}
+	return key, true
+}
+
+// isEnvNameRune reports whether r may appear in an environment variable name
+func isEnvNameRune(r rune) bool {
+	return r == '_' || (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9')
+}
+
+// prompter asks questions on w and reads the answers from in
+type prompter struct {
+	in *bufio.Reader
+	w  io.Writer
+}
+
+// ask prints the question with its default and returns the answer, or the default for an empty one
+func (p *prompter) ask(question, defaultValue string) (string, error) {
+	if defaultValue != "" {
+		fmt.Fprintf(p.w, "%s [%s]: ", question, defaultValue)
+	} else {
+		fmt.Fprintf(p.w, "%s: ", question)
+	}
+	return p.answer(defaultValue)
+}
+
+// askSecret is ask for API keys: a current value isn't echoed back
+func (p *prompter) askSecret(question, current string) (string, error) {
+	if current != "" {
+		fmt.Fprintf(p.w, "%s [keep current]: ", question)
+	} else {
+		fmt.Fprintf(p.w, "%s: ", question)
+	}
+	return p.answer(current)
+}
+
+// confirm asks a yes/no question
+func (p *prompter) confirm(question string, defaultYes bool) (bool, error) {
+	options, defaultValue := "y/N", "n"
+	if defaultYes {
+		options, defaultValue = "Y/n", "y"
+	}
+	fmt.Fprintf(p.w, "%s [%s]: ", question, options)
+	answer, err := p.answer(defaultValue)
+	if err != nil {
+		return false, err
+	}
+	return strings.HasPrefix(strings.ToLower(answer), "y"), nil
+}
+
+// answer reads a line, returning defaultValue when it's empty
+func (p *prompter) answer(defaultValue string) (string, error) {
+	line, err := p.in.ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		if errors.Is(err, io.EOF) {
+			return "", fmt.Errorf("setup cancelled")
+		}
+		return "", fmt.Errorf("failed to read answer: %w", err)
+	}
+	if line = strings.TrimSpace(line); line != "" {
+		return line, nil
+	}
+	return defaultValue, nil
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hnipps/refresharr/internal/arr"
+	"github.com/hnipps/refresharr/internal/config"
+	"github.com/hnipps/refresharr/pkg/models"
+	"github.com/joho/godotenv"
+)
+
+// unreachableClient is a client whose connection test fails
+type unreachableClient struct {
+	arr.Client
+}
+
+func (c unreachableClient) TestConnection(ctx context.Context) error {
+	return errors.New("connection refused")
+}
+
+// initConnector returns a connect function for initCommand that serves simulated instances and
+// fails the connection for the URLs in unreachable. The URLs it was called with are recorded.
+func initConnector(unreachable map[string]bool, calls *[]string) func(service, url, apiKey string) arr.Client {
+	logger := arr.NewSlogLogger(slog.DiscardHandler, arr.LoggerOptions{})
+	return func(service, url, apiKey string) arr.Client {
+		*calls = append(*calls, service+" "+url+" "+apiKey)
+		fixture := arr.SimulationFixture{}
+		if service == "radarr" {
+			fixture.QualityProfiles = []models.QualityProfile{{ID: 1, Name: "Any"}, {ID: 4, Name: "HD-1080p"}}
+		}
+		client := arr.NewSimulatedClient(service, fixture, 0, logger)
+		if unreachable[url] {
+			return unreachableClient{client}
+		}
+		return client
+	}
+}
+
+func TestInitCommand_WritesAnswers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	var calls []string
+	// Default Sonarr URL, Radarr at another host, then the second quality profile
+	in := strings.NewReader("\nsonarr-key\nhttp://radarr:7878\nradarr-key\n4\n")
+	var out bytes.Buffer
+
+	if err := initCommand(context.Background(), &config.Config{}, in, &out, path, initConnector(nil, &calls)); err != nil {
+		t.Fatalf("initCommand() failed: %v\n%s", err, out.String())
+	}
+
+	wantCalls := []string{"sonarr http://127.0.0.1:8989 sonarr-key", "radarr http://radarr:7878 radarr-key"}
+	if strings.Join(calls, "\n") != strings.Join(wantCalls, "\n") {
+		t.Errorf("Expected connections %q, got %q", wantCalls, calls)
+	}
+	for _, want := range []string{"✅ Connected to Sonarr", "✅ Connected to Radarr", "Quality profiles in radarr:", "4) HD-1080p", "Configuration written to " + path} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected the output to contain %q, got:\n%s", want, out.String())
+		}
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	// The example configuration, with the answers in place of its service settings
+	want := strings.NewReplacer(
+		"SONARR_URL=http://127.0.0.1:8989\n", `SONARR_URL="http://127.0.0.1:8989"`+"\n",
+		"SONARR_API_KEY=\n", `SONARR_API_KEY="sonarr-key"`+"\n",
+		"RADARR_URL=http://127.0.0.1:7878\n", `RADARR_URL="http://radarr:7878"`+"\n",
+		"RADARR_API_KEY=\n", `RADARR_API_KEY="radarr-key"`+"\n",
+	).Replace(config.Example) + `QUALITY_PROFILE_ID="4"` + "\n"
+	if string(content) != want {
+		t.Errorf("Unexpected file:\n%s\nwant:\n%s", content, want)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected the file to be readable only by its owner, got %v, %v", info.Mode(), err)
+	}
+}
+
+func TestInitCommand_RetriesFailedConnection(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	var calls []string
+	unreachable := map[string]bool{"http://sonarr:8989": true}
+	// A mistyped Sonarr host, retried with the right one and the same key; Radarr skipped
+	in := strings.NewReader("http://sonarr:8989\nsonarr-key\ny\nhttp://sonarr.lan:8989\n\n\n\n")
+	var out bytes.Buffer
+
+	if err := initCommand(context.Background(), &config.Config{}, in, &out, path, initConnector(unreachable, &calls)); err != nil {
+		t.Fatalf("initCommand() failed: %v\n%s", err, out.String())
+	}
+
+	wantCalls := []string{"sonarr http://sonarr:8989 sonarr-key", "sonarr http://sonarr.lan:8989 sonarr-key"}
+	if strings.Join(calls, "\n") != strings.Join(wantCalls, "\n") {
+		t.Errorf("Expected connections %q, got %q", wantCalls, calls)
+	}
+	for _, want := range []string{"❌ Could not connect to Sonarr: connection refused", "Try again? [Y/n]", "API key (Sonarr Settings > General) [keep current]", "Skipping Radarr"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected the output to contain %q, got:\n%s", want, out.String())
+		}
+	}
+
+	// Sonarr has no quality profiles, so none is asked for
+	env, err := godotenv.Read(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	if env["SONARR_URL"] != "http://sonarr.lan:8989" || env["SONARR_API_KEY"] != "sonarr-key" || env["RADARR_API_KEY"] != "" || env["QUALITY_PROFILE_ID"] != "" {
+		t.Errorf("Unexpected settings: %v", env)
+	}
+}
+
+func TestInitCommand_KeepsUnreachableSettingsOnRequest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	var calls []string
+	unreachable := map[string]bool{"http://127.0.0.1:7878": true}
+	// Sonarr skipped; Radarr unreachable, not retried, but kept
+	in := strings.NewReader("\n\n\nradarr-key\nn\ny\n")
+	var out bytes.Buffer
+
+	if err := initCommand(context.Background(), &config.Config{}, in, &out, path, initConnector(unreachable, &calls)); err != nil {
+		t.Fatalf("initCommand() failed: %v\n%s", err, out.String())
+	}
+	env, err := godotenv.Read(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	if env["RADARR_API_KEY"] != "radarr-key" || env["SONARR_API_KEY"] != "" {
+		t.Errorf("Unexpected settings: %v", env)
+	}
+}
+
+func TestInitCommand_NoServiceConfigured(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	var calls []string
+	var out bytes.Buffer
+
+	err := initCommand(context.Background(), &config.Config{}, strings.NewReader("\n\n\n\n"), &out, path, initConnector(nil, &calls))
+	if err == nil || !strings.Contains(err.Error(), "no service configured") {
+		t.Errorf("Expected an error for skipping every service, got %v", err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected nothing written, got %v", err)
+	}
+}
+
+func TestInitCommand_UpdatesExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	existing := `# My settings
+export SONARR_URL=http://old:8989
+SONARR_API_KEY=old-key
+# RADARR_URL=http://127.0.0.1:7878
+DRY_RUN=true
+
+# Tuning
+CONCURRENT_LIMIT=2
+`
+	if err := os.WriteFile(path, []byte(existing), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+	cfg := &config.Config{Sonarr: config.SonarrConfig{URL: "http://old:8989", APIKey: "old-key"}}
+	var calls []string
+	// Sonarr moved to a new host with the current key; Radarr added; the default profile taken
+	in := strings.NewReader("http://new:8989\n\n\nradarr-key\n\n")
+	var out bytes.Buffer
+
+	if err := initCommand(context.Background(), cfg, in, &out, path, initConnector(nil, &calls)); err != nil {
+		t.Fatalf("initCommand() failed: %v\n%s", err, out.String())
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	// Radarr's URL takes the place of its commented-out line
+	want := `# My settings
+SONARR_URL="http://new:8989"
+SONARR_API_KEY="old-key"
+RADARR_URL="http://127.0.0.1:7878"
+DRY_RUN=true
+
+# Tuning
+CONCURRENT_LIMIT=2
+RADARR_API_KEY="radarr-key"
+QUALITY_PROFILE_ID="1"
+`
+	if string(content) != want {
+		t.Errorf("Unexpected file:\n%s\nwant:\n%s", content, want)
+	}
+}
@@ -0,0 +1,76 @@
+package arr
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// validateAddSettings checks, before a run that adds movies or series from broken symlinks does
+// any work, that the quality profile new items get exists and that the service has a root folder
+// it can reach. Otherwise every add would fail on its own halfway through the run. Dry runs
+// don't add anything, so they only check each planned add when add validation is on.
+func (s *CleanupServiceImpl) validateAddSettings(ctx context.Context) error {
+	if !s.addMissingMovies || s.targeted || s.reportOnly() {
+		return nil
+	}
+	name := s.client.GetName()
+	if name != "sonarr" && name != "radarr" {
+		return nil
+	}
+
+	s.addSettingsOnce.Do(func() {
+		s.addSettingsErr = s.checkAddSettings(ctx, name)
+		if s.addSettingsErr == nil {
+			s.logger.Debug("Quality profile %d and root folders are valid for adding items", s.qualityProfileID)
+		}
+	})
+	return s.addSettingsErr
+}
+
+// checkAddSettings returns why adding items to the service would fail, or nil
+func (s *CleanupServiceImpl) checkAddSettings(ctx context.Context, name string) error {
+	s.loadQualityProfiles(ctx)
+	if s.profilesErr != nil {
+		return fmt.Errorf("failed to get quality profiles: %w", s.profilesErr)
+	}
+	if _, ok := s.qualityProfiles[s.qualityProfileID]; !ok {
+		return fmt.Errorf("quality profile %d does not exist in %s (available: %s); set QUALITY_PROFILE_ID or disable ADD_MISSING_MOVIES",
+			s.qualityProfileID, name, profileList(s.qualityProfiles))
+	}
+
+	rootFolders, err := s.client.GetRootFolders(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get root folders: %w", err)
+	}
+	if len(rootFolders) == 0 {
+		return fmt.Errorf("no root folders are configured in %s, so missing items can't be added", name)
+	}
+	var unreachable []string
+	for _, folder := range rootFolders {
+		if folder.Accessible == nil || *folder.Accessible {
+			return nil
+		}
+		unreachable = append(unreachable, folder.Path)
+	}
+	return fmt.Errorf("none of the root folders of %s is accessible (%s), so missing items can't be added", name, strings.Join(unreachable, ", "))
+}
+
+// profileList formats quality profiles as "ID (name)", ordered by ID
+func profileList(profiles map[int]string) string {
+	if len(profiles) == 0 {
+		return "none"
+	}
+	ids := make([]int, 0, len(profiles))
+	for id := range profiles {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	entries := make([]string, len(ids))
+	for i, id := range ids {
+		entries[i] = fmt.Sprintf("%d (%s)", id, profiles[id])
+	}
+	return strings.Join(entries, ", ")
+}
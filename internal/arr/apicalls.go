@@ -0,0 +1,60 @@
+package arr
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+// apiBase returns the path the service's API is served under
+func apiBase(service string) string {
+	if service == "readarr" {
+		return "/api/v1"
+	}
+	return "/api/v3"
+}
+
+// plannedCall returns the request a real run would send to service to make action, or nil when
+// the change isn't a single request: symlinks are removed from disk, and imports depend on what
+// the service finds in the download folder. payload is the body an add or path update sends.
+func plannedCall(service string, action models.PlannedAction, payload interface{}) *models.APICall {
+	base := apiBase(service)
+
+	var call models.APICall
+	switch action.Action {
+	case models.ActionDeleteEpisodeFile:
+		call = models.APICall{Method: "DELETE", Endpoint: fmt.Sprintf("%s/episodeFile/%d", base, action.FileID)}
+	case models.ActionDeleteMovieFile:
+		call = models.APICall{Method: "DELETE", Endpoint: fmt.Sprintf("%s/moviefile/%d", base, action.FileID)}
+	case models.ActionDeleteBookFile:
+		call = models.APICall{Method: "DELETE", Endpoint: fmt.Sprintf("%s/bookfile/%d", base, action.FileID)}
+	case models.ActionAddMovie:
+		call = models.APICall{Method: "POST", Endpoint: base + "/movie"}
+	case models.ActionAddSeries:
+		call = models.APICall{Method: "POST", Endpoint: base + "/series"}
+	case models.ActionUpdatePath:
+		if action.SeriesID > 0 {
+			call = models.APICall{Method: "PUT", Endpoint: fmt.Sprintf("%s/series/%d?moveFiles=false", base, action.SeriesID)}
+		} else {
+			call = models.APICall{Method: "PUT", Endpoint: fmt.Sprintf("%s/movie/%d?moveFiles=false", base, action.MovieID)}
+		}
+	default:
+		return nil
+	}
+
+	if payload != nil {
+		if body, err := json.Marshal(payload); err == nil {
+			call.Body = body
+		}
+	}
+	return &call
+}
+
+// planCall attaches the request a real run would send for action and logs it
+func (s *CleanupServiceImpl) planCall(logger Logger, action *models.PlannedAction, payload interface{}) {
+	action.APICall = plannedCall(s.client.GetName(), *action, payload)
+	if action.APICall != nil {
+		logger.Info("    🏃 DRY RUN: Would send %s", action.APICall)
+	}
+}
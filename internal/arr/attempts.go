@@ -0,0 +1,116 @@
+package arr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+// ImportAttempts remembers, on disk between runs, when fix-imports last tried and failed to
+// import each stuck queue item. Items tried within the cooldown are skipped, so scheduled runs
+// don't retry a download that can't be imported every time they run.
+type ImportAttempts struct {
+	path     string
+	cooldown time.Duration
+
+	mu       sync.Mutex
+	attempts map[string]time.Time // attemptKey -> time of the last failed attempt
+}
+
+// ImportAttemptsPath returns the location of the import attempts file inside the state directory
+func ImportAttemptsPath(stateDir string) string {
+	return filepath.Join(stateDir, "import-attempts.json")
+}
+
+// LoadImportAttempts loads the attempts saved at path. A missing file gives no attempts, and
+// attempts older than the cooldown are dropped.
+func LoadImportAttempts(path string, cooldown time.Duration) (*ImportAttempts, error) {
+	a := &ImportAttempts{path: path, cooldown: cooldown, attempts: make(map[string]time.Time)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return a, nil
+	}
+	if err != nil {
+		return a, fmt.Errorf("failed to read import attempts: %w", err)
+	}
+	if err := json.Unmarshal(data, &a.attempts); err != nil {
+		a.attempts = make(map[string]time.Time)
+		return a, fmt.Errorf("failed to parse import attempts %s: %w", path, err)
+	}
+	for key, at := range a.attempts {
+		if time.Since(at) >= cooldown {
+			delete(a.attempts, key)
+		}
+	}
+	return a, nil
+}
+
+// attemptKey identifies a queue item across runs. Sonarr can give a download a new queue ID,
+// for example after a restart, so the download ID is preferred.
+func attemptKey(item models.QueueItem) string {
+	if item.DownloadID != "" {
+		return "download:" + item.DownloadID
+	}
+	return fmt.Sprintf("queue:%d", item.ID)
+}
+
+// CoolingDown returns when the item was last tried, and whether that was within the cooldown
+func (a *ImportAttempts) CoolingDown(item models.QueueItem) (time.Time, bool) {
+	if a == nil {
+		return time.Time{}, false
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	at, ok := a.attempts[attemptKey(item)]
+	return at, ok && time.Since(at) < a.cooldown
+}
+
+// Record notes that importing the item failed at time at
+func (a *ImportAttempts) Record(item models.QueueItem, at time.Time) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.attempts[attemptKey(item)] = at
+}
+
+// Cooldown returns how long items are skipped after a failed attempt
+func (a *ImportAttempts) Cooldown() time.Duration {
+	if a == nil {
+		return 0
+	}
+	return a.cooldown
+}
+
+// Save writes the attempts to disk atomically
+func (a *ImportAttempts) Save() error {
+	if a == nil {
+		return nil
+	}
+	a.mu.Lock()
+	data, err := json.MarshalIndent(a.attempts, "", "  ")
+	a.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal import attempts: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(a.path), 0755); err != nil {
+		return fmt.Errorf("failed to create import attempts directory: %w", err)
+	}
+	tmpPath := a.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write import attempts: %w", err)
+	}
+	if err := os.Rename(tmpPath, a.path); err != nil {
+		return fmt.Errorf("failed to replace import attempts file: %w", err)
+	}
+	return nil
+}
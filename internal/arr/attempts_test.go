@@ -0,0 +1,88 @@
+package arr
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+func TestImportAttempts_SaveAndLoad(t *testing.T) {
+	path := ImportAttemptsPath(filepath.Join(t.TempDir(), "state"))
+
+	attempts, err := LoadImportAttempts(path, time.Hour)
+	if err != nil {
+		t.Fatalf("LoadImportAttempts() error = %v", err)
+	}
+	recent := models.QueueItem{ID: 1, DownloadID: "ABC"}
+	old := models.QueueItem{ID: 2}
+	attempts.Record(recent, time.Now().Add(-10*time.Minute))
+	attempts.Record(old, time.Now().Add(-2*time.Hour))
+	if err := attempts.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := LoadImportAttempts(path, time.Hour)
+	if err != nil {
+		t.Fatalf("LoadImportAttempts() error = %v", err)
+	}
+	// Sonarr may give the download a new queue ID; it is still recognized by its download ID
+	if _, ok := loaded.CoolingDown(models.QueueItem{ID: 9, DownloadID: "ABC"}); !ok {
+		t.Error("Expected the download attempted 10 minutes ago to be cooling down")
+	}
+	if _, ok := loaded.CoolingDown(old); ok {
+		t.Error("Expected the attempt older than the cooldown to have expired")
+	}
+	if _, ok := loaded.CoolingDown(models.QueueItem{ID: 3}); ok {
+		t.Error("Expected an item never attempted not to be cooling down")
+	}
+
+	if err := os.WriteFile(path, []byte("not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if loaded, err := LoadImportAttempts(path, time.Hour); err == nil || loaded == nil {
+		t.Errorf("Expected a parse error and empty attempts, got %v and %v", loaded, err)
+	}
+}
+
+func TestImportFixer_Cooldown(t *testing.T) {
+	client := &mockClient{
+		queue: []models.QueueItem{{
+			ID:           7,
+			Title:        "Show.S01E01.1080p",
+			Series:       &models.Series{MediaItem: models.MediaItem{ID: 3, Title: "Show"}},
+			DownloadID:   "ABC",
+			Status:       "completed",
+			ErrorMessage: "One or more episodes expected in this release were not imported or missing from the release",
+		}},
+	}
+	path := ImportAttemptsPath(t.TempDir())
+	attempts, _ := LoadImportAttempts(path, time.Hour)
+	fixer := NewImportFixerWithOptions(client, &mockLogger{}, ImportFixerOptions{Attempts: attempts})
+
+	// The first run attempts the item and records the failure
+	result, err := fixer.FixImports(context.Background(), true)
+	if err != nil {
+		t.Fatalf("FixImports() returned error: %v", err)
+	}
+	if result.TotalStuckItems != 1 || len(result.Errors) != 1 || result.CoolingDown != 0 {
+		t.Fatalf("Expected the item to be attempted and fail, got %+v", result)
+	}
+
+	// The next run, with the attempts loaded from disk, skips it
+	attempts, err = LoadImportAttempts(path, time.Hour)
+	if err != nil {
+		t.Fatalf("LoadImportAttempts() error = %v", err)
+	}
+	fixer = NewImportFixerWithOptions(client, &mockLogger{}, ImportFixerOptions{Attempts: attempts})
+	result, err = fixer.FixImports(context.Background(), true)
+	if err != nil {
+		t.Fatalf("FixImports() returned error: %v", err)
+	}
+	if result.TotalStuckItems != 0 || result.CoolingDown != 1 {
+		t.Errorf("Expected the item to be skipped while cooling down, got %+v", result)
+	}
+}
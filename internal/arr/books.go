@@ -0,0 +1,506 @@
+package arr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+// bookExtensions are the ebook and audiobook files scanned for broken symlinks in Readarr's root folders
+var bookExtensions = []string{".epub", ".mobi", ".azw", ".azw3", ".pdf", ".cbz", ".cbr", ".m4b", ".m4a", ".mp3", ".flac"}
+
+// bookClient returns the client as a BookClient, or an error when it doesn't manage books
+func (s *CleanupServiceImpl) bookClient() (BookClient, error) {
+	books, ok := s.client.(BookClient)
+	if !ok {
+		return nil, fmt.Errorf("unsupported client type: %s doesn't manage books", s.client.GetName())
+	}
+	return books, nil
+}
+
+// cleanupAllBooks fetches every book in Readarr and cleans up the ones with files
+func (s *CleanupServiceImpl) cleanupAllBooks(ctx context.Context) (*models.CleanupResult, error) {
+	bookClient, err := s.bookClient()
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Step 1: Fetching all books...")
+	s.startPhase(PhaseFetching, 0)
+	fetchStart := time.Now()
+	books, err := bookClient.GetAllBooks(ctx)
+	s.clock.track(phaseFetch, fetchStart)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch books: %w", err)
+	}
+
+	if len(books) == 0 {
+		s.logger.Info("No books found")
+		return &models.CleanupResult{
+			Stats:   s.finishStats(models.CleanupStats{}),
+			Success: true,
+			Report:  s.buildReport(),
+			Actions: s.buildActions(),
+		}, nil
+	}
+
+	s.logger.Info("Found %d books", len(books))
+
+	// Every book is kept for naming broken symlinks, but books whose statistics report no
+	// files have nothing to clean up, so their files aren't fetched
+	s.books = make(map[int]models.Book, len(books))
+	var withFiles []models.Book
+	withoutFiles := 0
+	for _, book := range books {
+		if id := book.GoodreadsID(); id > 0 {
+			s.books[id] = book
+		}
+		if book.BookFileCount != nil && *book.BookFileCount == 0 {
+			withoutFiles++
+			continue
+		}
+		withFiles = append(withFiles, book)
+	}
+	if withoutFiles > 0 {
+		s.logger.Info("Skipping %d books without a file", withoutFiles)
+	}
+
+	return s.cleanupBooks(ctx, bookClient, withFiles)
+}
+
+// cleanupBooks checks the files of each book concurrently and removes the records of missing
+// ones. Untargeted runs first scan the root folders for broken symlinks.
+func (s *CleanupServiceImpl) cleanupBooks(ctx context.Context, bookClient BookClient, books []models.Book) (*models.CleanupResult, error) {
+	stats := models.CleanupStats{}
+	var messages []string
+	var mu sync.Mutex
+
+	s.logger.Info("Processing %d books with concurrency limit of %d", len(books), s.concurrentLimit)
+
+	if s.targeted {
+		s.logger.Info("Targeted run: skipping broken symlink scan")
+	} else {
+		s.logger.Info("Step 1.5: Checking for broken symlinks of books...")
+		s.startPhase(PhaseSymlinkScan, 0)
+		scanStart := time.Now()
+		symlinkStats, err := s.handleBrokenBookSymlinks(ctx, bookClient)
+		s.clock.track(phaseSymlinkScan, scanStart)
+		if err != nil {
+			s.logger.Warn("Broken symlink handling failed: %s", err.Error())
+			messages = append(messages, fmt.Sprintf("Broken symlink handling failed: %s", err.Error()))
+			stats.Warnings++
+		} else {
+			stats.TotalItemsChecked += symlinkStats.TotalItemsChecked
+			stats.MissingFiles += symlinkStats.MissingFiles
+			stats.Errors += symlinkStats.Errors
+			stats.Warnings += symlinkStats.Warnings
+			stats.Skipped += symlinkStats.Skipped
+		}
+	}
+
+	s.startPhase(PhaseChecking, len(books))
+	s.forEachConcurrently(ctx, len(books), func(i int) {
+		book := books[i]
+		s.logger.Info("")
+		s.logger.Info("Processing book %d/%d (ID: %d)", i+1, len(books), book.ID)
+		s.logger.Info("Book: %s", bookName(book))
+
+		bookStats, err := s.cleanupBook(ctx, bookClient, book)
+
+		mu.Lock()
+		if err != nil {
+			s.logger.Error("Error processing book %d: %s", book.ID, err.Error())
+			s.progressReporter.ReportError(err)
+			countFailure(&stats, err)
+			messages = append(messages, fmt.Sprintf("Error processing book %d: %s", book.ID, err.Error()))
+		} else {
+			stats.TotalItemsChecked += bookStats.TotalItemsChecked
+			stats.MissingFiles += bookStats.MissingFiles
+			stats.DeletedRecords += bookStats.DeletedRecords
+			stats.Errors += bookStats.Errors
+			stats.Warnings += bookStats.Warnings
+			stats.Skipped += bookStats.Skipped
+			stats.SizeMismatches += bookStats.SizeMismatches
+			stats.EmptyPaths += bookStats.EmptyPaths
+			stats.Corrupt += bookStats.Corrupt
+			stats.OutsideRoot += bookStats.OutsideRoot
+		}
+		mu.Unlock()
+		s.finishItem()
+
+		// Add delay after processing to be nice to the API
+		if s.requestDelay > 0 {
+			time.Sleep(s.requestDelay)
+		}
+	})
+
+	if err := ctx.Err(); err != nil {
+		s.logger.Warn("Cleanup cancelled")
+		return &models.CleanupResult{
+			Stats:    s.finishStats(stats),
+			Messages: messages,
+			Success:  false,
+			Report:   s.buildReport(),
+			Actions:  s.buildActions(),
+		}, err
+	}
+
+	s.logger.Info("Completed processing %d books", len(books))
+
+	// Report final statistics
+	s.progressReporter.Finish(stats)
+
+	// Trigger refresh if we deleted any records
+	if stats.DeletedRecords > 0 && !s.dryRun {
+		if msg := s.triggerSearch(ctx); msg != "" {
+			messages = append(messages, msg)
+		}
+	}
+
+	return &models.CleanupResult{
+		Stats:    s.finishStats(stats),
+		Messages: messages,
+		Success:  stats.Errors == 0,
+		Report:   s.buildReport(),
+		Actions:  s.buildActions(),
+	}, nil
+}
+
+// bookName returns a book's title along with its author, when Readarr reported one
+func bookName(book models.Book) string {
+	if book.AuthorName == "" {
+		return book.Title
+	}
+	return fmt.Sprintf("%s - %s", book.AuthorName, book.Title)
+}
+
+// cleanupBook checks every file of a book. Audiobooks can have a file per part, so each file
+// record is checked and deleted on its own.
+func (s *CleanupServiceImpl) cleanupBook(ctx context.Context, bookClient BookClient, book models.Book) (models.CleanupStats, error) {
+	stats := models.CleanupStats{}
+	logger := WithItem(s.logger, ItemFields{Service: s.client.GetName(), BookID: book.ID})
+
+	fetchStart := time.Now()
+	files, err := retryOnce(ctx, func() ([]models.BookFile, error) {
+		return bookClient.GetBookFiles(ctx, book.ID)
+	})
+	s.clock.track(phaseFetch, fetchStart)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			logger.Info("    ℹ️  Book %d already deleted or not found", book.ID)
+			return stats, nil
+		}
+		return stats, fmt.Errorf("failed to get files of book %d: %w", book.ID, err)
+	}
+
+	if len(files) == 0 {
+		logger.Debug("  Book %d has no file records", book.ID)
+		return stats, nil
+	}
+
+	for _, file := range files {
+		if ctx.Err() != nil {
+			return stats, ctx.Err()
+		}
+		stats.TotalItemsChecked++
+		s.cleanupBookFile(ctx, WithItem(logger, ItemFields{FileID: file.ID}), bookClient, book, file, &stats)
+	}
+	return stats, nil
+}
+
+// cleanupBookFile checks one book file and deletes its record when the file is missing
+func (s *CleanupServiceImpl) cleanupBookFile(ctx context.Context, logger Logger, bookClient BookClient, book models.Book, file models.BookFile, stats *models.CleanupStats) {
+	name := bookName(book)
+
+	// A record without a path is skipped, or with the delete empty path policy handled like a
+	// missing file
+	emptyPath := file.Path == ""
+	if emptyPath {
+		stats.EmptyPaths++
+		if !s.deleteEmptyPaths {
+			logger.Warn("    ⚠️  No file path found for book file %d", file.ID)
+			stats.Warnings++
+			return
+		}
+		logger.Warn("    ⚠️  No file path found for book file %d, treating the record as broken", file.ID)
+	}
+	logger = WithItem(logger, ItemFields{Path: file.Path})
+
+	// A record outside every root folder follows the outside root policy, and under the delete
+	// policy is handled like a missing file
+	outside := !emptyPath && s.outsideRootFolders(ctx, file.Path)
+	if outside {
+		entry := models.OutsideRootEntry{
+			MediaType: "book",
+			MediaName: name,
+			FilePath:  file.Path,
+			FileID:    file.ID,
+		}
+		if s.handleOutsideRoot(ctx, logger, entry, book.ID, stats) {
+			return
+		}
+	}
+
+	verifyStart := time.Now()
+	exists := !emptyPath && !outside && s.fileChecker.FileExists(file.Path)
+	actual, mismatch := int64(0), false
+	if exists {
+		actual, mismatch = s.sizeMismatch(file.Path, file.Size)
+	}
+	expectedSum, actualSum, corrupt := "", "", false
+	if exists && !mismatch {
+		expectedSum, actualSum, corrupt = s.checksumMismatch(file.Path)
+	}
+	s.clock.track(phaseVerification, verifyStart)
+
+	if exists {
+		if mismatch {
+			logger.Warn("    ⚠️  Size mismatch: %s (expected %d bytes, found %d)", file.Path, file.Size, actual)
+			stats.SizeMismatches++
+			s.addMissingFileEntry(models.MissingFileEntry{
+				MediaType:    "book",
+				MediaName:    name,
+				FilePath:     file.Path,
+				FileID:       file.ID,
+				ProcessedAt:  time.Now().Format(time.RFC3339),
+				GoodreadsID:  book.GoodreadsID(),
+				Issue:        models.IssueSizeMismatch,
+				ExpectedSize: file.Size,
+				ActualSize:   actual,
+				Reason:       mismatchReason(actual),
+			})
+		} else if corrupt {
+			logger.Warn("    ⚠️  Checksum mismatch: %s", file.Path)
+			stats.Corrupt++
+			s.addMissingFileEntry(models.MissingFileEntry{
+				MediaType:      "book",
+				MediaName:      name,
+				FilePath:       file.Path,
+				FileID:         file.ID,
+				ProcessedAt:    time.Now().Format(time.RFC3339),
+				GoodreadsID:    book.GoodreadsID(),
+				Issue:          models.IssueChecksumMismatch,
+				ExpectedSHA256: expectedSum,
+				ActualSHA256:   actualSum,
+				Reason:         models.ReasonCorrupt,
+			})
+		} else {
+			logger.Debug("    ✅ File exists: %s", file.Path)
+			s.markRecovered(logger, models.MissingFileEntry{
+				MediaType:   "book",
+				MediaName:   name,
+				GoodreadsID: book.GoodreadsID(),
+				FilePath:    file.Path,
+			})
+		}
+		return
+	}
+
+	// File is missing; empty paths and records outside the root folders are counted on their own
+	if !emptyPath && !outside {
+		stats.MissingFiles++
+		s.progressReporter.ReportMissingFile(file.Path)
+	}
+
+	reason := s.missingReason(file.Path)
+	if outside {
+		reason = models.ReasonOutsideRoot
+	}
+	s.addMissingFileEntry(models.MissingFileEntry{
+		MediaType:   "book",
+		MediaName:   name,
+		FilePath:    file.Path,
+		FileID:      file.ID,
+		ProcessedAt: time.Now().Format(time.RFC3339),
+		GoodreadsID: book.GoodreadsID(),
+		Reason:      reason,
+	})
+
+	action := models.PlannedAction{
+		Action:      models.ActionDeleteBookFile,
+		MediaType:   "book",
+		MediaName:   name,
+		BookID:      book.ID,
+		FileID:      file.ID,
+		Path:        file.Path,
+		GoodreadsID: book.GoodreadsID(),
+	}
+	if !s.scope.Allows(action) {
+		logger.Info("    ⏭️  Skipping book file record %d: not listed in %s", file.ID, s.scope.Source)
+		stats.Skipped++
+		return
+	}
+
+	if s.reportOnly() {
+		logger.Info("    🏃 DRY RUN: Would delete book file record %d", file.ID)
+		s.planCall(logger, &action, nil)
+		s.addPlannedAction(action)
+		return
+	}
+
+	logger.Info("    🗑️  Deleting book file record %d...", file.ID)
+	deleteStart := time.Now()
+	err := bookClient.DeleteBookFile(ctx, file.ID)
+	s.clock.track(phaseDeletion, deleteStart)
+	if err != nil {
+		logger.Error("    ❌ Failed to delete book file record %d: %s", file.ID, err.Error())
+		s.progressReporter.ReportError(err)
+		stats.Errors++
+		return
+	}
+
+	stats.DeletedRecords++
+	s.progressReporter.ReportDeletedRecord(file.ID)
+
+	// Small delay between operations
+	if s.requestDelay > 0 {
+		time.Sleep(s.requestDelay)
+	}
+}
+
+// resolveBookPath finds the book with a book file at path. Only books whose author's folder
+// contains the path have their files fetched.
+func (s *CleanupServiceImpl) resolveBookPath(ctx context.Context, bookClient BookClient, path string) (*models.Book, error) {
+	books, err := bookClient.GetAllBooks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch books: %w", err)
+	}
+
+	for _, book := range books {
+		if (book.BookFileCount != nil && *book.BookFileCount == 0) || (book.Path != "" && !pathWithin(path, book.Path)) {
+			continue
+		}
+
+		files, err := bookClient.GetBookFiles(ctx, book.ID)
+		if err != nil {
+			s.logger.Debug("Could not get files of book %d: %s", book.ID, err.Error())
+			continue
+		}
+		for _, file := range files {
+			if file.Path != "" && filepath.Clean(file.Path) == path {
+				s.logger.Info("✅ %s is a file of %s", path, bookName(book))
+				return &book, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("%w: no readarr book file is %s", ErrNoOwningRecord, path)
+}
+
+// handleBrokenBookSymlinks scans Readarr's root folders for broken symlinks of books. Books in
+// the library are reported as missing; Readarr can't add books by ID alone, so the others are
+// only reported.
+func (s *CleanupServiceImpl) handleBrokenBookSymlinks(ctx context.Context, bookClient BookClient) (models.CleanupStats, error) {
+	stats := models.CleanupStats{}
+
+	s.logger.Info("Scanning for broken symlinks in Readarr root directories...")
+
+	rootFolders, err := s.client.GetRootFolders(ctx)
+	if err != nil {
+		return stats, fmt.Errorf("failed to get root folders: %w", err)
+	}
+
+	if len(rootFolders) == 0 {
+		s.logger.Info("No root folders configured in Readarr")
+		return stats, nil
+	}
+
+	var allBrokenSymlinks []string
+	for _, folder := range rootFolders {
+		s.logger.Info("Scanning root folder: %s", folder.Path)
+
+		brokenSymlinks, err := s.fileChecker.FindBrokenSymlinks(folder.Path, bookExtensions)
+		if err != nil {
+			s.logger.Warn("Failed to scan folder %s: %s", folder.Path, err.Error())
+			stats.Errors++
+			continue
+		}
+
+		s.logger.Info("Found %d broken symlinks in %s", len(brokenSymlinks), folder.Path)
+		allBrokenSymlinks = append(allBrokenSymlinks, brokenSymlinks...)
+	}
+
+	if len(allBrokenSymlinks) == 0 {
+		s.logger.Info("No broken symlinks found")
+		return stats, nil
+	}
+
+	s.logger.Info("Processing %d broken symlinks...", len(allBrokenSymlinks))
+
+	resolve := func(ctx context.Context, symlinkPath string) (int, *titleMatch, error) {
+		id, err := s.bookGoodreadsIDFromPath(ctx, bookClient, symlinkPath)
+		return id, nil, err
+	}
+	symlinkStats := s.processBrokenSymlinks(ctx, allBrokenSymlinks, rootFolders, resolve, s.handleBrokenBookSymlink)
+	stats.Errors += symlinkStats.Errors
+	stats.TotalItemsChecked += symlinkStats.TotalItemsChecked
+	stats.MissingFiles += symlinkStats.MissingFiles
+	stats.Warnings += symlinkStats.Warnings
+	stats.Skipped += symlinkStats.Skipped
+
+	return stats, nil
+}
+
+// bookGoodreadsIDFromPath returns the Goodreads ID of the book a path belongs to. Paths tagged
+// with an ISBN instead are resolved through Readarr's book lookup.
+func (s *CleanupServiceImpl) bookGoodreadsIDFromPath(ctx context.Context, bookClient BookClient, symlinkPath string) (int, error) {
+	info := s.pathParser.Parse(symlinkPath)
+	if info.GoodreadsID > 0 {
+		return info.GoodreadsID, nil
+	}
+	if info.ISBN == "" {
+		return 0, fmt.Errorf("Goodreads ID not found in path: %s", symlinkPath)
+	}
+
+	book, err := bookClient.LookupBookByISBN(ctx, info.ISBN)
+	if err != nil {
+		return 0, err
+	}
+	if id := book.GoodreadsID(); id > 0 {
+		s.logger.Debug("Resolved ISBN %s to Goodreads ID %d", info.ISBN, id)
+		return id, nil
+	}
+	return 0, fmt.Errorf("book with ISBN %s has no Goodreads ID", info.ISBN)
+}
+
+// handleBrokenBookSymlink deletes the broken symlinks of a book and reports the book as missing
+func (s *CleanupServiceImpl) handleBrokenBookSymlink(ctx context.Context, group brokenSymlinkGroup, rootFolders []models.RootFolder) (models.CleanupStats, error) {
+	stats := models.CleanupStats{TotalItemsChecked: len(group.Paths)}
+	goodreadsID := group.ID
+	logger := WithItem(s.logger, ItemFields{Service: s.client.GetName(), Path: group.Paths[0]})
+
+	logger.Debug("Processing %d broken symlink(s) of Goodreads ID %d", len(group.Paths), goodreadsID)
+
+	symlinkPaths, err := s.deleteBrokenSymlinks(group, models.PlannedAction{MediaType: "book", GoodreadsID: goodreadsID}, &stats)
+	if err != nil || len(symlinkPaths) == 0 {
+		return stats, err
+	}
+
+	name := fmt.Sprintf("Goodreads %d", goodreadsID)
+	if book, ok := s.books[goodreadsID]; ok {
+		name = bookName(book)
+		logger = WithItem(logger, ItemFields{BookID: book.ID})
+		logger.Debug("Book with Goodreads ID %d is in the library: %s", goodreadsID, name)
+	} else {
+		logger.Info("📋 Book with Goodreads ID %d is not in Readarr: add it to have Readarr search for it", goodreadsID)
+	}
+
+	s.addMissingFileEntry(models.MissingFileEntry{
+		MediaType:   "book",
+		MediaName:   name,
+		FilePath:    symlinkPaths[0],
+		FilePaths:   symlinkPathsOf(symlinkPaths),
+		FileID:      0, // No file ID since it's a broken symlink
+		ProcessedAt: time.Now().Format(time.RFC3339),
+		GoodreadsID: goodreadsID,
+		Reason:      models.ReasonBrokenSymlink,
+	})
+	stats.MissingFiles += len(symlinkPaths)
+
+	return stats, nil
+}
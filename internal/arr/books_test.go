@@ -0,0 +1,157 @@
+package arr
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/hnipps/refresharr/internal/config"
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+// mockBookClient adds the BookClient methods to mockClient
+type mockBookClient struct {
+	*mockClient
+	books          []models.Book
+	bookFiles      map[int][]models.BookFile // bookID -> files
+	deletedBookIDs []int
+}
+
+func newMockBookClient(books []models.Book, bookFiles map[int][]models.BookFile) *mockBookClient {
+	return &mockBookClient{mockClient: &mockClient{name: "readarr"}, books: books, bookFiles: bookFiles}
+}
+
+func (m *mockBookClient) GetAllBooks(ctx context.Context) ([]models.Book, error) {
+	return m.books, nil
+}
+
+func (m *mockBookClient) GetBookFiles(ctx context.Context, bookID int) ([]models.BookFile, error) {
+	return m.bookFiles[bookID], nil
+}
+
+func (m *mockBookClient) DeleteBookFile(ctx context.Context, fileID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deletedBookIDs = append(m.deletedBookIDs, fileID)
+	return nil
+}
+
+func (m *mockBookClient) LookupBookByISBN(ctx context.Context, isbn string) (*models.Book, error) {
+	return nil, ErrNotFound
+}
+
+func newTestBookLibrary() *mockBookClient {
+	return newMockBookClient(
+		[]models.Book{
+			{MediaItem: models.MediaItem{ID: 1, Title: "Dune", Path: "/books/Frank Herbert"}, AuthorName: "Frank Herbert", ForeignBookID: "234225", BookFileCount: intPtr(2)},
+			{MediaItem: models.MediaItem{ID: 2, Title: "Emma"}, AuthorName: "Jane Austen", ForeignBookID: "6969", BookFileCount: intPtr(0)},
+		},
+		map[int][]models.BookFile{
+			1: {
+				{ID: 10, BookID: 1, Path: "/books/Frank Herbert/Dune.epub"},
+				{ID: 11, BookID: 1, Path: "/books/Frank Herbert/Dune.m4b"},
+			},
+		},
+	)
+}
+
+func TestCleanupService_CleanupMissingFiles_Books(t *testing.T) {
+	client := newTestBookLibrary()
+	fileChecker := &mockFileChecker{fileExists: map[string]bool{"/books/Frank Herbert/Dune.epub": true}}
+	progressReporter := &mockProgressReporter{}
+
+	service := NewCleanupService(client, fileChecker, &mockLogger{}, progressReporter, 0, false)
+	result, err := service.CleanupMissingFiles(context.Background())
+	if err != nil {
+		t.Fatalf("CleanupMissingFiles() failed: %v", err)
+	}
+
+	// The book without files isn't fetched
+	if result.Stats.TotalItemsChecked != 2 {
+		t.Errorf("Expected 2 items checked, got %d", result.Stats.TotalItemsChecked)
+	}
+	if result.Stats.MissingFiles != 1 || result.Stats.DeletedRecords != 1 {
+		t.Errorf("Expected 1 missing file deleted, got %+v", result.Stats)
+	}
+	if !reflect.DeepEqual(client.deletedBookIDs, []int{11}) {
+		t.Errorf("Expected book file 11 to be deleted, got %v", client.deletedBookIDs)
+	}
+
+	entries := result.Report.MissingFiles
+	if len(entries) != 1 || entries[0].MediaType != "book" || entries[0].MediaName != "Frank Herbert - Dune" || entries[0].GoodreadsID != 234225 {
+		t.Errorf("Unexpected missing file entries: %+v", entries)
+	}
+	if !progressReporter.finishCalled {
+		t.Error("Expected Finish() to be called on progress reporter")
+	}
+}
+
+func TestCleanupService_CleanupMissingFiles_BooksDryRun(t *testing.T) {
+	client := newTestBookLibrary()
+
+	service := NewCleanupService(client, &mockFileChecker{}, &mockLogger{}, &mockProgressReporter{}, 0, true)
+	result, err := service.CleanupMissingFiles(context.Background())
+	if err != nil {
+		t.Fatalf("CleanupMissingFiles() failed: %v", err)
+	}
+
+	if len(client.deletedBookIDs) != 0 {
+		t.Errorf("Expected nothing deleted in a dry run, got %v", client.deletedBookIDs)
+	}
+	if len(result.Actions) != 2 {
+		t.Fatalf("Expected 2 planned actions, got %+v", result.Actions)
+	}
+	for _, action := range result.Actions {
+		if action.Action != models.ActionDeleteBookFile || action.BookID != 1 || action.GoodreadsID != 234225 {
+			t.Errorf("Unexpected planned action: %+v", action)
+		}
+	}
+}
+
+func TestCleanupService_CleanupMissingFileAtPath_Book(t *testing.T) {
+	client := newTestBookLibrary()
+
+	service := NewCleanupService(client, &mockFileChecker{}, &mockLogger{}, &mockProgressReporter{}, 0, false)
+	if _, err := service.CleanupMissingFileAtPath(context.Background(), "/books/Frank Herbert/Dune.m4b"); err != nil {
+		t.Fatalf("CleanupMissingFileAtPath() failed: %v", err)
+	}
+	// Both files of the book are checked, and both are missing
+	if len(client.deletedBookIDs) != 2 {
+		t.Errorf("Expected the book's file records to be deleted, got %v", client.deletedBookIDs)
+	}
+
+	_, err := service.CleanupMissingFileAtPath(context.Background(), "/books/Unknown/Book.epub")
+	if !errors.Is(err, ErrNoOwningRecord) {
+		t.Errorf("CleanupMissingFileAtPath() error = %v, want ErrNoOwningRecord", err)
+	}
+}
+
+func TestCleanupService_BooksOutsideRootWithRewritePolicy(t *testing.T) {
+	client := newTestBookLibrary()
+	client.rootFolders = []models.RootFolder{{ID: 1, Path: "/data/books"}}
+	fileChecker := &mockFileChecker{fileExists: map[string]bool{
+		"/data/books/Frank Herbert/Dune.epub": true,
+		"/data/books/Frank Herbert/Dune.m4b":  true,
+	}}
+
+	service := NewCleanupServiceWithOptions(client, fileChecker, &mockLogger{}, &mockProgressReporter{}, CleanupOptions{
+		ConcurrentLimit:   1,
+		OutsideRootPolicy: config.OutsideRootRewrite,
+		PathMappings:      []models.PathMapping{{From: "/books", To: "/data/books"}},
+	})
+	result, err := service.CleanupMissingFiles(context.Background())
+	if err != nil {
+		t.Fatalf("CleanupMissingFiles() failed: %v", err)
+	}
+
+	// Readarr can't move books, so they're reported like under the skip policy, not failed
+	if result.Stats.OutsideRoot != 2 || result.Stats.Errors != 0 || result.Stats.Warnings != 2 || len(client.deletedBookIDs) != 0 {
+		t.Errorf("Expected 2 reported records outside the root folders and no errors, got %+v", result.Stats)
+	}
+	for _, entry := range result.Report.OutsideRootFolders {
+		if entry.Outcome != models.OutsideReported {
+			t.Errorf("Expected outcome %s, got %+v", models.OutsideReported, entry)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package arr
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Feature is an optional API feature that not every version of Sonarr and Radarr has
+type Feature string
+
+// Optional features, see Capabilities
+const (
+	FeatureDownloadedEpisodesScan Feature = "DownloadedEpisodesScan command"
+	FeatureManualImportFilters    Feature = "manual import filters" // Narrowing candidates by download or series
+)
+
+// Capabilities are the optional features a server supports. They are probed from the server's
+// version when a client connects. The version is all there is to go on for query parameters
+// such as the manual import filters: older servers ignore parameters they don't know and
+// answer with every candidate, so calling the endpoint can't tell. Commands can't be probed
+// without running them, so they are assumed available until the server answers one with 404;
+// the feature is then switched off and later calls skip it instead of failing the same way again.
+type Capabilities struct {
+	mu          sync.RWMutex
+	service     string
+	features    []Feature // Optional features the service has in some version
+	version     string
+	unsupported map[Feature]bool
+}
+
+// CapabilityReporter is implemented by clients that probe which optional features the server supports
+type CapabilityReporter interface {
+	Capabilities() *Capabilities
+}
+
+// capabilitiesOf returns the capabilities the client probed, or nil when it doesn't probe them
+func capabilitiesOf(client Client) *Capabilities {
+	if reporter, ok := client.(CapabilityReporter); ok {
+		return reporter.Capabilities()
+	}
+	return nil
+}
+
+// newCapabilities creates the capabilities of a service that hasn't been probed yet, with the
+// optional features some of its versions have
+func newCapabilities(service string, features ...Feature) *Capabilities {
+	return &Capabilities{service: service, features: features, unsupported: make(map[Feature]bool)}
+}
+
+// Supports reports whether the feature can be used. Everything is assumed supported until
+// probed, and by clients that don't probe (nil capabilities).
+func (c *Capabilities) Supports(feature Feature) bool {
+	if c == nil {
+		return true
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return !c.unsupported[feature]
+}
+
+// Version returns the server version found when connecting, empty until then
+func (c *Capabilities) Version() string {
+	if c == nil {
+		return ""
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.version
+}
+
+// probe records the server's version and which features it lacks
+func (c *Capabilities) probe(version string, unsupported ...Feature) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.version = version
+	c.unsupported = make(map[Feature]bool, len(unsupported))
+	for _, feature := range unsupported {
+		c.unsupported[feature] = true
+	}
+}
+
+// disable switches off a feature the server rejected, logging it the first time
+func (c *Capabilities) disable(feature Feature, logger Logger) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.unsupported[feature] {
+		return
+	}
+	c.unsupported[feature] = true
+	logger.Info("🧭 %s %s doesn't support the %s, skipping it from now on", c.service, c.version, feature)
+}
+
+// String lists the service's optional features and whether each is supported
+func (c *Capabilities) String() string {
+	parts := make([]string, 0, len(c.features))
+	for _, feature := range c.features {
+		mark := "yes"
+		if !c.Supports(feature) {
+			mark = "no"
+		}
+		parts = append(parts, fmt.Sprintf("%s: %s", feature, mark))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// probeRadarrCapabilities records the version of a Radarr server. The calls made to Radarr are
+// the same in every version it supports, so it has no optional features to switch off.
+func probeRadarrCapabilities(caps *Capabilities, version string, logger Logger) {
+	caps.probe(version)
+	logger.Debug("Radarr version %s", version)
+}
@@ -0,0 +1,103 @@
+package arr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hnipps/refresharr/internal/config"
+)
+
+func TestSonarrClient_DisablesRejectedFeatures(t *testing.T) {
+	var commands, statusRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/system/status":
+			statusRequests.Add(1)
+			w.Write([]byte(`{"version":"4.0.5.1710"}`))
+		case "/api/v3/command":
+			commands.Add(1)
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.Write([]byte(`[]`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewSonarrClient(&config.SonarrConfig{URL: server.URL, APIKey: "key"}, 5*time.Second, &mockLogger{})
+	ctx := context.Background()
+	if err := client.TestConnection(ctx); err != nil {
+		t.Fatalf("TestConnection() error = %v", err)
+	}
+
+	caps := capabilitiesOf(client)
+	if caps.Version() != "4.0.5.1710" {
+		t.Errorf("Expected the probed version 4.0.5.1710, got %q", caps.Version())
+	}
+	if !caps.Supports(FeatureManualImportFilters) {
+		t.Errorf("Expected a v4 server to support every probed feature, got %s", caps)
+	}
+
+	// The rejected command is only sent once
+	for i := 0; i < 3; i++ {
+		if err := client.TriggerDownloadClientScan(ctx); err != nil {
+			t.Fatalf("TriggerDownloadClientScan() error = %v", err)
+		}
+	}
+	if commands.Load() != 1 {
+		t.Errorf("Expected 1 scan command, got %d", commands.Load())
+	}
+	if caps.Supports(FeatureDownloadedEpisodesScan) {
+		t.Error("Expected the rejected command to be switched off")
+	}
+
+	// The version found on connect is reused for the API mapping
+	if _, err := client.GetManualImport(ctx, "/downloads"); err != nil {
+		t.Fatalf("GetManualImport() error = %v", err)
+	}
+	if statusRequests.Load() != 1 {
+		t.Errorf("Expected a single status request, got %d", statusRequests.Load())
+	}
+}
+
+func TestSonarrClient_ProbesV2Capabilities(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"version":"2.0.0.5344"}`))
+	}))
+	defer server.Close()
+
+	client := NewSonarrClient(&config.SonarrConfig{URL: server.URL, APIKey: "key"}, 5*time.Second, &mockLogger{})
+	caps := capabilitiesOf(client)
+	if !caps.Supports(FeatureManualImportFilters) {
+		t.Error("Expected features to be assumed supported before connecting")
+	}
+	if err := client.TestConnection(context.Background()); err != nil {
+		t.Fatalf("TestConnection() error = %v", err)
+	}
+	if caps.Version() != "2.0.0.5344" || caps.Supports(FeatureManualImportFilters) {
+		t.Errorf("Expected a v2 server without manual import filters, got %s %s", caps.Version(), caps)
+	}
+}
+
+func TestRadarrClients_ProbeVersion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"version":"2.0.0.5344"}`))
+	}))
+	defer server.Close()
+
+	cfg := &config.RadarrConfig{URL: server.URL, APIKey: "key"}
+	for _, client := range []Client{
+		NewRadarrClient(cfg, 5*time.Second, &mockLogger{}),
+		NewStarrRadarrClient(cfg, 5*time.Second, &mockLogger{}),
+	} {
+		if err := client.TestConnection(context.Background()); err != nil {
+			t.Fatalf("%T: TestConnection() error = %v", client, err)
+		}
+		if caps := capabilitiesOf(client); caps.Version() != "2.0.0.5344" {
+			t.Errorf("%T: expected the probed version 2.0.0.5344, got %q", client, caps.Version())
+		}
+	}
+}
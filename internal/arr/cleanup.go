@@ -2,10 +2,14 @@ package arr
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/hnipps/refresharr/pkg/models"
@@ -19,22 +23,80 @@ func min(a, b int) int {
 	return b
 }
 
+// ErrNoOwningRecord is returned when no episode, movie or book record owns a targeted file path
+var ErrNoOwningRecord = errors.New("no record owns path")
+
+// ErrConnectionFailed is returned when a cleanup can't start because the service isn't reachable
+var ErrConnectionFailed = errors.New("connection test failed")
+
 // CleanupServiceImpl implements the CleanupService interface
 type CleanupServiceImpl struct {
-	client           Client
-	fileChecker      FileChecker
-	logger           Logger
-	progressReporter ProgressReporter
-	requestDelay     time.Duration
-	concurrentLimit  int
-	dryRun           bool
-	qualityProfileID int  // Quality profile ID for adding movies/series
-	addMissingMovies bool // Whether to add missing movies/series from broken symlinks to collection
-	missingFiles     []models.MissingFileEntry
-	missingFilesMu   sync.Mutex
-	seriesInfo       map[int]string // seriesID -> seriesName
-	movieInfo        map[int]string // movieID -> movieName
-	mediaInfoMu      sync.RWMutex
+	client            Client
+	fileChecker       FileChecker
+	logger            Logger
+	progressReporter  ProgressReporter
+	requestDelay      time.Duration
+	concurrentLimit   int
+	episodeLimit      int // Episodes of a series checked at once (0 means the default, see episodeConcurrency)
+	movieLimit        int // Movies checked at once (0 means concurrentLimit)
+	dryRun            bool
+	qualityProfileID  int                  // Quality profile ID for adding movies/series
+	addMissingMovies  bool                 // Whether to add missing movies/series from broken symlinks to collection
+	scope             *ActionScope         // Restricts changes to items from a reviewed dry-run artifact
+	searchGate        SearchGate           // Checked before triggering missing searches (nil means always search)
+	seasons           map[int]bool         // Season numbers to restrict series cleanup to (nil means all seasons)
+	episodeIDs        map[int]bool         // Episode IDs to restrict series cleanup to (nil means all episodes)
+	targeted          bool                 // Limited to explicit items, so library-wide symlink scans are skipped
+	verify            bool                 // Read-only verify run: sizes are checked and nothing is written
+	searchSkipped     string               // Why the missing search was not triggered, if it was skipped
+	clock             runClock             // Run duration, phase timings and API call count
+	apiBudget         int                  // API calls allowed before switching to report-only mode (0 means unlimited)
+	budgetOnce        sync.Once            // Logs the switch to report-only mode once
+	spillAfter        int                  // Missing file entries kept in memory before spilling to disk (0 means the default)
+	mediaCache        *MediaCache          // Library kept between runs (nil fetches the library every run)
+	skipSpecials      bool                 // Leave season 0 out unless it was selected explicitly
+	pathParser        *models.PathParser   // Finds IDs in broken symlink paths (nil means the default patterns)
+	titleConfidence   float64              // Confidence a title lookup match needs before the item is added
+	addCollections    bool                 // Monitor the collection of movies added from broken symlinks, so Radarr adds the rest of it
+	validateAdds      bool                 // Check planned adds in dry runs, see checkAdd
+	deleteEmptyPaths  bool                 // Delete file records without a path like missing files instead of skipping them
+	outsideRootPolicy string               // What to do with file records outside the root folders, one of the config.OutsideRoot* policies
+	pathMappings      []models.PathMapping // Rules for rewriting paths outside the root folders
+	checksums         map[string]string    // SHA-256 sums by path from the checksum manifest
+	rootFolders       []models.RootFolder  // Fetched for the first file record checked
+	rootFoldersOnce   sync.Once
+	qualityProfiles   map[int]string // Names of the service's quality profiles by ID, see loadQualityProfiles
+	profilesErr       error
+	profilesOnce      sync.Once
+	addSettingsErr    error // Why adds would fail, see validateAddSettings
+	addSettingsOnce   sync.Once
+	missingFiles      *missingFileSpool
+	plannedActions    []models.PlannedAction // Actions skipped because of dry-run mode
+	missingFilesMu    sync.Mutex
+	seriesInfo        map[int]string      // seriesID -> seriesName
+	movieInfo         map[int]string      // movieID -> movieName
+	books             map[int]models.Book // Goodreads ID -> book, for naming broken symlinks of books
+	mediaInfoMu       sync.RWMutex
+	symlinkEpisodes   map[int][]models.Episode // seriesID -> episodes, for naming broken symlinks
+	symlinkEpMu       sync.Mutex
+	missingBefore     map[string]models.MissingFileEntry // Files earlier runs found missing, by recovery key
+	missingBeforeGaps bool                               // missingBefore may lack files, see CleanupOptions.PreviouslyTruncated
+	recovered         []models.RecoveredFileEntry
+	recoveredMu       sync.Mutex
+	outsideRoot       []models.OutsideRootEntry
+	outsideRootMu     sync.Mutex
+	pathUpdates       map[string]string // Outcome of each series or movie path update, by "<mediaType>-<id>"
+	pathUpdatesMu     sync.Mutex
+	instance          string   // Extra instance of the service, see CleanupOptions.Instance
+	tag               string   // Label of the tag applied to affected series and movies (empty means none)
+	tags              tagState // Series and movies to tag or untag, see updateTags
+	tagsMu            sync.Mutex
+
+	// Maintenance windows
+	windows    []models.MaintenanceWindow // Times changes may be made (nil means any time)
+	now        func() time.Time           // Clock the maintenance windows are checked against
+	windowOnce sync.Once                  // Logs and records the first change deferred by the windows
+	deferred   atomic.Bool                // A change came up outside the maintenance windows
 }
 
 // NewCleanupService creates a new cleanup service
@@ -56,9 +118,56 @@ func NewCleanupService(
 		dryRun:           dryRun,
 		qualityProfileID: 12,    // Default quality profile ID
 		addMissingMovies: false, // Default to disabled
+		clock:            runClock{counter: callCounterOf(client)},
 	}
 }
 
+// CleanupOptions holds the tunable settings for a cleanup service
+type CleanupOptions struct {
+	RequestDelay         time.Duration
+	ConcurrentLimit      int
+	EpisodeConcurrency   int // Episodes of a series checked at once (0 means min(ConcurrentLimit, 3))
+	MovieConcurrency     int // Movies checked at once (0 means ConcurrentLimit)
+	DryRun               bool
+	QualityProfileID     int                  // Quality profile ID for adding movies/series
+	AddMissingMovies     bool                 // Whether to add missing movies/series from broken symlinks to collection
+	Scope                *ActionScope         // Restricts changes to a reviewed dry-run artifact (nil means no restriction)
+	SearchGate           SearchGate           // Consulted before triggering missing searches (nil means always search)
+	Seasons              []int                // Season numbers to restrict series cleanup to (empty means all seasons)
+	VerifyOnly           bool                 // Check files and sizes without any writes; implies DryRun
+	APIBudget            int                  // API calls allowed before remaining changes are only reported (0 means unlimited)
+	SpillAfter           int                  // Missing file entries kept in memory before spilling to a temp file (0 means the default, negative never spills)
+	MediaCache           *MediaCache          // Library cached between runs (nil means the library is fetched every run)
+	SkipSpecials         bool                 // Leave season 0 (specials) out of series cleanup unless Seasons selects it
+	PathParser           *models.PathParser   // Parses IDs from broken symlink paths (nil means the default [tmdb-N]/[tvdb-N] tags)
+	TitleMatchConfidence float64              // Confidence (0-1) a title lookup match of a path without IDs needs before the item is added (0 means the default)
+	MonitorCollections   bool                 // Monitor the Radarr collection of movies added from broken symlinks
+	ValidateAdds         bool                 // In dry runs, check that each planned add of a movie/series would succeed
+	DeleteEmptyPaths     bool                 // Treat file records without a path as broken and delete them instead of skipping them
+	OutsideRootPolicy    string               // File records outside the root folders: config.OutsideRootSkip (default), OutsideRootDelete or OutsideRootRewrite
+	PathMappings         []models.PathMapping // Rules the rewrite policy applies to series and movie folders
+	Checksums            map[string]string    // SHA-256 sums by path that verify runs compare files with (nil means no checksums)
+
+	// PreviouslyMissing are the files earlier runs found missing, reported as recovered once they have a valid file
+	PreviouslyMissing []models.MissingFileEntry
+	// PreviouslyTruncated is set when the history left out some of the files earlier runs found
+	// missing, so an item's files can't be known to be back and tags stay on
+	PreviouslyTruncated bool
+
+	// Instance names the extra instance of the service the client talks to, for the report
+	// (empty for the default one)
+	Instance string
+
+	// Tag is the label of a tag applied to the series and movies whose file records were
+	// deleted, so they can be filtered on in Sonarr and Radarr (empty means no tagging). The tag
+	// is removed again once a later run finds their files back.
+	Tag string
+
+	// MaintenanceWindows are the times changes may be made. Outside them changes are deferred:
+	// only reported, like over the API budget (empty means any time).
+	MaintenanceWindows []models.MaintenanceWindow
+}
+
 // NewCleanupServiceWithConcurrency creates a new cleanup service with configurable concurrency
 func NewCleanupServiceWithConcurrency(
 	client Client,
@@ -71,16 +180,77 @@ func NewCleanupServiceWithConcurrency(
 	qualityProfileID int,
 	addMissingMovies bool,
 ) CleanupService {
+	return NewCleanupServiceWithOptions(client, fileChecker, logger, progressReporter, CleanupOptions{
+		RequestDelay:     requestDelay,
+		ConcurrentLimit:  concurrentLimit,
+		DryRun:           dryRun,
+		QualityProfileID: qualityProfileID,
+		AddMissingMovies: addMissingMovies,
+	})
+}
+
+// NewCleanupServiceWithOptions creates a new cleanup service from a set of options
+func NewCleanupServiceWithOptions(
+	client Client,
+	fileChecker FileChecker,
+	logger Logger,
+	progressReporter ProgressReporter,
+	opts CleanupOptions,
+) CleanupService {
+	var seasons map[int]bool
+	if len(opts.Seasons) > 0 {
+		seasons = make(map[int]bool, len(opts.Seasons))
+		for _, season := range opts.Seasons {
+			seasons[season] = true
+		}
+	}
+
+	// Take the counter before any wrapping hides it
+	counter := callCounterOf(client)
+
+	if opts.VerifyOnly {
+		// Guard the API and filesystem so a verify run cannot write even if a code path misses the dry-run flag
+		client = NewReadOnlyClient(client)
+		fileChecker = NewReadOnlyFileChecker(fileChecker)
+		opts.DryRun = true
+	}
+
 	return &CleanupServiceImpl{
-		client:           client,
-		fileChecker:      fileChecker,
-		logger:           logger,
-		progressReporter: progressReporter,
-		requestDelay:     requestDelay,
-		concurrentLimit:  concurrentLimit,
-		dryRun:           dryRun,
-		qualityProfileID: qualityProfileID,
-		addMissingMovies: addMissingMovies,
+		client:            client,
+		fileChecker:       fileChecker,
+		logger:            logger,
+		progressReporter:  progressReporter,
+		requestDelay:      opts.RequestDelay,
+		concurrentLimit:   opts.ConcurrentLimit,
+		episodeLimit:      opts.EpisodeConcurrency,
+		movieLimit:        opts.MovieConcurrency,
+		dryRun:            opts.DryRun,
+		qualityProfileID:  opts.QualityProfileID,
+		addMissingMovies:  opts.AddMissingMovies,
+		scope:             opts.Scope,
+		searchGate:        opts.SearchGate,
+		seasons:           seasons,
+		targeted:          seasons != nil,
+		verify:            opts.VerifyOnly,
+		clock:             runClock{counter: counter},
+		apiBudget:         opts.APIBudget,
+		spillAfter:        opts.SpillAfter,
+		mediaCache:        opts.MediaCache,
+		skipSpecials:      opts.SkipSpecials,
+		pathParser:        opts.PathParser,
+		titleConfidence:   opts.TitleMatchConfidence,
+		addCollections:    opts.MonitorCollections,
+		validateAdds:      opts.ValidateAdds,
+		deleteEmptyPaths:  opts.DeleteEmptyPaths,
+		outsideRootPolicy: opts.OutsideRootPolicy,
+		pathMappings:      opts.PathMappings,
+		checksums:         opts.Checksums,
+		missingBefore:     newMissingBefore(opts.PreviouslyMissing),
+		missingBeforeGaps: opts.PreviouslyTruncated,
+		instance:          opts.Instance,
+		tag:               opts.Tag,
+		windows:           opts.MaintenanceWindows,
+		now:               time.Now,
 	}
 }
 
@@ -89,7 +259,15 @@ func NewCleanupServiceWithConcurrency(
 func (s *CleanupServiceImpl) addMissingFileEntry(entry models.MissingFileEntry) {
 	s.missingFilesMu.Lock()
 	defer s.missingFilesMu.Unlock()
-	s.missingFiles = append(s.missingFiles, entry)
+	if s.missingFiles == nil {
+		s.missingFiles = newMissingFileSpool(s.spillAfter)
+	}
+	wasSpilled := s.missingFiles.isSpilled()
+	if err := s.missingFiles.add(entry); err != nil {
+		s.logger.Warn("Failed to spill missing files to disk: %s", err.Error())
+	} else if !wasSpilled && s.missingFiles.isSpilled() {
+		s.logger.Info("💾 Over %d missing files: spilling them to a temporary file", s.missingFiles.limit)
+	}
 }
 
 // deduplicateMissingFiles removes duplicate entries, prioritizing those with real FileIDs
@@ -98,35 +276,10 @@ func (s *CleanupServiceImpl) deduplicateMissingFiles(entries []models.MissingFil
 	entryMap := make(map[string]models.MissingFileEntry)
 
 	for _, entry := range entries {
-		// Create a unique key for deduplication
-		var key string
-		if entry.MediaType == "movie" && entry.TMDBID > 0 {
-			// For movies with TMDB ID, use TMDB ID as primary key
-			key = fmt.Sprintf("movie-tmdb-%d", entry.TMDBID)
-		} else if entry.MediaType == "series" && entry.TVDBID > 0 {
-			// For series with TVDB ID, use TVDB ID as primary key
-			key = fmt.Sprintf("series-tvdb-%d", entry.TVDBID)
-		} else {
-			// For series or movies without TMDB/TVDB ID, use file path
-			key = fmt.Sprintf("%s-path-%s", entry.MediaType, entry.FilePath)
-		}
+		key := missingFileKey(entry)
 
 		// Check if we already have an entry for this key
-		if existing, exists := entryMap[key]; exists {
-			// Prioritize entry with real FileID (> 0) over broken symlink entries (FileID = 0)
-			if entry.FileID > 0 && existing.FileID == 0 {
-				entryMap[key] = entry
-			} else if entry.FileID == 0 && existing.FileID > 0 {
-				// Keep existing entry (which has real FileID)
-				continue
-			} else {
-				// Both have same FileID type, keep the more recent one
-				if entry.ProcessedAt > existing.ProcessedAt {
-					entryMap[key] = entry
-				}
-			}
-		} else {
-			// First entry for this key
+		if existing, exists := entryMap[key]; !exists || preferMissingFile(entry, existing) {
 			entryMap[key] = entry
 		}
 	}
@@ -145,26 +298,364 @@ func (s *CleanupServiceImpl) deduplicateMissingFiles(entries []models.MissingFil
 	return deduplicated
 }
 
+// missingFileKey returns the identity used to deduplicate missing file entries
+func missingFileKey(entry models.MissingFileEntry) string {
+	if entry.MediaType == "movie" && entry.TMDBID > 0 {
+		// For movies with TMDB ID, use TMDB ID as primary key
+		return fmt.Sprintf("movie-tmdb-%d", entry.TMDBID)
+	}
+	if entry.MediaType == "series" && entry.TVDBID > 0 {
+		// Broken symlinks of a series get an entry per episode, or per path when the episode
+		// isn't known
+		if entry.Season != nil && entry.Episode != nil {
+			return fmt.Sprintf("series-tvdb-%d-s%d-e%d", entry.TVDBID, *entry.Season, *entry.Episode)
+		}
+		return fmt.Sprintf("series-tvdb-%d-path-%s", entry.TVDBID, entry.FilePath)
+	}
+	// For series or movies without TMDB/TVDB ID, use file path
+	return fmt.Sprintf("%s-path-%s", entry.MediaType, entry.FilePath)
+}
+
+// preferMissingFile reports whether entry should replace existing, an entry with the same key
+func preferMissingFile(entry, existing models.MissingFileEntry) bool {
+	// Prioritize entry with real FileID (> 0) over broken symlink entries (FileID = 0)
+	if (entry.FileID > 0) != (existing.FileID > 0) {
+		return entry.FileID > 0
+	}
+	// Both have same FileID type, keep the more recent one
+	return entry.ProcessedAt > existing.ProcessedAt
+}
+
 // buildReport creates a missing files report from collected data
 func (s *CleanupServiceImpl) buildReport() *models.MissingFilesReport {
 	s.missingFilesMu.Lock()
 	defer s.missingFilesMu.Unlock()
 
 	runType := "real-run"
-	if s.dryRun {
+	if s.verify {
+		runType = "verify"
+	} else if s.dryRun {
 		runType = "dry-run"
 	}
 
+	var timing models.CleanupStats
+	s.clock.stamp(&timing)
+
+	report := &models.MissingFilesReport{
+		GeneratedAt:   time.Now().Format(time.RFC3339),
+		RunType:       runType,
+		ServiceType:   s.client.GetName(),
+		Instance:      s.instance,
+		SearchSkipped: s.searchSkipped,
+		Timing:        timing.Timing(),
+		APIBudget:     s.apiBudget,
+		OverBudget:    s.overBudget(),
+		OutsideWindow: s.deferred.Load(),
+	}
+	if report.OutsideWindow {
+		if next, ok := models.NextMaintenanceWindow(s.windows, s.now()); ok {
+			report.NextWindow = next.Format(time.RFC3339)
+		}
+	}
+
 	// Deduplicate missing files before building the report
-	deduplicatedFiles := s.deduplicateMissingFiles(s.missingFiles)
+	switch {
+	case s.missingFiles == nil:
+		report.MissingFiles = s.deduplicateMissingFiles(nil)
+	case s.missingFiles.isSpilled():
+		// The report takes over the spill file and streams the entries from it
+		spilled, err := s.missingFiles.deduplicated()
+		if err != nil {
+			s.logger.Error("Failed to read spilled missing files: %s", err.Error())
+			s.missingFiles.close()
+		} else {
+			report.Spilled = spilled
+			report.TotalMissing = spilled.Len()
+		}
+		s.missingFiles = nil
+	default:
+		report.MissingFiles = s.deduplicateMissingFiles(s.missingFiles.entries)
+	}
+	if report.Spilled == nil {
+		report.TotalMissing = len(report.MissingFiles)
+	}
+	report.MostAffected = mostAffected(report, mostAffectedLimit)
+	report.Recovered = s.recoveredFiles()
+	report.OutsideRootFolders = s.outsideRootEntries()
+	if mttr, n := models.MeanTimeToRecovery(report.Recovered); n > 0 {
+		report.MeanTimeToRecoveryMs = mttr.Milliseconds()
+	}
+
+	return report
+}
+
+// mostAffectedLimit is how many series or movies a report's most affected list names
+const mostAffectedLimit = 10
+
+// mostAffected returns the series or movies with the most entries in the report, most first,
+// so it's clear at a glance whether one folder vanished or the losses are spread around
+func mostAffected(report *models.MissingFilesReport, limit int) []models.AffectedItem {
+	type mediaKey struct{ mediaType, mediaName string }
+	counts := make(map[mediaKey]int)
+	err := report.EachMissingFile(func(entry models.MissingFileEntry) error {
+		counts[mediaKey{entry.MediaType, entry.MediaName}]++
+		return nil
+	})
+	if err != nil || len(counts) == 0 {
+		return nil
+	}
+
+	items := make([]models.AffectedItem, 0, len(counts))
+	for key, count := range counts {
+		items = append(items, models.AffectedItem{MediaType: key.mediaType, MediaName: key.mediaName, Missing: count})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Missing != items[j].Missing {
+			return items[i].Missing > items[j].Missing
+		}
+		return items[i].MediaName < items[j].MediaName
+	})
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	return items
+}
+
+// overBudget reports whether the run has used up its API budget. From then on the run is
+// report-only: changes are recorded as planned actions instead of being made.
+func (s *CleanupServiceImpl) overBudget() bool {
+	if s.apiBudget <= 0 || s.clock.calls() < s.apiBudget {
+		return false
+	}
+
+	s.budgetOnce.Do(func() {
+		s.logger.Warn("📉 API budget of %d calls reached: switching to report-only mode", s.apiBudget)
+	})
+	return true
+}
+
+// outsideWindow reports whether it's outside every maintenance window. Changes that come up
+// then are deferred: recorded as planned actions for a later run, like in report-only mode.
+func (s *CleanupServiceImpl) outsideWindow() bool {
+	if len(s.windows) == 0 || models.InMaintenanceWindow(s.windows, s.now()) {
+		return false
+	}
+
+	s.windowOnce.Do(func() {
+		s.deferred.Store(true)
+		s.logger.Warn("🕒 Outside the maintenance windows: deferring changes, they are only reported")
+	})
+	return true
+}
+
+// reportOnly reports whether changes should be recorded rather than made, because of
+// dry-run mode, an exhausted API budget or being outside the maintenance windows
+func (s *CleanupServiceImpl) reportOnly() bool {
+	return s.dryRun || s.overBudget() || s.outsideWindow()
+}
+
+// episodeConcurrency returns how many episodes of a series are checked at once. Unless it was
+// configured, it's a smaller limit than for series, to avoid overwhelming the API.
+func (s *CleanupServiceImpl) episodeConcurrency() int {
+	if s.episodeLimit > 0 {
+		return s.episodeLimit
+	}
+	return min(s.concurrentLimit, 3)
+}
+
+// movieConcurrency returns how many movies are checked at once
+func (s *CleanupServiceImpl) movieConcurrency() int {
+	if s.movieLimit > 0 {
+		return s.movieLimit
+	}
+	return s.concurrentLimit
+}
+
+// startPhase tells the progress reporter, if it follows phases, that the run moved on to phase
+func (s *CleanupServiceImpl) startPhase(phase string, total int) {
+	if reporter, ok := s.progressReporter.(PhaseReporter); ok {
+		reporter.StartPhase(phase, total)
+	}
+}
+
+// finishItem tells the progress reporter, if it follows phases, that an item was processed
+func (s *CleanupServiceImpl) finishItem() {
+	if reporter, ok := s.progressReporter.(PhaseReporter); ok {
+		reporter.FinishItem()
+	}
+}
+
+// finishStats stamps the run's duration, phase timings and API call count onto stats
+func (s *CleanupServiceImpl) finishStats(stats models.CleanupStats) models.CleanupStats {
+	s.clock.stamp(&stats)
+	return stats
+}
+
+// sizeMismatch reports whether a file differs in size from the size recorded by the service,
+// along with the size on disk. Sizes are only compared in verify runs and when a size is recorded.
+func (s *CleanupServiceImpl) sizeMismatch(path string, expected int64) (int64, bool) {
+	if !s.verify || expected <= 0 {
+		return 0, false
+	}
+
+	actual, err := s.fileChecker.FileSize(path)
+	if err != nil {
+		s.logger.Warn("    ⚠️  Failed to read size of %s: %s", path, err.Error())
+		return 0, false
+	}
+	return actual, actual != expected
+}
+
+// checksumMismatch compares the checksum of a file with the one the checksum manifest lists,
+// and returns both along with whether they differ. Checksums are only compared in verify runs,
+// for files the manifest lists, when the file checker can compute them.
+func (s *CleanupServiceImpl) checksumMismatch(path string) (string, string, bool) {
+	// The manifest's paths are cleaned, and so must be the record's to find its entry
+	expected, listed := s.checksums[filepath.Clean(path)]
+	if !s.verify || !listed {
+		return "", "", false
+	}
+	hasher, ok := s.fileChecker.(FileHasher)
+	if !ok {
+		return "", "", false
+	}
+
+	actual, err := hasher.FileSHA256(path)
+	if err != nil {
+		s.logger.Warn("    ⚠️  Failed to checksum %s: %s", path, err.Error())
+		return "", "", false
+	}
+	return expected, actual, !strings.EqualFold(actual, expected)
+}
+
+// missingReason returns why the file at path is missing, as far as the file checker can tell
+func (s *CleanupServiceImpl) missingReason(path string) string {
+	if path == "" {
+		return models.ReasonEmptyPath
+	}
+	if classifier, ok := s.fileChecker.(MissingReasonClassifier); ok {
+		return classifier.MissingReason(path)
+	}
+	return models.ReasonNotFound
+}
+
+// mismatchReason returns the reason for a file of the wrong size
+func mismatchReason(actual int64) string {
+	if actual == 0 {
+		return models.ReasonZeroByte
+	}
+	return models.ReasonSizeMismatch
+}
+
+// countFailure counts a failed request as a warning when it may succeed on a later run, e.g.
+// a timeout or rate limit, and as an error otherwise
+func countFailure(stats *models.CleanupStats, err error) {
+	if IsRetryable(err) {
+		stats.Warnings++
+		return
+	}
+	stats.Errors++
+}
+
+// skipsSeason reports whether the season is left out as specials. Explicitly selected seasons
+// and episodes are always processed.
+func (s *CleanupServiceImpl) skipsSeason(season int) bool {
+	return s.skipSpecials && season == 0 && !s.seasons[0] && s.episodeIDs == nil
+}
+
+// specialsFolderPattern matches the season folder Sonarr puts specials in
+var specialsFolderPattern = regexp.MustCompile(`(?i)^(specials|season 0+)$`)
+
+// isSpecialsPath reports whether a file is inside a series' specials folder or named as a
+// season 0 episode
+func isSpecialsPath(path string) bool {
+	if specialsFolderPattern.MatchString(filepath.Base(filepath.Dir(path))) {
+		return true
+	}
+	ref, err := models.ParseEpisodeFromPath(path)
+	return err == nil && len(ref.Episodes) > 0 && ref.Season == 0
+}
+
+// seasonList returns the selected seasons as a sorted, comma-separated list
+func (s *CleanupServiceImpl) seasonList() string {
+	seasons := make([]int, 0, len(s.seasons))
+	for season := range s.seasons {
+		seasons = append(seasons, season)
+	}
+	sort.Ints(seasons)
+
+	parts := make([]string, len(seasons))
+	for i, season := range seasons {
+		parts[i] = fmt.Sprintf("%d", season)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// triggerSearch triggers the missing search unless the search gate refuses it. It returns a
+// message for the result when the search was skipped or failed.
+func (s *CleanupServiceImpl) triggerSearch(ctx context.Context) string {
+	defer s.clock.track(phaseRefresh, time.Now())
+	s.startPhase(PhaseSearching, 0)
+
+	if s.overBudget() {
+		s.searchSkipped = fmt.Sprintf("API budget of %d calls exceeded", s.apiBudget)
+		s.logger.Warn("⏸️  Deferring missing search to a later run: %s", s.searchSkipped)
+		return fmt.Sprintf("Missing search deferred: %s", s.searchSkipped)
+	}
+	if s.outsideWindow() {
+		s.searchSkipped = "outside the maintenance windows"
+		s.logger.Warn("⏸️  Deferring missing search to a later run: %s", s.searchSkipped)
+		return fmt.Sprintf("Missing search deferred: %s", s.searchSkipped)
+	}
+
+	if s.searchGate != nil {
+		allowed, reason, err := s.searchGate.SearchAllowed(ctx)
+		if err != nil {
+			// Don't let an unreachable health source block searches
+			s.logger.Warn("Failed to check indexer health, searching anyway: %s", err.Error())
+		} else if !allowed {
+			s.searchSkipped = reason
+			s.logger.Warn("⏸️  Deferring missing search to a later run: %s", reason)
+			return fmt.Sprintf("Missing search deferred: %s", reason)
+		}
+	}
+
+	if err := s.client.TriggerRefresh(ctx); err != nil {
+		s.logger.Warn("Failed to trigger refresh: %s", err.Error())
+		return fmt.Sprintf("Failed to trigger refresh: %s", err.Error())
+	}
+	return ""
+}
 
-	return &models.MissingFilesReport{
-		GeneratedAt:  time.Now().Format(time.RFC3339),
-		RunType:      runType,
-		ServiceType:  s.client.GetName(),
-		TotalMissing: len(deduplicatedFiles),
-		MissingFiles: deduplicatedFiles,
+// addPlannedAction records an action that was skipped because of dry-run mode
+func (s *CleanupServiceImpl) addPlannedAction(action models.PlannedAction) {
+	s.missingFilesMu.Lock()
+	defer s.missingFilesMu.Unlock()
+	s.plannedActions = append(s.plannedActions, action)
+}
+
+// buildActions returns the planned actions in a stable order
+func (s *CleanupServiceImpl) buildActions() []models.PlannedAction {
+	s.missingFilesMu.Lock()
+	defer s.missingFilesMu.Unlock()
+
+	if len(s.plannedActions) == 0 {
+		return nil
 	}
+
+	actions := make([]models.PlannedAction, len(s.plannedActions))
+	copy(actions, s.plannedActions)
+	sort.SliceStable(actions, func(i, j int) bool {
+		if actions[i].Action != actions[j].Action {
+			return actions[i].Action < actions[j].Action
+		}
+		if actions[i].Path != actions[j].Path {
+			return actions[i].Path < actions[j].Path
+		}
+		return actions[i].FileID < actions[j].FileID
+	})
+
+	return actions
 }
 
 // setSeriesInfo safely sets series information
@@ -184,6 +675,9 @@ func (s *CleanupServiceImpl) getSeriesInfo(seriesID int) string {
 	if name, exists := s.seriesInfo[seriesID]; exists {
 		return name
 	}
+	if name, ok := s.mediaCache.Title(seriesID); ok {
+		return name
+	}
 	return fmt.Sprintf("Series %d", seriesID)
 }
 
@@ -204,10 +698,75 @@ func (s *CleanupServiceImpl) getMovieInfo(movieID int) string {
 	if name, exists := s.movieInfo[movieID]; exists {
 		return name
 	}
+	if name, ok := s.mediaCache.Title(movieID); ok {
+		return name
+	}
 	return fmt.Sprintf("Movie %d", movieID)
 }
 
-func (s *CleanupServiceImpl) CleanupMissingFiles(ctx context.Context) (*models.CleanupResult, error) {
+// fetchAllSeries returns every series, from the media cache while it is fresh
+func (s *CleanupServiceImpl) fetchAllSeries(ctx context.Context) ([]models.Series, error) {
+	if series, ok := s.mediaCache.Series(); ok {
+		s.logger.Info("📦 Using %d series cached %s ago (--refresh-cache fetches them again)", len(series), s.mediaCache.Age().Round(time.Second))
+		return series, nil
+	}
+
+	defer s.clock.track(phaseFetch, time.Now())
+	series, err := s.client.GetAllSeries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.mediaCache.SetSeries(series)
+	s.saveMediaCache()
+	return series, nil
+}
+
+// fetchAllMovies returns every movie, from the media cache while it is fresh
+func (s *CleanupServiceImpl) fetchAllMovies(ctx context.Context) ([]models.Movie, error) {
+	if movies, ok := s.mediaCache.Movies(); ok {
+		s.logger.Info("📦 Using %d movies cached %s ago (--refresh-cache fetches them again)", len(movies), s.mediaCache.Age().Round(time.Second))
+		return movies, nil
+	}
+
+	defer s.clock.track(phaseFetch, time.Now())
+	movies, err := s.client.GetAllMovies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.mediaCache.SetMovies(movies)
+	s.saveMediaCache()
+	return movies, nil
+}
+
+// seriesByTVDBID returns the series in the collection with the TVDB ID, checking the media
+// cache before asking Sonarr
+func (s *CleanupServiceImpl) seriesByTVDBID(ctx context.Context, tvdbID int) (*models.Series, error) {
+	if series, ok := s.mediaCache.SeriesByTVDBID(tvdbID); ok {
+		return series, nil
+	}
+	return s.client.GetSeriesByTVDBID(ctx, tvdbID)
+}
+
+// movieByTMDBID returns the movie in the collection with the TMDB ID, checking the media
+// cache before asking Radarr
+func (s *CleanupServiceImpl) movieByTMDBID(ctx context.Context, tmdbID int) (*models.Movie, error) {
+	if movie, ok := s.mediaCache.MovieByTMDBID(tmdbID); ok {
+		return movie, nil
+	}
+	return s.client.GetMovieByTMDBID(ctx, tmdbID)
+}
+
+// saveMediaCache writes the media cache to disk, if there is one
+func (s *CleanupServiceImpl) saveMediaCache() {
+	if err := s.mediaCache.Save(); err != nil {
+		s.logger.Warn("Failed to save media cache: %s", err.Error())
+	}
+}
+
+func (s *CleanupServiceImpl) CleanupMissingFiles(ctx context.Context) (result *models.CleanupResult, err error) {
+	defer s.recoverPanic(&err)
+
+	s.clock.start()
 	s.logger.Info("Starting %s missing file cleanup...", s.client.GetName())
 	s.logger.Info("================================================")
 
@@ -217,15 +776,20 @@ func (s *CleanupServiceImpl) CleanupMissingFiles(ctx context.Context) (*models.C
 	}
 
 	// Test connection first
+	s.startPhase(PhaseConnecting, 0)
 	if err := s.client.TestConnection(ctx); err != nil {
-		return nil, fmt.Errorf("connection test failed: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrConnectionFailed, err)
+	}
+	if err := s.validateAddSettings(ctx); err != nil {
+		return nil, err
 	}
 
 	// Handle based on client type
 	if s.client.GetName() == "sonarr" {
 		// Get all series
 		s.logger.Info("Step 1: Fetching all series...")
-		series, err := s.client.GetAllSeries(ctx)
+		s.startPhase(PhaseFetching, 0)
+		series, err := s.fetchAllSeries(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("failed to fetch series: %w", err)
 		}
@@ -233,27 +797,38 @@ func (s *CleanupServiceImpl) CleanupMissingFiles(ctx context.Context) (*models.C
 		if len(series) == 0 {
 			s.logger.Info("No series found")
 			return &models.CleanupResult{
-				Stats:   models.CleanupStats{},
+				Stats:   s.finishStats(models.CleanupStats{}),
 				Success: true,
 				Report:  s.buildReport(),
+				Actions: s.buildActions(),
 			}, nil
 		}
 
 		s.logger.Info("Found %d series", len(series))
 
-		// Store series information and extract series IDs
+		// Store series information and extract series IDs. Series whose statistics report
+		// no episode files have nothing to clean up, so their episodes aren't fetched.
 		var seriesIDs []int
+		withoutFiles := 0
 		for _, series := range series {
 			s.setSeriesInfo(series.ID, series.Title)
+			if series.EpisodeFileCount != nil && *series.EpisodeFileCount == 0 {
+				withoutFiles++
+				continue
+			}
 			seriesIDs = append(seriesIDs, series.ID)
 		}
+		if withoutFiles > 0 {
+			s.logger.Info("Skipping %d series without episode files", withoutFiles)
+		}
 
 		// Cleanup specific series
 		return s.CleanupMissingFilesForSeries(ctx, seriesIDs)
 	} else if s.client.GetName() == "radarr" {
 		// Get all movies
 		s.logger.Info("Step 1: Fetching all movies...")
-		movies, err := s.client.GetAllMovies(ctx)
+		s.startPhase(PhaseFetching, 0)
+		movies, err := s.fetchAllMovies(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("failed to fetch movies: %w", err)
 		}
@@ -261,30 +836,48 @@ func (s *CleanupServiceImpl) CleanupMissingFiles(ctx context.Context) (*models.C
 		if len(movies) == 0 {
 			s.logger.Info("No movies found")
 			return &models.CleanupResult{
-				Stats:   models.CleanupStats{},
+				Stats:   s.finishStats(models.CleanupStats{}),
 				Success: true,
 				Report:  s.buildReport(),
+				Actions: s.buildActions(),
 			}, nil
 		}
 
 		s.logger.Info("Found %d movies", len(movies))
 
-		// Store movie information and extract movie IDs
+		// Store movie information and extract movie IDs. Movies without a file have nothing
+		// to clean up, so they aren't fetched again.
 		var movieIDs []int
+		withoutFiles := 0
 		for _, movie := range movies {
 			s.setMovieInfo(movie.ID, movie.Title)
+			if !movie.HasFile {
+				withoutFiles++
+				continue
+			}
 			movieIDs = append(movieIDs, movie.ID)
 		}
+		if withoutFiles > 0 {
+			s.logger.Info("Skipping %d movies without a file", withoutFiles)
+		}
 
 		// Cleanup specific movies
 		return s.CleanupMissingFilesForMovies(ctx, movieIDs)
+	} else if s.client.GetName() == "readarr" {
+		return s.cleanupAllBooks(ctx)
 	}
 
 	return nil, fmt.Errorf("unsupported client type: %s", s.client.GetName())
 }
 
 // CleanupMissingFilesForSeries performs cleanup for specific series using concurrent processing
-func (s *CleanupServiceImpl) CleanupMissingFilesForSeries(ctx context.Context, seriesIDs []int) (*models.CleanupResult, error) {
+func (s *CleanupServiceImpl) CleanupMissingFilesForSeries(ctx context.Context, seriesIDs []int) (result *models.CleanupResult, err error) {
+	defer s.recoverPanic(&err)
+
+	s.clock.start()
+	if err := s.validateAddSettings(ctx); err != nil {
+		return nil, err
+	}
 	stats := models.CleanupStats{}
 	var messages []string
 	var mu sync.Mutex
@@ -292,24 +885,44 @@ func (s *CleanupServiceImpl) CleanupMissingFilesForSeries(ctx context.Context, s
 	seriesCount := len(seriesIDs)
 	s.logger.Info("Processing %d series with concurrency limit of %d", seriesCount, s.concurrentLimit)
 
-	// Handle broken symlinks if this is a Sonarr client
-	if s.client.GetName() == "sonarr" {
+	// Handle broken symlinks if this is a Sonarr client. A targeted run only touches
+	// the selected episode records, so the library-wide symlink scan is skipped.
+	if s.seasons != nil {
+		s.logger.Info("Limiting cleanup to season(s) %s", s.seasonList())
+	}
+	if s.skipsSeason(0) {
+		s.logger.Info("Skipping season 0 (specials)")
+	}
+	if s.targeted {
+		s.logger.Info("Targeted run: skipping broken symlink scan")
+	} else if s.client.GetName() == "sonarr" {
 		s.logger.Info("Step 1.5: Checking for broken symlinks and missing series...")
+		s.startPhase(PhaseSymlinkScan, 0)
+		scanStart := time.Now()
 		symlinkStats, err := s.handleBrokenSymlinksForSeries(ctx)
+		s.clock.track(phaseSymlinkScan, scanStart)
 		if err != nil {
 			s.logger.Warn("Broken symlink handling failed: %s", err.Error())
 			// Don't fail the entire operation, just add to messages
 			messages = append(messages, fmt.Sprintf("Broken symlink handling failed: %s", err.Error()))
+			stats.Warnings++
 		} else {
 			// Merge symlink stats into main stats
 			mu.Lock()
 			stats.TotalItemsChecked += symlinkStats.TotalItemsChecked
 			stats.MissingFiles += symlinkStats.MissingFiles
 			stats.Errors += symlinkStats.Errors
+			stats.Warnings += symlinkStats.Warnings
+			stats.Skipped += symlinkStats.Skipped
+			stats.SizeMismatches += symlinkStats.SizeMismatches
 			mu.Unlock()
 		}
 	}
 
+	// Cancelled on return, so the workers stop when a panic ends the run early
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	// Create worker pool for concurrent processing
 	semaphore := make(chan struct{}, s.concurrentLimit)
 	var wg sync.WaitGroup
@@ -323,6 +936,7 @@ func (s *CleanupServiceImpl) CleanupMissingFilesForSeries(ctx context.Context, s
 	resultsChan := make(chan seriesResult, seriesCount)
 
 	// Process each series concurrently
+	s.startPhase(PhaseChecking, seriesCount)
 	for i, seriesID := range seriesIDs {
 		wg.Add(1)
 		go func(seriesID, index int) {
@@ -331,6 +945,9 @@ func (s *CleanupServiceImpl) CleanupMissingFilesForSeries(ctx context.Context, s
 			// Acquire semaphore slot
 			semaphore <- struct{}{}
 			defer func() { <-semaphore }()
+			defer recoverWorker(func(err error) {
+				resultsChan <- seriesResult{seriesID: seriesID, err: err}
+			})
 
 			select {
 			case <-ctx.Done():
@@ -367,15 +984,30 @@ func (s *CleanupServiceImpl) CleanupMissingFilesForSeries(ctx context.Context, s
 	processedCount := 0
 	for result := range resultsChan {
 		processedCount++
+		s.finishItem()
 
 		if result.err != nil {
 			if result.err == ctx.Err() {
 				s.logger.Warn("Cleanup cancelled")
 				return &models.CleanupResult{
-					Stats:    stats,
+					Stats:    s.finishStats(stats),
 					Messages: messages,
 					Success:  false,
 					Report:   s.buildReport(),
+					Actions:  s.buildActions(),
+				}, result.err
+			}
+
+			// A panic leaves the pipeline in an unknown state, so the run stops with what it has
+			var panicErr *PanicError
+			if errors.As(result.err, &panicErr) {
+				s.logger.Error("💥 Processing series %d: %s", result.seriesID, panicErr.Error())
+				return &models.CleanupResult{
+					Stats:    s.finishStats(stats),
+					Messages: messages,
+					Success:  false,
+					Report:   s.buildReport(),
+					Actions:  s.buildActions(),
 				}, result.err
 			}
 
@@ -383,7 +1015,7 @@ func (s *CleanupServiceImpl) CleanupMissingFilesForSeries(ctx context.Context, s
 			s.progressReporter.ReportError(result.err)
 
 			mu.Lock()
-			stats.Errors++
+			countFailure(&stats, result.err)
 			messages = append(messages, fmt.Sprintf("Error processing series %d: %s", result.seriesID, result.err.Error()))
 			mu.Unlock()
 			continue
@@ -395,6 +1027,12 @@ func (s *CleanupServiceImpl) CleanupMissingFilesForSeries(ctx context.Context, s
 		stats.MissingFiles += result.stats.MissingFiles
 		stats.DeletedRecords += result.stats.DeletedRecords
 		stats.Errors += result.stats.Errors
+		stats.Warnings += result.stats.Warnings
+		stats.Skipped += result.stats.Skipped
+		stats.SizeMismatches += result.stats.SizeMismatches
+		stats.EmptyPaths += result.stats.EmptyPaths
+		stats.Corrupt += result.stats.Corrupt
+		stats.OutsideRoot += result.stats.OutsideRoot
 		mu.Unlock()
 	}
 
@@ -403,51 +1041,72 @@ func (s *CleanupServiceImpl) CleanupMissingFilesForSeries(ctx context.Context, s
 	// Report final statistics
 	s.progressReporter.Finish(stats)
 
+	messages = append(messages, s.updateTags(ctx, "series")...)
+
 	// Trigger refresh if we deleted any records
 	if stats.DeletedRecords > 0 && !s.dryRun {
-		if err := s.client.TriggerRefresh(ctx); err != nil {
-			s.logger.Warn("Failed to trigger refresh: %s", err.Error())
-			messages = append(messages, fmt.Sprintf("Failed to trigger refresh: %s", err.Error()))
+		if msg := s.triggerSearch(ctx); msg != "" {
+			messages = append(messages, msg)
 		}
 	}
 
 	return &models.CleanupResult{
-		Stats:    stats,
+		Stats:    s.finishStats(stats),
 		Messages: messages,
 		Success:  stats.Errors == 0,
 		Report:   s.buildReport(),
+		Actions:  s.buildActions(),
 	}, nil
 }
 
 // CleanupMissingFilesForMovies performs cleanup for specific movies using concurrent processing
-func (s *CleanupServiceImpl) CleanupMissingFilesForMovies(ctx context.Context, movieIDs []int) (*models.CleanupResult, error) {
+func (s *CleanupServiceImpl) CleanupMissingFilesForMovies(ctx context.Context, movieIDs []int) (result *models.CleanupResult, err error) {
+	defer s.recoverPanic(&err)
+
+	s.clock.start()
+	if err := s.validateAddSettings(ctx); err != nil {
+		return nil, err
+	}
 	stats := models.CleanupStats{}
 	var messages []string
 	var mu sync.Mutex
 
 	movieCount := len(movieIDs)
-	s.logger.Info("Processing %d movies with concurrency limit of %d", movieCount, s.concurrentLimit)
+	s.logger.Info("Processing %d movies with concurrency limit of %d", movieCount, s.movieConcurrency())
 
 	// Handle broken symlinks if this is a Radarr client
-	if s.client.GetName() == "radarr" {
+	if s.targeted {
+		s.logger.Info("Targeted run: skipping broken symlink scan")
+	} else if s.client.GetName() == "radarr" {
 		s.logger.Info("Step 1.5: Checking for broken symlinks and missing movies...")
+		s.startPhase(PhaseSymlinkScan, 0)
+		scanStart := time.Now()
 		symlinkStats, err := s.handleBrokenSymlinks(ctx)
+		s.clock.track(phaseSymlinkScan, scanStart)
 		if err != nil {
 			s.logger.Warn("Broken symlink handling failed: %s", err.Error())
 			// Don't fail the entire operation, just add to messages
 			messages = append(messages, fmt.Sprintf("Broken symlink handling failed: %s", err.Error()))
+			stats.Warnings++
 		} else {
 			// Merge symlink stats into main stats
 			mu.Lock()
 			stats.TotalItemsChecked += symlinkStats.TotalItemsChecked
 			stats.MissingFiles += symlinkStats.MissingFiles
 			stats.Errors += symlinkStats.Errors
+			stats.Warnings += symlinkStats.Warnings
+			stats.Skipped += symlinkStats.Skipped
+			stats.SizeMismatches += symlinkStats.SizeMismatches
 			mu.Unlock()
 		}
 	}
 
+	// Cancelled on return, so the workers stop when a panic ends the run early
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	// Create worker pool for concurrent processing
-	semaphore := make(chan struct{}, s.concurrentLimit)
+	semaphore := make(chan struct{}, s.movieConcurrency())
 	var wg sync.WaitGroup
 
 	// Channel for collecting results
@@ -459,6 +1118,7 @@ func (s *CleanupServiceImpl) CleanupMissingFilesForMovies(ctx context.Context, m
 	resultsChan := make(chan movieResult, movieCount)
 
 	// Process each movie concurrently
+	s.startPhase(PhaseChecking, movieCount)
 	for i, movieID := range movieIDs {
 		wg.Add(1)
 		go func(movieID, index int) {
@@ -467,6 +1127,9 @@ func (s *CleanupServiceImpl) CleanupMissingFilesForMovies(ctx context.Context, m
 			// Acquire semaphore slot
 			semaphore <- struct{}{}
 			defer func() { <-semaphore }()
+			defer recoverWorker(func(err error) {
+				resultsChan <- movieResult{movieID: movieID, err: err}
+			})
 
 			select {
 			case <-ctx.Done():
@@ -503,15 +1166,30 @@ func (s *CleanupServiceImpl) CleanupMissingFilesForMovies(ctx context.Context, m
 	processedCount := 0
 	for result := range resultsChan {
 		processedCount++
+		s.finishItem()
 
 		if result.err != nil {
 			if result.err == ctx.Err() {
 				s.logger.Warn("Cleanup cancelled")
 				return &models.CleanupResult{
-					Stats:    stats,
+					Stats:    s.finishStats(stats),
 					Messages: messages,
 					Success:  false,
 					Report:   s.buildReport(),
+					Actions:  s.buildActions(),
+				}, result.err
+			}
+
+			// A panic leaves the pipeline in an unknown state, so the run stops with what it has
+			var panicErr *PanicError
+			if errors.As(result.err, &panicErr) {
+				s.logger.Error("💥 Processing movie %d: %s", result.movieID, panicErr.Error())
+				return &models.CleanupResult{
+					Stats:    s.finishStats(stats),
+					Messages: messages,
+					Success:  false,
+					Report:   s.buildReport(),
+					Actions:  s.buildActions(),
 				}, result.err
 			}
 
@@ -519,7 +1197,7 @@ func (s *CleanupServiceImpl) CleanupMissingFilesForMovies(ctx context.Context, m
 			s.progressReporter.ReportError(result.err)
 
 			mu.Lock()
-			stats.Errors++
+			countFailure(&stats, result.err)
 			messages = append(messages, fmt.Sprintf("Error processing movie %d: %s", result.movieID, result.err.Error()))
 			mu.Unlock()
 			continue
@@ -531,6 +1209,12 @@ func (s *CleanupServiceImpl) CleanupMissingFilesForMovies(ctx context.Context, m
 		stats.MissingFiles += result.stats.MissingFiles
 		stats.DeletedRecords += result.stats.DeletedRecords
 		stats.Errors += result.stats.Errors
+		stats.Warnings += result.stats.Warnings
+		stats.Skipped += result.stats.Skipped
+		stats.SizeMismatches += result.stats.SizeMismatches
+		stats.EmptyPaths += result.stats.EmptyPaths
+		stats.Corrupt += result.stats.Corrupt
+		stats.OutsideRoot += result.stats.OutsideRoot
 		mu.Unlock()
 	}
 
@@ -539,29 +1223,192 @@ func (s *CleanupServiceImpl) CleanupMissingFilesForMovies(ctx context.Context, m
 	// Report final statistics
 	s.progressReporter.Finish(stats)
 
+	messages = append(messages, s.updateTags(ctx, "movies")...)
+
 	// Trigger refresh if we deleted any records
 	if stats.DeletedRecords > 0 && !s.dryRun {
-		if err := s.client.TriggerRefresh(ctx); err != nil {
-			s.logger.Warn("Failed to trigger refresh: %s", err.Error())
-			messages = append(messages, fmt.Sprintf("Failed to trigger refresh: %s", err.Error()))
+		if msg := s.triggerSearch(ctx); msg != "" {
+			messages = append(messages, msg)
 		}
 	}
 
 	return &models.CleanupResult{
-		Stats:    stats,
+		Stats:    s.finishStats(stats),
 		Messages: messages,
 		Success:  stats.Errors == 0,
 		Report:   s.buildReport(),
+		Actions:  s.buildActions(),
 	}, nil
 }
 
+// CleanupMissingFilesForEpisodes performs cleanup for specific episodes, leaving the rest of
+// their series alone
+func (s *CleanupServiceImpl) CleanupMissingFilesForEpisodes(ctx context.Context, episodeIDs []int) (result *models.CleanupResult, err error) {
+	defer s.recoverPanic(&err)
+
+	s.clock.start()
+	if s.client.GetName() != "sonarr" {
+		return nil, fmt.Errorf("episode targeting is not supported for %s", s.client.GetName())
+	}
+
+	s.episodeIDs = make(map[int]bool, len(episodeIDs))
+	var seriesIDs []int
+	seenSeries := make(map[int]bool)
+	for _, episodeID := range episodeIDs {
+		episode, err := s.client.GetEpisode(ctx, episodeID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve episode %d: %w", episodeID, err)
+		}
+		s.episodeIDs[episode.ID] = true
+		if !seenSeries[episode.SeriesID] {
+			seenSeries[episode.SeriesID] = true
+			seriesIDs = append(seriesIDs, episode.SeriesID)
+		}
+	}
+
+	s.targeted = true
+	s.logger.Info("Targeting %d episode(s) across %d series", len(s.episodeIDs), len(seriesIDs))
+	return s.CleanupMissingFilesForSeries(ctx, seriesIDs)
+}
+
+// CleanupMissingFileAtPath resolves the episode, movie or book record that owns path and cleans up
+// just that item. It returns ErrNoOwningRecord if no record in this service owns the path.
+func (s *CleanupServiceImpl) CleanupMissingFileAtPath(ctx context.Context, path string) (result *models.CleanupResult, err error) {
+	defer s.recoverPanic(&err)
+
+	s.clock.start()
+	path = filepath.Clean(path)
+	s.logger.Info("🔍 Resolving %s record for %s...", s.client.GetName(), path)
+
+	switch s.client.GetName() {
+	case "sonarr":
+		seriesID, episodeIDs, err := s.resolveEpisodePath(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		s.episodeIDs = make(map[int]bool, len(episodeIDs))
+		for _, episodeID := range episodeIDs {
+			s.episodeIDs[episodeID] = true
+		}
+		s.targeted = true
+		return s.CleanupMissingFilesForSeries(ctx, []int{seriesID})
+
+	case "radarr":
+		movieID, err := s.resolveMoviePath(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		s.targeted = true
+		return s.CleanupMissingFilesForMovies(ctx, []int{movieID})
+
+	case "readarr":
+		bookClient, err := s.bookClient()
+		if err != nil {
+			return nil, err
+		}
+		book, err := s.resolveBookPath(ctx, bookClient, path)
+		if err != nil {
+			return nil, err
+		}
+		s.targeted = true
+		return s.cleanupBooks(ctx, bookClient, []models.Book{*book})
+	}
+
+	return nil, fmt.Errorf("unsupported client type: %s", s.client.GetName())
+}
+
+// resolveEpisodePath finds the series and episodes whose episode file is path
+func (s *CleanupServiceImpl) resolveEpisodePath(ctx context.Context, path string) (int, []int, error) {
+	series, err := s.client.GetAllSeries(ctx)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to fetch series: %w", err)
+	}
+
+	for _, show := range series {
+		if !pathWithin(path, show.Path) {
+			continue
+		}
+
+		episodes, err := s.client.GetEpisodesForSeries(ctx, show.ID)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to get episodes for series %d: %w", show.ID, err)
+		}
+
+		// A multi-episode file is shared by several episodes, so look each file up once
+		filePaths := make(map[int]string)
+		var episodeIDs []int
+		for _, episode := range episodes {
+			if !episode.HasFile || episode.EpisodeFileID == nil {
+				continue
+			}
+			fileID := *episode.EpisodeFileID
+			filePath, seen := filePaths[fileID]
+			if !seen {
+				if file, err := s.client.GetEpisodeFile(ctx, fileID); err == nil {
+					filePath = filepath.Clean(file.Path)
+				}
+				filePaths[fileID] = filePath
+			}
+			if filePath == path {
+				episodeIDs = append(episodeIDs, episode.ID)
+			}
+		}
+
+		if len(episodeIDs) > 0 {
+			s.setSeriesInfo(show.ID, show.Title)
+			s.logger.Info("✅ %s is the file of %d episode(s) of %s", path, len(episodeIDs), show.Title)
+			return show.ID, episodeIDs, nil
+		}
+	}
+
+	return 0, nil, fmt.Errorf("%w: no sonarr episode file is %s", ErrNoOwningRecord, path)
+}
+
+// resolveMoviePath finds the movie whose movie file is path
+func (s *CleanupServiceImpl) resolveMoviePath(ctx context.Context, path string) (int, error) {
+	movies, err := s.client.GetAllMovies(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch movies: %w", err)
+	}
+
+	for _, movie := range movies {
+		if !movie.HasFile || movie.MovieFileID == nil || !pathWithin(path, movie.Path) {
+			continue
+		}
+
+		file, err := s.client.GetMovieFile(ctx, *movie.MovieFileID)
+		if err != nil {
+			s.logger.Debug("Could not get movie file %d: %s", *movie.MovieFileID, err.Error())
+			continue
+		}
+		if filepath.Clean(file.Path) == path {
+			s.setMovieInfo(movie.ID, movie.Title)
+			s.logger.Info("✅ %s is the file of %s", path, movie.Title)
+			return movie.ID, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: no radarr movie file is %s", ErrNoOwningRecord, path)
+}
+
+// pathWithin reports whether path is inside dir
+func pathWithin(path, dir string) bool {
+	if dir == "" {
+		return false
+	}
+	dir = filepath.Clean(dir)
+	return strings.HasPrefix(path, dir+string(filepath.Separator))
+}
+
 // cleanupSeries processes a single series
 func (s *CleanupServiceImpl) cleanupSeries(ctx context.Context, seriesID int) (models.CleanupStats, error) {
 	stats := models.CleanupStats{}
 
 	// Get episodes for this series
 	s.logger.Debug("Fetching episodes for series %d...", seriesID)
+	fetchStart := time.Now()
 	episodes, err := s.client.GetEpisodesForSeries(ctx, seriesID)
+	s.clock.track(phaseFetch, fetchStart)
 	if err != nil {
 		return stats, fmt.Errorf("failed to get episodes for series %d: %w", seriesID, err)
 	}
@@ -574,6 +1421,15 @@ func (s *CleanupServiceImpl) cleanupSeries(ctx context.Context, seriesID int) (m
 	// Process episodes that claim to have files concurrently
 	episodesWithFiles := make([]models.Episode, 0)
 	for _, episode := range episodes {
+		if s.seasons != nil && !s.seasons[episode.SeasonNumber] {
+			continue
+		}
+		if s.episodeIDs != nil && !s.episodeIDs[episode.ID] {
+			continue
+		}
+		if s.skipsSeason(episode.SeasonNumber) {
+			continue
+		}
 		if episode.HasFile && episode.EpisodeFileID != nil {
 			episodesWithFiles = append(episodesWithFiles, episode)
 		}
@@ -583,9 +1439,7 @@ func (s *CleanupServiceImpl) cleanupSeries(ctx context.Context, seriesID int) (m
 		return stats, nil
 	}
 
-	// Use a smaller concurrency limit for episodes within a series to avoid overwhelming the API
-	episodeConcurrency := min(s.concurrentLimit, 3)
-	episodeSemaphore := make(chan struct{}, episodeConcurrency)
+	episodeSemaphore := make(chan struct{}, s.episodeConcurrency())
 	var episodeWg sync.WaitGroup
 	var episodeMu sync.Mutex
 
@@ -606,6 +1460,9 @@ func (s *CleanupServiceImpl) cleanupSeries(ctx context.Context, seriesID int) (m
 			// Acquire semaphore slot
 			episodeSemaphore <- struct{}{}
 			defer func() { <-episodeSemaphore }()
+			defer recoverWorker(func(err error) {
+				episodeResultsChan <- episodeResult{episode: ep, err: err}
+			})
 
 			select {
 			case <-ctx.Done():
@@ -615,67 +1472,203 @@ func (s *CleanupServiceImpl) cleanupSeries(ctx context.Context, seriesID int) (m
 			}
 
 			episodeStats := models.CleanupStats{TotalItemsChecked: 1}
+			logger := WithItem(s.logger, ItemFields{Service: s.client.GetName(), SeriesID: ep.SeriesID, EpisodeID: ep.ID, FileID: *ep.EpisodeFileID})
 			s.progressReporter.StartEpisode(ep.ID, ep.SeasonNumber, ep.EpisodeNumber)
 
 			// Get episode file details
-			episodeFile, err := s.client.GetEpisodeFile(ctx, *ep.EpisodeFileID)
+			fetchStart := time.Now()
+			episodeFile, err := retryOnce(ctx, func() (*models.EpisodeFile, error) {
+				return s.client.GetEpisodeFile(ctx, *ep.EpisodeFileID)
+			})
+			s.clock.track(phaseFetch, fetchStart)
 			if err != nil {
 				// If episode file is not found, it might have been already deleted
 				// This is not an error condition - just skip this episode
-				if strings.Contains(strings.ToLower(err.Error()), "not found") {
-					s.logger.Info("    ℹ️  Episode file %d already deleted or not found", *ep.EpisodeFileID)
+				if errors.Is(err, ErrNotFound) {
+					logger.Info("    ℹ️  Episode file %d already deleted or not found", *ep.EpisodeFileID)
 					episodeResultsChan <- episodeResult{episode: ep, stats: episodeStats, err: nil}
 					return
 				}
-				s.logger.Warn("    ⚠️  Failed to get episode file %d: %s", *ep.EpisodeFileID, err.Error())
-				episodeStats.Errors++
+				logger.Warn("    ⚠️  Failed to get episode file %d: %s", *ep.EpisodeFileID, err.Error())
+				countFailure(&episodeStats, err)
 				episodeResultsChan <- episodeResult{episode: ep, stats: episodeStats, err: nil}
 				return
 			}
 
-			// Check if file exists
-			if episodeFile.Path == "" {
-				s.logger.Warn("    ⚠️  No file path found for episode file %d", *ep.EpisodeFileID)
-				episodeResultsChan <- episodeResult{episode: ep, stats: episodeStats, err: nil}
-				return
+			// Check if file exists. A record without a path is skipped, or with the delete
+			// empty path policy handled like a missing file.
+			emptyPath := episodeFile.Path == ""
+			if emptyPath {
+				episodeStats.EmptyPaths++
+				if !s.deleteEmptyPaths {
+					logger.Warn("    ⚠️  No file path found for episode file %d", *ep.EpisodeFileID)
+					episodeStats.Warnings++
+					episodeResultsChan <- episodeResult{episode: ep, stats: episodeStats, err: nil}
+					return
+				}
+				logger.Warn("    ⚠️  No file path found for episode file %d, treating the record as broken", *ep.EpisodeFileID)
+			}
+			logger = WithItem(logger, ItemFields{Path: episodeFile.Path})
+
+			// A record outside every root folder follows the outside root policy, and under the
+			// delete policy is handled like a missing file
+			outside := !emptyPath && s.outsideRootFolders(ctx, episodeFile.Path)
+			if outside {
+				season, episode := ep.SeasonNumber, ep.EpisodeNumber
+				entry := models.OutsideRootEntry{
+					MediaType:   "series",
+					MediaName:   s.getSeriesInfo(ep.SeriesID),
+					EpisodeName: ep.Title,
+					Season:      &season,
+					Episode:     &episode,
+					FilePath:    episodeFile.Path,
+					FileID:      *ep.EpisodeFileID,
+				}
+				if s.handleOutsideRoot(ctx, logger, entry, ep.SeriesID, &episodeStats) {
+					episodeResultsChan <- episodeResult{episode: ep, stats: episodeStats, err: nil}
+					return
+				}
 			}
 
-			if s.fileChecker.FileExists(episodeFile.Path) {
-				s.logger.Debug("    ✅ File exists: %s", episodeFile.Path)
+			verifyStart := time.Now()
+			exists := !emptyPath && !outside && s.fileChecker.FileExists(episodeFile.Path)
+			actual, mismatch := int64(0), false
+			if exists {
+				actual, mismatch = s.sizeMismatch(episodeFile.Path, episodeFile.Size)
+			}
+			expectedSum, actualSum, corrupt := "", "", false
+			if exists && !mismatch {
+				expectedSum, actualSum, corrupt = s.checksumMismatch(episodeFile.Path)
+			}
+			s.clock.track(phaseVerification, verifyStart)
+			s.noteFile(ep.SeriesID, exists && !mismatch && !corrupt)
+
+			if exists {
+				if mismatch {
+					logger.Warn("    ⚠️  Size mismatch: %s (expected %d bytes, found %d)", episodeFile.Path, episodeFile.Size, actual)
+					episodeStats.SizeMismatches++
+					season := ep.SeasonNumber
+					episode := ep.EpisodeNumber
+					s.addMissingFileEntry(models.MissingFileEntry{
+						MediaType:     "series",
+						MediaName:     s.getSeriesInfo(ep.SeriesID),
+						EpisodeName:   ep.Title,
+						Season:        &season,
+						Episode:       &episode,
+						FilePath:      episodeFile.Path,
+						FileID:        *ep.EpisodeFileID,
+						ProcessedAt:   time.Now().Format(time.RFC3339),
+						Issue:         models.IssueSizeMismatch,
+						ExpectedSize:  episodeFile.Size,
+						ActualSize:    actual,
+						Reason:        mismatchReason(actual),
+						Quality:       episodeFile.Quality.Name(),
+						ReleaseGroup:  episodeFile.ReleaseGroup,
+						CustomFormats: models.CustomFormatNames(episodeFile.CustomFormats),
+						Size:          episodeFile.Size,
+					})
+				} else if corrupt {
+					logger.Warn("    ⚠️  Checksum mismatch: %s", episodeFile.Path)
+					episodeStats.Corrupt++
+					season := ep.SeasonNumber
+					episode := ep.EpisodeNumber
+					s.addMissingFileEntry(models.MissingFileEntry{
+						MediaType:      "series",
+						MediaName:      s.getSeriesInfo(ep.SeriesID),
+						EpisodeName:    ep.Title,
+						Season:         &season,
+						Episode:        &episode,
+						FilePath:       episodeFile.Path,
+						FileID:         *ep.EpisodeFileID,
+						ProcessedAt:    time.Now().Format(time.RFC3339),
+						Issue:          models.IssueChecksumMismatch,
+						ExpectedSHA256: expectedSum,
+						ActualSHA256:   actualSum,
+						Reason:         models.ReasonCorrupt,
+						Quality:        episodeFile.Quality.Name(),
+						ReleaseGroup:   episodeFile.ReleaseGroup,
+						CustomFormats:  models.CustomFormatNames(episodeFile.CustomFormats),
+						Size:           episodeFile.Size,
+					})
+				} else {
+					logger.Debug("    ✅ File exists: %s", episodeFile.Path)
+					season, episode := ep.SeasonNumber, ep.EpisodeNumber
+					s.markRecovered(logger, models.MissingFileEntry{
+						MediaType:   "series",
+						MediaName:   s.getSeriesInfo(ep.SeriesID),
+						EpisodeName: ep.Title,
+						Season:      &season,
+						Episode:     &episode,
+						FilePath:    episodeFile.Path,
+					})
+				}
 				episodeResultsChan <- episodeResult{episode: ep, stats: episodeStats, err: nil}
 				return
 			}
 
-			// File is missing
-			episodeStats.MissingFiles++
-			s.progressReporter.ReportMissingFile(episodeFile.Path)
+			// File is missing; empty paths and records outside the root folders are counted on their own
+			if !emptyPath && !outside {
+				episodeStats.MissingFiles++
+				s.progressReporter.ReportMissingFile(episodeFile.Path)
+			}
 
 			// Add to missing files report
 			seriesName := s.getSeriesInfo(ep.SeriesID)
 			season := ep.SeasonNumber
 			episode := ep.EpisodeNumber
+			reason := s.missingReason(episodeFile.Path)
+			if outside {
+				reason = models.ReasonOutsideRoot
+			}
 			missingEntry := models.MissingFileEntry{
-				MediaType:   "series",
-				MediaName:   seriesName,
-				EpisodeName: ep.Title,
-				Season:      &season,
-				Episode:     &episode,
-				FilePath:    episodeFile.Path,
-				FileID:      *ep.EpisodeFileID,
-				ProcessedAt: time.Now().Format(time.RFC3339),
+				MediaType:     "series",
+				MediaName:     seriesName,
+				EpisodeName:   ep.Title,
+				Season:        &season,
+				Episode:       &episode,
+				FilePath:      episodeFile.Path,
+				FileID:        *ep.EpisodeFileID,
+				ProcessedAt:   time.Now().Format(time.RFC3339),
+				Reason:        reason,
+				Quality:       episodeFile.Quality.Name(),
+				ReleaseGroup:  episodeFile.ReleaseGroup,
+				CustomFormats: models.CustomFormatNames(episodeFile.CustomFormats),
+				Size:          episodeFile.Size,
 			}
 			s.addMissingFileEntry(missingEntry)
 
-			if s.dryRun {
-				s.logger.Info("    🏃 DRY RUN: Would delete episode file record %d", *ep.EpisodeFileID)
+			action := models.PlannedAction{
+				Action:    models.ActionDeleteEpisodeFile,
+				MediaType: "series",
+				MediaName: seriesName,
+				SeriesID:  ep.SeriesID,
+				EpisodeID: ep.ID,
+				FileID:    *ep.EpisodeFileID,
+				Path:      episodeFile.Path,
+			}
+			if !s.scope.Allows(action) {
+				logger.Info("    ⏭️  Skipping episode file record %d: not listed in %s", *ep.EpisodeFileID, s.scope.Source)
+				episodeStats.Skipped++
+				episodeResultsChan <- episodeResult{episode: ep, stats: episodeStats, err: nil}
+				return
+			}
+
+			if s.reportOnly() {
+				logger.Info("    🏃 DRY RUN: Would delete episode file record %d", *ep.EpisodeFileID)
+				s.planCall(logger, &action, nil)
+				s.addPlannedAction(action)
+				s.markPlanned(ep.SeriesID)
 				episodeResultsChan <- episodeResult{episode: ep, stats: episodeStats, err: nil}
 				return
 			}
 
 			// Delete the episode file record
-			s.logger.Info("    🗑️  Deleting episode file record %d...", *ep.EpisodeFileID)
-			if err := s.client.DeleteEpisodeFile(ctx, *ep.EpisodeFileID); err != nil {
-				s.logger.Error("    ❌ Failed to delete episode file record %d: %s", *ep.EpisodeFileID, err.Error())
+			logger.Info("    🗑️  Deleting episode file record %d...", *ep.EpisodeFileID)
+			deleteStart := time.Now()
+			err = s.client.DeleteEpisodeFile(ctx, *ep.EpisodeFileID)
+			s.clock.track(phaseDeletion, deleteStart)
+			if err != nil {
+				logger.Error("    ❌ Failed to delete episode file record %d: %s", *ep.EpisodeFileID, err.Error())
 				s.progressReporter.ReportError(err)
 				episodeStats.Errors++
 				episodeResultsChan <- episodeResult{episode: ep, stats: episodeStats, err: nil}
@@ -684,6 +1677,7 @@ func (s *CleanupServiceImpl) cleanupSeries(ctx context.Context, seriesID int) (m
 
 			episodeStats.DeletedRecords++
 			s.progressReporter.ReportDeletedEpisodeRecord(*ep.EpisodeFileID)
+			s.markDeleted(ep.SeriesID)
 
 			// Note: In modern Sonarr versions, deleting the episode file record
 			// automatically updates the episode status, so explicit updates are not needed
@@ -713,7 +1707,8 @@ func (s *CleanupServiceImpl) cleanupSeries(ctx context.Context, seriesID int) (m
 	// Collect episode results
 	for result := range episodeResultsChan {
 		if result.err != nil {
-			if result.err == ctx.Err() {
+			var panicErr *PanicError
+			if result.err == ctx.Err() || errors.As(result.err, &panicErr) {
 				return stats, result.err
 			}
 		}
@@ -723,6 +1718,12 @@ func (s *CleanupServiceImpl) cleanupSeries(ctx context.Context, seriesID int) (m
 		stats.MissingFiles += result.stats.MissingFiles
 		stats.DeletedRecords += result.stats.DeletedRecords
 		stats.Errors += result.stats.Errors
+		stats.Warnings += result.stats.Warnings
+		stats.Skipped += result.stats.Skipped
+		stats.SizeMismatches += result.stats.SizeMismatches
+		stats.EmptyPaths += result.stats.EmptyPaths
+		stats.Corrupt += result.stats.Corrupt
+		stats.OutsideRoot += result.stats.OutsideRoot
 		episodeMu.Unlock()
 	}
 
@@ -732,72 +1733,194 @@ func (s *CleanupServiceImpl) cleanupSeries(ctx context.Context, seriesID int) (m
 // cleanupMovie processes a single movie
 func (s *CleanupServiceImpl) cleanupMovie(ctx context.Context, movieID int) (models.CleanupStats, error) {
 	stats := models.CleanupStats{}
+	logger := WithItem(s.logger, ItemFields{Service: s.client.GetName(), MovieID: movieID})
 
 	// Get the specific movie directly
-	s.logger.Debug("Fetching movie %d...", movieID)
+	logger.Debug("Fetching movie %d...", movieID)
+	fetchStart := time.Now()
 	targetMovie, err := s.client.GetMovie(ctx, movieID)
+	s.clock.track(phaseFetch, fetchStart)
 	if err != nil {
 		return stats, fmt.Errorf("failed to get movie %d: %w", movieID, err)
 	}
 
 	// Check if movie has a file
 	if !targetMovie.HasFile || targetMovie.MovieFileID == nil {
-		s.logger.Debug("  Movie %d has no file reference", movieID)
+		logger.Debug("  Movie %d has no file reference", movieID)
 		return stats, nil
 	}
 
 	stats.TotalItemsChecked++
+	logger = WithItem(logger, ItemFields{FileID: *targetMovie.MovieFileID})
 
 	// Get movie file details
-	movieFile, err := s.client.GetMovieFile(ctx, *targetMovie.MovieFileID)
+	fetchStart = time.Now()
+	movieFile, err := retryOnce(ctx, func() (*models.MovieFile, error) {
+		return s.client.GetMovieFile(ctx, *targetMovie.MovieFileID)
+	})
+	s.clock.track(phaseFetch, fetchStart)
 	if err != nil {
 		// If movie file is not found, it might have been already deleted
 		// This is not an error condition - just skip this movie
-		if strings.Contains(strings.ToLower(err.Error()), "not found") {
-			s.logger.Info("    ℹ️  Movie file %d already deleted or not found", *targetMovie.MovieFileID)
+		if errors.Is(err, ErrNotFound) {
+			logger.Info("    ℹ️  Movie file %d already deleted or not found", *targetMovie.MovieFileID)
 			return stats, nil
 		}
-		s.logger.Warn("    ⚠️  Failed to get movie file %d: %s", *targetMovie.MovieFileID, err.Error())
-		stats.Errors++
+		logger.Warn("    ⚠️  Failed to get movie file %d: %s", *targetMovie.MovieFileID, err.Error())
+		countFailure(&stats, err)
 		return stats, nil
 	}
 
-	// Check if file exists
-	if movieFile.Path == "" {
-		s.logger.Warn("    ⚠️  No file path found for movie file %d", *targetMovie.MovieFileID)
-		return stats, nil
+	// Check if file exists. A record without a path is skipped, or with the delete empty path
+	// policy handled like a missing file.
+	emptyPath := movieFile.Path == ""
+	if emptyPath {
+		stats.EmptyPaths++
+		if !s.deleteEmptyPaths {
+			logger.Warn("    ⚠️  No file path found for movie file %d", *targetMovie.MovieFileID)
+			stats.Warnings++
+			return stats, nil
+		}
+		logger.Warn("    ⚠️  No file path found for movie file %d, treating the record as broken", *targetMovie.MovieFileID)
+	}
+	logger = WithItem(logger, ItemFields{Path: movieFile.Path})
+
+	// A record outside every root folder follows the outside root policy, and under the delete
+	// policy is handled like a missing file
+	outside := !emptyPath && s.outsideRootFolders(ctx, movieFile.Path)
+	if outside {
+		entry := models.OutsideRootEntry{
+			MediaType: "movie",
+			MediaName: s.getMovieInfo(targetMovie.ID),
+			FilePath:  movieFile.Path,
+			FileID:    *targetMovie.MovieFileID,
+		}
+		if s.handleOutsideRoot(ctx, logger, entry, targetMovie.ID, &stats) {
+			return stats, nil
+		}
 	}
 
-	if s.fileChecker.FileExists(movieFile.Path) {
-		s.logger.Debug("    ✅ File exists: %s", movieFile.Path)
+	verifyStart := time.Now()
+	exists := !emptyPath && !outside && s.fileChecker.FileExists(movieFile.Path)
+	actual, mismatch := int64(0), false
+	if exists {
+		actual, mismatch = s.sizeMismatch(movieFile.Path, movieFile.Size)
+	}
+	expectedSum, actualSum, corrupt := "", "", false
+	if exists && !mismatch {
+		expectedSum, actualSum, corrupt = s.checksumMismatch(movieFile.Path)
+	}
+	s.clock.track(phaseVerification, verifyStart)
+	s.noteFile(targetMovie.ID, exists && !mismatch && !corrupt)
+
+	if exists {
+		if mismatch {
+			logger.Warn("    ⚠️  Size mismatch: %s (expected %d bytes, found %d)", movieFile.Path, movieFile.Size, actual)
+			stats.SizeMismatches++
+			s.addMissingFileEntry(models.MissingFileEntry{
+				MediaType:     "movie",
+				MediaName:     s.getMovieInfo(targetMovie.ID),
+				FilePath:      movieFile.Path,
+				FileID:        *targetMovie.MovieFileID,
+				ProcessedAt:   time.Now().Format(time.RFC3339),
+				TMDBID:        targetMovie.TMDBID,
+				Issue:         models.IssueSizeMismatch,
+				ExpectedSize:  movieFile.Size,
+				ActualSize:    actual,
+				Reason:        mismatchReason(actual),
+				Quality:       movieFile.Quality.Name(),
+				ReleaseGroup:  movieFile.ReleaseGroup,
+				CustomFormats: models.CustomFormatNames(movieFile.CustomFormats),
+				Size:          movieFile.Size,
+			})
+		} else if corrupt {
+			logger.Warn("    ⚠️  Checksum mismatch: %s", movieFile.Path)
+			stats.Corrupt++
+			s.addMissingFileEntry(models.MissingFileEntry{
+				MediaType:      "movie",
+				MediaName:      s.getMovieInfo(targetMovie.ID),
+				FilePath:       movieFile.Path,
+				FileID:         *targetMovie.MovieFileID,
+				ProcessedAt:    time.Now().Format(time.RFC3339),
+				TMDBID:         targetMovie.TMDBID,
+				Issue:          models.IssueChecksumMismatch,
+				ExpectedSHA256: expectedSum,
+				ActualSHA256:   actualSum,
+				Reason:         models.ReasonCorrupt,
+				Quality:        movieFile.Quality.Name(),
+				ReleaseGroup:   movieFile.ReleaseGroup,
+				CustomFormats:  models.CustomFormatNames(movieFile.CustomFormats),
+				Size:           movieFile.Size,
+			})
+		} else {
+			logger.Debug("    ✅ File exists: %s", movieFile.Path)
+			s.markRecovered(logger, models.MissingFileEntry{
+				MediaType: "movie",
+				MediaName: s.getMovieInfo(targetMovie.ID),
+				TMDBID:    targetMovie.TMDBID,
+				FilePath:  movieFile.Path,
+			})
+		}
 		return stats, nil
 	}
 
-	// File is missing
-	stats.MissingFiles++
-	s.progressReporter.ReportMissingFile(movieFile.Path)
+	// File is missing; empty paths and records outside the root folders are counted on their own
+	if !emptyPath && !outside {
+		stats.MissingFiles++
+		s.progressReporter.ReportMissingFile(movieFile.Path)
+	}
 
 	// Add to missing files report
 	movieName := s.getMovieInfo(targetMovie.ID)
+	reason := s.missingReason(movieFile.Path)
+	if outside {
+		reason = models.ReasonOutsideRoot
+	}
 	missingEntry := models.MissingFileEntry{
-		MediaType:   "movie",
-		MediaName:   movieName,
-		FilePath:    movieFile.Path,
-		FileID:      *targetMovie.MovieFileID,
-		ProcessedAt: time.Now().Format(time.RFC3339),
-		TMDBID:      targetMovie.TMDBID,
+		MediaType:     "movie",
+		MediaName:     movieName,
+		FilePath:      movieFile.Path,
+		FileID:        *targetMovie.MovieFileID,
+		ProcessedAt:   time.Now().Format(time.RFC3339),
+		TMDBID:        targetMovie.TMDBID,
+		Reason:        reason,
+		Quality:       movieFile.Quality.Name(),
+		ReleaseGroup:  movieFile.ReleaseGroup,
+		CustomFormats: models.CustomFormatNames(movieFile.CustomFormats),
+		Size:          movieFile.Size,
 	}
 	s.addMissingFileEntry(missingEntry)
 
-	if s.dryRun {
-		s.logger.Info("    🏃 DRY RUN: Would delete movie file record %d", *targetMovie.MovieFileID)
+	action := models.PlannedAction{
+		Action:    models.ActionDeleteMovieFile,
+		MediaType: "movie",
+		MediaName: movieName,
+		MovieID:   targetMovie.ID,
+		FileID:    *targetMovie.MovieFileID,
+		Path:      movieFile.Path,
+		TMDBID:    targetMovie.TMDBID,
+	}
+	if !s.scope.Allows(action) {
+		logger.Info("    ⏭️  Skipping movie file record %d: not listed in %s", *targetMovie.MovieFileID, s.scope.Source)
+		stats.Skipped++
+		return stats, nil
+	}
+
+	if s.reportOnly() {
+		logger.Info("    🏃 DRY RUN: Would delete movie file record %d", *targetMovie.MovieFileID)
+		s.planCall(logger, &action, nil)
+		s.addPlannedAction(action)
+		s.markPlanned(targetMovie.ID)
 		return stats, nil
 	}
 
 	// Delete the movie file record
-	s.logger.Info("    🗑️  Deleting movie file record %d...", *targetMovie.MovieFileID)
-	if err := s.client.DeleteMovieFile(ctx, *targetMovie.MovieFileID); err != nil {
-		s.logger.Error("    ❌ Failed to delete movie file record %d: %s", *targetMovie.MovieFileID, err.Error())
+	logger.Info("    🗑️  Deleting movie file record %d...", *targetMovie.MovieFileID)
+	deleteStart := time.Now()
+	err = s.client.DeleteMovieFile(ctx, *targetMovie.MovieFileID)
+	s.clock.track(phaseDeletion, deleteStart)
+	if err != nil {
+		logger.Error("    ❌ Failed to delete movie file record %d: %s", *targetMovie.MovieFileID, err.Error())
 		s.progressReporter.ReportError(err)
 		stats.Errors++
 		return stats, nil
@@ -805,6 +1928,7 @@ func (s *CleanupServiceImpl) cleanupMovie(ctx context.Context, movieID int) (mod
 
 	stats.DeletedRecords++
 	s.progressReporter.ReportDeletedMovieRecord(*targetMovie.MovieFileID)
+	s.markDeleted(targetMovie.ID)
 
 	// Note: In modern Radarr versions, deleting the movie file record
 	// automatically updates the movie status, so explicit updates are not needed
@@ -867,73 +1991,361 @@ func (s *CleanupServiceImpl) handleBrokenSymlinks(ctx context.Context) (models.C
 
 	s.logger.Info("Processing %d broken symlinks...", len(allBrokenSymlinks))
 
-	// Process each broken symlink
-	for _, symlinkPath := range allBrokenSymlinks {
-		symlinkStats, err := s.handleBrokenSymlink(ctx, symlinkPath, rootFolders)
+	symlinkStats := s.processBrokenSymlinks(ctx, allBrokenSymlinks, rootFolders, s.movieTMDBIDFromPath, s.handleBrokenSymlink)
+	stats.Errors += symlinkStats.Errors
+	stats.TotalItemsChecked += symlinkStats.TotalItemsChecked
+	stats.MissingFiles += symlinkStats.MissingFiles
+	stats.Warnings += symlinkStats.Warnings
+	stats.Skipped += symlinkStats.Skipped
+	stats.SizeMismatches += symlinkStats.SizeMismatches
+
+	return stats, nil
+}
+
+// brokenSymlinkGroup is the broken symlinks that belong to one movie or series
+type brokenSymlinkGroup struct {
+	ID    int         // TMDB ID of a movie, TVDB ID of a series
+	Paths []string    // Every broken symlink of the movie or series, in scan order
+	Match *titleMatch // Title lookup match the ID came from, nil when a path had the ID
+}
+
+// processBrokenSymlinks finds the movie or series each broken symlink belongs to with resolve,
+// then runs handle once per movie or series with all of its symlinks, so lookups and adds
+// aren't repeated for every edition or extra. Both steps run in a worker pool of the configured
+// concurrency. A movie or series that fails is logged and counted as an error without stopping the others.
+func (s *CleanupServiceImpl) processBrokenSymlinks(
+	ctx context.Context,
+	symlinkPaths []string,
+	rootFolders []models.RootFolder,
+	resolve func(context.Context, string) (int, *titleMatch, error),
+	handle func(context.Context, brokenSymlinkGroup, []models.RootFolder) (models.CleanupStats, error),
+) models.CleanupStats {
+	var stats models.CleanupStats
+	groups := s.groupBrokenSymlinks(ctx, symlinkPaths, resolve, &stats)
+	if len(groups) < len(symlinkPaths) {
+		s.logger.Info("Grouped %d broken symlinks into %d movies/series", len(symlinkPaths), len(groups))
+	}
+
+	var mu sync.Mutex
+	s.forEachConcurrently(ctx, len(groups), func(i int) {
+		group := groups[i]
+		groupStats, err := handle(ctx, group, rootFolders)
+
+		mu.Lock()
 		if err != nil {
-			s.logger.Error("Failed to handle broken symlink %s: %s", symlinkPath, err.Error())
+			s.logger.Error("Failed to handle broken symlink %s: %s", group.Paths[0], err.Error())
 			stats.Errors++
+		} else {
+			stats.TotalItemsChecked += groupStats.TotalItemsChecked
+			stats.MissingFiles += groupStats.MissingFiles
+			stats.Warnings += groupStats.Warnings
+			stats.Skipped += groupStats.Skipped
+			stats.SizeMismatches += groupStats.SizeMismatches
+		}
+		mu.Unlock()
+
+		// Add delay after processing to be nice to the API
+		if s.requestDelay > 0 {
+			time.Sleep(s.requestDelay)
+		}
+	})
+	return stats
+}
+
+// groupBrokenSymlinks groups broken symlinks by the TMDB or TVDB ID resolve finds for them,
+// in the order their first symlinks were found. Symlinks without an ID are skipped and
+// counted as checked in stats.
+func (s *CleanupServiceImpl) groupBrokenSymlinks(
+	ctx context.Context,
+	symlinkPaths []string,
+	resolve func(context.Context, string) (int, *titleMatch, error),
+	stats *models.CleanupStats,
+) []brokenSymlinkGroup {
+	ids := make([]int, len(symlinkPaths))
+	matches := make([]*titleMatch, len(symlinkPaths))
+	s.forEachConcurrently(ctx, len(symlinkPaths), func(i int) {
+		id, match, err := resolve(ctx, symlinkPaths[i])
+		if err != nil {
+			s.logger.Warn("Could not parse an ID from path %s: %s", symlinkPaths[i], err.Error())
+			return // Not an error, just skip this file
+		}
+		s.logger.Debug("Extracted ID %d from %s", id, symlinkPaths[i])
+		ids[i], matches[i] = id, match
+	})
+
+	var groups []brokenSymlinkGroup
+	index := make(map[int]int) // ID -> position in groups
+	for i, symlinkPath := range symlinkPaths {
+		if ids[i] <= 0 {
+			stats.TotalItemsChecked++
+			continue
+		}
+		pos, ok := index[ids[i]]
+		if !ok {
+			index[ids[i]] = len(groups)
+			groups = append(groups, brokenSymlinkGroup{ID: ids[i], Paths: []string{symlinkPath}, Match: matches[i]})
 			continue
 		}
 
-		stats.TotalItemsChecked += symlinkStats.TotalItemsChecked
-		stats.MissingFiles += symlinkStats.MissingFiles
+		// The most certain identification wins: an ID in a path, then the best title match
+		group := &groups[pos]
+		group.Paths = append(group.Paths, symlinkPath)
+		if group.Match != nil && (matches[i] == nil || matches[i].Confidence > group.Match.Confidence) {
+			group.Match = matches[i]
+		}
 	}
+	return groups
+}
 
-	return stats, nil
+// forEachConcurrently calls fn for 0..n-1 in a worker pool of the configured concurrency, and
+// stops starting new calls once ctx is done. A panic in fn is raised again in the caller once
+// the other calls have finished.
+func (s *CleanupServiceImpl) forEachConcurrently(ctx context.Context, n int, fn func(i int)) {
+	// Create worker pool for concurrent processing
+	semaphore := make(chan struct{}, max(s.concurrentLimit, 1))
+	var wg sync.WaitGroup
+	var panicMu sync.Mutex
+	var panicErr error
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			// Acquire semaphore slot
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			defer recoverWorker(func(err error) {
+				panicMu.Lock()
+				defer panicMu.Unlock()
+				if panicErr == nil {
+					panicErr = err
+				}
+			})
+
+			if ctx.Err() != nil {
+				return
+			}
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+
+	if panicErr != nil {
+		panic(panicErr)
+	}
+}
+
+// deleteBrokenSymlinks deletes a movie's or series' broken symlinks before it is processed, or
+// plans their deletion in dry-run mode. Symlinks the action scope doesn't list are left alone
+// and counted as skipped. It returns the symlinks that were (or would be) deleted.
+func (s *CleanupServiceImpl) deleteBrokenSymlinks(group brokenSymlinkGroup, action models.PlannedAction, stats *models.CleanupStats) ([]string, error) {
+	var deleted []string
+	for _, symlinkPath := range group.Paths {
+		logger := WithItem(s.logger, ItemFields{Service: s.client.GetName(), Path: symlinkPath})
+		symlinkAction := action
+		symlinkAction.Action = models.ActionDeleteSymlink
+		symlinkAction.Path = symlinkPath
+		if !s.scope.Allows(symlinkAction) {
+			logger.Info("⏭️  Skipping broken symlink %s: not listed in %s", symlinkPath, s.scope.Source)
+			stats.Skipped++
+			continue
+		}
+
+		if !s.reportOnly() {
+			logger.Info("🗑️  Deleting broken symlink: %s", symlinkPath)
+			if err := s.fileChecker.DeleteSymlink(symlinkPath); err != nil {
+				logger.Error("Failed to delete broken symlink %s: %s", symlinkPath, err.Error())
+				stats.Errors++
+				return deleted, fmt.Errorf("failed to delete broken symlink %s: %w", symlinkPath, err)
+			}
+			logger.Info("✅ Successfully deleted broken symlink: %s", symlinkPath)
+		} else {
+			logger.Info("🏃 DRY RUN: Would delete broken symlink: %s", symlinkPath)
+			s.addPlannedAction(symlinkAction)
+		}
+		deleted = append(deleted, symlinkPath)
+	}
+	return deleted, nil
 }
 
-// handleBrokenSymlink processes a single broken symlink
-func (s *CleanupServiceImpl) handleBrokenSymlink(ctx context.Context, symlinkPath string, rootFolders []models.RootFolder) (models.CleanupStats, error) {
-	stats := models.CleanupStats{TotalItemsChecked: 1}
+// symlinkPathsOf returns the paths a report entry lists besides its FilePath: all of them when
+// several broken symlinks belong to the movie or series, none otherwise
+func symlinkPathsOf(symlinkPaths []string) []string {
+	if len(symlinkPaths) < 2 {
+		return nil
+	}
+	return symlinkPaths
+}
 
-	s.logger.Debug("Processing broken symlink: %s", symlinkPath)
+// movieTMDBIDFromPath returns the TMDB ID of the movie a path belongs to. Paths tagged with an
+// IMDb ID instead are resolved through Radarr's IMDb lookup, and paths without any ID through a
+// title lookup, whose match is returned as well (nil when the ID came from the path).
+func (s *CleanupServiceImpl) movieTMDBIDFromPath(ctx context.Context, symlinkPath string) (int, *titleMatch, error) {
+	info := s.pathParser.Parse(symlinkPath)
+	if info.TMDBID > 0 {
+		return info.TMDBID, nil, nil
+	}
+	if info.IMDBID == "" {
+		if info.Title == "" {
+			return 0, nil, fmt.Errorf("TMDB ID not found in path: %s", symlinkPath)
+		}
+		lookups, err := s.client.LookupMoviesByTerm(ctx, info.Title)
+		if err != nil {
+			return 0, nil, fmt.Errorf("no TMDB ID in path and title lookup for %q failed: %w", info.Title, err)
+		}
+		candidates := make([]titleCandidate, 0, len(lookups))
+		for _, lookup := range lookups {
+			candidates = append(candidates, titleCandidate{ID: lookup.TMDBID, Title: lookup.Title, Year: lookup.Year})
+		}
+		return s.matchTitle("TMDB", info, candidates)
+	}
 
-	// Extract TMDB ID from path
-	tmdbID, err := models.ParseTMDBIDFromPath(symlinkPath)
+	lookup, err := s.client.LookupMovieByIMDBID(ctx, info.IMDBID)
 	if err != nil {
-		s.logger.Warn("Could not parse TMDB ID from path %s: %s", symlinkPath, err.Error())
-		return stats, nil // Not an error, just skip this file
+		return 0, nil, fmt.Errorf("no TMDB ID in path and IMDb lookup for %s failed: %w", info.IMDBID, err)
 	}
+	s.logger.Debug("Resolved IMDb ID %s to TMDB ID %d (%s)", info.IMDBID, lookup.TMDBID, lookup.Title)
+	return lookup.TMDBID, nil, nil
+}
 
-	s.logger.Debug("Extracted TMDB ID %d from %s", tmdbID, symlinkPath)
+// seriesTVDBIDFromPath returns the TVDB ID of the series a path belongs to. Paths without one
+// are resolved through a title lookup, whose match is returned as well (nil when the ID came from the path).
+func (s *CleanupServiceImpl) seriesTVDBIDFromPath(ctx context.Context, symlinkPath string) (int, *titleMatch, error) {
+	info := s.pathParser.Parse(symlinkPath)
+	if info.TVDBID > 0 {
+		return info.TVDBID, nil, nil
+	}
+	if info.Title == "" {
+		return 0, nil, fmt.Errorf("TVDB ID not found in path: %s", symlinkPath)
+	}
 
-	// Delete the broken symlink before processing (if not in dry-run mode)
-	if !s.dryRun {
-		s.logger.Info("🗑️  Deleting broken symlink: %s", symlinkPath)
-		if err := s.fileChecker.DeleteSymlink(symlinkPath); err != nil {
-			s.logger.Error("Failed to delete broken symlink %s: %s", symlinkPath, err.Error())
-			stats.Errors++
-			return stats, fmt.Errorf("failed to delete broken symlink %s: %w", symlinkPath, err)
+	lookups, err := s.client.LookupSeriesByTerm(ctx, info.Title)
+	if err != nil {
+		return 0, nil, fmt.Errorf("no TVDB ID in path and title lookup for %q failed: %w", info.Title, err)
+	}
+	candidates := make([]titleCandidate, 0, len(lookups))
+	for _, lookup := range lookups {
+		candidates = append(candidates, titleCandidate{ID: lookup.TVDBID, Title: lookup.Title, Year: lookup.Year})
+	}
+	return s.matchTitle("TVDB", info, candidates)
+}
+
+// matchTitle picks the lookup result matching the title and year parsed from a path
+func (s *CleanupServiceImpl) matchTitle(idName string, info models.PathInfo, candidates []titleCandidate) (int, *titleMatch, error) {
+	match, ok := bestTitleMatch(info.Title, info.Year, candidates)
+	if !ok {
+		return 0, nil, fmt.Errorf("no %s ID in path and none of %d lookup results for %q matches", idName, len(candidates), info.Title)
+	}
+	s.logger.Debug("Matched %q (%d) to %s ID %d (%s) with %.0f%% confidence",
+		info.Title, info.Year, idName, match.ID, match.Title, match.Confidence*100)
+	return match.ID, &match, nil
+}
+
+// confidentTitleMatch reports whether an item identified by title lookup (match is nil for IDs
+// parsed from the path) may be changed without review
+func (s *CleanupServiceImpl) confidentTitleMatch(match *titleMatch) bool {
+	if match == nil {
+		return true
+	}
+	threshold := s.titleConfidence
+	if threshold <= 0 {
+		threshold = DefaultTitleMatchConfidence
+	}
+	return match.Confidence >= threshold
+}
+
+// confidence returns the match's confidence, 0 for IDs parsed from the path
+func (m *titleMatch) confidence() float64 {
+	if m == nil {
+		return 0
+	}
+	return m.Confidence
+}
+
+// reportUnconfidentMatch records broken symlinks whose title lookup match is too uncertain to
+// act on. The symlinks are kept so their paths can be tagged with an ID and processed by a later run.
+func (s *CleanupServiceImpl) reportUnconfidentMatch(mediaType string, group brokenSymlinkGroup, entry models.MissingFileEntry) {
+	match := group.Match
+	logger := WithItem(s.logger, ItemFields{Service: s.client.GetName(), Path: group.Paths[0]})
+	logger.Warn("🔍 %s looks like %s %s (%d) with only %.0f%% confidence, leaving it for review",
+		group.Paths[0], mediaType, match.Title, match.Year, match.Confidence*100)
+	entry.MediaType = mediaType
+	entry.MediaName = match.Title
+	entry.FilePath = group.Paths[0]
+	entry.FilePaths = symlinkPathsOf(group.Paths)
+	entry.ProcessedAt = time.Now().Format(time.RFC3339)
+	entry.Reason = models.ReasonBrokenSymlink
+	entry.MatchConfidence = match.Confidence
+	if mediaType == "series" {
+		s.addSeriesSymlinkEntries(entry, group.Paths, nil)
+		return
+	}
+	s.addMissingFileEntry(entry)
+}
+
+// addSeriesSymlinkEntries adds a report entry per broken symlink of a series, like the series'
+// other missing files get one per episode. describe, when set, fills in the episode of each.
+func (s *CleanupServiceImpl) addSeriesSymlinkEntries(entry models.MissingFileEntry, symlinkPaths []string, describe func(path string, entry *models.MissingFileEntry)) {
+	for _, path := range symlinkPaths {
+		pathEntry := entry
+		pathEntry.FilePath = path
+		pathEntry.FilePaths = nil
+		if describe != nil {
+			describe(path, &pathEntry)
 		}
-		s.logger.Info("✅ Successfully deleted broken symlink: %s", symlinkPath)
-	} else {
-		s.logger.Info("🏃 DRY RUN: Would delete broken symlink: %s", symlinkPath)
+		s.addMissingFileEntry(pathEntry)
+	}
+}
+
+// handleBrokenSymlink processes the broken symlinks of a single movie
+func (s *CleanupServiceImpl) handleBrokenSymlink(ctx context.Context, group brokenSymlinkGroup, rootFolders []models.RootFolder) (models.CleanupStats, error) {
+	stats := models.CleanupStats{TotalItemsChecked: len(group.Paths)}
+	tmdbID, match := group.ID, group.Match
+	logger := WithItem(s.logger, ItemFields{Service: s.client.GetName(), Path: group.Paths[0]})
+
+	logger.Debug("Processing %d broken symlink(s) of TMDB ID %d", len(group.Paths), tmdbID)
+
+	if !s.confidentTitleMatch(match) {
+		s.reportUnconfidentMatch("movie", group, models.MissingFileEntry{TMDBID: tmdbID})
+		stats.MissingFiles += len(group.Paths)
+		return stats, nil
 	}
 
+	// Delete the broken symlinks before processing (if not in dry-run mode)
+	symlinkPaths, err := s.deleteBrokenSymlinks(group, models.PlannedAction{MediaType: "movie", TMDBID: tmdbID}, &stats)
+	if err != nil || len(symlinkPaths) == 0 {
+		return stats, err
+	}
+	symlinkPath := symlinkPaths[0]
+
 	// Check if movie already exists in Radarr collection
-	existingMovie, err := s.client.GetMovieByTMDBID(ctx, tmdbID)
+	existingMovie, err := s.movieByTMDBID(ctx, tmdbID)
 	if err == nil {
 		// Movie already exists in collection
-		s.logger.Debug("Movie with TMDB ID %d already exists in collection: %s", tmdbID, existingMovie.Title)
+		logger = WithItem(logger, ItemFields{MovieID: existingMovie.ID})
+		logger.Debug("Movie with TMDB ID %d already exists in collection: %s", tmdbID, existingMovie.Title)
 
 		// Add to missing files report but don't add to collection
 		missingEntry := models.MissingFileEntry{
 			MediaType:         "movie",
 			MediaName:         existingMovie.Title,
 			FilePath:          symlinkPath,
+			FilePaths:         symlinkPathsOf(symlinkPaths),
 			FileID:            0, // No file ID since it's a broken symlink
 			ProcessedAt:       time.Now().Format(time.RFC3339),
 			AddedToCollection: false,
 			TMDBID:            tmdbID,
+			Reason:            models.ReasonBrokenSymlink,
+			MatchConfidence:   match.confidence(),
 		}
 		s.addMissingFileEntry(missingEntry)
-		stats.MissingFiles++
+		stats.MissingFiles += len(symlinkPaths)
 		return stats, nil
 	}
 
 	// Movie not found in collection, need to add it
-	s.logger.Info("Movie with TMDB ID %d not found in collection, looking up details...", tmdbID)
+	logger.Info("Movie with TMDB ID %d not found in collection, looking up details...", tmdbID)
 
 	// Lookup movie details from TMDB
 	movieLookup, err := s.client.LookupMovieByTMDBID(ctx, tmdbID)
@@ -953,7 +2365,7 @@ func (s *CleanupServiceImpl) handleBrokenSymlink(ctx context.Context, symlinkPat
 	// If no matching root folder found, use the first one
 	if selectedRootFolder == nil && len(rootFolders) > 0 {
 		selectedRootFolder = &rootFolders[0]
-		s.logger.Debug("Using first available root folder: %s", selectedRootFolder.Path)
+		logger.Debug("Using first available root folder: %s", selectedRootFolder.Path)
 	}
 
 	if selectedRootFolder == nil {
@@ -973,20 +2385,51 @@ func (s *CleanupServiceImpl) handleBrokenSymlink(ctx context.Context, symlinkPat
 		HasFile:          false,
 	}
 
-	if s.addMissingMovies && !s.dryRun {
+	// Monitoring the collection makes Radarr add the rest of the franchise, as adding the movie by hand would
+	collection := movieLookup.Collection
+	if s.addCollections && collection != nil {
+		movieToAdd.AddOptions = &models.MovieAddOptions{Monitor: models.MonitorMovieAndCollection}
+	}
+
+	addAction := models.PlannedAction{
+		Action:    models.ActionAddMovie,
+		MediaType: "movie",
+		MediaName: movieLookup.Title,
+		Path:      symlinkPath,
+		TMDBID:    tmdbID,
+	}
+	addAllowed := s.scope.Allows(addAction)
+	reportOnly := s.reportOnly()
+
+	if s.addMissingMovies && !addAllowed {
+		logger.Info("⏭️  Not adding movie %s: not listed in %s", movieLookup.Title, s.scope.Source)
+	} else if s.addMissingMovies && !reportOnly {
 		// Add movie to Radarr collection
-		s.logger.Info("Adding movie to collection: %s (%d)", movieLookup.Title, movieLookup.Year)
+		logger.Info("Adding movie to collection: %s (%d)", movieLookup.Title, movieLookup.Year)
+		if movieToAdd.AddOptions != nil {
+			logger.Info("📚 Also monitoring its collection: %s", collectionName(collection))
+		}
 		addedMovie, err := s.client.AddMovie(ctx, movieToAdd)
 		if err != nil {
 			return stats, fmt.Errorf("failed to add movie %s: %w", movieLookup.Title, err)
 		}
 
-		// Update our movie info cache
+		// Update our movie info caches
 		s.setMovieInfo(addedMovie.ID, addedMovie.Title)
-	} else if s.dryRun {
-		s.logger.Info("🏃 DRY RUN: Would add movie to collection: %s (%d)", movieLookup.Title, movieLookup.Year)
+		s.mediaCache.AddMovie(*addedMovie)
+		s.saveMediaCache()
+	} else if reportOnly {
+		logger.Info("🏃 DRY RUN: Would add movie to collection: %s (%d)", movieLookup.Title, movieLookup.Year)
+		if movieToAdd.AddOptions != nil {
+			logger.Info("🏃 DRY RUN: Would also monitor its collection: %s", collectionName(collection))
+		}
+		if s.addMissingMovies {
+			addAction.AddCheck = s.checkAdd(ctx, movieLookup.Title, *selectedRootFolder)
+			s.planCall(logger, &addAction, movieToAdd)
+			s.addPlannedAction(addAction)
+		}
 	} else if !s.addMissingMovies {
-		s.logger.Info("📋 ADD_MISSING_MOVIES=false: Would add movie to collection: %s (%d)", movieLookup.Title, movieLookup.Year)
+		logger.Info("📋 ADD_MISSING_MOVIES=false: Would add movie to collection: %s (%d)", movieLookup.Title, movieLookup.Year)
 	}
 
 	// Add to missing files report
@@ -994,17 +2437,73 @@ func (s *CleanupServiceImpl) handleBrokenSymlink(ctx context.Context, symlinkPat
 		MediaType:         "movie",
 		MediaName:         movieLookup.Title,
 		FilePath:          symlinkPath,
+		FilePaths:         symlinkPathsOf(symlinkPaths),
 		FileID:            0, // No file ID since it's a broken symlink
 		ProcessedAt:       time.Now().Format(time.RFC3339),
-		AddedToCollection: s.addMissingMovies && !s.dryRun,
+		AddedToCollection: s.addMissingMovies && !reportOnly && addAllowed,
 		TMDBID:            tmdbID,
+		Reason:            models.ReasonBrokenSymlink,
+		MatchConfidence:   match.confidence(),
+		AddCheck:          addAction.AddCheck,
 	}
 	s.addMissingFileEntry(missingEntry)
-	stats.MissingFiles++
+	stats.MissingFiles += len(symlinkPaths)
 
 	return stats, nil
 }
 
+// checkAdd validates an add a dry run skipped, without making it: the quality profile new items
+// get must exist, and the service must be able to reach the root folder. It returns
+// models.AddCheckOK or why the add would fail, and "" when add validation is off.
+func (s *CleanupServiceImpl) checkAdd(ctx context.Context, title string, rootFolder models.RootFolder) string {
+	if !s.validateAdds {
+		return ""
+	}
+
+	s.loadQualityProfiles(ctx)
+
+	var problem string
+	_, profileExists := s.qualityProfiles[s.qualityProfileID]
+	switch {
+	case s.profilesErr != nil:
+		problem = fmt.Sprintf("quality profiles could not be fetched: %s", s.profilesErr.Error())
+	case !profileExists:
+		problem = fmt.Sprintf("quality profile %d does not exist", s.qualityProfileID)
+	case rootFolder.Accessible != nil && !*rootFolder.Accessible:
+		problem = fmt.Sprintf("root folder %s is not accessible", rootFolder.Path)
+	}
+
+	if problem != "" {
+		s.logger.Warn("❌ DRY RUN: Adding %s would fail: %s", title, problem)
+		return problem
+	}
+	s.logger.Info("✅ DRY RUN: Adding %s would succeed", title)
+	return models.AddCheckOK
+}
+
+// loadQualityProfiles fetches the service's quality profiles the first time they're needed
+func (s *CleanupServiceImpl) loadQualityProfiles(ctx context.Context) {
+	s.profilesOnce.Do(func() {
+		profiles, err := s.client.GetQualityProfiles(ctx)
+		if err != nil {
+			s.profilesErr = err
+			return
+		}
+		s.qualityProfiles = make(map[int]string, len(profiles))
+		for _, profile := range profiles {
+			s.qualityProfiles[profile.ID] = profile.Name
+		}
+	})
+}
+
+// collectionName returns a collection's title, or its TMDB ID for servers that don't send titles
+func collectionName(collection *models.MovieCollection) string {
+	if collection.Title != "" {
+		return collection.Title
+	}
+	return fmt.Sprintf("TMDB ID %d", collection.TMDBID)
+}
+
 // handleBrokenSymlinksForSeries scans for broken symlinks and adds missing series to Sonarr collection
 func (s *CleanupServiceImpl) handleBrokenSymlinksForSeries(ctx context.Context) (models.CleanupStats, error) {
 	stats := models.CleanupStats{}
@@ -1046,75 +2545,127 @@ func (s *CleanupServiceImpl) handleBrokenSymlinksForSeries(ctx context.Context)
 		return stats, nil
 	}
 
-	s.logger.Info("Processing %d broken symlinks...", len(allBrokenSymlinks))
-
-	// Process each broken symlink
-	for _, symlinkPath := range allBrokenSymlinks {
-		symlinkStats, err := s.handleBrokenSymlinkForSeries(ctx, symlinkPath, rootFolders)
-		if err != nil {
-			s.logger.Error("Failed to handle broken symlink %s: %s", symlinkPath, err.Error())
-			stats.Errors++
-			continue
+	if s.skipsSeason(0) {
+		kept := allBrokenSymlinks[:0]
+		for _, symlinkPath := range allBrokenSymlinks {
+			if isSpecialsPath(symlinkPath) {
+				s.logger.Debug("Skipping broken symlink in specials folder: %s", symlinkPath)
+				continue
+			}
+			kept = append(kept, symlinkPath)
 		}
-
-		stats.TotalItemsChecked += symlinkStats.TotalItemsChecked
-		stats.MissingFiles += symlinkStats.MissingFiles
+		allBrokenSymlinks = kept
 	}
 
+	s.logger.Info("Processing %d broken symlinks...", len(allBrokenSymlinks))
+
+	symlinkStats := s.processBrokenSymlinks(ctx, allBrokenSymlinks, rootFolders, s.seriesTVDBIDFromPath, s.handleBrokenSymlinkForSeries)
+	stats.Errors += symlinkStats.Errors
+	stats.TotalItemsChecked += symlinkStats.TotalItemsChecked
+	stats.MissingFiles += symlinkStats.MissingFiles
+	stats.Warnings += symlinkStats.Warnings
+	stats.Skipped += symlinkStats.Skipped
+	stats.SizeMismatches += symlinkStats.SizeMismatches
+
 	return stats, nil
 }
 
-// handleBrokenSymlinkForSeries processes a single broken symlink for series
-func (s *CleanupServiceImpl) handleBrokenSymlinkForSeries(ctx context.Context, symlinkPath string, rootFolders []models.RootFolder) (models.CleanupStats, error) {
-	stats := models.CleanupStats{TotalItemsChecked: 1}
+// describeSymlinkEpisode fills in the season, episode number and title of the episode a
+// broken symlink was for, when its name identifies one of the series' episodes. Anime names
+// with absolute episode numbers are matched against the episodes' absolute numbers.
+func (s *CleanupServiceImpl) describeSymlinkEpisode(ctx context.Context, seriesID int, symlinkPath string, entry *models.MissingFileEntry) {
+	ref, err := models.ParseEpisodeFromPath(symlinkPath)
+	if err != nil {
+		s.logger.Debug("Could not parse episode from %s: %s", symlinkPath, err.Error())
+		return
+	}
 
-	s.logger.Debug("Processing broken symlink: %s", symlinkPath)
+	episodes, err := s.episodesForSymlinks(ctx, seriesID)
+	if err != nil {
+		s.logger.Debug("Could not fetch episodes for series %d: %s", seriesID, err.Error())
+		return
+	}
+	for _, episode := range episodes {
+		if ref.Matches(episode) {
+			season, number := episode.SeasonNumber, episode.EpisodeNumber
+			entry.Season = &season
+			entry.Episode = &number
+			entry.EpisodeName = episode.Title
+			return
+		}
+	}
+	s.logger.Debug("No episode of series %d matches %s", seriesID, symlinkPath)
+}
+
+// episodesForSymlinks returns the series' episodes, fetched once per run however many of
+// its symlinks are broken
+func (s *CleanupServiceImpl) episodesForSymlinks(ctx context.Context, seriesID int) ([]models.Episode, error) {
+	s.symlinkEpMu.Lock()
+	defer s.symlinkEpMu.Unlock()
+	if episodes, ok := s.symlinkEpisodes[seriesID]; ok {
+		return episodes, nil
+	}
 
-	// Extract TVDB ID from path
-	tvdbID, err := models.ParseTVDBIDFromPath(symlinkPath)
+	defer s.clock.track(phaseFetch, time.Now())
+	episodes, err := s.client.GetEpisodesForSeries(ctx, seriesID)
 	if err != nil {
-		s.logger.Warn("Could not parse TVDB ID from path %s: %s", symlinkPath, err.Error())
-		return stats, nil // Not an error, just skip this file
+		return nil, err
 	}
+	if s.symlinkEpisodes == nil {
+		s.symlinkEpisodes = make(map[int][]models.Episode)
+	}
+	s.symlinkEpisodes[seriesID] = episodes
+	return episodes, nil
+}
 
-	s.logger.Debug("Extracted TVDB ID %d from %s", tvdbID, symlinkPath)
+// handleBrokenSymlinkForSeries processes the broken symlinks of a single series
+func (s *CleanupServiceImpl) handleBrokenSymlinkForSeries(ctx context.Context, group brokenSymlinkGroup, rootFolders []models.RootFolder) (models.CleanupStats, error) {
+	stats := models.CleanupStats{TotalItemsChecked: len(group.Paths)}
+	tvdbID, match := group.ID, group.Match
+	logger := WithItem(s.logger, ItemFields{Service: s.client.GetName(), Path: group.Paths[0]})
 
-	// Delete the broken symlink before processing (if not in dry-run mode)
-	if !s.dryRun {
-		s.logger.Info("🗑️  Deleting broken symlink: %s", symlinkPath)
-		if err := s.fileChecker.DeleteSymlink(symlinkPath); err != nil {
-			s.logger.Error("Failed to delete broken symlink %s: %s", symlinkPath, err.Error())
-			stats.Errors++
-			return stats, fmt.Errorf("failed to delete broken symlink %s: %w", symlinkPath, err)
-		}
-		s.logger.Info("✅ Successfully deleted broken symlink: %s", symlinkPath)
-	} else {
-		s.logger.Info("🏃 DRY RUN: Would delete broken symlink: %s", symlinkPath)
+	logger.Debug("Processing %d broken symlink(s) of TVDB ID %d", len(group.Paths), tvdbID)
+
+	if !s.confidentTitleMatch(match) {
+		s.reportUnconfidentMatch("series", group, models.MissingFileEntry{TVDBID: tvdbID})
+		stats.MissingFiles += len(group.Paths)
+		return stats, nil
 	}
 
+	// Delete the broken symlinks before processing (if not in dry-run mode)
+	symlinkPaths, err := s.deleteBrokenSymlinks(group, models.PlannedAction{MediaType: "series", TVDBID: tvdbID}, &stats)
+	if err != nil || len(symlinkPaths) == 0 {
+		return stats, err
+	}
+	symlinkPath := symlinkPaths[0]
+
 	// Check if series already exists in Sonarr collection
-	existingSeries, err := s.client.GetSeriesByTVDBID(ctx, tvdbID)
+	existingSeries, err := s.seriesByTVDBID(ctx, tvdbID)
 	if err == nil {
 		// Series already exists in collection
-		s.logger.Debug("Series with TVDB ID %d already exists in collection: %s", tvdbID, existingSeries.Title)
+		logger = WithItem(logger, ItemFields{SeriesID: existingSeries.ID})
+		logger.Debug("Series with TVDB ID %d already exists in collection: %s", tvdbID, existingSeries.Title)
 
 		// Add to missing files report but don't add to collection
 		missingEntry := models.MissingFileEntry{
 			MediaType:         "series",
 			MediaName:         existingSeries.Title,
-			FilePath:          symlinkPath,
 			FileID:            0, // No file ID since it's a broken symlink
 			ProcessedAt:       time.Now().Format(time.RFC3339),
 			AddedToCollection: false,
 			TVDBID:            tvdbID,
+			Reason:            models.ReasonBrokenSymlink,
+			MatchConfidence:   match.confidence(),
 		}
-		s.addMissingFileEntry(missingEntry)
-		stats.MissingFiles++
+		s.addSeriesSymlinkEntries(missingEntry, symlinkPaths, func(path string, entry *models.MissingFileEntry) {
+			s.describeSymlinkEpisode(ctx, existingSeries.ID, path, entry)
+		})
+		stats.MissingFiles += len(symlinkPaths)
 		return stats, nil
 	}
 
 	// Series not found in collection, need to add it
-	s.logger.Info("Series with TVDB ID %d not found in collection, looking up details...", tvdbID)
+	logger.Info("Series with TVDB ID %d not found in collection, looking up details...", tvdbID)
 
 	// Lookup series details from TVDB
 	seriesLookup, err := s.client.LookupSeriesByTVDBID(ctx, tvdbID)
@@ -1134,7 +2685,7 @@ func (s *CleanupServiceImpl) handleBrokenSymlinkForSeries(ctx context.Context, s
 	// If no matching root folder found, use the first one
 	if selectedRootFolder == nil && len(rootFolders) > 0 {
 		selectedRootFolder = &rootFolders[0]
-		s.logger.Debug("Using first available root folder: %s", selectedRootFolder.Path)
+		logger.Debug("Using first available root folder: %s", selectedRootFolder.Path)
 	}
 
 	if selectedRootFolder == nil {
@@ -1152,34 +2703,55 @@ func (s *CleanupServiceImpl) handleBrokenSymlinkForSeries(ctx context.Context, s
 		RootFolderPath:   selectedRootFolder.Path,
 	}
 
-	if s.addMissingMovies && !s.dryRun {
+	addAction := models.PlannedAction{
+		Action:    models.ActionAddSeries,
+		MediaType: "series",
+		MediaName: seriesLookup.Title,
+		Path:      symlinkPath,
+		TVDBID:    tvdbID,
+	}
+	addAllowed := s.scope.Allows(addAction)
+	reportOnly := s.reportOnly()
+
+	if s.addMissingMovies && !addAllowed {
+		logger.Info("⏭️  Not adding series %s: not listed in %s", seriesLookup.Title, s.scope.Source)
+	} else if s.addMissingMovies && !reportOnly {
 		// Add series to Sonarr collection
-		s.logger.Info("Adding series to collection: %s", seriesLookup.Title)
+		logger.Info("Adding series to collection: %s", seriesLookup.Title)
 		addedSeries, err := s.client.AddSeries(ctx, seriesToAdd)
 		if err != nil {
 			return stats, fmt.Errorf("failed to add series %s: %w", seriesLookup.Title, err)
 		}
 
-		// Update our series info cache
+		// Update our series info caches
 		s.setSeriesInfo(addedSeries.ID, addedSeries.Title)
-	} else if s.dryRun {
-		s.logger.Info("🏃 DRY RUN: Would add series to collection: %s", seriesLookup.Title)
+		s.mediaCache.AddSeries(*addedSeries)
+		s.saveMediaCache()
+	} else if reportOnly {
+		logger.Info("🏃 DRY RUN: Would add series to collection: %s", seriesLookup.Title)
+		if s.addMissingMovies {
+			addAction.AddCheck = s.checkAdd(ctx, seriesLookup.Title, *selectedRootFolder)
+			s.planCall(logger, &addAction, mapModelsSeriesToSonarrInput(seriesToAdd))
+			s.addPlannedAction(addAction)
+		}
 	} else if !s.addMissingMovies {
-		s.logger.Info("📋 ADD_MISSING_MOVIES=false: Would add series to collection: %s", seriesLookup.Title)
+		logger.Info("📋 ADD_MISSING_MOVIES=false: Would add series to collection: %s", seriesLookup.Title)
 	}
 
 	// Add to missing files report
 	missingEntry := models.MissingFileEntry{
 		MediaType:         "series",
 		MediaName:         seriesLookup.Title,
-		FilePath:          symlinkPath,
 		FileID:            0, // No file ID since it's a broken symlink
 		ProcessedAt:       time.Now().Format(time.RFC3339),
-		AddedToCollection: s.addMissingMovies && !s.dryRun,
+		AddedToCollection: s.addMissingMovies && !reportOnly && addAllowed,
 		TVDBID:            tvdbID,
+		Reason:            models.ReasonBrokenSymlink,
+		MatchConfidence:   match.confidence(),
+		AddCheck:          addAction.AddCheck,
 	}
-	s.addMissingFileEntry(missingEntry)
-	stats.MissingFiles++
+	s.addSeriesSymlinkEntries(missingEntry, symlinkPaths, nil)
+	stats.MissingFiles += len(symlinkPaths)
 
 	return stats, nil
 }
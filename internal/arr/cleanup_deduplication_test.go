@@ -114,6 +114,18 @@ func TestCleanupServiceImpl_deduplicateMissingFiles(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "series symlinks - one entry per episode",
+			input: []models.MissingFileEntry{
+				{MediaType: "series", MediaName: "Show", FilePath: "/tv/Show/S01E01.mkv", TVDBID: 81797, Season: intPtr(1), Episode: intPtr(1), ProcessedAt: "2025-09-02T17:43:55Z"},
+				{MediaType: "series", MediaName: "Show", FilePath: "/tv/Show/S01E02.mkv", TVDBID: 81797, Season: intPtr(1), Episode: intPtr(2), ProcessedAt: "2025-09-02T17:43:56Z"},
+				{MediaType: "series", MediaName: "Show", FilePath: "/tv/Show/S01E02.mkv", TVDBID: 81797, Season: intPtr(1), Episode: intPtr(2), ProcessedAt: "2025-09-02T17:44:18Z"},
+			},
+			expected: []models.MissingFileEntry{
+				{MediaType: "series", MediaName: "Show", FilePath: "/tv/Show/S01E01.mkv", TVDBID: 81797, Season: intPtr(1), Episode: intPtr(1), ProcessedAt: "2025-09-02T17:43:55Z"},
+				{MediaType: "series", MediaName: "Show", FilePath: "/tv/Show/S01E02.mkv", TVDBID: 81797, Season: intPtr(1), Episode: intPtr(2), ProcessedAt: "2025-09-02T17:44:18Z"},
+			},
+		},
 		{
 			name: "series entries - use file path for deduplication",
 			input: []models.MissingFileEntry{
@@ -209,3 +221,33 @@ func TestCleanupServiceImpl_deduplicateMissingFiles_EdgeCases(t *testing.T) {
 		t.Errorf("Expected FileID 123, got %d", result[0].FileID)
 	}
 }
+
+func TestMostAffected(t *testing.T) {
+	report := &models.MissingFilesReport{
+		MissingFiles: []models.MissingFileEntry{
+			{MediaType: "series", MediaName: "Show B", FilePath: "/tv/b/1.mkv"},
+			{MediaType: "series", MediaName: "Show A", FilePath: "/tv/a/1.mkv"},
+			{MediaType: "series", MediaName: "Show C", FilePath: "/tv/c/1.mkv"},
+			{MediaType: "series", MediaName: "Show C", FilePath: "/tv/c/2.mkv"},
+			{MediaType: "series", MediaName: "Show C", FilePath: "/tv/c/3.mkv"},
+		},
+	}
+
+	got := mostAffected(report, 2)
+	expected := []models.AffectedItem{
+		{MediaType: "series", MediaName: "Show C", Missing: 3},
+		{MediaType: "series", MediaName: "Show A", Missing: 1},
+	}
+	if len(got) != len(expected) {
+		t.Fatalf("mostAffected() returned %d items, expected %d: %+v", len(got), len(expected), got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("mostAffected()[%d] = %+v, expected %+v", i, got[i], expected[i])
+		}
+	}
+
+	if items := mostAffected(&models.MissingFilesReport{}, 2); items != nil {
+		t.Errorf("Expected no items for an empty report, got %+v", items)
+	}
+}
@@ -1,13 +1,20 @@
 package arr
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/hnipps/refresharr/internal/config"
 	"github.com/hnipps/refresharr/pkg/models"
 )
 
@@ -25,8 +32,21 @@ type mockClient struct {
 	deleteEpisodeFileError error
 	updateEpisodeError     error
 	triggerRefreshError    error
+	refreshTriggered       int
 	deletedFileIDs         []int
 	updatedEpisodes        []models.Episode
+	queue                  []models.QueueItem
+	imdbLookups            map[string]*models.MovieLookup // IMDb ID -> lookup result
+	movieLookups           []models.MovieLookup           // Results of every title lookup
+	seriesLookups          []models.SeriesLookup          // Results of every title lookup
+	addedMovies            []models.Movie
+	mu                     sync.Mutex // Guards addedMovies
+	qualityProfiles        []models.QualityProfile
+	rootFolders            []models.RootFolder
+	manualImports          map[string][]models.ManualImportItem // folder -> files Sonarr offers for manual import
+	importedFiles          []models.ManualImportItem
+	blocklisted            []int // Queue item IDs removed with their release blocklisted
+	searchedEpisodes       []int
 }
 
 func (m *mockClient) GetName() string {
@@ -60,13 +80,24 @@ func (m *mockClient) GetEpisodesForSeries(ctx context.Context, seriesID int) ([]
 	return episodes, nil
 }
 
+func (m *mockClient) GetEpisode(ctx context.Context, episodeID int) (*models.Episode, error) {
+	for _, episodes := range m.episodes {
+		for i := range episodes {
+			if episodes[i].ID == episodeID {
+				return &episodes[i], nil
+			}
+		}
+	}
+	return nil, notFoundError("episode %d not found", episodeID)
+}
+
 func (m *mockClient) GetEpisodeFile(ctx context.Context, fileID int) (*models.EpisodeFile, error) {
 	if m.episodeFileError != nil {
 		return nil, m.episodeFileError
 	}
 	file, exists := m.episodeFiles[fileID]
 	if !exists {
-		return nil, errors.New("episode file not found")
+		return nil, notFoundError("episode file %d not found", fileID)
 	}
 	return file, nil
 }
@@ -100,28 +131,66 @@ func (m *mockClient) UpdateMovie(ctx context.Context, movie models.Movie) error
 }
 
 func (m *mockClient) TriggerRefresh(ctx context.Context) error {
+	m.refreshTriggered++
 	return m.triggerRefreshError
 }
 
 // New methods for broken symlink functionality (stubs for testing)
 func (m *mockClient) GetRootFolders(ctx context.Context) ([]models.RootFolder, error) {
-	return nil, errors.New("GetRootFolders not implemented in mock")
+	if m.rootFolders == nil {
+		return nil, errors.New("GetRootFolders not implemented in mock")
+	}
+	return m.rootFolders, nil
 }
 
 func (m *mockClient) GetQualityProfiles(ctx context.Context) ([]models.QualityProfile, error) {
-	return nil, errors.New("GetQualityProfiles not implemented in mock")
+	if m.qualityProfiles == nil {
+		return nil, errors.New("GetQualityProfiles not implemented in mock")
+	}
+	return m.qualityProfiles, nil
 }
 
 func (m *mockClient) LookupMovieByTMDBID(ctx context.Context, tmdbID int) (*models.MovieLookup, error) {
+	for _, lookup := range m.movieLookups {
+		if lookup.TMDBID == tmdbID {
+			return &lookup, nil
+		}
+	}
 	return nil, errors.New("LookupMovieByTMDBID not implemented in mock")
 }
 
+func (m *mockClient) LookupMovieByIMDBID(ctx context.Context, imdbID string) (*models.MovieLookup, error) {
+	if lookup, ok := m.imdbLookups[imdbID]; ok {
+		return lookup, nil
+	}
+	return nil, notFoundError("movie with IMDb ID %s not found", imdbID)
+}
+
+func (m *mockClient) LookupMoviesByTerm(ctx context.Context, term string) ([]models.MovieLookup, error) {
+	return m.movieLookups, nil
+}
+
+func (m *mockClient) LookupSeriesByTerm(ctx context.Context, term string) ([]models.SeriesLookup, error) {
+	return m.seriesLookups, nil
+}
+
 func (m *mockClient) GetMovieByTMDBID(ctx context.Context, tmdbID int) (*models.Movie, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, movie := range m.addedMovies {
+		if movie.TMDBID == tmdbID {
+			return &movie, nil
+		}
+	}
 	return nil, errors.New("GetMovieByTMDBID not implemented in mock")
 }
 
 func (m *mockClient) AddMovie(ctx context.Context, movie models.Movie) (*models.Movie, error) {
-	return nil, errors.New("AddMovie not implemented in mock")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.addedMovies = append(m.addedMovies, movie)
+	movie.ID = 1000 + len(m.addedMovies)
+	return &movie, nil
 }
 
 func (m *mockClient) AddSeries(ctx context.Context, series models.Series) (*models.Series, error) {
@@ -137,7 +206,20 @@ func (m *mockClient) LookupSeriesByTVDBID(ctx context.Context, tvdbID int) (*mod
 }
 
 func (m *mockClient) GetQueue(ctx context.Context) ([]models.QueueItem, error) {
-	return []models.QueueItem{}, nil
+	if m.queue == nil {
+		return []models.QueueItem{}, nil
+	}
+	return m.queue, nil
+}
+
+func (m *mockClient) GetQueueWithOptions(ctx context.Context, opts models.QueueOptions) ([]models.QueueItem, error) {
+	var result []models.QueueItem
+	for _, item := range m.queue {
+		if opts.Matches(item) {
+			result = append(result, item)
+		}
+	}
+	return result, nil
 }
 
 func (m *mockClient) GetQueueDetails(ctx context.Context, queueID int) (*models.QueueItem, error) {
@@ -148,25 +230,37 @@ func (m *mockClient) RemoveFromQueue(ctx context.Context, queueID int, removeFro
 	return nil
 }
 
+func (m *mockClient) BlocklistQueueItem(ctx context.Context, queueID int, removeFromClient bool) error {
+	m.blocklisted = append(m.blocklisted, queueID)
+	return nil
+}
+
+func (m *mockClient) SearchEpisodes(ctx context.Context, episodeIDs []int) error {
+	m.searchedEpisodes = append(m.searchedEpisodes, episodeIDs...)
+	return nil
+}
+
 func (m *mockClient) TriggerDownloadClientScan(ctx context.Context) error {
 	return nil
 }
 
 func (m *mockClient) GetManualImport(ctx context.Context, folder string) ([]models.ManualImportItem, error) {
-	return []models.ManualImportItem{}, nil
+	return m.manualImports[folder], nil
 }
 
 func (m *mockClient) GetManualImportWithParams(ctx context.Context, folder, downloadID string, seriesID int, filterExisting bool) ([]models.ManualImportItem, error) {
-	return []models.ManualImportItem{}, nil
+	return m.manualImports[folder], nil
 }
 
 func (m *mockClient) ExecuteManualImport(ctx context.Context, files []models.ManualImportItem, importMode string) error {
+	m.importedFiles = append(m.importedFiles, files...)
 	return nil
 }
 
 type mockFileChecker struct {
 	fileExists map[string]bool
 	readable   map[string]bool
+	sizes      map[string]int64
 }
 
 func (m *mockFileChecker) FileExists(path string) bool {
@@ -185,6 +279,14 @@ func (m *mockFileChecker) IsReadable(path string) bool {
 	return readable
 }
 
+func (m *mockFileChecker) FileSize(path string) (int64, error) {
+	size, found := m.sizes[path]
+	if !found {
+		return 0, errors.New("size not available")
+	}
+	return size, nil
+}
+
 func (m *mockFileChecker) IsSymlink(path string) bool {
 	// For testing, assume any path with "symlink" in it is a symlink
 	return strings.Contains(path, "symlink")
@@ -387,6 +489,778 @@ func TestCleanupService_CleanupMissingFiles_Success(t *testing.T) {
 	}
 }
 
+// mockSearchGate implements SearchGate for testing
+type mockSearchGate struct {
+	allowed bool
+	reason  string
+	err     error
+}
+
+func (m *mockSearchGate) SearchAllowed(ctx context.Context) (bool, string, error) {
+	return m.allowed, m.reason, m.err
+}
+
+func TestCleanupService_SearchGate(t *testing.T) {
+	tests := []struct {
+		name          string
+		gate          *mockSearchGate
+		wantTriggered int
+		wantSkipped   string
+	}{
+		{"allowed", &mockSearchGate{allowed: true}, 1, ""},
+		{"indexers down", &mockSearchGate{reason: "all 2 Prowlarr indexers are down or rate-limited"}, 0, "all 2 Prowlarr indexers are down or rate-limited"},
+		{"health check failed", &mockSearchGate{err: fmt.Errorf("connection refused")}, 1, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &mockClient{
+				name:      "sonarr",
+				allSeries: []models.Series{{MediaItem: models.MediaItem{ID: 1, Title: "Test Series"}}},
+				episodes: map[int][]models.Episode{
+					1: {{ID: 1, SeriesID: 1, SeasonNumber: 1, EpisodeNumber: 1, HasFile: true, EpisodeFileID: intPtr(100)}},
+				},
+				episodeFiles: map[int]*models.EpisodeFile{
+					100: {ID: 100, Path: "/path/to/missing/episode1.mkv"},
+				},
+			}
+			fileChecker := &mockFileChecker{fileExists: map[string]bool{}}
+
+			service := NewCleanupServiceWithOptions(client, fileChecker, &mockLogger{}, &mockProgressReporter{}, CleanupOptions{
+				ConcurrentLimit: 1,
+				SearchGate:      tt.gate,
+			})
+
+			result, err := service.CleanupMissingFiles(context.Background())
+			if err != nil {
+				t.Fatalf("CleanupMissingFiles() failed: %v", err)
+			}
+			if result.Stats.DeletedRecords != 1 {
+				t.Fatalf("Expected 1 deleted record, got %d", result.Stats.DeletedRecords)
+			}
+			if client.refreshTriggered != tt.wantTriggered {
+				t.Errorf("Expected %d search triggers, got %d", tt.wantTriggered, client.refreshTriggered)
+			}
+			if result.Report.SearchSkipped != tt.wantSkipped {
+				t.Errorf("Expected report SearchSkipped %q, got %q", tt.wantSkipped, result.Report.SearchSkipped)
+			}
+		})
+	}
+}
+
+func TestCleanupService_SeasonFilter(t *testing.T) {
+	client := &mockClient{
+		name: "sonarr",
+		episodes: map[int][]models.Episode{
+			1: {
+				{ID: 1, SeriesID: 1, SeasonNumber: 1, EpisodeNumber: 1, HasFile: true, EpisodeFileID: intPtr(100)},
+				{ID: 2, SeriesID: 1, SeasonNumber: 2, EpisodeNumber: 1, HasFile: true, EpisodeFileID: intPtr(200)},
+				{ID: 3, SeriesID: 1, SeasonNumber: 2, EpisodeNumber: 2, HasFile: true, EpisodeFileID: intPtr(300)},
+			},
+		},
+		episodeFiles: map[int]*models.EpisodeFile{
+			100: {ID: 100, Path: "/tv/show/s01e01.mkv"},
+			200: {ID: 200, Path: "/tv/show/s02e01.mkv"},
+			300: {ID: 300, Path: "/tv/show/s02e02.mkv"},
+		},
+	}
+	// Every file is missing, but only season 2 may be cleaned
+	fileChecker := &mockFileChecker{fileExists: map[string]bool{}}
+	logger := &mockLogger{}
+
+	service := NewCleanupServiceWithOptions(client, fileChecker, logger, &mockProgressReporter{}, CleanupOptions{
+		ConcurrentLimit: 1,
+		Seasons:         []int{2},
+	})
+
+	result, err := service.CleanupMissingFilesForSeries(context.Background(), []int{1})
+	if err != nil {
+		t.Fatalf("CleanupMissingFilesForSeries() failed: %v", err)
+	}
+
+	if result.Stats.TotalItemsChecked != 2 {
+		t.Errorf("Expected 2 items checked, got %d", result.Stats.TotalItemsChecked)
+	}
+	if len(client.deletedFileIDs) != 2 {
+		t.Fatalf("Expected 2 deleted records, got %v", client.deletedFileIDs)
+	}
+	for _, id := range client.deletedFileIDs {
+		if id == 100 {
+			t.Error("Expected season 1 file record to be left alone")
+		}
+	}
+
+	// The library-wide broken symlink scan must not run for a season-restricted run
+	for _, msg := range logger.infoMessages {
+		if strings.Contains(msg, "Checking for broken symlinks") {
+			t.Error("Expected broken symlink scan to be skipped")
+		}
+	}
+}
+
+func TestCleanupService_SkipSpecials(t *testing.T) {
+	newClient := func() *mockClient {
+		return &mockClient{
+			name: "sonarr",
+			episodes: map[int][]models.Episode{
+				1: {
+					{ID: 1, SeriesID: 1, SeasonNumber: 0, EpisodeNumber: 1, HasFile: true, EpisodeFileID: intPtr(100)},
+					{ID: 2, SeriesID: 1, SeasonNumber: 1, EpisodeNumber: 1, HasFile: true, EpisodeFileID: intPtr(200)},
+				},
+			},
+			episodeFiles: map[int]*models.EpisodeFile{
+				100: {ID: 100, Path: "/tv/show/Specials/s00e01.mkv"},
+				200: {ID: 200, Path: "/tv/show/Season 1/s01e01.mkv"},
+			},
+		}
+	}
+
+	// Every file is missing, but the special is left alone
+	client := newClient()
+	service := NewCleanupServiceWithOptions(client, &mockFileChecker{fileExists: map[string]bool{}}, &mockLogger{}, &mockProgressReporter{}, CleanupOptions{
+		ConcurrentLimit: 1,
+		SkipSpecials:    true,
+	})
+	if _, err := service.CleanupMissingFilesForSeries(context.Background(), []int{1}); err != nil {
+		t.Fatalf("CleanupMissingFilesForSeries() failed: %v", err)
+	}
+	if len(client.deletedFileIDs) != 1 || client.deletedFileIDs[0] != 200 {
+		t.Errorf("Expected only file record 200 to be deleted, got %v", client.deletedFileIDs)
+	}
+
+	// Selecting season 0 explicitly still processes it
+	client = newClient()
+	service = NewCleanupServiceWithOptions(client, &mockFileChecker{fileExists: map[string]bool{}}, &mockLogger{}, &mockProgressReporter{}, CleanupOptions{
+		ConcurrentLimit: 1,
+		SkipSpecials:    true,
+		Seasons:         []int{0},
+	})
+	if _, err := service.CleanupMissingFilesForSeries(context.Background(), []int{1}); err != nil {
+		t.Fatalf("CleanupMissingFilesForSeries() failed: %v", err)
+	}
+	if len(client.deletedFileIDs) != 1 || client.deletedFileIDs[0] != 100 {
+		t.Errorf("Expected only file record 100 to be deleted, got %v", client.deletedFileIDs)
+	}
+
+	for path, want := range map[string]bool{
+		"/tv/Show (2020) {tvdb-1}/Specials/S00E01.mkv":   true,
+		"/tv/Show (2020) {tvdb-1}/Season 00/S00E01.mkv":  true,
+		"/tv/Show (2020) {tvdb-1}/Season 10/S10E01.mkv":  false,
+		"/tv/Show (2020) {tvdb-1}/Season 1/Specials.mkv": false,
+	} {
+		if got := isSpecialsPath(path); got != want {
+			t.Errorf("isSpecialsPath(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestCleanupService_BrokenSymlinkEpisodes(t *testing.T) {
+	cache := NewMediaCache(MediaCachePath(t.TempDir(), "sonarr"), time.Hour)
+	cache.SetSeries([]models.Series{{MediaItem: models.MediaItem{ID: 7, Title: "One Piece"}, TVDBID: 81797}})
+	client := &mockClient{
+		name: "sonarr",
+		episodes: map[int][]models.Episode{
+			7: {
+				{ID: 1, SeriesID: 7, SeasonNumber: 21, EpisodeNumber: 79, AbsoluteEpisodeNumber: 1070, Title: "Seventy-Nine"},
+				{ID: 2, SeriesID: 7, SeasonNumber: 21, EpisodeNumber: 80, AbsoluteEpisodeNumber: 1071, Title: "Eighty"},
+			},
+		},
+	}
+
+	// Absolute and season numbering both map to the same episode
+	for _, path := range []string{
+		"/anime/One Piece [tvdb-81797]/Season 21/[SubsPlease] One Piece - 1071 (1080p) [ABCD1234].mkv",
+		"/anime/One Piece [tvdb-81797]/Season 21/One Piece - S21E80 - Eighty.mkv",
+	} {
+		service := NewCleanupServiceWithOptions(client, &mockFileChecker{}, &mockLogger{}, &mockProgressReporter{}, CleanupOptions{
+			ConcurrentLimit: 1,
+			DryRun:          true,
+			MediaCache:      cache,
+		}).(*CleanupServiceImpl)
+		if stats := handleSeriesSymlinks(service, nil, path); stats.Errors != 0 {
+			t.Fatalf("Expected %s to be handled, got %+v", path, stats)
+		}
+
+		entries := service.buildReport().MissingFiles
+		if len(entries) != 1 {
+			t.Fatalf("Expected 1 report entry, got %+v", entries)
+		}
+		entry := entries[0]
+		if entry.Season == nil || *entry.Season != 21 || entry.Episode == nil || *entry.Episode != 80 || entry.EpisodeName != "Eighty" {
+			t.Errorf("Expected %s to map to S21E80, got %+v", path, entry)
+		}
+	}
+
+	// Broken symlinks of several episodes are handled as one series, but each keeps its entry
+	service := NewCleanupServiceWithOptions(client, &mockFileChecker{}, &mockLogger{}, &mockProgressReporter{}, CleanupOptions{
+		ConcurrentLimit: 1,
+		DryRun:          true,
+		MediaCache:      cache,
+	}).(*CleanupServiceImpl)
+	stats := handleSeriesSymlinks(service, nil,
+		"/anime/One Piece [tvdb-81797]/Season 21/One Piece - S21E79 - Seventy-Nine.mkv",
+		"/anime/One Piece [tvdb-81797]/Season 21/One Piece - S21E80 - Eighty.mkv",
+	)
+	entries := service.buildReport().MissingFiles
+	if len(entries) != 2 || stats.MissingFiles != len(entries) {
+		t.Fatalf("Expected 2 entries matching %d missing files, got %+v", stats.MissingFiles, entries)
+	}
+	for _, entry := range entries {
+		if entry.Episode == nil || entry.FilePaths != nil || !strings.Contains(entry.FilePath, fmt.Sprintf("S21E%d", *entry.Episode)) {
+			t.Errorf("Expected an entry of its own episode, got %+v", entry)
+		}
+	}
+}
+
+func TestCleanupService_BrokenSymlinkIMDBFallback(t *testing.T) {
+	cache := NewMediaCache(MediaCachePath(t.TempDir(), "radarr"), time.Hour)
+	cache.SetMovies([]models.Movie{{MediaItem: models.MediaItem{ID: 3, Title: "The Shawshank Redemption"}, TMDBID: 278, HasFile: true}})
+	client := &mockClient{
+		name:        "radarr",
+		imdbLookups: map[string]*models.MovieLookup{"tt0111161": {TMDBID: 278, IMDBID: "tt0111161", Title: "The Shawshank Redemption"}},
+	}
+	service := NewCleanupServiceWithOptions(client, &mockFileChecker{}, &mockLogger{}, &mockProgressReporter{}, CleanupOptions{
+		ConcurrentLimit: 1,
+		DryRun:          true,
+		MediaCache:      cache,
+	}).(*CleanupServiceImpl)
+
+	if stats := handleMovieSymlinks(service, nil, "/movies/The Shawshank Redemption (1994) [imdbid-tt0111161]/movie.mkv"); stats.Errors != 0 {
+		t.Fatalf("Expected the symlink to be handled, got %+v", stats)
+	}
+	entries := service.buildReport().MissingFiles
+	if len(entries) != 1 || entries[0].TMDBID != 278 || entries[0].MediaName != "The Shawshank Redemption" {
+		t.Errorf("Expected the IMDb tag to resolve to TMDB ID 278, got %+v", entries)
+	}
+
+	// An IMDb ID Radarr doesn't know is skipped like an untagged path
+	stats := handleMovieSymlinks(service, nil, "/movies/Unknown (2001) [imdb-tt9999999]/movie.mkv")
+	if stats.Errors != 0 || stats.MissingFiles != 0 {
+		t.Errorf("Expected the unknown IMDb ID to be skipped, got %+v", stats)
+	}
+}
+
+func TestCleanupService_BrokenSymlinkTitleFallback(t *testing.T) {
+	client := &mockClient{
+		name: "radarr",
+		movieLookups: []models.MovieLookup{
+			{TMDBID: 603, Title: "The Matrix", Year: 1999},
+			{TMDBID: 604, Title: "The Matrix Reloaded", Year: 2003},
+		},
+	}
+	newService := func() *CleanupServiceImpl {
+		return NewCleanupServiceWithOptions(client, &mockFileChecker{}, &mockLogger{}, &mockProgressReporter{}, CleanupOptions{
+			ConcurrentLimit:  1,
+			DryRun:           true,
+			AddMissingMovies: true,
+		}).(*CleanupServiceImpl)
+	}
+	rootFolders := []models.RootFolder{{ID: 1, Path: "/movies"}}
+
+	// An exact title and year is confident enough to add
+	service := newService()
+	if stats := handleMovieSymlinks(service, rootFolders, "/movies/The Matrix (1999)/The Matrix (1999).mkv"); stats.Errors != 0 {
+		t.Fatalf("Expected the symlink to be handled, got %+v", stats)
+	}
+	entries := service.buildReport().MissingFiles
+	if len(entries) != 1 || entries[0].TMDBID != 603 || entries[0].MatchConfidence != 1 {
+		t.Errorf("Expected a confident match with TMDB ID 603, got %+v", entries)
+	}
+	if len(service.plannedActions) != 2 || service.plannedActions[1].Action != models.ActionAddMovie {
+		t.Errorf("Expected the symlink deletion and movie add to be planned, got %+v", service.plannedActions)
+	}
+
+	// A loose match is only reported, and the symlink is kept for review
+	service = newService()
+	if stats := handleMovieSymlinks(service, rootFolders, "/movies/Matrix (2000)/movie.mkv"); stats.Errors != 0 {
+		t.Fatalf("Expected the symlink to be handled, got %+v", stats)
+	}
+	entries = service.buildReport().MissingFiles
+	if len(entries) != 1 || entries[0].TMDBID != 603 || entries[0].MatchConfidence <= 0 || entries[0].MatchConfidence >= DefaultTitleMatchConfidence {
+		t.Errorf("Expected a low-confidence match with TMDB ID 603, got %+v", entries)
+	}
+	if len(service.plannedActions) != 0 {
+		t.Errorf("Expected nothing to be planned for a low-confidence match, got %+v", service.plannedActions)
+	}
+
+	// A title no result resembles is skipped like an untagged path
+	stats := handleMovieSymlinks(newService(), rootFolders, "/movies/Something Else Entirely (1999)/movie.mkv")
+	if stats.Errors != 0 || stats.MissingFiles != 0 {
+		t.Errorf("Expected the unmatched title to be skipped, got %+v", stats)
+	}
+}
+
+func TestCleanupService_MonitorCollections(t *testing.T) {
+	for _, monitor := range []bool{false, true} {
+		client := &mockClient{
+			name: "radarr",
+			movieLookups: []models.MovieLookup{
+				{TMDBID: 603, Title: "The Matrix", Year: 1999, Collection: &models.MovieCollection{TMDBID: 2344, Title: "The Matrix Collection"}},
+			},
+		}
+		service := NewCleanupServiceWithOptions(client, &mockFileChecker{}, &mockLogger{}, &mockProgressReporter{}, CleanupOptions{
+			ConcurrentLimit:    1,
+			AddMissingMovies:   true,
+			MonitorCollections: monitor,
+		}).(*CleanupServiceImpl)
+
+		rootFolders := []models.RootFolder{{ID: 1, Path: "/movies"}}
+		if stats := handleMovieSymlinks(service, rootFolders, "/movies/The Matrix (1999) [tmdb-603]/movie.mkv"); stats.Errors != 0 {
+			t.Fatalf("Expected the symlink to be handled, got %+v", stats)
+		}
+		if len(client.addedMovies) != 1 {
+			t.Fatalf("Expected the movie to be added, got %+v", client.addedMovies)
+		}
+		options := client.addedMovies[0].AddOptions
+		if monitor && (options == nil || options.Monitor != models.MonitorMovieAndCollection) {
+			t.Errorf("Expected the collection to be monitored, got %+v", options)
+		}
+		if !monitor && options != nil {
+			t.Errorf("Expected Radarr's default add options without MonitorCollections, got %+v", options)
+		}
+	}
+}
+
+func TestCleanupService_ValidateAdds(t *testing.T) {
+	accessible, unreachable := true, false
+	tests := []struct {
+		name       string
+		profiles   []models.QualityProfile
+		rootFolder models.RootFolder
+		want       string
+	}{
+		{"would succeed", []models.QualityProfile{{ID: 12, Name: "HD"}}, models.RootFolder{Path: "/movies", Accessible: &accessible}, models.AddCheckOK},
+		{"missing profile", []models.QualityProfile{{ID: 4, Name: "SD"}}, models.RootFolder{Path: "/movies"}, "quality profile 12 does not exist"},
+		{"unreachable root folder", []models.QualityProfile{{ID: 12, Name: "HD"}}, models.RootFolder{Path: "/movies", Accessible: &unreachable}, "root folder /movies is not accessible"},
+		{"profiles unavailable", nil, models.RootFolder{Path: "/movies"}, "quality profiles could not be fetched: GetQualityProfiles not implemented in mock"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &mockClient{
+				name:            "radarr",
+				movieLookups:    []models.MovieLookup{{TMDBID: 603, Title: "The Matrix", Year: 1999}},
+				qualityProfiles: tt.profiles,
+			}
+			service := NewCleanupServiceWithOptions(client, &mockFileChecker{}, &mockLogger{}, &mockProgressReporter{}, CleanupOptions{
+				ConcurrentLimit:  1,
+				DryRun:           true,
+				QualityProfileID: 12,
+				AddMissingMovies: true,
+				ValidateAdds:     true,
+			}).(*CleanupServiceImpl)
+
+			rootFolders := []models.RootFolder{tt.rootFolder}
+			if stats := handleMovieSymlinks(service, rootFolders, "/movies/The Matrix (1999) [tmdb-603]/movie.mkv"); stats.Errors != 0 {
+				t.Fatalf("Expected the symlink to be handled, got %+v", stats)
+			}
+			if len(client.addedMovies) != 0 {
+				t.Errorf("Expected nothing to be added in a dry run, got %+v", client.addedMovies)
+			}
+			entries := service.buildReport().MissingFiles
+			if len(entries) != 1 || entries[0].AddCheck != tt.want {
+				t.Errorf("Expected add check %q, got %+v", tt.want, entries)
+			}
+			if len(service.plannedActions) != 2 || service.plannedActions[1].AddCheck != tt.want {
+				t.Errorf("Expected the planned add to carry the check, got %+v", service.plannedActions)
+			}
+		})
+	}
+}
+
+func TestPlannedCall(t *testing.T) {
+	tests := []struct {
+		name    string
+		service string
+		action  models.PlannedAction
+		payload interface{}
+		want    string
+	}{
+		{"episode file", "sonarr", models.PlannedAction{Action: models.ActionDeleteEpisodeFile, FileID: 123}, nil, "DELETE /api/v3/episodeFile/123"},
+		{"movie file", "radarr", models.PlannedAction{Action: models.ActionDeleteMovieFile, FileID: 7}, nil, "DELETE /api/v3/moviefile/7"},
+		{"book file", "readarr", models.PlannedAction{Action: models.ActionDeleteBookFile, FileID: 9}, nil, "DELETE /api/v1/bookfile/9"},
+		{"add movie", "radarr", models.PlannedAction{Action: models.ActionAddMovie, TMDBID: 603},
+			models.Movie{TMDBID: 603, QualityProfileID: 12, RootFolderPath: "/movies", Monitored: true},
+			`POST /api/v3/movie {"id":0,"title":"","hasFile":false,"tmdbId":603,"monitored":true,"qualityProfileId":12,"rootFolderPath":"/movies"}`},
+		{"add series", "sonarr", models.PlannedAction{Action: models.ActionAddSeries, TVDBID: 81189},
+			mapModelsSeriesToSonarrInput(models.Series{MediaItem: models.MediaItem{Title: "Breaking Bad"}, TVDBID: 81189}),
+			`POST /api/v3/series {"monitored":false,"seasonFolder":true,"tvdbId":81189,"title":"Breaking Bad"}`},
+		{"path update", "sonarr", models.PlannedAction{Action: models.ActionUpdatePath, SeriesID: 5}, map[string]string{"path": "/tv/Show"},
+			`PUT /api/v3/series/5?moveFiles=false {"path":"/tv/Show"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			call := plannedCall(tt.service, tt.action, tt.payload)
+			if call == nil || call.String() != tt.want {
+				t.Errorf("plannedCall() = %v, want %q", call, tt.want)
+			}
+		})
+	}
+
+	if call := plannedCall("radarr", models.PlannedAction{Action: models.ActionDeleteSymlink, Path: "/movies/x.mkv"}, nil); call != nil {
+		t.Errorf("Expected no API call for a symlink deletion, got %v", call)
+	}
+}
+
+func TestCleanupService_ValidateAddSettings(t *testing.T) {
+	unreachable := false
+	profiles := []models.QualityProfile{{ID: 4, Name: "SD"}, {ID: 12, Name: "HD"}}
+	tests := []struct {
+		name        string
+		profileID   int
+		rootFolders []models.RootFolder
+		dryRun      bool
+		wantErr     string
+	}{
+		{"valid", 12, []models.RootFolder{{Path: "/movies"}}, false, ""},
+		{"missing profile", 7, []models.RootFolder{{Path: "/movies"}}, false, "quality profile 7 does not exist in radarr (available: 4 (SD), 12 (HD))"},
+		{"no root folders", 12, []models.RootFolder{}, false, "no root folders are configured in radarr"},
+		{"unreachable root folders", 12, []models.RootFolder{{Path: "/movies", Accessible: &unreachable}, {Path: "/4k", Accessible: &unreachable}}, false, "none of the root folders of radarr is accessible (/movies, /4k)"},
+		{"one reachable root folder", 12, []models.RootFolder{{Path: "/movies", Accessible: &unreachable}, {Path: "/4k"}}, false, ""},
+		{"dry run", 7, nil, true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &mockClient{name: "radarr", qualityProfiles: profiles, rootFolders: tt.rootFolders}
+			service := NewCleanupServiceWithOptions(client, &mockFileChecker{}, &mockLogger{}, &mockProgressReporter{}, CleanupOptions{
+				ConcurrentLimit:  1,
+				DryRun:           tt.dryRun,
+				QualityProfileID: tt.profileID,
+				AddMissingMovies: true,
+			})
+
+			_, err := service.CleanupMissingFiles(context.Background())
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected the run to start, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCleanupService_ProcessBrokenSymlinksConcurrently(t *testing.T) {
+	// Three symlinks of one movie, one of another, and one whose lookup fails
+	client := &mockClient{
+		name: "radarr",
+		movieLookups: []models.MovieLookup{
+			{TMDBID: 603, Title: "The Matrix", Year: 1999},
+			{TMDBID: 604, Title: "The Matrix Reloaded", Year: 2003},
+		},
+	}
+	service := NewCleanupServiceWithOptions(client, &mockFileChecker{}, &mockLogger{}, &mockProgressReporter{}, CleanupOptions{
+		ConcurrentLimit:  4,
+		AddMissingMovies: true,
+	}).(*CleanupServiceImpl)
+
+	paths := []string{
+		"/movies/The Matrix (1999) [tmdb-603]/The Matrix.mkv",
+		"/movies/The Matrix (1999) [tmdb-603]/The Matrix.4K.mkv",
+		"/movies/The Matrix (1999) [tmdb-603]/Extras/Trailer.mkv",
+		"/movies/The Matrix Reloaded (2003) [tmdb-604]/movie.mkv",
+		"/movies/Unknown (2000) [tmdb-999]/movie.mkv",
+	}
+	rootFolders := []models.RootFolder{{ID: 1, Path: "/movies"}}
+	stats := handleMovieSymlinks(service, rootFolders, paths...)
+
+	if stats.Errors != 1 || stats.TotalItemsChecked != 4 || stats.MissingFiles != 4 {
+		t.Errorf("Expected 4 handled symlinks and 1 isolated error, got %+v", stats)
+	}
+	if len(client.addedMovies) != 2 {
+		t.Errorf("Expected each movie to be added once, got %+v", client.addedMovies)
+	}
+
+	// The symlinks of one movie share a single report entry
+	entries := service.buildReport().MissingFiles
+	if len(entries) != 2 || entries[0].TMDBID != 603 || entries[0].FilePath != paths[0] || len(entries[0].FilePaths) != 3 {
+		t.Errorf("Expected one entry per movie listing all of its symlinks, got %+v", entries)
+	}
+	if len(entries) == 2 && (entries[1].TMDBID != 604 || entries[1].FilePaths != nil) {
+		t.Errorf("Expected a single symlink to need no path list, got %+v", entries[1])
+	}
+
+	// No more symlinks are handled at once than the concurrency limit allows
+	var running, peak, next atomic.Int32
+	service.concurrentLimit = 2
+	resolve := func(context.Context, string) (int, *titleMatch, error) { return int(next.Add(1)), nil, nil }
+	service.processBrokenSymlinks(context.Background(), make([]string, 6), nil, resolve, func(context.Context, brokenSymlinkGroup, []models.RootFolder) (models.CleanupStats, error) {
+		now := running.Add(1)
+		for {
+			old := peak.Load()
+			if now <= old || peak.CompareAndSwap(old, now) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		running.Add(-1)
+		return models.CleanupStats{}, nil
+	})
+	if peak.Load() != 2 {
+		t.Errorf("Expected 2 symlinks to be handled at once, got %d", peak.Load())
+	}
+}
+
+// handleMovieSymlinks processes broken movie symlinks like a cleanup run does
+func handleMovieSymlinks(s *CleanupServiceImpl, rootFolders []models.RootFolder, paths ...string) models.CleanupStats {
+	return s.processBrokenSymlinks(context.Background(), paths, rootFolders, s.movieTMDBIDFromPath, s.handleBrokenSymlink)
+}
+
+// handleSeriesSymlinks processes broken series symlinks like a cleanup run does
+func handleSeriesSymlinks(s *CleanupServiceImpl, rootFolders []models.RootFolder, paths ...string) models.CleanupStats {
+	return s.processBrokenSymlinks(context.Background(), paths, rootFolders, s.seriesTVDBIDFromPath, s.handleBrokenSymlinkForSeries)
+}
+
+// newTargetingClient returns a Sonarr mock with two series whose files are all missing
+func newTargetingClient() *mockClient {
+	return &mockClient{
+		name: "sonarr",
+		allSeries: []models.Series{
+			{MediaItem: models.MediaItem{ID: 1, Title: "Show One", Path: "/tv/Show One"}},
+			{MediaItem: models.MediaItem{ID: 2, Title: "Show Two", Path: "/tv/Show Two"}},
+		},
+		episodes: map[int][]models.Episode{
+			1: {
+				{ID: 11, SeriesID: 1, SeasonNumber: 1, EpisodeNumber: 1, HasFile: true, EpisodeFileID: intPtr(100)},
+				{ID: 12, SeriesID: 1, SeasonNumber: 1, EpisodeNumber: 2, HasFile: true, EpisodeFileID: intPtr(101)},
+				{ID: 13, SeriesID: 1, SeasonNumber: 1, EpisodeNumber: 3, HasFile: true, EpisodeFileID: intPtr(101)},
+			},
+			2: {
+				{ID: 21, SeriesID: 2, SeasonNumber: 1, EpisodeNumber: 1, HasFile: true, EpisodeFileID: intPtr(200)},
+			},
+		},
+		episodeFiles: map[int]*models.EpisodeFile{
+			100: {ID: 100, Path: "/tv/Show One/Season 1/S01E01.mkv"},
+			101: {ID: 101, Path: "/tv/Show One/Season 1/S01E02-E03.mkv"},
+			200: {ID: 200, Path: "/tv/Show Two/Season 1/S01E01.mkv"},
+		},
+	}
+}
+
+func TestCleanupService_SkipsSeriesWithoutFiles(t *testing.T) {
+	// Series 2's statistics say it has no files, so its episodes aren't fetched even though
+	// its (stale) episode records point at a missing file
+	client := newTargetingClient()
+	client.allSeries[1].EpisodeFileCount = intPtr(0)
+	progress := &mockProgressReporter{}
+	service := NewCleanupServiceWithOptions(client, &mockFileChecker{}, &mockLogger{}, progress, CleanupOptions{ConcurrentLimit: 1})
+
+	if _, err := service.CleanupMissingFiles(context.Background()); err != nil {
+		t.Fatalf("CleanupMissingFiles() failed: %v", err)
+	}
+	if len(progress.seriesStarted) != 1 {
+		t.Errorf("Expected only 1 series to be processed, got %v", progress.seriesStarted)
+	}
+	for _, id := range client.deletedFileIDs {
+		if id == 200 {
+			t.Error("Expected the series without files to be skipped")
+		}
+	}
+}
+
+func TestCleanupService_EpisodeTargeting(t *testing.T) {
+	client := newTargetingClient()
+	service := NewCleanupServiceWithOptions(client, &mockFileChecker{}, &mockLogger{}, &mockProgressReporter{}, CleanupOptions{ConcurrentLimit: 1})
+
+	result, err := service.CleanupMissingFilesForEpisodes(context.Background(), []int{11, 21})
+	if err != nil {
+		t.Fatalf("CleanupMissingFilesForEpisodes() failed: %v", err)
+	}
+
+	if result.Stats.TotalItemsChecked != 2 {
+		t.Errorf("Expected 2 items checked, got %d", result.Stats.TotalItemsChecked)
+	}
+	deleted := make(map[int]bool)
+	for _, id := range client.deletedFileIDs {
+		deleted[id] = true
+	}
+	if len(deleted) != 2 || !deleted[100] || !deleted[200] {
+		t.Errorf("Expected file records 100 and 200 to be deleted, got %v", client.deletedFileIDs)
+	}
+
+	if _, err := NewCleanupServiceWithOptions(client, &mockFileChecker{}, &mockLogger{}, &mockProgressReporter{}, CleanupOptions{ConcurrentLimit: 1}).
+		CleanupMissingFilesForEpisodes(context.Background(), []int{99}); err == nil {
+		t.Error("Expected error for unknown episode ID")
+	}
+}
+
+func TestCleanupService_LogsItemFields(t *testing.T) {
+	var buf bytes.Buffer
+	client := newTargetingClient()
+	service := NewCleanupServiceWithOptions(client, &mockFileChecker{}, NewJSONLogger("INFO", &buf), &mockProgressReporter{}, CleanupOptions{ConcurrentLimit: 1})
+
+	if _, err := service.CleanupMissingFilesForEpisodes(context.Background(), []int{11}); err != nil {
+		t.Fatalf("CleanupMissingFilesForEpisodes() failed: %v", err)
+	}
+
+	// The deletion line carries the episode's IDs and path as fields
+	var found bool
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Log line is not JSON: %q", line)
+		}
+		if !strings.Contains(entry["msg"].(string), "Deleting episode file record 100") {
+			continue
+		}
+		found = true
+		if entry["service"] != "sonarr" || entry["seriesId"] != float64(1) || entry["episodeId"] != float64(11) ||
+			entry["fileId"] != float64(100) || entry["path"] != "/tv/Show One/Season 1/S01E01.mkv" {
+			t.Errorf("Expected the episode's fields on the line, got %v", entry)
+		}
+	}
+	if !found {
+		t.Errorf("Expected a deletion log line, got %s", buf.String())
+	}
+}
+
+func TestCleanupService_EmptyPathPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		deleteEmpty bool
+		wantDeleted bool
+		wantWarning int
+	}{
+		{name: "skip", deleteEmpty: false, wantDeleted: false, wantWarning: 1},
+		{name: "delete", deleteEmpty: true, wantDeleted: true, wantWarning: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTargetingClient()
+			client.episodeFiles[100].Path = ""
+			service := NewCleanupServiceWithOptions(client, &mockFileChecker{}, &mockLogger{}, &mockProgressReporter{}, CleanupOptions{ConcurrentLimit: 1, DeleteEmptyPaths: tt.deleteEmpty})
+
+			result, err := service.CleanupMissingFilesForEpisodes(context.Background(), []int{11})
+			if err != nil {
+				t.Fatalf("CleanupMissingFilesForEpisodes() failed: %v", err)
+			}
+			if result.Stats.EmptyPaths != 1 || result.Stats.MissingFiles != 0 || result.Stats.Warnings != tt.wantWarning {
+				t.Errorf("Expected 1 empty path, no missing files and %d warning(s), got %+v", tt.wantWarning, result.Stats)
+			}
+			deleted := len(client.deletedFileIDs) == 1 && client.deletedFileIDs[0] == 100
+			if deleted != tt.wantDeleted {
+				t.Errorf("Expected deleted = %v, got deleted file IDs %v", tt.wantDeleted, client.deletedFileIDs)
+			}
+			if tt.wantDeleted && (len(result.Report.MissingFiles) != 1 || result.Report.MissingFiles[0].Reason != models.ReasonEmptyPath) {
+				t.Errorf("Expected an empty-path report entry, got %+v", result.Report.MissingFiles)
+			}
+		})
+	}
+}
+
+func TestCleanupService_OutsideRootPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      string
+		dryRun      bool
+		wantDeleted bool
+		wantOutside int // Records found outside: once the series moved, its other files are inside
+		wantOutcome string
+		wantPath    string // Series path after the run
+	}{
+		{name: "skip", policy: config.OutsideRootSkip, wantOutside: 2, wantOutcome: models.OutsideReported, wantPath: "/mnt/old/tv/Show"},
+		{name: "delete", policy: config.OutsideRootDelete, wantDeleted: true, wantOutside: 2, wantOutcome: models.OutsideDeleted, wantPath: "/mnt/old/tv/Show"},
+		{name: "rewrite", policy: config.OutsideRootRewrite, wantOutside: 1, wantOutcome: models.OutsideRewritten, wantPath: "/data/tv/Show"},
+		{name: "rewrite dry run", policy: config.OutsideRootRewrite, dryRun: true, wantOutside: 2, wantOutcome: models.OutsidePlanned, wantPath: "/mnt/old/tv/Show"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewSimulatedClient("sonarr", SimulationFixture{
+				Series: []models.Series{{MediaItem: models.MediaItem{ID: 1, Title: "Show", Path: "/mnt/old/tv/Show"}}},
+				Episodes: []models.Episode{
+					{ID: 11, SeriesID: 1, SeasonNumber: 1, EpisodeNumber: 1, HasFile: true, EpisodeFileID: intPtr(101)},
+					{ID: 12, SeriesID: 1, SeasonNumber: 1, EpisodeNumber: 2, HasFile: true, EpisodeFileID: intPtr(102)},
+				},
+				EpisodeFiles: []models.EpisodeFile{
+					{ID: 101, Path: "/mnt/old/tv/Show/S01E01.mkv"},
+					{ID: 102, Path: "/mnt/old/tv/Show/S01E02.mkv"},
+				},
+				RootFolders: []models.RootFolder{{ID: 1, Path: "/data/tv"}},
+			}, 0, &mockLogger{})
+			fileChecker := &mockFileChecker{fileExists: map[string]bool{
+				"/data/tv/Show/S01E01.mkv": true,
+				"/data/tv/Show/S01E02.mkv": true,
+			}}
+			service := NewCleanupServiceWithOptions(client, fileChecker, &mockLogger{}, &mockProgressReporter{}, CleanupOptions{
+				ConcurrentLimit:   1,
+				DryRun:            tt.dryRun,
+				OutsideRootPolicy: tt.policy,
+				PathMappings:      []models.PathMapping{{From: "/mnt/old/tv", To: "/data/tv"}},
+			})
+
+			result, err := service.CleanupMissingFilesForSeries(context.Background(), []int{1})
+			if err != nil {
+				t.Fatalf("CleanupMissingFilesForSeries() failed: %v", err)
+			}
+			if result.Stats.OutsideRoot != tt.wantOutside || result.Stats.MissingFiles != 0 || result.Stats.Errors != 0 {
+				t.Errorf("Expected %d record(s) outside the root folders and no missing files, got %+v", tt.wantOutside, result.Stats)
+			}
+			if deleted := result.Stats.DeletedRecords == 2; deleted != tt.wantDeleted {
+				t.Errorf("Expected deleted = %v, got %+v", tt.wantDeleted, result.Stats)
+			}
+
+			entries := result.Report.OutsideRootFolders
+			if len(entries) != tt.wantOutside || !strings.HasPrefix(entries[0].FilePath, "/mnt/old/tv/Show/") || entries[0].Policy != tt.policy {
+				t.Fatalf("Expected %d record(s) in the report section, got %+v", tt.wantOutside, entries)
+			}
+			for _, entry := range entries {
+				if entry.Outcome != tt.wantOutcome {
+					t.Errorf("Expected outcome %s, got %+v", tt.wantOutcome, entry)
+				}
+			}
+			if tt.wantDeleted && result.Report.MissingFiles[0].Reason != models.ReasonOutsideRoot {
+				t.Errorf("Expected deleted records to have reason %s, got %+v", models.ReasonOutsideRoot, result.Report.MissingFiles[0])
+			}
+
+			path, _ := client.MediaPath(context.Background(), 1)
+			if path != tt.wantPath {
+				t.Errorf("Expected series path %s, got %s", tt.wantPath, path)
+			}
+			if tt.policy == config.OutsideRootRewrite {
+				// The series is moved once, however many of its files were outside
+				if tt.dryRun && (len(result.Actions) != 1 || result.Actions[0].Action != models.ActionUpdatePath || result.Actions[0].Path != "/data/tv/Show") {
+					t.Errorf("Expected one planned path update, got %+v", result.Actions)
+				}
+				if file, _ := client.GetEpisodeFile(context.Background(), 101); !tt.dryRun && file.Path != "/data/tv/Show/S01E01.mkv" {
+					t.Errorf("Expected the file record to follow the series, got %s", file.Path)
+				}
+			}
+		})
+	}
+}
+
+func TestCleanupService_PathTargeting(t *testing.T) {
+	client := newTargetingClient()
+	service := NewCleanupServiceWithOptions(client, &mockFileChecker{}, &mockLogger{}, &mockProgressReporter{}, CleanupOptions{ConcurrentLimit: 1})
+
+	// A multi-episode file resolves to every episode that shares it
+	result, err := service.CleanupMissingFileAtPath(context.Background(), "/tv/Show One/Season 1/../Season 1/S01E02-E03.mkv")
+	if err != nil {
+		t.Fatalf("CleanupMissingFileAtPath() failed: %v", err)
+	}
+	if result.Stats.TotalItemsChecked != 2 {
+		t.Errorf("Expected 2 items checked, got %d", result.Stats.TotalItemsChecked)
+	}
+	for _, id := range client.deletedFileIDs {
+		if id != 101 {
+			t.Errorf("Expected only file record 101 to be deleted, got %v", client.deletedFileIDs)
+			break
+		}
+	}
+
+	service = NewCleanupServiceWithOptions(client, &mockFileChecker{}, &mockLogger{}, &mockProgressReporter{}, CleanupOptions{ConcurrentLimit: 1})
+	_, err = service.CleanupMissingFileAtPath(context.Background(), "/tv/Show One/Season 1/unknown.mkv")
+	if !errors.Is(err, ErrNoOwningRecord) {
+		t.Errorf("Expected ErrNoOwningRecord, got %v", err)
+	}
+}
+
 func TestCleanupService_CleanupMissingFiles_DryRun(t *testing.T) {
 	// Setup mocks
 	client := &mockClient{
@@ -441,6 +1315,20 @@ func TestCleanupService_CleanupMissingFiles_DryRun(t *testing.T) {
 	if len(client.deletedFileIDs) != 0 {
 		t.Errorf("Expected no files to be deleted in dry run, got %v", client.deletedFileIDs)
 	}
+	// The skipped deletion should be recorded as a planned action
+	if len(result.Actions) != 1 {
+		t.Fatalf("Expected 1 planned action, got %d", len(result.Actions))
+	}
+	action := result.Actions[0]
+	if action.Action != models.ActionDeleteEpisodeFile {
+		t.Errorf("Expected action %q, got %q", models.ActionDeleteEpisodeFile, action.Action)
+	}
+	if action.FileID != 100 || action.EpisodeID != 1 || action.SeriesID != 1 {
+		t.Errorf("Unexpected action IDs: %+v", action)
+	}
+	if action.Path != "/path/to/missing/episode.mkv" {
+		t.Errorf("Expected action path '/path/to/missing/episode.mkv', got '%s'", action.Path)
+	}
 }
 
 func TestCleanupService_ConnectionError(t *testing.T) {
@@ -463,6 +1351,9 @@ func TestCleanupService_ConnectionError(t *testing.T) {
 	if err == nil {
 		t.Error("Expected error due to connection failure")
 	}
+	if !errors.Is(err, ErrConnectionFailed) {
+		t.Errorf("Expected ErrConnectionFailed, got %v", err)
+	}
 	if result != nil {
 		t.Error("Expected nil result on connection failure")
 	}
@@ -521,6 +1412,56 @@ func TestCleanupService_APIError(t *testing.T) {
 	}
 }
 
+func TestCleanupService_Severity(t *testing.T) {
+	defer func(delay time.Duration) { retryDelay = delay }(retryDelay)
+	retryDelay = time.Millisecond
+
+	tests := []struct {
+		name             string
+		episodeFileError error
+		expectedWarnings int
+		expectedErrors   int
+		expectedSeverity models.Severity
+	}{
+		{"clean run", nil, 0, 0, models.SeverityOK},
+		{"transient error", &APIError{StatusCode: http.StatusServiceUnavailable}, 1, 0, models.SeverityWarning},
+		{"hard failure", &APIError{StatusCode: http.StatusBadRequest}, 0, 1, models.SeverityError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &mockClient{
+				name: "sonarr",
+				episodes: map[int][]models.Episode{
+					1: {{ID: 1, SeriesID: 1, SeasonNumber: 1, EpisodeNumber: 1, HasFile: true, EpisodeFileID: intPtr(100)}},
+				},
+				episodeFiles: map[int]*models.EpisodeFile{
+					100: {ID: 100, Path: "/tv/show/s01e01.mkv"},
+				},
+				episodeFileError: tt.episodeFileError,
+			}
+			fileChecker := &mockFileChecker{fileExists: map[string]bool{"/tv/show/s01e01.mkv": true}}
+
+			service := NewCleanupService(client, fileChecker, &mockLogger{}, &mockProgressReporter{}, 0, false)
+			result, err := service.CleanupMissingFilesForEpisodes(context.Background(), []int{1})
+			if err != nil {
+				t.Fatalf("CleanupMissingFilesForEpisodes() failed: %v", err)
+			}
+
+			if result.Stats.Warnings != tt.expectedWarnings || result.Stats.Errors != tt.expectedErrors {
+				t.Errorf("Expected %d warnings and %d errors, got %d and %d",
+					tt.expectedWarnings, tt.expectedErrors, result.Stats.Warnings, result.Stats.Errors)
+			}
+			if got := result.Severity(); got != tt.expectedSeverity {
+				t.Errorf("Expected severity %s, got %s", tt.expectedSeverity, got)
+			}
+			if result.Success != (tt.expectedErrors == 0) {
+				t.Errorf("Expected Success to be %v with %d errors", tt.expectedErrors == 0, tt.expectedErrors)
+			}
+		})
+	}
+}
+
 func TestCleanupService_CancelledContext(t *testing.T) {
 	// Setup mocks
 	client := &mockClient{
@@ -558,3 +1499,342 @@ func TestCleanupService_CancelledContext(t *testing.T) {
 func intPtr(i int) *int {
 	return &i
 }
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func TestCleanupService_RecoversWorkerPanic(t *testing.T) {
+	// A nil file, as from a malformed API response, panics in series 2's episode worker
+	client := newTargetingClient()
+	client.episodeFiles[200] = nil
+	service := NewCleanupServiceWithOptions(client, &mockFileChecker{}, &mockLogger{}, &mockProgressReporter{}, CleanupOptions{ConcurrentLimit: 1})
+
+	result, err := service.CleanupMissingFilesForSeries(context.Background(), []int{1, 2})
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("Expected a *PanicError, got %v", err)
+	}
+	if !strings.Contains(panicErr.Stack, "cleanupSeries") {
+		t.Errorf("Expected the worker's stack, got %s", panicErr.Stack)
+	}
+	if result == nil || result.Success {
+		t.Errorf("Expected a failed result with the stats so far, got %+v", result)
+	}
+}
+
+func TestForEachConcurrently_RaisesPanicInCaller(t *testing.T) {
+	service := NewCleanupServiceWithOptions(&mockClient{name: "sonarr"}, &mockFileChecker{}, &mockLogger{}, &mockProgressReporter{}, CleanupOptions{ConcurrentLimit: 2}).(*CleanupServiceImpl)
+
+	defer func() {
+		panicErr, ok := recover().(*PanicError)
+		if !ok || panicErr.Value != "boom" {
+			t.Errorf("Expected the worker's panic as a *PanicError, got %v", panicErr)
+		}
+	}()
+	service.forEachConcurrently(context.Background(), 3, func(i int) {
+		if i == 1 {
+			panic("boom")
+		}
+	})
+	t.Error("Expected forEachConcurrently to panic")
+}
+
+func TestCleanupService_Concurrency(t *testing.T) {
+	tests := []struct {
+		name                     string
+		opts                     CleanupOptions
+		wantEpisodes, wantMovies int
+	}{
+		{"defaults", CleanupOptions{ConcurrentLimit: 5}, 3, 5},
+		{"low limit", CleanupOptions{ConcurrentLimit: 2}, 2, 2},
+		{"configured", CleanupOptions{ConcurrentLimit: 5, EpisodeConcurrency: 12, MovieConcurrency: 1}, 12, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewCleanupServiceWithOptions(&mockClient{}, &mockFileChecker{}, &mockLogger{}, &mockProgressReporter{}, tt.opts).(*CleanupServiceImpl)
+			if got := service.episodeConcurrency(); got != tt.wantEpisodes {
+				t.Errorf("episodeConcurrency() = %d, want %d", got, tt.wantEpisodes)
+			}
+			if got := service.movieConcurrency(); got != tt.wantMovies {
+				t.Errorf("movieConcurrency() = %d, want %d", got, tt.wantMovies)
+			}
+		})
+	}
+}
+
+func TestCleanupService_MaintenanceWindow(t *testing.T) {
+	windows, err := models.ParseMaintenanceWindows("sat 02:00-06:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	monday := time.Date(2026, 10, 12, 12, 0, 0, 0, time.UTC)
+	saturday := time.Date(2026, 10, 17, 3, 0, 0, 0, time.UTC)
+
+	for _, tt := range []struct {
+		name     string
+		now      time.Time
+		deferred bool
+	}{
+		{"outside the window", monday, true},
+		{"inside the window", saturday, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &mockClient{
+				name: "sonarr",
+				episodes: map[int][]models.Episode{
+					1: {
+						{ID: 1, SeriesID: 1, SeasonNumber: 1, EpisodeNumber: 1, HasFile: true, EpisodeFileID: intPtr(100)},
+						{ID: 2, SeriesID: 1, SeasonNumber: 1, EpisodeNumber: 2, HasFile: true, EpisodeFileID: intPtr(200)},
+					},
+				},
+				episodeFiles: map[int]*models.EpisodeFile{
+					100: {ID: 100, Path: "/tv/show/s01e01.mkv"},
+					200: {ID: 200, Path: "/tv/show/s01e02.mkv"},
+				},
+			}
+			service := NewCleanupServiceWithOptions(client, &mockFileChecker{fileExists: map[string]bool{}}, &mockLogger{}, &mockProgressReporter{}, CleanupOptions{
+				ConcurrentLimit:    1,
+				MaintenanceWindows: windows,
+			})
+			service.(*CleanupServiceImpl).now = func() time.Time { return tt.now }
+
+			result, err := service.CleanupMissingFilesForSeries(context.Background(), []int{1})
+			if err != nil {
+				t.Fatalf("CleanupMissingFilesForSeries() failed: %v", err)
+			}
+
+			if tt.deferred {
+				if len(client.deletedFileIDs) != 0 || len(result.Actions) != 2 {
+					t.Errorf("Expected both deletions deferred as planned actions, got deleted %v, actions %v", client.deletedFileIDs, result.Actions)
+				}
+				if !result.Report.OutsideWindow || result.Report.NextWindow != saturday.Add(-time.Hour).Format(time.RFC3339) {
+					t.Errorf("Expected the report to note the deferral and the next window, got %+v", result.Report)
+				}
+				return
+			}
+			if len(client.deletedFileIDs) != 2 || len(result.Actions) != 0 || result.Report.OutsideWindow {
+				t.Errorf("Expected both records deleted inside the window, got deleted %v, actions %v", client.deletedFileIDs, result.Actions)
+			}
+			if client.refreshTriggered != 1 {
+				t.Errorf("Expected the missing search inside the window, got %d", client.refreshTriggered)
+			}
+		})
+	}
+}
+
+func TestCleanupService_MissingFileDetails(t *testing.T) {
+	client := NewSimulatedClient("sonarr", SimulationFixture{
+		Series:   []models.Series{{MediaItem: models.MediaItem{ID: 1, Title: "Show", Path: "/tv/Show"}}},
+		Episodes: []models.Episode{{ID: 11, SeriesID: 1, SeasonNumber: 1, EpisodeNumber: 1, HasFile: true, EpisodeFileID: intPtr(101)}},
+		EpisodeFiles: []models.EpisodeFile{{
+			ID:            101,
+			Path:          "/tv/Show/S01E01.mkv",
+			Size:          1500,
+			Quality:       &models.FileQuality{Quality: models.Quality{ID: 7, Name: "Bluray-1080p"}},
+			ReleaseGroup:  "NTb",
+			CustomFormats: []models.CustomFormat{{ID: 1, Name: "x265"}, {ID: 2, Name: "HDR"}},
+		}},
+	}, 0, &mockLogger{})
+	service := NewCleanupServiceWithOptions(client, &mockFileChecker{}, &mockLogger{}, &mockProgressReporter{}, CleanupOptions{ConcurrentLimit: 1, DryRun: true})
+
+	result, err := service.CleanupMissingFilesForSeries(context.Background(), []int{1})
+	if err != nil {
+		t.Fatalf("CleanupMissingFilesForSeries() failed: %v", err)
+	}
+	if len(result.Report.MissingFiles) != 1 {
+		t.Fatalf("Expected 1 missing file, got %+v", result.Report.MissingFiles)
+	}
+	entry := result.Report.MissingFiles[0]
+	if entry.Quality != "Bluray-1080p" || entry.ReleaseGroup != "NTb" || entry.Size != 1500 || strings.Join(entry.CustomFormats, ",") != "x265,HDR" {
+		t.Errorf("Expected the file's quality, release group, custom formats and size, got %+v", entry)
+	}
+}
+
+func TestCleanupService_TagsAffectedItems(t *testing.T) {
+	fixture := func() SimulationFixture {
+		return SimulationFixture{
+			Movies: []models.Movie{
+				{MediaItem: models.MediaItem{ID: 1, Title: "Gone", Tags: []int{1}}, HasFile: true, MovieFileID: intPtr(10)},
+				{MediaItem: models.MediaItem{ID: 2, Title: "Not Downloaded"}},
+			},
+			MovieFiles: []models.MovieFile{{ID: 10, MovieID: 1, Path: "/movies/Gone/Gone.mkv"}},
+			Tags:       []models.Tag{{ID: 1, Label: "4k"}},
+		}
+	}
+
+	// Dry runs only log the tagging
+	client := NewSimulatedClient("radarr", fixture(), 0, &mockLogger{})
+	service := NewCleanupServiceWithOptions(client, &mockFileChecker{}, &mockLogger{}, &mockProgressReporter{}, CleanupOptions{ConcurrentLimit: 1, DryRun: true, Tag: "refresharr-cleaned"})
+	if _, err := service.CleanupMissingFilesForMovies(context.Background(), []int{1, 2}); err != nil {
+		t.Fatalf("CleanupMissingFilesForMovies() failed: %v", err)
+	}
+	if len(client.fixture.Tags) != 1 {
+		t.Errorf("Expected no tag to be created in a dry run, got %+v", client.fixture.Tags)
+	}
+
+	client = NewSimulatedClient("radarr", fixture(), 0, &mockLogger{})
+	service = NewCleanupServiceWithOptions(client, &mockFileChecker{}, &mockLogger{}, &mockProgressReporter{}, CleanupOptions{ConcurrentLimit: 1, Tag: "refresharr-cleaned"})
+	result, err := service.CleanupMissingFilesForMovies(context.Background(), []int{1, 2})
+	if err != nil {
+		t.Fatalf("CleanupMissingFilesForMovies() failed: %v", err)
+	}
+	if result.Stats.DeletedRecords != 1 {
+		t.Fatalf("Expected 1 deleted record, got %d", result.Stats.DeletedRecords)
+	}
+
+	tagID, ok := findTag(client.fixture.Tags, "refresharr-cleaned")
+	if !ok {
+		t.Fatalf("Expected the tag to be created, got %+v", client.fixture.Tags)
+	}
+	movies, _ := client.GetAllMovies(context.Background())
+	if !slices.Equal(movies[0].Tags, []int{1, tagID}) {
+		t.Errorf("Expected the movie with a deleted file to keep its tag and get the new one, got %v", movies[0].Tags)
+	}
+	if len(movies[1].Tags) != 0 {
+		t.Errorf("Expected the movie without a file to stay untagged, got %v", movies[1].Tags)
+	}
+}
+
+// deleteCountingClient is a SimulatedClient that counts each movie file deletion as an API call
+type deleteCountingClient struct {
+	*SimulatedClient
+	deletes int64
+}
+
+func (c *deleteCountingClient) DeleteMovieFile(ctx context.Context, fileID int) error {
+	c.deletes++
+	return c.SimulatedClient.DeleteMovieFile(ctx, fileID)
+}
+
+func (c *deleteCountingClient) APICalls() int64 {
+	return c.deletes
+}
+
+func TestCleanupService_TagsDeletionsOverBudget(t *testing.T) {
+	client := &deleteCountingClient{SimulatedClient: NewSimulatedClient("radarr", SimulationFixture{
+		Movies: []models.Movie{
+			{MediaItem: models.MediaItem{ID: 1, Title: "Deleted"}, HasFile: true, MovieFileID: intPtr(10)},
+			{MediaItem: models.MediaItem{ID: 2, Title: "Reported"}, HasFile: true, MovieFileID: intPtr(20)},
+		},
+		MovieFiles: []models.MovieFile{
+			{ID: 10, MovieID: 1, Path: "/movies/Deleted/Deleted.mkv"},
+			{ID: 20, MovieID: 2, Path: "/movies/Reported/Reported.mkv"},
+		},
+	}, 0, &mockLogger{})}
+	// The budget runs out with the first deletion, so the second is only reported
+	service := NewCleanupServiceWithOptions(client, &mockFileChecker{}, &mockLogger{}, &mockProgressReporter{}, CleanupOptions{
+		ConcurrentLimit: 1,
+		APIBudget:       1,
+		Tag:             "refresharr-cleaned",
+	})
+
+	result, err := service.CleanupMissingFilesForMovies(context.Background(), []int{1, 2})
+	if err != nil {
+		t.Fatalf("CleanupMissingFilesForMovies() failed: %v", err)
+	}
+	if result.Stats.DeletedRecords != 1 || len(result.Actions) != 1 || !result.Report.OverBudget {
+		t.Fatalf("Expected 1 deletion and 1 planned action over budget, got %d and %v", result.Stats.DeletedRecords, result.Actions)
+	}
+
+	// The deletion made before the budget ran out is tagged; the reported one isn't
+	tagID, ok := findTag(client.fixture.Tags, "refresharr-cleaned")
+	if !ok {
+		t.Fatalf("Expected the tag to be created, got %+v", client.fixture.Tags)
+	}
+	ids, err := client.TaggedMedia(context.Background(), tagID)
+	if err != nil {
+		t.Fatalf("TaggedMedia() failed: %v", err)
+	}
+	if !slices.Equal(ids, []int{1}) {
+		t.Errorf("Expected only the movie whose record was deleted to be tagged, got %v", ids)
+	}
+}
+
+func TestCleanupService_UntagsRecoveredItems(t *testing.T) {
+	episode := func(id, seriesID, number int, fileID *int) models.Episode {
+		return models.Episode{ID: id, SeriesID: seriesID, SeasonNumber: 1, EpisodeNumber: number, HasFile: fileID != nil, EpisodeFileID: fileID}
+	}
+	fixture := func() SimulationFixture {
+		return SimulationFixture{
+			Series: []models.Series{
+				{MediaItem: models.MediaItem{ID: 1, Title: "Still Missing", Tags: []int{5}}},
+				{MediaItem: models.MediaItem{ID: 2, Title: "Complete", Tags: []int{5}}},
+				{MediaItem: models.MediaItem{ID: 3, Title: "Recovered", Tags: []int{7, 5}}},
+				{MediaItem: models.MediaItem{ID: 4, Title: "Broken", Tags: []int{5}}},
+			},
+			Episodes: []models.Episode{
+				episode(11, 1, 1, intPtr(101)),
+				episode(12, 1, 2, nil), // Its record was deleted by an earlier run
+				episode(21, 2, 1, intPtr(201)),
+				episode(31, 3, 1, intPtr(301)),
+				episode(41, 4, 1, intPtr(401)),
+			},
+			EpisodeFiles: []models.EpisodeFile{
+				{ID: 101, Path: "/tv/Still Missing/S01E01.mkv"},
+				{ID: 201, Path: "/tv/Complete/S01E01.mkv"},
+				{ID: 301, Path: "/tv/Recovered/S01E01.mkv"},
+				{ID: 401, Path: "/tv/Broken/S01E01.mkv"},
+			},
+			Tags: []models.Tag{{ID: 5, Label: "refresharr-cleaned"}, {ID: 7, Label: "anime"}},
+		}
+	}
+	fileChecker := &mockFileChecker{fileExists: map[string]bool{
+		"/tv/Still Missing/S01E01.mkv": true,
+		"/tv/Complete/S01E01.mkv":      true,
+		"/tv/Recovered/S01E01.mkv":     true,
+	}}
+	season, first, second := 1, 1, 2
+	opts := CleanupOptions{
+		ConcurrentLimit: 1,
+		Tag:             "refresharr-cleaned",
+		PreviouslyMissing: []models.MissingFileEntry{
+			{MediaType: "series", MediaName: "Still Missing", Season: &season, Episode: &second},
+			{MediaType: "series", MediaName: "Recovered", Season: &season, Episode: &first},
+		},
+	}
+	tagged := func(client *SimulatedClient) []int {
+		ids, err := client.TaggedMedia(context.Background(), 5)
+		if err != nil {
+			t.Fatalf("TaggedMedia() failed: %v", err)
+		}
+		return ids
+	}
+
+	// Dry runs leave the tags alone
+	client := NewSimulatedClient("sonarr", fixture(), 0, &mockLogger{})
+	dryRun := opts
+	dryRun.DryRun = true
+	service := NewCleanupServiceWithOptions(client, fileChecker, &mockLogger{}, &mockProgressReporter{}, dryRun)
+	if _, err := service.CleanupMissingFiles(context.Background()); err != nil {
+		t.Fatalf("CleanupMissingFiles() failed: %v", err)
+	}
+	if ids := tagged(client); !slices.Equal(ids, []int{1, 2, 3, 4}) {
+		t.Errorf("Expected a dry run to keep every tag, got %v", ids)
+	}
+
+	client = NewSimulatedClient("sonarr", fixture(), 0, &mockLogger{})
+	service = NewCleanupServiceWithOptions(client, fileChecker, &mockLogger{}, &mockProgressReporter{}, opts)
+	if _, err := service.CleanupMissingFiles(context.Background()); err != nil {
+		t.Fatalf("CleanupMissingFiles() failed: %v", err)
+	}
+	// Series 1 still misses the episode an earlier run found missing, and series 4 just lost a file
+	if ids := tagged(client); !slices.Equal(ids, []int{1, 4}) {
+		t.Errorf("Expected only the series with files still missing to keep the tag, got %v", ids)
+	}
+	if tags := client.fixture.Series[2].Tags; !slices.Equal(tags, []int{7}) {
+		t.Errorf("Expected the recovered series to keep its other tags, got %v", tags)
+	}
+
+	// A history that left out missing files can't show any series is complete again
+	client = NewSimulatedClient("sonarr", fixture(), 0, &mockLogger{})
+	truncated := opts
+	truncated.PreviouslyTruncated = true
+	service = NewCleanupServiceWithOptions(client, fileChecker, &mockLogger{}, &mockProgressReporter{}, truncated)
+	if _, err := service.CleanupMissingFiles(context.Background()); err != nil {
+		t.Fatalf("CleanupMissingFiles() failed: %v", err)
+	}
+	if ids := tagged(client); !slices.Equal(ids, []int{1, 2, 3, 4}) {
+		t.Errorf("Expected every tag kept with a truncated history, got %v", ids)
+	}
+}
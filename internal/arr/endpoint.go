@@ -0,0 +1,120 @@
+package arr
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// unixSocketHost is the host of the base URL requests to a Unix socket are built on. It only
+// shows up in the Host header, which socket-activated proxies don't route on.
+const unixSocketHost = "localhost"
+
+// UnixSocketPath returns the socket path of a unix:// endpoint, e.g. /var/run/sonarr.sock for
+// unix:///var/run/sonarr.sock, and false for any other URL
+func UnixSocketPath(rawURL string) (string, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "unix" || u.Host != "" || u.Path == "" {
+		return "", false
+	}
+	return u.Path, true
+}
+
+// srvSchemePrefix marks endpoints whose host is a DNS SRV name, e.g. srv+http://_sonarr._tcp.media.svc
+const srvSchemePrefix = "srv+"
+
+// ResolveEndpoint returns the base URL a client builds its request URLs on, and the transport
+// that reaches the endpoint. http(s) URLs are returned as they are, without trailing slashes,
+// and a nil transport, meaning the default. unix:// URLs are served over the socket, and
+// srv+http(s):// URLs by the targets of the host's SRV records.
+func ResolveEndpoint(rawURL string) (string, http.RoundTripper) {
+	if socketPath, ok := UnixSocketPath(rawURL); ok {
+		return "http://" + unixSocketHost, unixSocketTransport(socketPath)
+	}
+	if rest, ok := strings.CutPrefix(rawURL, srvSchemePrefix); ok {
+		if u, err := url.Parse(rest); err == nil && u.Hostname() != "" {
+			return strings.TrimRight(rest, "/"), srvTransport(u.Hostname())
+		}
+	}
+	return strings.TrimRight(rawURL, "/"), nil
+}
+
+// unixSocketTransport returns a transport that sends every request to the socket at path
+func unixSocketTransport(path string) http.RoundTripper {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	dialer := &net.Dialer{}
+	transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+		return dialer.DialContext(ctx, "unix", path)
+	}
+	return transport
+}
+
+// lookupSRV looks up SRV records, replaced in tests
+var lookupSRV = net.DefaultResolver.LookupSRV
+
+// srvTransport returns a transport that connects to the targets of name's SRV records. The
+// records are looked up again for every new connection, so a long-running serve follows the
+// service as it moves between runs.
+func srvTransport(name string) http.RoundTripper {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	dialer := &net.Dialer{}
+	transport.Proxy = nil
+	transport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return dialSRV(ctx, name, func(target, address string) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, address)
+		})
+	}
+	// Certificates are issued for the targets, not for the SRV name in the URL, so each
+	// connection is verified against the target it reached
+	transport.DialTLSContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return dialSRV(ctx, name, func(target, address string) (net.Conn, error) {
+			conn, err := dialer.DialContext(ctx, network, address)
+			if err != nil {
+				return nil, err
+			}
+			tlsConfig := &tls.Config{}
+			if transport.TLSClientConfig != nil {
+				tlsConfig = transport.TLSClientConfig.Clone()
+			}
+			tlsConfig.ServerName = target
+			tlsConn := tls.Client(conn, tlsConfig)
+			if err := tlsConn.HandshakeContext(ctx); err != nil {
+				conn.Close()
+				return nil, err
+			}
+			return tlsConn, nil
+		})
+	}
+	return transport
+}
+
+// dialSRV looks up name's SRV records and connects to their targets with dial, which gets the
+// target host and its host:port address. Records come ordered by priority and weight, and the
+// next target is tried when one fails.
+func dialSRV(ctx context.Context, name string, dial func(target, address string) (net.Conn, error)) (net.Conn, error) {
+	_, records, err := lookupSRV(ctx, "", "", name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up SRV records for %s: %w", name, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no SRV records for %s", name)
+	}
+
+	var errs []error
+	for _, record := range records {
+		target := strings.TrimSuffix(record.Target, ".")
+		conn, err := dial(target, net.JoinHostPort(target, strconv.Itoa(int(record.Port))))
+		if err == nil {
+			return conn, nil
+		}
+		errs = append(errs, err)
+	}
+	return nil, errors.Join(errs...)
+}
@@ -0,0 +1,136 @@
+package arr
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/hnipps/refresharr/internal/config"
+)
+
+func TestResolveEndpoint(t *testing.T) {
+	baseURL, transport := ResolveEndpoint("http://127.0.0.1:8989/")
+	if baseURL != "http://127.0.0.1:8989" || transport != nil {
+		t.Errorf("ResolveEndpoint(http) = %q, %v; want the URL without trailing slash and the default transport", baseURL, transport)
+	}
+
+	baseURL, transport = ResolveEndpoint("unix:///var/run/sonarr.sock")
+	if baseURL != "http://localhost" || transport == nil {
+		t.Errorf("ResolveEndpoint(unix) = %q, %v; want http://localhost and a socket transport", baseURL, transport)
+	}
+
+	for _, rawURL := range []string{"http://sonarr:8989", "unix://sonarr", "unix://", "/var/run/sonarr.sock"} {
+		if path, ok := UnixSocketPath(rawURL); ok {
+			t.Errorf("UnixSocketPath(%q) = %q, expected no socket path", rawURL, path)
+		}
+	}
+}
+
+func TestClients_UnixSocketEndpoint(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "arr.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Skipf("Unix sockets unavailable: %v", err)
+	}
+
+	var paths []string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Write([]byte(`{"version":"3.0.0"}`))
+	}))
+	server.Listener = listener
+	server.Start()
+	defer server.Close()
+
+	url := "unix://" + socketPath
+	clients := []Client{
+		NewRadarrClient(&config.RadarrConfig{URL: url, APIKey: "key"}, 5*time.Second, &mockLogger{}),
+		NewStarrRadarrClient(&config.RadarrConfig{URL: url, APIKey: "key"}, 5*time.Second, &mockLogger{}),
+		NewSonarrClient(&config.SonarrConfig{URL: url, APIKey: "key"}, 5*time.Second, &mockLogger{}),
+	}
+	for _, client := range clients {
+		if err := client.TestConnection(context.Background()); err != nil {
+			t.Errorf("%s: TestConnection() over a Unix socket error = %v", client.GetName(), err)
+		}
+	}
+
+	if len(paths) != len(clients) {
+		t.Fatalf("Expected %d requests over the socket, got %d", len(clients), len(paths))
+	}
+	for _, path := range paths {
+		if path != "/api/v3/system/status" {
+			t.Errorf("Expected request for /api/v3/system/status, got %s", path)
+		}
+	}
+}
+
+func TestClients_SRVEndpoint(t *testing.T) {
+	var hosts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hosts = append(hosts, r.Host)
+		w.Write([]byte(`{"version":"3.0.0"}`))
+	}))
+	defer server.Close()
+
+	_, portStr, _ := net.SplitHostPort(server.Listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+	lookups := 0
+	original := lookupSRV
+	lookupSRV = func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+		lookups++
+		if name != "_sonarr._tcp.media.test" {
+			return "", nil, fmt.Errorf("unexpected SRV name %s", name)
+		}
+		return "", []*net.SRV{
+			{Target: "unreachable.invalid.", Port: 1, Priority: 1},
+			{Target: "127.0.0.1.", Port: uint16(port), Priority: 2},
+		}, nil
+	}
+	defer func() { lookupSRV = original }()
+
+	client := NewSonarrClient(&config.SonarrConfig{URL: "srv+http://_sonarr._tcp.media.test", APIKey: "key"}, 5*time.Second, &mockLogger{})
+	if err := client.TestConnection(context.Background()); err != nil {
+		t.Fatalf("TestConnection() through SRV records error = %v", err)
+	}
+	if lookups == 0 {
+		t.Error("Expected the SRV records to be looked up")
+	}
+	if len(hosts) != 1 || hosts[0] != "_sonarr._tcp.media.test" {
+		t.Errorf("Expected one request for the SRV name, got %v", hosts)
+	}
+}
+
+func TestSRVTransport_VerifiesTargetCertificate(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"version":"3.0.0"}`))
+	}))
+	defer server.Close()
+
+	_, portStr, _ := net.SplitHostPort(server.Listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+	original := lookupSRV
+	lookupSRV = func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+		return "", []*net.SRV{{Target: "127.0.0.1.", Port: uint16(port)}}, nil
+	}
+	defer func() { lookupSRV = original }()
+
+	// The test certificate is issued for 127.0.0.1, the target, and not for the SRV name
+	transport := srvTransport("_sonarr._tcp.media.test").(*http.Transport)
+	transport.TLSClientConfig = server.Client().Transport.(*http.Transport).TLSClientConfig.Clone()
+	client := &http.Client{Transport: transport, Timeout: 5 * time.Second}
+
+	resp, err := client.Get("https://_sonarr._tcp.media.test/api/v3/system/status")
+	if err != nil {
+		t.Fatalf("Request through SRV records over TLS failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,164 @@
+package arr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"golift.io/starr"
+)
+
+// Kinds of API failure. Errors returned by the clients match these with errors.Is, e.g.
+// errors.Is(err, ErrNotFound) when the requested record doesn't exist.
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrRateLimited  = errors.New("rate limited")
+	ErrServerError  = errors.New("server error")
+)
+
+// APIError is a request the server answered with an error status
+type APIError struct {
+	StatusCode int
+	Body       string // What the response said went wrong, truncated; see describeResponseBody
+	Err        error  // Underlying error, if any
+}
+
+// Error returns the status code and what the response said, or the underlying error's message
+func (e *APIError) Error() string {
+	switch {
+	case e.Body != "":
+		return fmt.Sprintf("status: %d, response: %s", e.StatusCode, e.Body)
+	case e.Err != nil:
+		return e.Err.Error()
+	default:
+		return fmt.Sprintf("status: %d", e.StatusCode)
+	}
+}
+
+// Unwrap returns the underlying error
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the status code is of the given kind of failure
+func (e *APIError) Is(target error) bool {
+	kind := statusKind(e.StatusCode)
+	return kind != nil && target == kind
+}
+
+// statusKind returns the kind of failure a status code signals, or nil
+func statusKind(statusCode int) error {
+	switch {
+	case statusCode == http.StatusNotFound:
+		return ErrNotFound
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		return ErrUnauthorized
+	case statusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case statusCode >= 500:
+		return ErrServerError
+	}
+	return nil
+}
+
+// maxErrorBody is the most of a response body included in an error
+const maxErrorBody = 500
+
+// responseError creates an error for a response with an unexpected status code, including
+// what the response body says went wrong
+func responseError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	return &APIError{StatusCode: resp.StatusCode, Body: describeResponseBody(body)}
+}
+
+// describeResponseBody summarizes an error response. The *arr apps answer invalid requests
+// with an array of validation failures, which is reduced to "property: message" pairs; other
+// bodies are reduced to their message, or included as they are. The result is truncated.
+func describeResponseBody(body []byte) string {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return ""
+	}
+
+	var failures []struct {
+		PropertyName string `json:"propertyName"`
+		ErrorMessage string `json:"errorMessage"`
+	}
+	if json.Unmarshal(body, &failures) == nil {
+		var parts []string
+		for _, failure := range failures {
+			switch {
+			case failure.ErrorMessage == "":
+			case failure.PropertyName == "":
+				parts = append(parts, failure.ErrorMessage)
+			default:
+				parts = append(parts, failure.PropertyName+": "+failure.ErrorMessage)
+			}
+		}
+		if len(parts) > 0 {
+			return truncateErrorBody(strings.Join(parts, "; "))
+		}
+	}
+
+	var message struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &message) == nil && message.Message != "" {
+		return truncateErrorBody(message.Message)
+	}
+
+	return truncateErrorBody(string(body))
+}
+
+// truncateErrorBody shortens s to maxErrorBody bytes
+func truncateErrorBody(s string) string {
+	if len(s) <= maxErrorBody {
+		return s
+	}
+	return strings.ToValidUTF8(s[:maxErrorBody], "") + "..."
+}
+
+// notFoundError creates an error matching ErrNotFound for a record that doesn't exist
+func notFoundError(format string, args ...interface{}) error {
+	return &APIError{StatusCode: http.StatusNotFound, Err: fmt.Errorf(format, args...)}
+}
+
+// apiError converts the errors starr returns for error statuses to an APIError, so the kind of
+// failure can be checked with errors.Is. Other errors are returned unchanged.
+func apiError(err error) error {
+	var reqErr *starr.ReqError
+	if errors.As(err, &reqErr) {
+		return &APIError{StatusCode: reqErr.Code, Body: describeResponseBody(reqErr.Body), Err: err}
+	}
+	return err
+}
+
+// IsRetryable reports whether a request that failed with err may succeed if sent again later
+func IsRetryable(err error) bool {
+	return errors.Is(err, ErrRateLimited) || errors.Is(err, ErrServerError)
+}
+
+// retryDelay is how long to wait before sending a request again after a retryable failure
+var retryDelay = 2 * time.Second
+
+// retryOnce calls fetch and, if it fails with a retryable error, once more after retryDelay
+func retryOnce[T any](ctx context.Context, fetch func() (T, error)) (T, error) {
+	result, err := fetch()
+	if err == nil || !IsRetryable(err) {
+		return result, err
+	}
+
+	select {
+	case <-ctx.Done():
+		return result, err
+	case <-time.After(retryDelay):
+	}
+	return fetch()
+}
@@ -0,0 +1,161 @@
+package arr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hnipps/refresharr/internal/config"
+	"github.com/hnipps/refresharr/pkg/models"
+	"golift.io/starr"
+)
+
+func TestAPIError_Is(t *testing.T) {
+	kinds := []error{ErrNotFound, ErrUnauthorized, ErrRateLimited, ErrServerError}
+	tests := []struct {
+		statusCode int
+		expected   error
+	}{
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusForbidden, ErrUnauthorized},
+		{http.StatusTooManyRequests, ErrRateLimited},
+		{http.StatusInternalServerError, ErrServerError},
+		{http.StatusBadGateway, ErrServerError},
+		{http.StatusBadRequest, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.statusCode), func(t *testing.T) {
+			err := fmt.Errorf("failed to fetch movies, %w", &APIError{StatusCode: tt.statusCode})
+			for _, kind := range kinds {
+				if got := errors.Is(err, kind); got != (kind == tt.expected) {
+					t.Errorf("errors.Is(%v, %v) = %v", err, kind, got)
+				}
+			}
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.statusCode {
+				t.Errorf("Expected an APIError with status %d, got %v", tt.statusCode, err)
+			}
+		})
+	}
+}
+
+func TestAPIErrorConvertsStarrErrors(t *testing.T) {
+	reqErr := &starr.ReqError{Code: http.StatusUnauthorized}
+	err := fmt.Errorf("failed to fetch series: %w", apiError(fmt.Errorf("api.Get(series): %w", reqErr)))
+
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Expected ErrUnauthorized, got %v", err)
+	}
+	if !errors.Is(err, starr.ErrInvalidStatusCode) {
+		t.Error("Expected the starr error to stay in the chain")
+	}
+
+	plain := errors.New("connection refused")
+	if apiError(plain) != plain {
+		t.Error("Expected errors without a status to be returned unchanged")
+	}
+	if apiError(nil) != nil {
+		t.Error("Expected nil to stay nil")
+	}
+}
+
+func TestNotFoundError(t *testing.T) {
+	err := notFoundError("movie file %d not found", 7)
+	if err.Error() != "movie file 7 not found" {
+		t.Errorf("Unexpected message %q", err.Error())
+	}
+	if !errors.Is(err, ErrNotFound) {
+		t.Error("Expected ErrNotFound")
+	}
+}
+
+func TestRetryOnce(t *testing.T) {
+	defer func(delay time.Duration) { retryDelay = delay }(retryDelay)
+	retryDelay = time.Millisecond
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		errs          []error
+		expectedCalls int
+		expectError   bool
+	}{
+		{"success", []error{nil}, 1, false},
+		{"rate limited then success", []error{&APIError{StatusCode: http.StatusTooManyRequests}, nil}, 2, false},
+		{"server error twice", []error{&APIError{StatusCode: http.StatusBadGateway}, &APIError{StatusCode: http.StatusBadGateway}}, 2, true},
+		{"not found is not retried", []error{notFoundError("episode file 1 not found")}, 1, true},
+		{"unauthorized is not retried", []error{&APIError{StatusCode: http.StatusUnauthorized}}, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			_, err := retryOnce(ctx, func() (int, error) {
+				err := tt.errs[calls]
+				calls++
+				return calls, err
+			})
+			if calls != tt.expectedCalls {
+				t.Errorf("Expected %d calls, got %d", tt.expectedCalls, calls)
+			}
+			if (err != nil) != tt.expectError {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestDescribeResponseBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{"empty", "  ", ""},
+		{
+			"validation failures",
+			`[{"propertyName":"Path","errorMessage":"Path is already configured for an existing movie","severity":"error"},{"propertyName":"","errorMessage":"Invalid quality profile"}]`,
+			"Path: Path is already configured for an existing movie; Invalid quality profile",
+		},
+		{"message", `{"message":"NotFound","description":"Movie not found"}`, "NotFound"},
+		{"plain text", "Internal Server Error", "Internal Server Error"},
+		{"long body", strings.Repeat("x", maxErrorBody+10), strings.Repeat("x", maxErrorBody) + "..."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describeResponseBody([]byte(tt.body)); got != tt.expected {
+				t.Errorf("describeResponseBody() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestClientsIncludeValidationFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`[{"propertyName":"RootFolderPath","errorMessage":"Folder is not writable by user abc"}]`))
+	}))
+	defer server.Close()
+	ctx := context.Background()
+	expected := "status: 400, response: RootFolderPath: Folder is not writable by user abc"
+
+	radarrClient := NewRadarrClient(&config.RadarrConfig{URL: server.URL, APIKey: "test-key"}, 5*time.Second, &mockLogger{})
+	if _, err := radarrClient.AddMovie(ctx, models.Movie{TMDBID: 1}); err == nil || !strings.Contains(err.Error(), expected) {
+		t.Errorf("Expected Radarr AddMovie() error to contain %q, got %v", expected, err)
+	}
+
+	sonarrClient := NewSonarrClient(&config.SonarrConfig{URL: server.URL, APIKey: "test-key"}, 5*time.Second, &mockLogger{})
+	if _, err := sonarrClient.AddSeries(ctx, models.Series{TVDBID: 1}); err == nil || !strings.Contains(err.Error(), expected) {
+		t.Errorf("Expected Sonarr AddSeries() error to contain %q, got %v", expected, err)
+	}
+}
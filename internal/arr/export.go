@@ -0,0 +1,168 @@
+package arr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+// ExportFiles returns every episode file (Sonarr), movie file (Radarr) or book file (Readarr)
+// record of the service, as it is recorded: nothing is checked on disk. Files holding several
+// episodes are listed once.
+func ExportFiles(ctx context.Context, client Client, logger Logger) ([]models.SnapshotFile, error) {
+	switch client.GetName() {
+	case "sonarr":
+		return exportEpisodeFiles(ctx, client, logger)
+	case "readarr":
+		return exportBookFiles(ctx, client, logger)
+	}
+	return exportMovieFiles(ctx, client, logger)
+}
+
+// exportEpisodeFiles does the work of ExportFiles for Sonarr
+func exportEpisodeFiles(ctx context.Context, client Client, logger Logger) ([]models.SnapshotFile, error) {
+	series, err := client.GetAllSeries(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get series: %w", err)
+	}
+
+	files := []models.SnapshotFile{}
+	for _, show := range series {
+		episodes, err := client.GetEpisodesForSeries(ctx, show.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get episodes for series %s: %w", show.Title, err)
+		}
+
+		// Group the episodes by file, keeping the order the files were first seen in
+		var fileIDs []int
+		episodesByFile := make(map[int][]models.Episode)
+		for _, episode := range episodes {
+			if !episode.HasFile || episode.EpisodeFileID == nil || *episode.EpisodeFileID == 0 {
+				continue
+			}
+			fileID := *episode.EpisodeFileID
+			if _, seen := episodesByFile[fileID]; !seen {
+				fileIDs = append(fileIDs, fileID)
+			}
+			episodesByFile[fileID] = append(episodesByFile[fileID], episode)
+		}
+
+		for _, fileID := range fileIDs {
+			episodeFile, err := client.GetEpisodeFile(ctx, fileID)
+			if err != nil {
+				if errors.Is(err, ErrNotFound) {
+					logger.Warn("⚠️  Episode file %d of %s no longer exists, skipping", fileID, show.Title)
+					continue
+				}
+				return nil, fmt.Errorf("failed to get episode file %d: %w", fileID, err)
+			}
+
+			fileEpisodes := episodesByFile[fileID]
+			numbers := make([]int, 0, len(fileEpisodes))
+			for _, episode := range fileEpisodes {
+				numbers = append(numbers, episode.EpisodeNumber)
+			}
+			sort.Ints(numbers)
+
+			files = append(files, models.SnapshotFile{
+				Service:   client.GetName(),
+				MediaType: "episode",
+				MediaID:   show.ID,
+				MediaName: show.Title,
+				Season:    fileEpisodes[0].SeasonNumber,
+				Episodes:  numbers,
+				FileID:    episodeFile.ID,
+				Path:      episodeFile.Path,
+				Size:      episodeFile.Size,
+				Quality:   episodeFile.Quality.Name(),
+			})
+		}
+		logger.Debug("Exported the episode files of %s", show.Title)
+	}
+
+	return files, nil
+}
+
+// exportMovieFiles does the work of ExportFiles for Radarr
+func exportMovieFiles(ctx context.Context, client Client, logger Logger) ([]models.SnapshotFile, error) {
+	movies, err := client.GetAllMovies(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get movies: %w", err)
+	}
+
+	files := []models.SnapshotFile{}
+	for _, movie := range movies {
+		if !movie.HasFile || movie.MovieFileID == nil || *movie.MovieFileID == 0 {
+			continue
+		}
+
+		movieFile, err := client.GetMovieFile(ctx, *movie.MovieFileID)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				logger.Warn("⚠️  Movie file %d of %s no longer exists, skipping", *movie.MovieFileID, movie.Title)
+				continue
+			}
+			return nil, fmt.Errorf("failed to get movie file %d: %w", *movie.MovieFileID, err)
+		}
+
+		files = append(files, models.SnapshotFile{
+			Service:   client.GetName(),
+			MediaType: "movie",
+			MediaID:   movie.ID,
+			MediaName: movie.Title,
+			FileID:    movieFile.ID,
+			Path:      movieFile.Path,
+			Size:      movieFile.Size,
+			Quality:   movieFile.Quality.Name(),
+		})
+	}
+
+	return files, nil
+}
+
+// exportBookFiles does the work of ExportFiles for Readarr
+func exportBookFiles(ctx context.Context, client Client, logger Logger) ([]models.SnapshotFile, error) {
+	bookClient, ok := client.(BookClient)
+	if !ok {
+		return nil, fmt.Errorf("%s client doesn't manage books", client.GetName())
+	}
+
+	books, err := bookClient.GetAllBooks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get books: %w", err)
+	}
+
+	files := []models.SnapshotFile{}
+	for _, book := range books {
+		if book.BookFileCount != nil && *book.BookFileCount == 0 {
+			continue
+		}
+
+		bookFiles, err := bookClient.GetBookFiles(ctx, book.ID)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				logger.Warn("⚠️  Book %s no longer exists, skipping", book.Title)
+				continue
+			}
+			return nil, fmt.Errorf("failed to get book files for %s: %w", book.Title, err)
+		}
+
+		for _, bookFile := range bookFiles {
+			files = append(files, models.SnapshotFile{
+				Service:   client.GetName(),
+				MediaType: "book",
+				MediaID:   book.ID,
+				MediaName: bookName(book),
+				FileID:    bookFile.ID,
+				Path:      bookFile.Path,
+				Size:      bookFile.Size,
+				Quality:   bookFile.Quality.Name(),
+			})
+		}
+	}
+
+	return files, nil
+}
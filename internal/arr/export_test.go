@@ -0,0 +1,91 @@
+package arr
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+func TestExportFiles_Episodes(t *testing.T) {
+	fileID := func(id int) *int { return &id }
+	client := NewSimulatedClient("sonarr", SimulationFixture{
+		Series: []models.Series{{MediaItem: models.MediaItem{ID: 1, Title: "Foo"}}},
+		Episodes: []models.Episode{
+			{ID: 11, SeriesID: 1, SeasonNumber: 1, EpisodeNumber: 2, HasFile: true, EpisodeFileID: fileID(100)},
+			{ID: 10, SeriesID: 1, SeasonNumber: 1, EpisodeNumber: 1, HasFile: true, EpisodeFileID: fileID(100)},
+			{ID: 12, SeriesID: 1, SeasonNumber: 1, EpisodeNumber: 3, HasFile: true, EpisodeFileID: fileID(101)},
+			{ID: 13, SeriesID: 1, SeasonNumber: 1, EpisodeNumber: 4},
+		},
+		EpisodeFiles: []models.EpisodeFile{
+			{ID: 100, Path: "/tv/Foo/S01E01-E02.mkv", Size: 2048, Quality: &models.FileQuality{Quality: models.Quality{ID: 7, Name: "Bluray-1080p"}}},
+		},
+	}, 0, &mockLogger{})
+
+	files, err := ExportFiles(context.Background(), client, &mockLogger{})
+	if err != nil {
+		t.Fatalf("ExportFiles() failed: %v", err)
+	}
+
+	// File 101 is gone, so only the double episode file is exported
+	want := []models.SnapshotFile{{
+		Service:   "sonarr",
+		MediaType: "episode",
+		MediaID:   1,
+		MediaName: "Foo",
+		Season:    1,
+		Episodes:  []int{1, 2},
+		FileID:    100,
+		Path:      "/tv/Foo/S01E01-E02.mkv",
+		Size:      2048,
+		Quality:   "Bluray-1080p",
+	}}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("ExportFiles() = %+v, want %+v", files, want)
+	}
+}
+
+func TestExportFiles_Movies(t *testing.T) {
+	fileID := 50
+	client := NewSimulatedClient("radarr", SimulationFixture{
+		Movies: []models.Movie{
+			{MediaItem: models.MediaItem{ID: 5, Title: "Foo"}, HasFile: true, MovieFileID: &fileID},
+			{MediaItem: models.MediaItem{ID: 6, Title: "Bar"}},
+		},
+		MovieFiles: []models.MovieFile{{ID: 50, MovieID: 5, Path: "/movies/Foo/Foo.mkv", Size: 4096}},
+	}, 0, &mockLogger{})
+
+	files, err := ExportFiles(context.Background(), client, &mockLogger{})
+	if err != nil {
+		t.Fatalf("ExportFiles() failed: %v", err)
+	}
+	if len(files) != 1 || files[0].FileID != 50 || files[0].MediaType != "movie" || files[0].Size != 4096 || files[0].Quality != "" {
+		t.Errorf("Unexpected export %+v", files)
+	}
+}
+
+func TestExportFiles_Books(t *testing.T) {
+	client := newTestBookLibrary()
+
+	files, err := ExportFiles(context.Background(), client, &mockLogger{})
+	if err != nil {
+		t.Fatalf("ExportFiles() failed: %v", err)
+	}
+
+	// Each format of a book is its own record; the book without files isn't fetched
+	if len(files) != 2 {
+		t.Fatalf("Expected 2 book file records, got %+v", files)
+	}
+	want := models.SnapshotFile{
+		Service:   "readarr",
+		MediaType: "book",
+		MediaID:   1,
+		MediaName: "Frank Herbert - Dune",
+		FileID:    11,
+		Path:      "/books/Frank Herbert/Dune.m4b",
+	}
+	if !reflect.DeepEqual(files[1], want) {
+		t.Errorf("ExportFiles()[1] = %+v, want %+v", files[1], want)
+	}
+}
@@ -0,0 +1,72 @@
+package arr
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"sync/atomic"
+)
+
+// RequestIDHeader carries the ID RefreshArr gives each request, so the *arr apps' and reverse
+// proxies' logs can be matched with RefreshArr's own debug log
+const RequestIDHeader = "X-Request-Id"
+
+// DefaultUserAgent is sent until SetUserAgent is called
+const DefaultUserAgent = "refresharr"
+
+// userAgent is the User-Agent header sent with every request to the *arr apps
+var userAgent atomic.Value
+
+// SetUserAgent sets the User-Agent header the clients send, e.g. "refresharr/1.2.0".
+// An empty value restores DefaultUserAgent.
+func SetUserAgent(ua string) {
+	if ua == "" {
+		ua = DefaultUserAgent
+	}
+	userAgent.Store(ua)
+}
+
+// currentUserAgent returns the User-Agent header the clients send
+func currentUserAgent() string {
+	if ua, ok := userAgent.Load().(string); ok {
+		return ua
+	}
+	return DefaultUserAgent
+}
+
+// headerTransport is an http.RoundTripper that identifies RefreshArr and gives each request an ID
+type headerTransport struct {
+	next   http.RoundTripper
+	logger Logger // nil disables logging the request IDs
+}
+
+// newHeaderTransport wraps next, falling back to the default transport when next is nil
+func newHeaderTransport(next http.RoundTripper, logger Logger) *headerTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &headerTransport{next: next, logger: logger}
+}
+
+// RoundTrip sets the User-Agent and request ID headers and passes the request on. Request IDs
+// the caller already set are kept.
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", currentUserAgent())
+	id := req.Header.Get(RequestIDHeader)
+	if id == "" {
+		id = newRequestID()
+		req.Header.Set(RequestIDHeader, id)
+	}
+	if t.logger != nil {
+		t.logger.Debug("→ %s %s (request %s)", req.Method, req.URL.Path, id)
+	}
+	return t.next.RoundTrip(req)
+}
+
+// newRequestID returns a random request identifier
+func newRequestID() string {
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,90 @@
+package arr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hnipps/refresharr/internal/config"
+)
+
+func TestClients_SendUserAgentAndRequestID(t *testing.T) {
+	var mu sync.Mutex
+	var userAgents, requestIDs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		userAgents = append(userAgents, r.Header.Get("User-Agent"))
+		requestIDs = append(requestIDs, r.Header.Get(RequestIDHeader))
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"version":"3.0.0"}`))
+	}))
+	defer server.Close()
+
+	SetUserAgent("refresharr/1.2.3")
+	defer SetUserAgent("")
+
+	logger := &mockLogger{}
+	clients := []Client{
+		NewRadarrClient(&config.RadarrConfig{URL: server.URL, APIKey: "key"}, 5*time.Second, logger),
+		NewStarrRadarrClient(&config.RadarrConfig{URL: server.URL, APIKey: "key"}, 5*time.Second, logger),
+		NewSonarrClient(&config.SonarrConfig{URL: server.URL, APIKey: "key"}, 5*time.Second, logger),
+	}
+	for _, client := range clients {
+		if err := client.TestConnection(context.Background()); err != nil {
+			t.Fatalf("%s: TestConnection() error = %v", client.GetName(), err)
+		}
+	}
+
+	if len(requestIDs) != len(clients) {
+		t.Fatalf("Expected %d requests, got %d", len(clients), len(requestIDs))
+	}
+	seen := make(map[string]bool)
+	for i, id := range requestIDs {
+		if userAgents[i] != "refresharr/1.2.3" {
+			t.Errorf("Request %d: expected User-Agent refresharr/1.2.3, got %q", i, userAgents[i])
+		}
+		if id == "" || seen[id] {
+			t.Errorf("Request %d: expected a unique request ID, got %q", i, id)
+		}
+		seen[id] = true
+
+		logged := false
+		for _, msg := range logger.debugMessages {
+			if strings.Contains(msg, id) {
+				logged = true
+			}
+		}
+		if !logged {
+			t.Errorf("Request %d: expected request ID %s to be logged", i, id)
+		}
+	}
+}
+
+func TestHeaderTransport_KeepsCallerRequestID(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get(RequestIDHeader)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: newHeaderTransport(nil, nil)}
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set(RequestIDHeader, "run-42")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if got != "run-42" {
+		t.Errorf("Expected request ID run-42, got %q", got)
+	}
+	if req.Header.Get("User-Agent") != "" {
+		t.Error("Expected the caller's request to be left unchanged")
+	}
+}
@@ -3,42 +3,87 @@ package arr
 import (
 	"context"
 	"fmt"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/hnipps/refresharr/pkg/models"
 )
 
 // ImportFixer handles fixing stuck import issues in Sonarr
 type ImportFixer struct {
-	client Client
-	logger Logger
-	dryRun bool
+	client         Client
+	logger         Logger
+	dryRun         bool
+	scope          *ActionScope            // Restricts fixes to queue items from a reviewed dry-run artifact
+	deleteRejected bool                    // Delete files Sonarr rejects as samples or unsupported, then import the rest
+	removeFile     func(path string) error // Deletes rejected files (os.Remove outside tests)
+	requeue        bool                    // Blocklist downloads that can't be imported and search for their episodes again
+	attempts       *ImportAttempts         // Failed attempts, for skipping items within the cooldown (nil never skips)
+	strategies     []ImportStrategy        // Ways of finding a stuck item's files, tried in order
+}
+
+// ImportFixerOptions holds the optional settings of an ImportFixer
+type ImportFixerOptions struct {
+	DryRun         bool
+	Scope          *ActionScope     // Restricts fixes to queue items from a reviewed dry-run artifact
+	DeleteRejected bool             // Delete files Sonarr rejects as samples or for their extension, then import the rest
+	Requeue        bool             // Blocklist downloads that can't be imported and search for their episodes again
+	Attempts       *ImportAttempts  // Skips items whose import failed within the cooldown (nil retries every run)
+	Strategies     []ImportStrategy // Ways of finding a stuck item's files, tried in order (nil means DefaultImportStrategies)
 }
 
 // NewImportFixer creates a new ImportFixer instance
 func NewImportFixer(client Client, logger Logger, dryRun bool) *ImportFixer {
+	return NewImportFixerWithScope(client, logger, dryRun, nil)
+}
+
+// NewImportFixerWithScope creates a new ImportFixer that only touches queue items allowed by scope
+func NewImportFixerWithScope(client Client, logger Logger, dryRun bool, scope *ActionScope) *ImportFixer {
+	return NewImportFixerWithOptions(client, logger, ImportFixerOptions{DryRun: dryRun, Scope: scope})
+}
+
+// NewImportFixerWithOptions creates a new ImportFixer with the given options
+func NewImportFixerWithOptions(client Client, logger Logger, opts ImportFixerOptions) *ImportFixer {
+	if opts.Strategies == nil {
+		opts.Strategies = DefaultImportStrategies()
+	}
 	return &ImportFixer{
-		client: client,
-		logger: logger,
-		dryRun: dryRun,
+		client:         client,
+		logger:         logger,
+		dryRun:         opts.DryRun,
+		scope:          opts.Scope,
+		deleteRejected: opts.DeleteRejected,
+		removeFile:     os.Remove,
+		requeue:        opts.Requeue,
+		attempts:       opts.Attempts,
+		strategies:     opts.Strategies,
 	}
 }
 
+// stuckImportQueueOptions fetches the queue items that may be stuck importing, with their series
+var stuckImportQueueOptions = models.QueueOptions{
+	Statuses:                  []string{"completed"},
+	IncludeUnknownSeriesItems: true,
+	IncludeSeries:             true,
+}
+
 // AnalyzeStuckImports finds all items in the queue with "already imported" issues
 func (f *ImportFixer) AnalyzeStuckImports(ctx context.Context) ([]models.QueueItem, error) {
-	f.logger.Info("Fetching download queue...")
+	f.logger.Info("Fetching completed downloads from the queue...")
 
-	queue, err := f.client.GetQueue(ctx)
+	// Only completed downloads can be stuck importing, so let Sonarr leave out the rest
+	queue, err := f.client.GetQueueWithOptions(ctx, stuckImportQueueOptions)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch queue: %w", err)
 	}
 
 	if len(queue) == 0 {
-		f.logger.Info("No items in queue")
+		f.logger.Info("No completed items in queue")
 		return []models.QueueItem{}, nil
 	}
 
-	f.logger.Info("Found %d items in queue", len(queue))
+	f.logger.Info("Found %d completed items in queue", len(queue))
 
 	var stuckItems []models.QueueItem
 	for _, item := range queue {
@@ -72,6 +117,15 @@ func (f *ImportFixer) AnalyzeStuckImports(ctx context.Context) ([]models.QueueIt
 		if item.DownloadClient != "" {
 			f.logger.Info("    DownloadClient: %s", item.DownloadClient)
 		}
+		if item.Indexer != "" {
+			f.logger.Info("    Indexer: %s", item.Indexer)
+		}
+		if item.Added != nil {
+			f.logger.Info("    Added: %s (%s ago)", item.Added.Format(time.RFC3339), time.Since(*item.Added).Round(time.Minute))
+		}
+		if item.TrackedDownloadState != "" {
+			f.logger.Info("    State: %s", item.TrackedDownloadState)
+		}
 
 		// Show status messages if available
 		for i, msg := range item.StatusMessages {
@@ -93,11 +147,24 @@ func (f *ImportFixer) isAlreadyImportedIssue(item models.QueueItem) bool {
 		return false
 	}
 
-	// Check status messages for the specific issue
+	// Leave items alone that haven't finished downloading, however close they are, and items
+	// Sonarr is importing right now
+	if item.SizeLeft > 0 {
+		f.logger.Debug("Skipping queue item %d: download %.1f%% complete", item.ID, item.Progress()*100)
+		return false
+	}
+	if strings.EqualFold(item.TrackedDownloadState, "importing") {
+		f.logger.Debug("Skipping queue item %d: import in progress", item.ID)
+		return false
+	}
+
+	// Check status messages for the specific issue. Sonarr puts the file name in the title
+	// and the reasons in the messages.
 	for _, message := range item.StatusMessages {
-		msgText := strings.ToLower(message.Title)
-		if f.containsImportIssueKeywords(msgText) {
-			return true
+		for _, text := range statusMessageTexts(message) {
+			if f.containsImportIssueKeywords(text) {
+				return true
+			}
 		}
 	}
 
@@ -107,9 +174,31 @@ func (f *ImportFixer) isAlreadyImportedIssue(item models.QueueItem) bool {
 		return true
 	}
 
+	// Sonarr v4 says whether the episode already has a file. If it does, a release that is
+	// "not an upgrade" is stuck for the same reason as an already imported one.
+	if item.EpisodeHasFile != nil && *item.EpisodeHasFile {
+		for _, message := range item.StatusMessages {
+			for _, text := range statusMessageTexts(message) {
+				if strings.Contains(text, "not an upgrade") {
+					return true
+				}
+			}
+		}
+	}
+
 	return false
 }
 
+// statusMessageTexts returns the lowercased title and messages of a status message
+func statusMessageTexts(message models.StatusMessage) []string {
+	texts := make([]string, 0, len(message.Messages)+1)
+	texts = append(texts, strings.ToLower(message.Title))
+	for _, msg := range message.Messages {
+		texts = append(texts, strings.ToLower(msg))
+	}
+	return texts
+}
+
 // containsImportIssueKeywords checks if a message contains import issue keywords
 func (f *ImportFixer) containsImportIssueKeywords(message string) bool {
 	keywords := []string{
@@ -134,9 +223,24 @@ func (f *ImportFixer) FixImports(ctx context.Context, removeFromClient bool) (*m
 		return nil, fmt.Errorf("failed to analyze stuck imports: %w", err)
 	}
 
+	if f.scope != nil {
+		scoped := make([]models.QueueItem, 0, len(stuckItems))
+		for _, item := range stuckItems {
+			if f.scope.Allows(models.PlannedAction{Action: models.ActionImportQueueItem, QueueItemID: item.ID}) {
+				scoped = append(scoped, item)
+			} else {
+				f.logger.Info("⏭️  Skipping queue item %d (%s): not listed in %s", item.ID, item.Title, f.scope.Source)
+			}
+		}
+		stuckItems = scoped
+	}
+
+	stuckItems, coolingDown := f.skipCoolingDown(stuckItems)
+
 	result := &models.ImportFixResult{
 		TotalStuckItems: len(stuckItems),
 		FixedItems:      0,
+		CoolingDown:     coolingDown,
 		Errors:          []string{},
 		Success:         true,
 		DryRun:          f.dryRun,
@@ -148,8 +252,29 @@ func (f *ImportFixer) FixImports(ctx context.Context, removeFromClient bool) (*m
 	}
 
 	if f.dryRun {
+		for _, item := range stuckItems {
+			action := models.PlannedAction{
+				Action:      models.ActionImportQueueItem,
+				MediaType:   "series",
+				MediaName:   item.Title,
+				QueueItemID: item.ID,
+				Path:        item.OutputPath,
+			}
+			if item.Series != nil {
+				action.MediaName = item.Series.Title
+				action.SeriesID = item.Series.ID
+				action.TVDBID = item.Series.TVDBID
+			}
+			result.Actions = append(result.Actions, action)
+			result.Actions = append(result.Actions, f.planRejectedDeletions(ctx, item)...)
+		}
+
 		f.logger.Info("[DRY RUN] Would attempt to import %d stuck import(s)", len(stuckItems))
-		f.logger.Info("Items that fail to import will be left in queue for manual resolution")
+		if f.requeue {
+			f.logger.Info("Items that fail to import will be blocklisted and their episodes searched for again")
+		} else {
+			f.logger.Info("Items that fail to import will be left in queue for manual resolution")
+		}
 		f.logger.Info("Run without --dry-run to actually process these items")
 		return result, nil
 	}
@@ -157,11 +282,17 @@ func (f *ImportFixer) FixImports(ctx context.Context, removeFromClient bool) (*m
 	f.logger.Info("Processing %d stuck imports - attempting to import without removing from queue...", len(stuckItems))
 
 	// First, try to trigger a download client scan to refresh stuck imports
-	f.logger.Info("Triggering download client scan to refresh stuck imports...")
-	if err := f.client.TriggerDownloadClientScan(ctx); err != nil {
-		f.logger.Warn("Failed to trigger download client scan: %s (continuing anyway)", err.Error())
+	if caps := capabilitiesOf(f.client); caps.Supports(FeatureDownloadedEpisodesScan) {
+		f.logger.Info("Triggering download client scan to refresh stuck imports...")
+		if err := f.client.TriggerDownloadClientScan(ctx); err != nil {
+			f.logger.Warn("Failed to trigger download client scan: %s (continuing anyway)", err.Error())
+		}
+	} else {
+		f.logger.Info("Skipping download client scan: not supported by Sonarr %s", caps.Version())
 	}
 
+	// Queue items of a season pack share its download, which is blocklisted once for all of them
+	blocklisted := make(map[string]bool)
 	for _, item := range stuckItems {
 		seriesTitle := "Unknown Series"
 		if item.Series != nil {
@@ -176,17 +307,23 @@ func (f *ImportFixer) FixImports(ctx context.Context, removeFromClient bool) (*m
 		if imported {
 			f.logger.Info("  ✓ Successfully imported via manual import")
 			result.FixedItems++
+		} else if f.requeue && f.requeueItem(ctx, item, removeFromClient, blocklisted) {
+			result.RequeuedItems++
 		} else {
 			// Log failure but do NOT remove from queue - leave for manual resolution
 			errMsg := fmt.Sprintf("Failed to import queue item %d (%s - %s). Item left in queue for manual resolution.", item.ID, seriesTitle, item.Title)
 			f.logger.Warn("  ⚠ %s", errMsg)
 			result.Errors = append(result.Errors, errMsg)
+			f.attempts.Record(item, time.Now())
 			// Note: We don't set Success = false here since this is expected behavior
 		}
 	}
+	if err := f.attempts.Save(); err != nil {
+		f.logger.Warn("Failed to save import attempts: %s", err.Error())
+	}
 
-	f.logger.Info("Import results: %d/%d successfully imported, %d left in queue for manual resolution",
-		result.FixedItems, result.TotalStuckItems, result.TotalStuckItems-result.FixedItems)
+	f.logger.Info("Import results: %d/%d successfully imported, %d searched for again, %d left in queue for manual resolution",
+		result.FixedItems, result.TotalStuckItems, result.RequeuedItems, result.TotalStuckItems-result.FixedItems-result.RequeuedItems)
 
 	if len(result.Errors) > 0 {
 		f.logger.Info("Items requiring manual attention:")
@@ -197,255 +334,109 @@ func (f *ImportFixer) FixImports(ctx context.Context, removeFromClient bool) (*m
 	return result, nil
 }
 
-// TestConnection tests the connection to the service
-func (f *ImportFixer) TestConnection(ctx context.Context) error {
-	return f.client.TestConnection(ctx)
-}
-
-// attemptManualImport tries to manually import a stuck queue item
-func (f *ImportFixer) attemptManualImport(ctx context.Context, item models.QueueItem) bool {
-	if item.Series == nil {
-		f.logger.Debug("  → No series information available for manual import")
-		return false
+// skipCoolingDown leaves out the items whose import failed within the cooldown, and returns
+// how many were left out
+func (f *ImportFixer) skipCoolingDown(items []models.QueueItem) ([]models.QueueItem, int) {
+	if f.attempts == nil {
+		return items, 0
 	}
-
-	seriesTitle := item.Series.Title
-	f.logger.Debug("  → Attempting manual import for: %s", seriesTitle)
-
-	// Strategy 1: Try using OutputPath if available
-	if item.OutputPath != "" {
-		f.logger.Debug("  → Trying OutputPath: %s", item.OutputPath)
-		if f.tryManualImportByPath(ctx, item.OutputPath, item) {
-			f.logger.Info("  → Successfully imported using OutputPath")
-			return true
-		}
-	}
-
-	// Strategy 2: Try using DownloadID if available
-	if item.DownloadID != "" {
-		f.logger.Debug("  → Trying DownloadID: %s", item.DownloadID)
-		if f.tryManualImportByDownloadID(ctx, item.DownloadID, item) {
-			f.logger.Info("  → Successfully imported using DownloadID")
-			return true
+	kept := make([]models.QueueItem, 0, len(items))
+	for _, item := range items {
+		if at, ok := f.attempts.CoolingDown(item); ok {
+			f.logger.Info("⏳ Skipping queue item %d (%s): import failed %s ago, retrying after %s",
+				item.ID, item.Title, time.Since(at).Round(time.Minute), at.Add(f.attempts.Cooldown()).Format(time.RFC3339))
+			continue
 		}
+		kept = append(kept, item)
 	}
-
-	// Strategy 3: Try using Series ID approach (scan for files matching the series)
-	f.logger.Debug("  → Trying SeriesID approach for series: %s (ID: %d)", seriesTitle, item.Series.ID)
-	if f.tryManualImportBySeriesID(ctx, item) {
-		f.logger.Info("  → Successfully imported using SeriesID approach")
-		return true
-	}
-
-	f.logger.Debug("  → All manual import strategies failed")
-	return false
+	return kept, len(items) - len(kept)
 }
 
-// tryManualImportByPath attempts manual import using a specific folder path
-// tryManualImportByPath attempts manual import using a specific folder path
-func (f *ImportFixer) tryManualImportByPath(ctx context.Context, folderPath string, item models.QueueItem) bool {
-	f.logger.Debug("    → Scanning folder for importable files: %s", folderPath)
-
-	// Get files available for manual import from this folder
-	// Use GetManualImportWithParams to provide series context when available
-	var manualImportItems []models.ManualImportItem
-	var err error
-
-	if item.Series != nil && item.Series.ID > 0 {
-		// Use series ID for better context when available
-		manualImportItems, err = f.client.GetManualImportWithParams(ctx, folderPath, "", item.Series.ID, true)
-	} else {
-		// Fall back to basic folder scan if no series context available
-		manualImportItems, err = f.client.GetManualImport(ctx, folderPath)
-	}
-
-	if err != nil {
-		f.logger.Debug("    → Failed to get manual import items for folder %s: %s", folderPath, err.Error())
+// requeueItem removes a queue item that can't be imported with its release blocklisted, and
+// searches for its episode so another release is grabbed. It reports whether the item was
+// requeued; items without an episode, or on clients that can't blocklist, are left in the queue.
+// Downloads already blocklisted this run, listed by ID in blocklisted, aren't blocklisted
+// again: removing the first item of a season pack took the others with it.
+func (f *ImportFixer) requeueItem(ctx context.Context, item models.QueueItem, removeFromClient bool, blocklisted map[string]bool) bool {
+	requeuer, ok := f.client.(Requeuer)
+	if !ok {
+		f.logger.Debug("  → %s can't blocklist queue items", f.client.GetName())
 		return false
 	}
-
-	if len(manualImportItems) == 0 {
-		f.logger.Debug("    → No importable files found in folder %s", folderPath)
+	if item.EpisodeID == 0 {
+		f.logger.Debug("  → Queue item %d doesn't name its episode, so there is nothing to search for", item.ID)
 		return false
 	}
 
-	f.logger.Debug("    → Found %d potential files for import", len(manualImportItems))
-
-	// Filter files that match our queue item and series
-	matchedFiles := f.filterMatchingFiles(manualImportItems, item)
-	if len(matchedFiles) == 0 {
-		f.logger.Debug("    → No files matched the queue item criteria")
-		return false
-	}
-
-	f.logger.Debug("    → %d files matched queue item criteria", len(matchedFiles))
-
-	// Execute manual import for matched files
-	return f.executeManualImport(ctx, matchedFiles, item)
-}
-
-// tryManualImportByDownloadID attempts manual import using download ID
-func (f *ImportFixer) tryManualImportByDownloadID(ctx context.Context, downloadID string, item models.QueueItem) bool {
-	f.logger.Debug("    → Attempting manual import using downloadID: %s", downloadID)
-
-	// Use the enhanced GetManualImportWithParams method with downloadID
-	manualImportItems, err := f.client.GetManualImportWithParams(ctx, "", downloadID, 0, true)
-	if err != nil {
-		f.logger.Debug("    → Failed to get manual import items by downloadID: %s", err.Error())
-	} else if len(manualImportItems) > 0 {
-		f.logger.Debug("    → Found %d files using downloadID", len(manualImportItems))
-		matchedFiles := f.filterMatchingFiles(manualImportItems, item)
-		if len(matchedFiles) > 0 {
-			return f.executeManualImport(ctx, matchedFiles, item)
+	if item.DownloadID != "" && blocklisted[item.DownloadID] {
+		f.logger.Info("  🚫 Its download was already removed and blocklisted: %s", item.Title)
+	} else {
+		if err := requeuer.BlocklistQueueItem(ctx, item.ID, removeFromClient); err != nil {
+			f.logger.Warn("  ⚠️  Failed to blocklist queue item %d: %s", item.ID, err.Error())
+			return false
+		}
+		f.logger.Info("  🚫 Removed from queue and blocklisted: %s", item.Title)
+		if item.DownloadID != "" {
+			blocklisted[item.DownloadID] = true
 		}
 	}
 
-	// Fallback: Try to find common download folders and search there
-	commonDownloadPaths := []string{
-		"/downloads/complete",
-		"/downloads",
-		"/mnt/downloads",
-		"/data/downloads",
-	}
-
-	for _, basePath := range commonDownloadPaths {
-		f.logger.Debug("    → Trying common download path: %s", basePath)
-		if f.tryManualImportByPath(ctx, basePath, item) {
-			return true
-		}
+	// The release is gone either way, so a failed search is reported but still counts as requeued:
+	// Sonarr searches for the episode again on its own schedule
+	if err := requeuer.SearchEpisodes(ctx, []int{item.EpisodeID}); err != nil {
+		f.logger.Warn("  ⚠️  Failed to search for episode %d again: %s", item.EpisodeID, err.Error())
+	} else {
+		f.logger.Info("  🔁 Searching for episode %d again", item.EpisodeID)
 	}
+	return true
+}
 
-	f.logger.Debug("    → DownloadID approach failed - no files found")
-	return false
+// TestConnection tests the connection to the service
+func (f *ImportFixer) TestConnection(ctx context.Context) error {
+	return f.client.TestConnection(ctx)
 }
 
-// tryManualImportBySeriesID attempts manual import by scanning for series-related files
-func (f *ImportFixer) tryManualImportBySeriesID(ctx context.Context, item models.QueueItem) bool {
+// attemptManualImport tries each import strategy in turn until one imports the stuck queue item
+func (f *ImportFixer) attemptManualImport(ctx context.Context, item models.QueueItem) bool {
 	if item.Series == nil {
+		f.logger.Debug("  → No series information available for manual import")
 		return false
 	}
+	f.logger.Debug("  → Attempting manual import for: %s", item.Series.Title)
 
-	f.logger.Debug("    → Attempting import using series information")
-
-	// Try series-specific paths first
-	seriesPaths := []string{
-		fmt.Sprintf("/downloads/complete/%s", item.Series.Title),
-		fmt.Sprintf("/downloads/%s", item.Series.Title),
-		fmt.Sprintf("/mnt/downloads/%s", item.Series.Title),
+	attempt := &ImportAttempt{
+		Client: f.client,
+		Logger: f.logger,
+		Item:   item,
+		Import: func(ctx context.Context, files []models.ManualImportItem) bool {
+			return f.executeManualImport(ctx, files, item)
+		},
 	}
-
-	for _, seriesPath := range seriesPaths {
-		f.logger.Debug("    → Trying series-specific path: %s", seriesPath)
-		if f.tryManualImportByPath(ctx, seriesPath, item) {
+	for _, strategy := range f.strategies {
+		if strategy.Attempt(ctx, attempt) {
+			f.logger.Info("  → Successfully imported using %s", strategy.Name())
 			return true
 		}
 	}
 
-	// If series-specific paths don't work, try generic download paths
-	// but filter more strictly by series ID
-	f.logger.Debug("    → Trying generic paths with series filtering")
-	return f.tryGenericPathsWithSeriesFiltering(ctx, item)
-}
-
-// tryGenericPathsWithSeriesFiltering tries common download paths with strict series filtering
-// tryGenericPathsWithSeriesFiltering tries common download paths with strict series filtering
-func (f *ImportFixer) tryGenericPathsWithSeriesFiltering(ctx context.Context, item models.QueueItem) bool {
-	// First try the enhanced method with series ID filtering
-	f.logger.Debug("    → Trying enhanced series ID filtering")
-	manualImportItems, err := f.client.GetManualImportWithParams(ctx, "", "", item.Series.ID, true)
-	if err != nil {
-		f.logger.Debug("    → Enhanced series ID filtering failed: %s", err.Error())
-	} else if len(manualImportItems) > 0 {
-		f.logger.Debug("    → Found %d files using series ID filtering", len(manualImportItems))
-		matchedFiles := f.filterFilesBySeriesID(manualImportItems, item.Series.ID)
-		if len(matchedFiles) > 0 {
-			if f.executeManualImport(ctx, matchedFiles, item) {
-				return true
-			}
-		}
-	}
-
-	// Fallback to path-based scanning with series context
-	commonPaths := []string{
-		"/downloads/complete",
-		"/downloads",
-		"/mnt/downloads",
-		"/data/downloads",
-	}
-
-	for _, path := range commonPaths {
-		f.logger.Debug("    → Scanning %s for series %s files", path, item.Series.Title)
-
-		// Use GetManualImportWithParams to provide series context
-		var manualImportItems []models.ManualImportItem
-		var err error
-
-		if item.Series != nil && item.Series.ID > 0 {
-			// Use series ID for better context when available
-			manualImportItems, err = f.client.GetManualImportWithParams(ctx, path, "", item.Series.ID, true)
-		} else {
-			// Fall back to basic folder scan if no series context available
-			manualImportItems, err = f.client.GetManualImport(ctx, path)
-		}
-
-		if err != nil {
-			f.logger.Debug("    → Failed to scan %s: %s", path, err.Error())
-			continue
-		}
-
-		// Apply strict filtering for this series only
-		matchedFiles := f.filterFilesBySeriesID(manualImportItems, item.Series.ID)
-		if len(matchedFiles) > 0 {
-			f.logger.Debug("    → Found %d files for series in %s", len(matchedFiles), path)
-			if f.executeManualImport(ctx, matchedFiles, item) {
-				return true
-			}
-		}
-	}
-
+	f.logger.Debug("  → All manual import strategies failed")
 	return false
 }
 
-// filterMatchingFiles filters manual import items to those matching the queue item
-func (f *ImportFixer) filterMatchingFiles(items []models.ManualImportItem, queueItem models.QueueItem) []models.ManualImportItem {
-	var matched []models.ManualImportItem
-
-	for _, item := range items {
-		// Check if file matches our series
-		if item.Series != nil && queueItem.Series != nil {
-			if item.Series.ID == queueItem.Series.ID {
-				f.logger.Debug("      → Matched file: %s (Series: %s)", item.Name, item.Series.Title)
-				matched = append(matched, item)
-			}
-		} else if queueItem.DownloadID != "" && item.DownloadID == queueItem.DownloadID {
-			// If no series match, try download ID match
-			f.logger.Debug("      → Matched file by downloadID: %s", item.Name)
-			matched = append(matched, item)
-		}
-	}
-
-	return matched
-}
-
-// filterFilesBySeriesID filters files strictly by series ID
-func (f *ImportFixer) filterFilesBySeriesID(items []models.ManualImportItem, seriesID int) []models.ManualImportItem {
-	var matched []models.ManualImportItem
-
-	for _, item := range items {
-		if item.Series != nil && item.Series.ID == seriesID {
-			matched = append(matched, item)
-		}
-	}
-
-	return matched
-}
-
 // executeManualImport executes the manual import for the given files
 func (f *ImportFixer) executeManualImport(ctx context.Context, files []models.ManualImportItem, queueItem models.QueueItem) bool {
 	if len(files) == 0 {
 		return false
 	}
 
+	if f.deleteRejected {
+		files = f.deleteRejectedFiles(files)
+		if len(files) == 0 {
+			f.logger.Debug("    → No files left to import after deleting rejected files")
+			return false
+		}
+	}
+
 	f.logger.Debug("    → Executing manual import for %d files", len(files))
 
 	// Log files being imported
@@ -467,3 +458,82 @@ func (f *ImportFixer) executeManualImport(ctx context.Context, files []models.Ma
 	f.logger.Debug("    → Manual import command executed successfully")
 	return true
 }
+
+// rejectedAsJunk returns why Sonarr rejected a manual import file as a sample or for its
+// extension, or "" when it didn't. Files Sonarr can't tell are samples are left alone.
+func rejectedAsJunk(item models.ManualImportItem) string {
+	for _, rejection := range item.Rejections {
+		text := strings.ToLower(strings.TrimSpace(rejection))
+		if text == "sample" || strings.Contains(text, "unsupported extension") {
+			return rejection
+		}
+	}
+	return ""
+}
+
+// deleteRejectedFiles deletes the files Sonarr rejected as samples or for their extension from
+// the download folder and returns the others, which can then be imported. Files the action scope
+// doesn't list, and files that can't be deleted, are left out of the import all the same.
+func (f *ImportFixer) deleteRejectedFiles(files []models.ManualImportItem) []models.ManualImportItem {
+	var remaining []models.ManualImportItem
+	for _, file := range files {
+		reason := rejectedAsJunk(file)
+		if reason == "" {
+			remaining = append(remaining, file)
+			continue
+		}
+
+		action := models.PlannedAction{Action: models.ActionDeleteRejectedFile, MediaType: "series", Path: file.Path}
+		if !f.scope.Allows(action) {
+			f.logger.Info("  ⏭️  Not deleting rejected file %s: not listed in %s", file.Path, f.scope.Source)
+			continue
+		}
+
+		f.logger.Info("  🗑️  Deleting %s (rejected: %s)", file.Path, reason)
+		if err := f.removeFile(file.Path); err != nil {
+			f.logger.Warn("  ⚠️  Failed to delete rejected file %s: %s", file.Path, err.Error())
+		}
+	}
+	return remaining
+}
+
+// planRejectedDeletions looks up the files in a stuck item's download folder and returns the
+// deletions of the files Sonarr rejects as samples or for their extension, for dry runs. Nothing
+// is planned unless rejected files are deleted, or when the item has no download folder.
+func (f *ImportFixer) planRejectedDeletions(ctx context.Context, item models.QueueItem) []models.PlannedAction {
+	if !f.deleteRejected || item.OutputPath == "" {
+		return nil
+	}
+
+	seriesID := 0
+	if item.Series != nil {
+		seriesID = item.Series.ID
+	}
+	files, err := f.client.GetManualImportWithParams(ctx, item.OutputPath, "", seriesID, true)
+	if err != nil {
+		f.logger.Warn("  ⚠️  Failed to list the files of %s: %s", item.OutputPath, err.Error())
+		return nil
+	}
+
+	var actions []models.PlannedAction
+	for _, file := range matchingFiles(files, item, f.logger) {
+		reason := rejectedAsJunk(file)
+		if reason == "" {
+			continue
+		}
+		action := models.PlannedAction{
+			Action:      models.ActionDeleteRejectedFile,
+			MediaType:   "series",
+			MediaName:   item.Title,
+			QueueItemID: item.ID,
+			Path:        file.Path,
+		}
+		if item.Series != nil {
+			action.MediaName = item.Series.Title
+			action.SeriesID = item.Series.ID
+		}
+		f.logger.Info("[DRY RUN] Would delete %s (rejected: %s)", file.Path, reason)
+		actions = append(actions, action)
+	}
+	return actions
+}
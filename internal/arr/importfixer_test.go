@@ -64,6 +64,60 @@ func TestImportFixer_isAlreadyImportedIssue(t *testing.T) {
 			},
 			expected: false,
 		},
+		{
+			name: "reason in the status message's messages",
+			item: models.QueueItem{
+				Status: "completed",
+				StatusMessages: []models.StatusMessage{
+					{Title: "Show.S02E05.mkv", Messages: []string{"Episode file already imported at 2023-05-01T10:00:00Z"}},
+				},
+			},
+			expected: true,
+		},
+		{
+			name: "not an upgrade when the episode has a file (Sonarr v4)",
+			item: models.QueueItem{
+				Status:         "completed",
+				EpisodeHasFile: boolPtr(true),
+				StatusMessages: []models.StatusMessage{
+					{Title: "Show.S02E06.mkv", Messages: []string{"Not an upgrade for existing episode file(s)"}},
+				},
+			},
+			expected: true,
+		},
+		{
+			name: "not an upgrade without episode file information",
+			item: models.QueueItem{
+				Status: "completed",
+				StatusMessages: []models.StatusMessage{
+					{Title: "Show.S02E06.mkv", Messages: []string{"Not an upgrade for existing episode file(s)"}},
+				},
+			},
+			expected: false,
+		},
+		{
+			name: "download not finished",
+			item: models.QueueItem{
+				Status:   "completed",
+				Size:     1000,
+				SizeLeft: 10,
+				StatusMessages: []models.StatusMessage{
+					{Title: "Episode file already imported"},
+				},
+			},
+			expected: false,
+		},
+		{
+			name: "import in progress",
+			item: models.QueueItem{
+				Status:               "completed",
+				TrackedDownloadState: "importing",
+				StatusMessages: []models.StatusMessage{
+					{Title: "Episode file already imported"},
+				},
+			},
+			expected: false,
+		},
 		{
 			name: "empty status messages",
 			item: models.QueueItem{
@@ -193,3 +247,203 @@ func TestImportFixer_TestConnection(t *testing.T) {
 		t.Errorf("TestConnection() returned error: %v", err)
 	}
 }
+
+func TestImportFixer_FixImports_DryRunRecordsActions(t *testing.T) {
+	logger := &mockLogger{}
+	client := &mockClient{
+		queue: []models.QueueItem{
+			{
+				ID:           7,
+				Title:        "Show.S01E01.1080p",
+				Series:       &models.Series{MediaItem: models.MediaItem{ID: 3, Title: "Show"}, TVDBID: 999},
+				Status:       "completed",
+				ErrorMessage: "Episode file already imported",
+				OutputPath:   "/downloads/Show.S01E01.1080p",
+			},
+			{
+				ID:     8,
+				Title:  "Other.S01E01",
+				Status: "downloading",
+			},
+		},
+	}
+	fixer := NewImportFixer(client, logger, true)
+
+	result, err := fixer.FixImports(context.Background(), true)
+	if err != nil {
+		t.Fatalf("FixImports() returned error: %v", err)
+	}
+
+	if len(result.Actions) != 1 {
+		t.Fatalf("Expected 1 planned action, got %d", len(result.Actions))
+	}
+	action := result.Actions[0]
+	if action.Action != models.ActionImportQueueItem {
+		t.Errorf("Expected action %q, got %q", models.ActionImportQueueItem, action.Action)
+	}
+	if action.QueueItemID != 7 || action.SeriesID != 3 || action.TVDBID != 999 {
+		t.Errorf("Unexpected action IDs: %+v", action)
+	}
+	if action.Path != "/downloads/Show.S01E01.1080p" {
+		t.Errorf("Expected action path '/downloads/Show.S01E01.1080p', got '%s'", action.Path)
+	}
+}
+
+func TestImportFixer_DeleteRejected(t *testing.T) {
+	series := &models.Series{MediaItem: models.MediaItem{ID: 3, Title: "Show"}, TVDBID: 999}
+	folder := "/downloads/Show.S01E01.1080p"
+	newClient := func() *mockClient {
+		return &mockClient{
+			queue: []models.QueueItem{{
+				ID:           7,
+				Title:        "Show.S01E01.1080p",
+				Series:       series,
+				Status:       "completed",
+				ErrorMessage: "One or more episodes expected in this release were not imported or missing from the release",
+				OutputPath:   folder,
+			}},
+			manualImports: map[string][]models.ManualImportItem{folder: {
+				{Path: folder + "/Show.S01E01.mkv", Series: series},
+				{Path: folder + "/sample.mkv", Series: series, Rejections: []string{"Sample"}},
+				{Path: folder + "/Show.S01E01.iso", Series: series, Rejections: []string{"Invalid video file, unsupported extension: '.iso'"}},
+				{Path: folder + "/maybe-sample.mkv", Series: series, Rejections: []string{"Unable to determine if file is a sample"}},
+			}},
+		}
+	}
+
+	// A real run deletes the samples and unsupported files, then imports the rest
+	client := newClient()
+	fixer := NewImportFixerWithOptions(client, &mockLogger{}, ImportFixerOptions{DeleteRejected: true})
+	var removed []string
+	fixer.removeFile = func(path string) error {
+		removed = append(removed, path)
+		return nil
+	}
+	result, err := fixer.FixImports(context.Background(), true)
+	if err != nil {
+		t.Fatalf("FixImports() returned error: %v", err)
+	}
+	if result.FixedItems != 1 {
+		t.Errorf("Expected the item to be imported, got %+v", result)
+	}
+	if len(removed) != 2 || removed[0] != folder+"/sample.mkv" || removed[1] != folder+"/Show.S01E01.iso" {
+		t.Errorf("Expected the sample and the unsupported file to be deleted, got %v", removed)
+	}
+	if len(client.importedFiles) != 2 || client.importedFiles[0].Path != folder+"/Show.S01E01.mkv" || client.importedFiles[1].Path != folder+"/maybe-sample.mkv" {
+		t.Errorf("Expected the remaining files to be imported, got %+v", client.importedFiles)
+	}
+
+	// A dry run plans the deletions without making them
+	client = newClient()
+	fixer = NewImportFixerWithOptions(client, &mockLogger{}, ImportFixerOptions{DryRun: true, DeleteRejected: true})
+	fixer.removeFile = func(path string) error {
+		t.Errorf("Expected nothing to be deleted in a dry run, deleted %s", path)
+		return nil
+	}
+	result, err = fixer.FixImports(context.Background(), true)
+	if err != nil {
+		t.Fatalf("FixImports() returned error: %v", err)
+	}
+	if len(result.Actions) != 3 || result.Actions[1].Action != models.ActionDeleteRejectedFile || result.Actions[2].Path != folder+"/Show.S01E01.iso" {
+		t.Errorf("Expected the import and two deletions to be planned, got %+v", result.Actions)
+	}
+	if len(client.importedFiles) != 0 {
+		t.Errorf("Expected nothing to be imported in a dry run, got %+v", client.importedFiles)
+	}
+
+	// Without the option, every matching file is imported as before
+	client = newClient()
+	fixer = NewImportFixer(client, &mockLogger{}, false)
+	if _, err := fixer.FixImports(context.Background(), true); err != nil {
+		t.Fatalf("FixImports() returned error: %v", err)
+	}
+	if len(client.importedFiles) != 4 {
+		t.Errorf("Expected all 4 files to be imported, got %+v", client.importedFiles)
+	}
+}
+
+func TestImportFixer_Requeue(t *testing.T) {
+	series := &models.Series{MediaItem: models.MediaItem{ID: 3, Title: "Show"}, TVDBID: 999}
+	newClient := func() *mockClient {
+		return &mockClient{
+			queue: []models.QueueItem{
+				{
+					ID:           7,
+					Title:        "Show.S01E01.1080p",
+					Series:       series,
+					EpisodeID:    101,
+					Status:       "completed",
+					ErrorMessage: "One or more episodes expected in this release were not imported or missing from the release",
+					OutputPath:   "/downloads/Show.S01E01.1080p",
+				},
+				{
+					ID:           8,
+					Title:        "Show.S01.Pack",
+					Series:       series,
+					Status:       "completed",
+					ErrorMessage: "One or more episodes expected in this release were not imported or missing from the release",
+				},
+			},
+		}
+	}
+
+	// Nothing can be imported: the item naming its episode is requeued, the other is left alone
+	client := newClient()
+	fixer := NewImportFixerWithOptions(client, &mockLogger{}, ImportFixerOptions{Requeue: true})
+	result, err := fixer.FixImports(context.Background(), true)
+	if err != nil {
+		t.Fatalf("FixImports() returned error: %v", err)
+	}
+	if result.RequeuedItems != 1 || len(result.Errors) != 1 {
+		t.Errorf("Expected 1 requeued item and 1 left in queue, got %+v", result)
+	}
+	if len(client.blocklisted) != 1 || client.blocklisted[0] != 7 {
+		t.Errorf("Expected queue item 7 to be blocklisted, got %v", client.blocklisted)
+	}
+	if len(client.searchedEpisodes) != 1 || client.searchedEpisodes[0] != 101 {
+		t.Errorf("Expected episode 101 to be searched for, got %v", client.searchedEpisodes)
+	}
+
+	// Dry runs and runs without the option never blocklist
+	for _, opts := range []ImportFixerOptions{{DryRun: true, Requeue: true}, {}} {
+		client := newClient()
+		if _, err := NewImportFixerWithOptions(client, &mockLogger{}, opts).FixImports(context.Background(), true); err != nil {
+			t.Fatalf("FixImports() returned error: %v", err)
+		}
+		if len(client.blocklisted) != 0 || len(client.searchedEpisodes) != 0 {
+			t.Errorf("Expected nothing to be requeued with %+v, got %v and %v", opts, client.blocklisted, client.searchedEpisodes)
+		}
+	}
+}
+
+func TestImportFixer_RequeueSeasonPack(t *testing.T) {
+	series := &models.Series{MediaItem: models.MediaItem{ID: 3, Title: "Show"}, TVDBID: 999}
+	packItem := func(id, episodeID int) models.QueueItem {
+		return models.QueueItem{
+			ID:           id,
+			Title:        "Show.S01.1080p",
+			Series:       series,
+			EpisodeID:    episodeID,
+			DownloadID:   "SABnzbd_nzo_pack",
+			Status:       "completed",
+			ErrorMessage: "One or more episodes expected in this release were not imported or missing from the release",
+		}
+	}
+	// One queue item per episode of the pack, all of the same download
+	client := &mockClient{queue: []models.QueueItem{packItem(7, 101), packItem(8, 102), packItem(9, 103)}}
+
+	fixer := NewImportFixerWithOptions(client, &mockLogger{}, ImportFixerOptions{Requeue: true})
+	result, err := fixer.FixImports(context.Background(), true)
+	if err != nil {
+		t.Fatalf("FixImports() returned error: %v", err)
+	}
+	if result.RequeuedItems != 3 || len(result.Errors) != 0 {
+		t.Errorf("Expected every episode of the pack to be requeued, got %+v", result)
+	}
+	if len(client.blocklisted) != 1 || client.blocklisted[0] != 7 {
+		t.Errorf("Expected the pack's download to be blocklisted once, got %v", client.blocklisted)
+	}
+	if len(client.searchedEpisodes) != 3 {
+		t.Errorf("Expected each episode of the pack to be searched for, got %v", client.searchedEpisodes)
+	}
+}
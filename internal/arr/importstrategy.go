@@ -0,0 +1,246 @@
+package arr
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+// ImportStrategy is one way of finding the files of a stuck queue item so they can be imported
+// by hand. The import fixer tries its strategies in order until one imports the item.
+type ImportStrategy interface {
+	// Name identifies the strategy in logs
+	Name() string
+	// Attempt looks for the queue item's files and hands each set it finds to attempt.Import,
+	// until a set is imported. It reports whether one was.
+	Attempt(ctx context.Context, attempt *ImportAttempt) bool
+}
+
+// ImportAttempt is what a strategy gets to find and import a stuck queue item's files
+type ImportAttempt struct {
+	Client Client
+	Logger Logger
+	Item   models.QueueItem // The stuck queue item; its Series is always set
+	// Import imports a set of files found for the item and reports whether it succeeded
+	Import func(ctx context.Context, files []models.ManualImportItem) bool
+}
+
+// DefaultImportStrategies returns the strategies the import fixer uses unless told otherwise:
+// the item's download folder, its download ID, and then the usual download folders searched
+// for the item's series
+func DefaultImportStrategies() []ImportStrategy {
+	return []ImportStrategy{OutputPathStrategy{}, DownloadIDStrategy{}, SeriesStrategy{}}
+}
+
+// commonDownloadPaths are the download folders searched when Sonarr doesn't say where an
+// item was downloaded to
+var commonDownloadPaths = []string{
+	"/downloads/complete",
+	"/downloads",
+	"/mnt/downloads",
+	"/data/downloads",
+}
+
+// ImportFolder scans folder for the item's files and imports those that match the item
+func (a *ImportAttempt) ImportFolder(ctx context.Context, folder string) bool {
+	a.Logger.Debug("    → Scanning folder for importable files: %s", folder)
+
+	// Provide series context when available, so Sonarr can match the files
+	var files []models.ManualImportItem
+	var err error
+	if a.Item.Series != nil && a.Item.Series.ID > 0 {
+		files, err = a.Client.GetManualImportWithParams(ctx, folder, "", a.Item.Series.ID, true)
+	} else {
+		files, err = a.Client.GetManualImport(ctx, folder)
+	}
+	if err != nil {
+		a.Logger.Debug("    → Failed to get manual import items for folder %s: %s", folder, err.Error())
+		return false
+	}
+	if len(files) == 0 {
+		a.Logger.Debug("    → No importable files found in folder %s", folder)
+		return false
+	}
+	a.Logger.Debug("    → Found %d potential files for import", len(files))
+
+	matched := a.MatchingFiles(files)
+	if len(matched) == 0 {
+		a.Logger.Debug("    → No files matched the queue item criteria")
+		return false
+	}
+	a.Logger.Debug("    → %d files matched queue item criteria", len(matched))
+	return a.Import(ctx, matched)
+}
+
+// MatchingFiles returns the files that belong to the queue item: its series (or download, when
+// Sonarr couldn't tell the series) and, when the item names one, its episode
+func (a *ImportAttempt) MatchingFiles(files []models.ManualImportItem) []models.ManualImportItem {
+	return matchingFiles(files, a.Item, a.Logger)
+}
+
+// SeriesFiles returns the files Sonarr matched to the queue item's series
+func (a *ImportAttempt) SeriesFiles(files []models.ManualImportItem) []models.ManualImportItem {
+	var matched []models.ManualImportItem
+	for _, file := range files {
+		if file.Series != nil && file.Series.ID == a.Item.Series.ID {
+			matched = append(matched, file)
+		}
+	}
+	return matched
+}
+
+// matchingFiles filters manual import files to those matching the queue item
+func matchingFiles(files []models.ManualImportItem, queueItem models.QueueItem, logger Logger) []models.ManualImportItem {
+	var matched []models.ManualImportItem
+
+	for _, file := range files {
+		// Skip files for other episodes of the series when the queue item names its episode
+		if !containsQueueEpisode(file, queueItem) {
+			logger.Debug("      → Skipping file for other episodes: %s", file.Name)
+			continue
+		}
+
+		// Check if file matches our series
+		if file.Series != nil && queueItem.Series != nil {
+			if file.Series.ID == queueItem.Series.ID {
+				logger.Debug("      → Matched file: %s (Series: %s)", file.Name, file.Series.Title)
+				matched = append(matched, file)
+			}
+		} else if queueItem.DownloadID != "" && file.DownloadID == queueItem.DownloadID {
+			// If no series match, try download ID match
+			logger.Debug("      → Matched file by downloadID: %s", file.Name)
+			matched = append(matched, file)
+		}
+	}
+
+	return matched
+}
+
+// containsQueueEpisode reports whether a manual import file can belong to the queue item's
+// episode. Sonarr lists a queue record per episode, so for season packs only the file for the
+// record's own episode matches. Files or queue items without episode information always match.
+func containsQueueEpisode(item models.ManualImportItem, queueItem models.QueueItem) bool {
+	if queueItem.EpisodeID == 0 || len(item.Episodes) == 0 {
+		return true
+	}
+	for _, episode := range item.Episodes {
+		if episode.ID == queueItem.EpisodeID {
+			return true
+		}
+	}
+	return false
+}
+
+// OutputPathStrategy imports the files in the folder Sonarr says the item was downloaded to
+type OutputPathStrategy struct{}
+
+// Name returns "OutputPath"
+func (OutputPathStrategy) Name() string {
+	return "OutputPath"
+}
+
+// Attempt imports the files of the item's output path, if it has one
+func (OutputPathStrategy) Attempt(ctx context.Context, attempt *ImportAttempt) bool {
+	if attempt.Item.OutputPath == "" {
+		return false
+	}
+	attempt.Logger.Debug("  → Trying OutputPath: %s", attempt.Item.OutputPath)
+	return attempt.ImportFolder(ctx, attempt.Item.OutputPath)
+}
+
+// DownloadIDStrategy asks Sonarr for the files of the item's download, and falls back to
+// scanning the common download folders
+type DownloadIDStrategy struct{}
+
+// Name returns "DownloadID"
+func (DownloadIDStrategy) Name() string {
+	return "DownloadID"
+}
+
+// Attempt imports the files of the item's download, if it has a download ID
+func (DownloadIDStrategy) Attempt(ctx context.Context, attempt *ImportAttempt) bool {
+	downloadID := attempt.Item.DownloadID
+	if downloadID == "" {
+		return false
+	}
+	attempt.Logger.Debug("  → Trying DownloadID: %s", downloadID)
+
+	files, err := attempt.Client.GetManualImportWithParams(ctx, "", downloadID, 0, true)
+	if err != nil {
+		attempt.Logger.Debug("    → Failed to get manual import items by downloadID: %s", err.Error())
+	} else if len(files) > 0 {
+		attempt.Logger.Debug("    → Found %d files using downloadID", len(files))
+		if matched := attempt.MatchingFiles(files); len(matched) > 0 {
+			return attempt.Import(ctx, matched)
+		}
+	}
+
+	// Fallback: search the common download folders
+	for _, folder := range commonDownloadPaths {
+		attempt.Logger.Debug("    → Trying common download path: %s", folder)
+		if attempt.ImportFolder(ctx, folder) {
+			return true
+		}
+	}
+
+	attempt.Logger.Debug("    → DownloadID approach failed - no files found")
+	return false
+}
+
+// SeriesStrategy searches folders named after the item's series, then asks Sonarr for any
+// files of the series, and finally scans the common download folders for them
+type SeriesStrategy struct{}
+
+// Name returns "SeriesID"
+func (SeriesStrategy) Name() string {
+	return "SeriesID"
+}
+
+// Attempt imports files Sonarr matches to the item's series
+func (SeriesStrategy) Attempt(ctx context.Context, attempt *ImportAttempt) bool {
+	series := attempt.Item.Series
+	attempt.Logger.Debug("  → Trying SeriesID approach for series: %s (ID: %d)", series.Title, series.ID)
+
+	// Try series-specific paths first
+	seriesPaths := []string{
+		fmt.Sprintf("/downloads/complete/%s", series.Title),
+		fmt.Sprintf("/downloads/%s", series.Title),
+		fmt.Sprintf("/mnt/downloads/%s", series.Title),
+	}
+	for _, folder := range seriesPaths {
+		attempt.Logger.Debug("    → Trying series-specific path: %s", folder)
+		if attempt.ImportFolder(ctx, folder) {
+			return true
+		}
+	}
+
+	// Then any files Sonarr matches to the series
+	attempt.Logger.Debug("    → Trying enhanced series ID filtering")
+	files, err := attempt.Client.GetManualImportWithParams(ctx, "", "", series.ID, true)
+	if err != nil {
+		attempt.Logger.Debug("    → Enhanced series ID filtering failed: %s", err.Error())
+	} else if len(files) > 0 {
+		attempt.Logger.Debug("    → Found %d files using series ID filtering", len(files))
+		if matched := attempt.SeriesFiles(files); len(matched) > 0 && attempt.Import(ctx, matched) {
+			return true
+		}
+	}
+
+	// Finally the common download folders, keeping only the series' files
+	for _, folder := range commonDownloadPaths {
+		attempt.Logger.Debug("    → Scanning %s for series %s files", folder, series.Title)
+		files, err := attempt.Client.GetManualImportWithParams(ctx, folder, "", series.ID, true)
+		if err != nil {
+			attempt.Logger.Debug("    → Failed to scan %s: %s", folder, err.Error())
+			continue
+		}
+		if matched := attempt.SeriesFiles(files); len(matched) > 0 {
+			attempt.Logger.Debug("    → Found %d files for series in %s", len(matched), folder)
+			if attempt.Import(ctx, matched) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+package arr
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+// recordingAttempt returns an attempt for item whose imports are recorded in imported, and
+// succeed unless fail is set
+func recordingAttempt(client Client, item models.QueueItem, imported *[][]models.ManualImportItem, fail bool) *ImportAttempt {
+	return &ImportAttempt{
+		Client: client,
+		Logger: &mockLogger{},
+		Item:   item,
+		Import: func(ctx context.Context, files []models.ManualImportItem) bool {
+			*imported = append(*imported, files)
+			return !fail
+		},
+	}
+}
+
+func TestImportStrategies(t *testing.T) {
+	show := &models.Series{MediaItem: models.MediaItem{ID: 3, Title: "Show"}}
+	other := &models.Series{MediaItem: models.MediaItem{ID: 4, Title: "Other"}}
+	client := &mockClient{manualImports: map[string][]models.ManualImportItem{
+		"/downloads/Show.S01E01": {{Path: "/downloads/Show.S01E01/a.mkv", Series: show}, {Path: "/downloads/Show.S01E01/b.mkv", Series: other}},
+		"/downloads/complete":    {{Path: "/downloads/complete/c.mkv", Series: show}},
+		"/downloads":             {{Path: "/downloads/d.mkv", Series: show}},
+	}}
+	item := models.QueueItem{ID: 7, Series: show, OutputPath: "/downloads/Show.S01E01"}
+
+	tests := []struct {
+		name     string
+		strategy ImportStrategy
+		item     models.QueueItem
+		expected string // Path of the first file imported, "" for no import
+	}{
+		{"output path imports the item's files", OutputPathStrategy{}, item, "/downloads/Show.S01E01/a.mkv"},
+		{"output path needs an output path", OutputPathStrategy{}, models.QueueItem{ID: 7, Series: show}, ""},
+		{"download ID falls back to the common folders", DownloadIDStrategy{}, models.QueueItem{ID: 7, Series: show, DownloadID: "ABC"}, "/downloads/complete/c.mkv"},
+		{"download ID needs a download ID", DownloadIDStrategy{}, item, ""},
+		{"series scans the common folders", SeriesStrategy{}, models.QueueItem{ID: 7, Series: show}, "/downloads/complete/c.mkv"},
+		{"series finds nothing of another series", SeriesStrategy{}, models.QueueItem{ID: 8, Series: &models.Series{MediaItem: models.MediaItem{ID: 5, Title: "None"}}}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var imported [][]models.ManualImportItem
+			ok := tt.strategy.Attempt(context.Background(), recordingAttempt(client, tt.item, &imported, false))
+			if ok != (tt.expected != "") {
+				t.Fatalf("Attempt() = %v, imported %+v", ok, imported)
+			}
+			if tt.expected != "" && (len(imported) != 1 || len(imported[0]) != 1 || imported[0][0].Path != tt.expected) {
+				t.Errorf("Expected only %s to be imported, got %+v", tt.expected, imported)
+			}
+		})
+	}
+
+	// A failed import moves on to the next folder
+	var imported [][]models.ManualImportItem
+	if (SeriesStrategy{}).Attempt(context.Background(), recordingAttempt(client, models.QueueItem{ID: 7, Series: show}, &imported, true)) {
+		t.Error("Expected the strategy to fail when no import succeeds")
+	}
+	if len(imported) != 2 {
+		t.Errorf("Expected every folder with files to be tried, got %+v", imported)
+	}
+}
+
+// folderStrategy is a custom strategy that imports from a fixed folder
+type folderStrategy struct {
+	folder string
+}
+
+func (s folderStrategy) Name() string {
+	return "folder " + s.folder
+}
+
+func (s folderStrategy) Attempt(ctx context.Context, attempt *ImportAttempt) bool {
+	return attempt.ImportFolder(ctx, s.folder)
+}
+
+func TestImportFixer_CustomStrategies(t *testing.T) {
+	series := &models.Series{MediaItem: models.MediaItem{ID: 3, Title: "Show"}}
+	client := &mockClient{
+		queue: []models.QueueItem{{
+			ID:           7,
+			Title:        "Show.S01E01.1080p",
+			Series:       series,
+			Status:       "completed",
+			ErrorMessage: "Episode file already imported",
+			OutputPath:   "/downloads/Show.S01E01.1080p",
+		}},
+		manualImports: map[string][]models.ManualImportItem{
+			"/downloads/Show.S01E01.1080p":  {{Path: "/downloads/Show.S01E01.1080p/a.mkv", Series: series}},
+			"/mnt/remote/Show.S01E01.1080p": {{Path: "/mnt/remote/Show.S01E01.1080p/a.mkv", Series: series}},
+		},
+	}
+
+	fixer := NewImportFixerWithOptions(client, &mockLogger{}, ImportFixerOptions{
+		Strategies: []ImportStrategy{folderStrategy{folder: "/mnt/remote/Show.S01E01.1080p"}},
+	})
+	var service ImportFixService = fixer
+	result, err := service.FixImports(context.Background(), true)
+	if err != nil {
+		t.Fatalf("FixImports() returned error: %v", err)
+	}
+	if result.FixedItems != 1 || len(client.importedFiles) != 1 || client.importedFiles[0].Path != "/mnt/remote/Show.S01E01.1080p/a.mkv" {
+		t.Errorf("Expected only the custom strategy to import, got %+v and %+v", result, client.importedFiles)
+	}
+}
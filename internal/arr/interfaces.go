@@ -26,6 +26,9 @@ type Client interface {
 	// GetEpisodesForSeries returns all episodes for a given series
 	GetEpisodesForSeries(ctx context.Context, seriesID int) ([]models.Episode, error)
 
+	// GetEpisode returns a single episode by ID (Sonarr specific)
+	GetEpisode(ctx context.Context, episodeID int) (*models.Episode, error)
+
 	// GetEpisodeFile returns episode file details
 	GetEpisodeFile(ctx context.Context, fileID int) (*models.EpisodeFile, error)
 
@@ -54,6 +57,12 @@ type Client interface {
 	// LookupMovieByTMDBID looks up movie information by TMDB ID
 	LookupMovieByTMDBID(ctx context.Context, tmdbID int) (*models.MovieLookup, error)
 
+	// LookupMovieByIMDBID looks up movie information by IMDb ID (tt1234567)
+	LookupMovieByIMDBID(ctx context.Context, imdbID string) (*models.MovieLookup, error)
+
+	// LookupMoviesByTerm searches for movies by title
+	LookupMoviesByTerm(ctx context.Context, term string) ([]models.MovieLookup, error)
+
 	// AddMovie adds a movie to the collection
 	AddMovie(ctx context.Context, movie models.Movie) (*models.Movie, error)
 
@@ -66,6 +75,9 @@ type Client interface {
 	// LookupSeriesByTVDBID looks up series information by TVDB ID (Sonarr specific)
 	LookupSeriesByTVDBID(ctx context.Context, tvdbID int) (*models.SeriesLookup, error)
 
+	// LookupSeriesByTerm searches for series by title (Sonarr specific)
+	LookupSeriesByTerm(ctx context.Context, term string) ([]models.SeriesLookup, error)
+
 	// AddSeries adds a series to the collection (Sonarr specific)
 	AddSeries(ctx context.Context, series models.Series) (*models.Series, error)
 
@@ -73,6 +85,7 @@ type Client interface {
 
 	// Queue management methods (primarily for Sonarr import fixing)
 	GetQueue(ctx context.Context) ([]models.QueueItem, error)
+	GetQueueWithOptions(ctx context.Context, opts models.QueueOptions) ([]models.QueueItem, error)
 	GetQueueDetails(ctx context.Context, queueID int) (*models.QueueItem, error)
 	RemoveFromQueue(ctx context.Context, queueID int, removeFromClient bool) error
 
@@ -83,15 +96,75 @@ type Client interface {
 	ExecuteManualImport(ctx context.Context, files []models.ManualImportItem, importMode string) error
 }
 
+// PathUpdater is implemented by clients that can point a series (Sonarr) or movie (Radarr) at
+// another folder. Files are not moved: the service's file records follow the folder, which
+// repairs records left behind when media was moved outside the service.
+type PathUpdater interface {
+	// MediaPath returns the folder of the series or movie
+	MediaPath(ctx context.Context, id int) (string, error)
+	// UpdateMediaPath sets the folder of the series or movie to path
+	UpdateMediaPath(ctx context.Context, id int, path string) error
+}
+
+// Requeuer is implemented by clients that can give up on a download and search for its
+// episodes again (Sonarr), so a download that can't be imported is replaced by another release
+type Requeuer interface {
+	// BlocklistQueueItem removes a queue item and blocklists its release so it isn't grabbed again
+	BlocklistQueueItem(ctx context.Context, queueID int, removeFromClient bool) error
+	// SearchEpisodes triggers a search for the episodes
+	SearchEpisodes(ctx context.Context, episodeIDs []int) error
+}
+
+// Tagger is implemented by clients that can tag series (Sonarr) or movies (Radarr), so the items
+// a run changed can be filtered on inside the service
+type Tagger interface {
+	// FindTag returns the ID of the tag with the label, and false when there is none
+	FindTag(ctx context.Context, label string) (int, bool, error)
+	// EnsureTag returns the ID of the tag with the label, creating the tag when there is none
+	EnsureTag(ctx context.Context, label string) (int, error)
+	// TaggedMedia returns the IDs of the series or movies with the tag
+	TaggedMedia(ctx context.Context, tagID int) ([]int, error)
+	// AddMediaTag adds the tag to the series or movie, leaving its other tags as they are
+	AddMediaTag(ctx context.Context, id, tagID int) error
+	// RemoveMediaTag removes the tag from the series or movie, leaving its other tags as they are
+	RemoveMediaTag(ctx context.Context, id, tagID int) error
+}
+
+// BookClient is implemented by clients of services that manage books (Readarr), whose records
+// the series and movie methods of Client don't cover
+type BookClient interface {
+	// GetAllBooks returns every book in the library
+	GetAllBooks(ctx context.Context) ([]models.Book, error)
+	// GetBookFiles returns the files of a book
+	GetBookFiles(ctx context.Context, bookID int) ([]models.BookFile, error)
+	// DeleteBookFile deletes a book file record
+	DeleteBookFile(ctx context.Context, fileID int) error
+	// LookupBookByISBN looks up a book by its ISBN-10 or ISBN-13
+	LookupBookByISBN(ctx context.Context, isbn string) (*models.Book, error)
+}
+
 // FileChecker defines the interface for file system operations
 type FileChecker interface {
 	FileExists(path string) bool
 	IsReadable(path string) bool
+	FileSize(path string) (int64, error)
 	FindBrokenSymlinks(rootDir string, extensions []string) ([]string, error)
 	IsSymlink(path string) bool
 	DeleteSymlink(path string) error
 }
 
+// MissingReasonClassifier is implemented by file checkers that can tell why a file is missing
+type MissingReasonClassifier interface {
+	// MissingReason returns why nothing readable is at path, one of the models.Reason constants
+	MissingReason(path string) string
+}
+
+// FileHasher is implemented by file checkers that can checksum a file's contents
+type FileHasher interface {
+	// FileSHA256 returns the hex encoded SHA-256 sum of the file at path
+	FileSHA256(path string) (string, error)
+}
+
 // CleanupService defines the interface for cleanup operations
 type CleanupService interface {
 	// CleanupMissingFiles performs the cleanup operation
@@ -102,6 +175,31 @@ type CleanupService interface {
 
 	// CleanupMissingFilesForMovies performs cleanup for specific movies
 	CleanupMissingFilesForMovies(ctx context.Context, movieIDs []int) (*models.CleanupResult, error)
+
+	// CleanupMissingFilesForEpisodes performs cleanup for specific episodes (Sonarr specific)
+	CleanupMissingFilesForEpisodes(ctx context.Context, episodeIDs []int) (*models.CleanupResult, error)
+
+	// CleanupMissingFileAtPath resolves the record that owns a file path and cleans up just that item
+	CleanupMissingFileAtPath(ctx context.Context, path string) (*models.CleanupResult, error)
+}
+
+// ImportFixService finds downloads stuck in the queue after a failed import and imports them.
+// ImportFixer implements it with a pipeline of ImportStrategy.
+type ImportFixService interface {
+	// TestConnection tests the connection to the service
+	TestConnection(ctx context.Context) error
+
+	// AnalyzeStuckImports returns the completed queue items that failed to import
+	AnalyzeStuckImports(ctx context.Context) ([]models.QueueItem, error)
+
+	// FixImports imports the stuck queue items, or plans to in a dry run
+	FixImports(ctx context.Context, removeFromClient bool) (*models.ImportFixResult, error)
+}
+
+// SearchGate decides whether missing-item searches can be triggered right now
+type SearchGate interface {
+	// SearchAllowed reports whether searches should run; reason explains why they should not
+	SearchAllowed(ctx context.Context) (allowed bool, reason string, err error)
 }
 
 // Logger defines the interface for logging operations
@@ -124,3 +222,13 @@ type ProgressReporter interface {
 	ReportError(err error)
 	Finish(stats models.CleanupStats)
 }
+
+// PhaseReporter is implemented by progress reporters that follow which part of a run is in
+// progress and how far through it the run is
+type PhaseReporter interface {
+	// StartPhase reports that the run moved on to phase. total is the number of items the
+	// phase works through, or 0 when it doesn't work through items.
+	StartPhase(phase string, total int)
+	// FinishItem reports that one of the current phase's items was processed
+	FinishItem()
+}
@@ -1,8 +1,10 @@
 package arr
 
 import (
-	"fmt"
+	"io"
 	"log"
+	"log/slog"
+	"os"
 	"strings"
 )
 
@@ -16,55 +18,51 @@ const (
 	LogLevelError
 )
 
-// StandardLogger implements the Logger interface using Go's standard log package
+// StandardLogger implements the Logger interface using Go's standard log package, writing
+// "[LEVEL] message" lines. It has no WithItem, as its lines don't show item fields.
 type StandardLogger struct {
-	level  LogLevel
-	logger *log.Logger
+	logger *SlogLogger
 }
 
 // NewStandardLogger creates a new StandardLogger
 func NewStandardLogger(levelStr string) Logger {
-	level := parseLogLevel(levelStr)
-	return &StandardLogger{
-		level:  level,
-		logger: log.Default(),
-	}
+	return NewStandardLoggerWithOptions(LoggerOptions{Level: levelStr})
 }
 
-// Debug logs a debug message
-func (l *StandardLogger) Debug(msg string, args ...interface{}) {
-	if l.level <= LogLevelDebug {
-		l.log("DEBUG", msg, args...)
-	}
+// NewStandardLoggerWithOptions creates a StandardLogger with per-module levels
+func NewStandardLoggerWithOptions(opts LoggerOptions) Logger {
+	return &StandardLogger{logger: NewSlogLogger(NewTextHandler(log.Default()), opts)}
 }
 
-// Info logs an info message
-func (l *StandardLogger) Info(msg string, args ...interface{}) {
-	if l.level <= LogLevelInfo {
-		l.log("INFO", msg, args...)
+// NewLogger creates the logger for a log format: "json" gives a JSON logger writing to stderr,
+// anything else a StandardLogger
+func NewLogger(format string, opts LoggerOptions) Logger {
+	if format == "json" {
+		return NewSlogLogger(NewJSONHandler(os.Stderr), opts)
 	}
+	return NewStandardLoggerWithOptions(opts)
 }
 
+// Debug logs a debug message
+func (l *StandardLogger) Debug(msg string, args ...interface{}) { l.logger.Debug(msg, args...) }
+
+// Info logs an info message
+func (l *StandardLogger) Info(msg string, args ...interface{}) { l.logger.Info(msg, args...) }
+
 // Warn logs a warning message
-func (l *StandardLogger) Warn(msg string, args ...interface{}) {
-	if l.level <= LogLevelWarn {
-		l.log("WARN", msg, args...)
-	}
-}
+func (l *StandardLogger) Warn(msg string, args ...interface{}) { l.logger.Warn(msg, args...) }
 
 // Error logs an error message
-func (l *StandardLogger) Error(msg string, args ...interface{}) {
-	if l.level <= LogLevelError {
-		l.log("ERROR", msg, args...)
-	}
+func (l *StandardLogger) Error(msg string, args ...interface{}) { l.logger.Error(msg, args...) }
+
+// With returns a logger that appends the key/value pairs to every line
+func (l *StandardLogger) With(args ...any) Logger {
+	return &StandardLogger{logger: l.logger.with(args...)}
 }
 
-// log is the internal logging method
-func (l *StandardLogger) log(level, msg string, args ...interface{}) {
-	if len(args) > 0 {
-		msg = fmt.Sprintf(msg, args...)
-	}
-	l.logger.Printf("[%s] %s", level, msg)
+// Module returns a logger for a module, filtered by its level
+func (l *StandardLogger) Module(name string) Logger {
+	return &StandardLogger{logger: l.logger.forModule(name)}
 }
 
 // parseLogLevel parses a log level string into LogLevel
@@ -82,3 +80,108 @@ func parseLogLevel(levelStr string) LogLevel {
 		return LogLevelInfo
 	}
 }
+
+// String returns the name of the level as it appears in log lines
+func (level LogLevel) String() string {
+	switch level {
+	case LogLevelDebug:
+		return "DEBUG"
+	case LogLevelInfo:
+		return "INFO"
+	case LogLevelWarn:
+		return "WARN"
+	default:
+		return "ERROR"
+	}
+}
+
+// slogLevel returns the slog level of a log level
+func (level LogLevel) slogLevel() slog.Level {
+	switch level {
+	case LogLevelDebug:
+		return slog.LevelDebug
+	case LogLevelInfo:
+		return slog.LevelInfo
+	case LogLevelWarn:
+		return slog.LevelWarn
+	default:
+		return slog.LevelError
+	}
+}
+
+// logLevelOf returns the log level of a slog level, rounding custom levels down
+func logLevelOf(level slog.Level) LogLevel {
+	switch {
+	case level < slog.LevelInfo:
+		return LogLevelDebug
+	case level < slog.LevelWarn:
+		return LogLevelInfo
+	case level < slog.LevelError:
+		return LogLevelWarn
+	default:
+		return LogLevelError
+	}
+}
+
+// ItemFields identifies the series, movie, book, episode, file or path a log line is about.
+// Zero values are left out of the line.
+type ItemFields struct {
+	RunID     string `json:"runId,omitempty"` // History run ID, shared by every line of a service's run
+	Service   string `json:"service,omitempty"`
+	SeriesID  int    `json:"seriesId,omitempty"`
+	MovieID   int    `json:"movieId,omitempty"`
+	BookID    int    `json:"bookId,omitempty"`
+	EpisodeID int    `json:"episodeId,omitempty"`
+	FileID    int    `json:"fileId,omitempty"`
+	Path      string `json:"path,omitempty"`
+}
+
+// merge returns f with the non-zero fields of other set. A service that is already set is kept,
+// as the run's logger names the instance (e.g. radarr-4k) where items only know the service.
+func (f ItemFields) merge(other ItemFields) ItemFields {
+	if other.RunID != "" {
+		f.RunID = other.RunID
+	}
+	if other.Service != "" && f.Service == "" {
+		f.Service = other.Service
+	}
+	if other.SeriesID != 0 {
+		f.SeriesID = other.SeriesID
+	}
+	if other.MovieID != 0 {
+		f.MovieID = other.MovieID
+	}
+	if other.BookID != 0 {
+		f.BookID = other.BookID
+	}
+	if other.EpisodeID != 0 {
+		f.EpisodeID = other.EpisodeID
+	}
+	if other.FileID != 0 {
+		f.FileID = other.FileID
+	}
+	if other.Path != "" {
+		f.Path = other.Path
+	}
+	return f
+}
+
+// ItemLogger is implemented by loggers that can attach ItemFields to their lines
+type ItemLogger interface {
+	WithItem(fields ItemFields) Logger
+}
+
+// WithItem returns a logger that attaches fields to every line it writes, added to any the
+// logger already has. Loggers that don't implement ItemLogger are returned as they are.
+func WithItem(logger Logger, fields ItemFields) Logger {
+	if itemLogger, ok := logger.(ItemLogger); ok {
+		return itemLogger.WithItem(fields)
+	}
+	return logger
+}
+
+// NewJSONLogger creates a logger writing each message to out as a JSON object on its own line,
+// with the item fields as top-level keys so log pipelines can filter on them
+func NewJSONLogger(levelStr string, out io.Writer) Logger {
+	return NewSlogLogger(NewJSONHandler(out), LoggerOptions{Level: levelStr})
+}
@@ -2,8 +2,11 @@ package arr
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"log"
+	"log/slog"
 	"strings"
 	"testing"
 
@@ -23,6 +26,142 @@ func TestNewStandardLogger(t *testing.T) {
 	logger.Error("test error")
 }
 
+func TestJSONLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewJSONLogger("INFO", &buf)
+	logger.Debug("hidden")
+	item := WithItem(logger, ItemFields{Service: "radarr", MovieID: 7})
+	WithItem(item, ItemFields{FileID: 70, Path: "/movies/Foo (2020)/Foo.mkv"}).Warn("  ⚠️  Size mismatch: %s", "Foo.mkv")
+	logger.Info("plain")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %q", buf.String())
+	}
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("Line is not JSON: %v", err)
+	}
+	if entry["level"] != "WARN" || entry["msg"] != "⚠️  Size mismatch: Foo.mkv" || entry["time"] == nil {
+		t.Errorf("Unexpected line: %v", entry)
+	}
+	if entry["service"] != "radarr" || entry["movieId"] != float64(7) || entry["fileId"] != float64(70) || entry["path"] != "/movies/Foo (2020)/Foo.mkv" {
+		t.Errorf("Expected the item fields, got %v", entry)
+	}
+
+	// Fields only apply to the derived logger
+	if strings.Contains(lines[1], "movieId") {
+		t.Errorf("Expected no item fields on the base logger's line, got %s", lines[1])
+	}
+}
+
+func TestJSONLogger_RunFields(t *testing.T) {
+	var buf bytes.Buffer
+	run := WithItem(NewJSONLogger("INFO", &buf), ItemFields{RunID: "run-1", Service: "radarr-4k"})
+	run.Info("📡 radarr-4k API calls this run: %d", 12)
+	WithItem(run, ItemFields{Service: "radarr", MovieID: 7}).Info("Deleting movie file")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %q", buf.String())
+	}
+	for _, line := range lines {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Line is not JSON: %v", err)
+		}
+		// Items only know the service, so the run's instance label is kept
+		if entry["runId"] != "run-1" || entry["service"] != "radarr-4k" {
+			t.Errorf("Expected the run's ID and instance on every line, got %v", entry)
+		}
+	}
+}
+
+func TestSlogLogger_ModuleLevels(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewSlogLogger(NewJSONHandler(&buf), LoggerOptions{Level: "INFO", ModuleLevels: map[string]string{"notify": "DEBUG", "cleanup": "WARN"}})
+	ForModule(logger, ModuleNotify).Debug("notify debug")
+	ForModule(logger, ModuleCleanup).Info("cleanup info")
+	ForModule(logger, ModuleCleanup).Warn("cleanup warn")
+	ForModule(logger, ModuleAPI).Debug("api debug")
+	logger.Debug("plain debug")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %q", buf.String())
+	}
+	for i, want := range []string{"notify", "cleanup"} {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(lines[i]), &entry); err != nil {
+			t.Fatalf("Line is not JSON: %v", err)
+		}
+		if entry["module"] != want {
+			t.Errorf("Expected line %d to name the %s module, got %v", i, want, entry)
+		}
+	}
+}
+
+func TestSlogLogger_With(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewSlogLogger(NewTextHandler(log.New(&buf, "", 0)), LoggerOptions{Level: "INFO"})
+	requests := WithAttrs(logger, "method", "GET", "status", 200)
+	WithItem(requests, ItemFields{MovieID: 7}).Info("Fetched %s", "movie")
+	logger.Info("plain")
+
+	// Item fields stay out of text lines, as they always have
+	want := "[INFO] Fetched movie method=GET status=200\n[INFO] plain\n"
+	if buf.String() != want {
+		t.Errorf("Expected %q, got %q", want, buf.String())
+	}
+}
+
+// recordingHandler keeps the records it's given
+type recordingHandler struct {
+	records []slog.Record
+}
+
+func (h *recordingHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *recordingHandler) Handle(_ context.Context, record slog.Record) error {
+	h.records = append(h.records, record)
+	return nil
+}
+
+func (h *recordingHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h *recordingHandler) WithGroup(string) slog.Handler { return h }
+
+func TestNewSlogLogger_CustomHandler(t *testing.T) {
+	handler := &recordingHandler{}
+	logger := NewSlogLogger(handler, LoggerOptions{Level: "WARN"})
+	logger.Info("hidden")
+	WithItem(logger, ItemFields{Service: "sonarr", SeriesID: 12}).Error("Failed: %d", 3)
+
+	if len(handler.records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(handler.records))
+	}
+	record := handler.records[0]
+	if record.Level != slog.LevelError || record.Message != "Failed: 3" {
+		t.Errorf("Unexpected record: %v %q", record.Level, record.Message)
+	}
+	attrs := make(map[string]string)
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs[attr.Key] = attr.Value.String()
+		return true
+	})
+	if len(attrs) != 2 || attrs["service"] != "sonarr" || attrs["seriesId"] != "12" {
+		t.Errorf("Expected the item fields as attributes, got %v", attrs)
+	}
+}
+
+func TestWithItem_StandardLogger(t *testing.T) {
+	logger := NewStandardLogger("INFO")
+	if WithItem(logger, ItemFields{MovieID: 1}) != logger {
+		t.Error("Expected WithItem to return a logger without item support unchanged")
+	}
+}
+
 func TestStandardLogger_LogLevels(t *testing.T) {
 	// Capture log output
 	var buf bytes.Buffer
@@ -0,0 +1,282 @@
+package arr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+// MediaCache keeps a service's library, the titles, TVDB/TMDB IDs and file counts of its series
+// or movies, on disk between runs. While it is fresh, runs use it instead of fetching the whole library,
+// and the broken symlink handler checks it before asking whether an item is in the collection.
+type MediaCache struct {
+	path string
+	ttl  time.Duration
+
+	mu     sync.RWMutex
+	data   mediaCacheData
+	saveMu sync.Mutex // Serializes writes of the cache file
+}
+
+// mediaCacheData is the cache file's content
+type mediaCacheData struct {
+	FetchedAt time.Time           `json:"fetchedAt"`
+	Series    map[int]cachedMedia `json:"series,omitempty"` // series ID -> series
+	Movies    map[int]cachedMedia `json:"movies,omitempty"` // movie ID -> movie
+}
+
+// cachedMedia is what the cache keeps of a series or movie
+type cachedMedia struct {
+	Title      string `json:"title"`
+	ExternalID int    `json:"externalId,omitempty"` // TVDB ID of a series, TMDB ID of a movie
+	Files      *int   `json:"files,omitempty"`      // Episode files of a series, 1 or 0 for a movie; nil when unknown
+}
+
+// MediaCachePath returns the location of a service's media cache inside the state directory
+func MediaCachePath(stateDir, service string) string {
+	return filepath.Join(stateDir, fmt.Sprintf("media-cache-%s.json", service))
+}
+
+// NewMediaCache creates an empty cache saved to path. Its content is used for ttl after the
+// library was fetched.
+func NewMediaCache(path string, ttl time.Duration) *MediaCache {
+	return &MediaCache{path: path, ttl: ttl}
+}
+
+// LoadMediaCache loads the cache saved at path. A missing or expired file gives an empty cache.
+func LoadMediaCache(path string, ttl time.Duration) (*MediaCache, error) {
+	cache := NewMediaCache(path, ttl)
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cache, nil
+	}
+	if err != nil {
+		return cache, fmt.Errorf("failed to read media cache: %w", err)
+	}
+	if err := json.Unmarshal(data, &cache.data); err != nil {
+		cache.data = mediaCacheData{}
+		return cache, fmt.Errorf("failed to parse media cache %s: %w", path, err)
+	}
+	if !cache.freshLocked() {
+		cache.data = mediaCacheData{}
+	}
+	return cache, nil
+}
+
+// freshLocked reports whether the library was fetched within the TTL. Caller must hold c.mu.
+func (c *MediaCache) freshLocked() bool {
+	return !c.data.FetchedAt.IsZero() && time.Since(c.data.FetchedAt) < c.ttl
+}
+
+// Age returns how long ago the cached library was fetched
+func (c *MediaCache) Age() time.Duration {
+	if c == nil {
+		return 0
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return time.Since(c.data.FetchedAt)
+}
+
+// Series returns the cached series, ordered by ID, and false when there is no fresh copy
+func (c *MediaCache) Series() ([]models.Series, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if !c.freshLocked() || c.data.Series == nil {
+		return nil, false
+	}
+
+	series := make([]models.Series, 0, len(c.data.Series))
+	for id, item := range c.data.Series {
+		series = append(series, models.Series{
+			MediaItem:        models.MediaItem{ID: id, Title: item.Title},
+			TVDBID:           item.ExternalID,
+			EpisodeFileCount: item.Files,
+		})
+	}
+	sort.Slice(series, func(i, j int) bool { return series[i].ID < series[j].ID })
+	return series, true
+}
+
+// Movies returns the cached movies, ordered by ID, and false when there is no fresh copy
+func (c *MediaCache) Movies() ([]models.Movie, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if !c.freshLocked() || c.data.Movies == nil {
+		return nil, false
+	}
+
+	movies := make([]models.Movie, 0, len(c.data.Movies))
+	for id, item := range c.data.Movies {
+		movies = append(movies, models.Movie{
+			MediaItem: models.MediaItem{ID: id, Title: item.Title},
+			TMDBID:    item.ExternalID,
+			HasFile:   item.Files == nil || *item.Files > 0,
+		})
+	}
+	sort.Slice(movies, func(i, j int) bool { return movies[i].ID < movies[j].ID })
+	return movies, true
+}
+
+// SetSeries replaces the cache with a freshly fetched list of every series
+func (c *MediaCache) SetSeries(series []models.Series) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data = mediaCacheData{FetchedAt: time.Now(), Series: make(map[int]cachedMedia, len(series))}
+	for _, s := range series {
+		c.data.Series[s.ID] = cachedSeries(s)
+	}
+}
+
+// SetMovies replaces the cache with a freshly fetched list of every movie
+func (c *MediaCache) SetMovies(movies []models.Movie) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data = mediaCacheData{FetchedAt: time.Now(), Movies: make(map[int]cachedMedia, len(movies))}
+	for _, m := range movies {
+		c.data.Movies[m.ID] = cachedMovie(m)
+	}
+}
+
+// AddSeries records a series added to the collection, if the cache holds a fresh library
+func (c *MediaCache) AddSeries(series models.Series) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.freshLocked() && c.data.Series != nil {
+		c.data.Series[series.ID] = cachedSeries(series)
+	}
+}
+
+// AddMovie records a movie added to the collection, if the cache holds a fresh library
+func (c *MediaCache) AddMovie(movie models.Movie) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.freshLocked() && c.data.Movies != nil {
+		c.data.Movies[movie.ID] = cachedMovie(movie)
+	}
+}
+
+// cachedSeries is what the cache keeps of a series
+func cachedSeries(s models.Series) cachedMedia {
+	return cachedMedia{Title: s.Title, ExternalID: s.TVDBID, Files: s.EpisodeFileCount}
+}
+
+// cachedMovie is what the cache keeps of a movie
+func cachedMovie(m models.Movie) cachedMedia {
+	files := 0
+	if m.HasFile {
+		files = 1
+	}
+	return cachedMedia{Title: m.Title, ExternalID: m.TMDBID, Files: &files}
+}
+
+// Title returns the cached title of a series or movie
+func (c *MediaCache) Title(id int) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if !c.freshLocked() {
+		return "", false
+	}
+	if item, ok := c.data.Series[id]; ok {
+		return item.Title, true
+	}
+	if item, ok := c.data.Movies[id]; ok {
+		return item.Title, true
+	}
+	return "", false
+}
+
+// SeriesByTVDBID returns the cached series with the TVDB ID. A miss doesn't mean the series
+// isn't in the collection, as it may have been added since the library was fetched.
+func (c *MediaCache) SeriesByTVDBID(tvdbID int) (*models.Series, bool) {
+	id, title, ok := c.byExternalID(tvdbID, func(d *mediaCacheData) map[int]cachedMedia { return d.Series })
+	if !ok {
+		return nil, false
+	}
+	return &models.Series{MediaItem: models.MediaItem{ID: id, Title: title}, TVDBID: tvdbID}, true
+}
+
+// MovieByTMDBID returns the cached movie with the TMDB ID. A miss doesn't mean the movie
+// isn't in the collection, as it may have been added since the library was fetched.
+func (c *MediaCache) MovieByTMDBID(tmdbID int) (*models.Movie, bool) {
+	id, title, ok := c.byExternalID(tmdbID, func(d *mediaCacheData) map[int]cachedMedia { return d.Movies })
+	if !ok {
+		return nil, false
+	}
+	return &models.Movie{MediaItem: models.MediaItem{ID: id, Title: title}, TMDBID: tmdbID}, true
+}
+
+// byExternalID finds the item with the TVDB or TMDB ID in the map items selects
+func (c *MediaCache) byExternalID(externalID int, items func(*mediaCacheData) map[int]cachedMedia) (int, string, bool) {
+	if c == nil || externalID <= 0 {
+		return 0, "", false
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if !c.freshLocked() {
+		return 0, "", false
+	}
+	for id, item := range items(&c.data) {
+		if item.ExternalID == externalID {
+			return id, item.Title, true
+		}
+	}
+	return 0, "", false
+}
+
+// Save writes the cache to disk atomically
+func (c *MediaCache) Save() error {
+	if c == nil {
+		return nil
+	}
+	c.saveMu.Lock()
+	defer c.saveMu.Unlock()
+
+	c.mu.RLock()
+	data, err := json.Marshal(c.data)
+	c.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal media cache: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("failed to create media cache directory: %w", err)
+	}
+	tmpPath := c.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write media cache: %w", err)
+	}
+	if err := os.Rename(tmpPath, c.path); err != nil {
+		return fmt.Errorf("failed to replace media cache file: %w", err)
+	}
+	return nil
+}
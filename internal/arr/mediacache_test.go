@@ -0,0 +1,131 @@
+package arr
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+func TestMediaCache_SaveAndLoad(t *testing.T) {
+	path := MediaCachePath(filepath.Join(t.TempDir(), "state"), "sonarr")
+
+	cache := NewMediaCache(path, time.Hour)
+	cache.SetSeries([]models.Series{
+		{MediaItem: models.MediaItem{ID: 2, Title: "Two"}, TVDBID: 200},
+		{MediaItem: models.MediaItem{ID: 1, Title: "One"}, TVDBID: 100, EpisodeFileCount: intPtr(0)},
+	})
+	cache.AddSeries(models.Series{MediaItem: models.MediaItem{ID: 3, Title: "Three"}, TVDBID: 300})
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := LoadMediaCache(path, time.Hour)
+	if err != nil {
+		t.Fatalf("LoadMediaCache() error = %v", err)
+	}
+	series, ok := loaded.Series()
+	if !ok || len(series) != 3 || series[0].ID != 1 || series[0].Title != "One" || series[0].TVDBID != 100 {
+		t.Fatalf("Expected the 3 saved series ordered by ID, got %+v (ok=%v)", series, ok)
+	}
+	if series[0].EpisodeFileCount == nil || *series[0].EpisodeFileCount != 0 || series[1].EpisodeFileCount != nil {
+		t.Errorf("Expected the file counts to be kept, unknown ones as nil, got %+v", series)
+	}
+	if found, ok := loaded.SeriesByTVDBID(300); !ok || found.ID != 3 || found.Title != "Three" {
+		t.Errorf("Expected TVDB ID 300 to be series 3, got %+v (ok=%v)", found, ok)
+	}
+	if _, ok := loaded.SeriesByTVDBID(400); ok {
+		t.Error("Expected no series for an unknown TVDB ID")
+	}
+	if _, ok := loaded.Movies(); ok {
+		t.Error("Expected no cached movies in a Sonarr cache")
+	}
+
+	// An expired cache is loaded empty
+	expired, err := LoadMediaCache(path, time.Nanosecond)
+	if err != nil {
+		t.Fatalf("LoadMediaCache() error = %v", err)
+	}
+	if _, ok := expired.Series(); ok {
+		t.Error("Expected an expired cache to be ignored")
+	}
+}
+
+func TestLoadMediaCache_MissingAndCorrupt(t *testing.T) {
+	dir := t.TempDir()
+
+	cache, err := LoadMediaCache(filepath.Join(dir, "missing.json"), time.Hour)
+	if err != nil {
+		t.Fatalf("Expected a missing cache file to be fine, got %v", err)
+	}
+	if _, ok := cache.Movies(); ok {
+		t.Error("Expected an empty cache")
+	}
+
+	corrupt := filepath.Join(dir, "corrupt.json")
+	if err := os.WriteFile(corrupt, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cache, err = LoadMediaCache(corrupt, time.Hour)
+	if err == nil {
+		t.Error("Expected an error for a corrupt cache file")
+	}
+	if cache == nil {
+		t.Fatal("Expected an empty cache to use despite the error")
+	}
+	if _, ok := cache.Movies(); ok {
+		t.Error("Expected an empty cache")
+	}
+}
+
+func TestCleanupService_UsesMediaCache(t *testing.T) {
+	path := MediaCachePath(t.TempDir(), "sonarr")
+	cache := NewMediaCache(path, time.Hour)
+	cache.SetSeries([]models.Series{{MediaItem: models.MediaItem{ID: 1, Title: "Cached Series"}, TVDBID: 100}})
+
+	// Fetching the library would fail, so the run has to use the cache
+	client := &mockClient{
+		name:           "sonarr",
+		allSeriesError: errors.New("library fetch not expected"),
+		episodes:       map[int][]models.Episode{1: {}},
+	}
+	logger := &mockLogger{}
+	service := NewCleanupServiceWithOptions(client, &mockFileChecker{}, logger, &mockProgressReporter{}, CleanupOptions{
+		ConcurrentLimit: 1,
+		DryRun:          true,
+		MediaCache:      cache,
+	})
+
+	if _, err := service.CleanupMissingFiles(context.Background()); err != nil {
+		t.Fatalf("CleanupMissingFiles() error = %v", err)
+	}
+	if name := service.(*CleanupServiceImpl).getSeriesInfo(1); name != "Cached Series" {
+		t.Errorf("Expected the cached title, got %q", name)
+	}
+
+	// Without a fresh cache the library is fetched and saved for the next run
+	client = &mockClient{
+		name:      "sonarr",
+		allSeries: []models.Series{{MediaItem: models.MediaItem{ID: 5, Title: "Fetched Series"}, TVDBID: 500}},
+		episodes:  map[int][]models.Episode{5: {}},
+	}
+	service = NewCleanupServiceWithOptions(client, &mockFileChecker{}, logger, &mockProgressReporter{}, CleanupOptions{
+		ConcurrentLimit: 1,
+		DryRun:          true,
+		MediaCache:      NewMediaCache(path, time.Hour),
+	})
+	if _, err := service.CleanupMissingFiles(context.Background()); err != nil {
+		t.Fatalf("CleanupMissingFiles() error = %v", err)
+	}
+	saved, err := LoadMediaCache(path, time.Hour)
+	if err != nil {
+		t.Fatalf("LoadMediaCache() error = %v", err)
+	}
+	if found, ok := saved.SeriesByTVDBID(500); !ok || found.Title != "Fetched Series" {
+		t.Errorf("Expected the fetched library to be saved, got %+v (ok=%v)", found, ok)
+	}
+}
@@ -0,0 +1,110 @@
+package arr
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+// MigrateOptions holds the settings of a path migration
+type MigrateOptions struct {
+	Mappings []models.PathMapping
+	DryRun   bool
+	// FolderExists checks that a series or movie's new folder is there before it is moved
+	// (nil skips the check)
+	FolderExists func(path string) bool
+}
+
+// MigratePaths points every series (Sonarr) or movie (Radarr) whose folder a mapping matches at
+// the mapped folder, without moving any files. The service's file records follow their folder,
+// so this rewrites the file paths after storage was migrated outside the service.
+func MigratePaths(ctx context.Context, client Client, opts MigrateOptions, logger Logger) (*models.PathMigrationReport, error) {
+	updater, ok := client.(PathUpdater)
+	if !ok {
+		return nil, fmt.Errorf("%s client can't update paths", client.GetName())
+	}
+
+	runType := "real-run"
+	if opts.DryRun {
+		runType = "dry-run"
+	}
+	report := &models.PathMigrationReport{
+		GeneratedAt: time.Now().Format(time.RFC3339),
+		RunType:     runType,
+		ServiceType: client.GetName(),
+		Mappings:    opts.Mappings,
+		Items:       []models.PathMigrationEntry{},
+	}
+
+	var items []models.MediaItem
+	mediaType := "movie"
+	if client.GetName() == "sonarr" {
+		mediaType = "series"
+		series, err := client.GetAllSeries(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get series: %w", err)
+		}
+		for _, show := range series {
+			items = append(items, show.MediaItem)
+		}
+	} else {
+		movies, err := client.GetAllMovies(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get movies: %w", err)
+		}
+		for _, movie := range movies {
+			items = append(items, movie.MediaItem)
+		}
+	}
+	report.Checked = len(items)
+
+	// New folders outside every root folder work, but the service will flag them
+	rootFolders, err := client.GetRootFolders(ctx)
+	if err != nil {
+		logger.Warn("⚠️  Failed to fetch root folders, new folders won't be checked against them: %s", err.Error())
+	}
+
+	for _, item := range items {
+		if ctx.Err() != nil {
+			return report, ctx.Err()
+		}
+		newPath, ok := models.MapPath(opts.Mappings, item.Path)
+		if !ok || newPath == item.Path {
+			continue
+		}
+
+		entry := models.PathMigrationEntry{
+			MediaType: mediaType,
+			MediaName: item.Title,
+			ID:        item.ID,
+			FromPath:  item.Path,
+			ToPath:    newPath,
+		}
+		if rootFolders != nil && !withinRootFolders(newPath, rootFolders) {
+			entry.Note = "not under any root folder"
+		}
+
+		switch {
+		case opts.FolderExists != nil && !opts.FolderExists(newPath):
+			logger.Warn("⚠️  Skipping %s: %s does not exist", item.Title, newPath)
+			entry.Outcome = models.MigrationTargetMissing
+		case opts.DryRun:
+			logger.Info("🏃 DRY RUN: Would move %s from %s to %s", item.Title, item.Path, newPath)
+			entry.Outcome = models.MigrationPlanned
+		default:
+			logger.Info("📁 Moving %s from %s to %s...", item.Title, item.Path, newPath)
+			if err := updater.UpdateMediaPath(ctx, item.ID, newPath); err != nil {
+				logger.Error("❌ Failed to update the path of %s: %s", item.Title, err.Error())
+				entry.Outcome = models.MigrationFailed
+				entry.Note = err.Error()
+			} else {
+				entry.Outcome = models.MigrationMoved
+			}
+		}
+		report.Items = append(report.Items, entry)
+	}
+
+	return report, nil
+}
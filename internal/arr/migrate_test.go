@@ -0,0 +1,84 @@
+package arr
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+func newMigrationClient() *SimulatedClient {
+	return NewSimulatedClient("radarr", SimulationFixture{
+		Movies: []models.Movie{
+			{MediaItem: models.MediaItem{ID: 1, Title: "Foo", Path: "/mnt/old/movies/Foo (2020)"}},
+			{MediaItem: models.MediaItem{ID: 2, Title: "Bar", Path: "/mnt/old/movies/Bar (2021)"}},
+			{MediaItem: models.MediaItem{ID: 3, Title: "Baz", Path: "/data/movies/Baz (2022)"}},
+		},
+		MovieFiles: []models.MovieFile{
+			{ID: 10, MovieID: 1, Path: "/mnt/old/movies/Foo (2020)/Foo.mkv"},
+		},
+		RootFolders: []models.RootFolder{{ID: 1, Path: "/data/movies"}},
+	}, 0, &mockLogger{})
+}
+
+func TestMigratePaths(t *testing.T) {
+	client := newMigrationClient()
+	opts := MigrateOptions{
+		Mappings: []models.PathMapping{{From: "/mnt/old/movies", To: "/data/movies"}},
+		FolderExists: func(path string) bool {
+			return path != "/data/movies/Bar (2021)"
+		},
+	}
+
+	report, err := MigratePaths(context.Background(), client, opts, &mockLogger{})
+	if err != nil {
+		t.Fatalf("MigratePaths() failed: %v", err)
+	}
+	if report.Checked != 3 || len(report.Items) != 2 {
+		t.Fatalf("Expected 2 of 3 movies to match, got %+v", report)
+	}
+	if report.Count(models.MigrationMoved) != 1 || report.Count(models.MigrationTargetMissing) != 1 {
+		t.Errorf("Expected one moved and one skipped movie, got %+v", report.Items)
+	}
+	if item := report.Items[0]; item.ID != 1 || item.ToPath != "/data/movies/Foo (2020)" || item.Note != "" {
+		t.Errorf("Unexpected entry %+v", item)
+	}
+
+	if path, _ := client.MediaPath(context.Background(), 1); path != "/data/movies/Foo (2020)" {
+		t.Errorf("Expected the movie to be moved, got %s", path)
+	}
+	if file, _ := client.GetMovieFile(context.Background(), 10); file.Path != "/data/movies/Foo (2020)/Foo.mkv" {
+		t.Errorf("Expected the file record to follow the movie, got %s", file.Path)
+	}
+	if path, _ := client.MediaPath(context.Background(), 2); path != "/mnt/old/movies/Bar (2021)" {
+		t.Errorf("Expected the movie without a new folder to stay, got %s", path)
+	}
+}
+
+func TestMigratePaths_DryRun(t *testing.T) {
+	client := newMigrationClient()
+	opts := MigrateOptions{
+		Mappings: []models.PathMapping{{From: "/mnt/old", To: "/srv"}},
+		DryRun:   true,
+	}
+
+	report, err := MigratePaths(context.Background(), client, opts, &mockLogger{})
+	if err != nil {
+		t.Fatalf("MigratePaths() failed: %v", err)
+	}
+	if report.RunType != "dry-run" || report.Count(models.MigrationPlanned) != 2 {
+		t.Errorf("Expected 2 planned moves, got %+v", report)
+	}
+	if report.Items[0].Note != "not under any root folder" {
+		t.Errorf("Expected a warning about the root folders, got %+v", report.Items[0])
+	}
+	if path, _ := client.MediaPath(context.Background(), 1); path != "/mnt/old/movies/Foo (2020)" {
+		t.Errorf("Expected a dry run to change nothing, got %s", path)
+	}
+}
+
+func TestMigratePaths_UnsupportedClient(t *testing.T) {
+	if _, err := MigratePaths(context.Background(), &mockClient{name: "sonarr"}, MigrateOptions{}, &mockLogger{}); err == nil {
+		t.Error("Expected error for a client that can't update paths")
+	}
+}
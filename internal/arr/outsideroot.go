@@ -0,0 +1,191 @@
+package arr
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hnipps/refresharr/internal/config"
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+// outsideRootFolders reports whether path is under none of the service's root folders. The
+// root folders are fetched once per run; when they can't be, no record counts as outside.
+func (s *CleanupServiceImpl) outsideRootFolders(ctx context.Context, path string) bool {
+	s.rootFoldersOnce.Do(func() {
+		folders, err := s.client.GetRootFolders(ctx)
+		if err != nil {
+			s.logger.Warn("⚠️  Failed to fetch root folders, records outside them won't be flagged: %s", err.Error())
+			return
+		}
+		s.rootFolders = folders
+	})
+
+	if path == "" || len(s.rootFolders) == 0 {
+		return false
+	}
+	return !withinRootFolders(path, s.rootFolders)
+}
+
+// withinRootFolders reports whether path is inside one of the root folders
+func withinRootFolders(path string, rootFolders []models.RootFolder) bool {
+	for _, folder := range rootFolders {
+		if pathWithin(path, folder.Path) {
+			return true
+		}
+	}
+	return false
+}
+
+// handleOutsideRoot applies the outside root folder policy to a file record whose path is under
+// none of the root folders, and records it for the report. It returns false when the record is
+// to be handled like a missing file (delete policy) and true when the caller is done with it.
+// entry describes the record; mediaID is the ID of its series or movie.
+func (s *CleanupServiceImpl) handleOutsideRoot(ctx context.Context, logger Logger, entry models.OutsideRootEntry, mediaID int, stats *models.CleanupStats) bool {
+	stats.OutsideRoot++
+	entry.Policy = s.outsideRootPolicy
+	if entry.Policy == "" {
+		entry.Policy = config.OutsideRootSkip
+	}
+
+	done := true
+	switch entry.Policy {
+	case config.OutsideRootDelete:
+		logger.Warn("    ⚠️  %s is outside every root folder, treating the record as broken", entry.FilePath)
+		entry.Outcome = models.OutsideDeleted
+		done = false
+	case config.OutsideRootRewrite:
+		if entry.MediaType == "book" {
+			// Readarr has no way to point a book at another folder, so books are only reported
+			logger.Warn("    ⚠️  %s is outside every root folder; Readarr books can't be moved, so it's only reported", entry.FilePath)
+			entry.Outcome = models.OutsideReported
+			stats.Warnings++
+			break
+		}
+		entry.MappedPath, entry.Outcome = s.rewriteOutsideRoot(ctx, logger, entry, mediaID, stats)
+	default:
+		logger.Warn("    ⚠️  %s is outside every root folder", entry.FilePath)
+		entry.Outcome = models.OutsideReported
+		stats.Warnings++
+	}
+
+	s.outsideRootMu.Lock()
+	s.outsideRoot = append(s.outsideRoot, entry)
+	s.outsideRootMu.Unlock()
+	return done
+}
+
+// rewriteOutsideRoot moves the series or movie of a record outside the root folders to where
+// the path mapping rules put it, once the mapped file is confirmed to exist. It returns the
+// mapped file path and the outcome.
+func (s *CleanupServiceImpl) rewriteOutsideRoot(ctx context.Context, logger Logger, entry models.OutsideRootEntry, mediaID int, stats *models.CleanupStats) (string, string) {
+	mapped, ok := models.MapPath(s.pathMappings, entry.FilePath)
+	if !ok {
+		logger.Warn("    ⚠️  %s is outside every root folder and no path mapping matches it", entry.FilePath)
+		stats.Warnings++
+		return "", models.OutsideUnmapped
+	}
+	if !s.fileChecker.FileExists(mapped) {
+		logger.Warn("    ⚠️  %s is outside every root folder and nothing exists at the mapped path %s", entry.FilePath, mapped)
+		stats.Warnings++
+		return mapped, models.OutsideUnmapped
+	}
+
+	outcome := s.updateMediaPath(ctx, logger, entry.MediaType, entry.MediaName, mediaID, stats)
+	if outcome == models.OutsideUnmapped {
+		stats.Warnings++
+	}
+	return mapped, outcome
+}
+
+// updateMediaPath applies the path mapping rules to the folder of a series or movie. Each item
+// is updated once per run, however many of its files are outside the root folders; later
+// calls return the first call's outcome. Errors and skips are counted by the first call.
+func (s *CleanupServiceImpl) updateMediaPath(ctx context.Context, logger Logger, mediaType, mediaName string, mediaID int, stats *models.CleanupStats) string {
+	key := fmt.Sprintf("%s-%d", mediaType, mediaID)
+
+	// Held across the update so concurrent episodes of a series wait for its outcome
+	s.pathUpdatesMu.Lock()
+	defer s.pathUpdatesMu.Unlock()
+	if outcome, ok := s.pathUpdates[key]; ok {
+		return outcome
+	}
+	if s.pathUpdates == nil {
+		s.pathUpdates = make(map[string]string)
+	}
+
+	outcome := s.applyPathMapping(ctx, logger, mediaType, mediaName, mediaID, stats)
+	s.pathUpdates[key] = outcome
+	return outcome
+}
+
+// applyPathMapping does the work of updateMediaPath
+func (s *CleanupServiceImpl) applyPathMapping(ctx context.Context, logger Logger, mediaType, mediaName string, mediaID int, stats *models.CleanupStats) string {
+	updater, ok := s.client.(PathUpdater)
+	if !ok {
+		logger.Error("    ❌ %s can't update the path of %s", s.client.GetName(), mediaName)
+		stats.Errors++
+		return models.OutsideFailed
+	}
+
+	current, err := updater.MediaPath(ctx, mediaID)
+	if err != nil {
+		logger.Error("    ❌ Failed to get the path of %s: %s", mediaName, err.Error())
+		countFailure(stats, err)
+		return models.OutsideFailed
+	}
+	newPath, ok := models.MapPath(s.pathMappings, current)
+	if !ok {
+		logger.Warn("    ⚠️  No path mapping matches the folder of %s (%s)", mediaName, current)
+		return models.OutsideUnmapped
+	}
+
+	action := models.PlannedAction{
+		Action:    models.ActionUpdatePath,
+		MediaType: mediaType,
+		MediaName: mediaName,
+		Path:      newPath,
+		FromPath:  current,
+	}
+	if mediaType == "series" {
+		action.SeriesID = mediaID
+	} else {
+		action.MovieID = mediaID
+	}
+	if !s.scope.Allows(action) {
+		logger.Info("    ⏭️  Skipping path update of %s: not listed in %s", mediaName, s.scope.Source)
+		stats.Skipped++
+		return models.OutsideReported
+	}
+
+	if s.reportOnly() {
+		logger.Info("    🏃 DRY RUN: Would move %s from %s to %s", mediaName, current, newPath)
+		s.planCall(logger, &action, map[string]string{"path": newPath})
+		s.addPlannedAction(action)
+		return models.OutsidePlanned
+	}
+
+	logger.Info("    📁 Moving %s from %s to %s...", mediaName, current, newPath)
+	if err := updater.UpdateMediaPath(ctx, mediaID, newPath); err != nil {
+		logger.Error("    ❌ Failed to update the path of %s: %s", mediaName, err.Error())
+		s.progressReporter.ReportError(err)
+		stats.Errors++
+		return models.OutsideFailed
+	}
+	return models.OutsideRewritten
+}
+
+// outsideRootEntries returns the records found outside the root folders, sorted by name and path
+func (s *CleanupServiceImpl) outsideRootEntries() []models.OutsideRootEntry {
+	s.outsideRootMu.Lock()
+	defer s.outsideRootMu.Unlock()
+
+	entries := append([]models.OutsideRootEntry(nil), s.outsideRoot...)
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].MediaName != entries[j].MediaName {
+			return entries[i].MediaName < entries[j].MediaName
+		}
+		return entries[i].FilePath < entries[j].FilePath
+	})
+	return entries
+}
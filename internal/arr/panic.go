@@ -0,0 +1,46 @@
+package arr
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is a panic recovered in the cleanup pipeline, with the stack of the goroutine that
+// panicked. The run it happened in fails with it instead of taking the process down.
+type PanicError struct {
+	Value interface{}
+	Stack string
+}
+
+// NewPanicError wraps a recovered value with the current stack. Called from a deferred function
+// while panicking, the stack still shows where the panic happened. A value that already is a
+// *PanicError, re-raised from a worker, is returned as is to keep the worker's stack.
+func NewPanicError(recovered interface{}) *PanicError {
+	if panicErr, ok := recovered.(*PanicError); ok {
+		return panicErr
+	}
+	return &PanicError{Value: recovered, Stack: string(debug.Stack())}
+}
+
+// Error describes the panic
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// recoverWorker is deferred by worker goroutines to hand a panic to report as a *PanicError
+func recoverWorker(report func(err error)) {
+	if recovered := recover(); recovered != nil {
+		report(NewPanicError(recovered))
+	}
+}
+
+// recoverPanic is deferred by the cleanup entry points to return a panic, their own or one a
+// worker re-raised, as a *PanicError, so the run fails instead of the process. Series and movie
+// workers' panics don't get here: those end the run with the stats gathered so far.
+func (s *CleanupServiceImpl) recoverPanic(err *error) {
+	if recovered := recover(); recovered != nil {
+		panicErr := NewPanicError(recovered)
+		s.logger.Error("💥 %s cleanup %s", s.client.GetName(), panicErr.Error())
+		*err = panicErr
+	}
+}
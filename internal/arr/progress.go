@@ -68,6 +68,24 @@ func (r *ConsoleProgressReporter) Finish(stats models.CleanupStats) {
 	r.logger.Info("  Total items checked: %d", stats.TotalItemsChecked)
 	r.logger.Info("  Missing files found: %d", stats.MissingFiles)
 	r.logger.Info("  Records deleted: %d", stats.DeletedRecords)
+	if stats.Skipped > 0 {
+		r.logger.Info("  Skipped (not in --only-from): %d", stats.Skipped)
+	}
+	if stats.SizeMismatches > 0 {
+		r.logger.Warn("  Size mismatches: %d", stats.SizeMismatches)
+	}
+	if stats.Corrupt > 0 {
+		r.logger.Warn("  Corrupt files (checksum mismatch): %d", stats.Corrupt)
+	}
+	if stats.EmptyPaths > 0 {
+		r.logger.Warn("  Records without a file path: %d", stats.EmptyPaths)
+	}
+	if stats.OutsideRoot > 0 {
+		r.logger.Warn("  Records outside the root folders: %d", stats.OutsideRoot)
+	}
+	if stats.Warnings > 0 {
+		r.logger.Warn("  Warnings (skipped or transient errors): %d", stats.Warnings)
+	}
 	if stats.Errors > 0 {
 		r.logger.Warn("  Errors encountered: %d", stats.Errors)
 	}
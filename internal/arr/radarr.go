@@ -7,7 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"strings"
+	"net/url"
 	"time"
 
 	"github.com/hnipps/refresharr/internal/config"
@@ -19,21 +19,43 @@ type RadarrClient struct {
 	baseURL    string
 	apiKey     string
 	httpClient *http.Client
+	calls      *callCounter
 	logger     Logger
+	caps       *Capabilities // Optional features the server supports, probed on connect
 }
 
 // NewRadarrClient creates a new Radarr client
 func NewRadarrClient(cfg *config.RadarrConfig, timeout time.Duration, logger Logger) Client {
+	return NewRadarrClientWithTransport(cfg, timeout, nil, logger)
+}
+
+// NewRadarrClientWithTransport creates a new Radarr client whose requests go through wrap
+func NewRadarrClientWithTransport(cfg *config.RadarrConfig, timeout time.Duration, wrap TransportWrapper, logger Logger) Client {
+	baseURL, endpoint := ResolveEndpoint(cfg.URL)
+	calls := newClientTransport(wrap, endpoint, cfg.APIKey, logger)
 	return &RadarrClient{
-		baseURL: strings.TrimRight(cfg.URL, "/"),
+		baseURL: baseURL,
 		apiKey:  cfg.APIKey,
 		httpClient: &http.Client{
-			Timeout: timeout,
+			Timeout:   timeout,
+			Transport: calls,
 		},
+		calls:  calls,
 		logger: logger,
+		caps:   newCapabilities("Radarr"),
 	}
 }
 
+// APICalls returns the number of requests sent to Radarr
+func (c *RadarrClient) APICalls() int64 {
+	return c.calls.calls.Load()
+}
+
+// Capabilities returns the optional features the server supports
+func (c *RadarrClient) Capabilities() *Capabilities {
+	return c.caps
+}
+
 // GetName returns the service name
 func (c *RadarrClient) GetName() string {
 	return "radarr"
@@ -48,8 +70,16 @@ func (c *RadarrClient) TestConnection(ctx context.Context) error {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("Radarr returned status %d", resp.StatusCode)
+		return fmt.Errorf("Radarr returned %w", responseError(resp))
+	}
+
+	var status struct {
+		Version string `json:"version"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		c.logger.Debug("Could not read Radarr version: %s", err.Error())
 	}
+	probeRadarrCapabilities(c.caps, status.Version, c.logger)
 
 	c.logger.Info("✅ Successfully connected to Radarr")
 	return nil
@@ -69,7 +99,7 @@ func (c *RadarrClient) GetAllMovies(ctx context.Context) ([]models.Movie, error)
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch movies, status: %d", resp.StatusCode)
+		return nil, fmt.Errorf("failed to fetch movies, %w", responseError(resp))
 	}
 
 	var movies []models.Movie
@@ -91,11 +121,11 @@ func (c *RadarrClient) GetMovie(ctx context.Context, movieID int) (*models.Movie
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("movie %d not found", movieID)
+		return nil, notFoundError("movie %d not found", movieID)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch movie %d, status: %d", movieID, resp.StatusCode)
+		return nil, fmt.Errorf("failed to fetch movie %d, %w", movieID, responseError(resp))
 	}
 
 	var movie models.Movie
@@ -107,6 +137,11 @@ func (c *RadarrClient) GetMovie(ctx context.Context, movieID int) (*models.Movie
 	return &movie, nil
 }
 
+// GetEpisode is not applicable for Radarr (returns error)
+func (c *RadarrClient) GetEpisode(ctx context.Context, episodeID int) (*models.Episode, error) {
+	return nil, fmt.Errorf("GetEpisode is not supported by Radarr client")
+}
+
 // GetEpisodesForSeries is not applicable for Radarr (returns error)
 func (c *RadarrClient) GetEpisodesForSeries(ctx context.Context, seriesID int) ([]models.Episode, error) {
 	return nil, fmt.Errorf("GetEpisodesForSeries is not supported by Radarr client")
@@ -137,11 +172,11 @@ func (c *RadarrClient) GetMovieFile(ctx context.Context, fileID int) (*models.Mo
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("movie file %d not found", fileID)
+		return nil, notFoundError("movie file %d not found", fileID)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch movie file %d, status: %d", fileID, resp.StatusCode)
+		return nil, fmt.Errorf("failed to fetch movie file %d, %w", fileID, responseError(resp))
 	}
 
 	var movieFile models.MovieFile
@@ -162,7 +197,7 @@ func (c *RadarrClient) DeleteMovieFile(ctx context.Context, fileID int) error {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
-		return fmt.Errorf("failed to delete movie file %d, status: %d", fileID, resp.StatusCode)
+		return fmt.Errorf("failed to delete movie file %d, %w", fileID, responseError(resp))
 	}
 
 	c.logger.Debug("Successfully deleted movie file %d", fileID)
@@ -180,7 +215,7 @@ func (c *RadarrClient) UpdateMovie(ctx context.Context, movie models.Movie) erro
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to fetch current movie %d data, status: %d", movie.ID, resp.StatusCode)
+		return fmt.Errorf("failed to fetch current movie %d data, %w", movie.ID, responseError(resp))
 	}
 
 	var currentMovie models.Movie
@@ -205,16 +240,189 @@ func (c *RadarrClient) UpdateMovie(ctx context.Context, movie models.Movie) erro
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		// Get response body for better error reporting
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("failed to update movie %d, status: %d, response: %s", movie.ID, resp.StatusCode, string(bodyBytes))
+	// Radarr answers 202 Accepted to movie updates
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("failed to update movie %d, %w", movie.ID, responseError(resp))
 	}
 
 	c.logger.Debug("Successfully updated movie %d", movie.ID)
 	return nil
 }
 
+// MediaPath returns the folder of a movie
+func (c *RadarrClient) MediaPath(ctx context.Context, movieID int) (string, error) {
+	movie, err := c.fetchMovieFields(ctx, movieID)
+	if err != nil {
+		return "", err
+	}
+	path, _ := movie["path"].(string)
+	return path, nil
+}
+
+// UpdateMediaPath points a movie at another folder without moving its file. The movie is sent
+// back as Radarr returned it, so fields models.Movie doesn't have survive the update.
+func (c *RadarrClient) UpdateMediaPath(ctx context.Context, movieID int, path string) error {
+	movie, err := c.fetchMovieFields(ctx, movieID)
+	if err != nil {
+		return err
+	}
+	movie["path"] = path
+
+	jsonData, err := json.Marshal(movie)
+	if err != nil {
+		return fmt.Errorf("failed to marshal movie update: %w", err)
+	}
+
+	resp, err := c.makeRequest(ctx, "PUT", fmt.Sprintf("/api/v3/movie/%d?moveFiles=false", movieID), bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to update path of movie %d: %w", movieID, err)
+	}
+	defer resp.Body.Close()
+
+	// Radarr answers 202 Accepted to movie updates
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("failed to update path of movie %d, %w", movieID, responseError(resp))
+	}
+
+	c.logger.Debug("Successfully moved movie %d to %s", movieID, path)
+	return nil
+}
+
+// FindTag returns the ID of the tag with the label, and false when Radarr has none
+func (c *RadarrClient) FindTag(ctx context.Context, label string) (int, bool, error) {
+	resp, err := c.makeRequest(ctx, "GET", "/api/v3/tag", nil)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to fetch tags: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, false, fmt.Errorf("failed to fetch tags, %w", responseError(resp))
+	}
+
+	var tags []models.Tag
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return 0, false, fmt.Errorf("failed to decode tags: %w", err)
+	}
+	id, ok := findTag(tags, label)
+	return id, ok, nil
+}
+
+// EnsureTag returns the ID of the tag with the label, creating the tag when Radarr has none
+func (c *RadarrClient) EnsureTag(ctx context.Context, label string) (int, error) {
+	if id, ok, err := c.FindTag(ctx, label); err != nil || ok {
+		return id, err
+	}
+
+	jsonData, err := json.Marshal(models.Tag{Label: label})
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal tag: %w", err)
+	}
+
+	createResp, err := c.makeRequest(ctx, "POST", "/api/v3/tag", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create tag %q: %w", label, err)
+	}
+	defer createResp.Body.Close()
+
+	if createResp.StatusCode != http.StatusCreated && createResp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("failed to create tag %q, %w", label, responseError(createResp))
+	}
+
+	var tag models.Tag
+	if err := json.NewDecoder(createResp.Body).Decode(&tag); err != nil {
+		return 0, fmt.Errorf("failed to decode tag %q: %w", label, err)
+	}
+	c.logger.Debug("Created tag %q (%d)", label, tag.ID)
+	return tag.ID, nil
+}
+
+// TaggedMedia returns the IDs of the movies with the tag, from the tag's details
+func (c *RadarrClient) TaggedMedia(ctx context.Context, tagID int) ([]int, error) {
+	resp, err := c.makeRequest(ctx, "GET", fmt.Sprintf("/api/v3/tag/detail/%d", tagID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch details of tag %d: %w", tagID, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch details of tag %d, %w", tagID, responseError(resp))
+	}
+
+	var details struct {
+		MovieIDs []int `json:"movieIds"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&details); err != nil {
+		return nil, fmt.Errorf("failed to decode details of tag %d: %w", tagID, err)
+	}
+	return details.MovieIDs, nil
+}
+
+// AddMediaTag adds a tag to a movie
+func (c *RadarrClient) AddMediaTag(ctx context.Context, movieID, tagID int) error {
+	return c.updateMovieTags(ctx, movieID, func(movie map[string]interface{}) bool {
+		return addTagID(movie, tagID)
+	})
+}
+
+// RemoveMediaTag removes a tag from a movie
+func (c *RadarrClient) RemoveMediaTag(ctx context.Context, movieID, tagID int) error {
+	return c.updateMovieTags(ctx, movieID, func(movie map[string]interface{}) bool {
+		return removeTagID(movie, tagID)
+	})
+}
+
+// updateMovieTags changes the tags of a movie, sending it back only when change reports a
+// change. The movie is sent back as Radarr returned it, so fields models.Movie doesn't have
+// survive the update.
+func (c *RadarrClient) updateMovieTags(ctx context.Context, movieID int, change func(map[string]interface{}) bool) error {
+	movie, err := c.fetchMovieFields(ctx, movieID)
+	if err != nil {
+		return err
+	}
+	if !change(movie) {
+		return nil
+	}
+
+	jsonData, err := json.Marshal(movie)
+	if err != nil {
+		return fmt.Errorf("failed to marshal movie update: %w", err)
+	}
+
+	resp, err := c.makeRequest(ctx, "PUT", fmt.Sprintf("/api/v3/movie/%d?moveFiles=false", movieID), bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to update tags of movie %d: %w", movieID, err)
+	}
+	defer resp.Body.Close()
+
+	// Radarr answers 202 Accepted to movie updates
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("failed to update tags of movie %d, %w", movieID, responseError(resp))
+	}
+
+	c.logger.Debug("Successfully updated tags of movie %d", movieID)
+	return nil
+}
+
+// fetchMovieFields fetches a movie with every field Radarr returns
+func (c *RadarrClient) fetchMovieFields(ctx context.Context, movieID int) (map[string]interface{}, error) {
+	resp, err := c.makeRequest(ctx, "GET", fmt.Sprintf("/api/v3/movie/%d", movieID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch movie %d: %w", movieID, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch movie %d, %w", movieID, responseError(resp))
+	}
+
+	var movie map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&movie); err != nil {
+		return nil, fmt.Errorf("failed to decode movie %d: %w", movieID, err)
+	}
+	return movie, nil
+}
+
 // TriggerRefresh triggers a missing movie search
 func (c *RadarrClient) TriggerRefresh(ctx context.Context) error {
 	command := map[string]string{
@@ -233,7 +441,7 @@ func (c *RadarrClient) TriggerRefresh(ctx context.Context) error {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to trigger refresh, status: %d", resp.StatusCode)
+		return fmt.Errorf("failed to trigger refresh, %w", responseError(resp))
 	}
 
 	c.logger.Info("✅ Refresh triggered successfully")
@@ -249,7 +457,7 @@ func (c *RadarrClient) GetRootFolders(ctx context.Context) ([]models.RootFolder,
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch root folders, status: %d", resp.StatusCode)
+		return nil, fmt.Errorf("failed to fetch root folders, %w", responseError(resp))
 	}
 
 	var rootFolders []models.RootFolder
@@ -270,7 +478,7 @@ func (c *RadarrClient) GetQualityProfiles(ctx context.Context) ([]models.Quality
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch quality profiles, status: %d", resp.StatusCode)
+		return nil, fmt.Errorf("failed to fetch quality profiles, %w", responseError(resp))
 	}
 
 	var qualityProfiles []models.QualityProfile
@@ -292,11 +500,11 @@ func (c *RadarrClient) LookupMovieByTMDBID(ctx context.Context, tmdbID int) (*mo
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("movie with TMDB ID %d not found", tmdbID)
+		return nil, notFoundError("movie with TMDB ID %d not found", tmdbID)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to lookup movie with TMDB ID %d, status: %d", tmdbID, resp.StatusCode)
+		return nil, fmt.Errorf("failed to lookup movie with TMDB ID %d, %w", tmdbID, responseError(resp))
 	}
 
 	var movieLookup models.MovieLookup
@@ -308,6 +516,57 @@ func (c *RadarrClient) LookupMovieByTMDBID(ctx context.Context, tmdbID int) (*mo
 	return &movieLookup, nil
 }
 
+// LookupMovieByIMDBID looks up movie information by IMDb ID
+func (c *RadarrClient) LookupMovieByIMDBID(ctx context.Context, imdbID string) (*models.MovieLookup, error) {
+	path := "/api/v3/movie/lookup/imdb?imdbId=" + url.QueryEscape(imdbID)
+	resp, err := c.makeRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lookup movie with IMDb ID %s: %w", imdbID, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, notFoundError("movie with IMDb ID %s not found", imdbID)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to lookup movie with IMDb ID %s, %w", imdbID, responseError(resp))
+	}
+
+	var movieLookup models.MovieLookup
+	if err := json.NewDecoder(resp.Body).Decode(&movieLookup); err != nil {
+		return nil, fmt.Errorf("failed to decode movie lookup response for IMDb ID %s: %w", imdbID, err)
+	}
+	if movieLookup.TMDBID == 0 {
+		return nil, notFoundError("movie with IMDb ID %s not found", imdbID)
+	}
+
+	c.logger.Debug("Successfully looked up movie with IMDb ID %s: %s", imdbID, movieLookup.Title)
+	return &movieLookup, nil
+}
+
+// LookupMoviesByTerm searches for movies by title
+func (c *RadarrClient) LookupMoviesByTerm(ctx context.Context, term string) ([]models.MovieLookup, error) {
+	path := "/api/v3/movie/lookup?term=" + url.QueryEscape(term)
+	resp, err := c.makeRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lookup movies matching %q: %w", term, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to lookup movies matching %q, %w", term, responseError(resp))
+	}
+
+	var lookups []models.MovieLookup
+	if err := json.NewDecoder(resp.Body).Decode(&lookups); err != nil {
+		return nil, fmt.Errorf("failed to decode movie lookup response for %q: %w", term, err)
+	}
+
+	c.logger.Debug("Found %d movies matching %q", len(lookups), term)
+	return lookups, nil
+}
+
 // GetMovieByTMDBID returns a movie by TMDB ID if it exists in the collection
 func (c *RadarrClient) GetMovieByTMDBID(ctx context.Context, tmdbID int) (*models.Movie, error) {
 	// Get all movies and find the one with matching TMDB ID
@@ -322,7 +581,7 @@ func (c *RadarrClient) GetMovieByTMDBID(ctx context.Context, tmdbID int) (*model
 		}
 	}
 
-	return nil, fmt.Errorf("movie with TMDB ID %d not found in collection", tmdbID)
+	return nil, notFoundError("movie with TMDB ID %d not found in collection", tmdbID)
 }
 
 // AddMovie adds a movie to the Radarr collection
@@ -340,9 +599,7 @@ func (c *RadarrClient) AddMovie(ctx context.Context, movie models.Movie) (*model
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
-		// Get response body for better error reporting
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to add movie, status: %d, response: %s", resp.StatusCode, string(bodyBytes))
+		return nil, fmt.Errorf("failed to add movie, %w", responseError(resp))
 	}
 
 	var addedMovie models.Movie
@@ -382,6 +639,11 @@ func (c *RadarrClient) GetSeriesByTVDBID(ctx context.Context, tvdbID int) (*mode
 	return nil, fmt.Errorf("GetSeriesByTVDBID is not supported by Radarr client")
 }
 
+// LookupSeriesByTerm is not applicable for Radarr (returns error)
+func (c *RadarrClient) LookupSeriesByTerm(ctx context.Context, term string) ([]models.SeriesLookup, error) {
+	return nil, fmt.Errorf("LookupSeriesByTerm is not supported by Radarr client")
+}
+
 // LookupSeriesByTVDBID is not applicable for Radarr (returns error)
 func (c *RadarrClient) LookupSeriesByTVDBID(ctx context.Context, tvdbID int) (*models.SeriesLookup, error) {
 	return nil, fmt.Errorf("LookupSeriesByTVDBID is not supported by Radarr client")
@@ -392,6 +654,11 @@ func (c *RadarrClient) GetQueue(ctx context.Context) ([]models.QueueItem, error)
 	return nil, fmt.Errorf("GetQueue is not supported by Radarr client")
 }
 
+// GetQueueWithOptions is not applicable for Radarr (returns error)
+func (c *RadarrClient) GetQueueWithOptions(ctx context.Context, opts models.QueueOptions) ([]models.QueueItem, error) {
+	return nil, fmt.Errorf("GetQueueWithOptions is not supported by Radarr client")
+}
+
 // GetQueueDetails is not applicable for Radarr (returns error)
 func (c *RadarrClient) GetQueueDetails(ctx context.Context, queueID int) (*models.QueueItem, error) {
 	return nil, fmt.Errorf("GetQueueDetails is not supported by Radarr client")
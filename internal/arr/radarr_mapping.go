@@ -0,0 +1,159 @@
+package arr
+
+import (
+	"github.com/hnipps/refresharr/pkg/models"
+	"golift.io/starr/radarr"
+)
+
+// starrRadarrMovie is a movie with the movieFileId field starr's type lacks. Radarr only
+// sends the nested movieFile for some endpoints, but always sends movieFileId.
+type starrRadarrMovie struct {
+	radarr.Movie
+	MovieFileID int64 `json:"movieFileId"`
+}
+
+// mapRadarrMovieToModels converts a starr Movie to our models.Movie
+func mapRadarrMovieToModels(m *starrRadarrMovie) models.Movie {
+	if m == nil {
+		return models.Movie{}
+	}
+
+	fileID := m.MovieFileID
+	if fileID == 0 && m.MovieFile != nil {
+		fileID = m.MovieFile.ID
+	}
+	var movieFileID *int
+	if fileID != 0 {
+		id := int(fileID)
+		movieFileID = &id
+	}
+
+	return models.Movie{
+		MediaItem: models.MediaItem{
+			ID:    int(m.ID),
+			Title: m.Title,
+			Path:  m.Path,
+		},
+		Year:             m.Year,
+		HasFile:          m.HasFile,
+		MovieFileID:      movieFileID,
+		TMDBID:           int(m.TmdbID),
+		IMDBID:           m.ImdbID,
+		Monitored:        m.Monitored,
+		QualityProfileID: int(m.QualityProfileID),
+	}
+}
+
+// mapRadarrMoviesToModelsList converts a slice of starr Movies to models.Movie
+func mapRadarrMoviesToModelsList(movies []*starrRadarrMovie) []models.Movie {
+	result := make([]models.Movie, len(movies))
+	for i, m := range movies {
+		result[i] = mapRadarrMovieToModels(m)
+	}
+	return result
+}
+
+// mapRadarrMovieFileToModels converts a starr MovieFile to our models.MovieFile
+func mapRadarrMovieFileToModels(mf *radarr.MovieFile) models.MovieFile {
+	if mf == nil {
+		return models.MovieFile{}
+	}
+
+	return models.MovieFile{
+		ID:            int(mf.ID),
+		Path:          mf.Path,
+		MovieID:       int(mf.MovieID),
+		Size:          mf.Size,
+		Quality:       mapFileQuality(mf.Quality),
+		ReleaseGroup:  mf.ReleaseGroup,
+		CustomFormats: mapRadarrCustomFormats(mf.CustomFormats),
+	}
+}
+
+// mapRadarrCustomFormats converts the starr custom formats of a file to our models.CustomFormat
+func mapRadarrCustomFormats(formats []*radarr.CustomFormatOutput) []models.CustomFormat {
+	var result []models.CustomFormat
+	for _, format := range formats {
+		if format != nil {
+			result = append(result, models.CustomFormat{ID: int(format.ID), Name: format.Name})
+		}
+	}
+	return result
+}
+
+// mapRadarrMovieLookupToModels converts a starr Movie lookup result to our models.MovieLookup
+func mapRadarrMovieLookupToModels(m *radarr.Movie) models.MovieLookup {
+	if m == nil {
+		return models.MovieLookup{}
+	}
+
+	result := models.MovieLookup{
+		TMDBID:   int(m.TmdbID),
+		IMDBID:   m.ImdbID,
+		Title:    m.Title,
+		Year:     m.Year,
+		Overview: m.Overview,
+		Images: make([]struct {
+			CoverType string `json:"coverType"`
+			URL       string `json:"url"`
+		}, len(m.Images)),
+	}
+	if m.Collection != nil && m.Collection.TmdbID > 0 {
+		result.Collection = &models.MovieCollection{TMDBID: int(m.Collection.TmdbID), Title: m.Collection.Name}
+	}
+	for i, img := range m.Images {
+		result.Images[i].CoverType = img.CoverType
+		result.Images[i].URL = img.URL
+	}
+	return result
+}
+
+// mapModelsMovieToRadarrInput converts our models.Movie to a starr AddMovieInput
+func mapModelsMovieToRadarrInput(m models.Movie) *radarr.AddMovieInput {
+	input := &radarr.AddMovieInput{
+		Title:            m.Title,
+		TmdbID:           int64(m.TMDBID),
+		Year:             m.Year,
+		QualityProfileID: int64(m.QualityProfileID),
+		RootFolderPath:   m.RootFolderPath,
+		Monitored:        m.Monitored,
+		AddOptions:       &radarr.AddMovieOptions{SearchForMovie: false},
+	}
+	if m.AddOptions != nil {
+		input.AddOptions = &radarr.AddMovieOptions{SearchForMovie: m.AddOptions.SearchForMovie, Monitor: m.AddOptions.Monitor}
+	}
+	return input
+}
+
+// mapRadarrRootFoldersToModelsList converts a slice of starr RootFolders to models.RootFolder
+func mapRadarrRootFoldersToModelsList(folders []*radarr.RootFolder) []models.RootFolder {
+	result := make([]models.RootFolder, len(folders))
+	for i, rf := range folders {
+		if rf == nil {
+			continue
+		}
+		accessible := rf.Accessible
+		result[i] = models.RootFolder{
+			ID:         int(rf.ID),
+			Path:       rf.Path,
+			Name:       rf.Path, // starr doesn't have a separate name field
+			Accessible: &accessible,
+		}
+	}
+	return result
+}
+
+// mapRadarrQualityProfilesToModelsList converts a slice of starr QualityProfiles to models.QualityProfile
+func mapRadarrQualityProfilesToModelsList(profiles []*radarr.QualityProfile) []models.QualityProfile {
+	result := make([]models.QualityProfile, len(profiles))
+	for i, qp := range profiles {
+		if qp == nil {
+			continue
+		}
+		result[i] = models.QualityProfile{
+			ID:   int(qp.ID),
+			Name: qp.Name,
+		}
+	}
+	return result
+}
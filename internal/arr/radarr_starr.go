@@ -0,0 +1,512 @@
+package arr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/hnipps/refresharr/internal/config"
+	"github.com/hnipps/refresharr/pkg/models"
+	"golift.io/starr"
+	"golift.io/starr/radarr"
+)
+
+// StarrRadarrClient implements the Client interface for the Radarr API using the starr library,
+// like SonarrClient does for Sonarr. It is an alternative to the built-in RadarrClient,
+// selected with API_CLIENT=starr.
+type StarrRadarrClient struct {
+	client *radarr.Radarr
+	calls  *callCounter
+	logger Logger
+	caps   *Capabilities // Optional features the server supports, probed on connect
+}
+
+// NewStarrRadarrClient creates a new starr-backed Radarr client
+func NewStarrRadarrClient(cfg *config.RadarrConfig, timeout time.Duration, logger Logger) Client {
+	return NewStarrRadarrClientWithTransport(cfg, timeout, nil, logger)
+}
+
+// NewStarrRadarrClientWithTransport creates a new starr-backed Radarr client whose requests go through wrap
+func NewStarrRadarrClientWithTransport(cfg *config.RadarrConfig, timeout time.Duration, wrap TransportWrapper, logger Logger) Client {
+	baseURL, endpoint := ResolveEndpoint(cfg.URL)
+	starrConfig := starr.New(cfg.APIKey, baseURL, timeout)
+	if endpoint != nil {
+		starrConfig.Client.Transport = endpoint
+	}
+	calls := newClientTransport(wrap, starrConfig.Client.Transport, cfg.APIKey, logger)
+	starrConfig.Client.Transport = calls
+
+	return &StarrRadarrClient{
+		client: radarr.New(starrConfig),
+		calls:  calls,
+		logger: logger,
+		caps:   newCapabilities("Radarr"),
+	}
+}
+
+// APICalls returns the number of requests sent to Radarr
+func (c *StarrRadarrClient) APICalls() int64 {
+	return c.calls.calls.Load()
+}
+
+// Capabilities returns the optional features the server supports
+func (c *StarrRadarrClient) Capabilities() *Capabilities {
+	return c.caps
+}
+
+// GetName returns the service name
+func (c *StarrRadarrClient) GetName() string {
+	return "radarr"
+}
+
+// TestConnection verifies the connection to Radarr
+func (c *StarrRadarrClient) TestConnection(ctx context.Context) error {
+	status, err := c.client.GetSystemStatusContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to Radarr: %w", apiError(err))
+	}
+	probeRadarrCapabilities(c.caps, status.Version, c.logger)
+
+	c.logger.Info("✅ Successfully connected to Radarr")
+	return nil
+}
+
+// GetAllSeries is not applicable for Radarr (returns error)
+func (c *StarrRadarrClient) GetAllSeries(ctx context.Context) ([]models.Series, error) {
+	return nil, fmt.Errorf("GetAllSeries is not supported by Radarr client")
+}
+
+// GetAllMovies returns all movies from Radarr
+func (c *StarrRadarrClient) GetAllMovies(ctx context.Context) ([]models.Movie, error) {
+	movies, err := c.fetchMovies(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch movies: %w", err)
+	}
+
+	result := mapRadarrMoviesToModelsList(movies)
+	c.logger.Debug("Fetched %d movies from Radarr", len(result))
+	return result, nil
+}
+
+// GetMovie returns a single movie by ID from Radarr
+func (c *StarrRadarrClient) GetMovie(ctx context.Context, movieID int) (*models.Movie, error) {
+	movie, err := c.fetchMovie(ctx, movieID)
+	if errors.Is(err, ErrNotFound) {
+		return nil, notFoundError("movie %d not found", movieID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch movie %d: %w", movieID, err)
+	}
+
+	result := mapRadarrMovieToModels(movie)
+	c.logger.Debug("Fetched movie %d from Radarr", movieID)
+	return &result, nil
+}
+
+// GetEpisode is not applicable for Radarr (returns error)
+func (c *StarrRadarrClient) GetEpisode(ctx context.Context, episodeID int) (*models.Episode, error) {
+	return nil, fmt.Errorf("GetEpisode is not supported by Radarr client")
+}
+
+// GetEpisodesForSeries is not applicable for Radarr (returns error)
+func (c *StarrRadarrClient) GetEpisodesForSeries(ctx context.Context, seriesID int) ([]models.Episode, error) {
+	return nil, fmt.Errorf("GetEpisodesForSeries is not supported by Radarr client")
+}
+
+// GetEpisodeFile is not applicable for Radarr (returns error)
+func (c *StarrRadarrClient) GetEpisodeFile(ctx context.Context, fileID int) (*models.EpisodeFile, error) {
+	return nil, fmt.Errorf("GetEpisodeFile is not supported by Radarr client")
+}
+
+// DeleteEpisodeFile is not applicable for Radarr (returns error)
+func (c *StarrRadarrClient) DeleteEpisodeFile(ctx context.Context, fileID int) error {
+	return fmt.Errorf("DeleteEpisodeFile is not supported by Radarr client")
+}
+
+// UpdateEpisode is not applicable for Radarr (returns error)
+func (c *StarrRadarrClient) UpdateEpisode(ctx context.Context, episode models.Episode) error {
+	return fmt.Errorf("UpdateEpisode is not supported by Radarr client")
+}
+
+// GetMovieFile returns movie file details
+func (c *StarrRadarrClient) GetMovieFile(ctx context.Context, fileID int) (*models.MovieFile, error) {
+	movieFile, err := c.client.GetMovieFileByIDContext(ctx, int64(fileID))
+	err = apiError(err)
+	if errors.Is(err, ErrNotFound) {
+		return nil, notFoundError("movie file %d not found", fileID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch movie file %d: %w", fileID, err)
+	}
+
+	result := mapRadarrMovieFileToModels(movieFile)
+	return &result, nil
+}
+
+// DeleteMovieFile deletes a movie file record
+func (c *StarrRadarrClient) DeleteMovieFile(ctx context.Context, fileID int) error {
+	req := starr.Request{URI: fmt.Sprintf("%s/moviefile/%d", radarr.APIver, fileID)}
+	if err := c.client.DeleteAny(ctx, req); err != nil {
+		return fmt.Errorf("failed to delete movie file %d: %w", fileID, apiError(err))
+	}
+
+	c.logger.Debug("Successfully deleted movie file %d", fileID)
+	return nil
+}
+
+// UpdateMovie updates a movie's metadata
+func (c *StarrRadarrClient) UpdateMovie(ctx context.Context, movie models.Movie) error {
+	// First, fetch the current movie data to ensure we have the complete object
+	currentMovie, err := c.fetchMovie(ctx, movie.ID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch current movie %d data: %w", movie.ID, err)
+	}
+
+	// Update the file reference fields
+	currentMovie.HasFile = false
+	currentMovie.MovieFile = nil
+	currentMovie.MovieFileID = 0
+
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(currentMovie); err != nil {
+		return fmt.Errorf("failed to marshal movie update: %w", err)
+	}
+
+	var output starrRadarrMovie
+	req := starr.Request{URI: fmt.Sprintf("%s/movie/%d", radarr.APIver, movie.ID), Body: &body}
+	if err := c.client.PutInto(ctx, req, &output); err != nil {
+		return fmt.Errorf("failed to update movie %d: %w", movie.ID, apiError(err))
+	}
+
+	c.logger.Debug("Successfully updated movie %d", movie.ID)
+	return nil
+}
+
+// MediaPath returns the folder of a movie
+func (c *StarrRadarrClient) MediaPath(ctx context.Context, movieID int) (string, error) {
+	movie, err := c.fetchMovieFields(ctx, movieID)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch movie %d: %w", movieID, err)
+	}
+	path, _ := movie["path"].(string)
+	return path, nil
+}
+
+// UpdateMediaPath points a movie at another folder without moving its file. The movie is sent
+// back as Radarr returned it, so fields starr doesn't model survive the update.
+func (c *StarrRadarrClient) UpdateMediaPath(ctx context.Context, movieID int, path string) error {
+	movie, err := c.fetchMovieFields(ctx, movieID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch movie %d: %w", movieID, err)
+	}
+	movie["path"] = path
+
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(movie); err != nil {
+		return fmt.Errorf("failed to marshal movie update: %w", err)
+	}
+
+	var output map[string]interface{}
+	req := starr.Request{
+		URI:   fmt.Sprintf("%s/movie/%d", radarr.APIver, movieID),
+		Query: url.Values{"moveFiles": {"false"}},
+		Body:  &body,
+	}
+	if err := c.client.PutInto(ctx, req, &output); err != nil {
+		return fmt.Errorf("failed to update path of movie %d: %w", movieID, apiError(err))
+	}
+
+	c.logger.Debug("Successfully moved movie %d to %s", movieID, path)
+	return nil
+}
+
+// FindTag returns the ID of the tag with the label, and false when Radarr has none
+func (c *StarrRadarrClient) FindTag(ctx context.Context, label string) (int, bool, error) {
+	tags, err := c.client.GetTagsContext(ctx)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to fetch tags: %w", apiError(err))
+	}
+	id, ok := findTag(starrTags(tags), label)
+	return id, ok, nil
+}
+
+// EnsureTag returns the ID of the tag with the label, creating the tag when Radarr has none
+func (c *StarrRadarrClient) EnsureTag(ctx context.Context, label string) (int, error) {
+	if id, ok, err := c.FindTag(ctx, label); err != nil || ok {
+		return id, err
+	}
+
+	tag, err := c.client.AddTagContext(ctx, &starr.Tag{Label: label})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create tag %q: %w", label, apiError(err))
+	}
+	c.logger.Debug("Created tag %q (%d)", label, tag.ID)
+	return tag.ID, nil
+}
+
+// TaggedMedia returns the IDs of the movies with the tag, from the tag's details
+func (c *StarrRadarrClient) TaggedMedia(ctx context.Context, tagID int) ([]int, error) {
+	var details struct {
+		MovieIDs []int `json:"movieIds"`
+	}
+	req := starr.Request{URI: fmt.Sprintf("%s/tag/detail/%d", radarr.APIver, tagID)}
+	if err := c.client.GetInto(ctx, req, &details); err != nil {
+		return nil, fmt.Errorf("failed to fetch details of tag %d: %w", tagID, apiError(err))
+	}
+	return details.MovieIDs, nil
+}
+
+// AddMediaTag adds a tag to a movie
+func (c *StarrRadarrClient) AddMediaTag(ctx context.Context, movieID, tagID int) error {
+	return c.updateMovieTags(ctx, movieID, func(movie map[string]interface{}) bool {
+		return addTagID(movie, tagID)
+	})
+}
+
+// RemoveMediaTag removes a tag from a movie
+func (c *StarrRadarrClient) RemoveMediaTag(ctx context.Context, movieID, tagID int) error {
+	return c.updateMovieTags(ctx, movieID, func(movie map[string]interface{}) bool {
+		return removeTagID(movie, tagID)
+	})
+}
+
+// updateMovieTags changes the tags of a movie, sending it back only when change reports a
+// change. The movie is sent back as Radarr returned it, so fields starr doesn't model survive
+// the update.
+func (c *StarrRadarrClient) updateMovieTags(ctx context.Context, movieID int, change func(map[string]interface{}) bool) error {
+	movie, err := c.fetchMovieFields(ctx, movieID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch movie %d: %w", movieID, err)
+	}
+	if !change(movie) {
+		return nil
+	}
+
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(movie); err != nil {
+		return fmt.Errorf("failed to marshal movie update: %w", err)
+	}
+
+	var output map[string]interface{}
+	req := starr.Request{
+		URI:   fmt.Sprintf("%s/movie/%d", radarr.APIver, movieID),
+		Query: url.Values{"moveFiles": {"false"}},
+		Body:  &body,
+	}
+	if err := c.client.PutInto(ctx, req, &output); err != nil {
+		return fmt.Errorf("failed to update tags of movie %d: %w", movieID, apiError(err))
+	}
+
+	c.logger.Debug("Successfully updated tags of movie %d", movieID)
+	return nil
+}
+
+// TriggerRefresh triggers a missing movie search
+func (c *StarrRadarrClient) TriggerRefresh(ctx context.Context) error {
+	command := &radarr.CommandRequest{
+		Name: "MissingMoviesSearch",
+	}
+
+	if _, err := c.client.SendCommandContext(ctx, command); err != nil {
+		return fmt.Errorf("failed to trigger refresh: %w", apiError(err))
+	}
+
+	c.logger.Info("✅ Refresh triggered successfully")
+	return nil
+}
+
+// GetRootFolders returns all root folders from Radarr
+func (c *StarrRadarrClient) GetRootFolders(ctx context.Context) ([]models.RootFolder, error) {
+	rootFolders, err := c.client.GetRootFoldersContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch root folders: %w", apiError(err))
+	}
+
+	result := mapRadarrRootFoldersToModelsList(rootFolders)
+	c.logger.Debug("Fetched %d root folders from Radarr", len(result))
+	return result, nil
+}
+
+// GetQualityProfiles returns all quality profiles from Radarr
+func (c *StarrRadarrClient) GetQualityProfiles(ctx context.Context) ([]models.QualityProfile, error) {
+	qualityProfiles, err := c.client.GetQualityProfilesContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch quality profiles: %w", apiError(err))
+	}
+
+	result := mapRadarrQualityProfilesToModelsList(qualityProfiles)
+	c.logger.Debug("Fetched %d quality profiles from Radarr", len(result))
+	return result, nil
+}
+
+// LookupMovieByTMDBID looks up movie information by TMDB ID
+func (c *StarrRadarrClient) LookupMovieByTMDBID(ctx context.Context, tmdbID int) (*models.MovieLookup, error) {
+	movie, err := c.client.LookupTMDBContext(ctx, int64(tmdbID))
+	err = apiError(err)
+	if errors.Is(err, ErrNotFound) {
+		return nil, notFoundError("movie with TMDB ID %d not found", tmdbID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lookup movie with TMDB ID %d: %w", tmdbID, err)
+	}
+
+	result := mapRadarrMovieLookupToModels(movie)
+	c.logger.Debug("Successfully looked up movie with TMDB ID %d: %s", tmdbID, result.Title)
+	return &result, nil
+}
+
+// LookupMovieByIMDBID looks up movie information by IMDb ID
+func (c *StarrRadarrClient) LookupMovieByIMDBID(ctx context.Context, imdbID string) (*models.MovieLookup, error) {
+	movie, err := c.client.LookupIMDBContext(ctx, imdbID)
+	err = apiError(err)
+	if errors.Is(err, ErrNotFound) || (err == nil && (movie == nil || movie.TmdbID == 0)) {
+		return nil, notFoundError("movie with IMDb ID %s not found", imdbID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lookup movie with IMDb ID %s: %w", imdbID, err)
+	}
+
+	result := mapRadarrMovieLookupToModels(movie)
+	c.logger.Debug("Successfully looked up movie with IMDb ID %s: %s", imdbID, result.Title)
+	return &result, nil
+}
+
+// LookupMoviesByTerm searches for movies by title
+func (c *StarrRadarrClient) LookupMoviesByTerm(ctx context.Context, term string) ([]models.MovieLookup, error) {
+	movies, err := c.client.LookupContext(ctx, term)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lookup movies matching %q: %w", term, apiError(err))
+	}
+
+	lookups := make([]models.MovieLookup, 0, len(movies))
+	for _, movie := range movies {
+		if movie != nil {
+			lookups = append(lookups, mapRadarrMovieLookupToModels(movie))
+		}
+	}
+	c.logger.Debug("Found %d movies matching %q", len(lookups), term)
+	return lookups, nil
+}
+
+// GetMovieByTMDBID returns a movie by TMDB ID if it exists in the collection
+func (c *StarrRadarrClient) GetMovieByTMDBID(ctx context.Context, tmdbID int) (*models.Movie, error) {
+	movies, err := c.fetchMovies(ctx, url.Values{"tmdbId": {fmt.Sprint(tmdbID)}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch movies to search for TMDB ID %d: %w", tmdbID, err)
+	}
+
+	for _, movie := range movies {
+		if movie != nil && int(movie.TmdbID) == tmdbID {
+			result := mapRadarrMovieToModels(movie)
+			return &result, nil
+		}
+	}
+
+	return nil, notFoundError("movie with TMDB ID %d not found in collection", tmdbID)
+}
+
+// AddMovie adds a movie to the Radarr collection
+func (c *StarrRadarrClient) AddMovie(ctx context.Context, movie models.Movie) (*models.Movie, error) {
+	addedMovie, err := c.client.AddMovieContext(ctx, mapModelsMovieToRadarrInput(movie))
+	if err != nil {
+		return nil, fmt.Errorf("failed to add movie: %w", apiError(err))
+	}
+
+	result := mapRadarrMovieToModels(&starrRadarrMovie{Movie: *addedMovie})
+	c.logger.Info("✅ Successfully added movie: %s (%d) with TMDB ID %d", result.Title, result.Year, result.TMDBID)
+	return &result, nil
+}
+
+// fetchMovies fetches the movies matching query, or all movies when query is nil
+func (c *StarrRadarrClient) fetchMovies(ctx context.Context, query url.Values) ([]*starrRadarrMovie, error) {
+	var movies []*starrRadarrMovie
+	req := starr.Request{URI: radarr.APIver + "/movie", Query: query}
+	if err := c.client.GetInto(ctx, req, &movies); err != nil {
+		return nil, fmt.Errorf("api.Get(%s): %w", &req, apiError(err))
+	}
+	return movies, nil
+}
+
+// fetchMovie fetches a single movie
+func (c *StarrRadarrClient) fetchMovie(ctx context.Context, movieID int) (*starrRadarrMovie, error) {
+	var movie starrRadarrMovie
+	req := starr.Request{URI: fmt.Sprintf("%s/movie/%d", radarr.APIver, movieID)}
+	if err := c.client.GetInto(ctx, req, &movie); err != nil {
+		return nil, fmt.Errorf("api.Get(%s): %w", &req, apiError(err))
+	}
+	return &movie, nil
+}
+
+// fetchMovieFields fetches a movie with every field Radarr returns
+func (c *StarrRadarrClient) fetchMovieFields(ctx context.Context, movieID int) (map[string]interface{}, error) {
+	var movie map[string]interface{}
+	req := starr.Request{URI: fmt.Sprintf("%s/movie/%d", radarr.APIver, movieID)}
+	if err := c.client.GetInto(ctx, req, &movie); err != nil {
+		return nil, fmt.Errorf("api.Get(%s): %w", &req, apiError(err))
+	}
+	return movie, nil
+}
+
+// AddSeries is not applicable for Radarr (returns error)
+func (c *StarrRadarrClient) AddSeries(ctx context.Context, series models.Series) (*models.Series, error) {
+	return nil, fmt.Errorf("AddSeries is not supported by Radarr client")
+}
+
+// GetSeriesByTVDBID is not applicable for Radarr (returns error)
+func (c *StarrRadarrClient) GetSeriesByTVDBID(ctx context.Context, tvdbID int) (*models.Series, error) {
+	return nil, fmt.Errorf("GetSeriesByTVDBID is not supported by Radarr client")
+}
+
+// LookupSeriesByTerm is not applicable for Radarr (returns error)
+func (c *StarrRadarrClient) LookupSeriesByTerm(ctx context.Context, term string) ([]models.SeriesLookup, error) {
+	return nil, fmt.Errorf("LookupSeriesByTerm is not supported by Radarr client")
+}
+
+// LookupSeriesByTVDBID is not applicable for Radarr (returns error)
+func (c *StarrRadarrClient) LookupSeriesByTVDBID(ctx context.Context, tvdbID int) (*models.SeriesLookup, error) {
+	return nil, fmt.Errorf("LookupSeriesByTVDBID is not supported by Radarr client")
+}
+
+// GetQueue is not applicable for Radarr (returns error)
+func (c *StarrRadarrClient) GetQueue(ctx context.Context) ([]models.QueueItem, error) {
+	return nil, fmt.Errorf("GetQueue is not supported by Radarr client")
+}
+
+// GetQueueWithOptions is not applicable for Radarr (returns error)
+func (c *StarrRadarrClient) GetQueueWithOptions(ctx context.Context, opts models.QueueOptions) ([]models.QueueItem, error) {
+	return nil, fmt.Errorf("GetQueueWithOptions is not supported by Radarr client")
+}
+
+// GetQueueDetails is not applicable for Radarr (returns error)
+func (c *StarrRadarrClient) GetQueueDetails(ctx context.Context, queueID int) (*models.QueueItem, error) {
+	return nil, fmt.Errorf("GetQueueDetails is not supported by Radarr client")
+}
+
+// RemoveFromQueue is not applicable for Radarr (returns error)
+func (c *StarrRadarrClient) RemoveFromQueue(ctx context.Context, queueID int, removeFromClient bool) error {
+	return fmt.Errorf("RemoveFromQueue is not supported by Radarr client")
+}
+
+// TriggerDownloadClientScan is not applicable for Radarr (returns error)
+func (c *StarrRadarrClient) TriggerDownloadClientScan(ctx context.Context) error {
+	return fmt.Errorf("TriggerDownloadClientScan is not supported by Radarr client")
+}
+
+// GetManualImport is not applicable for Radarr (returns error)
+func (c *StarrRadarrClient) GetManualImport(ctx context.Context, folder string) ([]models.ManualImportItem, error) {
+	return nil, fmt.Errorf("GetManualImport is not supported by Radarr client")
+}
+
+// GetManualImportWithParams is not applicable for Radarr (returns error)
+func (c *StarrRadarrClient) GetManualImportWithParams(ctx context.Context, folder, downloadID string, seriesID int, filterExisting bool) ([]models.ManualImportItem, error) {
+	return nil, fmt.Errorf("GetManualImportWithParams is not supported by Radarr client")
+}
+
+// ExecuteManualImport is not applicable for Radarr (returns error)
+func (c *StarrRadarrClient) ExecuteManualImport(ctx context.Context, files []models.ManualImportItem, importMode string) error {
+	return fmt.Errorf("ExecuteManualImport is not supported by Radarr client")
+}
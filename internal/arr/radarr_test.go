@@ -3,8 +3,10 @@ package arr
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"testing"
 	"time"
 
@@ -179,8 +181,169 @@ func TestRadarrClient_GetMovieFile_NotFound(t *testing.T) {
 	ctx := context.Background()
 
 	_, err := client.GetMovieFile(ctx, 404)
-	if err == nil {
-		t.Error("Expected GetMovieFile() to fail with not found")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected GetMovieFile() to fail with ErrNotFound, got %v", err)
+	}
+}
+
+func TestRadarrClients_LookupMovieByIMDBID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/movie/lookup/imdb" {
+			t.Errorf("Expected path '/api/v3/movie/lookup/imdb', got '%s'", r.URL.Path)
+		}
+		if r.URL.Query().Get("imdbId") != "tt0111161" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"tmdbId":278,"imdbId":"tt0111161","title":"The Shawshank Redemption","year":1994}`))
+	}))
+	defer server.Close()
+
+	cfg := &config.RadarrConfig{URL: server.URL, APIKey: "test-key"}
+	for _, client := range []Client{
+		NewRadarrClient(cfg, 30*time.Second, &mockLogger{}),
+		NewStarrRadarrClient(cfg, 30*time.Second, &mockLogger{}),
+	} {
+		lookup, err := client.LookupMovieByIMDBID(context.Background(), "tt0111161")
+		if err != nil {
+			t.Fatalf("%T: LookupMovieByIMDBID() failed: %v", client, err)
+		}
+		if lookup.TMDBID != 278 || lookup.IMDBID != "tt0111161" || lookup.Year != 1994 {
+			t.Errorf("%T: unexpected lookup %+v", client, lookup)
+		}
+
+		if _, err := client.LookupMovieByIMDBID(context.Background(), "tt0000000"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("%T: expected ErrNotFound for an unknown IMDb ID, got %v", client, err)
+		}
+	}
+}
+
+func TestRadarrClients_AddMovieWithCollection(t *testing.T) {
+	var added map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v3/movie/lookup/tmdb":
+			w.Write([]byte(`{"tmdbId":603,"title":"The Matrix","year":1999,"collection":{"name":"The Matrix Collection","tmdbId":2344}}`))
+		case "/api/v3/movie":
+			added = nil
+			if err := json.NewDecoder(r.Body).Decode(&added); err != nil {
+				t.Errorf("Failed to decode added movie: %v", err)
+			}
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":1,"tmdbId":603,"title":"The Matrix","year":1999}`))
+		}
+	}))
+	defer server.Close()
+
+	cfg := &config.RadarrConfig{URL: server.URL, APIKey: "test-key"}
+	for _, client := range []Client{
+		NewRadarrClient(cfg, 30*time.Second, &mockLogger{}),
+		NewStarrRadarrClient(cfg, 30*time.Second, &mockLogger{}),
+	} {
+		lookup, err := client.LookupMovieByTMDBID(context.Background(), 603)
+		if err != nil {
+			t.Fatalf("%T: LookupMovieByTMDBID() failed: %v", client, err)
+		}
+		if lookup.Collection == nil || lookup.Collection.TMDBID != 2344 {
+			t.Errorf("%T: expected the movie's collection, got %+v", client, lookup.Collection)
+		}
+
+		movie := models.Movie{
+			MediaItem:  models.MediaItem{Title: "The Matrix"},
+			TMDBID:     603,
+			Monitored:  true,
+			AddOptions: &models.MovieAddOptions{Monitor: models.MonitorMovieAndCollection},
+		}
+		if _, err := client.AddMovie(context.Background(), movie); err != nil {
+			t.Fatalf("%T: AddMovie() failed: %v", client, err)
+		}
+		options, _ := added["addOptions"].(map[string]any)
+		if options["monitor"] != models.MonitorMovieAndCollection {
+			t.Errorf("%T: expected the collection to be monitored, got add options %v", client, added["addOptions"])
+		}
+	}
+}
+
+func TestRadarrClients_Tagging(t *testing.T) {
+	var updated map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v3/tag":
+			w.Write([]byte(`[{"id":1,"label":"4k"}]`))
+		case "POST /api/v3/tag":
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":2,"label":"refresharr-cleaned"}`))
+		case "GET /api/v3/tag/detail/2":
+			w.Write([]byte(`{"id":2,"label":"refresharr-cleaned","movieIds":[5,6]}`))
+		case "GET /api/v3/movie/5":
+			w.Write([]byte(`{"id":5,"title":"The Matrix","tags":[1],"minimumAvailability":"released"}`))
+		case "PUT /api/v3/movie/5":
+			updated = nil
+			if err := json.NewDecoder(r.Body).Decode(&updated); err != nil {
+				t.Errorf("Failed to decode updated movie: %v", err)
+			}
+			w.WriteHeader(http.StatusAccepted)
+			w.Write([]byte(`{"id":5}`))
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	cfg := &config.RadarrConfig{URL: server.URL, APIKey: "test-key"}
+	for _, client := range []Client{
+		NewRadarrClient(cfg, 30*time.Second, &mockLogger{}),
+		NewStarrRadarrClient(cfg, 30*time.Second, &mockLogger{}),
+	} {
+		tagger, ok := client.(Tagger)
+		if !ok {
+			t.Fatalf("%T doesn't implement Tagger", client)
+		}
+
+		// An existing tag is found whatever the case of the label
+		if id, err := tagger.EnsureTag(context.Background(), "4K"); err != nil || id != 1 {
+			t.Errorf("%T: expected existing tag 1, got %d, %v", client, id, err)
+		}
+		tagID, err := tagger.EnsureTag(context.Background(), "refresharr-cleaned")
+		if err != nil || tagID != 2 {
+			t.Fatalf("%T: expected created tag 2, got %d, %v", client, tagID, err)
+		}
+
+		if err := tagger.AddMediaTag(context.Background(), 5, tagID); err != nil {
+			t.Fatalf("%T: AddMediaTag() failed: %v", client, err)
+		}
+		tags, _ := updated["tags"].([]any)
+		if len(tags) != 2 || tags[0] != float64(1) || tags[1] != float64(2) {
+			t.Errorf("%T: expected tags [1 2], got %v", client, updated["tags"])
+		}
+		if updated["minimumAvailability"] != "released" {
+			t.Errorf("%T: expected other fields to survive the update, got %v", client, updated)
+		}
+
+		// Adding a tag the movie already has sends nothing
+		updated = nil
+		if err := tagger.AddMediaTag(context.Background(), 5, 1); err != nil || updated != nil {
+			t.Errorf("%T: expected no update for a tag the movie has, got %v, %v", client, updated, err)
+		}
+
+		ids, err := tagger.TaggedMedia(context.Background(), tagID)
+		if err != nil || !slices.Equal(ids, []int{5, 6}) {
+			t.Errorf("%T: expected movies 5 and 6 to be tagged, got %v, %v", client, ids, err)
+		}
+
+		if err := tagger.RemoveMediaTag(context.Background(), 5, 1); err != nil {
+			t.Fatalf("%T: RemoveMediaTag() failed: %v", client, err)
+		}
+		if tags, _ := updated["tags"].([]any); len(tags) != 0 {
+			t.Errorf("%T: expected the tag to be removed, got %v", client, updated["tags"])
+		}
+		updated = nil
+		if err := tagger.RemoveMediaTag(context.Background(), 5, tagID); err != nil || updated != nil {
+			t.Errorf("%T: expected no update for a tag the movie doesn't have, got %v, %v", client, updated, err)
+		}
 	}
 }
 
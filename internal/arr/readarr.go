@@ -0,0 +1,296 @@
+package arr
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hnipps/refresharr/internal/config"
+	"github.com/hnipps/refresharr/pkg/models"
+	"golift.io/starr"
+	"golift.io/starr/readarr"
+)
+
+// ReadarrClient implements the Client interface for the Readarr API. Books don't fit the series
+// and movie methods of Client, so its book records are reached through BookClient.
+type ReadarrClient struct {
+	client *readarr.Readarr
+	calls  *callCounter
+	logger Logger
+}
+
+// NewReadarrClient creates a new Readarr client
+func NewReadarrClient(cfg *config.ReadarrConfig, timeout time.Duration, logger Logger) Client {
+	return NewReadarrClientWithTransport(cfg, timeout, nil, logger)
+}
+
+// NewReadarrClientWithTransport creates a new Readarr client whose requests go through wrap
+func NewReadarrClientWithTransport(cfg *config.ReadarrConfig, timeout time.Duration, wrap TransportWrapper, logger Logger) Client {
+	baseURL, endpoint := ResolveEndpoint(cfg.URL)
+	starrConfig := starr.New(cfg.APIKey, baseURL, timeout)
+	if endpoint != nil {
+		starrConfig.Client.Transport = endpoint
+	}
+	calls := newClientTransport(wrap, starrConfig.Client.Transport, cfg.APIKey, logger)
+	starrConfig.Client.Transport = calls
+
+	return &ReadarrClient{
+		client: readarr.New(starrConfig),
+		calls:  calls,
+		logger: logger,
+	}
+}
+
+// APICalls returns the number of requests sent to Readarr
+func (c *ReadarrClient) APICalls() int64 {
+	return c.calls.calls.Load()
+}
+
+// GetName returns the service name
+func (c *ReadarrClient) GetName() string {
+	return "readarr"
+}
+
+// TestConnection verifies the connection to Readarr
+func (c *ReadarrClient) TestConnection(ctx context.Context) error {
+	if _, err := c.client.GetSystemStatusContext(ctx); err != nil {
+		return fmt.Errorf("failed to connect to Readarr: %w", apiError(err))
+	}
+
+	c.logger.Info("✅ Successfully connected to Readarr")
+	return nil
+}
+
+// GetAllBooks returns all books from Readarr
+func (c *ReadarrClient) GetAllBooks(ctx context.Context) ([]models.Book, error) {
+	books, err := c.client.GetBookContext(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch books: %w", apiError(err))
+	}
+
+	result := mapReadarrBooksToModelsList(books)
+	c.logger.Debug("Fetched %d books from Readarr", len(result))
+	return result, nil
+}
+
+// GetBookFiles returns the files of a book
+func (c *ReadarrClient) GetBookFiles(ctx context.Context, bookID int) ([]models.BookFile, error) {
+	files, err := c.client.GetBookFilesForBookContext(ctx, int64(bookID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch book files for book %d: %w", bookID, apiError(err))
+	}
+
+	result := make([]models.BookFile, len(files))
+	for i, file := range files {
+		result[i] = mapReadarrBookFileToModels(file)
+	}
+	return result, nil
+}
+
+// DeleteBookFile deletes a book file record
+func (c *ReadarrClient) DeleteBookFile(ctx context.Context, fileID int) error {
+	if err := c.client.DeleteBookFileContext(ctx, int64(fileID)); err != nil {
+		return fmt.Errorf("failed to delete book file %d: %w", fileID, apiError(err))
+	}
+
+	c.logger.Debug("Successfully deleted book file %d", fileID)
+	return nil
+}
+
+// LookupBookByISBN looks up a book by ISBN through Readarr's metadata source
+func (c *ReadarrClient) LookupBookByISBN(ctx context.Context, isbn string) (*models.Book, error) {
+	books, err := c.client.LookupContext(ctx, "isbn:"+isbn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lookup book with ISBN %s: %w", isbn, apiError(err))
+	}
+	if len(books) == 0 {
+		return nil, notFoundError("no book found with ISBN %s", isbn)
+	}
+
+	book := mapReadarrBookToModels(books[0])
+	return &book, nil
+}
+
+// TriggerRefresh triggers a missing book search
+func (c *ReadarrClient) TriggerRefresh(ctx context.Context) error {
+	command := &readarr.CommandRequest{
+		Name: "MissingBookSearch",
+	}
+
+	if _, err := c.client.SendCommandContext(ctx, command); err != nil {
+		return fmt.Errorf("failed to trigger refresh: %w", apiError(err))
+	}
+
+	c.logger.Info("✅ Refresh triggered successfully")
+	return nil
+}
+
+// GetRootFolders returns all root folders from Readarr
+func (c *ReadarrClient) GetRootFolders(ctx context.Context) ([]models.RootFolder, error) {
+	rootFolders, err := c.client.GetRootFoldersContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch root folders: %w", apiError(err))
+	}
+
+	result := make([]models.RootFolder, len(rootFolders))
+	for i, rf := range rootFolders {
+		result[i] = mapReadarrRootFolderToModels(rf)
+	}
+	c.logger.Debug("Fetched %d root folders from Readarr", len(result))
+	return result, nil
+}
+
+// GetQualityProfiles returns all quality profiles from Readarr
+func (c *ReadarrClient) GetQualityProfiles(ctx context.Context) ([]models.QualityProfile, error) {
+	qualityProfiles, err := c.client.GetQualityProfilesContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch quality profiles: %w", apiError(err))
+	}
+
+	result := make([]models.QualityProfile, len(qualityProfiles))
+	for i, qp := range qualityProfiles {
+		result[i] = mapReadarrQualityProfileToModels(qp)
+	}
+	c.logger.Debug("Fetched %d quality profiles from Readarr", len(result))
+	return result, nil
+}
+
+// GetAllSeries is not applicable for Readarr (returns error)
+func (c *ReadarrClient) GetAllSeries(ctx context.Context) ([]models.Series, error) {
+	return nil, fmt.Errorf("GetAllSeries is not supported by Readarr client")
+}
+
+// GetAllMovies is not applicable for Readarr (returns error)
+func (c *ReadarrClient) GetAllMovies(ctx context.Context) ([]models.Movie, error) {
+	return nil, fmt.Errorf("GetAllMovies is not supported by Readarr client")
+}
+
+// GetMovie is not applicable for Readarr (returns error)
+func (c *ReadarrClient) GetMovie(ctx context.Context, movieID int) (*models.Movie, error) {
+	return nil, fmt.Errorf("GetMovie is not supported by Readarr client")
+}
+
+// GetEpisodesForSeries is not applicable for Readarr (returns error)
+func (c *ReadarrClient) GetEpisodesForSeries(ctx context.Context, seriesID int) ([]models.Episode, error) {
+	return nil, fmt.Errorf("GetEpisodesForSeries is not supported by Readarr client")
+}
+
+// GetEpisode is not applicable for Readarr (returns error)
+func (c *ReadarrClient) GetEpisode(ctx context.Context, episodeID int) (*models.Episode, error) {
+	return nil, fmt.Errorf("GetEpisode is not supported by Readarr client")
+}
+
+// GetEpisodeFile is not applicable for Readarr (returns error)
+func (c *ReadarrClient) GetEpisodeFile(ctx context.Context, fileID int) (*models.EpisodeFile, error) {
+	return nil, fmt.Errorf("GetEpisodeFile is not supported by Readarr client")
+}
+
+// DeleteEpisodeFile is not applicable for Readarr (returns error)
+func (c *ReadarrClient) DeleteEpisodeFile(ctx context.Context, fileID int) error {
+	return fmt.Errorf("DeleteEpisodeFile is not supported by Readarr client")
+}
+
+// UpdateEpisode is not applicable for Readarr (returns error)
+func (c *ReadarrClient) UpdateEpisode(ctx context.Context, episode models.Episode) error {
+	return fmt.Errorf("UpdateEpisode is not supported by Readarr client")
+}
+
+// GetMovieFile is not applicable for Readarr (returns error)
+func (c *ReadarrClient) GetMovieFile(ctx context.Context, fileID int) (*models.MovieFile, error) {
+	return nil, fmt.Errorf("GetMovieFile is not supported by Readarr client")
+}
+
+// DeleteMovieFile is not applicable for Readarr (returns error)
+func (c *ReadarrClient) DeleteMovieFile(ctx context.Context, fileID int) error {
+	return fmt.Errorf("DeleteMovieFile is not supported by Readarr client")
+}
+
+// UpdateMovie is not applicable for Readarr (returns error)
+func (c *ReadarrClient) UpdateMovie(ctx context.Context, movie models.Movie) error {
+	return fmt.Errorf("UpdateMovie is not supported by Readarr client")
+}
+
+// LookupMovieByTMDBID is not applicable for Readarr (returns error)
+func (c *ReadarrClient) LookupMovieByTMDBID(ctx context.Context, tmdbID int) (*models.MovieLookup, error) {
+	return nil, fmt.Errorf("LookupMovieByTMDBID is not supported by Readarr client")
+}
+
+// LookupMovieByIMDBID is not applicable for Readarr (returns error)
+func (c *ReadarrClient) LookupMovieByIMDBID(ctx context.Context, imdbID string) (*models.MovieLookup, error) {
+	return nil, fmt.Errorf("LookupMovieByIMDBID is not supported by Readarr client")
+}
+
+// LookupMoviesByTerm is not applicable for Readarr (returns error)
+func (c *ReadarrClient) LookupMoviesByTerm(ctx context.Context, term string) ([]models.MovieLookup, error) {
+	return nil, fmt.Errorf("LookupMoviesByTerm is not supported by Readarr client")
+}
+
+// AddMovie is not applicable for Readarr (returns error)
+func (c *ReadarrClient) AddMovie(ctx context.Context, movie models.Movie) (*models.Movie, error) {
+	return nil, fmt.Errorf("AddMovie is not supported by Readarr client")
+}
+
+// GetMovieByTMDBID is not applicable for Readarr (returns error)
+func (c *ReadarrClient) GetMovieByTMDBID(ctx context.Context, tmdbID int) (*models.Movie, error) {
+	return nil, fmt.Errorf("GetMovieByTMDBID is not supported by Readarr client")
+}
+
+// GetSeriesByTVDBID is not applicable for Readarr (returns error)
+func (c *ReadarrClient) GetSeriesByTVDBID(ctx context.Context, tvdbID int) (*models.Series, error) {
+	return nil, fmt.Errorf("GetSeriesByTVDBID is not supported by Readarr client")
+}
+
+// LookupSeriesByTVDBID is not applicable for Readarr (returns error)
+func (c *ReadarrClient) LookupSeriesByTVDBID(ctx context.Context, tvdbID int) (*models.SeriesLookup, error) {
+	return nil, fmt.Errorf("LookupSeriesByTVDBID is not supported by Readarr client")
+}
+
+// LookupSeriesByTerm is not applicable for Readarr (returns error)
+func (c *ReadarrClient) LookupSeriesByTerm(ctx context.Context, term string) ([]models.SeriesLookup, error) {
+	return nil, fmt.Errorf("LookupSeriesByTerm is not supported by Readarr client")
+}
+
+// AddSeries is not applicable for Readarr (returns error)
+func (c *ReadarrClient) AddSeries(ctx context.Context, series models.Series) (*models.Series, error) {
+	return nil, fmt.Errorf("AddSeries is not supported by Readarr client")
+}
+
+// GetQueue is not applicable for Readarr (returns error)
+func (c *ReadarrClient) GetQueue(ctx context.Context) ([]models.QueueItem, error) {
+	return nil, fmt.Errorf("GetQueue is not supported by Readarr client")
+}
+
+// GetQueueWithOptions is not applicable for Readarr (returns error)
+func (c *ReadarrClient) GetQueueWithOptions(ctx context.Context, opts models.QueueOptions) ([]models.QueueItem, error) {
+	return nil, fmt.Errorf("GetQueueWithOptions is not supported by Readarr client")
+}
+
+// GetQueueDetails is not applicable for Readarr (returns error)
+func (c *ReadarrClient) GetQueueDetails(ctx context.Context, queueID int) (*models.QueueItem, error) {
+	return nil, fmt.Errorf("GetQueueDetails is not supported by Readarr client")
+}
+
+// RemoveFromQueue is not applicable for Readarr (returns error)
+func (c *ReadarrClient) RemoveFromQueue(ctx context.Context, queueID int, removeFromClient bool) error {
+	return fmt.Errorf("RemoveFromQueue is not supported by Readarr client")
+}
+
+// TriggerDownloadClientScan is not applicable for Readarr (returns error)
+func (c *ReadarrClient) TriggerDownloadClientScan(ctx context.Context) error {
+	return fmt.Errorf("TriggerDownloadClientScan is not supported by Readarr client")
+}
+
+// GetManualImport is not applicable for Readarr (returns error)
+func (c *ReadarrClient) GetManualImport(ctx context.Context, folder string) ([]models.ManualImportItem, error) {
+	return nil, fmt.Errorf("GetManualImport is not supported by Readarr client")
+}
+
+// GetManualImportWithParams is not applicable for Readarr (returns error)
+func (c *ReadarrClient) GetManualImportWithParams(ctx context.Context, folder, downloadID string, seriesID int, filterExisting bool) ([]models.ManualImportItem, error) {
+	return nil, fmt.Errorf("GetManualImportWithParams is not supported by Readarr client")
+}
+
+// ExecuteManualImport is not applicable for Readarr (returns error)
+func (c *ReadarrClient) ExecuteManualImport(ctx context.Context, files []models.ManualImportItem, importMode string) error {
+	return fmt.Errorf("ExecuteManualImport is not supported by Readarr client")
+}
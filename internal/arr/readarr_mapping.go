@@ -0,0 +1,82 @@
+package arr
+
+import (
+	"github.com/hnipps/refresharr/pkg/models"
+	"golift.io/starr/readarr"
+)
+
+// mapReadarrBookToModels converts a starr Book to our models.Book
+func mapReadarrBookToModels(b *readarr.Book) models.Book {
+	if b == nil {
+		return models.Book{}
+	}
+
+	result := models.Book{
+		MediaItem: models.MediaItem{
+			ID:    int(b.ID),
+			Title: b.Title,
+		},
+		ForeignBookID: b.ForeignBookID,
+		Monitored:     b.Monitored,
+	}
+	if b.Author != nil {
+		result.AuthorName = b.Author.AuthorName
+		result.Path = b.Author.Path
+	}
+	if b.Statistics != nil {
+		bookFileCount := b.Statistics.BookFileCount
+		result.BookFileCount = &bookFileCount
+	}
+	return result
+}
+
+// mapReadarrBooksToModelsList converts a slice of starr Books to models.Book
+func mapReadarrBooksToModelsList(books []*readarr.Book) []models.Book {
+	result := make([]models.Book, len(books))
+	for i, b := range books {
+		result[i] = mapReadarrBookToModels(b)
+	}
+	return result
+}
+
+// mapReadarrBookFileToModels converts a starr BookFile to our models.BookFile
+func mapReadarrBookFileToModels(bf *readarr.BookFile) models.BookFile {
+	if bf == nil {
+		return models.BookFile{}
+	}
+
+	return models.BookFile{
+		ID:      int(bf.ID),
+		Path:    bf.Path,
+		BookID:  int(bf.BookID),
+		Size:    int64(bf.Size),
+		Quality: mapFileQuality(bf.Quality),
+	}
+}
+
+// mapReadarrRootFolderToModels converts a starr RootFolder to our models.RootFolder
+func mapReadarrRootFolderToModels(rf *readarr.RootFolder) models.RootFolder {
+	if rf == nil {
+		return models.RootFolder{}
+	}
+
+	accessible := rf.Accessible
+	return models.RootFolder{
+		ID:         int(rf.ID),
+		Path:       rf.Path,
+		Name:       rf.Name,
+		Accessible: &accessible,
+	}
+}
+
+// mapReadarrQualityProfileToModels converts a starr QualityProfile to our models.QualityProfile
+func mapReadarrQualityProfileToModels(qp *readarr.QualityProfile) models.QualityProfile {
+	if qp == nil {
+		return models.QualityProfile{}
+	}
+
+	return models.QualityProfile{
+		ID:   int(qp.ID),
+		Name: qp.Name,
+	}
+}
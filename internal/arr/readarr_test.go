@@ -0,0 +1,118 @@
+package arr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hnipps/refresharr/internal/config"
+)
+
+func TestNewReadarrClient(t *testing.T) {
+	cfg := &config.ReadarrConfig{
+		URL:    "http://test:8787",
+		APIKey: "test-key",
+	}
+
+	client := NewReadarrClient(cfg, 30*time.Second, &mockLogger{})
+	if client == nil {
+		t.Fatal("NewReadarrClient() returned nil")
+	}
+	if client.GetName() != "readarr" {
+		t.Errorf("Expected name 'readarr', got '%s'", client.GetName())
+	}
+	if _, ok := client.(BookClient); !ok {
+		t.Error("Readarr client should implement BookClient")
+	}
+}
+
+func TestReadarrClient_GetAllBooks_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/book" {
+			t.Errorf("Expected path '/api/v1/book', got '%s'", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"id":1,"title":"Dune","foreignBookId":"234225","monitored":true,
+			"author":{"authorName":"Frank Herbert","path":"/books/Frank Herbert"},
+			"statistics":{"bookFileCount":2}}]`))
+	}))
+	defer server.Close()
+
+	client := NewReadarrClient(&config.ReadarrConfig{URL: server.URL, APIKey: "test-key"}, 30*time.Second, &mockLogger{})
+	books, err := client.(BookClient).GetAllBooks(context.Background())
+	if err != nil {
+		t.Fatalf("GetAllBooks() failed: %v", err)
+	}
+
+	if len(books) != 1 {
+		t.Fatalf("Expected 1 book, got %d", len(books))
+	}
+	book := books[0]
+	if book.Title != "Dune" || book.AuthorName != "Frank Herbert" || book.Path != "/books/Frank Herbert" {
+		t.Errorf("Unexpected book: %+v", book)
+	}
+	if book.GoodreadsID() != 234225 {
+		t.Errorf("Expected Goodreads ID 234225, got %d", book.GoodreadsID())
+	}
+	if book.BookFileCount == nil || *book.BookFileCount != 2 {
+		t.Errorf("Expected 2 book files, got %v", book.BookFileCount)
+	}
+}
+
+func TestReadarrClient_GetBookFiles_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/bookfile" {
+			t.Errorf("Expected path '/api/v1/bookfile', got '%s'", r.URL.Path)
+		}
+		if r.URL.Query().Get("bookId") != "1" {
+			t.Errorf("Expected bookId 1, got '%s'", r.URL.Query().Get("bookId"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"id":10,"bookId":1,"path":"/books/Frank Herbert/Dune.epub","size":1024,
+			"quality":{"quality":{"id":3,"name":"EPUB"}}}]`))
+	}))
+	defer server.Close()
+
+	client := NewReadarrClient(&config.ReadarrConfig{URL: server.URL, APIKey: "test-key"}, 30*time.Second, &mockLogger{})
+	files, err := client.(BookClient).GetBookFiles(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("GetBookFiles() failed: %v", err)
+	}
+
+	if len(files) != 1 {
+		t.Fatalf("Expected 1 book file, got %d", len(files))
+	}
+	file := files[0]
+	if file.ID != 10 || file.BookID != 1 || file.Path != "/books/Frank Herbert/Dune.epub" || file.Size != 1024 {
+		t.Errorf("Unexpected book file: %+v", file)
+	}
+	if file.Quality.Name() != "EPUB" {
+		t.Errorf("Expected quality 'EPUB', got '%s'", file.Quality.Name())
+	}
+}
+
+func TestReadarrClient_DeleteBookFile_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete || r.URL.Path != "/api/v1/bookfile/10" {
+			t.Errorf("Expected DELETE /api/v1/bookfile/10, got %s %s", r.Method, r.URL.Path)
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewReadarrClient(&config.ReadarrConfig{URL: server.URL, APIKey: "test-key"}, 30*time.Second, &mockLogger{})
+	err := client.(BookClient).DeleteBookFile(context.Background(), 10)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("DeleteBookFile() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestReadarrClient_UnsupportedMethods(t *testing.T) {
+	client := NewReadarrClient(&config.ReadarrConfig{URL: "http://test:8787", APIKey: "test-key"}, 30*time.Second, &mockLogger{})
+	if _, err := client.GetAllMovies(context.Background()); err == nil {
+		t.Error("GetAllMovies() should not be supported by the Readarr client")
+	}
+}
@@ -0,0 +1,160 @@
+package arr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+// ErrReadOnly is returned by read-only wrappers when a write is attempted
+var ErrReadOnly = errors.New("write blocked: running in read-only verify mode")
+
+// readOnlyClient wraps a Client and refuses every call that would change the *arr instance.
+// Verify runs use it so that no code path can write, even one that ignores the dry-run flag.
+type readOnlyClient struct {
+	Client
+}
+
+// NewReadOnlyClient returns a Client that passes reads through and rejects all writes with ErrReadOnly
+func NewReadOnlyClient(client Client) Client {
+	return &readOnlyClient{Client: client}
+}
+
+func (c *readOnlyClient) DeleteEpisodeFile(ctx context.Context, fileID int) error {
+	return ErrReadOnly
+}
+
+func (c *readOnlyClient) UpdateEpisode(ctx context.Context, episode models.Episode) error {
+	return ErrReadOnly
+}
+
+func (c *readOnlyClient) DeleteMovieFile(ctx context.Context, fileID int) error {
+	return ErrReadOnly
+}
+
+func (c *readOnlyClient) UpdateMovie(ctx context.Context, movie models.Movie) error {
+	return ErrReadOnly
+}
+
+func (c *readOnlyClient) AddMovie(ctx context.Context, movie models.Movie) (*models.Movie, error) {
+	return nil, ErrReadOnly
+}
+
+func (c *readOnlyClient) AddSeries(ctx context.Context, series models.Series) (*models.Series, error) {
+	return nil, ErrReadOnly
+}
+
+func (c *readOnlyClient) TriggerRefresh(ctx context.Context) error {
+	return ErrReadOnly
+}
+
+func (c *readOnlyClient) RemoveFromQueue(ctx context.Context, queueID int, removeFromClient bool) error {
+	return ErrReadOnly
+}
+
+func (c *readOnlyClient) TriggerDownloadClientScan(ctx context.Context) error {
+	return ErrReadOnly
+}
+
+func (c *readOnlyClient) ExecuteManualImport(ctx context.Context, files []models.ManualImportItem, importMode string) error {
+	return ErrReadOnly
+}
+
+// MediaPath passes through to the wrapped client when it can update paths, so verify runs can
+// plan path rewrites
+func (c *readOnlyClient) MediaPath(ctx context.Context, id int) (string, error) {
+	updater, ok := c.Client.(PathUpdater)
+	if !ok {
+		return "", fmt.Errorf("%s can't update media paths", c.GetName())
+	}
+	return updater.MediaPath(ctx, id)
+}
+
+func (c *readOnlyClient) UpdateMediaPath(ctx context.Context, id int, path string) error {
+	return ErrReadOnly
+}
+
+// FindTag and TaggedMedia pass through to the wrapped client when it can tag, so verify runs can
+// report the tagging a real run would do
+func (c *readOnlyClient) FindTag(ctx context.Context, label string) (int, bool, error) {
+	tagger, ok := c.Client.(Tagger)
+	if !ok {
+		return 0, false, fmt.Errorf("%s can't tag media", c.GetName())
+	}
+	return tagger.FindTag(ctx, label)
+}
+
+func (c *readOnlyClient) TaggedMedia(ctx context.Context, tagID int) ([]int, error) {
+	tagger, ok := c.Client.(Tagger)
+	if !ok {
+		return nil, fmt.Errorf("%s can't tag media", c.GetName())
+	}
+	return tagger.TaggedMedia(ctx, tagID)
+}
+
+func (c *readOnlyClient) EnsureTag(ctx context.Context, label string) (int, error) {
+	return 0, ErrReadOnly
+}
+
+func (c *readOnlyClient) AddMediaTag(ctx context.Context, id, tagID int) error {
+	return ErrReadOnly
+}
+
+func (c *readOnlyClient) RemoveMediaTag(ctx context.Context, id, tagID int) error {
+	return ErrReadOnly
+}
+
+// GetAllBooks, GetBookFiles and LookupBookByISBN pass through to the wrapped client when it
+// manages books, so verify runs can check Readarr's files
+func (c *readOnlyClient) GetAllBooks(ctx context.Context) ([]models.Book, error) {
+	books, ok := c.Client.(BookClient)
+	if !ok {
+		return nil, fmt.Errorf("%s doesn't manage books", c.GetName())
+	}
+	return books.GetAllBooks(ctx)
+}
+
+func (c *readOnlyClient) GetBookFiles(ctx context.Context, bookID int) ([]models.BookFile, error) {
+	books, ok := c.Client.(BookClient)
+	if !ok {
+		return nil, fmt.Errorf("%s doesn't manage books", c.GetName())
+	}
+	return books.GetBookFiles(ctx, bookID)
+}
+
+func (c *readOnlyClient) LookupBookByISBN(ctx context.Context, isbn string) (*models.Book, error) {
+	books, ok := c.Client.(BookClient)
+	if !ok {
+		return nil, fmt.Errorf("%s doesn't manage books", c.GetName())
+	}
+	return books.LookupBookByISBN(ctx, isbn)
+}
+
+func (c *readOnlyClient) DeleteBookFile(ctx context.Context, fileID int) error {
+	return ErrReadOnly
+}
+
+// readOnlyFileChecker wraps a FileChecker and refuses to delete anything
+type readOnlyFileChecker struct {
+	FileChecker
+}
+
+// NewReadOnlyFileChecker returns a FileChecker that can inspect files but never deletes them
+func NewReadOnlyFileChecker(fileChecker FileChecker) FileChecker {
+	return &readOnlyFileChecker{FileChecker: fileChecker}
+}
+
+func (f *readOnlyFileChecker) DeleteSymlink(path string) error {
+	return ErrReadOnly
+}
+
+// FileSHA256 passes the question on to the wrapped checker, if it can answer it
+func (f *readOnlyFileChecker) FileSHA256(path string) (string, error) {
+	hasher, ok := f.FileChecker.(FileHasher)
+	if !ok {
+		return "", fmt.Errorf("file checker can't compute checksums")
+	}
+	return hasher.FileSHA256(path)
+}
@@ -0,0 +1,225 @@
+package arr
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+func TestReadOnlyClient_BlocksWrites(t *testing.T) {
+	inner := &mockClient{name: "sonarr"}
+	client := NewReadOnlyClient(inner)
+	ctx := context.Background()
+
+	if client.GetName() != "sonarr" {
+		t.Errorf("Expected reads to pass through, got name %q", client.GetName())
+	}
+
+	writes := map[string]error{
+		"DeleteEpisodeFile":         client.DeleteEpisodeFile(ctx, 1),
+		"UpdateEpisode":             client.UpdateEpisode(ctx, models.Episode{}),
+		"DeleteMovieFile":           client.DeleteMovieFile(ctx, 1),
+		"UpdateMovie":               client.UpdateMovie(ctx, models.Movie{}),
+		"TriggerRefresh":            client.TriggerRefresh(ctx),
+		"RemoveFromQueue":           client.RemoveFromQueue(ctx, 1, true),
+		"TriggerDownloadClientScan": client.TriggerDownloadClientScan(ctx),
+		"ExecuteManualImport":       client.ExecuteManualImport(ctx, nil, "move"),
+	}
+	_, writes["AddMovie"] = client.AddMovie(ctx, models.Movie{})
+	_, writes["AddSeries"] = client.AddSeries(ctx, models.Series{})
+	tagger := client.(Tagger)
+	_, writes["EnsureTag"] = tagger.EnsureTag(ctx, "refresharr-cleaned")
+	writes["AddMediaTag"] = tagger.AddMediaTag(ctx, 1, 1)
+	writes["RemoveMediaTag"] = tagger.RemoveMediaTag(ctx, 1, 1)
+
+	for name, err := range writes {
+		if !errors.Is(err, ErrReadOnly) {
+			t.Errorf("Expected %s to return ErrReadOnly, got %v", name, err)
+		}
+	}
+	if len(inner.deletedFileIDs) != 0 || inner.refreshTriggered != 0 {
+		t.Error("Expected no writes to reach the wrapped client")
+	}
+
+	fileChecker := NewReadOnlyFileChecker(&mockFileChecker{})
+	if err := fileChecker.DeleteSymlink("/tv/show/symlink.mkv"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected DeleteSymlink to return ErrReadOnly, got %v", err)
+	}
+}
+
+func TestCleanupService_VerifyOnly(t *testing.T) {
+	client := &mockClient{
+		name: "sonarr",
+		episodes: map[int][]models.Episode{
+			1: {
+				{ID: 1, SeriesID: 1, SeasonNumber: 1, EpisodeNumber: 1, HasFile: true, EpisodeFileID: intPtr(100)},
+				{ID: 2, SeriesID: 1, SeasonNumber: 1, EpisodeNumber: 2, HasFile: true, EpisodeFileID: intPtr(200)},
+				{ID: 3, SeriesID: 1, SeasonNumber: 1, EpisodeNumber: 3, HasFile: true, EpisodeFileID: intPtr(300)},
+			},
+		},
+		episodeFiles: map[int]*models.EpisodeFile{
+			100: {ID: 100, Path: "/tv/show/s01e01.mkv", Size: 1000},
+			200: {ID: 200, Path: "/tv/show/s01e02.mkv", Size: 2000},
+			300: {ID: 300, Path: "/tv/show/s01e03.mkv", Size: 3000},
+		},
+	}
+	// s01e01 matches, s01e02 was truncated and s01e03 is missing
+	fileChecker := &mockFileChecker{
+		fileExists: map[string]bool{"/tv/show/s01e01.mkv": true, "/tv/show/s01e02.mkv": true},
+		sizes:      map[string]int64{"/tv/show/s01e01.mkv": 1000, "/tv/show/s01e02.mkv": 512},
+	}
+
+	service := NewCleanupServiceWithOptions(client, fileChecker, &mockLogger{}, &mockProgressReporter{}, CleanupOptions{
+		ConcurrentLimit: 1,
+		VerifyOnly:      true,
+	})
+
+	result, err := service.CleanupMissingFilesForSeries(context.Background(), []int{1})
+	if err != nil {
+		t.Fatalf("CleanupMissingFilesForSeries() failed: %v", err)
+	}
+
+	if len(client.deletedFileIDs) != 0 || len(client.updatedEpisodes) != 0 || client.refreshTriggered != 0 {
+		t.Errorf("Expected no writes, got deletes=%v updates=%d refreshes=%d",
+			client.deletedFileIDs, len(client.updatedEpisodes), client.refreshTriggered)
+	}
+	if result.Stats.MissingFiles != 1 || result.Stats.SizeMismatches != 1 {
+		t.Errorf("Expected 1 missing file and 1 size mismatch, got %+v", result.Stats)
+	}
+
+	if result.Report == nil || result.Report.RunType != "verify" {
+		t.Fatalf("Expected a verify report, got %+v", result.Report)
+	}
+	var mismatch, missing *models.MissingFileEntry
+	for i := range result.Report.MissingFiles {
+		if result.Report.MissingFiles[i].Issue == models.IssueSizeMismatch {
+			mismatch = &result.Report.MissingFiles[i]
+		} else {
+			missing = &result.Report.MissingFiles[i]
+		}
+	}
+	if mismatch == nil || mismatch.FilePath != "/tv/show/s01e02.mkv" || mismatch.ExpectedSize != 2000 || mismatch.ActualSize != 512 {
+		t.Errorf("Expected size mismatch entry for s01e02, got %+v", mismatch)
+	}
+	if mismatch != nil && mismatch.Reason != models.ReasonSizeMismatch {
+		t.Errorf("Expected reason %s for the size mismatch, got %q", models.ReasonSizeMismatch, mismatch.Reason)
+	}
+	if missing == nil || missing.Reason != models.ReasonNotFound {
+		t.Errorf("Expected missing entry for s01e03 with reason %s, got %+v", models.ReasonNotFound, missing)
+	}
+}
+
+// hashingFileChecker is a mockFileChecker that can checksum files
+type hashingFileChecker struct {
+	*mockFileChecker
+	sums map[string]string
+}
+
+func (c *hashingFileChecker) FileSHA256(path string) (string, error) {
+	sum, ok := c.sums[path]
+	if !ok {
+		return "", errors.New("no such file")
+	}
+	return sum, nil
+}
+
+func TestCleanupService_VerifyChecksums(t *testing.T) {
+	client := &mockClient{
+		name: "sonarr",
+		episodes: map[int][]models.Episode{
+			1: {
+				{ID: 1, SeriesID: 1, SeasonNumber: 1, EpisodeNumber: 1, HasFile: true, EpisodeFileID: intPtr(100)},
+				{ID: 2, SeriesID: 1, SeasonNumber: 1, EpisodeNumber: 2, HasFile: true, EpisodeFileID: intPtr(200)},
+				{ID: 3, SeriesID: 1, SeasonNumber: 1, EpisodeNumber: 3, HasFile: true, EpisodeFileID: intPtr(300)},
+			},
+		},
+		episodeFiles: map[int]*models.EpisodeFile{
+			100: {ID: 100, Path: "/tv/show/s01e01.mkv", Size: 1000},
+			200: {ID: 200, Path: "/tv/show/s01e02.mkv", Size: 2000},
+			300: {ID: 300, Path: "/tv/show/s01e03.mkv", Size: 3000},
+		},
+	}
+	// s01e01 matches, s01e02 has rotted and s01e03 isn't in the manifest
+	fileChecker := &hashingFileChecker{
+		mockFileChecker: &mockFileChecker{
+			fileExists: map[string]bool{"/tv/show/s01e01.mkv": true, "/tv/show/s01e02.mkv": true, "/tv/show/s01e03.mkv": true},
+			sizes:      map[string]int64{"/tv/show/s01e01.mkv": 1000, "/tv/show/s01e02.mkv": 2000, "/tv/show/s01e03.mkv": 3000},
+		},
+		sums: map[string]string{"/tv/show/s01e01.mkv": "aaaa", "/tv/show/s01e02.mkv": "bbbb", "/tv/show/s01e03.mkv": "cccc"},
+	}
+	checksums := map[string]string{"/tv/show/s01e01.mkv": "AAAA", "/tv/show/s01e02.mkv": "ffff"}
+
+	service := NewCleanupServiceWithOptions(client, fileChecker, &mockLogger{}, &mockProgressReporter{}, CleanupOptions{
+		ConcurrentLimit: 1,
+		VerifyOnly:      true,
+		Checksums:       checksums,
+	})
+
+	result, err := service.CleanupMissingFilesForSeries(context.Background(), []int{1})
+	if err != nil {
+		t.Fatalf("CleanupMissingFilesForSeries() failed: %v", err)
+	}
+
+	if result.Stats.Corrupt != 1 || result.Stats.MissingFiles != 0 || result.Stats.SizeMismatches != 0 {
+		t.Errorf("Expected 1 corrupt file, got %+v", result.Stats)
+	}
+	if len(result.Report.MissingFiles) != 1 {
+		t.Fatalf("Expected 1 report entry, got %+v", result.Report.MissingFiles)
+	}
+	entry := result.Report.MissingFiles[0]
+	if entry.FilePath != "/tv/show/s01e02.mkv" || entry.Issue != models.IssueChecksumMismatch || entry.Reason != models.ReasonCorrupt {
+		t.Errorf("Expected a checksum mismatch entry for s01e02, got %+v", entry)
+	}
+	if entry.ExpectedSHA256 != "ffff" || entry.ActualSHA256 != "bbbb" {
+		t.Errorf("Expected both checksums in the entry, got %+v", entry)
+	}
+
+	// Cleanup runs don't read file contents
+	service = NewCleanupServiceWithOptions(client, fileChecker, &mockLogger{}, &mockProgressReporter{}, CleanupOptions{
+		ConcurrentLimit: 1,
+		DryRun:          true,
+		Checksums:       checksums,
+	})
+	result, err = service.CleanupMissingFilesForSeries(context.Background(), []int{1})
+	if err != nil {
+		t.Fatalf("CleanupMissingFilesForSeries() failed: %v", err)
+	}
+	if result.Stats.Corrupt != 0 {
+		t.Errorf("Expected checksums to be checked only in verify runs, got %+v", result.Stats)
+	}
+}
+
+func TestCleanupService_VerifyChecksumsOfUncleanPaths(t *testing.T) {
+	// The record's path has a doubled slash and a ./ that the manifest's cleaned path doesn't
+	client := &mockClient{
+		name: "sonarr",
+		episodes: map[int][]models.Episode{
+			1: {{ID: 1, SeriesID: 1, SeasonNumber: 1, EpisodeNumber: 1, HasFile: true, EpisodeFileID: intPtr(100)}},
+		},
+		episodeFiles: map[int]*models.EpisodeFile{
+			100: {ID: 100, Path: "/tv//show/./s01e01.mkv", Size: 1000},
+		},
+	}
+	fileChecker := &hashingFileChecker{
+		mockFileChecker: &mockFileChecker{
+			fileExists: map[string]bool{"/tv//show/./s01e01.mkv": true},
+			sizes:      map[string]int64{"/tv//show/./s01e01.mkv": 1000},
+		},
+		sums: map[string]string{"/tv//show/./s01e01.mkv": "bbbb"},
+	}
+
+	service := NewCleanupServiceWithOptions(client, fileChecker, &mockLogger{}, &mockProgressReporter{}, CleanupOptions{
+		ConcurrentLimit: 1,
+		VerifyOnly:      true,
+		Checksums:       map[string]string{"/tv/show/s01e01.mkv": "ffff"},
+	})
+	result, err := service.CleanupMissingFilesForSeries(context.Background(), []int{1})
+	if err != nil {
+		t.Fatalf("CleanupMissingFilesForSeries() failed: %v", err)
+	}
+	if result.Stats.Corrupt != 1 {
+		t.Errorf("Expected the file to be checked against the manifest, got %+v", result.Stats)
+	}
+}
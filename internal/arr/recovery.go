@@ -0,0 +1,81 @@
+package arr
+
+import (
+	"sort"
+	"time"
+
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+// newMissingBefore indexes the files earlier runs found missing by their recovery key
+func newMissingBefore(entries []models.MissingFileEntry) map[string]models.MissingFileEntry {
+	if len(entries) == 0 {
+		return nil
+	}
+	missing := make(map[string]models.MissingFileEntry, len(entries))
+	for _, entry := range entries {
+		if key := entry.RecoveryKey(); key != "" {
+			missing[key] = entry
+		}
+	}
+	return missing
+}
+
+// markRecovered records a file that passed verification as recovered when an earlier run found
+// its episode or movie missing. current only needs the fields RecoveryKey looks at and the path.
+func (s *CleanupServiceImpl) markRecovered(logger Logger, current models.MissingFileEntry) {
+	if len(s.missingBefore) == 0 {
+		return
+	}
+	key := current.RecoveryKey()
+	if key == "" {
+		return
+	}
+
+	s.recoveredMu.Lock()
+	defer s.recoveredMu.Unlock()
+	previous, ok := s.missingBefore[key]
+	if !ok {
+		return
+	}
+	delete(s.missingBefore, key)
+
+	logger.Info("    ♻️  Recovered since it went missing on %s: %s", previous.ProcessedAt, current.FilePath)
+	s.recovered = append(s.recovered, models.RecoveredFileEntry{
+		MediaType:    current.MediaType,
+		MediaName:    current.MediaName,
+		EpisodeName:  current.EpisodeName,
+		Season:       current.Season,
+		Episode:      current.Episode,
+		TMDBID:       current.TMDBID,
+		FilePath:     current.FilePath,
+		MissingPath:  previous.FilePath,
+		MissingSince: previous.ProcessedAt,
+		RecoveredAt:  time.Now().Format(time.RFC3339),
+	})
+}
+
+// recoveredFiles returns the recovered files ordered by title, then season and episode
+func (s *CleanupServiceImpl) recoveredFiles() []models.RecoveredFileEntry {
+	s.recoveredMu.Lock()
+	defer s.recoveredMu.Unlock()
+	if len(s.recovered) == 0 {
+		return nil
+	}
+
+	recovered := append([]models.RecoveredFileEntry(nil), s.recovered...)
+	sort.Slice(recovered, func(i, j int) bool {
+		a, b := recovered[i], recovered[j]
+		if a.MediaName != b.MediaName {
+			return a.MediaName < b.MediaName
+		}
+		if a.Season != nil && b.Season != nil && *a.Season != *b.Season {
+			return *a.Season < *b.Season
+		}
+		if a.Episode != nil && b.Episode != nil {
+			return *a.Episode < *b.Episode
+		}
+		return false
+	})
+	return recovered
+}
@@ -0,0 +1,56 @@
+package arr
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+func TestCleanupService_ReportsRecoveredFiles(t *testing.T) {
+	season, episode, other := 1, 1, 2
+	client := &mockClient{
+		name:      "sonarr",
+		allSeries: []models.Series{{MediaItem: models.MediaItem{ID: 1, Title: "Show"}}},
+		episodes: map[int][]models.Episode{
+			1: {
+				{ID: 1, SeriesID: 1, SeasonNumber: 1, EpisodeNumber: 1, Title: "Pilot", HasFile: true, EpisodeFileID: intPtr(100)},
+				{ID: 2, SeriesID: 1, SeasonNumber: 1, EpisodeNumber: 2, HasFile: true, EpisodeFileID: intPtr(200)},
+			},
+		},
+		episodeFiles: map[int]*models.EpisodeFile{
+			100: {ID: 100, Path: "/tv/Show/Season 01/Show - S01E01 - Pilot WEBDL-1080p.mkv"},
+			200: {ID: 200, Path: "/tv/Show/Season 01/Show - S01E02.mkv"},
+		},
+	}
+	fileChecker := &mockFileChecker{fileExists: map[string]bool{
+		"/tv/Show/Season 01/Show - S01E01 - Pilot WEBDL-1080p.mkv": true,
+		"/tv/Show/Season 01/Show - S01E02.mkv":                     false,
+	}}
+
+	// Both episodes were missing before; only the re-downloaded one has recovered
+	service := NewCleanupServiceWithOptions(client, fileChecker, &mockLogger{}, &mockProgressReporter{}, CleanupOptions{
+		ConcurrentLimit: 1,
+		DryRun:          true,
+		PreviouslyMissing: []models.MissingFileEntry{
+			{MediaType: "series", MediaName: "Show", Season: &season, Episode: &episode, FilePath: "/tv/Show/Season 01/Show - S01E01 - Pilot HDTV.mkv", ProcessedAt: "2024-01-01T00:00:00Z"},
+			{MediaType: "series", MediaName: "Show", Season: &season, Episode: &other, FilePath: "/tv/Show/Season 01/Show - S01E02.mkv", ProcessedAt: "2024-01-01T00:00:00Z"},
+		},
+	})
+
+	result, err := service.CleanupMissingFiles(context.Background())
+	if err != nil {
+		t.Fatalf("CleanupMissingFiles() error = %v", err)
+	}
+	recovered := result.Report.Recovered
+	if len(recovered) != 1 {
+		t.Fatalf("Expected 1 recovered file, got %+v", recovered)
+	}
+	if r := recovered[0]; r.FilePath != "/tv/Show/Season 01/Show - S01E01 - Pilot WEBDL-1080p.mkv" ||
+		r.MissingPath != "/tv/Show/Season 01/Show - S01E01 - Pilot HDTV.mkv" || r.MissingSince != "2024-01-01T00:00:00Z" || r.EpisodeName != "Pilot" {
+		t.Errorf("Expected S01E01 to be recovered with its new file, got %+v", r)
+	}
+	if result.Report.TotalMissing != 1 {
+		t.Errorf("Expected S01E02 to still be missing, got %d missing", result.Report.TotalMissing)
+	}
+}
@@ -0,0 +1,78 @@
+package arr
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+// FileOpCounter is implemented by file checkers that count the filesystem calls they make
+type FileOpCounter interface {
+	// FileOps returns the number of stat, open and readdir calls made since the checker was created
+	FileOps() int64
+}
+
+// ResourceMonitor samples the process's memory and goroutine count in the background and keeps
+// the peaks, for sizing the machines runs happen on. Spikes shorter than the sampling interval
+// can be missed.
+type ResourceMonitor struct {
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+
+	mu             sync.Mutex
+	peakMemory     uint64
+	peakGoroutines int
+}
+
+// StartResourceMonitor starts sampling every interval until Stop is called
+func StartResourceMonitor(interval time.Duration) *ResourceMonitor {
+	m := &ResourceMonitor{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	m.sample()
+
+	go func() {
+		defer close(m.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				m.sample()
+			case <-m.stop:
+				return
+			}
+		}
+	}()
+	return m
+}
+
+// sample records the current memory and goroutine count if they are new peaks. Memory is what
+// the Go runtime holds from the OS and hasn't given back, which is close to the resident size.
+func (m *ResourceMonitor) sample() {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	memory := stats.Sys - stats.HeapReleased
+	goroutines := runtime.NumGoroutine()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.peakMemory = max(m.peakMemory, memory)
+	m.peakGoroutines = max(m.peakGoroutines, goroutines)
+}
+
+// Stop ends the sampling, takes a last sample and returns the peak memory in bytes and the
+// peak goroutine count. The sampling goroutine itself is counted.
+func (m *ResourceMonitor) Stop() (uint64, int) {
+	m.stopOnce.Do(func() {
+		close(m.stop)
+		<-m.done
+		m.sample()
+	})
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.peakMemory, m.peakGoroutines
+}
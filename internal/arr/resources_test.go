@@ -0,0 +1,38 @@
+package arr
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestResourceMonitor(t *testing.T) {
+	monitor := StartResourceMonitor(time.Millisecond)
+
+	// Hold a burst of goroutines long enough to be sampled
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-release
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	peakMemory, peakGoroutines := monitor.Stop()
+	if peakMemory == 0 {
+		t.Error("Expected a peak memory above 0")
+	}
+	if peakGoroutines < 50 {
+		t.Errorf("Expected at least 50 peak goroutines, got %d", peakGoroutines)
+	}
+
+	// Stopping again returns the same peaks
+	if memory, goroutines := monitor.Stop(); memory != peakMemory || goroutines != peakGoroutines {
+		t.Errorf("Second Stop() = %d, %d, want %d, %d", memory, goroutines, peakMemory, peakGoroutines)
+	}
+}
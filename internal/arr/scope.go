@@ -0,0 +1,171 @@
+package arr
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+// ActionScope restricts a run to the items enumerated in a previously reviewed
+// dry-run artifact (either an actions file or a missing files report)
+type ActionScope struct {
+	Source   string // Path of the file the scope was loaded from
+	Service  string // Service the artifact was generated for ("sonarr", "radarr" or "readarr")
+	Instance string // Extra instance of the service the artifact was generated for (empty for the default one)
+
+	episodeFiles map[int]bool
+	movieFiles   map[int]bool
+	bookFiles    map[int]bool
+	symlinks     map[string]bool
+	movies       map[int]bool // TMDB IDs that may be added
+	series       map[int]bool // TVDB IDs that may be added
+	queueItems   map[int]bool
+	pathUpdates  map[string]bool // Series and movies whose folder may change, see pathUpdateKey
+	rejected     map[string]bool // Rejected downloads that may be deleted, by path
+}
+
+// scopeFile holds the fields shared by actions files and missing files reports
+type scopeFile struct {
+	ServiceType  string                    `json:"serviceType"`
+	Instance     string                    `json:"instance"`
+	Actions      []models.PlannedAction    `json:"actions"`
+	MissingFiles []models.MissingFileEntry `json:"missingFiles"`
+}
+
+// LoadActionScope reads a dry-run actions file or missing files report and builds a scope from it
+func LoadActionScope(path string) (*ActionScope, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var file scopeFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+
+	if file.Actions == nil && file.MissingFiles == nil {
+		return nil, fmt.Errorf("%s is neither an actions file nor a missing files report", path)
+	}
+
+	scope := newActionScope(path, file.ServiceType)
+	scope.Instance = file.Instance
+	for _, action := range file.Actions {
+		scope.addAction(action)
+	}
+	for _, entry := range file.MissingFiles {
+		scope.addMissingFile(entry)
+	}
+
+	return scope, nil
+}
+
+// newActionScope creates an empty scope
+func newActionScope(source, service string) *ActionScope {
+	return &ActionScope{
+		Source:       source,
+		Service:      service,
+		episodeFiles: make(map[int]bool),
+		movieFiles:   make(map[int]bool),
+		bookFiles:    make(map[int]bool),
+		symlinks:     make(map[string]bool),
+		movies:       make(map[int]bool),
+		series:       make(map[int]bool),
+		queueItems:   make(map[int]bool),
+		pathUpdates:  make(map[string]bool),
+		rejected:     make(map[string]bool),
+	}
+}
+
+// addAction adds a planned action from an actions file to the scope
+func (s *ActionScope) addAction(action models.PlannedAction) {
+	switch action.Action {
+	case models.ActionDeleteEpisodeFile:
+		s.episodeFiles[action.FileID] = true
+	case models.ActionDeleteMovieFile:
+		s.movieFiles[action.FileID] = true
+	case models.ActionDeleteBookFile:
+		s.bookFiles[action.FileID] = true
+	case models.ActionDeleteSymlink:
+		s.symlinks[action.Path] = true
+	case models.ActionAddMovie:
+		s.movies[action.TMDBID] = true
+	case models.ActionAddSeries:
+		s.series[action.TVDBID] = true
+	case models.ActionImportQueueItem:
+		s.queueItems[action.QueueItemID] = true
+	case models.ActionUpdatePath:
+		s.pathUpdates[pathUpdateKey(action)] = true
+	case models.ActionDeleteRejectedFile:
+		s.rejected[action.Path] = true
+	}
+}
+
+// addMissingFile adds a missing files report entry to the scope
+func (s *ActionScope) addMissingFile(entry models.MissingFileEntry) {
+	if entry.FileID > 0 {
+		switch entry.MediaType {
+		case "series":
+			s.episodeFiles[entry.FileID] = true
+		case "book":
+			s.bookFiles[entry.FileID] = true
+		default:
+			s.movieFiles[entry.FileID] = true
+		}
+		return
+	}
+
+	// Entries without a file ID come from broken symlinks
+	s.symlinks[entry.FilePath] = true
+	if entry.TMDBID > 0 {
+		s.movies[entry.TMDBID] = true
+	}
+	if entry.TVDBID > 0 {
+		s.series[entry.TVDBID] = true
+	}
+}
+
+// Allows reports whether an action was enumerated in the scope. A nil scope allows everything.
+func (s *ActionScope) Allows(action models.PlannedAction) bool {
+	if s == nil {
+		return true
+	}
+
+	switch action.Action {
+	case models.ActionDeleteEpisodeFile:
+		return s.episodeFiles[action.FileID]
+	case models.ActionDeleteMovieFile:
+		return s.movieFiles[action.FileID]
+	case models.ActionDeleteBookFile:
+		return s.bookFiles[action.FileID]
+	case models.ActionDeleteSymlink:
+		return s.symlinks[action.Path]
+	case models.ActionAddMovie:
+		return s.movies[action.TMDBID]
+	case models.ActionAddSeries:
+		return s.series[action.TVDBID]
+	case models.ActionImportQueueItem:
+		return s.queueItems[action.QueueItemID]
+	case models.ActionUpdatePath:
+		return s.pathUpdates[pathUpdateKey(action)]
+	case models.ActionDeleteRejectedFile:
+		return s.rejected[action.Path]
+	}
+	return false
+}
+
+// pathUpdateKey identifies the series or movie of a path update, along with the folder it
+// moves to, so a changed mapping isn't applied under an old review
+func pathUpdateKey(action models.PlannedAction) string {
+	return fmt.Sprintf("%d-%d-%s", action.SeriesID, action.MovieID, action.Path)
+}
+
+// Size returns the number of items in the scope
+func (s *ActionScope) Size() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.episodeFiles) + len(s.movieFiles) + len(s.bookFiles) + len(s.symlinks) + len(s.movies) + len(s.series) + len(s.queueItems) + len(s.pathUpdates) + len(s.rejected)
+}
@@ -0,0 +1,167 @@
+package arr
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+// writeScopeFile marshals v to a temporary JSON file and returns its path
+func writeScopeFile(t *testing.T, v interface{}) string {
+	t.Helper()
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Failed to marshal scope file: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "scope.json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("Failed to write scope file: %v", err)
+	}
+	return path
+}
+
+func TestLoadActionScope_ActionsFile(t *testing.T) {
+	path := writeScopeFile(t, models.ActionsFile{
+		Command:     "cleanup",
+		ServiceType: "sonarr",
+		Actions: []models.PlannedAction{
+			{Action: models.ActionDeleteEpisodeFile, FileID: 100},
+			{Action: models.ActionDeleteSymlink, Path: "/tv/Show [tvdb-1]/ep.mkv", TVDBID: 1},
+			{Action: models.ActionAddSeries, TVDBID: 1},
+			{Action: models.ActionImportQueueItem, QueueItemID: 7},
+		},
+	})
+
+	scope, err := LoadActionScope(path)
+	if err != nil {
+		t.Fatalf("LoadActionScope() failed: %v", err)
+	}
+
+	if scope.Service != "sonarr" {
+		t.Errorf("Expected service 'sonarr', got '%s'", scope.Service)
+	}
+	if scope.Size() != 4 {
+		t.Errorf("Expected scope size 4, got %d", scope.Size())
+	}
+
+	tests := []struct {
+		name     string
+		action   models.PlannedAction
+		expected bool
+	}{
+		{"listed episode file", models.PlannedAction{Action: models.ActionDeleteEpisodeFile, FileID: 100}, true},
+		{"new episode file", models.PlannedAction{Action: models.ActionDeleteEpisodeFile, FileID: 101}, false},
+		{"movie file with same ID", models.PlannedAction{Action: models.ActionDeleteMovieFile, FileID: 100}, false},
+		{"listed symlink", models.PlannedAction{Action: models.ActionDeleteSymlink, Path: "/tv/Show [tvdb-1]/ep.mkv"}, true},
+		{"new symlink", models.PlannedAction{Action: models.ActionDeleteSymlink, Path: "/tv/Other [tvdb-2]/ep.mkv"}, false},
+		{"listed series addition", models.PlannedAction{Action: models.ActionAddSeries, TVDBID: 1}, true},
+		{"listed queue item", models.PlannedAction{Action: models.ActionImportQueueItem, QueueItemID: 7}, true},
+		{"new queue item", models.PlannedAction{Action: models.ActionImportQueueItem, QueueItemID: 8}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scope.Allows(tt.action); got != tt.expected {
+				t.Errorf("Allows(%+v) = %v, expected %v", tt.action, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLoadActionScope_Report(t *testing.T) {
+	path := writeScopeFile(t, models.MissingFilesReport{
+		RunType:     "dry-run",
+		ServiceType: "radarr",
+		MissingFiles: []models.MissingFileEntry{
+			{MediaType: "movie", FilePath: "/movies/Foo (2020)/Foo.mkv", FileID: 50},
+			{MediaType: "movie", FilePath: "/movies/Bar (2021) [tmdb-9]/Bar.mkv", TMDBID: 9},
+		},
+	})
+
+	scope, err := LoadActionScope(path)
+	if err != nil {
+		t.Fatalf("LoadActionScope() failed: %v", err)
+	}
+
+	if !scope.Allows(models.PlannedAction{Action: models.ActionDeleteMovieFile, FileID: 50}) {
+		t.Error("Expected movie file 50 to be allowed")
+	}
+	if !scope.Allows(models.PlannedAction{Action: models.ActionDeleteSymlink, Path: "/movies/Bar (2021) [tmdb-9]/Bar.mkv"}) {
+		t.Error("Expected broken symlink to be allowed")
+	}
+	if !scope.Allows(models.PlannedAction{Action: models.ActionAddMovie, TMDBID: 9}) {
+		t.Error("Expected movie addition for TMDB 9 to be allowed")
+	}
+	if scope.Allows(models.PlannedAction{Action: models.ActionDeleteEpisodeFile, FileID: 50}) {
+		t.Error("Expected episode file 50 to be rejected")
+	}
+}
+
+func TestLoadActionScope_Errors(t *testing.T) {
+	if _, err := LoadActionScope(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+
+	path := writeScopeFile(t, map[string]string{"foo": "bar"})
+	if _, err := LoadActionScope(path); err == nil {
+		t.Error("Expected error for file that is neither an actions file nor a report")
+	}
+}
+
+func TestActionScope_NilAllowsEverything(t *testing.T) {
+	var scope *ActionScope
+	if !scope.Allows(models.PlannedAction{Action: models.ActionDeleteMovieFile, FileID: 1}) {
+		t.Error("Expected nil scope to allow all actions")
+	}
+}
+
+func TestCleanupService_OnlyFromScope(t *testing.T) {
+	client := &mockClient{
+		name: "sonarr",
+		allSeries: []models.Series{
+			{MediaItem: models.MediaItem{ID: 1, Title: "Test Series"}},
+		},
+		episodes: map[int][]models.Episode{
+			1: {
+				{ID: 1, SeriesID: 1, SeasonNumber: 1, EpisodeNumber: 1, HasFile: true, EpisodeFileID: intPtr(100)},
+				{ID: 2, SeriesID: 1, SeasonNumber: 1, EpisodeNumber: 2, HasFile: true, EpisodeFileID: intPtr(101)},
+			},
+		},
+		episodeFiles: map[int]*models.EpisodeFile{
+			100: {ID: 100, Path: "/path/to/missing/episode1.mkv"},
+			101: {ID: 101, Path: "/path/to/missing/episode2.mkv"},
+		},
+	}
+
+	fileChecker := &mockFileChecker{fileExists: map[string]bool{}}
+
+	// Only file 100 was reviewed in the dry run
+	scope := newActionScope("reviewed.json", "sonarr")
+	scope.addAction(models.PlannedAction{Action: models.ActionDeleteEpisodeFile, FileID: 100})
+
+	service := NewCleanupServiceWithOptions(client, fileChecker, &mockLogger{}, &mockProgressReporter{}, CleanupOptions{
+		ConcurrentLimit: 1,
+		Scope:           scope,
+	})
+
+	result, err := service.CleanupMissingFiles(context.Background())
+	if err != nil {
+		t.Fatalf("CleanupMissingFiles() failed: %v", err)
+	}
+
+	if len(client.deletedFileIDs) != 1 || client.deletedFileIDs[0] != 100 {
+		t.Errorf("Expected only file 100 to be deleted, got %v", client.deletedFileIDs)
+	}
+	if result.Stats.Skipped != 1 {
+		t.Errorf("Expected 1 skipped item, got %d", result.Stats.Skipped)
+	}
+	if result.Stats.MissingFiles != 2 {
+		t.Errorf("Expected 2 missing files, got %d", result.Stats.MissingFiles)
+	}
+}
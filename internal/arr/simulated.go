@@ -0,0 +1,706 @@
+package arr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+// SimulationFixture is the canned data a simulated client serves. It is read from
+// <fixtures-dir>/<service>.json, and its fields use the same JSON shapes as the API.
+type SimulationFixture struct {
+	Series          []models.Series           `json:"series,omitempty"`
+	Episodes        []models.Episode          `json:"episodes,omitempty"`
+	EpisodeFiles    []models.EpisodeFile      `json:"episodeFiles,omitempty"`
+	Movies          []models.Movie            `json:"movies,omitempty"`
+	MovieFiles      []models.MovieFile        `json:"movieFiles,omitempty"`
+	RootFolders     []models.RootFolder       `json:"rootFolders,omitempty"`
+	QualityProfiles []models.QualityProfile   `json:"qualityProfiles,omitempty"`
+	MovieLookups    []models.MovieLookup      `json:"movieLookups,omitempty"`  // Results for TMDB and title lookups of movies not in the collection
+	SeriesLookups   []models.SeriesLookup     `json:"seriesLookups,omitempty"` // Results for TVDB and title lookups of series not in the collection
+	Queue           []models.QueueItem        `json:"queue,omitempty"`
+	ManualImport    []models.ManualImportItem `json:"manualImport,omitempty"`
+	Tags            []models.Tag              `json:"tags,omitempty"`
+}
+
+// SimulatedClient is a Client that serves a SimulationFixture instead of calling a live
+// instance. Writes only change the in-memory copy of the fixture, so a simulated run
+// behaves like a real one without touching anything. Every call counts as an API call
+// and waits for the configured latency, so concurrency settings can be benchmarked.
+type SimulatedClient struct {
+	name    string
+	latency time.Duration
+	logger  Logger
+	calls   atomic.Int64
+
+	mu      sync.RWMutex
+	fixture SimulationFixture
+}
+
+// LoadSimulationFixture reads the fixture for service ("sonarr" or "radarr") from
+// <dir>/<service>.json. It returns an error wrapping os.ErrNotExist when there is no fixture.
+func LoadSimulationFixture(dir, service string) (SimulationFixture, error) {
+	var fixture SimulationFixture
+
+	path := filepath.Join(dir, service+".json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fixture, fmt.Errorf("failed to read %s fixture: %w", service, err)
+	}
+
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return fixture, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return fixture, nil
+}
+
+// LoadSimulatedClient creates a simulated client for service from <dir>/<service>.json
+func LoadSimulatedClient(dir, service string, latency time.Duration, logger Logger) (*SimulatedClient, error) {
+	fixture, err := LoadSimulationFixture(dir, service)
+	if err != nil {
+		return nil, err
+	}
+	return NewSimulatedClient(service, fixture, latency, logger), nil
+}
+
+// NewSimulatedClient creates a simulated client serving fixture under the given service name
+func NewSimulatedClient(service string, fixture SimulationFixture, latency time.Duration, logger Logger) *SimulatedClient {
+	return &SimulatedClient{
+		name:    service,
+		latency: latency,
+		logger:  logger,
+		fixture: fixture,
+	}
+}
+
+// APICalls returns the number of simulated requests
+func (c *SimulatedClient) APICalls() int64 {
+	return c.calls.Load()
+}
+
+// call counts a simulated request and waits for the simulated latency
+func (c *SimulatedClient) call(ctx context.Context) error {
+	c.calls.Add(1)
+	if c.latency <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(c.latency)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// GetName returns the service name
+func (c *SimulatedClient) GetName() string {
+	return c.name
+}
+
+// TestConnection always succeeds
+func (c *SimulatedClient) TestConnection(ctx context.Context) error {
+	if err := c.call(ctx); err != nil {
+		return err
+	}
+	c.logger.Info("✅ Using simulated %s fixture data", c.name)
+	return nil
+}
+
+// GetAllSeries returns the fixture's series
+func (c *SimulatedClient) GetAllSeries(ctx context.Context) ([]models.Series, error) {
+	if err := c.call(ctx); err != nil {
+		return nil, err
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]models.Series(nil), c.fixture.Series...), nil
+}
+
+// GetAllMovies returns the fixture's movies
+func (c *SimulatedClient) GetAllMovies(ctx context.Context) ([]models.Movie, error) {
+	if err := c.call(ctx); err != nil {
+		return nil, err
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]models.Movie(nil), c.fixture.Movies...), nil
+}
+
+// GetMovie returns a movie by ID
+func (c *SimulatedClient) GetMovie(ctx context.Context, movieID int) (*models.Movie, error) {
+	if err := c.call(ctx); err != nil {
+		return nil, err
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, movie := range c.fixture.Movies {
+		if movie.ID == movieID {
+			return &movie, nil
+		}
+	}
+	return nil, notFoundError("movie %d not found", movieID)
+}
+
+// GetEpisodesForSeries returns the fixture's episodes for a series
+func (c *SimulatedClient) GetEpisodesForSeries(ctx context.Context, seriesID int) ([]models.Episode, error) {
+	if err := c.call(ctx); err != nil {
+		return nil, err
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var episodes []models.Episode
+	for _, episode := range c.fixture.Episodes {
+		if episode.SeriesID == seriesID {
+			episodes = append(episodes, episode)
+		}
+	}
+	return episodes, nil
+}
+
+// GetEpisode returns an episode by ID
+func (c *SimulatedClient) GetEpisode(ctx context.Context, episodeID int) (*models.Episode, error) {
+	if err := c.call(ctx); err != nil {
+		return nil, err
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, episode := range c.fixture.Episodes {
+		if episode.ID == episodeID {
+			return &episode, nil
+		}
+	}
+	return nil, notFoundError("episode %d not found", episodeID)
+}
+
+// GetEpisodeFile returns an episode file by ID
+func (c *SimulatedClient) GetEpisodeFile(ctx context.Context, fileID int) (*models.EpisodeFile, error) {
+	if err := c.call(ctx); err != nil {
+		return nil, err
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, file := range c.fixture.EpisodeFiles {
+		if file.ID == fileID {
+			return &file, nil
+		}
+	}
+	return nil, notFoundError("episode file %d not found", fileID)
+}
+
+// DeleteEpisodeFile removes an episode file from the fixture
+func (c *SimulatedClient) DeleteEpisodeFile(ctx context.Context, fileID int) error {
+	if err := c.call(ctx); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, file := range c.fixture.EpisodeFiles {
+		if file.ID == fileID {
+			c.fixture.EpisodeFiles = append(c.fixture.EpisodeFiles[:i], c.fixture.EpisodeFiles[i+1:]...)
+			return nil
+		}
+	}
+	return notFoundError("episode file %d not found", fileID)
+}
+
+// UpdateEpisode replaces an episode in the fixture
+func (c *SimulatedClient) UpdateEpisode(ctx context.Context, episode models.Episode) error {
+	if err := c.call(ctx); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := range c.fixture.Episodes {
+		if c.fixture.Episodes[i].ID == episode.ID {
+			c.fixture.Episodes[i] = episode
+			return nil
+		}
+	}
+	return notFoundError("episode %d not found", episode.ID)
+}
+
+// GetMovieFile returns a movie file by ID
+func (c *SimulatedClient) GetMovieFile(ctx context.Context, fileID int) (*models.MovieFile, error) {
+	if err := c.call(ctx); err != nil {
+		return nil, err
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, file := range c.fixture.MovieFiles {
+		if file.ID == fileID {
+			return &file, nil
+		}
+	}
+	return nil, notFoundError("movie file %d not found", fileID)
+}
+
+// DeleteMovieFile removes a movie file from the fixture
+func (c *SimulatedClient) DeleteMovieFile(ctx context.Context, fileID int) error {
+	if err := c.call(ctx); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, file := range c.fixture.MovieFiles {
+		if file.ID == fileID {
+			c.fixture.MovieFiles = append(c.fixture.MovieFiles[:i], c.fixture.MovieFiles[i+1:]...)
+			return nil
+		}
+	}
+	return notFoundError("movie file %d not found", fileID)
+}
+
+// UpdateMovie replaces a movie in the fixture
+func (c *SimulatedClient) UpdateMovie(ctx context.Context, movie models.Movie) error {
+	if err := c.call(ctx); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := range c.fixture.Movies {
+		if c.fixture.Movies[i].ID == movie.ID {
+			c.fixture.Movies[i] = movie
+			return nil
+		}
+	}
+	return notFoundError("movie %d not found", movie.ID)
+}
+
+// MediaPath returns the folder of a series, or of a movie for a simulated Radarr
+func (c *SimulatedClient) MediaPath(ctx context.Context, id int) (string, error) {
+	if err := c.call(ctx); err != nil {
+		return "", err
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	item := c.mediaItem(id)
+	if item == nil {
+		return "", notFoundError("%s item %d not found", c.name, id)
+	}
+	return item.Path, nil
+}
+
+// UpdateMediaPath moves a series or movie to another folder, along with the paths of the file
+// records inside it, as the service does when files are not moved
+func (c *SimulatedClient) UpdateMediaPath(ctx context.Context, id int, path string) error {
+	if err := c.call(ctx); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	item := c.mediaItem(id)
+	if item == nil {
+		return notFoundError("%s item %d not found", c.name, id)
+	}
+
+	oldPath := item.Path
+	item.Path = path
+	move := func(filePath string) string {
+		if oldPath == "" {
+			return filePath
+		}
+		if moved, ok := models.MapPath([]models.PathMapping{{From: oldPath, To: path}}, filePath); ok {
+			return moved
+		}
+		return filePath
+	}
+	if c.name == "radarr" {
+		for i := range c.fixture.MovieFiles {
+			if c.fixture.MovieFiles[i].MovieID == id {
+				c.fixture.MovieFiles[i].Path = move(c.fixture.MovieFiles[i].Path)
+			}
+		}
+		return nil
+	}
+	for i := range c.fixture.EpisodeFiles {
+		c.fixture.EpisodeFiles[i].Path = move(c.fixture.EpisodeFiles[i].Path)
+	}
+	return nil
+}
+
+// mediaItem returns the fixture's series, or movie for a simulated Radarr, with the given ID.
+// The caller holds the lock.
+func (c *SimulatedClient) mediaItem(id int) *models.MediaItem {
+	if c.name == "radarr" {
+		for i := range c.fixture.Movies {
+			if c.fixture.Movies[i].ID == id {
+				return &c.fixture.Movies[i].MediaItem
+			}
+		}
+		return nil
+	}
+	for i := range c.fixture.Series {
+		if c.fixture.Series[i].ID == id {
+			return &c.fixture.Series[i].MediaItem
+		}
+	}
+	return nil
+}
+
+// FindTag returns the ID of the fixture's tag with the label, and false when there is none
+func (c *SimulatedClient) FindTag(ctx context.Context, label string) (int, bool, error) {
+	if err := c.call(ctx); err != nil {
+		return 0, false, err
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	id, ok := findTag(c.fixture.Tags, label)
+	return id, ok, nil
+}
+
+// EnsureTag returns the ID of the fixture's tag with the label, adding the tag when there is none
+func (c *SimulatedClient) EnsureTag(ctx context.Context, label string) (int, error) {
+	if err := c.call(ctx); err != nil {
+		return 0, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if id, ok := findTag(c.fixture.Tags, label); ok {
+		return id, nil
+	}
+	id := 1
+	for _, tag := range c.fixture.Tags {
+		if tag.ID >= id {
+			id = tag.ID + 1
+		}
+	}
+	c.fixture.Tags = append(c.fixture.Tags, models.Tag{ID: id, Label: strings.ToLower(label)})
+	return id, nil
+}
+
+// AddMediaTag adds a tag to a series, or to a movie for a simulated Radarr
+func (c *SimulatedClient) AddMediaTag(ctx context.Context, id, tagID int) error {
+	if err := c.call(ctx); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	item := c.mediaItem(id)
+	if item == nil {
+		return notFoundError("%s item %d not found", c.name, id)
+	}
+	if !slices.Contains(item.Tags, tagID) {
+		item.Tags = append(item.Tags, tagID)
+	}
+	return nil
+}
+
+// TaggedMedia returns the IDs of the series, or movies for a simulated Radarr, with the tag
+func (c *SimulatedClient) TaggedMedia(ctx context.Context, tagID int) ([]int, error) {
+	if err := c.call(ctx); err != nil {
+		return nil, err
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var ids []int
+	if c.name == "radarr" {
+		for _, movie := range c.fixture.Movies {
+			if slices.Contains(movie.Tags, tagID) {
+				ids = append(ids, movie.ID)
+			}
+		}
+		return ids, nil
+	}
+	for _, series := range c.fixture.Series {
+		if slices.Contains(series.Tags, tagID) {
+			ids = append(ids, series.ID)
+		}
+	}
+	return ids, nil
+}
+
+// RemoveMediaTag removes a tag from a series, or from a movie for a simulated Radarr
+func (c *SimulatedClient) RemoveMediaTag(ctx context.Context, id, tagID int) error {
+	if err := c.call(ctx); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	item := c.mediaItem(id)
+	if item == nil {
+		return notFoundError("%s item %d not found", c.name, id)
+	}
+	item.Tags = slices.DeleteFunc(item.Tags, func(tag int) bool { return tag == tagID })
+	return nil
+}
+
+// TriggerRefresh does nothing beyond counting the call
+func (c *SimulatedClient) TriggerRefresh(ctx context.Context) error {
+	return c.call(ctx)
+}
+
+// GetRootFolders returns the fixture's root folders
+func (c *SimulatedClient) GetRootFolders(ctx context.Context) ([]models.RootFolder, error) {
+	if err := c.call(ctx); err != nil {
+		return nil, err
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]models.RootFolder(nil), c.fixture.RootFolders...), nil
+}
+
+// GetQualityProfiles returns the fixture's quality profiles
+func (c *SimulatedClient) GetQualityProfiles(ctx context.Context) ([]models.QualityProfile, error) {
+	if err := c.call(ctx); err != nil {
+		return nil, err
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]models.QualityProfile(nil), c.fixture.QualityProfiles...), nil
+}
+
+// LookupMovieByTMDBID returns the fixture's lookup result for a TMDB ID
+func (c *SimulatedClient) LookupMovieByTMDBID(ctx context.Context, tmdbID int) (*models.MovieLookup, error) {
+	if err := c.call(ctx); err != nil {
+		return nil, err
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, lookup := range c.fixture.MovieLookups {
+		if lookup.TMDBID == tmdbID {
+			return &lookup, nil
+		}
+	}
+	return nil, fmt.Errorf("no movie found with TMDB ID %d", tmdbID)
+}
+
+// LookupMovieByIMDBID returns the fixture's lookup result for an IMDb ID
+func (c *SimulatedClient) LookupMovieByIMDBID(ctx context.Context, imdbID string) (*models.MovieLookup, error) {
+	if err := c.call(ctx); err != nil {
+		return nil, err
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, lookup := range c.fixture.MovieLookups {
+		if lookup.IMDBID != "" && lookup.IMDBID == imdbID {
+			return &lookup, nil
+		}
+	}
+	return nil, fmt.Errorf("no movie found with IMDb ID %s", imdbID)
+}
+
+// LookupMoviesByTerm returns the fixture's lookup results whose title contains the term
+func (c *SimulatedClient) LookupMoviesByTerm(ctx context.Context, term string) ([]models.MovieLookup, error) {
+	if err := c.call(ctx); err != nil {
+		return nil, err
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var lookups []models.MovieLookup
+	for _, lookup := range c.fixture.MovieLookups {
+		if strings.Contains(strings.ToLower(lookup.Title), strings.ToLower(term)) {
+			lookups = append(lookups, lookup)
+		}
+	}
+	return lookups, nil
+}
+
+// AddMovie adds a movie to the fixture
+func (c *SimulatedClient) AddMovie(ctx context.Context, movie models.Movie) (*models.Movie, error) {
+	if err := c.call(ctx); err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	movie.ID = 1
+	movie.AddOptions = nil // Only part of the add request
+	for _, existing := range c.fixture.Movies {
+		if existing.ID >= movie.ID {
+			movie.ID = existing.ID + 1
+		}
+	}
+	c.fixture.Movies = append(c.fixture.Movies, movie)
+	return &movie, nil
+}
+
+// GetMovieByTMDBID returns a movie in the fixture by TMDB ID
+func (c *SimulatedClient) GetMovieByTMDBID(ctx context.Context, tmdbID int) (*models.Movie, error) {
+	if err := c.call(ctx); err != nil {
+		return nil, err
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, movie := range c.fixture.Movies {
+		if movie.TMDBID == tmdbID {
+			return &movie, nil
+		}
+	}
+	return nil, notFoundError("movie with TMDB ID %d not found in collection", tmdbID)
+}
+
+// GetSeriesByTVDBID returns a series in the fixture by TVDB ID
+func (c *SimulatedClient) GetSeriesByTVDBID(ctx context.Context, tvdbID int) (*models.Series, error) {
+	if err := c.call(ctx); err != nil {
+		return nil, err
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, series := range c.fixture.Series {
+		if series.TVDBID == tvdbID {
+			return &series, nil
+		}
+	}
+	return nil, notFoundError("series with TVDB ID %d not found in collection", tvdbID)
+}
+
+// LookupSeriesByTVDBID returns the fixture's lookup result for a TVDB ID
+func (c *SimulatedClient) LookupSeriesByTVDBID(ctx context.Context, tvdbID int) (*models.SeriesLookup, error) {
+	if err := c.call(ctx); err != nil {
+		return nil, err
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, lookup := range c.fixture.SeriesLookups {
+		if lookup.TVDBID == tvdbID {
+			return &lookup, nil
+		}
+	}
+	return nil, fmt.Errorf("no series found with TVDB ID %d", tvdbID)
+}
+
+// LookupSeriesByTerm returns the fixture's lookup results whose title contains the term
+func (c *SimulatedClient) LookupSeriesByTerm(ctx context.Context, term string) ([]models.SeriesLookup, error) {
+	if err := c.call(ctx); err != nil {
+		return nil, err
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var lookups []models.SeriesLookup
+	for _, lookup := range c.fixture.SeriesLookups {
+		if strings.Contains(strings.ToLower(lookup.Title), strings.ToLower(term)) {
+			lookups = append(lookups, lookup)
+		}
+	}
+	return lookups, nil
+}
+
+// AddSeries adds a series to the fixture
+func (c *SimulatedClient) AddSeries(ctx context.Context, series models.Series) (*models.Series, error) {
+	if err := c.call(ctx); err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	series.ID = 1
+	for _, existing := range c.fixture.Series {
+		if existing.ID >= series.ID {
+			series.ID = existing.ID + 1
+		}
+	}
+	c.fixture.Series = append(c.fixture.Series, series)
+	return &series, nil
+}
+
+// GetQueue returns the fixture's queue
+func (c *SimulatedClient) GetQueue(ctx context.Context) ([]models.QueueItem, error) {
+	if err := c.call(ctx); err != nil {
+		return nil, err
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]models.QueueItem(nil), c.fixture.Queue...), nil
+}
+
+// GetQueueWithOptions returns the fixture's queue items with the requested statuses. The
+// fixture's order is kept.
+func (c *SimulatedClient) GetQueueWithOptions(ctx context.Context, opts models.QueueOptions) ([]models.QueueItem, error) {
+	queue, err := c.GetQueue(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := queue[:0]
+	for _, item := range queue {
+		if opts.Matches(item) {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
+
+// GetQueueDetails returns a queue item by ID
+func (c *SimulatedClient) GetQueueDetails(ctx context.Context, queueID int) (*models.QueueItem, error) {
+	if err := c.call(ctx); err != nil {
+		return nil, err
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, item := range c.fixture.Queue {
+		if item.ID == queueID {
+			return &item, nil
+		}
+	}
+	return nil, notFoundError("queue item %d not found", queueID)
+}
+
+// RemoveFromQueue removes a queue item from the fixture
+func (c *SimulatedClient) RemoveFromQueue(ctx context.Context, queueID int, removeFromClient bool) error {
+	if err := c.call(ctx); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, item := range c.fixture.Queue {
+		if item.ID == queueID {
+			c.fixture.Queue = append(c.fixture.Queue[:i], c.fixture.Queue[i+1:]...)
+			return nil
+		}
+	}
+	return notFoundError("queue item %d not found", queueID)
+}
+
+// BlocklistQueueItem removes a queue item from the fixture; the simulated service has no blocklist
+func (c *SimulatedClient) BlocklistQueueItem(ctx context.Context, queueID int, removeFromClient bool) error {
+	return c.RemoveFromQueue(ctx, queueID, removeFromClient)
+}
+
+// SearchEpisodes does nothing beyond counting the call
+func (c *SimulatedClient) SearchEpisodes(ctx context.Context, episodeIDs []int) error {
+	return c.call(ctx)
+}
+
+// TriggerDownloadClientScan does nothing beyond counting the call
+func (c *SimulatedClient) TriggerDownloadClientScan(ctx context.Context) error {
+	return c.call(ctx)
+}
+
+// GetManualImport returns the fixture's manual import items
+func (c *SimulatedClient) GetManualImport(ctx context.Context, folder string) ([]models.ManualImportItem, error) {
+	return c.GetManualImportWithParams(ctx, folder, "", 0, false)
+}
+
+// GetManualImportWithParams returns the fixture's manual import items, filtered by download and series when set
+func (c *SimulatedClient) GetManualImportWithParams(ctx context.Context, folder, downloadID string, seriesID int, filterExisting bool) ([]models.ManualImportItem, error) {
+	if err := c.call(ctx); err != nil {
+		return nil, err
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var items []models.ManualImportItem
+	for _, item := range c.fixture.ManualImport {
+		if downloadID != "" && item.DownloadID != downloadID {
+			continue
+		}
+		if seriesID != 0 && (item.Series == nil || item.Series.ID != seriesID) {
+			continue
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// ExecuteManualImport does nothing beyond counting the call
+func (c *SimulatedClient) ExecuteManualImport(ctx context.Context, files []models.ManualImportItem, importMode string) error {
+	return c.call(ctx)
+}
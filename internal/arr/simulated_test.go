@@ -0,0 +1,85 @@
+package arr
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const sonarrFixture = `{
+  "series": [{"id": 1, "title": "Show", "tvdbId": 100}],
+  "episodes": [
+    {"id": 11, "seriesId": 1, "seasonNumber": 1, "episodeNumber": 1, "title": "Pilot", "hasFile": true, "episodeFileId": 101},
+    {"id": 12, "seriesId": 1, "seasonNumber": 1, "episodeNumber": 2, "title": "Second", "hasFile": true, "episodeFileId": 102}
+  ],
+  "episodeFiles": [
+    {"id": 101, "path": "/tv/Show/S01E01.mkv"},
+    {"id": 102, "path": "/tv/Show/S01E02.mkv"}
+  ]
+}`
+
+func TestLoadSimulatedClient(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "sonarr.json"), []byte(sonarrFixture), 0644); err != nil {
+		t.Fatalf("Failed to write fixture: %v", err)
+	}
+
+	client, err := LoadSimulatedClient(dir, "sonarr", 0, &mockLogger{})
+	if err != nil {
+		t.Fatalf("LoadSimulatedClient() failed: %v", err)
+	}
+	if client.GetName() != "sonarr" {
+		t.Errorf("Expected name sonarr, got %s", client.GetName())
+	}
+
+	episodes, err := client.GetEpisodesForSeries(context.Background(), 1)
+	if err != nil || len(episodes) != 2 {
+		t.Fatalf("Expected 2 episodes, got %v (err %v)", episodes, err)
+	}
+	if episodes[0].EpisodeFileID == nil || *episodes[0].EpisodeFileID != 101 {
+		t.Errorf("Expected episode file 101, got %+v", episodes[0])
+	}
+
+	if _, err := LoadSimulatedClient(dir, "radarr", 0, &mockLogger{}); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected a not-exist error for a missing fixture, got %v", err)
+	}
+}
+
+func TestSimulatedClient_CleanupRun(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "sonarr.json"), []byte(sonarrFixture), 0644); err != nil {
+		t.Fatalf("Failed to write fixture: %v", err)
+	}
+	client, err := LoadSimulatedClient(dir, "sonarr", time.Millisecond, &mockLogger{})
+	if err != nil {
+		t.Fatalf("LoadSimulatedClient() failed: %v", err)
+	}
+
+	fileChecker := &mockFileChecker{fileExists: map[string]bool{"/tv/Show/S01E01.mkv": true}}
+	service := NewCleanupServiceWithOptions(client, fileChecker, &mockLogger{}, &mockProgressReporter{}, CleanupOptions{
+		ConcurrentLimit: 2,
+	})
+
+	result, err := service.CleanupMissingFilesForSeries(context.Background(), []int{1})
+	if err != nil {
+		t.Fatalf("CleanupMissingFilesForSeries() failed: %v", err)
+	}
+	if result.Stats.DeletedRecords != 1 || result.Stats.MissingFiles != 1 {
+		t.Errorf("Expected 1 missing file deleted, got %+v", result.Stats)
+	}
+	if result.Stats.APICalls == 0 {
+		t.Error("Expected simulated calls to be counted")
+	}
+
+	// The deletion only changed the in-memory fixture
+	if _, err := client.GetEpisodeFile(context.Background(), 102); err == nil {
+		t.Error("Expected episode file 102 to be gone from the simulated data")
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "sonarr.json"))
+	if string(data) != sonarrFixture {
+		t.Error("Expected the fixture file to be left untouched")
+	}
+}
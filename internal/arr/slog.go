@@ -0,0 +1,284 @@
+package arr
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// LoggerOptions configures the loggers built on a slog.Handler
+type LoggerOptions struct {
+	Level        string            // Level of lines outside a module, and of modules without their own (default: INFO)
+	ModuleLevels map[string]string // Level by module name, e.g. {"notify": "DEBUG"}
+}
+
+// Modules that have loggers of their own, and so can be given a level in LoggerOptions.ModuleLevels
+const (
+	ModuleCleanup = "cleanup" // Scanning and deleting missing files
+	ModuleAPI     = "api"     // Requests to Sonarr, Radarr and Readarr
+	ModuleNotify  = "notify"  // Notifications about runs
+)
+
+// logLevels holds the levels of a logger and the loggers derived from it
+type logLevels struct {
+	level   slog.Level
+	modules map[string]slog.Level
+}
+
+// enabled reports whether lines of the level are written for the module ("" for none)
+func (l *logLevels) enabled(module string, level slog.Level) bool {
+	if moduleLevel, ok := l.modules[module]; ok && module != "" {
+		return level >= moduleLevel
+	}
+	return level >= l.level
+}
+
+// SlogLogger is a Logger writing to a slog.Handler, so lines can go anywhere a handler can
+// send them. Messages keep their printf-style arguments; key/value attributes are added with
+// With, item fields with WithItem, and Module names the module whose level filters the lines.
+type SlogLogger struct {
+	handler slog.Handler
+	levels  *logLevels
+	module  string
+	fields  ItemFields
+}
+
+// NewSlogLogger creates a logger writing to the handler. The handler is asked whether a level is
+// enabled too, but the levels in opts are applied first.
+func NewSlogLogger(handler slog.Handler, opts LoggerOptions) *SlogLogger {
+	levels := &logLevels{level: parseLogLevel(opts.Level).slogLevel(), modules: make(map[string]slog.Level)}
+	for module, level := range opts.ModuleLevels {
+		levels.modules[strings.ToLower(module)] = parseLogLevel(level).slogLevel()
+	}
+	return &SlogLogger{handler: handler, levels: levels}
+}
+
+// Handler returns the handler the logger writes to, with the attributes added by With
+func (l *SlogLogger) Handler() slog.Handler {
+	return l.handler
+}
+
+// Slog returns a *slog.Logger writing to the same handler, for code that logs through slog
+func (l *SlogLogger) Slog() *slog.Logger {
+	return slog.New(l.handler)
+}
+
+// Debug logs a debug message
+func (l *SlogLogger) Debug(msg string, args ...interface{}) { l.log(slog.LevelDebug, msg, args...) }
+
+// Info logs an info message
+func (l *SlogLogger) Info(msg string, args ...interface{}) { l.log(slog.LevelInfo, msg, args...) }
+
+// Warn logs a warning message
+func (l *SlogLogger) Warn(msg string, args ...interface{}) { l.log(slog.LevelWarn, msg, args...) }
+
+// Error logs an error message
+func (l *SlogLogger) Error(msg string, args ...interface{}) { l.log(slog.LevelError, msg, args...) }
+
+// WithItem returns a logger that adds fields to every line
+func (l *SlogLogger) WithItem(fields ItemFields) Logger {
+	derived := *l
+	derived.fields = l.fields.merge(fields)
+	return &derived
+}
+
+// With returns a logger that adds the key/value pairs to every line, as slog.Logger.With does
+func (l *SlogLogger) With(args ...any) Logger {
+	return l.with(args...)
+}
+
+// Module returns a logger for a module, filtered by its level and naming it in every line
+func (l *SlogLogger) Module(name string) Logger {
+	return l.forModule(name)
+}
+
+// with returns a copy of the logger with the key/value pairs added to its handler
+func (l *SlogLogger) with(args ...any) *SlogLogger {
+	derived := *l
+	derived.handler = slog.New(l.handler).With(args...).Handler()
+	return &derived
+}
+
+// forModule returns a copy of the logger for a module
+func (l *SlogLogger) forModule(name string) *SlogLogger {
+	derived := *l
+	derived.module = strings.ToLower(name)
+	derived.handler = l.handler.WithAttrs([]slog.Attr{slog.String(moduleKey, derived.module)})
+	return &derived
+}
+
+// log writes a line if its level is enabled for the module and by the handler
+func (l *SlogLogger) log(level slog.Level, msg string, args ...interface{}) {
+	ctx := context.Background()
+	if !l.levels.enabled(l.module, level) || !l.handler.Enabled(ctx, level) {
+		return
+	}
+	if len(args) > 0 {
+		msg = fmt.Sprintf(msg, args...)
+	}
+	record := slog.NewRecord(time.Now(), level, msg, 0)
+	record.AddAttrs(l.fields.attrs()...)
+	_ = l.handler.Handle(ctx, record)
+}
+
+// moduleKey is the attribute naming the module a line comes from
+const moduleKey = "module"
+
+// Keys of the attributes holding item fields
+const (
+	runIDKey     = "runId"
+	serviceKey   = "service"
+	seriesIDKey  = "seriesId"
+	movieIDKey   = "movieId"
+	bookIDKey    = "bookId"
+	episodeIDKey = "episodeId"
+	fileIDKey    = "fileId"
+	pathKey      = "path"
+)
+
+// attrs returns the non-zero fields as slog attributes
+func (f ItemFields) attrs() []slog.Attr {
+	var attrs []slog.Attr
+	addString := func(key, value string) {
+		if value != "" {
+			attrs = append(attrs, slog.String(key, value))
+		}
+	}
+	addID := func(key string, id int) {
+		if id != 0 {
+			attrs = append(attrs, slog.Int(key, id))
+		}
+	}
+	addString(runIDKey, f.RunID)
+	addString(serviceKey, f.Service)
+	addID(seriesIDKey, f.SeriesID)
+	addID(movieIDKey, f.MovieID)
+	addID(bookIDKey, f.BookID)
+	addID(episodeIDKey, f.EpisodeID)
+	addID(fileIDKey, f.FileID)
+	addString(pathKey, f.Path)
+	return attrs
+}
+
+// withAttr returns f with the item field the attribute holds set; other attributes leave it as is
+func (f ItemFields) withAttr(attr slog.Attr) (ItemFields, bool) {
+	switch attr.Key {
+	case runIDKey:
+		f.RunID = attr.Value.String()
+	case serviceKey:
+		f.Service = attr.Value.String()
+	case seriesIDKey:
+		f.SeriesID = int(attr.Value.Int64())
+	case movieIDKey:
+		f.MovieID = int(attr.Value.Int64())
+	case bookIDKey:
+		f.BookID = int(attr.Value.Int64())
+	case episodeIDKey:
+		f.EpisodeID = int(attr.Value.Int64())
+	case fileIDKey:
+		f.FileID = int(attr.Value.Int64())
+	case pathKey:
+		f.Path = attr.Value.String()
+	default:
+		return f, false
+	}
+	return f, true
+}
+
+// ModuleLogger is implemented by loggers that can be given a level per module
+type ModuleLogger interface {
+	Module(name string) Logger
+}
+
+// ForModule returns the logger to use in a module, such as ModuleNotify. Loggers that don't
+// implement ModuleLogger are returned as they are.
+func ForModule(logger Logger, name string) Logger {
+	if moduleLogger, ok := logger.(ModuleLogger); ok {
+		return moduleLogger.Module(name)
+	}
+	return logger
+}
+
+// AttrLogger is implemented by loggers that can attach key/value attributes to their lines
+type AttrLogger interface {
+	With(args ...any) Logger
+}
+
+// WithAttrs returns a logger that adds the key/value pairs (or slog.Attr values) to every line.
+// Loggers that don't implement AttrLogger are returned as they are.
+func WithAttrs(logger Logger, args ...any) Logger {
+	if attrLogger, ok := logger.(AttrLogger); ok {
+		return attrLogger.With(args...)
+	}
+	return logger
+}
+
+// NewJSONHandler creates a handler writing each line to w as a JSON object with time (UTC),
+// level and msg keys, followed by the line's attributes. Messages are trimmed, as the
+// indentation meant for terminals means nothing to log pipelines.
+func NewJSONHandler(w io.Writer) slog.Handler {
+	return slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			if len(groups) > 0 {
+				return attr
+			}
+			switch attr.Key {
+			case slog.TimeKey:
+				return slog.String(slog.TimeKey, attr.Value.Time().UTC().Format(time.RFC3339Nano))
+			case slog.MessageKey:
+				return slog.String(slog.MessageKey, strings.TrimSpace(attr.Value.String()))
+			}
+			return attr
+		},
+	})
+}
+
+// textHandler writes "[LEVEL] message" lines to a log.Logger, followed by the key=value pairs
+// added with With. Item fields and the module are left out to keep lines as they always were.
+type textHandler struct {
+	logger *log.Logger
+	attrs  []slog.Attr
+}
+
+// NewTextHandler creates a handler writing "[LEVEL] message key=value" lines to logger
+func NewTextHandler(logger *log.Logger) slog.Handler {
+	return &textHandler{logger: logger}
+}
+
+// Enabled leaves filtering to the logger
+func (h *textHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+// Handle writes a line
+func (h *textHandler) Handle(_ context.Context, record slog.Record) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s] %s", logLevelOf(record.Level).String(), record.Message)
+	write := func(attr slog.Attr) bool {
+		if _, isItem := (ItemFields{}).withAttr(attr); !isItem && attr.Key != moduleKey {
+			fmt.Fprintf(&b, " %s=%s", attr.Key, attr.Value.String())
+		}
+		return true
+	}
+	for _, attr := range h.attrs {
+		write(attr)
+	}
+	record.Attrs(write)
+	h.logger.Print(b.String())
+	return nil
+}
+
+// WithAttrs returns a handler adding the attributes to every line
+func (h *textHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &textHandler{logger: h.logger, attrs: append(h.attrs[:len(h.attrs):len(h.attrs)], attrs...)}
+}
+
+// WithGroup returns the handler unchanged; text lines have no groups
+func (h *textHandler) WithGroup(string) slog.Handler {
+	return h
+}
@@ -1,9 +1,13 @@
 package arr
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"strings"
+	"net/url"
+	"sync"
 	"time"
 
 	"github.com/hnipps/refresharr/internal/config"
@@ -15,23 +19,51 @@ import (
 // SonarrClient implements the Client interface for Sonarr API
 type SonarrClient struct {
 	client *sonarr.Sonarr
+	calls  *callCounter
 	logger Logger
+
+	versionOnce sync.Once
+	major       int           // Major version of the server, see majorVersion
+	caps        *Capabilities // Optional features the server supports, probed on connect
 }
 
 // NewSonarrClient creates a new Sonarr client
 func NewSonarrClient(cfg *config.SonarrConfig, timeout time.Duration, logger Logger) Client {
+	return NewSonarrClientWithTransport(cfg, timeout, nil, logger)
+}
+
+// NewSonarrClientWithTransport creates a new Sonarr client whose requests go through wrap
+func NewSonarrClientWithTransport(cfg *config.SonarrConfig, timeout time.Duration, wrap TransportWrapper, logger Logger) Client {
 	// Create starr config
-	starrConfig := starr.New(cfg.APIKey, cfg.URL, timeout)
+	baseURL, endpoint := ResolveEndpoint(cfg.URL)
+	starrConfig := starr.New(cfg.APIKey, baseURL, timeout)
+	if endpoint != nil {
+		starrConfig.Client.Transport = endpoint
+	}
+	calls := newClientTransport(wrap, starrConfig.Client.Transport, cfg.APIKey, logger)
+	starrConfig.Client.Transport = calls
 
 	// Create sonarr client
 	sonarrClient := sonarr.New(starrConfig)
 
 	return &SonarrClient{
 		client: sonarrClient,
+		calls:  calls,
 		logger: logger,
+		caps:   newCapabilities("Sonarr", FeatureDownloadedEpisodesScan, FeatureManualImportFilters),
 	}
 }
 
+// APICalls returns the number of requests sent to Sonarr
+func (c *SonarrClient) APICalls() int64 {
+	return c.calls.calls.Load()
+}
+
+// Capabilities returns the optional features the server supports
+func (c *SonarrClient) Capabilities() *Capabilities {
+	return c.caps
+}
+
 // GetName returns the service name
 func (c *SonarrClient) GetName() string {
 	return "sonarr"
@@ -39,10 +71,11 @@ func (c *SonarrClient) GetName() string {
 
 // TestConnection verifies the connection to Sonarr
 func (c *SonarrClient) TestConnection(ctx context.Context) error {
-	_, err := c.client.GetSystemStatusContext(ctx)
+	status, err := c.client.GetSystemStatusContext(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to connect to Sonarr: %w", err)
+		return fmt.Errorf("failed to connect to Sonarr: %w", apiError(err))
 	}
+	c.versionOnce.Do(func() { c.setVersion(status.Version) })
 
 	c.logger.Info("✅ Successfully connected to Sonarr")
 	return nil
@@ -52,7 +85,7 @@ func (c *SonarrClient) TestConnection(ctx context.Context) error {
 func (c *SonarrClient) GetAllSeries(ctx context.Context) ([]models.Series, error) {
 	series, err := c.client.GetAllSeriesContext(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch series: %w", err)
+		return nil, fmt.Errorf("failed to fetch series: %w", apiError(err))
 	}
 
 	result := mapSonarrSeriesToModelsList(series)
@@ -78,7 +111,7 @@ func (c *SonarrClient) GetEpisodesForSeries(ctx context.Context, seriesID int) (
 
 	episodes, err := c.client.GetSeriesEpisodesContext(ctx, getEpisode)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch episodes for series %d: %w", seriesID, err)
+		return nil, fmt.Errorf("failed to fetch episodes for series %d: %w", seriesID, apiError(err))
 	}
 
 	result := mapSonarrEpisodesToModelsList(episodes)
@@ -86,15 +119,26 @@ func (c *SonarrClient) GetEpisodesForSeries(ctx context.Context, seriesID int) (
 	return result, nil
 }
 
+// GetEpisode returns a single episode by ID
+func (c *SonarrClient) GetEpisode(ctx context.Context, episodeID int) (*models.Episode, error) {
+	episode, err := c.client.GetEpisodeByIDContext(ctx, int64(episodeID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch episode %d: %w", episodeID, apiError(err))
+	}
+
+	result := mapSonarrEpisodeToModels(episode)
+	return &result, nil
+}
+
 // GetEpisodeFile returns episode file details
 func (c *SonarrClient) GetEpisodeFile(ctx context.Context, fileID int) (*models.EpisodeFile, error) {
 	episodeFiles, err := c.client.GetEpisodeFilesContext(ctx, int64(fileID))
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch episode file %d: %w", fileID, err)
+		return nil, fmt.Errorf("failed to fetch episode file %d: %w", fileID, apiError(err))
 	}
 
 	if len(episodeFiles) == 0 {
-		return nil, fmt.Errorf("episode file %d not found", fileID)
+		return nil, notFoundError("episode file %d not found", fileID)
 	}
 
 	result := mapSonarrEpisodeFileToModels(episodeFiles[0])
@@ -105,7 +149,7 @@ func (c *SonarrClient) GetEpisodeFile(ctx context.Context, fileID int) (*models.
 func (c *SonarrClient) DeleteEpisodeFile(ctx context.Context, fileID int) error {
 	err := c.client.DeleteEpisodeFileContext(ctx, int64(fileID))
 	if err != nil {
-		return fmt.Errorf("failed to delete episode file %d: %w", fileID, err)
+		return fmt.Errorf("failed to delete episode file %d: %w", fileID, apiError(err))
 	}
 
 	c.logger.Debug("Successfully deleted episode file %d", fileID)
@@ -117,7 +161,7 @@ func (c *SonarrClient) UpdateEpisode(ctx context.Context, episode models.Episode
 	// First get the current episode data
 	currentEpisode, err := c.client.GetEpisodeByIDContext(ctx, int64(episode.ID))
 	if err != nil {
-		return fmt.Errorf("failed to fetch current episode %d data: %w", episode.ID, err)
+		return fmt.Errorf("failed to fetch current episode %d data: %w", episode.ID, apiError(err))
 	}
 
 	// Update the file reference fields
@@ -129,13 +173,142 @@ func (c *SonarrClient) UpdateEpisode(ctx context.Context, episode models.Episode
 	// with monitoring set to current state to trigger an update
 	_, err = c.client.MonitorEpisodeContext(ctx, []int64{int64(episode.ID)}, currentEpisode.Monitored)
 	if err != nil {
-		return fmt.Errorf("failed to update episode %d: %w", episode.ID, err)
+		return fmt.Errorf("failed to update episode %d: %w", episode.ID, apiError(err))
 	}
 
 	c.logger.Debug("Successfully updated episode %d", episode.ID)
 	return nil
 }
 
+// MediaPath returns the folder of a series
+func (c *SonarrClient) MediaPath(ctx context.Context, seriesID int) (string, error) {
+	series, err := c.fetchSeriesFields(ctx, seriesID)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch series %d: %w", seriesID, err)
+	}
+	path, _ := series["path"].(string)
+	return path, nil
+}
+
+// UpdateMediaPath points a series at another folder without moving its files. The series is
+// sent back as Sonarr returned it, so fields starr doesn't model survive the update.
+func (c *SonarrClient) UpdateMediaPath(ctx context.Context, seriesID int, path string) error {
+	series, err := c.fetchSeriesFields(ctx, seriesID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch series %d: %w", seriesID, err)
+	}
+	series["path"] = path
+
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(series); err != nil {
+		return fmt.Errorf("failed to marshal series update: %w", err)
+	}
+
+	var output map[string]interface{}
+	req := starr.Request{
+		URI:   fmt.Sprintf("%s/series/%d", sonarr.APIver, seriesID),
+		Query: url.Values{"moveFiles": {"false"}},
+		Body:  &body,
+	}
+	if err := c.client.PutInto(ctx, req, &output); err != nil {
+		return fmt.Errorf("failed to update path of series %d: %w", seriesID, apiError(err))
+	}
+
+	c.logger.Debug("Successfully moved series %d to %s", seriesID, path)
+	return nil
+}
+
+// FindTag returns the ID of the tag with the label, and false when Sonarr has none
+func (c *SonarrClient) FindTag(ctx context.Context, label string) (int, bool, error) {
+	tags, err := c.client.GetTagsContext(ctx)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to fetch tags: %w", apiError(err))
+	}
+	id, ok := findTag(starrTags(tags), label)
+	return id, ok, nil
+}
+
+// EnsureTag returns the ID of the tag with the label, creating the tag when Sonarr has none
+func (c *SonarrClient) EnsureTag(ctx context.Context, label string) (int, error) {
+	if id, ok, err := c.FindTag(ctx, label); err != nil || ok {
+		return id, err
+	}
+
+	tag, err := c.client.AddTagContext(ctx, &starr.Tag{Label: label})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create tag %q: %w", label, apiError(err))
+	}
+	c.logger.Debug("Created tag %q (%d)", label, tag.ID)
+	return tag.ID, nil
+}
+
+// TaggedMedia returns the IDs of the series with the tag, from the tag's details
+func (c *SonarrClient) TaggedMedia(ctx context.Context, tagID int) ([]int, error) {
+	var details struct {
+		SeriesIDs []int `json:"seriesIds"`
+	}
+	req := starr.Request{URI: fmt.Sprintf("%s/tag/detail/%d", sonarr.APIver, tagID)}
+	if err := c.client.GetInto(ctx, req, &details); err != nil {
+		return nil, fmt.Errorf("failed to fetch details of tag %d: %w", tagID, apiError(err))
+	}
+	return details.SeriesIDs, nil
+}
+
+// AddMediaTag adds a tag to a series
+func (c *SonarrClient) AddMediaTag(ctx context.Context, seriesID, tagID int) error {
+	return c.updateSeriesTags(ctx, seriesID, func(series map[string]interface{}) bool {
+		return addTagID(series, tagID)
+	})
+}
+
+// RemoveMediaTag removes a tag from a series
+func (c *SonarrClient) RemoveMediaTag(ctx context.Context, seriesID, tagID int) error {
+	return c.updateSeriesTags(ctx, seriesID, func(series map[string]interface{}) bool {
+		return removeTagID(series, tagID)
+	})
+}
+
+// updateSeriesTags changes the tags of a series, sending it back only when change reports a
+// change. The series is sent back as Sonarr returned it, so fields starr doesn't model survive
+// the update.
+func (c *SonarrClient) updateSeriesTags(ctx context.Context, seriesID int, change func(map[string]interface{}) bool) error {
+	series, err := c.fetchSeriesFields(ctx, seriesID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch series %d: %w", seriesID, err)
+	}
+	if !change(series) {
+		return nil
+	}
+
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(series); err != nil {
+		return fmt.Errorf("failed to marshal series update: %w", err)
+	}
+
+	var output map[string]interface{}
+	req := starr.Request{
+		URI:   fmt.Sprintf("%s/series/%d", sonarr.APIver, seriesID),
+		Query: url.Values{"moveFiles": {"false"}},
+		Body:  &body,
+	}
+	if err := c.client.PutInto(ctx, req, &output); err != nil {
+		return fmt.Errorf("failed to update tags of series %d: %w", seriesID, apiError(err))
+	}
+
+	c.logger.Debug("Successfully updated tags of series %d", seriesID)
+	return nil
+}
+
+// fetchSeriesFields fetches a series with every field Sonarr returns
+func (c *SonarrClient) fetchSeriesFields(ctx context.Context, seriesID int) (map[string]interface{}, error) {
+	var series map[string]interface{}
+	req := starr.Request{URI: fmt.Sprintf("%s/series/%d", sonarr.APIver, seriesID)}
+	if err := c.client.GetInto(ctx, req, &series); err != nil {
+		return nil, fmt.Errorf("api.Get(%s): %w", &req, apiError(err))
+	}
+	return series, nil
+}
+
 // GetMovieFile is not applicable for Sonarr (returns error)
 func (c *SonarrClient) GetMovieFile(ctx context.Context, fileID int) (*models.MovieFile, error) {
 	return nil, fmt.Errorf("GetMovieFile is not supported by Sonarr client")
@@ -155,7 +328,7 @@ func (c *SonarrClient) UpdateMovie(ctx context.Context, movie models.Movie) erro
 func (c *SonarrClient) GetRootFolders(ctx context.Context) ([]models.RootFolder, error) {
 	rootFolders, err := c.client.GetRootFoldersContext(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch root folders: %w", err)
+		return nil, fmt.Errorf("failed to fetch root folders: %w", apiError(err))
 	}
 
 	result := mapSonarrRootFoldersToModelsList(rootFolders)
@@ -167,7 +340,7 @@ func (c *SonarrClient) GetRootFolders(ctx context.Context) ([]models.RootFolder,
 func (c *SonarrClient) GetQualityProfiles(ctx context.Context) ([]models.QualityProfile, error) {
 	qualityProfiles, err := c.client.GetQualityProfilesContext(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch quality profiles: %w", err)
+		return nil, fmt.Errorf("failed to fetch quality profiles: %w", apiError(err))
 	}
 
 	result := mapSonarrQualityProfilesToModelsList(qualityProfiles)
@@ -180,6 +353,16 @@ func (c *SonarrClient) LookupMovieByTMDBID(ctx context.Context, tmdbID int) (*mo
 	return nil, fmt.Errorf("LookupMovieByTMDBID is not supported by Sonarr client")
 }
 
+// LookupMovieByIMDBID is not applicable for Sonarr (returns error)
+func (c *SonarrClient) LookupMovieByIMDBID(ctx context.Context, imdbID string) (*models.MovieLookup, error) {
+	return nil, fmt.Errorf("LookupMovieByIMDBID is not supported by Sonarr client")
+}
+
+// LookupMoviesByTerm is not applicable for Sonarr (returns error)
+func (c *SonarrClient) LookupMoviesByTerm(ctx context.Context, term string) ([]models.MovieLookup, error) {
+	return nil, fmt.Errorf("LookupMoviesByTerm is not supported by Sonarr client")
+}
+
 // GetMovieByTMDBID is not applicable for Sonarr (returns error)
 func (c *SonarrClient) GetMovieByTMDBID(ctx context.Context, tmdbID int) (*models.Movie, error) {
 	return nil, fmt.Errorf("GetMovieByTMDBID is not supported by Sonarr client")
@@ -198,7 +381,7 @@ func (c *SonarrClient) TriggerRefresh(ctx context.Context) error {
 
 	_, err := c.client.SendCommandContext(ctx, command)
 	if err != nil {
-		return fmt.Errorf("failed to trigger refresh: %w", err)
+		return fmt.Errorf("failed to trigger refresh: %w", apiError(err))
 	}
 
 	c.logger.Info("✅ Refresh triggered successfully")
@@ -207,20 +390,9 @@ func (c *SonarrClient) TriggerRefresh(ctx context.Context) error {
 
 // AddSeries adds a series to the Sonarr collection
 func (c *SonarrClient) AddSeries(ctx context.Context, series models.Series) (*models.Series, error) {
-	// Convert models.Series to sonarr.AddSeriesInput
-	addSeriesInput := &sonarr.AddSeriesInput{
-		Title:            series.Title,
-		TvdbID:           int64(series.TVDBID),
-		Path:             series.Path,
-		QualityProfileID: int64(series.QualityProfileID),
-		RootFolderPath:   series.RootFolderPath,
-		Monitored:        series.Monitored,
-		SeasonFolder:     true, // Default to true
-	}
-
-	addedSeries, err := c.client.AddSeriesContext(ctx, addSeriesInput)
+	addedSeries, err := c.client.AddSeriesContext(ctx, mapModelsSeriesToSonarrInput(series))
 	if err != nil {
-		return nil, fmt.Errorf("failed to add series: %w", err)
+		return nil, fmt.Errorf("failed to add series: %w", apiError(err))
 	}
 
 	result := mapSonarrSeriesToModels(addedSeries)
@@ -233,11 +405,11 @@ func (c *SonarrClient) GetSeriesByTVDBID(ctx context.Context, tvdbID int) (*mode
 	// Get series by TVDB ID using starr's GetSeries method
 	series, err := c.client.GetSeriesContext(ctx, int64(tvdbID))
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch series with TVDB ID %d: %w", tvdbID, err)
+		return nil, fmt.Errorf("failed to fetch series with TVDB ID %d: %w", tvdbID, apiError(err))
 	}
 
 	if len(series) == 0 {
-		return nil, fmt.Errorf("series with TVDB ID %d not found in collection", tvdbID)
+		return nil, notFoundError("series with TVDB ID %d not found in collection", tvdbID)
 	}
 
 	// Find the series with matching TVDB ID
@@ -248,7 +420,7 @@ func (c *SonarrClient) GetSeriesByTVDBID(ctx context.Context, tvdbID int) (*mode
 		}
 	}
 
-	return nil, fmt.Errorf("series with TVDB ID %d not found in collection", tvdbID)
+	return nil, notFoundError("series with TVDB ID %d not found in collection", tvdbID)
 }
 
 // LookupSeriesByTVDBID looks up series information by TVDB ID
@@ -256,71 +428,174 @@ func (c *SonarrClient) LookupSeriesByTVDBID(ctx context.Context, tvdbID int) (*m
 	term := fmt.Sprintf("tvdb:%d", tvdbID)
 	series, err := c.client.GetSeriesLookupContext(ctx, term, int64(tvdbID))
 	if err != nil {
-		return nil, fmt.Errorf("failed to lookup series with TVDB ID %d: %w", tvdbID, err)
+		return nil, fmt.Errorf("failed to lookup series with TVDB ID %d: %w", tvdbID, apiError(err))
 	}
 
 	if len(series) == 0 {
-		return nil, fmt.Errorf("series with TVDB ID %d not found", tvdbID)
+		return nil, notFoundError("series with TVDB ID %d not found", tvdbID)
 	}
 
 	// Find the series with matching TVDB ID (API might return multiple results)
 	for _, s := range series {
 		if int(s.TvdbID) == tvdbID {
-			result := &models.SeriesLookup{
-				TVDBID:   int(s.TvdbID),
-				Title:    s.Title,
-				Year:     s.Year,
-				Overview: s.Overview,
-				Images: make([]struct {
-					CoverType string `json:"coverType"`
-					URL       string `json:"url"`
-				}, len(s.Images)),
-			}
-
-			// Map images if present
-			for i, img := range s.Images {
-				result.Images[i].CoverType = img.CoverType
-				result.Images[i].URL = img.URL
-			}
-
+			result := mapSonarrSeriesLookupToModels(s)
 			c.logger.Debug("Successfully looked up series with TVDB ID %d: %s", tvdbID, result.Title)
-			return result, nil
+			return &result, nil
 		}
 	}
 
-	return nil, fmt.Errorf("series with TVDB ID %d not found in lookup results", tvdbID)
+	return nil, notFoundError("series with TVDB ID %d not found in lookup results", tvdbID)
+}
+
+// LookupSeriesByTerm searches for series by title
+func (c *SonarrClient) LookupSeriesByTerm(ctx context.Context, term string) ([]models.SeriesLookup, error) {
+	series, err := c.client.GetSeriesLookupContext(ctx, term, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lookup series matching %q: %w", term, apiError(err))
+	}
+
+	results := make([]models.SeriesLookup, 0, len(series))
+	for _, s := range series {
+		if s != nil {
+			results = append(results, mapSonarrSeriesLookupToModels(s))
+		}
+	}
+	c.logger.Debug("Found %d series matching %q", len(results), term)
+	return results, nil
 }
 
 // GetQueue returns all items in the download queue
 func (c *SonarrClient) GetQueue(ctx context.Context) ([]models.QueueItem, error) {
-	queue, err := c.client.GetQueueContext(ctx, 0, 0) // Get all records
+	return c.GetQueueWithOptions(ctx, models.QueueOptions{IncludeUnknownSeriesItems: true})
+}
+
+// GetQueueWithOptions returns the items in the download queue that match opts, filtered and
+// sorted by Sonarr
+func (c *SonarrClient) GetQueueWithOptions(ctx context.Context, opts models.QueueOptions) ([]models.QueueItem, error) {
+	records, err := c.fetchQueue(ctx, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch queue: %w", err)
 	}
 
-	result := mapSonarrQueueToModelsList(queue)
+	major := c.majorVersion(ctx)
+	result := make([]models.QueueItem, 0, len(records))
+	for _, qr := range records {
+		if item := mapSonarrQueueRecordForVersion(qr, major); opts.Matches(item) {
+			result = append(result, item)
+		}
+	}
+	c.resolveQueueSeries(ctx, result)
 	c.logger.Debug("Fetched %d items from queue", len(result))
 	return result, nil
 }
 
+// queueSeriesLookupLimit is the most series resolved one request at a time; when the queue
+// references more, all series are fetched in a single request instead
+const queueSeriesLookupLimit = 5
+
+// resolveQueueSeries fills in the series of queue items, which the queue only references by
+// ID. Series that can't be resolved keep just their ID.
+func (c *SonarrClient) resolveQueueSeries(ctx context.Context, items []models.QueueItem) {
+	var ids []int
+	seen := make(map[int]bool)
+	for _, item := range items {
+		if item.Series != nil && item.Series.Title == "" && !seen[item.Series.ID] {
+			seen[item.Series.ID] = true
+			ids = append(ids, item.Series.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	seriesByID := make(map[int]models.Series, len(ids))
+	if len(ids) > queueSeriesLookupLimit {
+		allSeries, err := c.GetAllSeries(ctx)
+		if err != nil {
+			c.logger.Debug("Could not resolve series for queue items: %s", err.Error())
+			return
+		}
+		for _, series := range allSeries {
+			seriesByID[series.ID] = series
+		}
+	} else {
+		for _, id := range ids {
+			series, err := c.client.GetSeriesByIDContext(ctx, int64(id))
+			if err != nil {
+				c.logger.Debug("Could not resolve series %d for queue items: %s", id, err.Error())
+				continue
+			}
+			seriesByID[id] = mapSonarrSeriesToModels(series)
+		}
+	}
+
+	for i := range items {
+		if items[i].Series == nil {
+			continue
+		}
+		if series, ok := seriesByID[items[i].Series.ID]; ok {
+			items[i].Series = &series
+		}
+	}
+}
+
+// fetchQueue fetches every page of the queue
+func (c *SonarrClient) fetchQueue(ctx context.Context, opts models.QueueOptions) ([]*sonarrQueueRecord, error) {
+	sortKey := opts.SortKey
+	if sortKey == "" {
+		sortKey = "timeleft"
+	}
+	query := url.Values{
+		"pageSize":                  {fmt.Sprint(sonarrQueuePageSize)},
+		"sortKey":                   {sortKey},
+		"includeUnknownSeriesItems": {fmt.Sprint(opts.IncludeUnknownSeriesItems)},
+	}
+	if opts.SortDirection != "" {
+		query.Set("sortDirection", opts.SortDirection)
+	}
+	if opts.IncludeSeries {
+		query.Set("includeSeries", "true")
+	}
+	for _, status := range opts.Statuses {
+		query.Add("status", status)
+	}
+
+	var records []*sonarrQueueRecord
+	for page := 1; ; page++ {
+		query.Set("page", fmt.Sprint(page))
+		req := starr.Request{URI: sonarr.APIver + "/queue", Query: query}
+
+		var output sonarrQueuePage
+		if err := c.client.GetInto(ctx, req, &output); err != nil {
+			return nil, fmt.Errorf("api.Get(%s): %w", &req, apiError(err))
+		}
+
+		records = append(records, output.Records...)
+		if len(output.Records) == 0 || len(records) >= output.TotalRecords {
+			return records, nil
+		}
+	}
+}
+
 // GetQueueDetails returns detailed information about a specific queue item
 func (c *SonarrClient) GetQueueDetails(ctx context.Context, queueID int) (*models.QueueItem, error) {
 	// starr doesn't have a method to get a specific queue item by ID
 	// so we'll get all queue items and find the one with matching ID
-	queue, err := c.client.GetQueueContext(ctx, 0, 0)
+	records, err := c.fetchQueue(ctx, models.QueueOptions{IncludeUnknownSeriesItems: true})
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch queue details for ID %d: %w", queueID, err)
 	}
 
 	// Find the queue item with matching ID
-	for _, qr := range queue.Records {
+	for _, qr := range records {
 		if int(qr.ID) == queueID {
-			result := mapSonarrQueueRecordToModels(qr)
-			return &result, nil
+			result := []models.QueueItem{mapSonarrQueueRecordForVersion(qr, c.majorVersion(ctx))}
+			c.resolveQueueSeries(ctx, result)
+			return &result[0], nil
 		}
 	}
 
-	return nil, fmt.Errorf("queue item %d not found", queueID)
+	return nil, notFoundError("queue item %d not found", queueID)
 }
 
 // RemoveFromQueue removes an item from the queue
@@ -333,10 +608,10 @@ func (c *SonarrClient) RemoveFromQueue(ctx context.Context, queueID int, removeF
 		ChangeCategory:   false,
 	}
 
-	err := c.client.DeleteQueueContext(ctx, int64(queueID), opts)
+	err := apiError(c.client.DeleteQueueContext(ctx, int64(queueID), opts))
 	if err != nil {
 		// Check if it's a "not found" error - this is common and not a real error
-		if strings.Contains(err.Error(), "404") || strings.Contains(err.Error(), "not found") {
+		if errors.Is(err, ErrNotFound) {
 			c.logger.Debug("Queue item %d not found (already removed)", queueID)
 			return nil
 		}
@@ -347,18 +622,56 @@ func (c *SonarrClient) RemoveFromQueue(ctx context.Context, queueID int, removeF
 	return nil
 }
 
+// BlocklistQueueItem removes an item from the queue and adds its release to the blocklist
+func (c *SonarrClient) BlocklistQueueItem(ctx context.Context, queueID int, removeFromClient bool) error {
+	opts := &starr.QueueDeleteOpts{
+		RemoveFromClient: &removeFromClient,
+		BlockList:        true,
+		SkipRedownload:   true, // The episodes are searched for explicitly
+	}
+
+	if err := apiError(c.client.DeleteQueueContext(ctx, int64(queueID), opts)); err != nil {
+		return fmt.Errorf("failed to blocklist queue item %d: %w", queueID, err)
+	}
+
+	c.logger.Debug("Removed queue item %d and blocklisted its release", queueID)
+	return nil
+}
+
+// SearchEpisodes triggers a search for the episodes
+func (c *SonarrClient) SearchEpisodes(ctx context.Context, episodeIDs []int) error {
+	command := &sonarr.CommandRequest{
+		Name:       "EpisodeSearch",
+		EpisodeIDs: make([]int64, len(episodeIDs)),
+	}
+	for i, id := range episodeIDs {
+		command.EpisodeIDs[i] = int64(id)
+	}
+
+	if _, err := c.client.SendCommandContext(ctx, command); err != nil {
+		return fmt.Errorf("failed to search for episodes %v: %w", episodeIDs, apiError(err))
+	}
+	return nil
+}
+
 // TriggerDownloadClientScan triggers a scan of completed downloads
 func (c *SonarrClient) TriggerDownloadClientScan(ctx context.Context) error {
+	if !c.caps.Supports(FeatureDownloadedEpisodesScan) {
+		c.logger.Debug("Skipping download client scan: not supported by Sonarr %s", c.caps.Version())
+		return nil
+	}
+
 	command := &sonarr.CommandRequest{
 		Name: "DownloadedEpisodesScan",
 	}
 
 	_, err := c.client.SendCommandContext(ctx, command)
+	err = apiError(err)
 	if err != nil {
 		// For Sonarr v4+, the DownloadedEpisodesScan command may not be available
 		// This is expected and not an error - we'll fall back to other methods
-		if strings.Contains(err.Error(), "404") || strings.Contains(err.Error(), "not found") {
-			c.logger.Debug("Download client scan command not available (likely Sonarr v4+)")
+		if errors.Is(err, ErrNotFound) {
+			c.caps.disable(FeatureDownloadedEpisodesScan, c.logger)
 			return nil
 		}
 		return fmt.Errorf("failed to trigger download client scan: %w", err)
@@ -375,17 +688,11 @@ func (c *SonarrClient) GetManualImport(ctx context.Context, folder string) ([]mo
 		FilterExistingFiles: true,
 	}
 
-	manualImportOutput, err := c.client.ManualImportContext(ctx, params)
+	result, err := c.fetchManualImport(ctx, params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch manual import items for folder %s: %w", folder, err)
 	}
 
-	// Convert from starr ManualImportOutput to our models
-	result := make([]models.ManualImportItem, 0)
-	if manualImportOutput != nil {
-		result = append(result, mapSonarrManualImportToModels(manualImportOutput))
-	}
-
 	c.logger.Debug("Found %d manual import items in folder %s", len(result), folder)
 	return result, nil
 }
@@ -393,12 +700,17 @@ func (c *SonarrClient) GetManualImport(ctx context.Context, folder string) ([]mo
 // ExecuteManualImport executes manual import for the specified files
 func (c *SonarrClient) ExecuteManualImport(ctx context.Context, files []models.ManualImportItem, importMode string) error {
 	// Convert each manual import item to starr format and process individually
+	major := c.majorVersion(ctx)
 	for _, file := range files {
-		manualImportInput := mapModelsManualImportToSonarr(file)
+		var body bytes.Buffer
+		if err := json.NewEncoder(&body).Encode(mapModelsManualImportForVersion(file, major)); err != nil {
+			return fmt.Errorf("failed to encode manual import for file %s: %w", file.Path, err)
+		}
 
-		err := c.client.ManualImportReprocessContext(ctx, manualImportInput)
-		if err != nil {
-			return fmt.Errorf("failed to execute manual import for file %s: %w", file.Path, err)
+		var output interface{}
+		req := starr.Request{URI: sonarr.APIver + "/manualimport", Body: &body}
+		if err := c.client.PostInto(ctx, req, &output); err != nil {
+			return fmt.Errorf("failed to execute manual import for file %s: %w", file.Path, apiError(err))
 		}
 	}
 
@@ -408,6 +720,14 @@ func (c *SonarrClient) ExecuteManualImport(ctx context.Context, files []models.M
 
 // GetManualImportWithParams gets files available for manual import with additional parameters
 func (c *SonarrClient) GetManualImportWithParams(ctx context.Context, folder, downloadID string, seriesID int, filterExisting bool) ([]models.ManualImportItem, error) {
+	if (downloadID != "" || seriesID > 0) && !c.caps.Supports(FeatureManualImportFilters) {
+		if folder == "" {
+			c.logger.Debug("Skipping manual import lookup: Sonarr %s can't filter by download or series", c.caps.Version())
+			return nil, nil
+		}
+		downloadID, seriesID = "", 0
+	}
+
 	params := &sonarr.ManualImportParams{
 		Folder:              folder,
 		DownloadID:          downloadID,
@@ -418,17 +738,39 @@ func (c *SonarrClient) GetManualImportWithParams(ctx context.Context, folder, do
 		params.SeriesID = int64(seriesID)
 	}
 
-	manualImportOutput, err := c.client.ManualImportContext(ctx, params)
+	result, err := c.fetchManualImport(ctx, params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch manual import items: %w", err)
 	}
 
-	// Convert from starr ManualImportOutput to our models
-	result := make([]models.ManualImportItem, 0)
-	if manualImportOutput != nil {
-		result = append(result, mapSonarrManualImportToModels(manualImportOutput))
+	c.logger.Debug("Found %d manual import items with custom parameters", len(result))
+	return result, nil
+}
+
+// fetchManualImport fetches the manual import candidates matching params
+func (c *SonarrClient) fetchManualImport(ctx context.Context, params *sonarr.ManualImportParams) ([]models.ManualImportItem, error) {
+	req := starr.Request{URI: sonarr.APIver + "/manualimport", Query: url.Values{
+		"folder":              {params.Folder},
+		"downloadId":          {params.DownloadID},
+		"seriesId":            {starr.Str(params.SeriesID)},
+		"seasonNumber":        {starr.Str(params.SeasonNumber)},
+		"filterExistingFiles": {starr.Str(params.FilterExistingFiles)},
+	}}
+
+	// Decoded separately because Sonarr returns an array, not the single object starr expects
+	var data json.RawMessage
+	if err := c.client.GetInto(ctx, req, &data); err != nil {
+		return nil, fmt.Errorf("api.Get(%s): %w", &req, apiError(err))
+	}
+	outputs, err := decodeSonarrManualImport(data)
+	if err != nil {
+		return nil, err
 	}
 
-	c.logger.Debug("Found %d manual import items with custom parameters", len(result))
+	major := c.majorVersion(ctx)
+	result := make([]models.ManualImportItem, 0, len(outputs))
+	for _, output := range outputs {
+		result = append(result, mapSonarrManualImportForVersion(output, major))
+	}
 	return result, nil
 }
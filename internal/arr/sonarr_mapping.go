@@ -12,6 +12,12 @@ func mapSonarrSeriesToModels(s *sonarr.Series) models.Series {
 		return models.Series{}
 	}
 
+	var episodeFileCount *int
+	if s.Statistics != nil {
+		count := s.Statistics.EpisodeFileCount
+		episodeFileCount = &count
+	}
+
 	return models.Series{
 		MediaItem: models.MediaItem{
 			ID:    int(s.ID),
@@ -23,6 +29,7 @@ func mapSonarrSeriesToModels(s *sonarr.Series) models.Series {
 		Monitored:        s.Monitored,
 		QualityProfileID: int(s.QualityProfileID),
 		RootFolderPath:   s.RootFolderPath,
+		EpisodeFileCount: episodeFileCount,
 	}
 }
 
@@ -35,6 +42,27 @@ func mapSonarrSeriesToModelsList(series []*sonarr.Series) []models.Series {
 	return result
 }
 
+// mapSonarrSeriesLookupToModels converts a starr Series lookup result to our models.SeriesLookup
+func mapSonarrSeriesLookupToModels(s *sonarr.Series) models.SeriesLookup {
+	result := models.SeriesLookup{
+		TVDBID:   int(s.TvdbID),
+		Title:    s.Title,
+		Year:     s.Year,
+		Overview: s.Overview,
+		Images: make([]struct {
+			CoverType string `json:"coverType"`
+			URL       string `json:"url"`
+		}, len(s.Images)),
+	}
+
+	// Map images if present
+	for i, img := range s.Images {
+		result.Images[i].CoverType = img.CoverType
+		result.Images[i].URL = img.URL
+	}
+	return result
+}
+
 // mapSonarrEpisodeToModels converts a starr Episode to our models.Episode
 func mapSonarrEpisodeToModels(e *sonarr.Episode) models.Episode {
 	if e == nil {
@@ -55,6 +83,8 @@ func mapSonarrEpisodeToModels(e *sonarr.Episode) models.Episode {
 		Title:         e.Title,
 		HasFile:       e.HasFile,
 		EpisodeFileID: episodeFileID,
+
+		AbsoluteEpisodeNumber: e.AbsoluteEpisodeNumber,
 	}
 }
 
@@ -74,21 +104,46 @@ func mapSonarrEpisodeFileToModels(ef *sonarr.EpisodeFile) models.EpisodeFile {
 	}
 
 	return models.EpisodeFile{
-		ID:   int(ef.ID),
-		Path: ef.Path,
+		ID:            int(ef.ID),
+		Path:          ef.Path,
+		Size:          ef.Size,
+		Quality:       mapFileQuality(ef.Quality),
+		ReleaseGroup:  ef.ReleaseGroup,
+		CustomFormats: mapSonarrCustomFormats(ef.CustomFormats),
 	}
 }
 
+// mapSonarrCustomFormats converts the starr custom formats of a file to our models.CustomFormat
+func mapSonarrCustomFormats(formats []*sonarr.CustomFormatOutput) []models.CustomFormat {
+	var result []models.CustomFormat
+	for _, format := range formats {
+		if format != nil {
+			result = append(result, models.CustomFormat{ID: int(format.ID), Name: format.Name})
+		}
+	}
+	return result
+}
+
+// mapFileQuality converts the starr quality of a file to our models.FileQuality
+func mapFileQuality(q *starr.Quality) *models.FileQuality {
+	if q == nil || q.Quality == nil {
+		return nil
+	}
+	return &models.FileQuality{Quality: models.Quality{ID: int(q.Quality.ID), Name: q.Quality.Name}}
+}
+
 // mapSonarrRootFolderToModels converts a starr RootFolder to our models.RootFolder
 func mapSonarrRootFolderToModels(rf *sonarr.RootFolder) models.RootFolder {
 	if rf == nil {
 		return models.RootFolder{}
 	}
 
+	accessible := rf.Accessible
 	return models.RootFolder{
-		ID:   int(rf.ID),
-		Path: rf.Path,
-		Name: rf.Path, // starr doesn't have a separate name field
+		ID:         int(rf.ID),
+		Path:       rf.Path,
+		Name:       rf.Path, // starr doesn't have a separate name field
+		Accessible: &accessible,
 	}
 }
 
@@ -167,6 +222,11 @@ func mapSonarrQueueRecordToModels(qr *sonarr.QueueRecord) models.QueueItem {
 		OutputPath:     qr.OutputPath,
 		Protocol:       string(qr.Protocol),
 		DownloadClient: qr.DownloadClient,
+
+		SizeLeft:             int64(qr.Sizeleft),
+		TimeLeft:             qr.Timeleft,
+		Indexer:              qr.Indexer,
+		TrackedDownloadState: qr.TrackedDownloadState,
 	}
 }
 
@@ -198,6 +258,8 @@ func mapModelsEpisodeToSonarr(e models.Episode) *sonarr.Episode {
 		Title:         e.Title,
 		HasFile:       e.HasFile,
 		EpisodeFileID: episodeFileID,
+
+		AbsoluteEpisodeNumber: e.AbsoluteEpisodeNumber,
 	}
 }
 
@@ -327,3 +389,16 @@ func mapModelsManualImportToSonarrList(items []models.ManualImportItem) []*sonar
 	}
 	return result
 }
+
+// mapModelsSeriesToSonarrInput converts our models.Series to the starr AddSeriesInput sent to add it
+func mapModelsSeriesToSonarrInput(series models.Series) *sonarr.AddSeriesInput {
+	return &sonarr.AddSeriesInput{
+		Title:            series.Title,
+		TvdbID:           int64(series.TVDBID),
+		Path:             series.Path,
+		QualityProfileID: int64(series.QualityProfileID),
+		RootFolderPath:   series.RootFolderPath,
+		Monitored:        series.Monitored,
+		SeasonFolder:     true, // Default to true
+	}
+}
@@ -0,0 +1,162 @@
+package arr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hnipps/refresharr/pkg/models"
+	"golift.io/starr/sonarr"
+)
+
+// starr's queue and manual import types follow Sonarr v3. Sonarr v4 added fields to both
+// payloads (e.g. episodeHasFile, releaseType) that those types drop, so the client decodes
+// them into the wrappers below and maps the v4 fields only when talking to v4.
+
+// sonarrQueueRecord is a queue record with the fields starr's type lacks
+type sonarrQueueRecord struct {
+	sonarr.QueueRecord
+	Added          time.Time      `json:"added"`          // v3 and v4
+	EpisodeHasFile *bool          `json:"episodeHasFile"` // v4
+	Series         *sonarr.Series `json:"series"`         // Only with includeSeries
+}
+
+// sonarrQueuePage is a page of the queue endpoint
+type sonarrQueuePage struct {
+	Page         int                  `json:"page"`
+	PageSize     int                  `json:"pageSize"`
+	TotalRecords int                  `json:"totalRecords"`
+	Records      []*sonarrQueueRecord `json:"records"`
+}
+
+// sonarrManualImportOutput is a manual import candidate with the fields Sonarr v4 added
+type sonarrManualImportOutput struct {
+	sonarr.ManualImportOutput
+	ReleaseType string `json:"releaseType"` // v4
+}
+
+// sonarrManualImportInput is a manual import request with the fields Sonarr v4 expects
+type sonarrManualImportInput struct {
+	sonarr.ManualImportInput
+	ReleaseType string `json:"releaseType,omitempty"` // v4
+}
+
+// sonarrQueuePageSize is how many queue records are requested per page
+const sonarrQueuePageSize = 250
+
+// majorVersion returns the major version of the Sonarr server, fetched once per client.
+// Servers whose version can't be determined are treated as v3.
+func (c *SonarrClient) majorVersion(ctx context.Context) int {
+	c.versionOnce.Do(func() {
+		c.major = 3
+		status, err := c.client.GetSystemStatusContext(ctx)
+		if err != nil {
+			c.logger.Debug("Could not determine Sonarr version, assuming v3: %s", err.Error())
+			return
+		}
+		c.setVersion(status.Version)
+	})
+	return c.major
+}
+
+// setVersion records the server's version and probes its capabilities from it. Caller must
+// be inside c.versionOnce.
+func (c *SonarrClient) setVersion(version string) {
+	c.major = 3
+	if major, ok := parseMajorVersion(version); ok {
+		c.major = major
+	}
+	c.logger.Debug("Sonarr version %s (API mapping for v%d)", version, c.major)
+
+	// Manual import filters arrived with the v3 API
+	var unsupported []Feature
+	if c.major < 3 {
+		unsupported = append(unsupported, FeatureManualImportFilters)
+	}
+	c.caps.probe(version, unsupported...)
+	c.logger.Debug("Sonarr capabilities: %s", c.caps)
+}
+
+// parseMajorVersion returns the major component of a version such as "4.0.5.1710"
+func parseMajorVersion(version string) (int, bool) {
+	majorStr, _, _ := strings.Cut(version, ".")
+	major, err := strconv.Atoi(majorStr)
+	if err != nil || major <= 0 {
+		return 0, false
+	}
+	return major, true
+}
+
+// mapSonarrQueueRecordForVersion converts a queue record, including the fields the server's
+// major version provides
+func mapSonarrQueueRecordForVersion(qr *sonarrQueueRecord, major int) models.QueueItem {
+	if qr == nil {
+		return models.QueueItem{}
+	}
+
+	item := mapSonarrQueueRecordToModels(&qr.QueueRecord)
+	item.EpisodeID = int(qr.EpisodeID)
+	if !qr.Added.IsZero() {
+		added := qr.Added
+		item.Added = &added
+	}
+	if major >= 4 {
+		item.EpisodeHasFile = qr.EpisodeHasFile
+	}
+	if qr.Series != nil && qr.Series.Title != "" {
+		series := mapSonarrSeriesToModels(qr.Series)
+		item.Series = &series
+	}
+	return item
+}
+
+// mapSonarrManualImportForVersion converts a manual import candidate, including the fields
+// the server's major version provides
+func mapSonarrManualImportForVersion(mi *sonarrManualImportOutput, major int) models.ManualImportItem {
+	if mi == nil {
+		return models.ManualImportItem{}
+	}
+
+	item := mapSonarrManualImportToModels(&mi.ManualImportOutput)
+	if major >= 4 {
+		item.ReleaseType = mi.ReleaseType
+	}
+	return item
+}
+
+// mapModelsManualImportForVersion converts a manual import item to a request body for the
+// server's major version. v4 needs the release type to import multi-episode and season pack files.
+func mapModelsManualImportForVersion(item models.ManualImportItem, major int) *sonarrManualImportInput {
+	input := &sonarrManualImportInput{ManualImportInput: *mapModelsManualImportToSonarr(item)}
+	if major >= 4 {
+		input.ReleaseType = item.ReleaseType
+	}
+	return input
+}
+
+// decodeSonarrManualImport decodes the manual import endpoint's response. Sonarr returns an
+// array of candidates; a single object is accepted too.
+func decodeSonarrManualImport(data []byte) ([]*sonarrManualImportOutput, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return nil, nil
+	}
+
+	if data[0] == '{' {
+		var item sonarrManualImportOutput
+		if err := json.Unmarshal(data, &item); err != nil {
+			return nil, fmt.Errorf("failed to decode manual import item: %w", err)
+		}
+		return []*sonarrManualImportOutput{&item}, nil
+	}
+
+	var items []*sonarrManualImportOutput
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("failed to decode manual import items: %w", err)
+	}
+	return items, nil
+}
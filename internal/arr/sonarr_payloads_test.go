@@ -0,0 +1,329 @@
+package arr
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hnipps/refresharr/internal/config"
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+// newSonarrPayloadServer serves the queue and manual import fixtures for a Sonarr version and
+// records the manual import requests it receives
+func newSonarrPayloadServer(t *testing.T, version, fixturePrefix string, posted *[]map[string]interface{}) *httptest.Server {
+	t.Helper()
+	readFixture := func(name string) []byte {
+		data, err := os.ReadFile(filepath.Join("testdata", fixturePrefix+"_"+name+".json"))
+		if err != nil {
+			t.Fatalf("Failed to read fixture: %v", err)
+		}
+		return data
+	}
+	queue := readFixture("queue")
+	manualImport := readFixture("manualimport")
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/api/v3/system/status":
+			json.NewEncoder(w).Encode(map[string]string{"version": version})
+		case r.URL.Path == "/api/v3/queue":
+			w.Write(queue)
+		case r.URL.Path == "/api/v3/series/12":
+			w.Write([]byte(`{"id": 12, "title": "Show", "tvdbId": 81189, "path": "/tv/Show"}`))
+		case r.URL.Path == "/api/v3/manualimport" && r.Method == http.MethodGet:
+			w.Write(manualImport)
+		case r.URL.Path == "/api/v3/manualimport" && r.Method == http.MethodPost:
+			body, _ := io.ReadAll(r.Body)
+			var input map[string]interface{}
+			if err := json.Unmarshal(body, &input); err != nil {
+				t.Errorf("Invalid manual import body: %v", err)
+			}
+			*posted = append(*posted, input)
+			w.Write([]byte("[]"))
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestSonarrClient_QueueAndManualImport_V3(t *testing.T) {
+	var posted []map[string]interface{}
+	server := newSonarrPayloadServer(t, "3.0.10.1567", "sonarr_v3", &posted)
+	defer server.Close()
+
+	client := NewSonarrClient(&config.SonarrConfig{URL: server.URL, APIKey: "test-key"}, 5*time.Second, &mockLogger{})
+	ctx := context.Background()
+
+	queue, err := client.GetQueue(ctx)
+	if err != nil {
+		t.Fatalf("GetQueue() failed: %v", err)
+	}
+	if len(queue) != 1 {
+		t.Fatalf("Expected 1 queue item, got %d", len(queue))
+	}
+	item := queue[0]
+	if item.ID != 501 || item.EpisodeID != 340 || item.Series == nil || item.Series.ID != 12 {
+		t.Errorf("Unexpected queue item: %+v", item)
+	}
+	if item.EpisodeHasFile != nil {
+		t.Errorf("Expected no episodeHasFile from v3, got %v", *item.EpisodeHasFile)
+	}
+	if item.SizeLeft != 0 || item.TimeLeft != "00:00:00" || item.Indexer == "" || item.TrackedDownloadState != "importPending" {
+		t.Errorf("Expected download progress and origin, got %+v", item)
+	}
+	if item.Added == nil || item.Added.IsZero() {
+		t.Error("Expected the added date")
+	}
+	if item.Series.Title != "Show" {
+		t.Errorf("Expected the series title to be resolved, got %q", item.Series.Title)
+	}
+
+	files, err := client.GetManualImportWithParams(ctx, item.OutputPath, "", 12, true)
+	if err != nil {
+		t.Fatalf("GetManualImportWithParams() failed: %v", err)
+	}
+	if len(files) != 1 || files[0].ReleaseType != "" || len(files[0].Episodes) != 1 || files[0].Episodes[0].ID != 340 {
+		t.Fatalf("Unexpected manual import items: %+v", files)
+	}
+	if len(files[0].Rejections) != 1 || files[0].Rejections[0] != "Episode file already imported" {
+		t.Errorf("Expected the rejection reason, got %v", files[0].Rejections)
+	}
+
+	if err := client.ExecuteManualImport(ctx, files, "move"); err != nil {
+		t.Fatalf("ExecuteManualImport() failed: %v", err)
+	}
+	if len(posted) != 1 {
+		t.Fatalf("Expected 1 manual import request, got %d", len(posted))
+	}
+	if _, ok := posted[0]["releaseType"]; ok {
+		t.Error("Expected no releaseType to be sent to v3")
+	}
+}
+
+func TestSonarrClient_QueueAndManualImport_V4(t *testing.T) {
+	var posted []map[string]interface{}
+	server := newSonarrPayloadServer(t, "4.0.5.1710", "sonarr_v4", &posted)
+	defer server.Close()
+
+	client := NewSonarrClient(&config.SonarrConfig{URL: server.URL, APIKey: "test-key"}, 5*time.Second, &mockLogger{})
+	ctx := context.Background()
+
+	queue, err := client.GetQueue(ctx)
+	if err != nil {
+		t.Fatalf("GetQueue() failed: %v", err)
+	}
+	if len(queue) != 2 {
+		t.Fatalf("Expected 2 queue items, got %d", len(queue))
+	}
+	if queue[0].EpisodeHasFile == nil || !*queue[0].EpisodeHasFile {
+		t.Errorf("Expected episodeHasFile true for item 602, got %+v", queue[0])
+	}
+	if queue[1].EpisodeHasFile == nil || *queue[1].EpisodeHasFile {
+		t.Errorf("Expected episodeHasFile false for item 603, got %+v", queue[1])
+	}
+
+	files, err := client.GetManualImportWithParams(ctx, queue[0].OutputPath, "", 12, true)
+	if err != nil {
+		t.Fatalf("GetManualImportWithParams() failed: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("Expected 2 manual import items (an array), got %d", len(files))
+	}
+	if files[0].ReleaseType != "seasonPack" {
+		t.Errorf("Expected releaseType seasonPack, got %q", files[0].ReleaseType)
+	}
+
+	if err := client.ExecuteManualImport(ctx, files[:1], "move"); err != nil {
+		t.Fatalf("ExecuteManualImport() failed: %v", err)
+	}
+	if len(posted) != 1 || posted[0]["releaseType"] != "seasonPack" {
+		t.Errorf("Expected releaseType seasonPack to be sent to v4, got %v", posted)
+	}
+}
+
+func TestImportFixer_V4Payloads(t *testing.T) {
+	var posted []map[string]interface{}
+	server := newSonarrPayloadServer(t, "4.0.5.1710", "sonarr_v4", &posted)
+	defer server.Close()
+
+	client := NewSonarrClient(&config.SonarrConfig{URL: server.URL, APIKey: "test-key"}, 5*time.Second, &mockLogger{})
+	fixer := NewImportFixer(client, &mockLogger{}, true)
+	ctx := context.Background()
+
+	// "Not an upgrade" only counts as stuck because v4 says the episode already has a file
+	stuck, err := fixer.AnalyzeStuckImports(ctx)
+	if err != nil {
+		t.Fatalf("AnalyzeStuckImports() failed: %v", err)
+	}
+	if len(stuck) != 1 || stuck[0].ID != 602 {
+		t.Fatalf("Expected only queue item 602 to be stuck, got %+v", stuck)
+	}
+
+	// Both season pack files belong to the series, but only one to the queue item's episode
+	files, err := client.GetManualImportWithParams(ctx, stuck[0].OutputPath, "", 12, true)
+	if err != nil {
+		t.Fatalf("GetManualImportWithParams() failed: %v", err)
+	}
+	matched := matchingFiles(files, stuck[0], &mockLogger{})
+	if len(matched) != 1 || matched[0].Episodes[0].ID != 341 {
+		t.Errorf("Expected only the file for episode 341 to match, got %+v", matched)
+	}
+}
+
+func TestDecodeSonarrManualImport(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected int
+	}{
+		{name: "array", data: `[{"id": 1}, {"id": 2}]`, expected: 2},
+		{name: "single object", data: `{"id": 1}`, expected: 1},
+		{name: "empty array", data: `[]`, expected: 0},
+		{name: "null", data: `null`, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := decodeSonarrManualImport([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("decodeSonarrManualImport() failed: %v", err)
+			}
+			if len(items) != tt.expected {
+				t.Errorf("Expected %d items, got %d", tt.expected, len(items))
+			}
+		})
+	}
+}
+
+func TestParseMajorVersion(t *testing.T) {
+	tests := map[string]int{"4.0.5.1710": 4, "3.0.10.1567": 3, "": 0, "v4": 0}
+	for version, expected := range tests {
+		major, ok := parseMajorVersion(version)
+		if major != expected || ok != (expected > 0) {
+			t.Errorf("parseMajorVersion(%q) = %d, %v; want %d", version, major, ok, expected)
+		}
+	}
+}
+
+func TestSonarrClient_ResolveQueueSeries(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v3/series":
+			var series []map[string]interface{}
+			for id := 1; id <= 6; id++ {
+				series = append(series, map[string]interface{}{"id": id, "title": "Show " + strconv.Itoa(id)})
+			}
+			json.NewEncoder(w).Encode(series)
+		case "/api/v3/series/1":
+			w.Write([]byte(`{"id": 1, "title": "Show 1"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewSonarrClient(&config.SonarrConfig{URL: server.URL, APIKey: "test-key"}, 5*time.Second, &mockLogger{}).(*SonarrClient)
+	ctx := context.Background()
+
+	t.Run("few series are looked up individually", func(t *testing.T) {
+		requests = nil
+		items := []models.QueueItem{
+			{ID: 1, Series: &models.Series{MediaItem: models.MediaItem{ID: 1}}},
+			{ID: 2, Series: &models.Series{MediaItem: models.MediaItem{ID: 1}}},
+			{ID: 3, Series: &models.Series{MediaItem: models.MediaItem{ID: 99}}},
+			{ID: 4},
+		}
+		client.resolveQueueSeries(ctx, items)
+
+		if items[0].Series.Title != "Show 1" || items[1].Series.Title != "Show 1" {
+			t.Errorf("Expected series 1 to be resolved, got %+v and %+v", items[0].Series, items[1].Series)
+		}
+		if items[2].Series.ID != 99 || items[2].Series.Title != "" {
+			t.Errorf("Expected unresolvable series to keep its ID, got %+v", items[2].Series)
+		}
+		if items[3].Series != nil {
+			t.Errorf("Expected no series for item without one, got %+v", items[3].Series)
+		}
+		if len(requests) != 2 {
+			t.Errorf("Expected one request per distinct series, got %v", requests)
+		}
+	})
+
+	t.Run("many series are fetched at once", func(t *testing.T) {
+		requests = nil
+		var items []models.QueueItem
+		for id := 1; id <= queueSeriesLookupLimit+1; id++ {
+			items = append(items, models.QueueItem{ID: id, Series: &models.Series{MediaItem: models.MediaItem{ID: id}}})
+		}
+		client.resolveQueueSeries(ctx, items)
+
+		for _, item := range items {
+			if item.Series.Title != "Show "+strconv.Itoa(item.Series.ID) {
+				t.Errorf("Expected series %d to be resolved, got %q", item.Series.ID, item.Series.Title)
+			}
+		}
+		if len(requests) != 1 || requests[0] != "/api/v3/series" {
+			t.Errorf("Expected a single request for all series, got %v", requests)
+		}
+	})
+}
+
+func TestSonarrClient_GetQueueWithOptions(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/system/status":
+			w.Write([]byte(`{"version":"3.0.10.1567"}`))
+		case "/api/v3/queue":
+			queries = append(queries, r.URL.RawQuery)
+			// Like Sonarr v3, ignore the status filter
+			w.Write([]byte(`{"page":1,"pageSize":250,"totalRecords":2,"records":[
+				{"id":1,"seriesId":7,"status":"completed","series":{"id":7,"title":"Embedded Show"}},
+				{"id":2,"seriesId":7,"status":"downloading","series":{"id":7,"title":"Embedded Show"}}]}`))
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewSonarrClient(&config.SonarrConfig{URL: server.URL, APIKey: "test-key"}, 5*time.Second, &mockLogger{})
+	queue, err := client.GetQueueWithOptions(context.Background(), models.QueueOptions{
+		Statuses:      []string{"completed"},
+		IncludeSeries: true,
+		SortKey:       "added",
+		SortDirection: "descending",
+	})
+	if err != nil {
+		t.Fatalf("GetQueueWithOptions() failed: %v", err)
+	}
+
+	if len(queries) != 1 {
+		t.Fatalf("Expected 1 queue request, got %d", len(queries))
+	}
+	for _, param := range []string{"status=completed", "includeSeries=true", "sortKey=added", "sortDirection=descending", "includeUnknownSeriesItems=false"} {
+		if !strings.Contains(queries[0], param) {
+			t.Errorf("Expected %s in query %s", param, queries[0])
+		}
+	}
+	if len(queue) != 1 || queue[0].ID != 1 {
+		t.Fatalf("Expected only the completed item, got %+v", queue)
+	}
+	if queue[0].Series == nil || queue[0].Series.Title != "Embedded Show" {
+		t.Errorf("Expected the embedded series, got %+v", queue[0].Series)
+	}
+}
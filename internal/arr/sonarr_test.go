@@ -3,6 +3,7 @@ package arr
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -377,8 +378,8 @@ func TestSonarrClient_HTTPError(t *testing.T) {
 
 	// Test various operations should fail
 	_, err := client.GetAllSeries(ctx)
-	if err == nil {
-		t.Error("Expected GetAllSeries() to fail with HTTP error")
+	if !errors.Is(err, ErrServerError) {
+		t.Errorf("Expected GetAllSeries() to fail with ErrServerError, got %v", err)
 	}
 
 	_, err = client.GetEpisodesForSeries(ctx, 1)
@@ -0,0 +1,187 @@
+package arr
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+// defaultSpillAfter is how many missing file entries are kept in memory before a run
+// starts spilling them to a temporary file
+const defaultSpillAfter = 10000
+
+// missingFileSpool collects missing file entries. The first limit entries are kept in
+// memory; once there are more, all entries are written to a temporary JSON lines file
+// so mass-missing events on large libraries don't grow memory without bound.
+// It is not safe for concurrent use; callers hold missingFilesMu.
+type missingFileSpool struct {
+	limit   int // Entries kept in memory before spilling (negative means never spill)
+	entries []models.MissingFileEntry
+	file    *os.File
+	writer  *bufio.Writer
+}
+
+// newMissingFileSpool creates a spool that spills after limit entries (0 means the default)
+func newMissingFileSpool(limit int) *missingFileSpool {
+	if limit == 0 {
+		limit = defaultSpillAfter
+	}
+	return &missingFileSpool{limit: limit}
+}
+
+// add records an entry, spilling to disk once the in-memory limit is exceeded
+func (sp *missingFileSpool) add(entry models.MissingFileEntry) error {
+	if sp.file == nil && (sp.limit < 0 || len(sp.entries) < sp.limit) {
+		sp.entries = append(sp.entries, entry)
+		return nil
+	}
+
+	if sp.file == nil {
+		file, err := os.CreateTemp("", "refresharr-missing-*.jsonl")
+		if err != nil {
+			// Keep collecting in memory rather than losing entries
+			sp.entries = append(sp.entries, entry)
+			return fmt.Errorf("failed to create spill file: %w", err)
+		}
+		sp.file = file
+		sp.writer = bufio.NewWriter(file)
+
+		buffered := sp.entries
+		sp.entries = nil
+		for _, e := range buffered {
+			if err := sp.write(e); err != nil {
+				return err
+			}
+		}
+	}
+
+	return sp.write(entry)
+}
+
+// write appends an entry to the spill file
+func (sp *missingFileSpool) write(entry models.MissingFileEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal missing file entry: %w", err)
+	}
+	data = append(data, '\n')
+	if _, err := sp.writer.Write(data); err != nil {
+		return fmt.Errorf("failed to write spill file: %w", err)
+	}
+	return nil
+}
+
+// isSpilled reports whether the entries are on disk rather than in memory
+func (sp *missingFileSpool) isSpilled() bool {
+	return sp.file != nil
+}
+
+// deduplicated returns the spilled entries with duplicates removed, using the same rules as
+// deduplicateMissingFiles. Only the key of each entry and the position of the best entry per
+// key are held in memory; the entries stay on disk and keep the order they were collected in.
+// The returned source takes over the spill file, so the spool must not be used afterwards.
+func (sp *missingFileSpool) deduplicated() (*spilledMissingFiles, error) {
+	if err := sp.writer.Flush(); err != nil {
+		return nil, fmt.Errorf("failed to flush spill file: %w", err)
+	}
+
+	source := &spilledMissingFiles{path: sp.file.Name()}
+	if err := sp.file.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close spill file: %w", err)
+	}
+
+	type best struct {
+		index int
+		entry models.MissingFileEntry // Only the fields used to compare entries are kept
+	}
+	bestByKey := make(map[string]best)
+
+	index := 0
+	err := source.scan(func(entry models.MissingFileEntry) error {
+		key := missingFileKey(entry)
+		if existing, exists := bestByKey[key]; !exists || preferMissingFile(entry, existing.entry) {
+			bestByKey[key] = best{
+				index: index,
+				entry: models.MissingFileEntry{FileID: entry.FileID, ProcessedAt: entry.ProcessedAt},
+			}
+		}
+		index++
+		return nil
+	})
+	if err != nil {
+		source.Close()
+		return nil, err
+	}
+
+	source.keep = make(map[int]bool, len(bestByKey))
+	for _, b := range bestByKey {
+		source.keep[b.index] = true
+	}
+	return source, nil
+}
+
+// close removes the spill file, if there is one
+func (sp *missingFileSpool) close() {
+	if sp.file != nil {
+		sp.file.Close()
+		os.Remove(sp.file.Name())
+	}
+}
+
+// spilledMissingFiles streams deduplicated missing file entries back from a spill file
+type spilledMissingFiles struct {
+	path string
+	keep map[int]bool // Positions in the file of the entries that survived deduplication
+}
+
+// Len returns the number of entries the source yields
+func (s *spilledMissingFiles) Len() int {
+	return len(s.keep)
+}
+
+// Each calls fn for every deduplicated entry in collection order
+func (s *spilledMissingFiles) Each(fn func(models.MissingFileEntry) error) error {
+	index := 0
+	return s.scan(func(entry models.MissingFileEntry) error {
+		keep := s.keep[index]
+		index++
+		if !keep {
+			return nil
+		}
+		return fn(entry)
+	})
+}
+
+// Close removes the spill file
+func (s *spilledMissingFiles) Close() error {
+	if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove spill file: %w", err)
+	}
+	return nil
+}
+
+// scan decodes every entry in the spill file
+func (s *spilledMissingFiles) scan(fn func(models.MissingFileEntry) error) error {
+	file, err := os.Open(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to open spill file: %w", err)
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(bufio.NewReader(file))
+	for {
+		var entry models.MissingFileEntry
+		if err := decoder.Decode(&entry); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("failed to read spill file: %w", err)
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+}
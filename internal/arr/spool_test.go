@@ -0,0 +1,126 @@
+package arr
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+func TestMissingFileSpool_SpillsAndDeduplicates(t *testing.T) {
+	spool := newMissingFileSpool(2)
+	entries := []models.MissingFileEntry{
+		{MediaType: "movie", TMDBID: 705, FilePath: "/movies/a.mkv", FileID: 0, ProcessedAt: "2025-09-02T17:43:55Z"},
+		{MediaType: "movie", FilePath: "/movies/b.mkv", FileID: 2, ProcessedAt: "2025-09-02T17:43:56Z"},
+		{MediaType: "movie", TMDBID: 705, FilePath: "/movies/a.mkv", FileID: 1607, ProcessedAt: "2025-09-02T17:44:18Z"},
+		{MediaType: "movie", FilePath: "/movies/c.mkv", FileID: 3, ProcessedAt: "2025-09-02T17:44:20Z"},
+	}
+	for _, entry := range entries {
+		if err := spool.add(entry); err != nil {
+			t.Fatalf("add() failed: %v", err)
+		}
+	}
+
+	if !spool.isSpilled() || len(spool.entries) != 0 {
+		t.Fatalf("Expected all entries to be spilled past the limit, %d still in memory", len(spool.entries))
+	}
+
+	source, err := spool.deduplicated()
+	if err != nil {
+		t.Fatalf("deduplicated() failed: %v", err)
+	}
+	if source.Len() != 3 {
+		t.Errorf("Expected 3 entries after deduplication, got %d", source.Len())
+	}
+
+	var got []models.MissingFileEntry
+	if err := source.Each(func(entry models.MissingFileEntry) error {
+		got = append(got, entry)
+		return nil
+	}); err != nil {
+		t.Fatalf("Each() failed: %v", err)
+	}
+
+	wantPaths := []string{"/movies/b.mkv", "/movies/a.mkv", "/movies/c.mkv"}
+	if len(got) != len(wantPaths) {
+		t.Fatalf("Expected %d entries, got %+v", len(wantPaths), got)
+	}
+	for i, path := range wantPaths {
+		if got[i].FilePath != path {
+			t.Errorf("Entry %d: expected %s, got %s", i, path, got[i].FilePath)
+		}
+	}
+	if got[1].FileID != 1607 {
+		t.Errorf("Expected the entry with a real FileID to win, got FileID %d", got[1].FileID)
+	}
+
+	if err := source.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if _, err := os.Stat(source.path); !os.IsNotExist(err) {
+		t.Errorf("Expected spill file to be removed, got %v", err)
+	}
+}
+
+func TestMissingFileSpool_NegativeLimitNeverSpills(t *testing.T) {
+	spool := newMissingFileSpool(-1)
+	for i := 0; i < 5; i++ {
+		if err := spool.add(models.MissingFileEntry{FileID: i}); err != nil {
+			t.Fatalf("add() failed: %v", err)
+		}
+	}
+	if spool.isSpilled() || len(spool.entries) != 5 {
+		t.Errorf("Expected 5 entries in memory, spilled=%v", spool.isSpilled())
+	}
+}
+
+func TestCleanupService_SpillsMissingFiles(t *testing.T) {
+	client := &mockClient{
+		name: "sonarr",
+		episodes: map[int][]models.Episode{
+			1: {
+				{ID: 1, SeriesID: 1, SeasonNumber: 1, EpisodeNumber: 1, HasFile: true, EpisodeFileID: intPtr(100)},
+				{ID: 2, SeriesID: 1, SeasonNumber: 1, EpisodeNumber: 2, HasFile: true, EpisodeFileID: intPtr(200)},
+				{ID: 3, SeriesID: 1, SeasonNumber: 1, EpisodeNumber: 3, HasFile: true, EpisodeFileID: intPtr(300)},
+			},
+		},
+		episodeFiles: map[int]*models.EpisodeFile{
+			100: {ID: 100, Path: "/tv/show/s01e01.mkv"},
+			200: {ID: 200, Path: "/tv/show/s01e02.mkv"},
+			300: {ID: 300, Path: "/tv/show/s01e03.mkv"},
+		},
+	}
+	fileChecker := &mockFileChecker{fileExists: map[string]bool{}}
+
+	service := NewCleanupServiceWithOptions(client, fileChecker, &mockLogger{}, &mockProgressReporter{}, CleanupOptions{
+		ConcurrentLimit: 1,
+		DryRun:          true,
+		SpillAfter:      1,
+	})
+
+	result, err := service.CleanupMissingFilesForSeries(context.Background(), []int{1})
+	if err != nil {
+		t.Fatalf("CleanupMissingFilesForSeries() failed: %v", err)
+	}
+
+	report := result.Report
+	defer report.Close()
+	if report.Spilled == nil || report.MissingFiles != nil {
+		t.Fatalf("Expected the report to stream spilled entries, got %+v", report)
+	}
+	if report.TotalMissing != 3 {
+		t.Errorf("Expected 3 missing files, got %d", report.TotalMissing)
+	}
+
+	count := 0
+	if err := report.EachMissingFile(func(models.MissingFileEntry) error {
+		count++
+		return nil
+	}); err != nil {
+		t.Fatalf("EachMissingFile() failed: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected to stream 3 entries, got %d", count)
+	}
+}
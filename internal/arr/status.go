@@ -0,0 +1,206 @@
+package arr
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+// Phases a run reports to PhaseReporters, in the order they happen
+const (
+	PhaseConnecting  = "connecting"   // Testing the connection to the service
+	PhaseFetching    = "fetching"     // Fetching the library from the service
+	PhaseSymlinkScan = "symlink-scan" // Scanning root folders for broken symlinks
+	PhaseChecking    = "checking"     // Checking each item's files on disk
+	PhaseSearching   = "searching"    // Triggering the missing search
+)
+
+// RunStatus is a snapshot of the progress of a run in flight
+type RunStatus struct {
+	RunID          string     `json:"runId"`
+	Command        string     `json:"command,omitempty"`
+	Service        string     `json:"service,omitempty"`
+	DryRun         bool       `json:"dryRun,omitempty"`
+	Phase          string     `json:"phase,omitempty"`
+	Processed      int        `json:"processed"`
+	Total          int        `json:"total"`
+	MissingFiles   int        `json:"missingFiles"`
+	DeletedRecords int        `json:"deletedRecords"`
+	Errors         int        `json:"errors"`
+	StartedAt      *time.Time `json:"startedAt,omitempty"`
+	ElapsedSeconds float64    `json:"elapsedSeconds"`
+}
+
+// StatusTracker follows the runs in flight through the progress they report, so a long-running
+// process can show what it is doing. Runs are kept apart by their run ID, as serve runs a job
+// per service at the same time. A nil *StatusTracker tracks nothing.
+type StatusTracker struct {
+	mu   sync.Mutex
+	runs map[string]*RunStatus
+	now  func() time.Time
+}
+
+// NewStatusTracker creates a StatusTracker with no run in flight
+func NewStatusTracker() *StatusTracker {
+	return &StatusTracker{runs: make(map[string]*RunStatus), now: time.Now}
+}
+
+// Track starts tracking the run runID of command against service and returns a progress
+// reporter that records the run's progress before passing it on to next. The run is tracked
+// until Done is called with its ID.
+func (t *StatusTracker) Track(runID, command, service string, dryRun bool, next ProgressReporter) ProgressReporter {
+	if t == nil {
+		return next
+	}
+
+	startedAt := t.now().UTC()
+	t.mu.Lock()
+	t.runs[runID] = &RunStatus{
+		RunID:     runID,
+		Command:   command,
+		Service:   service,
+		DryRun:    dryRun,
+		Phase:     PhaseConnecting,
+		StartedAt: &startedAt,
+	}
+	t.mu.Unlock()
+
+	return &trackedReporter{tracker: t, runID: runID, next: next}
+}
+
+// Done marks the run runID as finished, leaving the other runs tracked. It is safe to call more
+// than once.
+func (t *StatusTracker) Done(runID string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.runs, runID)
+}
+
+// Status returns a snapshot of every run in flight, oldest first, or none between runs
+func (t *StatusTracker) Status() []RunStatus {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	runs := make([]RunStatus, 0, len(t.runs))
+	now := t.now()
+	for _, run := range t.runs {
+		status := *run
+		startedAt := *status.StartedAt
+		status.StartedAt = &startedAt
+		status.ElapsedSeconds = now.Sub(startedAt).Seconds()
+		runs = append(runs, status)
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		if !runs[i].StartedAt.Equal(*runs[j].StartedAt) {
+			return runs[i].StartedAt.Before(*runs[j].StartedAt)
+		}
+		return runs[i].RunID < runs[j].RunID
+	})
+	return runs
+}
+
+// update applies fn to the run runID, if it is still tracked
+func (t *StatusTracker) update(runID string, fn func(status *RunStatus)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if status, ok := t.runs[runID]; ok {
+		fn(status)
+	}
+}
+
+// trackedReporter records the progress of a run on its tracker and passes it on
+type trackedReporter struct {
+	tracker *StatusTracker
+	runID   string
+	next    ProgressReporter
+}
+
+// StartPhase records the run's new phase and how many items it works through
+func (r *trackedReporter) StartPhase(phase string, total int) {
+	r.tracker.update(r.runID, func(status *RunStatus) {
+		status.Phase = phase
+		if total > 0 {
+			status.Processed = 0
+			status.Total = total
+		}
+	})
+	if next, ok := r.next.(PhaseReporter); ok {
+		next.StartPhase(phase, total)
+	}
+}
+
+// FinishItem counts an item of the current phase as processed
+func (r *trackedReporter) FinishItem() {
+	r.tracker.update(r.runID, func(status *RunStatus) {
+		status.Processed++
+	})
+	if next, ok := r.next.(PhaseReporter); ok {
+		next.FinishItem()
+	}
+}
+
+func (r *trackedReporter) StartSeries(seriesID int, seriesName string, current, total int) {
+	r.next.StartSeries(seriesID, seriesName, current, total)
+}
+
+func (r *trackedReporter) StartEpisode(episodeID int, seasonNum, episodeNum int) {
+	r.next.StartEpisode(episodeID, seasonNum, episodeNum)
+}
+
+func (r *trackedReporter) StartMovie(movieID int, movieName string, current, total int) {
+	r.next.StartMovie(movieID, movieName, current, total)
+}
+
+func (r *trackedReporter) ReportMissingFile(filePath string) {
+	r.tracker.update(r.runID, func(status *RunStatus) {
+		status.MissingFiles++
+	})
+	r.next.ReportMissingFile(filePath)
+}
+
+func (r *trackedReporter) ReportDeletedRecord(fileID int) {
+	r.countDeleted()
+	r.next.ReportDeletedRecord(fileID)
+}
+
+func (r *trackedReporter) ReportDeletedEpisodeRecord(fileID int) {
+	r.countDeleted()
+	r.next.ReportDeletedEpisodeRecord(fileID)
+}
+
+func (r *trackedReporter) ReportDeletedMovieRecord(fileID int) {
+	r.countDeleted()
+	r.next.ReportDeletedMovieRecord(fileID)
+}
+
+func (r *trackedReporter) ReportError(err error) {
+	r.tracker.update(r.runID, func(status *RunStatus) {
+		status.Errors++
+	})
+	r.next.ReportError(err)
+}
+
+// Finish replaces the running counts with the run's final statistics
+func (r *trackedReporter) Finish(stats models.CleanupStats) {
+	r.tracker.update(r.runID, func(status *RunStatus) {
+		status.MissingFiles = stats.MissingFiles
+		status.DeletedRecords = stats.DeletedRecords
+		status.Errors = stats.Errors
+	})
+	r.next.Finish(stats)
+}
+
+// countDeleted counts a deleted file record
+func (r *trackedReporter) countDeleted() {
+	r.tracker.update(r.runID, func(status *RunStatus) {
+		status.DeletedRecords++
+	})
+}
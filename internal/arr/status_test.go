@@ -0,0 +1,149 @@
+package arr
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStatusTracker_Track(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC)
+	tracker := NewStatusTracker()
+	tracker.now = func() time.Time { return now }
+
+	if runs := tracker.Status(); len(runs) != 0 {
+		t.Fatalf("Expected no run in flight, got %+v", runs)
+	}
+
+	next := &mockProgressReporter{}
+	reporter := tracker.Track("run-1", "cleanup", "radarr-4k", true, next)
+	phases := reporter.(PhaseReporter)
+
+	phases.StartPhase(PhaseFetching, 0)
+	phases.StartPhase(PhaseChecking, 3)
+	phases.FinishItem()
+	phases.FinishItem()
+	reporter.ReportMissingFile("/movies/Heat (1995)/Heat.mkv")
+	reporter.ReportDeletedMovieRecord(7)
+	reporter.ReportError(errors.New("boom"))
+	now = now.Add(90 * time.Second)
+
+	runs := tracker.Status()
+	if len(runs) != 1 {
+		t.Fatalf("Expected one run in flight, got %+v", runs)
+	}
+	status := runs[0]
+	if status.RunID != "run-1" || status.Command != "cleanup" || status.Service != "radarr-4k" || !status.DryRun {
+		t.Errorf("Unexpected run details: %+v", status)
+	}
+	if status.Phase != PhaseChecking || status.Processed != 2 || status.Total != 3 {
+		t.Errorf("Expected 2/3 processed while checking, got %s %d/%d", status.Phase, status.Processed, status.Total)
+	}
+	if status.MissingFiles != 1 || status.DeletedRecords != 1 || status.Errors != 1 {
+		t.Errorf("Unexpected counts: %+v", status)
+	}
+	if status.ElapsedSeconds != 90 {
+		t.Errorf("Expected 90s elapsed, got %v", status.ElapsedSeconds)
+	}
+
+	// The progress is passed on
+	if len(next.missingFilesReported) != 1 || len(next.deletedRecords) != 1 || len(next.errors) != 1 {
+		t.Errorf("Expected the progress to be passed on, got %+v", next)
+	}
+
+	tracker.Done("run-1")
+	if runs := tracker.Status(); len(runs) != 0 {
+		t.Errorf("Expected no run in flight after Done, got %+v", runs)
+	}
+
+	// Progress reported after the run is done doesn't show up
+	reporter.ReportMissingFile("/movies/Ronin (1998)/Ronin.mkv")
+	if runs := tracker.Status(); len(runs) != 0 {
+		t.Errorf("Expected nothing tracked after Done, got %+v", runs)
+	}
+}
+
+func TestStatusTracker_ConcurrentRuns(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC)
+	tracker := NewStatusTracker()
+	tracker.now = func() time.Time { return now }
+
+	sonarr := tracker.Track("run-sonarr", "cleanup", "sonarr", false, &mockProgressReporter{})
+	now = now.Add(time.Second)
+	radarr := tracker.Track("run-radarr", "cleanup", "radarr", true, &mockProgressReporter{})
+
+	// Both runs report progress at the same time, each to its own entry
+	var wg sync.WaitGroup
+	for _, reporter := range []ProgressReporter{sonarr, radarr} {
+		wg.Add(1)
+		go func(reporter ProgressReporter) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				reporter.ReportMissingFile("/media/file.mkv")
+			}
+		}(reporter)
+	}
+	wg.Wait()
+	radarr.ReportDeletedMovieRecord(7)
+
+	runs := tracker.Status()
+	if len(runs) != 2 || runs[0].Service != "sonarr" || runs[1].Service != "radarr" {
+		t.Fatalf("Expected the sonarr and radarr runs, oldest first, got %+v", runs)
+	}
+	if runs[0].MissingFiles != 50 || runs[0].DeletedRecords != 0 || runs[1].MissingFiles != 50 || runs[1].DeletedRecords != 1 {
+		t.Errorf("Expected each run to keep its own counts, got %+v", runs)
+	}
+
+	// The first run to finish leaves the other one tracked
+	tracker.Done("run-sonarr")
+	runs = tracker.Status()
+	if len(runs) != 1 || runs[0].RunID != "run-radarr" || runs[0].MissingFiles != 50 {
+		t.Errorf("Expected the radarr run to still be tracked, got %+v", runs)
+	}
+	sonarr.ReportMissingFile("/tv/late.mkv")
+	if runs := tracker.Status(); len(runs) != 1 {
+		t.Errorf("Expected progress of a finished run to be dropped, got %+v", runs)
+	}
+}
+
+func TestStatusTracker_Nil(t *testing.T) {
+	var tracker *StatusTracker
+	next := &mockProgressReporter{}
+	if reporter := tracker.Track("run-1", "cleanup", "sonarr", false, next); reporter != next {
+		t.Error("Expected a nil tracker to return the reporter unchanged")
+	}
+	tracker.Done("run-1")
+	if runs := tracker.Status(); len(runs) != 0 {
+		t.Errorf("Expected a nil tracker to report no run, got %+v", runs)
+	}
+}
+
+func TestStatusTracker_CleanupBooks(t *testing.T) {
+	client := newTestBookLibrary()
+	fileChecker := &mockFileChecker{fileExists: map[string]bool{"/books/Frank Herbert/Dune.epub": true}}
+	tracker := NewStatusTracker()
+
+	reporter := tracker.Track("run-1", "cleanup", "readarr", false, &mockProgressReporter{})
+	service := NewCleanupService(client, fileChecker, &mockLogger{}, reporter, 0, false)
+	if _, err := service.CleanupMissingFiles(context.Background()); err != nil {
+		t.Fatalf("CleanupMissingFiles() failed: %v", err)
+	}
+
+	// The run stays tracked until its caller is done with it
+	runs := tracker.Status()
+	if len(runs) != 1 {
+		t.Fatalf("Expected the run to still be tracked, got %+v", runs)
+	}
+	status := runs[0]
+	if status.Phase != PhaseSearching {
+		t.Errorf("Expected the run to end searching, got %q", status.Phase)
+	}
+	if status.Processed != 1 || status.Total != 1 {
+		t.Errorf("Expected 1/1 books processed, got %d/%d", status.Processed, status.Total)
+	}
+	if status.MissingFiles != 1 || status.DeletedRecords != 1 {
+		t.Errorf("Unexpected counts: %+v", status)
+	}
+}
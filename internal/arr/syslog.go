@@ -0,0 +1,272 @@
+package arr
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultJournalSocket is where systemd-journald accepts native protocol messages
+const DefaultJournalSocket = "/run/systemd/journal/socket"
+
+// syslogAppName is the APP-NAME of syslog messages and the SYSLOG_IDENTIFIER of journal entries
+const syslogAppName = "refresharr"
+
+// syslogFacilities maps facility names to their RFC 5424 codes
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5, "lpr": 6, "news": 7,
+	"uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19, "local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// severity returns the syslog severity of a log level, which journald uses as PRIORITY too
+func severity(level LogLevel) int {
+	switch level {
+	case LogLevelDebug:
+		return 7
+	case LogLevelInfo:
+		return 6
+	case LogLevelWarn:
+		return 4
+	default:
+		return 3
+	}
+}
+
+// logSink delivers a formatted log line somewhere other than stderr
+type logSink interface {
+	send(level LogLevel, msg string, fields ItemFields) error
+}
+
+// sinkHandler is a slog.Handler writing to a logSink. Item fields are picked out of the line's
+// attributes; other attributes aren't sent. Lines that can't be delivered go to stderr instead,
+// so they aren't lost while the syslog server or journal is unavailable.
+type sinkHandler struct {
+	sink   logSink
+	fields ItemFields // Set with WithAttrs
+}
+
+// Enabled leaves filtering to the logger
+func (h *sinkHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+// Handle sends a line
+func (h *sinkHandler) Handle(_ context.Context, record slog.Record) error {
+	fields := h.fields
+	record.Attrs(func(attr slog.Attr) bool {
+		fields, _ = fields.withAttr(attr)
+		return true
+	})
+	msg := strings.TrimSpace(record.Message)
+	if err := h.sink.send(logLevelOf(record.Level), msg, fields); err != nil {
+		fmt.Fprintf(os.Stderr, "%s (log delivery failed: %s)\n", msg, err.Error())
+	}
+	return nil
+}
+
+// WithAttrs returns a handler sending the item fields among the attributes with every line
+func (h *sinkHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	derived := &sinkHandler{sink: h.sink, fields: h.fields}
+	for _, attr := range attrs {
+		derived.fields, _ = derived.fields.withAttr(attr)
+	}
+	return derived
+}
+
+// WithGroup returns the handler unchanged; grouped attributes aren't sent
+func (h *sinkHandler) WithGroup(string) slog.Handler {
+	return h
+}
+
+// NewSyslogLogger creates a logger sending RFC 5424 messages with the facility to a syslog
+// server. addr is udp://host:port or tcp://host:port, and empty for the local /dev/log socket.
+// Item fields are sent as structured data.
+func NewSyslogLogger(levelStr, addr, facility string) (Logger, error) {
+	handler, err := NewSyslogHandler(addr, facility)
+	if err != nil {
+		return nil, err
+	}
+	return NewSlogLogger(handler, LoggerOptions{Level: levelStr}), nil
+}
+
+// NewSyslogHandler creates the slog.Handler behind NewSyslogLogger
+func NewSyslogHandler(addr, facility string) (slog.Handler, error) {
+	code, ok := syslogFacilities[strings.ToLower(facility)]
+	if !ok {
+		return nil, fmt.Errorf("unknown syslog facility %q", facility)
+	}
+
+	network, address := "unixgram", "/dev/log"
+	if addr != "" {
+		scheme, hostPort, found := strings.Cut(addr, "://")
+		if !found || (scheme != "udp" && scheme != "tcp") {
+			return nil, fmt.Errorf("invalid syslog address %q: expected udp://host:port or tcp://host:port", addr)
+		}
+		network, address = scheme, hostPort
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+	sink := &syslogSink{network: network, address: address, facility: code, hostname: hostname}
+	if err := sink.dial(); err != nil {
+		return nil, err
+	}
+	return &sinkHandler{sink: sink}, nil
+}
+
+// syslogSink writes messages to a syslog server, reconnecting once when a write fails
+type syslogSink struct {
+	network  string
+	address  string
+	facility int
+	hostname string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// dial connects to the syslog server. Caller must hold s.mu or be the only user.
+func (s *syslogSink) dial() error {
+	conn, err := net.DialTimeout(s.network, s.address, 5*time.Second)
+	if err != nil {
+		return fmt.Errorf("failed to connect to syslog at %s: %w", s.address, err)
+	}
+	s.conn = conn
+	return nil
+}
+
+// send writes a message, framed with octet counting over TCP (RFC 6587)
+func (s *syslogSink) send(level LogLevel, msg string, fields ItemFields) error {
+	line := formatSyslog(time.Now(), s.facility*8+severity(level), s.hostname, os.Getpid(), msg, fields)
+	if s.network == "tcp" {
+		line = strconv.Itoa(len(line)) + " " + line
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn != nil {
+		if _, err := s.conn.Write([]byte(line)); err == nil {
+			return nil
+		}
+		s.conn.Close()
+		s.conn = nil
+	}
+	if err := s.dial(); err != nil {
+		return err
+	}
+	_, err := s.conn.Write([]byte(line))
+	return err
+}
+
+// formatSyslog formats an RFC 5424 message. The item fields become an item@32473 structured
+// data element (32473 is the enterprise number reserved for examples and private use).
+func formatSyslog(now time.Time, priority int, hostname string, pid int, msg string, fields ItemFields) string {
+	return fmt.Sprintf("<%d>1 %s %s %s %d - %s %s",
+		priority, now.UTC().Format("2006-01-02T15:04:05.000000Z07:00"), hostname, syslogAppName, pid, structuredData(fields), msg)
+}
+
+// structuredData returns the SD-ELEMENT for the item fields, or "-" when there are none
+func structuredData(fields ItemFields) string {
+	var params []string
+	add := func(name, value string) {
+		if value != "" {
+			escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(value)
+			params = append(params, fmt.Sprintf(`%s="%s"`, name, escaped))
+		}
+	}
+	add("runId", fields.RunID)
+	add("service", fields.Service)
+	add("seriesId", idString(fields.SeriesID))
+	add("movieId", idString(fields.MovieID))
+	add("bookId", idString(fields.BookID))
+	add("episodeId", idString(fields.EpisodeID))
+	add("fileId", idString(fields.FileID))
+	add("path", fields.Path)
+	if len(params) == 0 {
+		return "-"
+	}
+	return "[item@32473 " + strings.Join(params, " ") + "]"
+}
+
+// NewJournalLogger creates a logger writing to the systemd journal's native protocol socket
+// (DefaultJournalSocket when socketPath is empty). Levels map to journal priorities and item
+// fields become journal fields such as SERIES_ID.
+func NewJournalLogger(levelStr, socketPath string) (Logger, error) {
+	handler, err := NewJournalHandler(socketPath)
+	if err != nil {
+		return nil, err
+	}
+	return NewSlogLogger(handler, LoggerOptions{Level: levelStr}), nil
+}
+
+// NewJournalHandler creates the slog.Handler behind NewJournalLogger
+func NewJournalHandler(socketPath string) (slog.Handler, error) {
+	if socketPath == "" {
+		socketPath = DefaultJournalSocket
+	}
+	conn, err := net.Dial("unixgram", socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the systemd journal: %w", err)
+	}
+	return &sinkHandler{sink: &journalSink{conn: conn}}, nil
+}
+
+// journalSink sends journal entries as datagrams
+type journalSink struct {
+	conn net.Conn
+}
+
+// send writes an entry to the journal
+func (j *journalSink) send(level LogLevel, msg string, fields ItemFields) error {
+	_, err := j.conn.Write(journalEntry(level, msg, fields))
+	return err
+}
+
+// journalEntry encodes an entry in the native protocol: KEY=value lines, with values that
+// contain a newline sent as the key, a newline, their little-endian 64-bit length and the value
+func journalEntry(level LogLevel, msg string, fields ItemFields) []byte {
+	var b bytes.Buffer
+	add := func(key, value string) {
+		if value == "" {
+			return
+		}
+		if !strings.Contains(value, "\n") {
+			fmt.Fprintf(&b, "%s=%s\n", key, value)
+			return
+		}
+		b.WriteString(key + "\n")
+		_ = binary.Write(&b, binary.LittleEndian, uint64(len(value)))
+		b.WriteString(value + "\n")
+	}
+	add("MESSAGE", msg)
+	add("PRIORITY", strconv.Itoa(severity(level)))
+	add("SYSLOG_IDENTIFIER", syslogAppName)
+	add("RUN_ID", fields.RunID)
+	add("SERVICE", fields.Service)
+	add("SERIES_ID", idString(fields.SeriesID))
+	add("MOVIE_ID", idString(fields.MovieID))
+	add("BOOK_ID", idString(fields.BookID))
+	add("EPISODE_ID", idString(fields.EpisodeID))
+	add("FILE_ID", idString(fields.FileID))
+	add("FILE_PATH", fields.Path)
+	return b.Bytes()
+}
+
+// idString formats an item ID, with an unset (zero) ID giving an empty string
+func idString(id int) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.Itoa(id)
+}
@@ -0,0 +1,96 @@
+package arr
+
+import (
+	"net"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyslogLogger_UDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("UDP not available: %v", err)
+	}
+	defer conn.Close()
+
+	logger, err := NewSyslogLogger("INFO", "udp://"+conn.LocalAddr().String(), "local3")
+	if err != nil {
+		t.Fatalf("NewSyslogLogger() error = %v", err)
+	}
+	logger.Debug("hidden")
+	WithItem(logger, ItemFields{Service: "sonarr", SeriesID: 1, Path: `/tv/A "B"/S01E01.mkv`}).Warn("    ⚠️  Size mismatch")
+
+	buf := make([]byte, 4096)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("No message received: %v", err)
+	}
+	message := string(buf[:n])
+
+	// local3 (19) * 8 + warning (4)
+	pattern := regexp.MustCompile(`^<156>1 \S+Z \S+ refresharr \d+ - \[item@32473 service="sonarr" seriesId="1" path="/tv/A \\"B\\"/S01E01.mkv"\] ⚠️  Size mismatch$`)
+	if !pattern.MatchString(message) {
+		t.Errorf("Unexpected syslog message: %q", message)
+	}
+}
+
+func TestNewSyslogLogger_InvalidSettings(t *testing.T) {
+	if _, err := NewSyslogLogger("INFO", "udp://127.0.0.1:514", "nope"); err == nil {
+		t.Error("Expected error for an unknown facility")
+	}
+	if _, err := NewSyslogLogger("INFO", "127.0.0.1:514", "daemon"); err == nil {
+		t.Error("Expected error for an address without a scheme")
+	}
+}
+
+func TestStructuredData(t *testing.T) {
+	if got := structuredData(ItemFields{}); got != "-" {
+		t.Errorf("Expected - without fields, got %q", got)
+	}
+	if got := structuredData(ItemFields{MovieID: 5, FileID: 50}); got != `[item@32473 movieId="5" fileId="50"]` {
+		t.Errorf("Unexpected structured data: %q", got)
+	}
+}
+
+func TestJournalLogger(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "journal.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socketPath, Net: "unixgram"})
+	if err != nil {
+		t.Skipf("unixgram sockets not available: %v", err)
+	}
+	defer conn.Close()
+
+	logger, err := NewJournalLogger("DEBUG", socketPath)
+	if err != nil {
+		t.Fatalf("NewJournalLogger() error = %v", err)
+	}
+	WithItem(logger, ItemFields{Service: "radarr", MovieID: 7, FileID: 70}).Error("❌ Failed to delete movie file record 70")
+
+	buf := make([]byte, 4096)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("No entry received: %v", err)
+	}
+	entry := string(buf[:n])
+	for _, want := range []string{"MESSAGE=❌ Failed to delete movie file record 70\n", "PRIORITY=3\n", "SYSLOG_IDENTIFIER=refresharr\n", "SERVICE=radarr\n", "MOVIE_ID=7\n", "FILE_ID=70\n"} {
+		if !strings.Contains(entry, want) {
+			t.Errorf("Expected %q in the entry, got %q", want, entry)
+		}
+	}
+	if strings.Contains(entry, "SERIES_ID") {
+		t.Errorf("Expected unset fields to be left out, got %q", entry)
+	}
+}
+
+func TestJournalEntry_MultilineMessage(t *testing.T) {
+	entry := journalEntry(LogLevelInfo, "line one\nline two", ItemFields{})
+	want := "MESSAGE\n\x11\x00\x00\x00\x00\x00\x00\x00line one\nline two\n"
+	if !strings.HasPrefix(string(entry), want) {
+		t.Errorf("Expected a length-prefixed message, got %q", entry)
+	}
+}
@@ -0,0 +1,286 @@
+package arr
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hnipps/refresharr/pkg/models"
+	"golift.io/starr"
+)
+
+// findTag returns the ID of the tag with the label. Sonarr and Radarr store labels in lower
+// case, so labels are compared case-insensitively.
+func findTag(tags []models.Tag, label string) (int, bool) {
+	for _, tag := range tags {
+		if strings.EqualFold(tag.Label, label) {
+			return tag.ID, true
+		}
+	}
+	return 0, false
+}
+
+// starrTags converts the tags starr returns
+func starrTags(tags []*starr.Tag) []models.Tag {
+	converted := make([]models.Tag, 0, len(tags))
+	for _, tag := range tags {
+		if tag != nil {
+			converted = append(converted, models.Tag{ID: tag.ID, Label: tag.Label})
+		}
+	}
+	return converted
+}
+
+// addTagID adds the tag to the "tags" field of a series or movie as the API returned it. It
+// returns false when the item already has the tag.
+func addTagID(fields map[string]interface{}, tagID int) bool {
+	tags, _ := fields["tags"].([]interface{})
+	for _, tag := range tags {
+		if id, ok := tag.(float64); ok && int(id) == tagID {
+			return false
+		}
+	}
+	fields["tags"] = append(tags, tagID)
+	return true
+}
+
+// removeTagID removes the tag from the "tags" field of a series or movie as the API returned
+// it. It returns false when the item doesn't have the tag.
+func removeTagID(fields map[string]interface{}, tagID int) bool {
+	tags, _ := fields["tags"].([]interface{})
+	kept := make([]interface{}, 0, len(tags))
+	for _, tag := range tags {
+		if id, ok := tag.(float64); ok && int(id) == tagID {
+			continue
+		}
+		kept = append(kept, tag)
+	}
+	if len(kept) == len(tags) {
+		return false
+	}
+	fields["tags"] = kept
+	return true
+}
+
+// tagState is what a run learned about its series or movies for tagging, by ID
+type tagState struct {
+	deleted map[int]bool // File records were deleted
+	planned map[int]bool // File records would have been deleted, but the run only reported it
+	broken  map[int]bool // A file is missing or damaged
+	valid   map[int]bool // A file passed verification
+}
+
+// addID adds id to the set, creating the set when it is nil
+func addID(set *map[int]bool, id int) {
+	if *set == nil {
+		*set = make(map[int]bool)
+	}
+	(*set)[id] = true
+}
+
+// markDeleted records a series or movie whose file records the run deleted, so updateTags tags
+// it however the run ends
+func (s *CleanupServiceImpl) markDeleted(id int) {
+	if s.tag == "" {
+		return
+	}
+	s.tagsMu.Lock()
+	defer s.tagsMu.Unlock()
+	addID(&s.tags.deleted, id)
+}
+
+// markPlanned records a series or movie whose file records the run would have deleted, but only
+// reported, e.g. in a dry run or once over the API budget
+func (s *CleanupServiceImpl) markPlanned(id int) {
+	if s.tag == "" {
+		return
+	}
+	s.tagsMu.Lock()
+	defer s.tagsMu.Unlock()
+	addID(&s.tags.planned, id)
+}
+
+// noteFile records whether a file of a series or movie passed verification, so updateTags can
+// tell which tagged items have their files back
+func (s *CleanupServiceImpl) noteFile(id int, ok bool) {
+	if s.tag == "" {
+		return
+	}
+	s.tagsMu.Lock()
+	defer s.tagsMu.Unlock()
+	if ok {
+		addID(&s.tags.valid, id)
+	} else {
+		addID(&s.tags.broken, id)
+	}
+}
+
+// updateTags applies the configured tag to the series or movies whose file records were
+// deleted, and removes it from tagged ones whose files are back. It returns messages for the
+// result when tagging failed.
+func (s *CleanupServiceImpl) updateTags(ctx context.Context, mediaType string) []string {
+	if s.tag == "" {
+		return nil
+	}
+	s.tagsMu.Lock()
+	deleted := sortedIDs(s.tags.deleted)
+	var planned []int
+	for _, id := range sortedIDs(s.tags.planned) {
+		if !s.tags.deleted[id] {
+			planned = append(planned, id)
+		}
+	}
+	s.tagsMu.Unlock()
+
+	tagger, ok := s.client.(Tagger)
+	if !ok {
+		if len(deleted) == 0 && len(planned) == 0 {
+			return nil
+		}
+		s.logger.Warn("⚠️  %s can't tag %s, not applying tag %q", s.client.GetName(), mediaType, s.tag)
+		return []string{fmt.Sprintf("Tagging skipped: %s can't tag %s", s.client.GetName(), mediaType)}
+	}
+
+	if len(planned) > 0 {
+		s.logger.Info("🏃 DRY RUN: Would tag %d %s with %q", len(planned), mediaType, s.tag)
+	}
+	var messages []string
+	if msg := s.tagAffected(ctx, tagger, mediaType, deleted); msg != "" {
+		messages = append(messages, msg)
+	}
+	if msg := s.untagRecovered(ctx, tagger, mediaType); msg != "" {
+		messages = append(messages, msg)
+	}
+	return messages
+}
+
+// tagAffected applies the configured tag to the series or movies whose file records were
+// deleted. They are tagged even when the run went on to only report changes, e.g. once over the
+// API budget, as the deletions were real. It returns a message for the result when tagging failed.
+func (s *CleanupServiceImpl) tagAffected(ctx context.Context, tagger Tagger, mediaType string, ids []int) string {
+	if len(ids) == 0 {
+		return ""
+	}
+
+	tagID, err := tagger.EnsureTag(ctx, s.tag)
+	if err != nil {
+		s.logger.Warn("Failed to create tag %q: %s", s.tag, err.Error())
+		return fmt.Sprintf("Failed to create tag %q: %s", s.tag, err.Error())
+	}
+
+	failed := 0
+	for _, id := range ids {
+		if err := tagger.AddMediaTag(ctx, id, tagID); err != nil {
+			s.logger.Warn("Failed to tag %s %d with %q: %s", mediaType, id, s.tag, err.Error())
+			failed++
+		}
+	}
+	s.logger.Info("🏷️  Tagged %d %s with %q", len(ids)-failed, mediaType, s.tag)
+	if failed > 0 {
+		return fmt.Sprintf("Failed to tag %d %s with %q", failed, mediaType, s.tag)
+	}
+	return ""
+}
+
+// untagRecovered removes the configured tag from the series or movies that have it and whose
+// files are back: the run verified at least one of their files, found none missing or damaged,
+// and every file an earlier run found missing has recovered. Items the run didn't check, or
+// whose title it doesn't know, keep the tag, and so does every item when the history left out
+// some missing files. It returns a message for the result when untagging failed.
+func (s *CleanupServiceImpl) untagRecovered(ctx context.Context, tagger Tagger, mediaType string) string {
+	if s.missingBeforeGaps {
+		s.logger.Info("🏷️  Keeping tag %q: the history doesn't list every file earlier runs found missing", s.tag)
+		return ""
+	}
+	tagID, exists, err := tagger.FindTag(ctx, s.tag)
+	if err != nil {
+		s.logger.Warn("Failed to look up tag %q: %s", s.tag, err.Error())
+		return fmt.Sprintf("Failed to look up tag %q: %s", s.tag, err.Error())
+	}
+	if !exists {
+		return ""
+	}
+	tagged, err := tagger.TaggedMedia(ctx, tagID)
+	if err != nil {
+		s.logger.Warn("Failed to find %s tagged %q: %s", mediaType, s.tag, err.Error())
+		return fmt.Sprintf("Failed to find %s tagged %q: %s", mediaType, s.tag, err.Error())
+	}
+
+	var recovered []int
+	for _, id := range tagged {
+		if s.filesBack(mediaType, id) {
+			recovered = append(recovered, id)
+		}
+	}
+	if len(recovered) == 0 {
+		return ""
+	}
+	sort.Ints(recovered)
+
+	if s.reportOnly() {
+		s.logger.Info("🏃 DRY RUN: Would remove tag %q from %d %s whose files are back", s.tag, len(recovered), mediaType)
+		return ""
+	}
+
+	failed := 0
+	for _, id := range recovered {
+		if err := tagger.RemoveMediaTag(ctx, id, tagID); err != nil {
+			s.logger.Warn("Failed to remove tag %q from %s %d: %s", s.tag, mediaType, id, err.Error())
+			failed++
+		}
+	}
+	s.logger.Info("🏷️  Removed tag %q from %d %s whose files are back", s.tag, len(recovered)-failed, mediaType)
+	if failed > 0 {
+		return fmt.Sprintf("Failed to remove tag %q from %d %s", s.tag, failed, mediaType)
+	}
+	return ""
+}
+
+// filesBack reports whether the run found the files of a tagged series or movie back, see
+// untagRecovered
+func (s *CleanupServiceImpl) filesBack(mediaType string, id int) bool {
+	s.tagsMu.Lock()
+	checked := s.tags.valid[id] && !s.tags.broken[id] && !s.tags.deleted[id] && !s.tags.planned[id]
+	s.tagsMu.Unlock()
+	if !checked {
+		return false
+	}
+
+	// Files earlier runs found missing are matched by title, so an item without a known title
+	// keeps its tag
+	s.mediaInfoMu.RLock()
+	entryType, name, known := "series", "", false
+	if mediaType == "movies" {
+		entryType = "movie"
+		name, known = s.movieInfo[id]
+	} else {
+		name, known = s.seriesInfo[id]
+	}
+	s.mediaInfoMu.RUnlock()
+	if !known {
+		name, known = s.mediaCache.Title(id)
+	}
+	if !known {
+		return false
+	}
+
+	s.recoveredMu.Lock()
+	defer s.recoveredMu.Unlock()
+	for _, entry := range s.missingBefore {
+		if entry.MediaType == entryType && entry.MediaName == name {
+			return false
+		}
+	}
+	return true
+}
+
+// sortedIDs returns the IDs in the set in ascending order
+func sortedIDs(set map[int]bool) []int {
+	ids := make([]int, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
@@ -0,0 +1,161 @@
+package arr
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Target ID kinds accepted in an IDs file
+const (
+	TargetSeries = "series" // Sonarr series ID
+	TargetMovie  = "movie"  // Radarr movie ID
+	TargetTVDB   = "tvdb"   // TVDB ID, resolved to a Sonarr series
+	TargetTMDB   = "tmdb"   // TMDB ID, resolved to a Radarr movie
+)
+
+// TargetList is the set of items listed in an IDs file
+type TargetList struct {
+	Source    string // Path of the file the targets were loaded from
+	SeriesIDs []int
+	MovieIDs  []int
+	TVDBIDs   []int
+	TMDBIDs   []int
+
+	bare int // Number of bare IDs, listed in both SeriesIDs and MovieIDs
+}
+
+// LoadTargetList reads an IDs file. Each line holds one ID, optionally prefixed with its kind
+// ("series:", "movie:", "tvdb:" or "tmdb:"). Bare numbers are the native ID of the service
+// being processed (series IDs for Sonarr, movie IDs for Radarr). Blank lines, "#" comments
+// and commas or whitespace between IDs are allowed.
+func LoadTargetList(path string) (*TargetList, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open IDs file: %w", err)
+	}
+	defer f.Close()
+
+	targets := &TargetList{Source: path}
+	var bare []int
+	scanner := bufio.NewScanner(f)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+
+		for _, field := range strings.FieldsFunc(line, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+			kind, value, hasKind := strings.Cut(field, ":")
+			if !hasKind {
+				kind, value = "", field
+			}
+
+			id, err := strconv.Atoi(value)
+			if err != nil || id <= 0 {
+				return nil, fmt.Errorf("%s:%d: invalid ID %q", path, lineNum, field)
+			}
+
+			switch strings.ToLower(kind) {
+			case "":
+				bare = append(bare, id)
+			case TargetSeries:
+				targets.SeriesIDs = append(targets.SeriesIDs, id)
+			case TargetMovie:
+				targets.MovieIDs = append(targets.MovieIDs, id)
+			case TargetTVDB:
+				targets.TVDBIDs = append(targets.TVDBIDs, id)
+			case TargetTMDB:
+				targets.TMDBIDs = append(targets.TMDBIDs, id)
+			default:
+				return nil, fmt.Errorf("%s:%d: unknown ID kind %q (expected series, movie, tvdb or tmdb)", path, lineNum, kind)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read IDs file: %w", err)
+	}
+
+	// Bare IDs apply to whichever service is processed
+	targets.SeriesIDs = append(targets.SeriesIDs, bare...)
+	targets.MovieIDs = append(targets.MovieIDs, bare...)
+	targets.bare = len(bare)
+
+	if targets.Size() == 0 {
+		return nil, fmt.Errorf("%s does not list any IDs", path)
+	}
+	return targets, nil
+}
+
+// Size returns the number of IDs in the list, counting bare IDs once
+func (t *TargetList) Size() int {
+	return len(t.SeriesIDs) + len(t.MovieIDs) + len(t.TVDBIDs) + len(t.TMDBIDs) - t.bare
+}
+
+// Resolve returns the native IDs to process for the client's service: series IDs for Sonarr
+// and movie IDs for Radarr. TVDB and TMDB IDs not in the collection are logged and skipped.
+func (t *TargetList) Resolve(ctx context.Context, client Client, logger Logger) ([]int, error) {
+	switch client.GetName() {
+	case "sonarr":
+		ids := append([]int(nil), t.SeriesIDs...)
+		if len(t.TVDBIDs) > 0 {
+			series, err := client.GetAllSeries(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch series to resolve TVDB IDs: %w", err)
+			}
+			byTVDB := make(map[int]int, len(series))
+			for _, s := range series {
+				byTVDB[s.TVDBID] = s.ID
+			}
+			for _, tvdbID := range t.TVDBIDs {
+				if id, ok := byTVDB[tvdbID]; ok {
+					ids = append(ids, id)
+				} else {
+					logger.Warn("⚠️  TVDB ID %d from %s is not in Sonarr, skipping", tvdbID, t.Source)
+				}
+			}
+		}
+		return uniqueIDs(ids), nil
+
+	case "radarr":
+		ids := append([]int(nil), t.MovieIDs...)
+		if len(t.TMDBIDs) > 0 {
+			movies, err := client.GetAllMovies(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch movies to resolve TMDB IDs: %w", err)
+			}
+			byTMDB := make(map[int]int, len(movies))
+			for _, m := range movies {
+				byTMDB[m.TMDBID] = m.ID
+			}
+			for _, tmdbID := range t.TMDBIDs {
+				if id, ok := byTMDB[tmdbID]; ok {
+					ids = append(ids, id)
+				} else {
+					logger.Warn("⚠️  TMDB ID %d from %s is not in Radarr, skipping", tmdbID, t.Source)
+				}
+			}
+		}
+		return uniqueIDs(ids), nil
+	}
+
+	return nil, fmt.Errorf("unsupported client type: %s", client.GetName())
+}
+
+// uniqueIDs removes duplicate IDs, keeping the first occurrence
+func uniqueIDs(ids []int) []int {
+	seen := make(map[int]bool, len(ids))
+	result := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
@@ -0,0 +1,105 @@
+package arr
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+// writeIDsFile writes an IDs file to a temporary directory
+func writeIDsFile(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "targets.txt")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write IDs file: %v", err)
+	}
+	return path
+}
+
+func TestLoadTargetList(t *testing.T) {
+	path := writeIDsFile(t, `# targets after the disk failure
+series:12
+tvdb:73739, tvdb:81189
+tmdb:603   movie:7
+
+42 # bare ID
+`)
+
+	targets, err := LoadTargetList(path)
+	if err != nil {
+		t.Fatalf("LoadTargetList returned error: %v", err)
+	}
+
+	if len(targets.SeriesIDs) != 2 || targets.SeriesIDs[0] != 12 || targets.SeriesIDs[1] != 42 {
+		t.Errorf("Unexpected series IDs: %v", targets.SeriesIDs)
+	}
+	if len(targets.MovieIDs) != 2 || targets.MovieIDs[0] != 7 || targets.MovieIDs[1] != 42 {
+		t.Errorf("Unexpected movie IDs: %v", targets.MovieIDs)
+	}
+	if len(targets.TVDBIDs) != 2 || len(targets.TMDBIDs) != 1 {
+		t.Errorf("Unexpected external IDs: tvdb=%v tmdb=%v", targets.TVDBIDs, targets.TMDBIDs)
+	}
+	// The bare ID is listed for both services but counted once
+	if targets.Size() != 6 {
+		t.Errorf("Expected 6 IDs, got %d", targets.Size())
+	}
+}
+
+func TestLoadTargetList_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"empty", "# nothing here\n"},
+		{"not a number", "series:abc\n"},
+		{"unknown kind", "imdb:123\n"},
+		{"negative", "-5\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := LoadTargetList(writeIDsFile(t, tt.content)); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+
+	if _, err := LoadTargetList(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
+
+func TestTargetList_ResolveSonarr(t *testing.T) {
+	client := &mockClient{
+		name: "sonarr",
+		allSeries: []models.Series{
+			{MediaItem: models.MediaItem{ID: 1}, TVDBID: 73739},
+			{MediaItem: models.MediaItem{ID: 2}, TVDBID: 81189},
+		},
+	}
+	targets := &TargetList{Source: "targets.txt", SeriesIDs: []int{2, 5}, TVDBIDs: []int{73739, 81189, 99999}, TMDBIDs: []int{603}}
+	logger := &mockLogger{}
+
+	ids, err := targets.Resolve(context.Background(), client, logger)
+	if err != nil {
+		t.Fatalf("Resolve returned error: %v", err)
+	}
+
+	want := []int{2, 5, 1}
+	if len(ids) != len(want) {
+		t.Fatalf("Expected IDs %v, got %v", want, ids)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Errorf("Expected IDs %v, got %v", want, ids)
+			break
+		}
+	}
+	if len(logger.warnMessages) != 1 {
+		t.Errorf("Expected 1 warning for the unknown TVDB ID, got %v", logger.warnMessages)
+	}
+}
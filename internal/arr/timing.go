@@ -0,0 +1,131 @@
+package arr
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+// APICallCounter is implemented by clients that count the requests they send
+type APICallCounter interface {
+	// APICalls returns the number of HTTP requests sent since the client was created
+	APICalls() int64
+}
+
+// callCounterOf returns the client's request counter, or nil if it doesn't count requests
+func callCounterOf(client Client) APICallCounter {
+	counter, _ := client.(APICallCounter)
+	return counter
+}
+
+// callCounter is an http.RoundTripper that counts the requests sent through it
+type callCounter struct {
+	next  http.RoundTripper
+	calls atomic.Int64
+}
+
+// newCallCounter wraps next, falling back to the default transport when next is nil
+func newCallCounter(next http.RoundTripper) *callCounter {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &callCounter{next: next}
+}
+
+// TransportWrapper wraps the transport a client sends its requests through, e.g. to record
+// or replay them. next is the client's default transport.
+type TransportWrapper func(next http.RoundTripper) http.RoundTripper
+
+// wrapTransport applies wrap to next, if there is a wrapper
+func wrapTransport(wrap TransportWrapper, next http.RoundTripper) http.RoundTripper {
+	if wrap == nil {
+		return next
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return wrap(next)
+}
+
+// RoundTrip counts the request and passes it on
+func (c *callCounter) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.calls.Add(1)
+	return c.next.RoundTrip(req)
+}
+
+// phase identifies a part of a cleanup run whose time is tracked separately
+type phase int
+
+const (
+	phaseFetch        phase = iota // Fetching series, episodes, movies and file records
+	phaseSymlinkScan               // Scanning root folders for broken symlinks and handling them
+	phaseVerification              // Checking files on disk
+	phaseDeletion                  // Deleting file records
+	phaseRefresh                   // Triggering the missing search
+)
+
+// runClock tracks a run's wall-clock duration, the time spent in each phase and the API calls made.
+// Phase times are summed across concurrent workers, so together they can exceed the duration.
+type runClock struct {
+	once      sync.Once
+	startedAt time.Time
+	counter   APICallCounter // nil when the client doesn't count requests
+	baseCalls int64
+
+	mu     sync.Mutex
+	phases models.PhaseTimings
+}
+
+// start marks the beginning of the run. Only the first call has an effect, so nested
+// entry points (e.g. CleanupMissingFiles calling CleanupMissingFilesForSeries) share one clock.
+func (c *runClock) start() {
+	c.once.Do(func() {
+		c.startedAt = time.Now()
+		if c.counter != nil {
+			c.baseCalls = c.counter.APICalls()
+		}
+	})
+}
+
+// track adds the time since start to a phase
+func (c *runClock) track(p phase, start time.Time) {
+	elapsed := time.Since(start)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch p {
+	case phaseFetch:
+		c.phases.Fetch += elapsed
+	case phaseSymlinkScan:
+		c.phases.SymlinkScan += elapsed
+	case phaseVerification:
+		c.phases.Verification += elapsed
+	case phaseDeletion:
+		c.phases.Deletion += elapsed
+	case phaseRefresh:
+		c.phases.Refresh += elapsed
+	}
+}
+
+// calls returns the API calls made since the run started (0 when the client doesn't count them)
+func (c *runClock) calls() int {
+	if c.counter == nil {
+		return 0
+	}
+	return int(c.counter.APICalls() - c.baseCalls)
+}
+
+// stamp records the duration so far, the phase timings and the API calls made on stats
+func (c *runClock) stamp(stats *models.CleanupStats) {
+	if !c.startedAt.IsZero() {
+		stats.Duration = time.Since(c.startedAt)
+	}
+	stats.APICalls = c.calls()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats.Phases = c.phases
+}
@@ -0,0 +1,142 @@
+package arr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hnipps/refresharr/internal/config"
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+// countingClient is a mockClient that counts each episode file lookup as an API call
+type countingClient struct {
+	*mockClient
+	calls int64
+}
+
+func (c *countingClient) GetEpisodeFile(ctx context.Context, fileID int) (*models.EpisodeFile, error) {
+	c.calls++
+	return c.mockClient.GetEpisodeFile(ctx, fileID)
+}
+
+func (c *countingClient) APICalls() int64 {
+	return c.calls
+}
+
+func TestClients_CountAPICalls(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"version":"3.0.0"}`))
+	}))
+	defer server.Close()
+
+	radarr := NewRadarrClient(&config.RadarrConfig{URL: server.URL, APIKey: "key"}, 5*time.Second, &mockLogger{})
+	sonarr := NewSonarrClient(&config.SonarrConfig{URL: server.URL, APIKey: "key"}, 5*time.Second, &mockLogger{})
+
+	for _, client := range []Client{radarr, sonarr} {
+		for i := 0; i < 2; i++ {
+			if err := client.TestConnection(context.Background()); err != nil {
+				t.Fatalf("%s TestConnection failed: %v", client.GetName(), err)
+			}
+		}
+
+		counter, ok := client.(APICallCounter)
+		if !ok {
+			t.Fatalf("Expected %s client to count API calls", client.GetName())
+		}
+		if counter.APICalls() != 2 {
+			t.Errorf("Expected 2 %s API calls, got %d", client.GetName(), counter.APICalls())
+		}
+	}
+}
+
+func TestCleanupService_RecordsTiming(t *testing.T) {
+	client := &countingClient{mockClient: &mockClient{
+		name: "sonarr",
+		episodes: map[int][]models.Episode{
+			1: {
+				{ID: 1, SeriesID: 1, SeasonNumber: 1, EpisodeNumber: 1, HasFile: true, EpisodeFileID: intPtr(100)},
+				{ID: 2, SeriesID: 1, SeasonNumber: 1, EpisodeNumber: 2, HasFile: true, EpisodeFileID: intPtr(200)},
+			},
+		},
+		episodeFiles: map[int]*models.EpisodeFile{
+			100: {ID: 100, Path: "/tv/show/s01e01.mkv"},
+			200: {ID: 200, Path: "/tv/show/s01e02.mkv"},
+		},
+	}}
+	// Calls made before the run must not be counted
+	client.calls = 7
+	fileChecker := &mockFileChecker{fileExists: map[string]bool{"/tv/show/s01e01.mkv": true}}
+
+	// Verify runs wrap the client; the counter must still be found
+	service := NewCleanupServiceWithOptions(client, fileChecker, &mockLogger{}, &mockProgressReporter{}, CleanupOptions{
+		ConcurrentLimit: 1,
+		VerifyOnly:      true,
+	})
+
+	result, err := service.CleanupMissingFilesForSeries(context.Background(), []int{1})
+	if err != nil {
+		t.Fatalf("CleanupMissingFilesForSeries() failed: %v", err)
+	}
+
+	if result.Stats.APICalls != 2 {
+		t.Errorf("Expected 2 API calls, got %d", result.Stats.APICalls)
+	}
+	if result.Stats.Duration <= 0 {
+		t.Errorf("Expected a positive duration, got %v", result.Stats.Duration)
+	}
+	if result.Stats.Phases.Fetch <= 0 || result.Stats.Phases.Verification <= 0 {
+		t.Errorf("Expected fetch and verification time, got %+v", result.Stats.Phases)
+	}
+	if result.Stats.Phases.Deletion != 0 || result.Stats.Phases.Refresh != 0 {
+		t.Errorf("Expected no deletion or refresh time for a verify run, got %+v", result.Stats.Phases)
+	}
+
+	if result.Report == nil || result.Report.Timing == nil || result.Report.Timing.APICalls != 2 {
+		t.Errorf("Expected report timing with 2 API calls, got %+v", result.Report)
+	}
+}
+
+func TestCleanupService_APIBudget(t *testing.T) {
+	client := &countingClient{mockClient: &mockClient{
+		name: "sonarr",
+		episodes: map[int][]models.Episode{
+			1: {
+				{ID: 1, SeriesID: 1, SeasonNumber: 1, EpisodeNumber: 1, HasFile: true, EpisodeFileID: intPtr(100)},
+				{ID: 2, SeriesID: 1, SeasonNumber: 1, EpisodeNumber: 2, HasFile: true, EpisodeFileID: intPtr(200)},
+			},
+		},
+		episodeFiles: map[int]*models.EpisodeFile{
+			100: {ID: 100, Path: "/tv/show/s01e01.mkv"},
+			200: {ID: 200, Path: "/tv/show/s01e02.mkv"},
+		},
+	}}
+	// Both files are missing; the budget runs out on the second file lookup
+	fileChecker := &mockFileChecker{fileExists: map[string]bool{}}
+
+	service := NewCleanupServiceWithOptions(client, fileChecker, &mockLogger{}, &mockProgressReporter{}, CleanupOptions{
+		ConcurrentLimit: 1,
+		APIBudget:       2,
+	})
+
+	result, err := service.CleanupMissingFilesForSeries(context.Background(), []int{1})
+	if err != nil {
+		t.Fatalf("CleanupMissingFilesForSeries() failed: %v", err)
+	}
+
+	if len(client.deletedFileIDs) != 1 {
+		t.Errorf("Expected 1 record deleted before the budget ran out, got %v", client.deletedFileIDs)
+	}
+	if len(result.Actions) != 1 {
+		t.Errorf("Expected the remaining deletion to be reported as a planned action, got %v", result.Actions)
+	}
+	if client.refreshTriggered != 0 {
+		t.Error("Expected the missing search to be skipped once over budget")
+	}
+	if !result.Report.OverBudget || result.Report.SearchSkipped == "" {
+		t.Errorf("Expected the report to show the exceeded budget, got %+v", result.Report)
+	}
+}
@@ -0,0 +1,107 @@
+package arr
+
+import (
+	"strings"
+	"unicode"
+)
+
+// DefaultTitleMatchConfidence is the confidence a title lookup match needs before the item is
+// added to the collection, used when CleanupOptions.TitleMatchConfidence is 0
+const DefaultTitleMatchConfidence = 0.9
+
+// minTitleMatchConfidence is the confidence below which a lookup result isn't considered a match at all
+const minTitleMatchConfidence = 0.5
+
+// titleCandidate is a lookup result a path's title and year are compared with
+type titleCandidate struct {
+	ID    int // TMDB ID of a movie, TVDB ID of a series
+	Title string
+	Year  int
+}
+
+// titleMatch is the lookup result that best matches a path without provider IDs
+type titleMatch struct {
+	titleCandidate
+	Confidence float64 // 0-1, see titleMatchConfidence
+}
+
+// bestTitleMatch returns the candidate that best matches the title and year, and false when
+// none reaches minTitleMatchConfidence
+func bestTitleMatch(title string, year int, candidates []titleCandidate) (titleMatch, bool) {
+	var best titleMatch
+	for _, candidate := range candidates {
+		if candidate.ID <= 0 {
+			continue
+		}
+		if confidence := titleMatchConfidence(title, year, candidate.Title, candidate.Year); confidence > best.Confidence {
+			best = titleMatch{titleCandidate: candidate, Confidence: confidence}
+		}
+	}
+	return best, best.Confidence >= minTitleMatchConfidence
+}
+
+// titleMatchConfidence scores how likely a lookup result is the title and year parsed from a
+// path: the similarity of the normalized titles, lowered when the years differ or the path has none
+func titleMatchConfidence(title string, year int, candidateTitle string, candidateYear int) float64 {
+	a, b := normalizeTitle(title), normalizeTitle(candidateTitle)
+	if a == "" || b == "" {
+		return 0
+	}
+
+	longest := max(len([]rune(a)), len([]rune(b)))
+	similarity := 1 - float64(editDistance(a, b))/float64(longest)
+
+	switch {
+	case year == 0 || candidateYear == 0:
+		similarity *= 0.85
+	case year == candidateYear:
+	case year-candidateYear == 1 || candidateYear-year == 1:
+		// Release years often differ by one between regions and databases
+		similarity *= 0.9
+	default:
+		similarity *= 0.5
+	}
+	return similarity
+}
+
+// normalizeTitle lowercases a title and drops punctuation, so "Marvel's Agents of S.H.I.E.L.D."
+// and "marvels agents of shield" compare equal
+func normalizeTitle(title string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(strings.ReplaceAll(title, "&", "and")) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteRune(r)
+			space = false
+		case unicode.IsSpace(r) || r == '-' || r == ':':
+			space = true
+		}
+	}
+	return b.String()
+}
+
+// editDistance returns the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(min(prev[j]+1, curr[j-1]+1), prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,50 @@
+package arr
+
+import "testing"
+
+func TestTitleMatchConfidence(t *testing.T) {
+	tests := []struct {
+		name           string
+		title          string
+		year           int
+		candidateTitle string
+		candidateYear  int
+		wantMin        float64
+		wantMax        float64
+	}{
+		{"exact", "The Matrix", 1999, "The Matrix", 1999, 1, 1},
+		{"punctuation and case", "marvels agents of shield", 2013, "Marvel's Agents of S.H.I.E.L.D.", 2013, 1, 1},
+		{"ampersand", "Law and Order", 1990, "Law & Order", 1990, 1, 1},
+		{"year off by one", "The Matrix", 1998, "The Matrix", 1999, 0.9, 0.9},
+		{"no year", "The Matrix", 0, "The Matrix", 1999, 0.85, 0.85},
+		{"other year", "The Matrix", 2021, "The Matrix", 1999, 0.5, 0.5},
+		{"sequel", "The Matrix", 1999, "The Matrix Reloaded", 2003, 0, 0.5},
+		{"different title", "Alien", 1979, "Heat", 1995, 0, 0.2},
+		{"empty", "", 1999, "The Matrix", 1999, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := titleMatchConfidence(tt.title, tt.year, tt.candidateTitle, tt.candidateYear)
+			if got < tt.wantMin-1e-9 || got > tt.wantMax+1e-9 {
+				t.Errorf("titleMatchConfidence() = %.3f, want %.2f-%.2f", got, tt.wantMin, tt.wantMax)
+			}
+		})
+	}
+}
+
+func TestBestTitleMatch(t *testing.T) {
+	candidates := []titleCandidate{
+		{ID: 1, Title: "The Office", Year: 2001},
+		{ID: 2, Title: "The Office", Year: 2005},
+		{ID: 0, Title: "The Office", Year: 2005}, // Lookup results without an ID are ignored
+	}
+
+	match, ok := bestTitleMatch("The Office", 2005, candidates)
+	if !ok || match.ID != 2 || match.Confidence != 1 {
+		t.Errorf("Expected the result with the matching year, got %+v (ok=%v)", match, ok)
+	}
+	if _, ok := bestTitleMatch("Parks and Recreation", 2009, candidates); ok {
+		t.Error("Expected no match for an unrelated title")
+	}
+}
@@ -0,0 +1,140 @@
+package arr
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// HTTPTrace controls logging of the requests the clients send, see SetHTTPTrace
+type HTTPTrace struct {
+	Enabled bool // Log each request's method, URL, latency, status code and sizes
+	Bodies  bool // Also log request and response bodies
+}
+
+// httpTrace is the tracing the clients created from now on use
+var httpTrace atomic.Value
+
+// SetHTTPTrace sets how the clients created afterwards trace their requests. API keys are
+// redacted from everything logged.
+func SetHTTPTrace(trace HTTPTrace) {
+	httpTrace.Store(trace)
+}
+
+// currentHTTPTrace returns the tracing new clients use
+func currentHTTPTrace() HTTPTrace {
+	trace, _ := httpTrace.Load().(HTTPTrace)
+	return trace
+}
+
+// maxTraceBody is the most of a body logged when tracing bodies
+const maxTraceBody = 4096
+
+// traceRedacted replaces secrets in traced URLs and bodies
+const traceRedacted = "REDACTED"
+
+// traceTransport is an http.RoundTripper that logs every request it sends
+type traceTransport struct {
+	next   http.RoundTripper
+	logger Logger
+	bodies bool
+	secret string // API key, redacted wherever it appears
+}
+
+// newClientTransport builds the transport a client sends its requests through: the counter
+// sees every request, then the identifying headers are set, the request is traced if tracing
+// is enabled, and finally it goes through wrap to base (the default transport when nil)
+func newClientTransport(wrap TransportWrapper, base http.RoundTripper, apiKey string, logger Logger) *callCounter {
+	next := wrapTransport(wrap, base)
+	if trace := currentHTTPTrace(); trace.Enabled && logger != nil {
+		if next == nil {
+			next = http.DefaultTransport
+		}
+		next = &traceTransport{next: next, logger: logger, bodies: trace.Bodies, secret: apiKey}
+	}
+	return newCallCounter(newHeaderTransport(next, logger))
+}
+
+// RoundTrip sends the request and logs what happened
+func (t *traceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	target := t.redactURL(req.URL)
+	id := req.Header.Get(RequestIDHeader)
+
+	var reqBody []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		data, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		reqBody = data
+		req.Body = io.NopCloser(bytes.NewReader(data))
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	latency := time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		t.logger.Info("🔎 %s %s failed after %s: %s (request %s)", req.Method, target, latency, t.redact(err.Error()), id)
+		return nil, err
+	}
+
+	respBody, readErr := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	if readErr != nil {
+		t.logger.Info("🔎 %s %s → %d after %s, reading the response failed: %s (request %s)", req.Method, target, resp.StatusCode, latency, readErr.Error(), id)
+		return nil, readErr
+	}
+
+	t.logger.Info("🔎 %s %s → %d in %s (sent %d bytes, received %d bytes, request %s)",
+		req.Method, target, resp.StatusCode, latency, len(reqBody), len(respBody), id)
+	if t.bodies {
+		if len(reqBody) > 0 {
+			t.logger.Info("🔎   request body: %s", t.traceBody(reqBody))
+		}
+		if len(respBody) > 0 {
+			t.logger.Info("🔎   response body: %s", t.traceBody(respBody))
+		}
+	}
+	return resp, nil
+}
+
+// redactURL returns the URL with credentials and API key parameters redacted
+func (t *traceTransport) redactURL(u *url.URL) string {
+	copied := *u
+	copied.User = nil
+	query := copied.Query()
+	changed := false
+	for param := range query {
+		if strings.EqualFold(param, "apikey") {
+			query.Set(param, traceRedacted)
+			changed = true
+		}
+	}
+	if changed {
+		copied.RawQuery = query.Encode()
+	}
+	return t.redact(copied.String())
+}
+
+// traceBody returns a body for logging, redacted and truncated
+func (t *traceTransport) traceBody(body []byte) string {
+	s := t.redact(string(bytes.TrimSpace(body)))
+	if len(s) > maxTraceBody {
+		s = strings.ToValidUTF8(s[:maxTraceBody], "") + "..."
+	}
+	return s
+}
+
+// redact replaces the API key wherever it appears in s
+func (t *traceTransport) redact(s string) string {
+	if t.secret == "" {
+		return s
+	}
+	return strings.ReplaceAll(s, t.secret, traceRedacted)
+}
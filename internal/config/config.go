@@ -1,40 +1,226 @@
 package config
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"net/url"
 	"os"
+	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
 
+	"github.com/hnipps/refresharr/internal/messages"
+	"github.com/hnipps/refresharr/pkg/models"
 	"github.com/joho/godotenv"
 )
 
 // Config holds all configuration for the application
 type Config struct {
-	Sonarr SonarrConfig
-	Radarr RadarrConfig
-	Plex   PlexConfig
+	Sonarr   SonarrConfig
+	Radarr   RadarrConfig
+	Readarr  ReadarrConfig
+	Plex     PlexConfig
+	Kodi     KodiConfig
+	Prowlarr ProwlarrConfig
+	Notify   NotifyConfig
+
+	// Error tracking for panics and failed runs
+	ErrorTracking ErrorTrackingConfig
+
+	// Extra instances of the services, e.g. a second Radarr for 4K
+	Instances []InstanceConfig
 
 	// Global settings
-	RequestTimeout  time.Duration
-	RequestDelay    time.Duration
-	ConcurrentLimit int
-	LogLevel        string
-	DryRun          bool
-	NoReport        bool // Flag to disable terminal report output
+	RequestTimeout     time.Duration
+	RequestDelay       time.Duration
+	ConcurrentLimit    int
+	EpisodeConcurrency int           // Episodes of a series checked at once (0 means the default of min(ConcurrentLimit, 3))
+	MovieConcurrency   int           // Movies checked at once (0 means ConcurrentLimit)
+	APIBudget          int           // API calls per service per run before remaining changes are only reported (0 means unlimited)
+	SpillAfter         int           // Missing files kept in memory before the rest are spilled to a temp file (0 means the default)
+	SinkTimeout        time.Duration // How long a report write or notification may take before it's given up (0 means the default)
+	SinkQueueSize      int           // Report writes and notifications waiting for delivery before new ones wait for room (0 means the default)
+	LogLevel           string
+	LogFormat          string // "text", or "json" for one JSON object per line with per-item fields
+	DryRun             bool
+	Verify             bool     // Read-only verify run (set by the verify command); implies DryRun
+	NoReport           bool     // Flag to disable terminal report output
+	Args               []string // Arguments left after the flags, for commands that take them
+	Ignored            []string // Settings whose value can't be used, so the default is; each says what to change
+
+	// Per-service overrides of DryRun by service label, from <SERVICE>_DRY_RUN (e.g. RADARR_DRY_RUN
+	// or RADARR_4K_DRY_RUN)
+	ServiceDryRun map[string]bool
 
 	// CLI-specific settings
-	Service     string // Service to use: "sonarr", "radarr", or "auto"
+	Service     string // Service to use: "sonarr", "radarr", "readarr", or "auto"
 	SeriesIDs   []int  // Specific series IDs to process (empty means all)
+	Seasons     []int  // Season numbers to restrict --series-ids runs to (empty means all)
+	EpisodeIDs  []int  // Specific Sonarr episode IDs to process (empty means all)
+	MovieIDs    []int  // Specific Radarr movie IDs to process (empty means all)
+	TargetPath  string // Single media file to process; the owning episode or movie record is resolved
+	IDsFile     string // File listing series/movie/TVDB/TMDB IDs to process
 	ShowVersion bool   // Show version and exit
 
 	// Broken symlink handling
 	AddMissingMovies bool // Whether to add movies/series to collection when found from broken symlinks
 	QualityProfileID int  // Quality profile ID to use when adding movies (default: 12)
+
+	// Review -> apply workflow
+	OnlyFrom string // Dry-run actions file or report; a real run only touches the items it lists
+
+	// Server mode settings
+	StateDir   string // Directory for persistent state such as the job queue (default: data)
+	ReportDir  string // Directory reports, dry-run actions files and snapshots are saved to (default: reports)
+	ListenAddr string // Address the serve command listens on (default: :8080)
+	APIKey     string // Key every request to the serve command's API must carry in single-user mode
+	VerifyAt   string // Local time of day (HH:MM) the serve command queues a verify run (empty disables)
+	WebUI      bool   // Serve the web dashboard at / in serve mode
+
+	// Report sink URLs, e.g. file:///srv/reports?format=csv or s3://bucket/prefix. Every report is
+	// written to each of them (empty means JSON files in ReportDir).
+	ReportSinks []string
+
+	// Housekeeping of REPORT_DIR after each run: files older than ReportCompressAfterDays are
+	// gzipped (0 disables), and with ReportArchiveMonthly every month that is over is bundled
+	// into one archive
+	ReportCompressAfterDays int
+	ReportArchiveMonthly    bool
+
+	// Runs older than HistoryRetentionDays are dropped from the run history after each run
+	// (0 keeps them all)
+	HistoryRetentionDays int
+
+	// Weekly local times runs may change the *arr instances. Outside them changes are deferred:
+	// only reported and saved as dry-run actions (empty means any time).
+	MaintenanceWindows []models.MaintenanceWindow
+
+	// Users of a shared serve deployment, each with their own instances and reports, selected by
+	// the API key of a request (empty means single-user mode)
+	Tenants []TenantConfig
+
+	// Verify alerting
+	AlertThreshold int // Verify runs alert when more files than this are missing (0 alerts only when the count grows)
+
+	// Simulation mode
+	Simulate        string        // Fixtures directory; when set, runs use canned data instead of live instances
+	SimulateLatency time.Duration // Delay added to every simulated API call
+
+	// Debug bundles
+	Record string // Bundle file to record API traffic and file checks to (API keys redacted)
+	Replay string // Bundle file to replay instead of talking to live instances
+
+	// API client implementation: APIClientBuiltin (default) or APIClientStarr
+	APIClient string
+
+	// User-Agent sent to the *arr apps; empty means refresharr/<version>
+	UserAgent string
+
+	// HTTP tracing
+	TraceHTTP       bool // Log every API request's method, URL, latency, status code and sizes
+	TraceHTTPBodies bool // Also log request and response bodies, with secrets redacted
+
+	// Media cache
+	MediaCacheTTL time.Duration // How long a fetched library is reused by later runs (0 disables the cache)
+	RefreshCache  bool          // Fetch the library again instead of using the cache
+
+	// Specials
+	SkipSpecials bool // Leave season 0 (specials) out of Sonarr cleanup unless --season 0 selects it
+
+	// Path parsing
+	PathPatterns []string // Regexes with named groups tried before the default [tmdb-N]/[tvdb-N] tags
+
+	// Title lookups
+	TitleMatchConfidence float64 // Confidence (0-1] a title lookup match needs before the item is added
+
+	// Radarr collections
+	MonitorCollections bool // Monitor the collection of movies added from broken symlinks, so Radarr adds the rest of it
+
+	// Add validation
+	ValidateAdds bool // In dry runs, check that each planned add would succeed (quality profile and root folder)
+
+	// Tagging
+	CleanupTag string // Tag applied to series and movies whose file records were deleted, and removed once their files are back (empty means no tagging)
+
+	// Import fixing
+	DeleteRejectedImports bool          // fix-imports deletes downloads Sonarr rejects as samples or for their extension, then imports the rest
+	RequeueFailedImports  bool          // fix-imports blocklists downloads it can't import and searches for their episodes again
+	ImportCooldown        time.Duration // How long fix-imports skips a queue item after failing to import it (0 retries every run)
+
+	// Localization
+	Messages *messages.Catalog // Text of report summaries and notifications (nil means English)
+
+	// Kubernetes Job mode
+	JobMode     bool   // Print a one-line JSON summary on stdout at the end and fail when nothing was processed
+	SummaryFile string // Also write the JSON summary to this file (empty means stdout only)
+
+	// What cleanup and verify print: OutputText (default), or OutputJSON for one JSON document on
+	// stdout at the end, with the logs on stderr suppressed
+	Output string
+
+	// Log destination
+	LogTarget      string // "stderr", "syslog" or "journald"
+	SyslogAddr     string // Syslog server as udp://host:port or tcp://host:port (empty means the local /dev/log)
+	SyslogFacility string // Syslog facility name, e.g. daemon or local0
+
+	// Log levels by module, overriding LogLevel for its lines (e.g. {"notify": "DEBUG"})
+	LogModuleLevels map[string]string
+
+	// File records without a path
+	EmptyPathPolicy string // EmptyPathSkip (default) or EmptyPathDelete
+
+	// File records whose path is under none of the service's root folders
+	OutsideRootPolicy string               // OutsideRootSkip (default), OutsideRootDelete or OutsideRootRewrite
+	PathMappings      []models.PathMapping // Rules the rewrite policy applies to series and movie folders
+
+	// sha256sum manifest whose checksums verify runs compare files with (empty means none)
+	ChecksumManifest string
+
+	// Disk IO pacing
+	IOOpsPerSecond int    // Max stat, open and readdir calls per second of file checks and scans (0 means unlimited)
+	IONice         string // IO scheduling class on Linux: IONiceIdle or IONiceBestEffort (empty leaves it alone)
 }
 
+// API client implementations, see Config.APIClient. Sonarr is always served by the starr
+// library; the setting chooses between RefreshArr's own Radarr client and a starr-backed one.
+const (
+	APIClientBuiltin = "builtin"
+	APIClientStarr   = "starr"
+)
+
+// Outputs, see Config.Output
+const (
+	OutputText = "text" // Logs and the terminal report on stderr
+	OutputJSON = "json" // A single JSON document on stdout at the end, for scripts
+)
+
+// Empty path policies, see Config.EmptyPathPolicy. File records without a path are almost
+// always corrupt database rows.
+const (
+	EmptyPathSkip   = "skip"   // Leave them alone and count them as warnings
+	EmptyPathDelete = "delete" // Delete them like records of missing files
+)
+
+// Outside root folder policies, see Config.OutsideRootPolicy. Records outside every root folder
+// are common after a library migration that the service wasn't told about.
+const (
+	OutsideRootSkip    = "skip"    // Report them and leave them alone
+	OutsideRootDelete  = "delete"  // Delete them like records of missing files
+	OutsideRootRewrite = "rewrite" // Move their series or movie to the folder PathMappings gives, when the file is there
+)
+
+// IO scheduling classes, see Config.IONice
+const (
+	IONiceIdle       = "idle"        // Disk time only when no other process wants it
+	IONiceBestEffort = "best-effort" // The lowest best-effort priority
+)
+
 // SonarrConfig holds Sonarr-specific configuration
 type SonarrConfig struct {
 	URL    string
@@ -47,10 +233,260 @@ type RadarrConfig struct {
 	APIKey string
 }
 
+// ReadarrConfig holds Readarr-specific configuration
+type ReadarrConfig struct {
+	URL    string
+	APIKey string
+}
+
+// InstanceConfig is an extra Sonarr, Radarr or Readarr instance next to the default one,
+// configured with <SERVICE>_<NAME>_URL and <SERVICE>_<NAME>_API_KEY (e.g. RADARR_4K_URL)
+type InstanceConfig struct {
+	Service string // "sonarr", "radarr" or "readarr"
+	Name    string // Lowercase instance name, e.g. "4k"
+	URL     string
+	APIKey  string
+}
+
+// Label names the instance in logs, reports and history, e.g. "radarr-4k"
+func (i InstanceConfig) Label() string {
+	return models.ServiceLabel(i.Service, i.Name)
+}
+
+// envPrefix returns the start of the instance's environment variables, e.g. "RADARR_4K"
+func (i InstanceConfig) envPrefix() string {
+	return strings.ToUpper(i.Service + "_" + strings.ReplaceAll(i.Name, "-", "_"))
+}
+
+// logModules are the modules LOG_MODULE_LEVELS can name, those with loggers of their own in arr
+var logModules = []string{"cleanup", "api", "notify"}
+
+// parseLogModuleLevels parses LOG_MODULE_LEVELS, a comma-separated list of module=level pairs
+// such as "notify=debug,cleanup=warn"
+func parseLogModuleLevels(value string) (map[string]string, error) {
+	var levels map[string]string
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		module, level, found := strings.Cut(pair, "=")
+		module = strings.ToLower(strings.TrimSpace(module))
+		level = strings.ToUpper(strings.TrimSpace(level))
+		if !found || module == "" {
+			return nil, fmt.Errorf("invalid LOG_MODULE_LEVELS entry %q: expected module=level", pair)
+		}
+		if !slices.Contains(logModules, module) {
+			return nil, fmt.Errorf("invalid LOG_MODULE_LEVELS module %q: must be one of %s", module, strings.Join(logModules, ", "))
+		}
+		switch level {
+		case "DEBUG", "INFO", "WARN", "WARNING", "ERROR":
+		default:
+			return nil, fmt.Errorf("invalid LOG_MODULE_LEVELS level %q for %s: must be DEBUG, INFO, WARN or ERROR", level, module)
+		}
+		if levels == nil {
+			levels = make(map[string]string)
+		}
+		levels[module] = level
+	}
+	return levels, nil
+}
+
+// tagLabelPattern matches the tag labels Sonarr and Radarr accept
+var tagLabelPattern = regexp.MustCompile(`^[a-z0-9-]+$`)
+
+// instanceEnvPattern matches the environment variables of extra instances
+var instanceEnvPattern = regexp.MustCompile(`^(SONARR|RADARR|READARR)_([A-Z0-9_]+?)_(URL|API_KEY)$`)
+
+// loadServiceDryRun reads the dry-run overrides of the default instances and the extra ones,
+// keyed by service label
+func loadServiceDryRun(instances []InstanceConfig) (map[string]bool, error) {
+	envs := map[string]string{
+		"sonarr":  "SONARR_DRY_RUN",
+		"radarr":  "RADARR_DRY_RUN",
+		"readarr": "READARR_DRY_RUN",
+	}
+	for _, instance := range instances {
+		envs[instance.Label()] = instance.envPrefix() + "_DRY_RUN"
+	}
+
+	var overrides map[string]bool
+	for label, env := range envs {
+		value := os.Getenv(env)
+		if value == "" {
+			continue
+		}
+		dryRun, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: must be true or false", env, value)
+		}
+		if overrides == nil {
+			overrides = make(map[string]bool)
+		}
+		overrides[label] = dryRun
+	}
+	return overrides, nil
+}
+
+// DryRunFor reports whether the service with the given label (e.g. "radarr-4k") runs in dry-run
+// mode: its <SERVICE>_DRY_RUN override if it has one, otherwise DryRun. Verify runs are always
+// dry runs.
+func (c *Config) DryRunFor(label string) bool {
+	if c.Verify {
+		return true
+	}
+	if dryRun, ok := c.ServiceDryRun[label]; ok {
+		return dryRun
+	}
+	return c.DryRun
+}
+
+// loadInstances collects the extra instances from the environment, sorted by service and name
+func loadInstances() []InstanceConfig {
+	byLabel := make(map[string]*InstanceConfig)
+	var instances []*InstanceConfig
+	for _, env := range os.Environ() {
+		key, value, _ := strings.Cut(env, "=")
+		match := instanceEnvPattern.FindStringSubmatch(key)
+		if match == nil || value == "" {
+			continue
+		}
+
+		instance := InstanceConfig{
+			Service: strings.ToLower(match[1]),
+			Name:    strings.ReplaceAll(strings.ToLower(match[2]), "_", "-"),
+		}
+		existing, ok := byLabel[instance.Label()]
+		if !ok {
+			existing = &instance
+			byLabel[instance.Label()] = existing
+			instances = append(instances, existing)
+		}
+		if match[3] == "URL" {
+			existing.URL = normalizeEndpointURL(value)
+		} else {
+			existing.APIKey = value
+		}
+	}
+
+	sort.Slice(instances, func(a, b int) bool {
+		return instances[a].Label() < instances[b].Label()
+	})
+	result := make([]InstanceConfig, len(instances))
+	for i, instance := range instances {
+		result[i] = *instance
+	}
+	return result
+}
+
+// ProwlarrConfig holds Prowlarr configuration, used to check indexer health before searching
+type ProwlarrConfig struct {
+	URL    string
+	APIKey string
+}
+
+// Configured reports whether both the Prowlarr URL and API key are set
+func (p ProwlarrConfig) Configured() bool {
+	return p.URL != "" && p.APIKey != ""
+}
+
+// NotifyConfig holds where alerts and run summaries are sent
+type NotifyConfig struct {
+	WebhookURL        string   // Receives alerts as a JSON POST
+	CompletionURL     string   // Receives a summary of every finished run as a JSON POST
+	CompletionRetries int      // Times a failed completion POST is retried
+	NtfyURL           string   // ntfy topic URL that receives alerts and run summaries as push notifications
+	NtfyToken         string   // Access token for protected ntfy topics
+	PushoverToken     string   // Pushover application token
+	PushoverUser      string   // Pushover user or group key that receives alerts and run summaries
+	PushFailuresOnly  bool     // Only push failed runs and alerts, not every finished run
+	URLs              []string // Notification services in Apprise URL syntax, such as ntfy://topic or discord://id/token
+}
+
+// Configured reports whether alerts are sent anywhere
+func (n NotifyConfig) Configured() bool {
+	return n.WebhookURL != "" || n.NtfyURL != "" || n.PushoverToken != "" || len(n.URLs) > 0
+}
+
+// ErrorTrackingConfig holds where panics and failed runs are reported
+type ErrorTrackingConfig struct {
+	SentryDSN   string // Sentry project DSN
+	WebhookURL  string // Receives error events as a JSON POST
+	Environment string // Sentry environment, e.g. production (optional)
+}
+
+// Configured reports whether a Sentry DSN or error webhook is set
+func (e ErrorTrackingConfig) Configured() bool {
+	return e.SentryDSN != "" || e.WebhookURL != ""
+}
+
 // PlexConfig holds Plex-specific configuration
 type PlexConfig struct {
-	URL   string
-	Token string
+	URL       string
+	Token     string
+	Timeout   time.Duration // Request timeout for Plex calls (0 means use REQUEST_TIMEOUT)
+	Libraries []string      // Library section names or keys to limit Plex operations to (empty means all)
+}
+
+// Configured reports whether both the Plex URL and token are set
+func (p PlexConfig) Configured() bool {
+	return p.URL != "" && p.Token != ""
+}
+
+// Validate checks that the Plex settings are complete and well-formed
+func (p PlexConfig) Validate() error {
+	if p.Token != "" && p.URL == "" {
+		return fmt.Errorf("Plex URL is required when Plex token is provided")
+	}
+	if p.URL != "" && p.Token == "" {
+		return fmt.Errorf("PLEX_TOKEN is required when PLEX_URL is provided")
+	}
+
+	if err := validateEndpointURL("Plex", p.URL); err != nil {
+		return err
+	}
+
+	if p.Timeout < 0 {
+		return fmt.Errorf("Plex timeout must not be negative")
+	}
+
+	for _, library := range p.Libraries {
+		if strings.TrimSpace(library) == "" {
+			return fmt.Errorf("Plex library names must not be empty")
+		}
+	}
+
+	return nil
+}
+
+// KodiConfig holds Kodi JSON-RPC configuration
+type KodiConfig struct {
+	URL      string // Kodi web server URL, e.g. http://127.0.0.1:8080
+	Username string // Web server username (optional)
+	Password string // Web server password (optional)
+	Timeout  time.Duration
+}
+
+// Configured reports whether the Kodi URL is set
+func (k KodiConfig) Configured() bool {
+	return k.URL != ""
+}
+
+// Validate checks that the Kodi settings are well-formed
+func (k KodiConfig) Validate() error {
+	if err := validateEndpointURL("Kodi", k.URL); err != nil {
+		return err
+	}
+
+	if k.Password != "" && k.Username == "" {
+		return fmt.Errorf("KODI_USERNAME is required when KODI_PASSWORD is provided")
+	}
+
+	if k.Timeout < 0 {
+		return fmt.Errorf("Kodi timeout must not be negative")
+	}
+
+	return nil
 }
 
 // LoadConfig loads configuration from environment variables and command line flags with sensible defaults
@@ -58,94 +494,340 @@ func LoadConfig() (*Config, error) {
 	return LoadConfigWithFlags(nil, nil, nil, nil, nil, nil, nil, nil)
 }
 
-// LoadConfigWithFlags loads configuration with optional flag overrides (used for testing)
-func LoadConfigWithFlags(dryRun, noReport, showVersion *bool, logLevel, service, sonarrURL, sonarrAPIKey *string, seriesIDs *string) (*Config, error) {
-	// Create a new FlagSet for isolated flag parsing (prevents test conflicts)
+// LoadEnvConfig loads the configuration from the environment and .env alone, without looking at
+// the command line
+func LoadEnvConfig() (*Config, error) {
+	return loadConfig(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+}
+
+// NewFlagSet returns the command line flags shared by every command. LoadConfigFromFlags reads
+// the configuration from them once they are parsed.
+func NewFlagSet() *flag.FlagSet {
 	fs := flag.NewFlagSet("refresharr", flag.ContinueOnError)
+	fs.Bool("dry-run", false, "Run in dry-run mode (no changes will be made)")
+	fs.Bool("no-report", false, "Disable terminal report output (report will still be saved to file)")
+	fs.Bool("version", false, "Show version information and exit")
+	fs.String("log-level", "", "Set log level (DEBUG, INFO, WARN, ERROR)")
+	fs.String("service", "auto", "Service to use: sonarr, radarr, readarr, one instance such as radarr-4k, or auto (default: auto)")
+	fs.String("sonarr-url", "", "Sonarr URL (overrides SONARR_URL env var)")
+	fs.String("sonarr-api-key", "", "Sonarr API key (overrides SONARR_API_KEY env var)")
+	fs.String("series-ids", "", "Comma-separated list of specific series IDs to process (empty means all)")
+	fs.String("episode-ids", "", "Comma-separated list of specific Sonarr episode IDs to process")
+	fs.String("movie-ids", "", "Comma-separated list of specific Radarr movie IDs to process (empty means all)")
+	fs.String("ids-file", "", "Process only the series/movies listed in this file (one series:, movie:, tvdb: or tmdb: ID per line)")
+	fs.String("path", "", "Process only the episode or movie that owns this file path")
+	fs.String("season", "", "Comma-separated season numbers to process (requires --series-ids)")
+	fs.String("listen", "", "Address for the serve command to listen on (overrides LISTEN_ADDR env var)")
+	fs.Bool("web-ui", false, "Serve the web dashboard at / in serve mode (overrides WEB_UI env var)")
+	fs.String("serve-api-key", "", "API key requests to the serve command must carry (overrides SERVE_API_KEY env var)")
+	fs.Int("api-budget", 0, "Max API calls per service per run before switching to report-only mode (overrides API_BUDGET env var, 0 means unlimited)")
+	fs.String("verify-at", "", "Daily time (HH:MM) for the serve command to run a verify sweep (overrides VERIFY_AT env var)")
+	fs.String("maintenance-windows", "", "Times changes may be made, e.g. \"sat,sun 02:00-06:00; mon-fri 03:00-05:00\"; outside them changes are only reported (overrides MAINTENANCE_WINDOWS env var)")
+	fs.String("only-from", "", "Only touch items listed in this dry-run actions file or report")
+	fs.String("simulate", "", "Run against canned fixture data in this directory instead of live instances")
+	fs.String("record", "", "Record all API requests/responses and file checks to this bundle file (API keys are redacted)")
+	fs.String("replay", "", "Run against the API responses and file checks recorded in this bundle file")
+	fs.String("api-client", "", "*arr API client implementation: builtin or starr (overrides API_CLIENT env var)")
+	fs.Bool("trace-http", false, "Log every API request with its latency, status code and sizes (overrides TRACE_HTTP env var)")
+	fs.Bool("trace-http-bodies", false, "Also log request and response bodies when tracing, with secrets redacted (implies --trace-http)")
+	fs.Bool("refresh-cache", false, "Fetch every series/movie again instead of using the media cache (the cache is then updated)")
+	fs.Bool("skip-specials", false, "Leave season 0 (specials) out of Sonarr cleanup (overrides SKIP_SPECIALS env var)")
+	fs.Bool("monitor-collections", false, "Monitor the Radarr collection of movies added from broken symlinks (overrides MONITOR_COLLECTIONS env var)")
+	fs.String("tag", "", "Tag series/movies whose file records were deleted with this label, e.g. refresharr-cleaned (overrides CLEANUP_TAG env var)")
+	fs.Bool("validate-adds", false, "In dry runs, check that each movie/series that would be added passes Radarr/Sonarr's checks (overrides VALIDATE_ADDS env var)")
+	fs.Bool("delete-rejected", false, "In fix-imports, delete downloads Sonarr rejects as samples or for their extension and import the rest (overrides DELETE_REJECTED_IMPORTS env var)")
+	fs.Bool("requeue", false, "In fix-imports, blocklist downloads that can't be imported and search for their episodes again (overrides REQUEUE_FAILED_IMPORTS env var)")
+	fs.String("path-patterns", "", "File of regexes with named groups (tmdb, tvdb, imdb, title, year) for parsing media paths (overrides PATH_PATTERNS_FILE env var)")
+	fs.Bool("job", false, "Run as a one-shot job: print a one-line JSON summary on stdout at the end (overrides JOB_MODE env var)")
+	fs.String("summary-file", "", "In job mode, also write the JSON summary to this file (overrides JOB_SUMMARY_FILE env var)")
+	fs.String("output", "", "Output: text, or json for a single JSON document with stats, reports and errors on stdout instead of logs (overrides OUTPUT_FORMAT env var)")
+	fs.String("log-format", "", "Log format: text or json (overrides LOG_FORMAT env var)")
+	fs.String("log-target", "", "Where logs go: stderr, syslog or journald (overrides LOG_TARGET env var)")
+	fs.String("empty-path-policy", "", "What to do with file records without a path: skip or delete (overrides EMPTY_PATH_POLICY env var)")
+	fs.String("outside-root-policy", "", "What to do with file records outside every root folder: skip, delete or rewrite (overrides OUTSIDE_ROOT_POLICY env var)")
+	fs.String("path-mappings", "", "Comma-separated from=to rules the rewrite policy applies to series/movie folders (overrides PATH_MAPPINGS env var)")
+	fs.String("checksum-manifest", "", "sha256sum manifest that verify runs check files against (overrides CHECKSUM_MANIFEST env var)")
+	fs.Int("io-ops-per-second", 0, "Max stat/readdir operations per second of file checks and symlink scans (overrides IO_OPS_PER_SECOND env var, 0 means unlimited)")
+	fs.Int("episode-concurrency", 0, "Episodes of a series checked at once (overrides EPISODE_CONCURRENCY env var, default: min(CONCURRENT_LIMIT, 3))")
+	fs.Int("movie-concurrency", 0, "Movies checked at once (overrides MOVIE_CONCURRENCY env var, default: CONCURRENT_LIMIT)")
+	fs.String("ionice", "", "IO scheduling class on Linux: idle or best-effort (overrides IONICE env var)")
+	fs.String("messages", "", "JSON file translating report summaries and notifications (overrides MESSAGES_FILE env var)")
+	fs.String("radarr-url", "", "Radarr URL (overrides RADARR_URL env var)")
+	fs.String("radarr-api-key", "", "Radarr API key (overrides RADARR_API_KEY env var)")
+	fs.String("readarr-url", "", "Readarr URL (overrides READARR_URL env var)")
+	fs.String("readarr-api-key", "", "Readarr API key (overrides READARR_API_KEY env var)")
+	fs.String("plex-url", "", "Plex URL (overrides PLEX_URL env var)")
+	fs.String("plex-token", "", "Plex token (overrides PLEX_TOKEN env var)")
+	fs.String("plex-libraries", "", "Comma-separated Plex library names or keys to use (overrides PLEX_LIBRARIES env var)")
+	fs.String("kodi-url", "", "Kodi web server URL (overrides KODI_URL env var)")
+
+	// Set custom usage function
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "RefreshArr - Missing File Cleanup Service\n\n")
+		fmt.Fprintf(os.Stderr, "Usage: %s [command] [options]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Commands:\n")
+		fmt.Fprintf(os.Stderr, "  cleanup       Clean up missing file references in *arr databases (default)\n")
+		fmt.Fprintf(os.Stderr, "  verify        Check files and sizes and report, guaranteed to make no changes\n")
+		fmt.Fprintf(os.Stderr, "  fix-imports   Fix stuck Sonarr imports (already imported issues)\n")
+		fmt.Fprintf(os.Stderr, "  compare-plex  Compare Radarr file status with Plex library availability\n")
+		fmt.Fprintf(os.Stderr, "  compare-kodi  Compare Radarr/Sonarr file status with the Kodi library\n")
+		fmt.Fprintf(os.Stderr, "  serve         Run an HTTP server that queues cleanup runs triggered via API\n")
+		fmt.Fprintf(os.Stderr, "  history       Query past runs: history list|show <run-id>|stats [--since 30d]\n")
+		fmt.Fprintf(os.Stderr, "  notify test   Send a sample alert through every configured notifier\n")
+		fmt.Fprintf(os.Stderr, "  init          Set up Sonarr/Radarr interactively and write a .env file\n")
+		fmt.Fprintf(os.Stderr, "  config        Write an example .env or check the configuration: config init [path]|validate [--online] [path]\n")
+		fmt.Fprintf(os.Stderr, "  migrate-paths Point series/movies at new folders after a storage migration (--path-mappings)\n")
+		fmt.Fprintf(os.Stderr, "  export        Write every episode/movie file record to a JSON or CSV snapshot: export [file]\n")
+		fmt.Fprintf(os.Stderr, "  compare-snapshot Diff the file records against an earlier export: compare-snapshot <old.json>\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+		PrintEnvironment(os.Stderr)
+	}
+	return fs
+}
+
+// PrintEnvironment writes the environment variables RefreshArr reads, and usage examples, to w
+func PrintEnvironment(w io.Writer) {
+	fmt.Fprintf(w, "\nEnvironment Variables:\n")
+	fmt.Fprintf(w, "  SONARR_URL      Sonarr base URL (default: http://127.0.0.1:8989)\n")
+	fmt.Fprintf(w, "  SONARR_API_KEY  Sonarr API key (required)\n")
+	fmt.Fprintf(w, "  RADARR_URL      Radarr base URL (default: http://127.0.0.1:7878)\n")
+	fmt.Fprintf(w, "  RADARR_API_KEY  Radarr API key (required for Radarr)\n")
+	fmt.Fprintf(w, "  READARR_URL     Readarr base URL (default: http://127.0.0.1:8787)\n")
+	fmt.Fprintf(w, "  READARR_API_KEY Readarr API key (required for Readarr)\n")
+	fmt.Fprintf(w, "  <SERVICE>_<NAME>_URL, <SERVICE>_<NAME>_API_KEY  Extra instance, e.g. RADARR_4K_URL and RADARR_4K_API_KEY\n")
+	fmt.Fprintf(w, "  PROWLARR_URL    Prowlarr base URL (default: http://127.0.0.1:9696)\n")
+	fmt.Fprintf(w, "  PROWLARR_API_KEY Prowlarr API key (optional, skips searches when all indexers are down)\n")
+	fmt.Fprintf(w, "  PLEX_URL        Plex base URL (default: http://127.0.0.1:32400)\n")
+	fmt.Fprintf(w, "  PLEX_TOKEN      Plex authentication token (required for Plex)\n")
+	fmt.Fprintf(w, "  PLEX_TIMEOUT    Plex request timeout (default: REQUEST_TIMEOUT)\n")
+	fmt.Fprintf(w, "  PLEX_LIBRARIES  Comma-separated Plex library names or keys (default: all)\n")
+	fmt.Fprintf(w, "  KODI_URL        Kodi web server URL (required for Kodi)\n")
+	fmt.Fprintf(w, "  KODI_USERNAME   Kodi web server username (optional)\n")
+	fmt.Fprintf(w, "  KODI_PASSWORD   Kodi web server password (optional)\n")
+	fmt.Fprintf(w, "  KODI_TIMEOUT    Kodi request timeout (default: REQUEST_TIMEOUT)\n")
+	fmt.Fprintf(w, "  REQUEST_TIMEOUT HTTP request timeout (default: 30s)\n")
+	fmt.Fprintf(w, "  REQUEST_DELAY   Delay between API requests (default: 500ms)\n")
+	fmt.Fprintf(w, "  CONCURRENT_LIMIT Max concurrent requests (default: 5)\n")
+	fmt.Fprintf(w, "  EPISODE_CONCURRENCY  Episodes of a series checked at once (default: min(CONCURRENT_LIMIT, 3))\n")
+	fmt.Fprintf(w, "  MOVIE_CONCURRENCY  Movies checked at once (default: CONCURRENT_LIMIT)\n")
+	fmt.Fprintf(w, "  API_BUDGET      Max API calls per service per run before switching to report-only (default: 0, unlimited)\n")
+	fmt.Fprintf(w, "  REPORT_SPILL_AFTER  Missing files kept in memory before spilling to a temp file (default: 10000)\n")
+	fmt.Fprintf(w, "  SINK_TIMEOUT    How long a report write or notification may take (default: 30s)\n")
+	fmt.Fprintf(w, "  SINK_QUEUE_SIZE Report writes and notifications waiting for delivery (default: 100)\n")
+	fmt.Fprintf(w, "  SIMULATE_LATENCY    Delay added to every API call in --simulate runs (default: 0s)\n")
+	fmt.Fprintf(w, "  API_CLIENT      *arr API client implementation: builtin or starr (default: builtin)\n")
+	fmt.Fprintf(w, "  USER_AGENT      User-Agent sent to Sonarr and Radarr (default: refresharr/<version>)\n")
+	fmt.Fprintf(w, "  TRACE_HTTP      Log every API request with its latency, status code and sizes (default: false)\n")
+	fmt.Fprintf(w, "  TRACE_HTTP_BODIES  Also log request and response bodies, secrets redacted (default: false)\n")
+	fmt.Fprintf(w, "  MEDIA_CACHE_TTL How long runs reuse a fetched series/movie list, 0 disables (default: 1h)\n")
+	fmt.Fprintf(w, "  SKIP_SPECIALS   Leave season 0 (specials) out of Sonarr cleanup (default: false)\n")
+	fmt.Fprintf(w, "  PATH_PATTERNS_FILE  File of extra regexes for parsing IDs, titles and years from media paths (optional)\n")
+	fmt.Fprintf(w, "  TITLE_MATCH_CONFIDENCE  Confidence a title lookup match needs before it is added, 0-1 (default: 0.9)\n")
+	fmt.Fprintf(w, "  MONITOR_COLLECTIONS  Monitor the Radarr collection of movies added from broken symlinks (default: false)\n")
+	fmt.Fprintf(w, "  CLEANUP_TAG     Tag applied to series/movies whose file records were deleted, e.g. refresharr-cleaned (optional)\n")
+	fmt.Fprintf(w, "  VALIDATE_ADDS   In dry runs, check that each planned add would succeed (default: false)\n")
+	fmt.Fprintf(w, "  DELETE_REJECTED_IMPORTS  In fix-imports, delete samples and unsupported files, then import the rest (default: false)\n")
+	fmt.Fprintf(w, "  REQUEUE_FAILED_IMPORTS  In fix-imports, blocklist downloads that can't be imported and search again (default: false)\n")
+	fmt.Fprintf(w, "  IMPORT_COOLDOWN  How long fix-imports skips a download after failing to import it, 0 disables (default: 0)\n")
+	fmt.Fprintf(w, "  MESSAGES_FILE   JSON file translating report summaries and notifications (default: English)\n")
+	fmt.Fprintf(w, "  JOB_MODE        Print a one-line JSON summary on stdout at the end, for Kubernetes Jobs (default: false)\n")
+	fmt.Fprintf(w, "  JOB_SUMMARY_FILE  In job mode, also write the JSON summary to this file (optional)\n")
+	fmt.Fprintf(w, "  OUTPUT_FORMAT     text, or json for one JSON document on stdout instead of logs (default: text)\n")
+	fmt.Fprintf(w, "  LOG_LEVEL       Log level (default: INFO)\n")
+	fmt.Fprintf(w, "  LOG_FORMAT      Log format: text or json (default: text)\n")
+	fmt.Fprintf(w, "  LOG_TARGET      Where logs go: stderr, syslog or journald (default: stderr)\n")
+	fmt.Fprintf(w, "  SYSLOG_ADDR     Syslog server as udp://host:port or tcp://host:port (default: local /dev/log)\n")
+	fmt.Fprintf(w, "  SYSLOG_FACILITY Syslog facility (default: daemon)\n")
+	fmt.Fprintf(w, "  LOG_MODULE_LEVELS Log levels of modules (cleanup, api, notify), e.g. notify=debug,cleanup=warn\n")
+	fmt.Fprintf(w, "  DRY_RUN         Run in dry-run mode (default: false)\n")
+	fmt.Fprintf(w, "  SONARR_DRY_RUN, RADARR_DRY_RUN, READARR_DRY_RUN  Override DRY_RUN for one service, also <SERVICE>_<NAME>_DRY_RUN for extra instances (default: DRY_RUN)\n")
+	fmt.Fprintf(w, "  EMPTY_PATH_POLICY  File records without a path: skip or delete (default: skip)\n")
+	fmt.Fprintf(w, "  OUTSIDE_ROOT_POLICY  File records outside every root folder: skip, delete or rewrite (default: skip)\n")
+	fmt.Fprintf(w, "  PATH_MAPPINGS   Comma-separated from=to rules for the rewrite policy, e.g. /mnt/old/tv=/data/tv\n")
+	fmt.Fprintf(w, "  CHECKSUM_MANIFEST  sha256sum manifest that verify runs check files against (default: none)\n")
+	fmt.Fprintf(w, "  IO_OPS_PER_SECOND  Max stat/readdir operations per second of file checks and scans (default: 0, unlimited)\n")
+	fmt.Fprintf(w, "  IONICE          IO scheduling class on Linux: idle or best-effort (default: unchanged)\n")
+	fmt.Fprintf(w, "  ADD_MISSING_MOVIES  Add movies/series to collection when found from broken symlinks (default: false)\n")
+	fmt.Fprintf(w, "  QUALITY_PROFILE_ID  Quality profile ID for new movies (default: 12)\n")
+	fmt.Fprintf(w, "  STATE_DIR       Directory for persistent state such as run history (default: data)\n")
+	fmt.Fprintf(w, "  REPORT_DIR      Directory reports and dry-run actions files are saved to (default: reports)\n")
+	fmt.Fprintf(w, "  REPORT_SINKS    Comma-separated URLs reports are written to, e.g. file://reports,s3://bucket/prefix?format=csv (default: JSON files in REPORT_DIR)\n")
+	fmt.Fprintf(w, "  REPORT_COMPRESS_AFTER_DAYS  Gzip files in REPORT_DIR older than this many days, 0 disables (default: 0)\n")
+	fmt.Fprintf(w, "  REPORT_ARCHIVE_MONTHLY  Bundle each past month's files in REPORT_DIR into archive-YYYY-MM.tar.gz (default: false)\n")
+	fmt.Fprintf(w, "  HISTORY_RETENTION_DAYS  Drop runs older than this many days from the run history, 0 keeps all (default: 90)\n")
+	fmt.Fprintf(w, "  TENANTS_FILE    JSON file of tenants for the serve command, each with their own instances and API key (optional)\n")
+	fmt.Fprintf(w, "  LISTEN_ADDR     Address for the serve command (default: :8080)\n")
+	fmt.Fprintf(w, "  SERVE_API_KEY   API key requests to the serve command must carry in the X-Api-Key header (required without TENANTS_FILE)\n")
+	fmt.Fprintf(w, "  WEB_UI          Serve the web dashboard at / in serve mode (default: false)\n")
+	fmt.Fprintf(w, "  VERIFY_AT       Daily time (HH:MM) for the serve command to run a verify sweep\n")
+	fmt.Fprintf(w, "  MAINTENANCE_WINDOWS  Times changes may be made, e.g. \"sat,sun 02:00-06:00; mon-fri 03:00-05:00\" (default: any time)\n")
+	fmt.Fprintf(w, "  VERIFY_ALERT_THRESHOLD Alert when a verify finds more missing files than this (default: 0, growth only)\n")
+	fmt.Fprintf(w, "  NOTIFY_WEBHOOK_URL Webhook that receives verify alerts as JSON (optional)\n")
+	fmt.Fprintf(w, "  COMPLETION_WEBHOOK_URL Webhook that receives a JSON summary of every finished run (optional)\n")
+	fmt.Fprintf(w, "  COMPLETION_WEBHOOK_RETRIES Retries of a failed completion webhook (default: 3)\n")
+	fmt.Fprintf(w, "  NTFY_URL        ntfy topic URL for push notifications of alerts and finished runs (optional)\n")
+	fmt.Fprintf(w, "  NTFY_TOKEN      Access token of a protected ntfy topic (optional)\n")
+	fmt.Fprintf(w, "  PUSHOVER_TOKEN  Pushover application token for push notifications (optional)\n")
+	fmt.Fprintf(w, "  PUSHOVER_USER   Pushover user or group key that receives the notifications\n")
+	fmt.Fprintf(w, "  PUSH_FAILURES_ONLY  Only push alerts and failed runs (default: false)\n")
+	fmt.Fprintf(w, "  NOTIFY_URLS     Comma-separated notification URLs in Apprise syntax, e.g. ntfy://topic,discord://id/token (optional)\n")
+	fmt.Fprintf(w, "  SENTRY_DSN      Report panics and failed runs to this Sentry project (optional)\n")
+	fmt.Fprintf(w, "  SENTRY_ENVIRONMENT  Sentry environment of the events (optional)\n")
+	fmt.Fprintf(w, "  ERROR_WEBHOOK_URL  Webhook that receives panics and failed runs as JSON (optional)\n")
+	fmt.Fprintf(w, "\nExamples:\n")
+	fmt.Fprintf(w, "  %s --dry-run\n", os.Args[0])
+	fmt.Fprintf(w, "  %s --service sonarr --series-ids '123,456,789'\n", os.Args[0])
+	fmt.Fprintf(w, "  %s --service sonarr --series-ids 123 --season 2\n", os.Args[0])
+	fmt.Fprintf(w, "  %s --service radarr --movie-ids '12,34'\n", os.Args[0])
+	fmt.Fprintf(w, "  %s cleanup --ids-file targets.txt\n", os.Args[0])
+	fmt.Fprintf(w, "  %s cleanup --path '/media/movies/Foo (2020)/Foo.mkv'\n", os.Args[0])
+	fmt.Fprintf(w, "  %s --sonarr-url 'http://192.168.1.100:8989' --sonarr-api-key 'your-key'\n", os.Args[0])
+	fmt.Fprintf(w, "  %s --service radarr --radarr-url 'http://192.168.1.100:7878' --radarr-api-key 'your-key'\n", os.Args[0])
+	fmt.Fprintf(w, "  %s --log-level DEBUG\n", os.Args[0])
+	fmt.Fprintf(w, "  %s fix-imports --dry-run\n", os.Args[0])
+	fmt.Fprintf(w, "  %s fix-imports --sonarr-url 'http://192.168.1.100:8989' --sonarr-api-key 'your-key'\n", os.Args[0])
+	fmt.Fprintf(w, "  %s compare-kodi series 81189\n", os.Args[0])
+	fmt.Fprintf(w, "  %s serve --listen ':9090'\n", os.Args[0])
+	fmt.Fprintf(w, "  %s history stats --since 30d\n", os.Args[0])
+	fmt.Fprintf(w, "  %s notify test\n", os.Args[0])
+	fmt.Fprintf(w, "  %s migrate-paths --dry-run --path-mappings '/mnt/old/tv=/data/tv'\n", os.Args[0])
+	fmt.Fprintf(w, "  %s export --service sonarr sonarr-files.csv\n", os.Args[0])
+	fmt.Fprintf(w, "  %s compare-snapshot reports/library-snapshot-20240101-120000.json\n", os.Args[0])
+	fmt.Fprintf(w, "  %s --only-from reports/sonarr-cleanup-actions-dryrun-20240101-120000.json\n", os.Args[0])
+}
 
+// LoadConfigWithFlags loads configuration with optional flag overrides (used for testing)
+func LoadConfigWithFlags(dryRun, noReport, showVersion *bool, logLevel, service, sonarrURL, sonarrAPIKey *string, seriesIDs *string) (*Config, error) {
 	// Parse command line flags only if not provided
 	if dryRun == nil || noReport == nil || showVersion == nil || logLevel == nil || service == nil || sonarrURL == nil || sonarrAPIKey == nil || seriesIDs == nil {
-		var (
-			dryRunFlag      = fs.Bool("dry-run", false, "Run in dry-run mode (no changes will be made)")
-			noReportFlag    = fs.Bool("no-report", false, "Disable terminal report output (report will still be saved to file)")
-			showVersionFlag = fs.Bool("version", false, "Show version information and exit")
-			logLevelFlag    = fs.String("log-level", "", "Set log level (DEBUG, INFO, WARN, ERROR)")
-			serviceFlag     = fs.String("service", "auto", "Service to use: sonarr, radarr, or auto (default: auto)")
-			sonarrURLFlag   = fs.String("sonarr-url", "", "Sonarr URL (overrides SONARR_URL env var)")
-			sonarrAPIFlag   = fs.String("sonarr-api-key", "", "Sonarr API key (overrides SONARR_API_KEY env var)")
-			seriesIDsFlag   = fs.String("series-ids", "", "Comma-separated list of specific series IDs to process (empty means all)")
-		)
-
-		// Set custom usage function
-		fs.Usage = func() {
-			fmt.Fprintf(os.Stderr, "RefreshArr - Missing File Cleanup Service\n\n")
-			fmt.Fprintf(os.Stderr, "Usage: %s [command] [options]\n\n", os.Args[0])
-			fmt.Fprintf(os.Stderr, "Commands:\n")
-			fmt.Fprintf(os.Stderr, "  (default)     Clean up missing file references in *arr databases\n")
-			fmt.Fprintf(os.Stderr, "  fix-imports   Fix stuck Sonarr imports (already imported issues)\n")
-			fmt.Fprintf(os.Stderr, "  compare-plex  Compare Radarr file status with Plex library availability\n\n")
-			fmt.Fprintf(os.Stderr, "Options:\n")
-			fs.PrintDefaults()
-			fmt.Fprintf(os.Stderr, "\nEnvironment Variables:\n")
-			fmt.Fprintf(os.Stderr, "  SONARR_URL      Sonarr base URL (default: http://127.0.0.1:8989)\n")
-			fmt.Fprintf(os.Stderr, "  SONARR_API_KEY  Sonarr API key (required)\n")
-			fmt.Fprintf(os.Stderr, "  RADARR_URL      Radarr base URL (default: http://127.0.0.1:7878)\n")
-			fmt.Fprintf(os.Stderr, "  RADARR_API_KEY  Radarr API key (required for Radarr)\n")
-			fmt.Fprintf(os.Stderr, "  PLEX_URL        Plex base URL (default: http://127.0.0.1:32400)\n")
-			fmt.Fprintf(os.Stderr, "  PLEX_TOKEN      Plex authentication token (required for Plex)\n")
-			fmt.Fprintf(os.Stderr, "  REQUEST_TIMEOUT HTTP request timeout (default: 30s)\n")
-			fmt.Fprintf(os.Stderr, "  REQUEST_DELAY   Delay between API requests (default: 500ms)\n")
-			fmt.Fprintf(os.Stderr, "  CONCURRENT_LIMIT Max concurrent requests (default: 5)\n")
-			fmt.Fprintf(os.Stderr, "  LOG_LEVEL       Log level (default: INFO)\n")
-			fmt.Fprintf(os.Stderr, "  DRY_RUN         Run in dry-run mode (default: false)\n")
-			fmt.Fprintf(os.Stderr, "  ADD_MISSING_MOVIES  Add movies/series to collection when found from broken symlinks (default: false)\n")
-			fmt.Fprintf(os.Stderr, "  QUALITY_PROFILE_ID  Quality profile ID for new movies (default: 12)\n")
-			fmt.Fprintf(os.Stderr, "\nExamples:\n")
-			fmt.Fprintf(os.Stderr, "  %s --dry-run\n", os.Args[0])
-			fmt.Fprintf(os.Stderr, "  %s --service sonarr --series-ids '123,456,789'\n", os.Args[0])
-			fmt.Fprintf(os.Stderr, "  %s --sonarr-url 'http://192.168.1.100:8989' --sonarr-api-key 'your-key'\n", os.Args[0])
-			fmt.Fprintf(os.Stderr, "  %s --log-level DEBUG\n", os.Args[0])
-			fmt.Fprintf(os.Stderr, "  %s fix-imports --dry-run\n", os.Args[0])
-			fmt.Fprintf(os.Stderr, "  %s fix-imports --sonarr-url 'http://192.168.1.100:8989' --sonarr-api-key 'your-key'\n", os.Args[0])
-		}
+		// Create a new FlagSet for isolated flag parsing (prevents test conflicts)
+		fs := NewFlagSet()
 
 		// Parse flags (only if we're not in test mode)
 		// In tests, we skip flag parsing since test flags conflict with our flags
+		var positional []string
 		args := os.Args[1:]
 		if len(args) > 0 && !strings.Contains(args[0], "test") {
 			err := fs.Parse(args)
 			if err != nil {
 				return nil, fmt.Errorf("error parsing flags: %w", err)
 			}
+			positional = fs.Args()
 		}
+		return loadConfig(fs, positional, dryRun, noReport, showVersion, logLevel, service, sonarrURL, sonarrAPIKey, seriesIDs)
+	}
+	return loadConfig(nil, nil, dryRun, noReport, showVersion, logLevel, service, sonarrURL, sonarrAPIKey, seriesIDs)
+}
+
+// LoadConfigFromFlags loads configuration with the values of fs, a flag set created by NewFlagSet
+// that has been parsed already, and the positional arguments left after the flags
+func LoadConfigFromFlags(fs *flag.FlagSet, args []string) (*Config, error) {
+	return loadConfig(fs, args, nil, nil, nil, nil, nil, nil, nil, nil)
+}
+
+// lookupString returns the value of a string flag fs defines
+func lookupString(fs *flag.FlagSet, name string) *string {
+	value := fs.Lookup(name).Value.(flag.Getter).Get().(string)
+	return &value
+}
+
+// lookupBool returns the value of a bool flag fs defines
+func lookupBool(fs *flag.FlagSet, name string) *bool {
+	value := fs.Lookup(name).Value.(flag.Getter).Get().(bool)
+	return &value
+}
+
+// lookupInt returns the value of an int flag fs defines
+func lookupInt(fs *flag.FlagSet, name string) *int {
+	value := fs.Lookup(name).Value.(flag.Getter).Get().(int)
+	return &value
+}
+
+// loadConfig loads configuration from the environment and, when fs isn't nil, its flags. Overrides
+// that aren't nil take precedence over the flags (used for testing).
+func loadConfig(fs *flag.FlagSet, positional []string, dryRun, noReport, showVersion *bool, logLevel, service, sonarrURL, sonarrAPIKey *string, seriesIDs *string) (*Config, error) {
+	// Flags without a test override
+	var listenAddr, serveAPIKey, onlyFrom, radarrURL, radarrAPIKey, readarrURL, readarrAPIKey, plexURL, plexToken, plexLibraries, kodiURL, seasons, episodeIDs, targetPath, idsFile, verifyAt, simulate, record, replay, apiClient, pathPatterns, messagesFile, movieIDs, summaryFile, output, logFormat, logTarget, emptyPathPolicy, outsideRootPolicy, pathMappings, checksumManifest, cleanupTag *string
+	var apiBudget, ioOpsPerSecond, episodeConcurrency, movieConcurrency *int
+	var ioNice, maintenanceWindows *string
+	var traceHTTP, traceHTTPBodies, refreshCache, skipSpecials, monitorCollections, validateAdds, deleteRejected, requeue, jobMode, webUI *bool
+
+	if fs != nil {
+		episodeIDs = lookupString(fs, "episode-ids")
+		movieIDs = lookupString(fs, "movie-ids")
+		idsFile = lookupString(fs, "ids-file")
+		targetPath = lookupString(fs, "path")
+		seasons = lookupString(fs, "season")
+		listenAddr = lookupString(fs, "listen")
+		serveAPIKey = lookupString(fs, "serve-api-key")
+		webUI = lookupBool(fs, "web-ui")
+		apiBudget = lookupInt(fs, "api-budget")
+		verifyAt = lookupString(fs, "verify-at")
+		maintenanceWindows = lookupString(fs, "maintenance-windows")
+		onlyFrom = lookupString(fs, "only-from")
+		simulate = lookupString(fs, "simulate")
+		record = lookupString(fs, "record")
+		replay = lookupString(fs, "replay")
+		apiClient = lookupString(fs, "api-client")
+		traceHTTP = lookupBool(fs, "trace-http")
+		traceHTTPBodies = lookupBool(fs, "trace-http-bodies")
+		refreshCache = lookupBool(fs, "refresh-cache")
+		skipSpecials = lookupBool(fs, "skip-specials")
+		monitorCollections = lookupBool(fs, "monitor-collections")
+		validateAdds = lookupBool(fs, "validate-adds")
+		cleanupTag = lookupString(fs, "tag")
+		deleteRejected = lookupBool(fs, "delete-rejected")
+		requeue = lookupBool(fs, "requeue")
+		pathPatterns = lookupString(fs, "path-patterns")
+		jobMode = lookupBool(fs, "job")
+		summaryFile = lookupString(fs, "summary-file")
+		output = lookupString(fs, "output")
+		logFormat = lookupString(fs, "log-format")
+		logTarget = lookupString(fs, "log-target")
+		emptyPathPolicy = lookupString(fs, "empty-path-policy")
+		outsideRootPolicy = lookupString(fs, "outside-root-policy")
+		pathMappings = lookupString(fs, "path-mappings")
+		checksumManifest = lookupString(fs, "checksum-manifest")
+		ioOpsPerSecond = lookupInt(fs, "io-ops-per-second")
+		episodeConcurrency = lookupInt(fs, "episode-concurrency")
+		movieConcurrency = lookupInt(fs, "movie-concurrency")
+		ioNice = lookupString(fs, "ionice")
+		messagesFile = lookupString(fs, "messages")
+		radarrURL = lookupString(fs, "radarr-url")
+		radarrAPIKey = lookupString(fs, "radarr-api-key")
+		readarrURL = lookupString(fs, "readarr-url")
+		readarrAPIKey = lookupString(fs, "readarr-api-key")
+		plexURL = lookupString(fs, "plex-url")
+		plexToken = lookupString(fs, "plex-token")
+		plexLibraries = lookupString(fs, "plex-libraries")
+		kodiURL = lookupString(fs, "kodi-url")
 
 		// Use parsed values if not provided
 		if dryRun == nil {
-			dryRun = dryRunFlag
+			dryRun = lookupBool(fs, "dry-run")
 		}
 		if noReport == nil {
-			noReport = noReportFlag
+			noReport = lookupBool(fs, "no-report")
 		}
 		if showVersion == nil {
-			showVersion = showVersionFlag
+			showVersion = lookupBool(fs, "version")
 		}
 		if logLevel == nil {
-			logLevel = logLevelFlag
+			logLevel = lookupString(fs, "log-level")
 		}
 		if service == nil {
-			service = serviceFlag
+			service = lookupString(fs, "service")
 		}
 		if sonarrURL == nil {
-			sonarrURL = sonarrURLFlag
+			sonarrURL = lookupString(fs, "sonarr-url")
 		}
 		if sonarrAPIKey == nil {
-			sonarrAPIKey = sonarrAPIFlag
+			sonarrAPIKey = lookupString(fs, "sonarr-api-key")
 		}
 		if seriesIDs == nil {
-			seriesIDs = seriesIDsFlag
+			seriesIDs = lookupString(fs, "series-ids")
 		}
-
-		// Handle new flags (they'll be processed later in the config loading)
 	}
 
 	// Load .env file if it exists (ignore errors - .env file is optional)
@@ -166,10 +848,14 @@ func LoadConfigWithFlags(dryRun, noReport, showVersion *bool, logLevel, service,
 		config.DryRun = true
 	} else if dryRunEnv := os.Getenv("DRY_RUN"); dryRunEnv != "" {
 		config.DryRun = dryRunEnv == "true" || dryRunEnv == "1"
+		if !config.DryRun && dryRunEnv != "false" && dryRunEnv != "0" {
+			config.Ignored = append(config.Ignored, fmt.Sprintf("DRY_RUN %q is read as false: set it to true or false", dryRunEnv))
+		}
 	} else {
 		config.DryRun = false
 	}
 	config.NoReport = noReport != nil && *noReport
+	config.Args = positional
 	config.ShowVersion = showVersion != nil && *showVersion
 
 	// Set service (default to "auto")
@@ -181,70 +867,324 @@ func LoadConfigWithFlags(dryRun, noReport, showVersion *bool, logLevel, service,
 
 	// Parse series IDs if provided
 	if seriesIDs != nil && *seriesIDs != "" {
-		ids, err := parseSeriesIDs(*seriesIDs)
+		ids, err := parseIDs("series ID", *seriesIDs)
 		if err != nil {
 			return nil, fmt.Errorf("error parsing series IDs: %w", err)
 		}
 		config.SeriesIDs = ids
 	}
 
+	// Parse seasons if provided; they narrow a --series-ids run
+	if seasons != nil && *seasons != "" {
+		numbers, err := parseIDs("season", *seasons)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing seasons: %w", err)
+		}
+		for _, number := range numbers {
+			if number < 0 {
+				return nil, fmt.Errorf("invalid season %d: must not be negative", number)
+			}
+		}
+		if len(numbers) > 0 && len(config.SeriesIDs) == 0 {
+			return nil, fmt.Errorf("--season requires --series-ids")
+		}
+		config.Seasons = numbers
+	}
+
+	// Parse explicit item targets
+	if episodeIDs != nil && *episodeIDs != "" {
+		ids, err := parseIDs("episode ID", *episodeIDs)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing episode IDs: %w", err)
+		}
+		config.EpisodeIDs = ids
+	}
+	if movieIDs != nil && *movieIDs != "" {
+		ids, err := parseIDs("movie ID", *movieIDs)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing movie IDs: %w", err)
+		}
+		config.MovieIDs = ids
+	}
+	if targetPath != nil {
+		config.TargetPath = strings.TrimSpace(*targetPath)
+	}
+	if idsFile != nil {
+		config.IDsFile = *idsFile
+	}
+	if err := checkTargets(config); err != nil {
+		return nil, err
+	}
+
 	// Load configuration from environment variables with CLI flag overrides
 
 	// Sonarr configuration
-	config.Sonarr.APIKey = os.Getenv("SONARR_API_KEY")
-	if config.Sonarr.APIKey != "" {
-		// Only set default URL if API key is provided
-		config.Sonarr.URL = getEnvOrDefault("SONARR_URL", "http://127.0.0.1:8989")
-	} else {
-		// Use URL from environment if provided, but no default
-		config.Sonarr.URL = os.Getenv("SONARR_URL")
+	config.Sonarr.URL, config.Sonarr.APIKey = loadEndpoint("SONARR_URL", "SONARR_API_KEY", "http://127.0.0.1:8989", sonarrURL, sonarrAPIKey)
+
+	// Radarr configuration
+	config.Radarr.URL, config.Radarr.APIKey = loadEndpoint("RADARR_URL", "RADARR_API_KEY", "http://127.0.0.1:7878", radarrURL, radarrAPIKey)
+
+	// Readarr configuration
+	config.Readarr.URL, config.Readarr.APIKey = loadEndpoint("READARR_URL", "READARR_API_KEY", "http://127.0.0.1:8787", readarrURL, readarrAPIKey)
+
+	// Prowlarr configuration
+	config.Prowlarr.URL, config.Prowlarr.APIKey = loadEndpoint("PROWLARR_URL", "PROWLARR_API_KEY", "http://127.0.0.1:9696", nil, nil)
+
+	config.Sonarr.URL = normalizeEndpointURL(config.Sonarr.URL)
+	config.Radarr.URL = normalizeEndpointURL(config.Radarr.URL)
+	config.Readarr.URL = normalizeEndpointURL(config.Readarr.URL)
+	config.Prowlarr.URL = normalizeEndpointURL(config.Prowlarr.URL)
+
+	// Extra instances, e.g. RADARR_4K_URL and RADARR_4K_API_KEY
+	config.Instances = loadInstances()
+
+	// Per-service dry-run overrides. --dry-run keeps every service in dry-run.
+	if dryRun == nil || !*dryRun {
+		overrides, err := loadServiceDryRun(config.Instances)
+		if err != nil {
+			return nil, err
+		}
+		config.ServiceDryRun = overrides
 	}
 
-	// Override with CLI flags if provided
-	if sonarrURL != nil && *sonarrURL != "" {
-		config.Sonarr.URL = *sonarrURL
+	// Plex configuration
+	config.Plex.URL, config.Plex.Token = loadEndpoint("PLEX_URL", "PLEX_TOKEN", "http://127.0.0.1:32400", plexURL, plexToken)
+
+	if timeoutStr := os.Getenv("PLEX_TIMEOUT"); timeoutStr != "" {
+		timeout, err := time.ParseDuration(timeoutStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PLEX_TIMEOUT %q: %w", timeoutStr, err)
+		}
+		config.Plex.Timeout = timeout
 	}
-	if sonarrAPIKey != nil && *sonarrAPIKey != "" {
-		config.Sonarr.APIKey = *sonarrAPIKey
+
+	libraries := os.Getenv("PLEX_LIBRARIES")
+	if plexLibraries != nil && *plexLibraries != "" {
+		libraries = *plexLibraries
 	}
+	config.Plex.Libraries = parseList(libraries)
 
-	// Radarr configuration
-	config.Radarr.APIKey = os.Getenv("RADARR_API_KEY")
-	if config.Radarr.APIKey != "" {
-		// Only set default URL if API key is provided
-		config.Radarr.URL = getEnvOrDefault("RADARR_URL", "http://127.0.0.1:7878")
-	} else {
-		// Use URL from environment if provided, but no default
-		config.Radarr.URL = os.Getenv("RADARR_URL")
+	// Normalize the Plex URL so clients can append paths directly
+	config.Plex.URL = strings.TrimRight(normalizeEndpointURL(config.Plex.URL), "/")
+
+	// Kodi configuration
+	config.Kodi.URL = os.Getenv("KODI_URL")
+	if kodiURL != nil && *kodiURL != "" {
+		config.Kodi.URL = *kodiURL
 	}
+	config.Kodi.URL = strings.TrimRight(normalizeEndpointURL(config.Kodi.URL), "/")
+	config.Kodi.Username = os.Getenv("KODI_USERNAME")
+	config.Kodi.Password = os.Getenv("KODI_PASSWORD")
 
-	// Plex configuration
-	config.Plex.Token = os.Getenv("PLEX_TOKEN")
-	if config.Plex.Token != "" {
-		// Only set default URL if token is provided
-		config.Plex.URL = getEnvOrDefault("PLEX_URL", "http://127.0.0.1:32400")
-	} else {
-		// Use URL from environment if provided, but no default
-		config.Plex.URL = os.Getenv("PLEX_URL")
+	if timeoutStr := os.Getenv("KODI_TIMEOUT"); timeoutStr != "" {
+		timeout, err := time.ParseDuration(timeoutStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid KODI_TIMEOUT %q: %w", timeoutStr, err)
+		}
+		config.Kodi.Timeout = timeout
 	}
 
 	// Request configuration
+	// Unusable values fall back to the default rather than fail the run; validate reports them
 	if timeoutStr := os.Getenv("REQUEST_TIMEOUT"); timeoutStr != "" {
 		if timeout, err := time.ParseDuration(timeoutStr); err == nil {
 			config.RequestTimeout = timeout
+		} else {
+			config.Ignored = append(config.Ignored, ignoredDuration("REQUEST_TIMEOUT", timeoutStr, "30s"))
 		}
 	}
 
 	if delayStr := os.Getenv("REQUEST_DELAY"); delayStr != "" {
 		if delay, err := time.ParseDuration(delayStr); err == nil {
 			config.RequestDelay = delay
+		} else {
+			config.Ignored = append(config.Ignored, ignoredDuration("REQUEST_DELAY", delayStr, "500ms"))
 		}
 	}
 
 	if limitStr := os.Getenv("CONCURRENT_LIMIT"); limitStr != "" {
 		if limit, err := strconv.Atoi(limitStr); err == nil {
 			config.ConcurrentLimit = limit
+		} else {
+			config.Ignored = append(config.Ignored, fmt.Sprintf("CONCURRENT_LIMIT %q is not a number, so the default 5 is used", limitStr))
+		}
+	}
+
+	// Per-level concurrency, for APIs that are faster or slower than the defaults suit
+	if concurrencyStr := os.Getenv("EPISODE_CONCURRENCY"); concurrencyStr != "" {
+		concurrency, err := strconv.Atoi(concurrencyStr)
+		if err != nil || concurrency < 1 {
+			return nil, fmt.Errorf("invalid EPISODE_CONCURRENCY %q: must be a positive number", concurrencyStr)
+		}
+		config.EpisodeConcurrency = concurrency
+	}
+	if episodeConcurrency != nil && *episodeConcurrency != 0 {
+		if *episodeConcurrency < 0 {
+			return nil, fmt.Errorf("invalid --episode-concurrency %d: must not be negative", *episodeConcurrency)
+		}
+		config.EpisodeConcurrency = *episodeConcurrency
+	}
+	if concurrencyStr := os.Getenv("MOVIE_CONCURRENCY"); concurrencyStr != "" {
+		concurrency, err := strconv.Atoi(concurrencyStr)
+		if err != nil || concurrency < 1 {
+			return nil, fmt.Errorf("invalid MOVIE_CONCURRENCY %q: must be a positive number", concurrencyStr)
+		}
+		config.MovieConcurrency = concurrency
+	}
+	if movieConcurrency != nil && *movieConcurrency != 0 {
+		if *movieConcurrency < 0 {
+			return nil, fmt.Errorf("invalid --movie-concurrency %d: must not be negative", *movieConcurrency)
+		}
+		config.MovieConcurrency = *movieConcurrency
+	}
+
+	// API budget configuration
+	if budgetStr := os.Getenv("API_BUDGET"); budgetStr != "" {
+		budget, err := strconv.Atoi(budgetStr)
+		if err != nil || budget < 0 {
+			return nil, fmt.Errorf("invalid API_BUDGET %q: must be a non-negative number", budgetStr)
+		}
+		config.APIBudget = budget
+	}
+	if apiBudget != nil && *apiBudget != 0 {
+		if *apiBudget < 0 {
+			return nil, fmt.Errorf("invalid --api-budget %d: must not be negative", *apiBudget)
+		}
+		config.APIBudget = *apiBudget
+	}
+
+	// Missing file spill configuration
+	if spillStr := os.Getenv("REPORT_SPILL_AFTER"); spillStr != "" {
+		spillAfter, err := strconv.Atoi(spillStr)
+		if err != nil || spillAfter < 0 {
+			return nil, fmt.Errorf("invalid REPORT_SPILL_AFTER %q: must be a non-negative number", spillStr)
+		}
+		config.SpillAfter = spillAfter
+	}
+
+	// Report and notification delivery limits
+	if timeoutStr := os.Getenv("SINK_TIMEOUT"); timeoutStr != "" {
+		timeout, err := time.ParseDuration(timeoutStr)
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("invalid SINK_TIMEOUT %q: must be a positive duration", timeoutStr)
+		}
+		config.SinkTimeout = timeout
+	}
+	if sizeStr := os.Getenv("SINK_QUEUE_SIZE"); sizeStr != "" {
+		size, err := strconv.Atoi(sizeStr)
+		if err != nil || size < 1 {
+			return nil, fmt.Errorf("invalid SINK_QUEUE_SIZE %q: must be a positive number", sizeStr)
 		}
+		config.SinkQueueSize = size
+	}
+
+	// API client configuration
+	config.APIClient = getEnvOrDefault("API_CLIENT", APIClientBuiltin)
+	if apiClient != nil && *apiClient != "" {
+		config.APIClient = *apiClient
+	}
+	config.APIClient = strings.ToLower(strings.TrimSpace(config.APIClient))
+	if config.APIClient != APIClientBuiltin && config.APIClient != APIClientStarr {
+		return nil, fmt.Errorf("invalid API client %q: must be %s or %s", config.APIClient, APIClientBuiltin, APIClientStarr)
+	}
+	config.UserAgent = strings.TrimSpace(os.Getenv("USER_AGENT"))
+
+	// HTTP trace configuration
+	config.TraceHTTP = getEnvBool("TRACE_HTTP", false) || (traceHTTP != nil && *traceHTTP)
+	config.TraceHTTPBodies = getEnvBool("TRACE_HTTP_BODIES", false) || (traceHTTPBodies != nil && *traceHTTPBodies)
+	if config.TraceHTTPBodies {
+		config.TraceHTTP = true
+	}
+
+	// Media cache configuration
+	config.MediaCacheTTL = time.Hour
+	if ttlStr := os.Getenv("MEDIA_CACHE_TTL"); ttlStr != "" {
+		ttl, err := time.ParseDuration(ttlStr)
+		if err != nil || ttl < 0 {
+			return nil, fmt.Errorf("invalid MEDIA_CACHE_TTL %q: must be a non-negative duration", ttlStr)
+		}
+		config.MediaCacheTTL = ttl
+	}
+	config.RefreshCache = refreshCache != nil && *refreshCache
+
+	// Specials configuration
+	config.SkipSpecials = getEnvBool("SKIP_SPECIALS", false) || (skipSpecials != nil && *skipSpecials)
+
+	// Path pattern configuration
+	patternsFile := os.Getenv("PATH_PATTERNS_FILE")
+	if pathPatterns != nil && *pathPatterns != "" {
+		patternsFile = *pathPatterns
+	}
+	if patternsFile != "" {
+		patterns, err := loadPathPatterns(patternsFile)
+		if err != nil {
+			return nil, err
+		}
+		config.PathPatterns = patterns
+	}
+
+	// Title lookup configuration
+	config.TitleMatchConfidence = 0.9
+	if confidenceStr := os.Getenv("TITLE_MATCH_CONFIDENCE"); confidenceStr != "" {
+		confidence, err := strconv.ParseFloat(confidenceStr, 64)
+		if err != nil || confidence <= 0 || confidence > 1 {
+			return nil, fmt.Errorf("invalid TITLE_MATCH_CONFIDENCE %q: must be a number above 0 and at most 1", confidenceStr)
+		}
+		config.TitleMatchConfidence = confidence
+	}
+
+	// Radarr collection configuration
+	config.MonitorCollections = getEnvBool("MONITOR_COLLECTIONS", false) || (monitorCollections != nil && *monitorCollections)
+
+	// Add validation configuration
+	config.ValidateAdds = getEnvBool("VALIDATE_ADDS", false) || (validateAdds != nil && *validateAdds)
+
+	// Tagging configuration. Sonarr and Radarr only accept lower case letters, digits and dashes.
+	config.CleanupTag = os.Getenv("CLEANUP_TAG")
+	if cleanupTag != nil && *cleanupTag != "" {
+		config.CleanupTag = *cleanupTag
+	}
+	config.CleanupTag = strings.ToLower(strings.TrimSpace(config.CleanupTag))
+	if config.CleanupTag != "" && !tagLabelPattern.MatchString(config.CleanupTag) {
+		return nil, fmt.Errorf("invalid CLEANUP_TAG %q: only letters, digits and dashes are allowed", config.CleanupTag)
+	}
+
+	// Import fixing configuration
+	config.DeleteRejectedImports = getEnvBool("DELETE_REJECTED_IMPORTS", false) || (deleteRejected != nil && *deleteRejected)
+	config.RequeueFailedImports = getEnvBool("REQUEUE_FAILED_IMPORTS", false) || (requeue != nil && *requeue)
+	if cooldownStr := os.Getenv("IMPORT_COOLDOWN"); cooldownStr != "" {
+		cooldown, err := time.ParseDuration(cooldownStr)
+		if err != nil || cooldown < 0 {
+			return nil, fmt.Errorf("invalid IMPORT_COOLDOWN %q: must be a non-negative duration", cooldownStr)
+		}
+		config.ImportCooldown = cooldown
+	}
+
+	// Localization configuration
+	catalogFile := os.Getenv("MESSAGES_FILE")
+	if messagesFile != nil && *messagesFile != "" {
+		catalogFile = *messagesFile
+	}
+	catalog, err := messages.Load(catalogFile)
+	if err != nil {
+		return nil, err
+	}
+	config.Messages = catalog
+
+	// Job mode configuration
+	config.JobMode = getEnvBool("JOB_MODE", false) || (jobMode != nil && *jobMode)
+	config.SummaryFile = os.Getenv("JOB_SUMMARY_FILE")
+	if summaryFile != nil && *summaryFile != "" {
+		config.SummaryFile = *summaryFile
+	}
+
+	// Output configuration
+	config.Output = strings.ToLower(getEnvOrDefault("OUTPUT_FORMAT", OutputText))
+	if output != nil && *output != "" {
+		config.Output = strings.ToLower(*output)
+	}
+	if config.Output != OutputText && config.Output != OutputJSON {
+		return nil, fmt.Errorf("invalid output %q: must be %s or %s", config.Output, OutputText, OutputJSON)
 	}
 
 	// Log level configuration
@@ -256,6 +1196,95 @@ func LoadConfigWithFlags(dryRun, noReport, showVersion *bool, logLevel, service,
 		config.LogLevel = "INFO"
 	}
 
+	// Log format configuration
+	config.LogFormat = strings.ToLower(getEnvOrDefault("LOG_FORMAT", "text"))
+	if logFormat != nil && *logFormat != "" {
+		config.LogFormat = strings.ToLower(*logFormat)
+	}
+	if config.LogFormat != "text" && config.LogFormat != "json" {
+		return nil, fmt.Errorf("invalid log format %q: must be text or json", config.LogFormat)
+	}
+
+	// Log target configuration
+	config.LogTarget = strings.ToLower(getEnvOrDefault("LOG_TARGET", "stderr"))
+	if logTarget != nil && *logTarget != "" {
+		config.LogTarget = strings.ToLower(*logTarget)
+	}
+	switch config.LogTarget {
+	case "stderr", "syslog", "journald":
+	default:
+		return nil, fmt.Errorf("invalid log target %q: must be stderr, syslog or journald", config.LogTarget)
+	}
+	config.SyslogAddr = os.Getenv("SYSLOG_ADDR")
+	config.SyslogFacility = getEnvOrDefault("SYSLOG_FACILITY", "daemon")
+	moduleLevels, err := parseLogModuleLevels(os.Getenv("LOG_MODULE_LEVELS"))
+	if err != nil {
+		return nil, err
+	}
+	config.LogModuleLevels = moduleLevels
+
+	// Empty path policy
+	config.EmptyPathPolicy = strings.ToLower(getEnvOrDefault("EMPTY_PATH_POLICY", EmptyPathSkip))
+	if emptyPathPolicy != nil && *emptyPathPolicy != "" {
+		config.EmptyPathPolicy = strings.ToLower(*emptyPathPolicy)
+	}
+	if config.EmptyPathPolicy != EmptyPathSkip && config.EmptyPathPolicy != EmptyPathDelete {
+		return nil, fmt.Errorf("invalid empty path policy %q: must be %s or %s", config.EmptyPathPolicy, EmptyPathSkip, EmptyPathDelete)
+	}
+
+	// Outside root folder policy
+	config.OutsideRootPolicy = strings.ToLower(getEnvOrDefault("OUTSIDE_ROOT_POLICY", OutsideRootSkip))
+	if outsideRootPolicy != nil && *outsideRootPolicy != "" {
+		config.OutsideRootPolicy = strings.ToLower(*outsideRootPolicy)
+	}
+	switch config.OutsideRootPolicy {
+	case OutsideRootSkip, OutsideRootDelete, OutsideRootRewrite:
+	default:
+		return nil, fmt.Errorf("invalid outside root policy %q: must be %s, %s or %s", config.OutsideRootPolicy, OutsideRootSkip, OutsideRootDelete, OutsideRootRewrite)
+	}
+	mappingRules := os.Getenv("PATH_MAPPINGS")
+	if pathMappings != nil && *pathMappings != "" {
+		mappingRules = *pathMappings
+	}
+	mappings, err := models.ParsePathMappings(mappingRules)
+	if err != nil {
+		return nil, err
+	}
+	config.PathMappings = mappings
+	if config.OutsideRootPolicy == OutsideRootRewrite && len(config.PathMappings) == 0 {
+		return nil, fmt.Errorf("PATH_MAPPINGS is required with the %s outside root policy", OutsideRootRewrite)
+	}
+
+	// Checksum manifest for verify runs
+	config.ChecksumManifest = os.Getenv("CHECKSUM_MANIFEST")
+	if checksumManifest != nil && *checksumManifest != "" {
+		config.ChecksumManifest = *checksumManifest
+	}
+
+	// Disk IO pacing
+	if opsStr := os.Getenv("IO_OPS_PER_SECOND"); opsStr != "" {
+		ops, err := strconv.Atoi(opsStr)
+		if err != nil || ops < 0 {
+			return nil, fmt.Errorf("invalid IO_OPS_PER_SECOND %q: must be a non-negative number", opsStr)
+		}
+		config.IOOpsPerSecond = ops
+	}
+	if ioOpsPerSecond != nil && *ioOpsPerSecond != 0 {
+		if *ioOpsPerSecond < 0 {
+			return nil, fmt.Errorf("invalid --io-ops-per-second %d: must not be negative", *ioOpsPerSecond)
+		}
+		config.IOOpsPerSecond = *ioOpsPerSecond
+	}
+	config.IONice = strings.ToLower(os.Getenv("IONICE"))
+	if ioNice != nil && *ioNice != "" {
+		config.IONice = strings.ToLower(*ioNice)
+	}
+	switch config.IONice {
+	case "", IONiceIdle, IONiceBestEffort:
+	default:
+		return nil, fmt.Errorf("invalid IO scheduling class %q: must be %s or %s", config.IONice, IONiceIdle, IONiceBestEffort)
+	}
+
 	// Configure broken symlink handling
 	config.AddMissingMovies = getEnvBool("ADD_MISSING_MOVIES", false)
 	if qualityProfileStr := os.Getenv("QUALITY_PROFILE_ID"); qualityProfileStr != "" {
@@ -268,6 +1297,131 @@ func LoadConfigWithFlags(dryRun, noReport, showVersion *bool, logLevel, service,
 		config.QualityProfileID = 12 // Default
 	}
 
+	if onlyFrom != nil {
+		config.OnlyFrom = *onlyFrom
+	}
+
+	// Simulation configuration
+	if simulate != nil {
+		config.Simulate = *simulate
+	}
+	if latencyStr := os.Getenv("SIMULATE_LATENCY"); latencyStr != "" {
+		latency, err := time.ParseDuration(latencyStr)
+		if err != nil || latency < 0 {
+			return nil, fmt.Errorf("invalid SIMULATE_LATENCY %q: must be a non-negative duration", latencyStr)
+		}
+		config.SimulateLatency = latency
+	}
+
+	// Debug bundle configuration
+	if record != nil {
+		config.Record = *record
+	}
+	if replay != nil {
+		config.Replay = *replay
+	}
+	if (config.Simulate != "" && config.Record != "") || (config.Replay != "" && (config.Simulate != "" || config.Record != "")) {
+		return nil, fmt.Errorf("only one of --simulate, --record and --replay can be used")
+	}
+
+	// Server mode configuration
+	config.StateDir = getEnvOrDefault("STATE_DIR", "data")
+	config.ReportDir = getEnvOrDefault("REPORT_DIR", "reports")
+	if daysStr := os.Getenv("REPORT_COMPRESS_AFTER_DAYS"); daysStr != "" {
+		days, err := strconv.Atoi(daysStr)
+		if err != nil || days < 0 {
+			return nil, fmt.Errorf("invalid REPORT_COMPRESS_AFTER_DAYS %q: must be a non-negative number", daysStr)
+		}
+		config.ReportCompressAfterDays = days
+	}
+	config.ReportArchiveMonthly = getEnvBool("REPORT_ARCHIVE_MONTHLY", false)
+	config.HistoryRetentionDays = 90
+	if daysStr := os.Getenv("HISTORY_RETENTION_DAYS"); daysStr != "" {
+		days, err := strconv.Atoi(daysStr)
+		if err != nil || days < 0 {
+			return nil, fmt.Errorf("invalid HISTORY_RETENTION_DAYS %q: must be a non-negative number", daysStr)
+		}
+		config.HistoryRetentionDays = days
+	}
+	config.ReportSinks = strings.FieldsFunc(os.Getenv("REPORT_SINKS"), func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+	for i, sink := range config.ReportSinks {
+		// The output document must be all there is on stdout
+		if strings.HasPrefix(sink, "stdout:") && config.Output == OutputJSON {
+			return nil, fmt.Errorf("invalid REPORT_SINKS entry %d: stdout:// can't be used with --output json", i+1)
+		}
+	}
+	if tenantsFile := os.Getenv("TENANTS_FILE"); tenantsFile != "" {
+		tenants, err := loadTenants(tenantsFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Tenants = tenants
+	}
+	if listenAddr != nil && *listenAddr != "" {
+		config.ListenAddr = *listenAddr
+	} else {
+		config.ListenAddr = getEnvOrDefault("LISTEN_ADDR", ":8080")
+	}
+	config.APIKey = os.Getenv("SERVE_API_KEY")
+	if serveAPIKey != nil && *serveAPIKey != "" {
+		config.APIKey = *serveAPIKey
+	}
+	config.WebUI = getEnvBool("WEB_UI", false) || (webUI != nil && *webUI)
+	config.VerifyAt = os.Getenv("VERIFY_AT")
+	if verifyAt != nil && *verifyAt != "" {
+		config.VerifyAt = *verifyAt
+	}
+	if config.VerifyAt != "" {
+		if _, err := time.Parse("15:04", config.VerifyAt); err != nil {
+			return nil, fmt.Errorf("invalid verify time %q: expected HH:MM", config.VerifyAt)
+		}
+	}
+
+	// Maintenance windows
+	windowSpec := os.Getenv("MAINTENANCE_WINDOWS")
+	if maintenanceWindows != nil && *maintenanceWindows != "" {
+		windowSpec = *maintenanceWindows
+	}
+	windows, err := models.ParseMaintenanceWindows(windowSpec)
+	if err != nil {
+		return nil, err
+	}
+	config.MaintenanceWindows = windows
+
+	// Verify alerting configuration
+	if thresholdStr := os.Getenv("VERIFY_ALERT_THRESHOLD"); thresholdStr != "" {
+		threshold, err := strconv.Atoi(thresholdStr)
+		if err != nil || threshold < 0 {
+			return nil, fmt.Errorf("invalid VERIFY_ALERT_THRESHOLD %q: must be a non-negative number", thresholdStr)
+		}
+		config.AlertThreshold = threshold
+	}
+	config.Notify.WebhookURL = strings.TrimSpace(os.Getenv("NOTIFY_WEBHOOK_URL"))
+	config.Notify.CompletionURL = strings.TrimSpace(os.Getenv("COMPLETION_WEBHOOK_URL"))
+	config.Notify.CompletionRetries = 3
+	if retriesStr := os.Getenv("COMPLETION_WEBHOOK_RETRIES"); retriesStr != "" {
+		retries, err := strconv.Atoi(retriesStr)
+		if err != nil || retries < 0 {
+			return nil, fmt.Errorf("invalid COMPLETION_WEBHOOK_RETRIES %q: must be a non-negative number", retriesStr)
+		}
+		config.Notify.CompletionRetries = retries
+	}
+	config.Notify.NtfyURL = strings.TrimSpace(os.Getenv("NTFY_URL"))
+	config.Notify.NtfyToken = os.Getenv("NTFY_TOKEN")
+	config.Notify.PushoverToken = strings.TrimSpace(os.Getenv("PUSHOVER_TOKEN"))
+	config.Notify.PushoverUser = strings.TrimSpace(os.Getenv("PUSHOVER_USER"))
+	config.Notify.PushFailuresOnly = getEnvBool("PUSH_FAILURES_ONLY", false)
+	config.Notify.URLs = strings.FieldsFunc(os.Getenv("NOTIFY_URLS"), func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+
+	// Error tracking configuration
+	config.ErrorTracking.SentryDSN = strings.TrimSpace(os.Getenv("SENTRY_DSN"))
+	config.ErrorTracking.WebhookURL = strings.TrimSpace(os.Getenv("ERROR_WEBHOOK_URL"))
+	config.ErrorTracking.Environment = os.Getenv("SENTRY_ENVIRONMENT")
+
 	// Skip validation for now - commands will validate their specific requirements
 
 	return config, nil
@@ -278,8 +1432,10 @@ func (c *Config) Validate() error {
 	// First, check if at least one service is configured
 	sonarrConfigured := c.Sonarr.APIKey != ""
 	radarrConfigured := c.Radarr.APIKey != ""
+	readarrConfigured := c.Readarr.APIKey != ""
 
-	if !sonarrConfigured && !radarrConfigured {
+	// With tenants, the services may all be theirs
+	if !sonarrConfigured && !radarrConfigured && !readarrConfigured && len(c.Instances) == 0 && len(c.Tenants) == 0 {
 		return fmt.Errorf("at least one service must be configured (Sonarr or Radarr)")
 	}
 
@@ -299,13 +1455,102 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("RADARR_API_KEY is required when RADARR_URL is provided")
 	}
 
+	// Validate Readarr configuration
+	if readarrConfigured && c.Readarr.URL == "" {
+		return fmt.Errorf("Readarr URL is required when Readarr API key is provided")
+	}
+	if c.Readarr.URL != "" && c.Readarr.APIKey == "" {
+		return fmt.Errorf("READARR_API_KEY is required when READARR_URL is provided")
+	}
+
+	// Validate the extra instances; they have no default URL
+	for _, instance := range c.Instances {
+		if instance.URL == "" {
+			return fmt.Errorf("%s_URL is required when %s_API_KEY is provided", instance.envPrefix(), instance.envPrefix())
+		}
+		if instance.APIKey == "" {
+			return fmt.Errorf("%s_API_KEY is required when %s_URL is provided", instance.envPrefix(), instance.envPrefix())
+		}
+		if err := validateEndpointURL(instance.Label(), instance.URL); err != nil {
+			return err
+		}
+	}
+
+	// Validate Prowlarr configuration
+	if c.Prowlarr.URL != "" && c.Prowlarr.APIKey == "" {
+		return fmt.Errorf("PROWLARR_API_KEY is required when PROWLARR_URL is provided")
+	}
+
+	// Validate the service endpoints
+	for _, endpoint := range []struct{ name, url string }{
+		{"Sonarr", c.Sonarr.URL},
+		{"Radarr", c.Radarr.URL},
+		{"Readarr", c.Readarr.URL},
+		{"Prowlarr", c.Prowlarr.URL},
+	} {
+		if err := validateEndpointURL(endpoint.name, endpoint.url); err != nil {
+			return err
+		}
+	}
+
 	// Validate Plex configuration
-	plexConfigured := c.Plex.Token != ""
-	if plexConfigured && c.Plex.URL == "" {
-		return fmt.Errorf("Plex URL is required when Plex token is provided")
+	if err := c.Plex.Validate(); err != nil {
+		return err
 	}
-	if c.Plex.URL != "" && c.Plex.Token == "" {
-		return fmt.Errorf("PLEX_TOKEN is required when PLEX_URL is provided")
+
+	// Validate Kodi configuration
+	if err := c.Kodi.Validate(); err != nil {
+		return err
+	}
+
+	// Validate notification configuration
+	if c.Notify.WebhookURL != "" {
+		u, err := url.Parse(c.Notify.WebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid NOTIFY_WEBHOOK_URL %q: must be an http(s) URL", c.Notify.WebhookURL)
+		}
+	}
+	if c.Notify.CompletionURL != "" {
+		u, err := url.Parse(c.Notify.CompletionURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid COMPLETION_WEBHOOK_URL %q: must be an http(s) URL", c.Notify.CompletionURL)
+		}
+	}
+	if c.Notify.NtfyURL != "" {
+		u, err := url.Parse(c.Notify.NtfyURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || strings.Trim(u.Path, "/") == "" {
+			return fmt.Errorf("invalid NTFY_URL %q: must be the http(s) URL of a topic, such as https://ntfy.sh/<topic>", c.Notify.NtfyURL)
+		}
+	}
+	if (c.Notify.PushoverToken == "") != (c.Notify.PushoverUser == "") {
+		return fmt.Errorf("PUSHOVER_TOKEN and PUSHOVER_USER must be set together")
+	}
+	// The URLs hold tokens, so only their position is echoed back
+	for i, raw := range c.Notify.URLs {
+		if u, err := url.Parse(raw); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid NOTIFY_URLS entry %d: must be a URL such as ntfy://topic", i+1)
+		}
+	}
+
+	// Report sink URLs may hold passwords too
+	for i, raw := range c.ReportSinks {
+		if u, err := url.Parse(raw); err != nil || u.Scheme == "" {
+			return fmt.Errorf("invalid REPORT_SINKS entry %d: must be a URL such as file:///srv/reports or s3://bucket", i+1)
+		}
+	}
+
+	// Validate error tracking configuration; the DSN isn't echoed back as it holds a key
+	if c.ErrorTracking.SentryDSN != "" {
+		u, err := url.Parse(c.ErrorTracking.SentryDSN)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.User == nil || strings.Trim(u.Path, "/") == "" {
+			return fmt.Errorf("invalid SENTRY_DSN: expected https://<key>@<host>/<project>")
+		}
+	}
+	if c.ErrorTracking.WebhookURL != "" {
+		u, err := url.Parse(c.ErrorTracking.WebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid ERROR_WEBHOOK_URL %q: must be an http(s) URL", c.ErrorTracking.WebhookURL)
+		}
 	}
 
 	// Validate request timeout
@@ -321,6 +1566,83 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// isEndpointURL reports whether u is an http(s) URL with a host, an srv+http(s) URL with the
+// DNS SRV name to look up as host, or a unix:// URL with a socket path
+func isEndpointURL(u *url.URL) bool {
+	switch u.Scheme {
+	case "http", "https", "srv+http", "srv+https":
+		return u.Host != ""
+	case "unix":
+		return u.Host == "" && u.Path != ""
+	}
+	return false
+}
+
+// validateEndpointURL checks that a service URL, if set, is one the clients can reach
+func validateEndpointURL(name, rawURL string) error {
+	if rawURL == "" {
+		return nil
+	}
+	u, err := url.Parse(rawURL)
+	if err == nil && strings.Count(u.Host, ":") >= 2 && !strings.HasPrefix(u.Host, "[") {
+		err = errUnbracketedIPv6
+	}
+	if err != nil {
+		return fmt.Errorf("invalid %s URL %q: %w", name, rawURL, endpointParseError(rawURL, err))
+	}
+	if !isEndpointURL(u) {
+		return fmt.Errorf("invalid %s URL %q: must be an http(s) URL, srv+http(s):// with a DNS SRV name, or unix:///path/to.sock", name, rawURL)
+	}
+	return nil
+}
+
+// endpointParseError explains why a URL doesn't parse. IPv6 literals without brackets are the
+// usual culprit, as their colons read as a port.
+func endpointParseError(rawURL string, err error) error {
+	_, rest, ok := strings.Cut(rawURL, "://")
+	host, _, _ := strings.Cut(rest, "/")
+	if ok && !strings.Contains(host, "[") && strings.Count(host, ":") >= 2 {
+		return errUnbracketedIPv6
+	}
+	return err
+}
+
+// errUnbracketedIPv6 is returned for URLs with an IPv6 address outside brackets
+var errUnbracketedIPv6 = errors.New("IPv6 addresses must be in brackets, e.g. http://[::1]:8989")
+
+// normalizeEndpointURL trims a service URL and escapes the zone of a bracketed IPv6 literal,
+// e.g. http://[fe80::1%eth0]:8989, which URLs must write as %25eth0
+func normalizeEndpointURL(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+	open := strings.Index(rawURL, "[")
+	closing := strings.Index(rawURL, "]")
+	if open < 0 || closing < open {
+		return rawURL
+	}
+	literal := rawURL[open:closing]
+	zone := strings.Index(literal, "%")
+	if zone < 0 || strings.HasPrefix(literal[zone:], "%25") {
+		return rawURL
+	}
+	return rawURL[:open+zone] + "%25" + rawURL[open+zone+1:]
+}
+
+// loadEndpoint returns a service's URL and API key. CLI flags override the environment, and the
+// default URL is only used when an API key was given either way, so an unset service stays unconfigured.
+func loadEndpoint(urlEnv, keyEnv, defaultURL string, urlFlag, keyFlag *string) (string, string) {
+	endpoint, key := os.Getenv(urlEnv), os.Getenv(keyEnv)
+	if keyFlag != nil && *keyFlag != "" {
+		key = *keyFlag
+	}
+	if urlFlag != nil && *urlFlag != "" {
+		endpoint = *urlFlag
+	}
+	if endpoint == "" && key != "" {
+		endpoint = defaultURL
+	}
+	return endpoint, key
+}
+
 func getEnvOrDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -328,6 +1650,28 @@ func getEnvOrDefault(key, defaultValue string) string {
 	return defaultValue
 }
 
+// loadPathPatterns reads a path patterns file: one regex per line, with blank lines and lines
+// starting with # ignored. The patterns are checked so mistakes show up before a run.
+func loadPathPatterns(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read path patterns: %w", err)
+	}
+
+	var patterns []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	if _, err := models.NewPathParser(patterns); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return patterns, nil
+}
+
 // getEnvBool returns the environment variable as a boolean or a default value
 func getEnvBool(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
@@ -338,14 +1682,40 @@ func getEnvBool(key string, defaultValue bool) bool {
 	return defaultValue
 }
 
-// parseSeriesIDs parses a comma-separated string of series IDs into a slice of integers
-func parseSeriesIDs(seriesIDsStr string) ([]int, error) {
-	if seriesIDsStr == "" {
+// parseList splits a comma-separated string into trimmed, non-empty values
+func parseList(value string) []string {
+	var items []string
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			items = append(items, part)
+		}
+	}
+	return items
+}
+
+// checkTargets rejects combinations of targeting flags that would be ambiguous
+func checkTargets(c *Config) error {
+	targets := 0
+	for _, set := range []bool{len(c.SeriesIDs) > 0, len(c.EpisodeIDs) > 0, len(c.MovieIDs) > 0, c.TargetPath != "", c.IDsFile != ""} {
+		if set {
+			targets++
+		}
+	}
+	if targets > 1 {
+		return fmt.Errorf("--series-ids, --episode-ids, --movie-ids, --path and --ids-file cannot be combined")
+	}
+	return nil
+}
+
+// parseIDs parses a comma-separated string of numbers into a slice of integers. kind names
+// what they are in errors, e.g. "movie ID".
+func parseIDs(kind, value string) ([]int, error) {
+	if value == "" {
 		return nil, nil
 	}
 
-	parts := strings.Split(seriesIDsStr, ",")
-	seriesIDs := make([]int, 0, len(parts))
+	parts := strings.Split(value, ",")
+	ids := make([]int, 0, len(parts))
 
 	for _, part := range parts {
 		part = strings.TrimSpace(part)
@@ -355,11 +1725,11 @@ func parseSeriesIDs(seriesIDsStr string) ([]int, error) {
 
 		id, err := strconv.Atoi(part)
 		if err != nil {
-			return nil, fmt.Errorf("invalid series ID '%s': %w", part, err)
+			return nil, fmt.Errorf("invalid %s '%s': %w", kind, part, err)
 		}
 
-		seriesIDs = append(seriesIDs, id)
+		ids = append(ids, id)
 	}
 
-	return seriesIDs, nil
+	return ids, nil
 }
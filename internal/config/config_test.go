@@ -2,8 +2,15 @@ package config
 
 import (
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/hnipps/refresharr/internal/messages"
+	"github.com/hnipps/refresharr/pkg/models"
+	"github.com/joho/godotenv"
 )
 
 func TestLoadConfig_WithDefaults(t *testing.T) {
@@ -45,6 +52,15 @@ func TestLoadConfig_WithDefaults(t *testing.T) {
 	if config.DryRun {
 		t.Errorf("Expected DryRun 'false', got '%t'", config.DryRun)
 	}
+	if config.StateDir != "data" {
+		t.Errorf("Expected StateDir 'data', got '%s'", config.StateDir)
+	}
+	if config.ListenAddr != ":8080" {
+		t.Errorf("Expected ListenAddr ':8080', got '%s'", config.ListenAddr)
+	}
+	if config.WebUI {
+		t.Error("Expected the web UI to be disabled by default")
+	}
 }
 
 func TestLoadConfig_WithCustomValues(t *testing.T) {
@@ -58,6 +74,10 @@ func TestLoadConfig_WithCustomValues(t *testing.T) {
 	os.Setenv("CONCURRENT_LIMIT", "10")
 	os.Setenv("LOG_LEVEL", "DEBUG")
 	os.Setenv("DRY_RUN", "true")
+	os.Setenv("STATE_DIR", "/var/lib/refresharr")
+	os.Setenv("LISTEN_ADDR", "127.0.0.1:9090")
+	os.Setenv("SERVE_API_KEY", "serve-key")
+	os.Setenv("WEB_UI", "true")
 	defer clearTestEnv()
 
 	dryRunFlag := false
@@ -89,6 +109,18 @@ func TestLoadConfig_WithCustomValues(t *testing.T) {
 	if !config.DryRun {
 		t.Errorf("Expected DryRun 'true', got '%t'", config.DryRun)
 	}
+	if config.StateDir != "/var/lib/refresharr" {
+		t.Errorf("Expected StateDir '/var/lib/refresharr', got '%s'", config.StateDir)
+	}
+	if config.ListenAddr != "127.0.0.1:9090" {
+		t.Errorf("Expected ListenAddr '127.0.0.1:9090', got '%s'", config.ListenAddr)
+	}
+	if config.APIKey != "serve-key" {
+		t.Errorf("Expected APIKey 'serve-key', got '%s'", config.APIKey)
+	}
+	if !config.WebUI {
+		t.Error("Expected the web UI to be enabled")
+	}
 }
 
 func TestLoadConfig_ValidationErrors_DISABLED(t *testing.T) {
@@ -202,6 +234,55 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "readarr only",
+			config: &Config{
+				Readarr: ReadarrConfig{
+					URL:    "http://test:8787",
+					APIKey: "test-key",
+				},
+				RequestTimeout:  30 * time.Second,
+				ConcurrentLimit: 5,
+			},
+			wantErr: false,
+		},
+		{
+			name: "readarr missing API key",
+			config: &Config{
+				Sonarr: SonarrConfig{
+					URL:    "http://test:8989",
+					APIKey: "test-key",
+				},
+				Readarr: ReadarrConfig{
+					URL: "http://test:8787",
+				},
+				RequestTimeout:  30 * time.Second,
+				ConcurrentLimit: 5,
+			},
+			wantErr: true,
+		},
+		{
+			name: "extra instance only",
+			config: &Config{
+				Instances: []InstanceConfig{
+					{Service: "radarr", Name: "4k", URL: "http://test:7879", APIKey: "test-key"},
+				},
+				RequestTimeout:  30 * time.Second,
+				ConcurrentLimit: 5,
+			},
+			wantErr: false,
+		},
+		{
+			name: "extra instance missing URL",
+			config: &Config{
+				Instances: []InstanceConfig{
+					{Service: "radarr", Name: "4k", APIKey: "test-key"},
+				},
+				RequestTimeout:  30 * time.Second,
+				ConcurrentLimit: 5,
+			},
+			wantErr: true,
+		},
 		{
 			name: "zero timeout",
 			config: &Config{
@@ -238,6 +319,49 @@ func TestConfig_Validate(t *testing.T) {
 	}
 }
 
+func TestValidateEndpointURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr string // Empty when the URL is valid
+	}{
+		{url: "http://sonarr:8989"},
+		{url: "http://[::1]:8989"},
+		{url: "http://[fe80::1%25eth0]:8989"},
+		{url: "srv+http://_sonarr._tcp.media.svc.cluster.local"},
+		{url: "unix:///run/sonarr.sock"},
+		{url: "http://::1:8989", wantErr: "must be in brackets"},
+		{url: "ftp://sonarr", wantErr: "must be an http(s) URL"},
+		{url: "srv+http://", wantErr: "must be an http(s) URL"},
+	}
+
+	for _, tt := range tests {
+		err := validateEndpointURL("Sonarr", tt.url)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("validateEndpointURL(%q) error = %v", tt.url, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("validateEndpointURL(%q) error = %v, want error containing %q", tt.url, err, tt.wantErr)
+		}
+	}
+}
+
+func TestNormalizeEndpointURL(t *testing.T) {
+	tests := map[string]string{
+		" http://sonarr:8989 ":         "http://sonarr:8989",
+		"http://[::1]:8989":            "http://[::1]:8989",
+		"http://[fe80::1%eth0]:8989":   "http://[fe80::1%25eth0]:8989",
+		"http://[fe80::1%25eth0]:8989": "http://[fe80::1%25eth0]:8989",
+	}
+	for input, want := range tests {
+		if got := normalizeEndpointURL(input); got != want {
+			t.Errorf("normalizeEndpointURL(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
 func TestGetEnvOrDefault(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -419,6 +543,1380 @@ func TestLoadConfig_PlexNotConfigured(t *testing.T) {
 	}
 }
 
+func TestLoadConfig_PlexTimeoutAndLibraries(t *testing.T) {
+	clearTestEnv()
+
+	os.Setenv("PLEX_URL", "http://plex.example.com:32400/")
+	os.Setenv("PLEX_TOKEN", "test-token")
+	os.Setenv("PLEX_TIMEOUT", "90s")
+	os.Setenv("PLEX_LIBRARIES", "Movies, 4K Movies,,")
+	defer clearTestEnv()
+
+	dryRunFlag := false
+	noReportFlag := false
+	config, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+
+	if config.Plex.URL != "http://plex.example.com:32400" {
+		t.Errorf("Expected normalized Plex URL without trailing slash, got '%s'", config.Plex.URL)
+	}
+	if config.Plex.Timeout != 90*time.Second {
+		t.Errorf("Expected Plex timeout '90s', got '%v'", config.Plex.Timeout)
+	}
+	if len(config.Plex.Libraries) != 2 || config.Plex.Libraries[0] != "Movies" || config.Plex.Libraries[1] != "4K Movies" {
+		t.Errorf("Expected Plex libraries [Movies 4K Movies], got %v", config.Plex.Libraries)
+	}
+}
+
+func TestLoadConfig_InvalidPlexTimeout(t *testing.T) {
+	clearTestEnv()
+
+	os.Setenv("PLEX_TIMEOUT", "soon")
+	defer clearTestEnv()
+
+	dryRunFlag := false
+	noReportFlag := false
+	if _, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil); err == nil {
+		t.Error("Expected error for invalid PLEX_TIMEOUT")
+	}
+}
+
+func TestPlexConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  PlexConfig
+		wantErr bool
+	}{
+		{"not configured", PlexConfig{}, false},
+		{"valid", PlexConfig{URL: "https://plex.example.com", Token: "t", Timeout: time.Second, Libraries: []string{"Movies"}}, false},
+		{"missing scheme", PlexConfig{URL: "plex.example.com:32400", Token: "t"}, true},
+		{"unsupported scheme", PlexConfig{URL: "ftp://plex.example.com", Token: "t"}, true},
+		{"unix socket", PlexConfig{URL: "unix:///run/plex.sock", Token: "t"}, false},
+		{"unix socket without path", PlexConfig{URL: "unix://plex", Token: "t"}, true},
+		{"negative timeout", PlexConfig{URL: "http://plex.example.com", Token: "t", Timeout: -time.Second}, true},
+		{"empty library name", PlexConfig{URL: "http://plex.example.com", Token: "t", Libraries: []string{" "}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("PlexConfig.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_KodiConfig(t *testing.T) {
+	clearTestEnv()
+
+	os.Setenv("KODI_URL", " http://kodi.example.com:8080/ ")
+	os.Setenv("KODI_USERNAME", "kodi")
+	os.Setenv("KODI_PASSWORD", "secret")
+	os.Setenv("KODI_TIMEOUT", "15s")
+	defer clearTestEnv()
+
+	dryRunFlag := false
+	noReportFlag := false
+	config, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+
+	if !config.Kodi.Configured() {
+		t.Error("Expected Kodi to be configured")
+	}
+	if config.Kodi.URL != "http://kodi.example.com:8080" {
+		t.Errorf("Expected normalized Kodi URL, got '%s'", config.Kodi.URL)
+	}
+	if config.Kodi.Username != "kodi" || config.Kodi.Password != "secret" {
+		t.Errorf("Expected Kodi credentials kodi/secret, got %s/%s", config.Kodi.Username, config.Kodi.Password)
+	}
+	if config.Kodi.Timeout != 15*time.Second {
+		t.Errorf("Expected Kodi timeout '15s', got '%v'", config.Kodi.Timeout)
+	}
+
+	os.Setenv("KODI_TIMEOUT", "later")
+	if _, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil); err == nil {
+		t.Error("Expected error for invalid KODI_TIMEOUT")
+	}
+}
+
+func TestLoadConfig_ProwlarrConfig(t *testing.T) {
+	clearTestEnv()
+
+	os.Setenv("PROWLARR_API_KEY", "prowlarr-key")
+	defer clearTestEnv()
+
+	dryRunFlag := false
+	noReportFlag := false
+	config, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+
+	if !config.Prowlarr.Configured() {
+		t.Error("Expected Prowlarr to be configured")
+	}
+	if config.Prowlarr.URL != "http://127.0.0.1:9696" {
+		t.Errorf("Expected default Prowlarr URL, got '%s'", config.Prowlarr.URL)
+	}
+
+	clearTestEnv()
+	os.Setenv("PROWLARR_URL", "http://prowlarr.local:9696")
+	config, err = LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	config.Sonarr.APIKey = "key"
+	config.Sonarr.URL = "http://sonarr.local:8989"
+	if err := config.Validate(); err == nil {
+		t.Error("Expected validation error for Prowlarr URL without API key")
+	}
+}
+
+func TestLoadConfig_VerifyAlerting(t *testing.T) {
+	clearTestEnv()
+
+	os.Setenv("VERIFY_AT", "03:30")
+	os.Setenv("VERIFY_ALERT_THRESHOLD", "25")
+	os.Setenv("NOTIFY_WEBHOOK_URL", "https://hooks.example.com/refresharr")
+	defer clearTestEnv()
+
+	dryRunFlag := false
+	noReportFlag := false
+	config, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+
+	if config.VerifyAt != "03:30" {
+		t.Errorf("Expected verify time '03:30', got '%s'", config.VerifyAt)
+	}
+	if config.AlertThreshold != 25 {
+		t.Errorf("Expected alert threshold 25, got %d", config.AlertThreshold)
+	}
+	if !config.Notify.Configured() {
+		t.Error("Expected notifications to be configured")
+	}
+
+	os.Setenv("VERIFY_AT", "3am")
+	if _, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil); err == nil {
+		t.Error("Expected error for invalid VERIFY_AT")
+	}
+
+	os.Setenv("VERIFY_AT", "03:30")
+	os.Setenv("VERIFY_ALERT_THRESHOLD", "-1")
+	if _, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil); err == nil {
+		t.Error("Expected error for negative VERIFY_ALERT_THRESHOLD")
+	}
+}
+
+func TestLoadConfig_CompletionWebhook(t *testing.T) {
+	clearTestEnv()
+	defer clearTestEnv()
+
+	dryRunFlag := false
+	noReportFlag := false
+	config, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if config.Notify.CompletionURL != "" || config.Notify.CompletionRetries != 3 {
+		t.Errorf("Expected no completion webhook with 3 retries by default, got %+v", config.Notify)
+	}
+
+	os.Setenv("COMPLETION_WEBHOOK_URL", "http://homeassistant.local:8123/api/webhook/refresharr")
+	os.Setenv("COMPLETION_WEBHOOK_RETRIES", "0")
+	config, err = LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if config.Notify.CompletionURL != "http://homeassistant.local:8123/api/webhook/refresharr" || config.Notify.CompletionRetries != 0 {
+		t.Errorf("Unexpected completion webhook settings: %+v", config.Notify)
+	}
+
+	os.Setenv("COMPLETION_WEBHOOK_RETRIES", "-1")
+	if _, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil); err == nil {
+		t.Error("Expected error for negative COMPLETION_WEBHOOK_RETRIES")
+	}
+
+	config.Sonarr.URL = "http://sonarr.local:8989"
+	config.Sonarr.APIKey = "key"
+	if err := config.Validate(); err != nil {
+		t.Errorf("Expected a valid configuration, got %v", err)
+	}
+
+	config.Notify.CompletionURL = "homeassistant.local/webhook"
+	if err := config.Validate(); err == nil {
+		t.Error("Expected validation error for a COMPLETION_WEBHOOK_URL without a scheme")
+	}
+}
+
+func TestLoadConfig_PushNotifications(t *testing.T) {
+	clearTestEnv()
+	defer clearTestEnv()
+
+	os.Setenv("SONARR_URL", "http://sonarr.local:8989")
+	os.Setenv("SONARR_API_KEY", "key")
+	os.Setenv("NTFY_URL", "https://ntfy.sh/refresharr-alerts")
+	os.Setenv("PUSHOVER_TOKEN", "app-token")
+	os.Setenv("PUSHOVER_USER", "user-key")
+	os.Setenv("PUSH_FAILURES_ONLY", "true")
+
+	dryRunFlag := false
+	noReportFlag := false
+	config, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if config.Notify.NtfyURL != "https://ntfy.sh/refresharr-alerts" || config.Notify.PushoverToken != "app-token" ||
+		config.Notify.PushoverUser != "user-key" || !config.Notify.PushFailuresOnly {
+		t.Errorf("Unexpected push settings: %+v", config.Notify)
+	}
+	if !config.Notify.Configured() {
+		t.Error("Expected push notifications to count as configured notifications")
+	}
+	if err := config.Validate(); err != nil {
+		t.Errorf("Expected a valid configuration, got %v", err)
+	}
+
+	config.Notify.NtfyURL = "https://ntfy.sh/"
+	if err := config.Validate(); err == nil {
+		t.Error("Expected validation error for an NTFY_URL without a topic")
+	}
+
+	config.Notify.NtfyURL = ""
+	config.Notify.PushoverUser = ""
+	if err := config.Validate(); err == nil {
+		t.Error("Expected validation error for PUSHOVER_TOKEN without PUSHOVER_USER")
+	}
+}
+
+func TestLoadConfig_NotifyURLs(t *testing.T) {
+	clearTestEnv()
+	defer clearTestEnv()
+
+	os.Setenv("SONARR_URL", "http://sonarr.local:8989")
+	os.Setenv("SONARR_API_KEY", "key")
+	os.Setenv("NOTIFY_URLS", "ntfy://refresharr-alerts, discord://1234/token\ngotifys://push.example.com/AbCdEf")
+
+	dryRunFlag := false
+	noReportFlag := false
+	config, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if len(config.Notify.URLs) != 3 || config.Notify.URLs[1] != "discord://1234/token" {
+		t.Errorf("Expected 3 notification URLs split on commas and whitespace, got %q", config.Notify.URLs)
+	}
+	if !config.Notify.Configured() {
+		t.Error("Expected notification URLs to count as configured notifications")
+	}
+	if err := config.Validate(); err != nil {
+		t.Errorf("Expected a valid configuration, got %v", err)
+	}
+
+	config.Notify.URLs = append(config.Notify.URLs, "secret-token")
+	err = config.Validate()
+	if err == nil || strings.Contains(err.Error(), "secret-token") {
+		t.Errorf("Expected a validation error that doesn't echo the URL, got %v", err)
+	}
+}
+
+func TestLoadConfig_ErrorTracking(t *testing.T) {
+	clearTestEnv()
+
+	os.Setenv("SENTRY_DSN", "https://abc123@o1.ingest.sentry.io/42")
+	os.Setenv("SENTRY_ENVIRONMENT", "production")
+	defer clearTestEnv()
+
+	dryRunFlag := false
+	noReportFlag := false
+	config, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if !config.ErrorTracking.Configured() {
+		t.Error("Expected error tracking to be configured")
+	}
+	if config.ErrorTracking.Environment != "production" {
+		t.Errorf("Expected environment 'production', got '%s'", config.ErrorTracking.Environment)
+	}
+
+	config.Sonarr.URL = "http://sonarr.local:8989"
+	config.Sonarr.APIKey = "key"
+	if err := config.Validate(); err != nil {
+		t.Errorf("Expected a valid configuration, got %v", err)
+	}
+
+	config.ErrorTracking.SentryDSN = "https://o1.ingest.sentry.io/42"
+	if err := config.Validate(); err == nil {
+		t.Error("Expected validation error for a DSN without a key")
+	}
+
+	config.ErrorTracking.SentryDSN = ""
+	config.ErrorTracking.WebhookURL = "ftp://errors.example.com"
+	if err := config.Validate(); err == nil {
+		t.Error("Expected validation error for a non-http ERROR_WEBHOOK_URL")
+	}
+}
+
+func TestLoadConfig_APIBudget(t *testing.T) {
+	clearTestEnv()
+
+	os.Setenv("API_BUDGET", "500")
+	defer clearTestEnv()
+
+	dryRunFlag := false
+	noReportFlag := false
+	config, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if config.APIBudget != 500 {
+		t.Errorf("Expected API budget 500, got %d", config.APIBudget)
+	}
+
+	os.Setenv("API_BUDGET", "lots")
+	if _, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil); err == nil {
+		t.Error("Expected error for invalid API_BUDGET")
+	}
+}
+
+func TestLoadConfig_APIClient(t *testing.T) {
+	clearTestEnv()
+	defer clearTestEnv()
+
+	dryRunFlag := false
+	noReportFlag := false
+	config, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if config.APIClient != APIClientBuiltin {
+		t.Errorf("Expected API client %q by default, got %q", APIClientBuiltin, config.APIClient)
+	}
+
+	os.Setenv("API_CLIENT", "Starr")
+	config, err = LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if config.APIClient != APIClientStarr {
+		t.Errorf("Expected API client %q, got %q", APIClientStarr, config.APIClient)
+	}
+
+	os.Setenv("API_CLIENT", "curl")
+	if _, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil); err == nil {
+		t.Error("Expected error for invalid API_CLIENT")
+	}
+}
+
+func TestLoadConfig_TraceHTTP(t *testing.T) {
+	clearTestEnv()
+	defer clearTestEnv()
+
+	dryRunFlag := false
+	noReportFlag := false
+	config, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if config.TraceHTTP || config.TraceHTTPBodies {
+		t.Error("Expected HTTP tracing to be off by default")
+	}
+
+	os.Setenv("TRACE_HTTP_BODIES", "true")
+	config, err = LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if !config.TraceHTTP || !config.TraceHTTPBodies {
+		t.Error("Expected TRACE_HTTP_BODIES to enable tracing with bodies")
+	}
+}
+
+func TestLoadConfig_MediaCacheTTL(t *testing.T) {
+	clearTestEnv()
+	defer clearTestEnv()
+
+	dryRunFlag := false
+	noReportFlag := false
+	config, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if config.MediaCacheTTL != time.Hour {
+		t.Errorf("Expected default media cache TTL 1h, got %v", config.MediaCacheTTL)
+	}
+
+	os.Setenv("MEDIA_CACHE_TTL", "0")
+	config, err = LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if config.MediaCacheTTL != 0 {
+		t.Errorf("Expected MEDIA_CACHE_TTL=0 to disable the cache, got %v", config.MediaCacheTTL)
+	}
+
+	os.Setenv("MEDIA_CACHE_TTL", "-1h")
+	if _, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil); err == nil {
+		t.Error("Expected an error for a negative MEDIA_CACHE_TTL")
+	}
+}
+
+func TestLoadConfig_ImportCooldown(t *testing.T) {
+	clearTestEnv()
+	defer clearTestEnv()
+
+	dryRunFlag := false
+	noReportFlag := false
+	config, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if config.ImportCooldown != 0 {
+		t.Errorf("Expected no import cooldown by default, got %v", config.ImportCooldown)
+	}
+
+	os.Setenv("IMPORT_COOLDOWN", "6h")
+	config, err = LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if config.ImportCooldown != 6*time.Hour {
+		t.Errorf("Expected import cooldown 6h, got %v", config.ImportCooldown)
+	}
+
+	os.Setenv("IMPORT_COOLDOWN", "soon")
+	if _, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil); err == nil {
+		t.Error("Expected an error for an invalid IMPORT_COOLDOWN")
+	}
+}
+
+func TestLoadConfig_ServiceDryRun(t *testing.T) {
+	clearTestEnv()
+	defer clearTestEnv()
+
+	os.Setenv("DRY_RUN", "true")
+	os.Setenv("SONARR_DRY_RUN", "false")
+	os.Setenv("RADARR_4K_URL", "http://radarr-4k:7878")
+	os.Setenv("RADARR_4K_API_KEY", "key")
+	os.Setenv("RADARR_4K_DRY_RUN", "false")
+
+	dryRunFlag := false
+	noReportFlag := false
+	config, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if config.DryRunFor("sonarr") || config.DryRunFor("radarr-4k") {
+		t.Error("Expected SONARR_DRY_RUN and RADARR_4K_DRY_RUN to override DRY_RUN")
+	}
+	if !config.DryRunFor("radarr") {
+		t.Error("Expected Radarr to follow DRY_RUN")
+	}
+
+	config.Verify = true
+	if !config.DryRunFor("sonarr") {
+		t.Error("Expected verify runs to be dry runs whatever the override")
+	}
+
+	// --dry-run keeps every service in dry-run
+	dryRunFlag = true
+	config, err = LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if !config.DryRunFor("sonarr") || !config.DryRunFor("radarr-4k") {
+		t.Error("Expected --dry-run to keep every service in dry-run")
+	}
+
+	dryRunFlag = false
+	os.Setenv("RADARR_DRY_RUN", "maybe")
+	if _, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil); err == nil {
+		t.Error("Expected an error for an invalid RADARR_DRY_RUN")
+	}
+}
+
+func TestIgnoredSettings(t *testing.T) {
+	clearTestEnv()
+	defer clearTestEnv()
+
+	os.Setenv("REQUEST_TIMEOUT", "45s")
+	os.Setenv("DRY_RUN", "true")
+	config, err := LoadEnvConfig()
+	if err != nil {
+		t.Fatalf("LoadEnvConfig() failed: %v", err)
+	}
+	if problems := config.Ignored; len(problems) != 0 {
+		t.Errorf("Expected no problems, got %v", problems)
+	}
+
+	os.Setenv("REQUEST_TIMEOUT", "5x")
+	os.Setenv("CONCURRENT_LIMIT", "many")
+	os.Setenv("DRY_RUN", "yes")
+	config, err = LoadEnvConfig()
+	if err != nil {
+		t.Fatalf("LoadEnvConfig() failed: %v", err)
+	}
+	problems := config.Ignored
+	if len(problems) != 3 || !strings.Contains(problems[0], "DRY_RUN") || config.RequestTimeout != 30*time.Second {
+		t.Errorf("Expected REQUEST_TIMEOUT, CONCURRENT_LIMIT and DRY_RUN to be reported, got %v", problems)
+	}
+}
+
+func TestExample(t *testing.T) {
+	env, err := godotenv.Unmarshal(Example)
+	if err != nil {
+		t.Fatalf("Failed to parse the example configuration: %v", err)
+	}
+	if _, ok := env["SONARR_API_KEY"]; !ok {
+		t.Error("Expected the example to set SONARR_API_KEY")
+	}
+	if _, ok := env["DRY_RUN"]; ok {
+		t.Error("Expected the example to leave the defaults commented out")
+	}
+}
+
+func TestLoadConfig_PathPatterns(t *testing.T) {
+	clearTestEnv()
+	defer clearTestEnv()
+
+	dir := t.TempDir()
+	patternsFile := filepath.Join(dir, "patterns.txt")
+	content := "# Plex-style tags\n\\{tmdb-(?P<tmdb>\\d+)\\}\n\n\\[imdbid-(?P<imdb>tt\\d+)\\]\n"
+	if err := os.WriteFile(patternsFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("PATH_PATTERNS_FILE", patternsFile)
+
+	dryRunFlag := false
+	noReportFlag := false
+	config, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if len(config.PathPatterns) != 2 || config.PathPatterns[0] != `\{tmdb-(?P<tmdb>\d+)\}` {
+		t.Errorf("Expected the 2 patterns without comments or blank lines, got %q", config.PathPatterns)
+	}
+
+	badFile := filepath.Join(dir, "bad.txt")
+	if err := os.WriteFile(badFile, []byte(`\[tmdb-(\d+)\]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("PATH_PATTERNS_FILE", badFile)
+	if _, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil); err == nil {
+		t.Error("Expected an error for a pattern without a named group")
+	}
+
+	os.Setenv("PATH_PATTERNS_FILE", filepath.Join(dir, "missing.txt"))
+	if _, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil); err == nil {
+		t.Error("Expected an error for a missing patterns file")
+	}
+}
+
+func TestLoadConfig_Tenants(t *testing.T) {
+	clearTestEnv()
+	defer clearTestEnv()
+
+	dir := t.TempDir()
+	tenantsFile := filepath.Join(dir, "tenants.json")
+	content := `[
+  {"name": "alice", "apiKey": "alice-key", "sonarr": {"url": " http://127.0.0.1:8989", "apiKey": "a"}},
+  {"name": "bob", "apiKey": "bob-key", "radarr": {"url": "http://127.0.0.1:7879", "apiKey": "b"},
+   "instances": [{"service": "Radarr", "name": "4K", "url": "http://127.0.0.1:7880", "apiKey": "c"}],
+   "reportDir": "/home/bob/reports", "reportSinks": ["s3://bob-bucket/reports"], "notifyUrls": ["ntfy://bob-alerts"],
+   "pathMappings": ["/mnt/bob/=/home/bob/media"], "checksumManifest": "/home/bob/sha256sums.txt"}
+]`
+	if err := os.WriteFile(tenantsFile, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("TENANTS_FILE", tenantsFile)
+	os.Setenv("STATE_DIR", "/var/lib/refresharr")
+	// The deployment's own sinks, notifiers and error tracking
+	os.Setenv("REPORT_SINKS", "s3://operator-bucket/reports")
+	os.Setenv("NOTIFY_WEBHOOK_URL", "https://hooks.example.com/operator")
+	os.Setenv("NOTIFY_URLS", "ntfy://operator-alerts")
+	os.Setenv("SENTRY_DSN", "https://key@o0.ingest.sentry.io/1")
+	// The deployment's own folders, files and media servers
+	os.Setenv("OUTSIDE_ROOT_POLICY", "rewrite")
+	os.Setenv("PATH_MAPPINGS", "/mnt/old=/data")
+	os.Setenv("CHECKSUM_MANIFEST", "/srv/sha256sums.txt")
+	os.Setenv("PLEX_TOKEN", "operator-plex-token")
+	os.Setenv("KODI_URL", "http://127.0.0.1:8080")
+
+	dryRunFlag := false
+	noReportFlag := false
+	config, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if len(config.Tenants) != 2 {
+		t.Fatalf("Expected 2 tenants, got %d", len(config.Tenants))
+	}
+	// Tenants alone are enough services
+	if err := config.Validate(); err != nil {
+		t.Errorf("Validate() with tenants only failed: %v", err)
+	}
+	config.OnlyFrom = "/srv/reviewed-actions.json"
+
+	alice := config.ForTenant(config.Tenants[0])
+	if alice.Sonarr.URL != "http://127.0.0.1:8989" || alice.Sonarr.APIKey != "a" || alice.Radarr.APIKey != "" {
+		t.Errorf("Unexpected services for alice: %+v %+v", alice.Sonarr, alice.Radarr)
+	}
+	if alice.StateDir != filepath.Join("/var/lib/refresharr", "tenants", "alice") || alice.ReportDir != filepath.Join("reports", "alice") {
+		t.Errorf("Unexpected directories for alice: %s, %s", alice.StateDir, alice.ReportDir)
+	}
+	if alice.Tenants != nil || config.StateDir != "/var/lib/refresharr" {
+		t.Error("ForTenant should copy the configuration without its tenants")
+	}
+	// Tenants' reports and failures never reach the deployment's sinks and notifiers
+	if len(alice.ReportSinks) != 0 || alice.Notify.Configured() || alice.ErrorTracking.Configured() {
+		t.Errorf("Alice shares the deployment's sinks %v, notifiers %+v or error tracking %+v", alice.ReportSinks, alice.Notify, alice.ErrorTracking)
+	}
+	// Nor do the deployment's folders, files and media servers apply to them
+	if len(alice.PathMappings) != 0 || alice.ChecksumManifest != "" || alice.OnlyFrom != "" || alice.Plex.Configured() || alice.Kodi.Configured() {
+		t.Errorf("Alice shares the deployment's path mappings %v, checksum manifest %q, actions file %q, Plex %+v or Kodi %+v",
+			alice.PathMappings, alice.ChecksumManifest, alice.OnlyFrom, alice.Plex, alice.Kodi)
+	}
+	// Without mappings of their own, records outside the root folders are only reported
+	if alice.OutsideRootPolicy != OutsideRootSkip {
+		t.Errorf("Expected alice's outside root policy to fall back to %s, got %s", OutsideRootSkip, alice.OutsideRootPolicy)
+	}
+
+	bob := config.ForTenant(config.Tenants[1])
+	if len(bob.Instances) != 1 || bob.Instances[0].Label() != "radarr-4k" || bob.ReportDir != "/home/bob/reports" {
+		t.Errorf("Unexpected configuration for bob: %+v, report dir %s", bob.Instances, bob.ReportDir)
+	}
+	if len(bob.ReportSinks) != 1 || bob.ReportSinks[0] != "s3://bob-bucket/reports" {
+		t.Errorf("Expected bob's own report sink, got %v", bob.ReportSinks)
+	}
+	if len(bob.Notify.URLs) != 1 || bob.Notify.URLs[0] != "ntfy://bob-alerts" || bob.Notify.WebhookURL != "" || bob.ErrorTracking.Configured() {
+		t.Errorf("Expected only bob's own notifier, got %+v, error tracking %+v", bob.Notify, bob.ErrorTracking)
+	}
+	wantMappings := []models.PathMapping{{From: "/mnt/bob", To: "/home/bob/media"}}
+	if !reflect.DeepEqual(bob.PathMappings, wantMappings) || bob.OutsideRootPolicy != OutsideRootRewrite || bob.ChecksumManifest != "/home/bob/sha256sums.txt" {
+		t.Errorf("Expected bob's own path mappings and checksum manifest, got %v (%s), %q", bob.PathMappings, bob.OutsideRootPolicy, bob.ChecksumManifest)
+	}
+	if len(config.ReportSinks) != 1 || !config.Notify.Configured() || !config.ErrorTracking.Configured() || len(config.PathMappings) != 1 || !config.Plex.Configured() {
+		t.Error("ForTenant should leave the deployment's own settings alone")
+	}
+
+	for name, bad := range map[string]string{
+		"duplicate key":   `[{"name": "a", "apiKey": "k", "sonarr": {"url": "http://x:1", "apiKey": "a"}}, {"name": "b", "apiKey": "k", "sonarr": {"url": "http://x:2", "apiKey": "b"}}]`,
+		"no services":     `[{"name": "a", "apiKey": "k"}]`,
+		"no url":          `[{"name": "a", "apiKey": "k", "sonarr": {"apiKey": "a"}}]`,
+		"bad name":        `[{"name": "../a", "apiKey": "k", "sonarr": {"url": "http://x:1", "apiKey": "a"}}]`,
+		"unknown field":   `[{"name": "a", "apiKey": "k", "sonar": {"url": "http://x:1", "apiKey": "a"}}]`,
+		"unknown service": `[{"name": "a", "apiKey": "k", "instances": [{"service": "lidarr", "name": "x", "url": "http://x:1", "apiKey": "a"}]}]`,
+		"bad notify url":  `[{"name": "a", "apiKey": "k", "sonarr": {"url": "http://x:1", "apiKey": "a"}, "notifyUrls": ["topic"]}]`,
+		"bad mapping":     `[{"name": "a", "apiKey": "k", "sonarr": {"url": "http://x:1", "apiKey": "a"}, "pathMappings": ["/mnt/old"]}]`,
+		"no tenants":      `[]`,
+	} {
+		if err := os.WriteFile(tenantsFile, []byte(bad), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil); err == nil {
+			t.Errorf("Expected an error for a tenants file with %s", name)
+		}
+	}
+}
+
+func TestLoadConfig_MaintenanceWindows(t *testing.T) {
+	clearTestEnv()
+	defer clearTestEnv()
+
+	dryRunFlag := false
+	noReportFlag := false
+	config, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if config.MaintenanceWindows != nil {
+		t.Errorf("Expected no maintenance windows by default, got %v", config.MaintenanceWindows)
+	}
+
+	os.Setenv("MAINTENANCE_WINDOWS", "sat,sun 02:00-06:00; mon-fri 03:00-05:00")
+	config, err = LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if len(config.MaintenanceWindows) != 2 || config.MaintenanceWindows[1].Spec != "mon-fri 03:00-05:00" {
+		t.Errorf("Unexpected maintenance windows: %+v", config.MaintenanceWindows)
+	}
+
+	os.Setenv("MAINTENANCE_WINDOWS", "weekends 02:00-06:00")
+	if _, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil); err == nil {
+		t.Error("Expected an error for an unknown day")
+	}
+}
+
+func TestLoadConfig_ReportSinks(t *testing.T) {
+	clearTestEnv()
+	defer clearTestEnv()
+
+	os.Setenv("SONARR_API_KEY", "test-key")
+	os.Setenv("REPORT_SINKS", "file://reports, s3://bucket/prefix?format=csv\nstdout://")
+	dryRunFlag := false
+	noReportFlag := false
+	config, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	want := []string{"file://reports", "s3://bucket/prefix?format=csv", "stdout://"}
+	if !reflect.DeepEqual(config.ReportSinks, want) {
+		t.Errorf("ReportSinks = %v, want %v", config.ReportSinks, want)
+	}
+	if err := config.Validate(); err != nil {
+		t.Errorf("Validate() failed: %v", err)
+	}
+
+	config.ReportSinks = []string{"file://reports", "reports"}
+	if err := config.Validate(); err == nil || !strings.Contains(err.Error(), "REPORT_SINKS entry 2") {
+		t.Errorf("Expected an error naming the second sink, got %v", err)
+	}
+}
+
+func TestLoadConfig_ReportArchiving(t *testing.T) {
+	clearTestEnv()
+	defer clearTestEnv()
+
+	os.Setenv("REPORT_COMPRESS_AFTER_DAYS", "14")
+	os.Setenv("REPORT_ARCHIVE_MONTHLY", "true")
+	dryRunFlag := false
+	noReportFlag := false
+	config, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if config.ReportCompressAfterDays != 14 || !config.ReportArchiveMonthly {
+		t.Errorf("Unexpected archiving settings: %d days, monthly %v", config.ReportCompressAfterDays, config.ReportArchiveMonthly)
+	}
+
+	os.Setenv("REPORT_COMPRESS_AFTER_DAYS", "-1")
+	if _, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil); err == nil {
+		t.Error("Expected an error for negative days")
+	}
+}
+
+func TestLoadConfig_HistoryRetention(t *testing.T) {
+	clearTestEnv()
+	defer clearTestEnv()
+
+	dryRunFlag := false
+	noReportFlag := false
+	config, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if config.HistoryRetentionDays != 90 {
+		t.Errorf("Expected 90 days of history by default, got %d", config.HistoryRetentionDays)
+	}
+
+	os.Setenv("HISTORY_RETENTION_DAYS", "0")
+	config, err = LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if config.HistoryRetentionDays != 0 {
+		t.Errorf("Expected the whole history to be kept, got %d days", config.HistoryRetentionDays)
+	}
+
+	os.Setenv("HISTORY_RETENTION_DAYS", "-1")
+	if _, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil); err == nil {
+		t.Error("Expected an error for negative days")
+	}
+}
+
+func TestLoadConfig_TitleMatchConfidence(t *testing.T) {
+	clearTestEnv()
+	defer clearTestEnv()
+
+	dryRunFlag := false
+	noReportFlag := false
+	config, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if config.TitleMatchConfidence != 0.9 {
+		t.Errorf("Expected the default confidence 0.9, got %v", config.TitleMatchConfidence)
+	}
+
+	os.Setenv("TITLE_MATCH_CONFIDENCE", "0.75")
+	config, err = LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if config.TitleMatchConfidence != 0.75 {
+		t.Errorf("Expected TITLE_MATCH_CONFIDENCE=0.75, got %v", config.TitleMatchConfidence)
+	}
+
+	for _, invalid := range []string{"0", "1.5", "high"} {
+		os.Setenv("TITLE_MATCH_CONFIDENCE", invalid)
+		if _, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil); err == nil {
+			t.Errorf("Expected an error for TITLE_MATCH_CONFIDENCE=%s", invalid)
+		}
+	}
+}
+
+func TestLoadConfig_Messages(t *testing.T) {
+	clearTestEnv()
+	defer clearTestEnv()
+
+	dir := t.TempDir()
+	messagesFile := filepath.Join(dir, "de.json")
+	if err := os.WriteFile(messagesFile, []byte(`{"report.totalMissing": "Fehlende Dateien: %d"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("MESSAGES_FILE", messagesFile)
+
+	dryRunFlag := false
+	noReportFlag := false
+	config, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if got := config.Messages.Sprintf(messages.ReportTotalMissing, 3); got != "Fehlende Dateien: 3" {
+		t.Errorf("Expected the translated message, got %q", got)
+	}
+
+	os.Setenv("MESSAGES_FILE", filepath.Join(dir, "missing.json"))
+	if _, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil); err == nil {
+		t.Error("Expected an error for a missing messages file")
+	}
+}
+
+func TestLoadConfig_JobMode(t *testing.T) {
+	clearTestEnv()
+	defer clearTestEnv()
+
+	dryRunFlag := false
+	noReportFlag := false
+	config, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if config.JobMode || config.SummaryFile != "" {
+		t.Errorf("Expected job mode to be off by default, got %t with summary file %q", config.JobMode, config.SummaryFile)
+	}
+
+	os.Setenv("JOB_MODE", "true")
+	os.Setenv("JOB_SUMMARY_FILE", "/run/refresharr/summary.json")
+	config, err = LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if !config.JobMode || config.SummaryFile != "/run/refresharr/summary.json" {
+		t.Errorf("Expected job mode with the summary file, got %t with %q", config.JobMode, config.SummaryFile)
+	}
+}
+
+func TestLoadConfig_Output(t *testing.T) {
+	clearTestEnv()
+	defer clearTestEnv()
+
+	dryRunFlag := false
+	noReportFlag := false
+	config, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if config.Output != OutputText {
+		t.Errorf("Expected text output by default, got %q", config.Output)
+	}
+
+	os.Setenv("OUTPUT_FORMAT", "JSON")
+	config, err = LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if config.Output != OutputJSON {
+		t.Errorf("Expected json output, got %q", config.Output)
+	}
+
+	// Reports on stdout would break the document
+	os.Setenv("REPORT_SINKS", "file://reports,stdout://")
+	if _, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil); err == nil || !strings.Contains(err.Error(), "entry 2") {
+		t.Errorf("Expected an error for a stdout report sink, got %v", err)
+	}
+	os.Unsetenv("REPORT_SINKS")
+
+	os.Setenv("OUTPUT_FORMAT", "yaml")
+	if _, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil); err == nil {
+		t.Error("Expected error for an unknown output")
+	}
+}
+
+func TestLoadConfig_CleanupTag(t *testing.T) {
+	clearTestEnv()
+	defer clearTestEnv()
+
+	dryRunFlag := false
+	noReportFlag := false
+	config, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if config.CleanupTag != "" {
+		t.Errorf("Expected no tag by default, got %q", config.CleanupTag)
+	}
+
+	// Labels are stored in lower case, as the services do
+	os.Setenv("CLEANUP_TAG", "RefreshArr-Cleaned")
+	config, err = LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if config.CleanupTag != "refresharr-cleaned" {
+		t.Errorf("Expected tag refresharr-cleaned, got %q", config.CleanupTag)
+	}
+
+	os.Setenv("CLEANUP_TAG", "cleaned by refresharr")
+	if _, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil); err == nil {
+		t.Error("Expected error for a tag with spaces")
+	}
+}
+
+func TestLoadConfig_LogFormat(t *testing.T) {
+	clearTestEnv()
+	defer clearTestEnv()
+
+	dryRunFlag := false
+	noReportFlag := false
+	config, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if config.LogFormat != "text" {
+		t.Errorf("Expected log format 'text', got %q", config.LogFormat)
+	}
+
+	os.Setenv("LOG_FORMAT", "JSON")
+	config, err = LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if config.LogFormat != "json" {
+		t.Errorf("Expected log format 'json', got %q", config.LogFormat)
+	}
+
+	os.Setenv("LOG_FORMAT", "xml")
+	if _, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil); err == nil {
+		t.Error("Expected error for an unknown log format")
+	}
+}
+
+func TestLoadConfig_LogTarget(t *testing.T) {
+	clearTestEnv()
+	defer clearTestEnv()
+
+	dryRunFlag := false
+	noReportFlag := false
+	config, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if config.LogTarget != "stderr" || config.SyslogFacility != "daemon" {
+		t.Errorf("Expected stderr logging with the daemon facility, got %q and %q", config.LogTarget, config.SyslogFacility)
+	}
+
+	os.Setenv("LOG_TARGET", "syslog")
+	os.Setenv("SYSLOG_ADDR", "udp://logs.local:514")
+	os.Setenv("SYSLOG_FACILITY", "local3")
+	config, err = LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if config.LogTarget != "syslog" || config.SyslogAddr != "udp://logs.local:514" || config.SyslogFacility != "local3" {
+		t.Errorf("Expected the syslog settings, got %q, %q and %q", config.LogTarget, config.SyslogAddr, config.SyslogFacility)
+	}
+
+	os.Setenv("LOG_TARGET", "file")
+	if _, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil); err == nil {
+		t.Error("Expected error for an unknown log target")
+	}
+}
+
+func TestLoadConfig_LogModuleLevels(t *testing.T) {
+	clearTestEnv()
+	defer clearTestEnv()
+
+	dryRunFlag := false
+	noReportFlag := false
+	os.Setenv("LOG_MODULE_LEVELS", "notify=debug, Cleanup=warn")
+	config, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	want := map[string]string{"notify": "DEBUG", "cleanup": "WARN"}
+	if !reflect.DeepEqual(config.LogModuleLevels, want) {
+		t.Errorf("Expected %v, got %v", want, config.LogModuleLevels)
+	}
+
+	for _, value := range []string{"notify", "notify=loud", "kodi=debug"} {
+		os.Setenv("LOG_MODULE_LEVELS", value)
+		if _, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil); err == nil {
+			t.Errorf("Expected error for LOG_MODULE_LEVELS=%s", value)
+		}
+	}
+}
+
+func TestLoadConfig_EmptyPathPolicy(t *testing.T) {
+	clearTestEnv()
+	defer clearTestEnv()
+
+	dryRunFlag := false
+	noReportFlag := false
+	config, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if config.EmptyPathPolicy != EmptyPathSkip {
+		t.Errorf("Expected empty paths to be skipped by default, got %q", config.EmptyPathPolicy)
+	}
+
+	os.Setenv("EMPTY_PATH_POLICY", "Delete")
+	config, err = LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if config.EmptyPathPolicy != EmptyPathDelete {
+		t.Errorf("Expected the delete policy, got %q", config.EmptyPathPolicy)
+	}
+
+	os.Setenv("EMPTY_PATH_POLICY", "ignore")
+	if _, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil); err == nil {
+		t.Error("Expected error for an unknown empty path policy")
+	}
+}
+
+func TestLoadConfig_OutsideRootPolicy(t *testing.T) {
+	clearTestEnv()
+	defer clearTestEnv()
+
+	dryRunFlag := false
+	noReportFlag := false
+	config, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if config.OutsideRootPolicy != OutsideRootSkip || config.PathMappings != nil {
+		t.Errorf("Expected records outside root folders to be skipped by default, got %q %v", config.OutsideRootPolicy, config.PathMappings)
+	}
+
+	os.Setenv("OUTSIDE_ROOT_POLICY", "Rewrite")
+	if _, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil); err == nil {
+		t.Error("Expected error for the rewrite policy without path mappings")
+	}
+
+	os.Setenv("PATH_MAPPINGS", "/mnt/old/tv=/data/tv,/mnt/old/movies=/data/movies")
+	config, err = LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if config.OutsideRootPolicy != OutsideRootRewrite || len(config.PathMappings) != 2 || config.PathMappings[1].To != "/data/movies" {
+		t.Errorf("Expected the rewrite policy with 2 mappings, got %q %v", config.OutsideRootPolicy, config.PathMappings)
+	}
+
+	os.Setenv("PATH_MAPPINGS", "/mnt/old/tv")
+	if _, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil); err == nil {
+		t.Error("Expected error for a mapping without a target")
+	}
+
+	os.Setenv("OUTSIDE_ROOT_POLICY", "move")
+	os.Setenv("PATH_MAPPINGS", "")
+	if _, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil); err == nil {
+		t.Error("Expected error for an unknown outside root policy")
+	}
+}
+
+func TestLoadConfig_SimulateLatency(t *testing.T) {
+	clearTestEnv()
+
+	os.Setenv("SIMULATE_LATENCY", "25ms")
+	defer clearTestEnv()
+
+	dryRunFlag := false
+	noReportFlag := false
+	config, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if config.SimulateLatency != 25*time.Millisecond {
+		t.Errorf("Expected simulated latency 25ms, got %v", config.SimulateLatency)
+	}
+
+	os.Setenv("SIMULATE_LATENCY", "slow")
+	if _, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil); err == nil {
+		t.Error("Expected error for invalid SIMULATE_LATENCY")
+	}
+}
+
+func TestLoadConfig_SpillAfter(t *testing.T) {
+	clearTestEnv()
+
+	os.Setenv("REPORT_SPILL_AFTER", "250")
+	defer clearTestEnv()
+
+	dryRunFlag := false
+	noReportFlag := false
+	config, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if config.SpillAfter != 250 {
+		t.Errorf("Expected spill threshold 250, got %d", config.SpillAfter)
+	}
+
+	os.Setenv("REPORT_SPILL_AFTER", "-1")
+	if _, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil); err == nil {
+		t.Error("Expected error for negative REPORT_SPILL_AFTER")
+	}
+}
+
+func TestLoadConfig_IOPacing(t *testing.T) {
+	clearTestEnv()
+
+	os.Setenv("IO_OPS_PER_SECOND", "200")
+	os.Setenv("IONICE", "Idle")
+	defer clearTestEnv()
+
+	dryRunFlag := false
+	noReportFlag := false
+	config, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if config.IOOpsPerSecond != 200 {
+		t.Errorf("Expected 200 IO operations per second, got %d", config.IOOpsPerSecond)
+	}
+	if config.IONice != IONiceIdle {
+		t.Errorf("Expected IO scheduling class %q, got %q", IONiceIdle, config.IONice)
+	}
+
+	os.Setenv("IO_OPS_PER_SECOND", "-5")
+	if _, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil); err == nil {
+		t.Error("Expected error for negative IO_OPS_PER_SECOND")
+	}
+
+	os.Setenv("IO_OPS_PER_SECOND", "0")
+	os.Setenv("IONICE", "realtime")
+	if _, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil); err == nil {
+		t.Error("Expected error for an unknown IONICE class")
+	}
+}
+
+func TestLoadConfig_Concurrency(t *testing.T) {
+	clearTestEnv()
+
+	os.Setenv("CONCURRENT_LIMIT", "8")
+	os.Setenv("EPISODE_CONCURRENCY", "10")
+	os.Setenv("MOVIE_CONCURRENCY", "1")
+	defer clearTestEnv()
+
+	dryRunFlag := false
+	noReportFlag := false
+	config, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if config.ConcurrentLimit != 8 || config.EpisodeConcurrency != 10 || config.MovieConcurrency != 1 {
+		t.Errorf("Expected concurrency 8/10/1, got %d/%d/%d", config.ConcurrentLimit, config.EpisodeConcurrency, config.MovieConcurrency)
+	}
+
+	os.Setenv("EPISODE_CONCURRENCY", "0")
+	if _, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil); err == nil {
+		t.Error("Expected error for EPISODE_CONCURRENCY of 0")
+	}
+
+	os.Setenv("EPISODE_CONCURRENCY", "2")
+	os.Setenv("MOVIE_CONCURRENCY", "fast")
+	if _, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil); err == nil {
+		t.Error("Expected error for a non-numeric MOVIE_CONCURRENCY")
+	}
+}
+
+func TestLoadConfig_Sinks(t *testing.T) {
+	clearTestEnv()
+
+	os.Setenv("SINK_TIMEOUT", "5s")
+	os.Setenv("SINK_QUEUE_SIZE", "20")
+	defer clearTestEnv()
+
+	dryRunFlag := false
+	noReportFlag := false
+	config, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if config.SinkTimeout != 5*time.Second || config.SinkQueueSize != 20 {
+		t.Errorf("Expected a 5s sink timeout and a queue of 20, got %s and %d", config.SinkTimeout, config.SinkQueueSize)
+	}
+
+	os.Setenv("SINK_TIMEOUT", "0s")
+	if _, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil); err == nil {
+		t.Error("Expected error for a zero SINK_TIMEOUT")
+	}
+
+	os.Setenv("SINK_TIMEOUT", "5s")
+	os.Setenv("SINK_QUEUE_SIZE", "-1")
+	if _, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil); err == nil {
+		t.Error("Expected error for a negative SINK_QUEUE_SIZE")
+	}
+}
+
+func TestLoadConfig_Instances(t *testing.T) {
+	clearTestEnv()
+
+	os.Setenv("RADARR_API_KEY", "hd-key")
+	os.Setenv("RADARR_4K_URL", " http://radarr-4k:7878")
+	os.Setenv("RADARR_4K_API_KEY", "4k-key")
+	os.Setenv("SONARR_ANIME_HD_API_KEY", "anime-key")
+	defer func() {
+		os.Unsetenv("RADARR_4K_URL")
+		os.Unsetenv("RADARR_4K_API_KEY")
+		os.Unsetenv("SONARR_ANIME_HD_API_KEY")
+		clearTestEnv()
+	}()
+
+	dryRunFlag := false
+	noReportFlag := false
+	config, err := LoadConfigWithFlags(&dryRunFlag, &noReportFlag, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+
+	// RADARR_API_KEY is the default instance, not one named "api"
+	want := []InstanceConfig{
+		{Service: "radarr", Name: "4k", URL: "http://radarr-4k:7878", APIKey: "4k-key"},
+		{Service: "sonarr", Name: "anime-hd", APIKey: "anime-key"},
+	}
+	if !reflect.DeepEqual(config.Instances, want) {
+		t.Errorf("Instances = %+v, want %+v", config.Instances, want)
+	}
+	if label := config.Instances[0].Label(); label != "radarr-4k" {
+		t.Errorf("Expected label radarr-4k, got %s", label)
+	}
+
+	// Extra instances have no default URL
+	err = config.Validate()
+	if err == nil || !strings.Contains(err.Error(), "SONARR_ANIME_HD_URL is required") {
+		t.Errorf("Expected an error for the missing SONARR_ANIME_HD_URL, got %v", err)
+	}
+}
+
+func TestLoadEndpoint(t *testing.T) {
+	clearTestEnv()
+	defer clearTestEnv()
+
+	str := func(v string) *string { return &v }
+	tests := []struct {
+		name    string
+		env     map[string]string
+		urlFlag *string
+		keyFlag *string
+		wantURL string
+		wantKey string
+	}{
+		{"unset", nil, nil, nil, "", ""},
+		{"env key gets default URL", map[string]string{"RADARR_API_KEY": "env-key"}, nil, nil, "http://127.0.0.1:7878", "env-key"},
+		{"flag key gets default URL", nil, nil, str("flag-key"), "http://127.0.0.1:7878", "flag-key"},
+		{"flags only", nil, str("http://radarr:7878"), str("flag-key"), "http://radarr:7878", "flag-key"},
+		{"flags override env", map[string]string{"RADARR_URL": "http://env:7878", "RADARR_API_KEY": "env-key"}, str("http://flag:7878"), str("flag-key"), "http://flag:7878", "flag-key"},
+		{"empty flags keep env", map[string]string{"RADARR_URL": "http://env:7878", "RADARR_API_KEY": "env-key"}, str(""), str(""), "http://env:7878", "env-key"},
+		{"URL without key", map[string]string{"RADARR_URL": "http://env:7878"}, nil, nil, "http://env:7878", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearTestEnv()
+			for k, v := range tt.env {
+				os.Setenv(k, v)
+			}
+			gotURL, gotKey := loadEndpoint("RADARR_URL", "RADARR_API_KEY", "http://127.0.0.1:7878", tt.urlFlag, tt.keyFlag)
+			if gotURL != tt.wantURL || gotKey != tt.wantKey {
+				t.Errorf("loadEndpoint() = %q, %q, want %q, %q", gotURL, gotKey, tt.wantURL, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestParseIDs(t *testing.T) {
+	ids, err := parseIDs("movie ID", " 1, 2,,3 ")
+	if err != nil || !reflect.DeepEqual(ids, []int{1, 2, 3}) {
+		t.Errorf("parseIDs() = %v, %v, want [1 2 3]", ids, err)
+	}
+
+	// The error names the kind of ID that was wrong
+	clearTestEnv()
+	defer clearTestEnv()
+	fs := NewFlagSet()
+	if err := fs.Parse([]string{"--movie-ids", "abc"}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = LoadConfigFromFlags(fs, nil)
+	if err == nil || !strings.Contains(err.Error(), "invalid movie ID 'abc'") {
+		t.Errorf("Expected an error naming the movie ID, got %v", err)
+	}
+}
+
+func TestCheckTargets(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{"none", Config{}, false},
+		{"series only", Config{SeriesIDs: []int{1}}, false},
+		{"episodes only", Config{EpisodeIDs: []int{1}}, false},
+		{"path only", Config{TargetPath: "/media/movies/Foo (2020)/Foo.mkv"}, false},
+		{"series and episodes", Config{SeriesIDs: []int{1}, EpisodeIDs: []int{2}}, true},
+		{"episodes and path", Config{EpisodeIDs: []int{2}, TargetPath: "/media/a.mkv"}, true},
+		{"ids file only", Config{IDsFile: "targets.txt"}, false},
+		{"series and ids file", Config{SeriesIDs: []int{1}, IDsFile: "targets.txt"}, true},
+		{"movies only", Config{MovieIDs: []int{1}}, false},
+		{"movies and series", Config{MovieIDs: []int{1}, SeriesIDs: []int{2}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkTargets(&tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkTargets() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestKodiConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  KodiConfig
+		wantErr bool
+	}{
+		{"not configured", KodiConfig{}, false},
+		{"valid without auth", KodiConfig{URL: "http://kodi.local:8080"}, false},
+		{"valid with auth", KodiConfig{URL: "https://kodi.local", Username: "kodi", Password: "p"}, false},
+		{"missing scheme", KodiConfig{URL: "kodi.local:8080"}, true},
+		{"unix socket", KodiConfig{URL: "unix:///run/kodi.sock"}, false},
+		{"password without username", KodiConfig{URL: "http://kodi.local:8080", Password: "p"}, true},
+		{"negative timeout", KodiConfig{URL: "http://kodi.local:8080", Timeout: -time.Second}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("KodiConfig.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestPlexConfig_Validation(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -490,9 +1988,25 @@ func clearTestEnv() {
 	envVars := []string{
 		"SONARR_URL", "SONARR_API_KEY",
 		"RADARR_URL", "RADARR_API_KEY",
+		"READARR_URL", "READARR_API_KEY",
 		"PLEX_URL", "PLEX_TOKEN",
 		"REQUEST_TIMEOUT", "REQUEST_DELAY", "CONCURRENT_LIMIT",
-		"LOG_LEVEL", "DRY_RUN",
+		"LOG_LEVEL", "DRY_RUN", "SONARR_DRY_RUN", "RADARR_DRY_RUN", "READARR_DRY_RUN", "RADARR_4K_URL", "RADARR_4K_API_KEY", "RADARR_4K_DRY_RUN",
+		"STATE_DIR", "REPORT_DIR", "REPORT_SINKS", "REPORT_COMPRESS_AFTER_DAYS", "REPORT_ARCHIVE_MONTHLY", "HISTORY_RETENTION_DAYS", "TENANTS_FILE", "LISTEN_ADDR", "SERVE_API_KEY", "WEB_UI", "MAINTENANCE_WINDOWS",
+		"PLEX_TIMEOUT", "PLEX_LIBRARIES",
+		"KODI_URL", "KODI_USERNAME", "KODI_PASSWORD", "KODI_TIMEOUT",
+		"PROWLARR_URL", "PROWLARR_API_KEY",
+		"VERIFY_AT", "VERIFY_ALERT_THRESHOLD", "NOTIFY_WEBHOOK_URL", "COMPLETION_WEBHOOK_URL", "COMPLETION_WEBHOOK_RETRIES", "NTFY_URL", "NTFY_TOKEN", "PUSHOVER_TOKEN", "PUSHOVER_USER", "PUSH_FAILURES_ONLY", "NOTIFY_URLS", "SENTRY_DSN", "SENTRY_ENVIRONMENT", "ERROR_WEBHOOK_URL",
+		"API_BUDGET",
+		"REPORT_SPILL_AFTER",
+		"SIMULATE_LATENCY",
+		"API_CLIENT",
+		"USER_AGENT",
+		"TRACE_HTTP", "TRACE_HTTP_BODIES", "MEDIA_CACHE_TTL", "SKIP_SPECIALS", "PATH_PATTERNS_FILE",
+		"TITLE_MATCH_CONFIDENCE", "MONITOR_COLLECTIONS", "VALIDATE_ADDS", "CLEANUP_TAG", "DELETE_REJECTED_IMPORTS", "REQUEUE_FAILED_IMPORTS", "IMPORT_COOLDOWN", "MESSAGES_FILE",
+		"JOB_MODE", "JOB_SUMMARY_FILE", "OUTPUT_FORMAT", "LOG_FORMAT", "LOG_TARGET", "SYSLOG_ADDR", "SYSLOG_FACILITY", "LOG_MODULE_LEVELS",
+		"EMPTY_PATH_POLICY", "OUTSIDE_ROOT_POLICY", "PATH_MAPPINGS", "CHECKSUM_MANIFEST",
+		"IO_OPS_PER_SECOND", "IONICE", "EPISODE_CONCURRENCY", "MOVIE_CONCURRENCY", "SINK_TIMEOUT", "SINK_QUEUE_SIZE",
 	}
 	for _, envVar := range envVars {
 		os.Unsetenv(envVar)
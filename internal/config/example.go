@@ -0,0 +1,90 @@
+package config
+
+import "fmt"
+
+// Example is a commented example configuration in .env format, written by `config init`. Only
+// the service URLs and API keys are set; everything else shows its default, commented out.
+const Example = `# RefreshArr configuration
+#
+# RefreshArr reads its settings from the environment and from a .env file in the working
+# directory. Uncomment a line to change the default shown. Check the file with
+# "refresharr config validate --online".
+
+# --- Services ---------------------------------------------------------------
+# Configure at least one. The API key is in each app under Settings -> General.
+SONARR_URL=http://127.0.0.1:8989
+SONARR_API_KEY=
+RADARR_URL=http://127.0.0.1:7878
+RADARR_API_KEY=
+# READARR_URL=http://127.0.0.1:8787
+# READARR_API_KEY=
+
+# Extra instances next to the default ones, named <SERVICE>_<NAME>_...
+# RADARR_4K_URL=http://127.0.0.1:7879
+# RADARR_4K_API_KEY=
+
+# Prowlarr, to skip missing searches while every indexer is down
+# PROWLARR_URL=http://127.0.0.1:9696
+# PROWLARR_API_KEY=
+
+# Plex and Kodi, for compare-plex and compare-kodi
+# PLEX_URL=http://127.0.0.1:32400
+# PLEX_TOKEN=
+# KODI_URL=http://127.0.0.1:8080
+
+# --- Runs -------------------------------------------------------------------
+# Report what would change without changing anything
+# DRY_RUN=false
+# Override DRY_RUN for a single service
+# RADARR_DRY_RUN=true
+# Only change things in these weekly local times; outside them changes are only reported
+# MAINTENANCE_WINDOWS=sat,sun 02:00-06:00; mon-fri 03:00-05:00
+
+# Durations are written like 500ms, 30s, 5m or 1h
+# REQUEST_TIMEOUT=30s
+# REQUEST_DELAY=500ms
+# CONCURRENT_LIMIT=5
+
+# Persistent state: run history, job queue, media cache
+# STATE_DIR=data
+# REPORT_DIR=reports
+# REPORT_SINKS=file://reports,s3://bucket/refresharr?format=csv
+# REPORT_COMPRESS_AFTER_DAYS=14
+# REPORT_ARCHIVE_MONTHLY=false
+# HISTORY_RETENTION_DAYS=90
+# MEDIA_CACHE_TTL=1h
+
+# DEBUG, INFO, WARN or ERROR
+# LOG_LEVEL=INFO
+# LOG_FORMAT=text
+# LOG_MODULE_LEVELS=notify=debug,cleanup=warn
+# text, or json for a single JSON document on stdout instead of logs
+# OUTPUT_FORMAT=text
+
+# Tag series/movies whose file records were deleted, to filter on them in Sonarr/Radarr
+# CLEANUP_TAG=refresharr-cleaned
+
+# --- fix-imports --------------------------------------------------------------
+# DELETE_REJECTED_IMPORTS=false
+# REQUEUE_FAILED_IMPORTS=false
+# IMPORT_COOLDOWN=0
+
+# --- serve ------------------------------------------------------------------
+# LISTEN_ADDR=:8080
+# Key requests to the API must carry in the X-Api-Key header (required without TENANTS_FILE)
+# SERVE_API_KEY=
+# WEB_UI=false
+# Daily verify sweep at a local time (HH:MM)
+# VERIFY_AT=03:00
+
+# --- Notifications ----------------------------------------------------------
+# Apprise-style URLs, separated by commas, e.g. ntfy://my-topic
+# NOTIFY_URLS=
+# PUSH_FAILURES_ONLY=false
+# COMPLETION_WEBHOOK_URL=
+`
+
+// ignoredDuration says what to change about a duration setting that isn't one
+func ignoredDuration(name, value, fallback string) string {
+	return fmt.Sprintf("%s %q is not a duration, so the default %s is used: write it like 500ms, 30s or 5m", name, value, fallback)
+}
@@ -0,0 +1,186 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+// TenantConfig is a user of a shared serve deployment, e.g. on a seedbox: their own Sonarr,
+// Radarr and Readarr, report directory and run history. Requests select the tenant with its
+// API key. Tenants are read from the JSON file TENANTS_FILE names.
+type TenantConfig struct {
+	Name      string // Lowercase name; the tenant's state is kept in <STATE_DIR>/tenants/<name>
+	APIKey    string // Key the tenant's requests send in the X-Api-Key header
+	Sonarr    SonarrConfig
+	Radarr    RadarrConfig
+	Readarr   ReadarrConfig
+	Instances []InstanceConfig // Extra instances, e.g. {"service": "radarr", "name": "4k", ...}
+	ReportDir string           // Where the tenant's reports are saved (empty means <REPORT_DIR>/<name>)
+
+	// The tenant's own report sinks and notification services in Apprise URL syntax. The
+	// deployment's REPORT_SINKS, notifiers and error tracking are never used for tenants.
+	ReportSinks []string // Report sink URLs (empty means JSON files in ReportDir)
+	NotifyURLs  []string // Alerts and run summaries, e.g. ntfy://topic (empty means none)
+
+	// Settings that name the tenant's own folders and files, in place of the deployment's
+	// PATH_MAPPINGS and CHECKSUM_MANIFEST
+	PathMappings     []string // Rules of the form from=to (empty means none)
+	ChecksumManifest string   // Checksum manifest verify runs check files against (empty means none)
+
+	pathMappings []models.PathMapping // PathMappings parsed by normalize
+}
+
+// tenantNamePattern matches the names tenants can have, as they name directories
+var tenantNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// loadTenants reads a tenants file: a JSON array of tenants. Every tenant needs a unique name
+// and API key, and at least one service with its URL and API key.
+func loadTenants(path string) ([]TenantConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tenants file: %w", err)
+	}
+
+	var tenants []TenantConfig
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&tenants); err != nil {
+		return nil, fmt.Errorf("failed to parse tenants file %s: %w", path, err)
+	}
+	if len(tenants) == 0 {
+		return nil, fmt.Errorf("tenants file %s lists no tenants", path)
+	}
+
+	names := make(map[string]bool)
+	keys := make(map[string]bool)
+	for i := range tenants {
+		tenant := &tenants[i]
+		if !tenantNamePattern.MatchString(tenant.Name) {
+			return nil, fmt.Errorf("invalid tenant name %q in %s: must be lowercase letters, digits, - and _", tenant.Name, path)
+		}
+		if names[tenant.Name] {
+			return nil, fmt.Errorf("tenant %s is listed twice in %s", tenant.Name, path)
+		}
+		names[tenant.Name] = true
+		if tenant.APIKey == "" {
+			return nil, fmt.Errorf("tenant %s has no apiKey", tenant.Name)
+		}
+		if keys[tenant.APIKey] {
+			return nil, fmt.Errorf("tenant %s has the API key of another tenant", tenant.Name)
+		}
+		keys[tenant.APIKey] = true
+
+		if err := tenant.normalize(); err != nil {
+			return nil, err
+		}
+	}
+	return tenants, nil
+}
+
+// tenantEndpoint is a service of a tenant, checked by normalize
+type tenantEndpoint struct {
+	name        string
+	url, apiKey *string
+}
+
+// normalize checks the tenant's services and normalizes their URLs
+func (t *TenantConfig) normalize() error {
+	var endpoints []tenantEndpoint
+	for _, endpoint := range []tenantEndpoint{
+		{"sonarr", &t.Sonarr.URL, &t.Sonarr.APIKey},
+		{"radarr", &t.Radarr.URL, &t.Radarr.APIKey},
+		{"readarr", &t.Readarr.URL, &t.Readarr.APIKey},
+	} {
+		if *endpoint.url != "" || *endpoint.apiKey != "" {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	for i := range t.Instances {
+		instance := &t.Instances[i]
+		instance.Service = strings.ToLower(instance.Service)
+		instance.Name = strings.ToLower(instance.Name)
+		switch instance.Service {
+		case "sonarr", "radarr", "readarr":
+		default:
+			return fmt.Errorf("tenant %s: unknown service %q of instance %q", t.Name, instance.Service, instance.Name)
+		}
+		if instance.Name == "" {
+			return fmt.Errorf("tenant %s: an extra %s instance has no name", t.Name, instance.Service)
+		}
+		endpoints = append(endpoints, tenantEndpoint{instance.Label(), &instance.URL, &instance.APIKey})
+	}
+	if len(endpoints) == 0 {
+		return fmt.Errorf("tenant %s has no services configured", t.Name)
+	}
+
+	// The URLs may hold passwords and tokens, so only their position is echoed back
+	for i, raw := range t.ReportSinks {
+		if u, err := url.Parse(raw); err != nil || u.Scheme == "" {
+			return fmt.Errorf("tenant %s: invalid reportSinks entry %d: must be a URL such as file:///srv/reports or s3://bucket", t.Name, i+1)
+		}
+	}
+	for i, raw := range t.NotifyURLs {
+		if u, err := url.Parse(raw); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("tenant %s: invalid notifyUrls entry %d: must be a URL such as ntfy://topic", t.Name, i+1)
+		}
+	}
+	mappings, err := models.ParsePathMappings(strings.Join(t.PathMappings, ","))
+	if err != nil {
+		return fmt.Errorf("tenant %s: %w", t.Name, err)
+	}
+	t.pathMappings = mappings
+
+	for _, endpoint := range endpoints {
+		// Tenants' services have no default URL; a shared host runs each user's on their own port
+		if *endpoint.url == "" || *endpoint.apiKey == "" {
+			return fmt.Errorf("tenant %s: %s needs both url and apiKey", t.Name, endpoint.name)
+		}
+		*endpoint.url = normalizeEndpointURL(*endpoint.url)
+		if err := validateEndpointURL(t.Name+" "+endpoint.name, *endpoint.url); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ForTenant returns a copy of the configuration that runs against the tenant's services, with
+// the tenant's own state directory, reports, report sinks, notifiers, path mappings and checksum
+// manifest. Prowlarr, Plex, Kodi, the per-service dry-run overrides, ONLY_FROM and error
+// tracking are left out, as they belong to the deployment itself, and a tenant's reports and
+// failures must not reach anyone else. Without path mappings of their own, the rewrite outside
+// root policy only reports a tenant's records.
+func (c *Config) ForTenant(tenant TenantConfig) *Config {
+	tenantCfg := *c
+	tenantCfg.Sonarr = tenant.Sonarr
+	tenantCfg.Radarr = tenant.Radarr
+	tenantCfg.Readarr = tenant.Readarr
+	tenantCfg.Instances = tenant.Instances
+	tenantCfg.Prowlarr = ProwlarrConfig{}
+	tenantCfg.ServiceDryRun = nil
+	tenantCfg.ReportSinks = tenant.ReportSinks
+	tenantCfg.Notify = NotifyConfig{URLs: tenant.NotifyURLs, PushFailuresOnly: c.Notify.PushFailuresOnly}
+	tenantCfg.ErrorTracking = ErrorTrackingConfig{}
+	tenantCfg.Plex = PlexConfig{}
+	tenantCfg.Kodi = KodiConfig{}
+	tenantCfg.OnlyFrom = ""
+	tenantCfg.PathMappings = tenant.pathMappings
+	if tenantCfg.OutsideRootPolicy == OutsideRootRewrite && len(tenantCfg.PathMappings) == 0 {
+		tenantCfg.OutsideRootPolicy = OutsideRootSkip
+	}
+	tenantCfg.ChecksumManifest = tenant.ChecksumManifest
+	tenantCfg.Tenants = nil
+	tenantCfg.StateDir = filepath.Join(c.StateDir, "tenants", tenant.Name)
+	tenantCfg.ReportDir = tenant.ReportDir
+	if tenantCfg.ReportDir == "" {
+		tenantCfg.ReportDir = filepath.Join(c.ReportDir, tenant.Name)
+	}
+	return &tenantCfg
+}
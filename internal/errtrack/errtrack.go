@@ -0,0 +1,191 @@
+// Package errtrack reports panics and failed runs to an error tracker, so an unattended daemon
+// that crashes or keeps failing doesn't go unnoticed. Sentry and a generic JSON webhook are supported.
+package errtrack
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hnipps/refresharr/internal/arr"
+	"github.com/hnipps/refresharr/internal/config"
+)
+
+// Event levels
+const (
+	LevelError = "error" // A run failed
+	LevelFatal = "fatal" // The process panicked
+)
+
+// Event describes a panic or a failed run
+type Event struct {
+	ID        string            `json:"id"` // 32 hex characters, the format Sentry expects
+	Timestamp time.Time         `json:"timestamp"`
+	Level     string            `json:"level"`
+	Message   string            `json:"message"`
+	Command   string            `json:"command"`
+	Service   string            `json:"service,omitempty"`
+	RunID     string            `json:"runId,omitempty"`
+	Release   string            `json:"release"`
+	Hostname  string            `json:"hostname,omitempty"`
+	Stack     string            `json:"stack,omitempty"` // Goroutine stack of a panic
+	Config    map[string]string `json:"config"`          // Settings of the run, without secrets
+}
+
+// NewEvent creates an event for the command, with the configuration of the run attached
+func NewEvent(level, message, command, release string, cfg *config.Config) *Event {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	hostname, _ := os.Hostname()
+	return &Event{
+		ID:        hex.EncodeToString(id),
+		Timestamp: time.Now().UTC(),
+		Level:     level,
+		Message:   message,
+		Command:   command,
+		Release:   release,
+		Hostname:  hostname,
+		Config:    ConfigContext(cfg),
+	}
+}
+
+// ConfigContext returns the settings that help make sense of a failure. API keys, tokens and
+// passwords are never included, and URLs lose their credentials and query strings.
+func ConfigContext(cfg *config.Config) map[string]string {
+	settings := map[string]string{
+		"service":         cfg.Service,
+		"dryRun":          strconv.FormatBool(cfg.DryRun),
+		"verify":          strconv.FormatBool(cfg.Verify),
+		"apiClient":       cfg.APIClient,
+		"concurrentLimit": strconv.Itoa(cfg.ConcurrentLimit),
+		"requestTimeout":  cfg.RequestTimeout.String(),
+		"stateDir":        cfg.StateDir,
+		"logLevel":        cfg.LogLevel,
+		"jobMode":         strconv.FormatBool(cfg.JobMode),
+	}
+	add := func(key, rawURL string) {
+		if rawURL != "" {
+			settings[key] = redactURL(rawURL)
+		}
+	}
+	add("sonarrUrl", cfg.Sonarr.URL)
+	add("radarrUrl", cfg.Radarr.URL)
+	add("prowlarrUrl", cfg.Prowlarr.URL)
+	add("plexUrl", cfg.Plex.URL)
+	add("kodiUrl", cfg.Kodi.URL)
+	return settings
+}
+
+// redactURL drops the user info, query and fragment of a URL, where credentials end up
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "[unparseable]"
+	}
+	u.User = nil
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String()
+}
+
+// Tracker sends events to one error-tracking backend
+type Tracker interface {
+	// Name identifies the backend in logs
+	Name() string
+	Capture(ctx context.Context, event *Event) error
+}
+
+// Trackers returns a tracker for every backend the configuration sets up
+func Trackers(cfg *config.ErrorTrackingConfig, timeout time.Duration, logger arr.Logger) []Tracker {
+	var trackers []Tracker
+	if cfg.SentryDSN != "" {
+		sentry, err := NewSentryClient(cfg, timeout, logger)
+		if err != nil {
+			logger.Warn("Sentry reporting disabled: %s", err.Error())
+		} else {
+			trackers = append(trackers, sentry)
+		}
+	}
+	if cfg.WebhookURL != "" {
+		trackers = append(trackers, NewWebhookClient(cfg, timeout, logger))
+	}
+	return trackers
+}
+
+// CaptureAll sends the event to every tracker. Failures are logged rather than returned, as
+// reporting an error must never turn into another one.
+func CaptureAll(ctx context.Context, trackers []Tracker, event *Event, logger arr.Logger) {
+	for _, tracker := range trackers {
+		if err := tracker.Capture(ctx, event); err != nil {
+			logger.Warn("Failed to report error to %s: %s", tracker.Name(), err.Error())
+		} else {
+			logger.Debug("Reported %s event %s to %s", event.Level, event.ID, tracker.Name())
+		}
+	}
+}
+
+// post sends a JSON body and checks for a 2xx response
+func post(ctx context.Context, httpClient *http.Client, target string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send event: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
+
+// WebhookClient posts events as JSON to a webhook URL
+type WebhookClient struct {
+	url        string
+	httpClient *http.Client
+	logger     arr.Logger
+}
+
+// NewWebhookClient creates a new webhook client
+func NewWebhookClient(cfg *config.ErrorTrackingConfig, timeout time.Duration, logger arr.Logger) *WebhookClient {
+	return &WebhookClient{
+		url: cfg.WebhookURL,
+		httpClient: &http.Client{
+			Timeout: timeout,
+		},
+		logger: logger,
+	}
+}
+
+// Name returns "webhook"
+func (c *WebhookClient) Name() string {
+	return "webhook"
+}
+
+// Capture posts the event to the webhook
+func (c *WebhookClient) Capture(ctx context.Context, event *Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+	return post(ctx, c.httpClient, c.url, body, nil)
+}
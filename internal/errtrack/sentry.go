@@ -0,0 +1,133 @@
+package errtrack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hnipps/refresharr/internal/arr"
+	"github.com/hnipps/refresharr/internal/config"
+)
+
+// SentryClient sends events to a Sentry project's store endpoint
+type SentryClient struct {
+	storeURL    string
+	publicKey   string
+	environment string
+	httpClient  *http.Client
+	logger      arr.Logger
+}
+
+// NewSentryClient creates a Sentry client for the configured DSN
+func NewSentryClient(cfg *config.ErrorTrackingConfig, timeout time.Duration, logger arr.Logger) (*SentryClient, error) {
+	storeURL, publicKey, err := parseDSN(cfg.SentryDSN)
+	if err != nil {
+		return nil, err
+	}
+	return &SentryClient{
+		storeURL:    storeURL,
+		publicKey:   publicKey,
+		environment: cfg.Environment,
+		httpClient: &http.Client{
+			Timeout: timeout,
+		},
+		logger: logger,
+	}, nil
+}
+
+// parseDSN returns the store endpoint and public key of a DSN such as
+// https://<key>@o1.ingest.sentry.io/<project>; self-hosted DSNs may have a path before the project
+func parseDSN(dsn string) (string, string, error) {
+	u, err := url.Parse(dsn)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.User == nil || u.User.Username() == "" {
+		return "", "", fmt.Errorf("invalid Sentry DSN: expected https://<key>@<host>/<project>")
+	}
+	path := strings.Trim(u.Path, "/")
+	project := path[strings.LastIndex(path, "/")+1:]
+	if project == "" {
+		return "", "", fmt.Errorf("invalid Sentry DSN: missing project ID")
+	}
+	prefix := strings.TrimSuffix(path, project)
+
+	store := url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/" + prefix + "api/" + project + "/store/"}
+	return store.String(), u.User.Username(), nil
+}
+
+// Name returns "sentry"
+func (c *SentryClient) Name() string {
+	return "sentry"
+}
+
+// sentryEvent is the event payload of Sentry's store endpoint
+type sentryEvent struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   string            `json:"timestamp"`
+	Level       string            `json:"level"`
+	Platform    string            `json:"platform"`
+	Logger      string            `json:"logger"`
+	ServerName  string            `json:"server_name,omitempty"`
+	Release     string            `json:"release"`
+	Environment string            `json:"environment,omitempty"`
+	Message     string            `json:"message,omitempty"`
+	Exception   *sentryExceptions `json:"exception,omitempty"`
+	Tags        map[string]string `json:"tags"`
+	Extra       map[string]string `json:"extra"`
+}
+
+// sentryExceptions holds the panic of a fatal event
+type sentryExceptions struct {
+	Values []sentryException `json:"values"`
+}
+
+// sentryException is a single exception; Go panics have no parsed frames, so the stack goes in extra
+type sentryException struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// Capture sends the event to Sentry. The command, service and run ID become tags, so events
+// can be grouped and searched by them; the configuration and any stack go in extra.
+func (c *SentryClient) Capture(ctx context.Context, event *Event) error {
+	payload := sentryEvent{
+		EventID:     event.ID,
+		Timestamp:   event.Timestamp.Format(time.RFC3339),
+		Level:       event.Level,
+		Platform:    "go",
+		Logger:      "refresharr",
+		ServerName:  event.Hostname,
+		Release:     "refresharr@" + event.Release,
+		Environment: c.environment,
+		Tags:        map[string]string{"command": event.Command},
+		Extra:       make(map[string]string, len(event.Config)+1),
+	}
+	if event.Level == LevelFatal {
+		payload.Exception = &sentryExceptions{Values: []sentryException{{Type: "panic", Value: event.Message}}}
+	} else {
+		payload.Message = event.Message
+	}
+	if event.Service != "" {
+		payload.Tags["service"] = event.Service
+	}
+	if event.RunID != "" {
+		payload.Tags["runId"] = event.RunID
+	}
+	for key, value := range event.Config {
+		payload.Extra["config."+key] = value
+	}
+	if event.Stack != "" {
+		payload.Extra["stack"] = event.Stack
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	auth := fmt.Sprintf("Sentry sentry_version=7, sentry_client=refresharr/%s, sentry_key=%s", event.Release, c.publicKey)
+	c.logger.Debug("Sending %s event %s to Sentry", event.Level, event.ID)
+	return post(ctx, c.httpClient, c.storeURL, body, map[string]string{"X-Sentry-Auth": auth})
+}
@@ -1,28 +1,54 @@
 package filesystem
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 
 	"github.com/hnipps/refresharr/internal/arr"
+	"github.com/hnipps/refresharr/pkg/models"
 )
 
 // FileSystemChecker implements the FileChecker interface
-type FileSystemChecker struct{}
+type FileSystemChecker struct {
+	pacer *pacer       // Limits stat, open and readdir calls per second (nil means unlimited)
+	ops   atomic.Int64 // stat, open and readdir calls made
+}
 
 // NewFileSystemChecker creates a new FileSystemChecker
 func NewFileSystemChecker() arr.FileChecker {
 	return &FileSystemChecker{}
 }
 
+// NewPacedFileSystemChecker creates a FileSystemChecker that makes at most opsPerSecond stat,
+// open and readdir calls per second, so scans don't saturate disks other programs are using.
+// 0 means unlimited.
+func NewPacedFileSystemChecker(opsPerSecond int) arr.FileChecker {
+	return &FileSystemChecker{pacer: newPacer(opsPerSecond)}
+}
+
+// pace counts a filesystem operation and waits until it is allowed
+func (f *FileSystemChecker) pace() {
+	f.ops.Add(1)
+	f.pacer.wait()
+}
+
+// FileOps returns the number of stat, open and readdir calls made since the checker was created
+func (f *FileSystemChecker) FileOps() int64 {
+	return f.ops.Load()
+}
+
 // FileExists checks if a file exists at the given path
 func (f *FileSystemChecker) FileExists(path string) bool {
 	if path == "" {
 		return false
 	}
 
+	f.pace()
 	info, err := os.Stat(path)
 	if err != nil {
 		return false
@@ -39,6 +65,7 @@ func (f *FileSystemChecker) IsReadable(path string) bool {
 	}
 
 	// Try to open the file for reading
+	f.pace()
 	file, err := os.Open(path)
 	if err != nil {
 		return false
@@ -48,12 +75,76 @@ func (f *FileSystemChecker) IsReadable(path string) bool {
 	return true
 }
 
+// FileSize returns the size of the file at the given path in bytes
+func (f *FileSystemChecker) FileSize(path string) (int64, error) {
+	f.pace()
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// MissingReason tells why there is no readable file at path: a broken symlink, a file or folder
+// that can't be accessed, storage that looks unmounted, or simply nothing there. Storage looks
+// unmounted when the nearest folder above the path that exists is empty, like an unmounted
+// mount point, or is the filesystem root.
+func (f *FileSystemChecker) MissingReason(path string) string {
+	f.pace()
+	info, err := os.Lstat(path)
+	switch {
+	case err == nil && info.Mode()&os.ModeSymlink != 0:
+		return models.ReasonBrokenSymlink
+	case err == nil:
+		f.pace()
+		if _, err := os.Stat(path); errors.Is(err, fs.ErrPermission) {
+			return models.ReasonPermissionDenied
+		}
+		return models.ReasonNotFound
+	case errors.Is(err, fs.ErrPermission):
+		return models.ReasonPermissionDenied
+	}
+
+	// Find the nearest folder above the path that exists
+	parent := filepath.Dir(path)
+	dir := parent
+	for {
+		f.pace()
+		info, err := os.Stat(dir)
+		if err == nil && info.IsDir() {
+			break
+		}
+		if errors.Is(err, fs.ErrPermission) {
+			return models.ReasonPermissionDenied
+		}
+		next := filepath.Dir(dir)
+		if next == dir {
+			return models.ReasonMountUnavailable
+		}
+		dir = next
+	}
+
+	if dir == parent {
+		return models.ReasonNotFound
+	}
+	f.pace()
+	entries, err := os.ReadDir(dir)
+	switch {
+	case errors.Is(err, fs.ErrPermission):
+		return models.ReasonPermissionDenied
+	case err == nil && len(entries) == 0, dir == filepath.Dir(dir):
+		return models.ReasonMountUnavailable
+	}
+	return models.ReasonNotFound
+}
+
 // IsSymlink checks if a path is a symbolic link
 func (f *FileSystemChecker) IsSymlink(path string) bool {
 	if path == "" {
 		return false
 	}
 
+	f.pace()
 	info, err := os.Lstat(path) // Use Lstat to check symlink status
 	if err != nil {
 		return false
@@ -62,18 +153,26 @@ func (f *FileSystemChecker) IsSymlink(path string) bool {
 	return info.Mode()&os.ModeSymlink != 0
 }
 
-// FindBrokenSymlinks recursively finds broken symlinks with specified extensions in a directory
+// FindBrokenSymlinks recursively finds broken symlinks with specified extensions in a directory.
+// Entry types come from the directory listings, so only folders and symlinks cost an operation.
 func (f *FileSystemChecker) FindBrokenSymlinks(rootDir string, extensions []string) ([]string, error) {
 	var brokenSymlinks []string
 
-	err := filepath.Walk(rootDir, func(path string, info os.FileInfo, err error) error {
+	f.pace()
+	err := filepath.WalkDir(rootDir, func(path string, entry fs.DirEntry, err error) error {
 		if err != nil {
 			// Log the error but continue walking
 			return nil
 		}
 
+		// Folders are read next
+		if entry.IsDir() {
+			f.pace()
+			return nil
+		}
+
 		// Check if this is a symlink
-		if info.Mode()&os.ModeSymlink == 0 {
+		if entry.Type()&os.ModeSymlink == 0 {
 			return nil
 		}
 
@@ -83,6 +182,7 @@ func (f *FileSystemChecker) FindBrokenSymlinks(rootDir string, extensions []stri
 		}
 
 		// Check if the symlink target exists
+		f.pace()
 		if _, err := os.Stat(path); err != nil {
 			// Symlink is broken
 			brokenSymlinks = append(brokenSymlinks, path)
@@ -101,6 +201,7 @@ func (f *FileSystemChecker) FindBrokenSymlinks(rootDir string, extensions []stri
 // DeleteSymlink removes a symlink from the filesystem
 func (f *FileSystemChecker) DeleteSymlink(path string) error {
 	// Verify that the target is actually a symlink before deletion
+	f.pace()
 	info, err := os.Lstat(path)
 	if err != nil {
 		return fmt.Errorf("failed to stat symlink %s: %w", path, err)
@@ -4,6 +4,8 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/hnipps/refresharr/pkg/models"
 )
 
 func TestFileSystemChecker_FileExists(t *testing.T) {
@@ -260,3 +262,41 @@ func findSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+func TestFileSystemChecker_MissingReason(t *testing.T) {
+	checker := &FileSystemChecker{}
+	tempDir := t.TempDir()
+
+	// A library with one show left, and an empty mount point for another library
+	library := filepath.Join(tempDir, "tv")
+	if err := os.MkdirAll(filepath.Join(library, "Show A", "Season 01"), 0755); err != nil {
+		t.Fatalf("Failed to create library: %v", err)
+	}
+	mountPoint := filepath.Join(tempDir, "movies")
+	if err := os.Mkdir(mountPoint, 0755); err != nil {
+		t.Fatalf("Failed to create mount point: %v", err)
+	}
+	brokenLink := filepath.Join(library, "Show A", "Season 01", "linked.mkv")
+	if err := os.Symlink(filepath.Join(tempDir, "gone.mkv"), brokenLink); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{"file gone from its folder", filepath.Join(library, "Show A", "Season 01", "episode.mkv"), models.ReasonNotFound},
+		{"show folder gone", filepath.Join(library, "Show B", "Season 01", "episode.mkv"), models.ReasonNotFound},
+		{"broken symlink", brokenLink, models.ReasonBrokenSymlink},
+		{"empty mount point", filepath.Join(mountPoint, "Movie (2020)", "movie.mkv"), models.ReasonMountUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checker.MissingReason(tt.path); got != tt.want {
+				t.Errorf("MissingReason(%s) = %s, expected %s", tt.path, got, tt.want)
+			}
+		})
+	}
+}
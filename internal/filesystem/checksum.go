@@ -0,0 +1,74 @@
+package filesystem
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LoadChecksumManifest reads a sha256sum-style manifest, one "<sha256>  <path>" line per file,
+// and returns the checksums by path. Relative paths are resolved against the manifest's folder.
+// Blank lines and lines starting with # are ignored.
+func LoadChecksumManifest(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checksum manifest: %w", err)
+	}
+	defer file.Close()
+
+	checksums := make(map[string]string)
+	dir := filepath.Dir(path)
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		sum, filePath, ok := strings.Cut(line, " ")
+		// sha256sum marks files hashed in binary mode with a * before the path
+		filePath = strings.TrimPrefix(strings.TrimLeft(filePath, " "), "*")
+		if !ok || filePath == "" || !isSHA256(sum) {
+			return nil, fmt.Errorf("invalid checksum manifest line %d in %s: expected \"<sha256>  <path>\"", lineNumber, path)
+		}
+		if !filepath.IsAbs(filePath) {
+			filePath = filepath.Join(dir, filePath)
+		}
+		checksums[filepath.Clean(filePath)] = strings.ToLower(sum)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read checksum manifest: %w", err)
+	}
+
+	return checksums, nil
+}
+
+// isSHA256 reports whether s is a hex encoded SHA-256 sum
+func isSHA256(s string) bool {
+	if len(s) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// FileSHA256 returns the hex encoded SHA-256 sum of the file's contents
+func (f *FileSystemChecker) FileSHA256(path string) (string, error) {
+	f.pace()
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
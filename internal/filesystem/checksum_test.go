@@ -0,0 +1,72 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadChecksumManifest(t *testing.T) {
+	dir := t.TempDir()
+	sumA := strings.Repeat("a", 64)
+	sumB := strings.Repeat("B", 64)
+	sumC := strings.Repeat("c", 64)
+	content := "# made with sha256sum\n" +
+		sumA + "  /tv/Show/S01E01.mkv\n" +
+		"\n" +
+		sumB + " *movies/Foo (2020)/Foo.mkv\n" +
+		sumC + "  /tv/Show/S01E02 - Two  Spaces.mkv\n"
+	path := filepath.Join(dir, "SHA256SUMS")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	checksums, err := LoadChecksumManifest(path)
+	if err != nil {
+		t.Fatalf("LoadChecksumManifest() failed: %v", err)
+	}
+
+	want := map[string]string{
+		"/tv/Show/S01E01.mkv":                           sumA,
+		filepath.Join(dir, "movies/Foo (2020)/Foo.mkv"): strings.ToLower(sumB),
+		"/tv/Show/S01E02 - Two  Spaces.mkv":             sumC,
+	}
+	if len(checksums) != len(want) {
+		t.Fatalf("Expected %d checksums, got %v", len(want), checksums)
+	}
+	for file, sum := range want {
+		if checksums[file] != sum {
+			t.Errorf("checksums[%q] = %q, want %q", file, checksums[file], sum)
+		}
+	}
+}
+
+func TestLoadChecksumManifest_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "SHA256SUMS")
+	if err := os.WriteFile(path, []byte("not-a-checksum  /tv/Show/S01E01.mkv\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadChecksumManifest(path); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("Expected an error naming the invalid line, got %v", err)
+	}
+	if _, err := LoadChecksumManifest(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Expected error for a missing manifest")
+	}
+}
+
+func TestFileSystemChecker_FileSHA256(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.txt")
+	if err := os.WriteFile(path, []byte("hello\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sum, err := (&FileSystemChecker{}).FileSHA256(path)
+	if err != nil {
+		t.Fatalf("FileSHA256() failed: %v", err)
+	}
+	if want := "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03"; sum != want {
+		t.Errorf("FileSHA256() = %s, want %s", sum, want)
+	}
+}
@@ -0,0 +1,48 @@
+package filesystem
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"syscall"
+)
+
+// IO priority classes and the ioprio_set target, from linux/ioprio.h
+const (
+	ioprioClassShift  = 13
+	ioprioClassBE     = 2
+	ioprioClassIdle   = 3
+	ioprioWhoProcess  = 1
+	ioprioLowestLevel = 7
+)
+
+// SetIOPriority lowers the IO scheduling class of the process like ionice does: "idle" only
+// gets disk time when no other process wants it, "best-effort" gets the lowest best-effort
+// priority. Linux keeps the priority per thread, so it is set on every thread of the process;
+// threads started later inherit it.
+func SetIOPriority(class string) error {
+	var prio int
+	switch class {
+	case "idle":
+		prio = ioprioClassIdle << ioprioClassShift
+	case "best-effort":
+		prio = ioprioClassBE<<ioprioClassShift | ioprioLowestLevel
+	default:
+		return fmt.Errorf("unknown IO priority class %q", class)
+	}
+
+	tasks, err := os.ReadDir("/proc/self/task")
+	if err != nil {
+		return fmt.Errorf("failed to list threads: %w", err)
+	}
+	for _, task := range tasks {
+		tid, err := strconv.Atoi(task.Name())
+		if err != nil {
+			continue
+		}
+		if _, _, errno := syscall.Syscall(syscall.SYS_IOPRIO_SET, ioprioWhoProcess, uintptr(tid), uintptr(prio)); errno != 0 {
+			return fmt.Errorf("failed to set the IO priority of thread %d: %w", tid, errno)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+
+package filesystem
+
+import "fmt"
+
+// SetIOPriority lowers the IO scheduling class of the process like ionice does. It is only
+// supported on Linux.
+func SetIOPriority(class string) error {
+	return fmt.Errorf("IO priority classes are only supported on Linux")
+}
@@ -0,0 +1,158 @@
+package filesystem
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hnipps/refresharr/internal/arr"
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+// Manifest describes the files a simulated run should treat as present on disk
+type Manifest struct {
+	Files          []ManifestFile `json:"files"`
+	BrokenSymlinks []string       `json:"brokenSymlinks,omitempty"` // Symlinks whose targets are missing
+}
+
+// ManifestFile is a file listed in a manifest
+type ManifestFile struct {
+	Path       string `json:"path"`
+	Size       int64  `json:"size,omitempty"`
+	Unreadable bool   `json:"unreadable,omitempty"`
+	Symlink    bool   `json:"symlink,omitempty"` // A working symlink
+	SHA256     string `json:"sha256,omitempty"`  // Checksum of the contents (unknown when empty)
+}
+
+// ManifestChecker implements the FileChecker interface from a manifest instead of the real
+// filesystem. Paths not listed in the manifest don't exist. Deleting a symlink only removes
+// it from the in-memory manifest.
+type ManifestChecker struct {
+	mu             sync.RWMutex
+	files          map[string]ManifestFile
+	brokenSymlinks map[string]bool
+}
+
+// LoadManifestChecker creates a ManifestChecker from a JSON manifest file
+func LoadManifestChecker(path string) (arr.FileChecker, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file manifest: %w", err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse file manifest %s: %w", path, err)
+	}
+
+	return NewManifestChecker(manifest), nil
+}
+
+// NewManifestChecker creates a ManifestChecker from a manifest
+func NewManifestChecker(manifest Manifest) *ManifestChecker {
+	checker := &ManifestChecker{
+		files:          make(map[string]ManifestFile, len(manifest.Files)),
+		brokenSymlinks: make(map[string]bool, len(manifest.BrokenSymlinks)),
+	}
+	for _, file := range manifest.Files {
+		checker.files[file.Path] = file
+	}
+	for _, path := range manifest.BrokenSymlinks {
+		checker.brokenSymlinks[path] = true
+	}
+	return checker
+}
+
+// FileExists reports whether the manifest lists the file
+func (m *ManifestChecker) FileExists(path string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	_, exists := m.files[path]
+	return exists
+}
+
+// IsReadable reports whether the manifest lists the file and doesn't mark it unreadable
+func (m *ManifestChecker) IsReadable(path string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	file, exists := m.files[path]
+	return exists && !file.Unreadable
+}
+
+// FileSize returns the size listed in the manifest
+func (m *ManifestChecker) FileSize(path string) (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	file, exists := m.files[path]
+	if !exists {
+		return 0, &os.PathError{Op: "stat", Path: path, Err: os.ErrNotExist}
+	}
+	return file.Size, nil
+}
+
+// FileSHA256 returns the checksum listed in the manifest
+func (m *ManifestChecker) FileSHA256(path string) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	file, exists := m.files[path]
+	if !exists {
+		return "", &os.PathError{Op: "open", Path: path, Err: os.ErrNotExist}
+	}
+	if file.SHA256 == "" {
+		return "", fmt.Errorf("no checksum listed for %s", path)
+	}
+	return file.SHA256, nil
+}
+
+// IsSymlink reports whether the manifest lists the path as a working or broken symlink
+func (m *ManifestChecker) IsSymlink(path string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.brokenSymlinks[path] || m.files[path].Symlink
+}
+
+// MissingReason reports broken symlinks listed in the manifest; other paths are simply not found
+func (m *ManifestChecker) MissingReason(path string) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.brokenSymlinks[path] {
+		return models.ReasonBrokenSymlink
+	}
+	return models.ReasonNotFound
+}
+
+// FindBrokenSymlinks returns the manifest's broken symlinks under rootDir with the given extensions
+func (m *ManifestChecker) FindBrokenSymlinks(rootDir string, extensions []string) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	prefix := filepath.Clean(rootDir) + string(filepath.Separator)
+	var brokenSymlinks []string
+	for path := range m.brokenSymlinks {
+		if strings.HasPrefix(path, prefix) && hasTargetExtension(path, extensions) {
+			brokenSymlinks = append(brokenSymlinks, path)
+		}
+	}
+	sort.Strings(brokenSymlinks)
+	return brokenSymlinks, nil
+}
+
+// DeleteSymlink removes a symlink from the manifest
+func (m *ManifestChecker) DeleteSymlink(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.brokenSymlinks[path] {
+		delete(m.brokenSymlinks, path)
+		return nil
+	}
+	if file, exists := m.files[path]; exists && file.Symlink {
+		delete(m.files, path)
+		return nil
+	}
+	return fmt.Errorf("path %s is not a symlink", path)
+}
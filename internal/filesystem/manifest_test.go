@@ -0,0 +1,62 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestManifestChecker(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "files.json")
+	manifest := `{
+  "files": [
+    {"path": "/tv/Show/S01E01.mkv", "size": 1024},
+    {"path": "/tv/Show/S01E02.mkv", "unreadable": true},
+    {"path": "/movies/Link.mkv", "symlink": true}
+  ],
+  "brokenSymlinks": ["/movies/Foo (2020)/Foo.mkv", "/movies/Foo (2020)/Foo.nfo", "/other/Bar.mkv"]
+}`
+	if err := os.WriteFile(path, []byte(manifest), 0644); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+
+	checker, err := LoadManifestChecker(path)
+	if err != nil {
+		t.Fatalf("LoadManifestChecker() failed: %v", err)
+	}
+
+	if !checker.FileExists("/tv/Show/S01E01.mkv") || checker.FileExists("/tv/Show/S01E03.mkv") {
+		t.Error("Expected only listed files to exist")
+	}
+	if !checker.IsReadable("/tv/Show/S01E01.mkv") || checker.IsReadable("/tv/Show/S01E02.mkv") {
+		t.Error("Expected unreadable files to be reported as unreadable")
+	}
+	if size, err := checker.FileSize("/tv/Show/S01E01.mkv"); err != nil || size != 1024 {
+		t.Errorf("Expected size 1024, got %d (err %v)", size, err)
+	}
+	if _, err := checker.FileSize("/tv/Show/S01E03.mkv"); !os.IsNotExist(err) {
+		t.Errorf("Expected a not-exist error for an unlisted file, got %v", err)
+	}
+
+	broken, err := checker.FindBrokenSymlinks("/movies", []string{".mkv"})
+	if err != nil {
+		t.Fatalf("FindBrokenSymlinks() failed: %v", err)
+	}
+	if want := []string{"/movies/Foo (2020)/Foo.mkv"}; !reflect.DeepEqual(broken, want) {
+		t.Errorf("Expected %v, got %v", want, broken)
+	}
+
+	if !checker.IsSymlink("/movies/Link.mkv") || checker.IsSymlink("/tv/Show/S01E01.mkv") {
+		t.Error("Expected only symlinks to be reported as symlinks")
+	}
+	if err := checker.DeleteSymlink("/movies/Foo (2020)/Foo.mkv"); err != nil {
+		t.Errorf("DeleteSymlink() failed: %v", err)
+	}
+	if err := checker.DeleteSymlink("/tv/Show/S01E01.mkv"); err == nil {
+		t.Error("Expected an error deleting a regular file")
+	}
+	if broken, _ := checker.FindBrokenSymlinks("/movies", nil); len(broken) != 1 {
+		t.Errorf("Expected the deleted symlink to be gone, got %v", broken)
+	}
+}
@@ -0,0 +1,42 @@
+package filesystem
+
+import (
+	"sync"
+	"time"
+)
+
+// pacer spaces filesystem operations out evenly so scans stay under a maximum rate. A nil
+// pacer doesn't wait.
+type pacer struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// newPacer creates a pacer allowing opsPerSecond operations per second, or nil when
+// opsPerSecond is 0 or less
+func newPacer(opsPerSecond int) *pacer {
+	if opsPerSecond <= 0 {
+		return nil
+	}
+	return &pacer{interval: time.Second / time.Duration(opsPerSecond)}
+}
+
+// wait blocks until the next operation is allowed. Concurrent callers are given consecutive
+// slots, so the rate holds across goroutines.
+func (p *pacer) wait() {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	now := time.Now()
+	if p.next.Before(now) {
+		p.next = now
+	}
+	slot := p.next
+	p.next = slot.Add(p.interval)
+	p.mu.Unlock()
+
+	time.Sleep(time.Until(slot))
+}
@@ -0,0 +1,85 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hnipps/refresharr/internal/arr"
+)
+
+func TestPacer_Wait(t *testing.T) {
+	// Unlimited pacing never waits
+	unlimited := newPacer(0)
+	start := time.Now()
+	for i := 0; i < 1000; i++ {
+		unlimited.wait()
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("Unlimited pacer waited %s", elapsed)
+	}
+
+	// 100 operations per second spaces 11 concurrent operations at least 100ms apart in total
+	p := newPacer(100)
+	start = time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 11; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.wait()
+		}()
+	}
+	wg.Wait()
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("Expected 11 operations at 100/s to take at least 100ms, took %s", elapsed)
+	}
+}
+
+func TestPacedFileSystemChecker_FindBrokenSymlinks(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tempDir, "Movie (2020)"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	broken := filepath.Join(tempDir, "Movie (2020)", "movie.mkv")
+	if err := os.Symlink(filepath.Join(tempDir, "missing.mkv"), broken); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	// The root, one folder and one symlink are 4 operations: 30ms at 100/s
+	checker := NewPacedFileSystemChecker(100)
+	start := time.Now()
+	symlinks, err := checker.FindBrokenSymlinks(tempDir, []string{".mkv"})
+	if err != nil {
+		t.Fatalf("FindBrokenSymlinks() failed: %v", err)
+	}
+	if len(symlinks) != 1 || symlinks[0] != broken {
+		t.Errorf("Expected [%s], got %v", broken, symlinks)
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("Expected the paced scan to take at least 30ms, took %s", elapsed)
+	}
+}
+
+func TestFileSystemChecker_FileOps(t *testing.T) {
+	tempDir := t.TempDir()
+	path := filepath.Join(tempDir, "movie.mkv")
+	if err := os.WriteFile(path, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	checker := NewFileSystemChecker()
+	counter, ok := checker.(arr.FileOpCounter)
+	if !ok {
+		t.Fatal("FileSystemChecker should count its filesystem calls")
+	}
+
+	// A stat, then a stat and an open
+	checker.FileExists(path)
+	checker.IsReadable(path)
+	if ops := counter.FileOps(); ops != 3 {
+		t.Errorf("Expected 3 filesystem calls, got %d", ops)
+	}
+}
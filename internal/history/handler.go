@@ -0,0 +1,141 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/hnipps/refresharr/internal/report"
+)
+
+// NewHandler returns an HTTP handler exposing the run history:
+//
+//	GET /api/runs               list runs, newest first (?service=sonarr&limit=10); missing and recovered files are left out
+//	GET /api/runs/{id}          show a single run
+//	GET /api/runs/{id}/report   fetch the run's MissingFilesReport; {id} may be "latest" (?service=sonarr)
+func NewHandler(store *Store) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /api/runs", func(w http.ResponseWriter, r *http.Request) {
+		limit := 0
+		if value := r.URL.Query().Get("limit"); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid limit: " + value})
+				return
+			}
+			limit = n
+		}
+
+		runs, err := newestFirst(store, r.URL.Query().Get("service"))
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		if limit > 0 && len(runs) > limit {
+			runs = runs[:limit]
+		}
+
+		// The file lists can be long; they're in the run's report and in GET /api/runs/{id}
+		for i := range runs {
+			runs[i].MissingFiles = nil
+			runs[i].Recovered = nil
+		}
+		writeJSON(w, http.StatusOK, runs)
+	})
+
+	mux.HandleFunc("GET /api/runs/{id}", func(w http.ResponseWriter, r *http.Request) {
+		run, err := store.Get(r.PathValue("id"))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, run)
+	})
+
+	mux.HandleFunc("GET /api/runs/{id}/report", func(w http.ResponseWriter, r *http.Request) {
+		var run *Run
+		var err error
+		if id := r.PathValue("id"); id == "latest" {
+			run, err = latestWithReport(store, r.URL.Query().Get("service"))
+		} else {
+			run, err = store.Get(id)
+		}
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		if run.ReportPath == "" {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "run has no report"})
+			return
+		}
+
+		// Old reports may have been gzipped since, which OpenReport reads through
+		f, err := report.OpenReport(run.ReportPath)
+		if os.IsNotExist(err) {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "report file no longer exists: " + run.ReportPath})
+			return
+		}
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to open report: " + err.Error()})
+			return
+		}
+		defer f.Close()
+
+		// Reports are already JSON, and can be large, so they're streamed as they are
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.Copy(w, f)
+	})
+
+	return mux
+}
+
+// newestFirst returns the recorded runs, newest first, limited to service unless it's empty
+func newestFirst(store *Store, service string) ([]Run, error) {
+	runs, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	selected := make([]Run, 0, len(runs))
+	for i := len(runs) - 1; i >= 0; i-- {
+		if service == "" || runs[i].Service == service {
+			selected = append(selected, runs[i])
+		}
+	}
+	return selected, nil
+}
+
+// latestWithReport returns the most recent run of service, or of any service if it's empty,
+// that saved a report
+func latestWithReport(store *Store, service string) (*Run, error) {
+	runs, err := newestFirst(store, service)
+	if err != nil {
+		return nil, err
+	}
+	for i := range runs {
+		if runs[i].ReportPath != "" {
+			return &runs[i], nil
+		}
+	}
+	return nil, ErrRunNotFound
+}
+
+// writeError writes err as a JSON response, with 404 for runs that aren't in the history
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	if errors.Is(err, ErrRunNotFound) {
+		status = http.StatusNotFound
+	}
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// writeJSON writes v as a JSON response with the given status code
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
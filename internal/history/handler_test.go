@@ -0,0 +1,105 @@
+package history
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+func TestHandler(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStore(dir)
+
+	reportPath := filepath.Join(dir, "sonarr-missing-files-report.json")
+	if err := os.WriteFile(reportPath, []byte(`{"serviceType":"sonarr","missingFiles":[]}`), 0644); err != nil {
+		t.Fatalf("Failed to write report: %v", err)
+	}
+
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	first := &Run{Command: "cleanup", Service: "sonarr", StartedAt: base, ReportPath: reportPath,
+		MissingFiles: []models.MissingFileEntry{{FilePath: "/tv/Show/S01E01.mkv"}}}
+	second := &Run{Command: "cleanup", Service: "radarr", StartedAt: base.Add(time.Hour)}
+	for _, run := range []*Run{first, second} {
+		if err := store.Append(run); err != nil {
+			t.Fatalf("Append() failed: %v", err)
+		}
+	}
+
+	server := httptest.NewServer(NewHandler(store))
+	defer server.Close()
+
+	get := func(path string, wantStatus int) []byte {
+		t.Helper()
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != wantStatus {
+			t.Errorf("GET %s: expected status %d, got %d (%s)", path, wantStatus, resp.StatusCode, body)
+		}
+		return body
+	}
+
+	var runs []Run
+	if err := json.Unmarshal(get("/api/runs", http.StatusOK), &runs); err != nil {
+		t.Fatalf("Failed to decode runs: %v", err)
+	}
+	if len(runs) != 2 || runs[0].ID != second.ID || runs[1].ID != first.ID {
+		t.Fatalf("Expected both runs newest first, got %+v", runs)
+	}
+	if runs[1].MissingFiles != nil {
+		t.Error("Expected missing files to be left out of the list")
+	}
+
+	runs = nil
+	if err := json.Unmarshal(get("/api/runs?service=sonarr&limit=1", http.StatusOK), &runs); err != nil {
+		t.Fatalf("Failed to decode runs: %v", err)
+	}
+	if len(runs) != 1 || runs[0].ID != first.ID {
+		t.Errorf("Expected just the sonarr run, got %+v", runs)
+	}
+	get("/api/runs?limit=0", http.StatusBadRequest)
+
+	var run Run
+	if err := json.Unmarshal(get("/api/runs/"+first.ID, http.StatusOK), &run); err != nil {
+		t.Fatalf("Failed to decode run: %v", err)
+	}
+	if len(run.MissingFiles) != 1 {
+		t.Errorf("Expected the single run to include its missing files, got %+v", run)
+	}
+	get("/api/runs/missing", http.StatusNotFound)
+
+	var report models.MissingFilesReport
+	if err := json.Unmarshal(get("/api/runs/"+first.ID+"/report", http.StatusOK), &report); err != nil {
+		t.Fatalf("Failed to decode report: %v", err)
+	}
+	if report.ServiceType != "sonarr" {
+		t.Errorf("Expected the sonarr report, got %+v", report)
+	}
+
+	// The latest run without a report is passed over
+	report = models.MissingFilesReport{}
+	if err := json.Unmarshal(get("/api/runs/latest/report", http.StatusOK), &report); err != nil {
+		t.Fatalf("Failed to decode report: %v", err)
+	}
+	if report.ServiceType != "sonarr" {
+		t.Errorf("Expected the latest report to be sonarr's, got %+v", report)
+	}
+	get("/api/runs/latest/report?service=radarr", http.StatusNotFound)
+	get("/api/runs/"+second.ID+"/report", http.StatusNotFound)
+
+	// Reports deleted since the run are reported as missing
+	if err := os.Remove(reportPath); err != nil {
+		t.Fatalf("Failed to remove report: %v", err)
+	}
+	get("/api/runs/"+first.ID+"/report", http.StatusNotFound)
+}
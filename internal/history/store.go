@@ -0,0 +1,513 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hnipps/refresharr/internal/report"
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+// fileName is the name of the history file inside the state directory
+const fileName = "history.jsonl"
+
+// MaxMissingFiles is the number of missing files recorded with a run. The run's stats keep the
+// full count, and its report lists them all. Runs that found more are marked truncated.
+const MaxMissingFiles = 1000
+
+// ErrRunNotFound is returned when a run ID is not in the history
+var ErrRunNotFound = errors.New("run not found")
+
+// Logger defines the interface for logging operations
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// Run is a single recorded run for one service
+type Run struct {
+	ID           string                    `json:"id"`
+	Command      string                    `json:"command"` // e.g. "cleanup"
+	Service      string                    `json:"service"` // "sonarr", "radarr" or "readarr"
+	DryRun       bool                      `json:"dryRun"`
+	Success      bool                      `json:"success"`
+	Error        string                    `json:"error,omitempty"`
+	StartedAt    time.Time                 `json:"startedAt"`
+	FinishedAt   time.Time                 `json:"finishedAt"`
+	Stats        models.CleanupStats       `json:"stats"`
+	ReportPath   string                    `json:"reportPath,omitempty"`
+	CrashReport  string                    `json:"crashReport,omitempty"` // Written when the run panicked
+	MissingFiles []models.MissingFileEntry `json:"missingFiles,omitempty"`
+
+	// MissingFilesTruncated is set when only some of the run's missing files were recorded: the
+	// first MaxMissingFiles, or none when its report spilled them to disk
+	MissingFilesTruncated bool `json:"missingFilesTruncated,omitempty"`
+
+	// Recovered lists the files earlier runs found missing that this run found valid again
+	Recovered []models.RecoveredFileEntry `json:"recovered,omitempty"`
+
+	// SinkFailures lists the reports and alerts of the run that couldn't be delivered. They
+	// don't fail the run.
+	SinkFailures []models.SinkFailure `json:"sinkFailures,omitempty"`
+}
+
+// Truncated reports whether only some of the missing files the run found are recorded with it.
+// A report entry can cover several files of one movie, so the stats can't tell.
+func (r Run) Truncated() bool {
+	return r.MissingFilesTruncated
+}
+
+// Duration returns how long the run took
+func (r Run) Duration() time.Duration {
+	return r.FinishedAt.Sub(r.StartedAt)
+}
+
+// Result returns the run in the shape every command's result shares
+func (r *Run) Result() *models.RunResult {
+	return &models.RunResult{
+		Command:    r.Command,
+		RunID:      r.ID,
+		Service:    r.Service,
+		DryRun:     r.DryRun,
+		Success:    r.Success,
+		Error:      r.Error,
+		StartedAt:  r.StartedAt,
+		FinishedAt: r.FinishedAt,
+		Stats:      r.Stats,
+		ReportPath: r.ReportPath,
+		Output:     r,
+	}
+}
+
+// Store is an append-only run history kept as one JSON object per line. Writes take a lock on
+// a file next to it, so processes sharing the state directory don't lose each other's runs.
+type Store struct {
+	path   string
+	logger Logger // nil skips damaged lines silently
+	mu     sync.Mutex
+}
+
+// NewStore creates a history store inside the given state directory
+func NewStore(stateDir string) *Store {
+	return &Store{path: filepath.Join(stateDir, fileName)}
+}
+
+// NewStoreWithLogger creates a history store that warns about damaged lines on logger
+func NewStoreWithLogger(stateDir string, logger Logger) *Store {
+	return &Store{path: filepath.Join(stateDir, fileName), logger: logger}
+}
+
+// Path returns the location of the history file
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Append records a run, assigning an ID if it has none. Only the first MaxMissingFiles of its
+// missing files are recorded, and the run is marked truncated when there were more.
+func (s *Store) Append(run *Run) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if run.ID == "" {
+		run.ID = NewRunID()
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+	unlock, err := lockFile(s.path + ".lock")
+	if err != nil {
+		return fmt.Errorf("failed to lock history file: %w", err)
+	}
+	defer unlock()
+
+	recorded := *run
+	if len(recorded.MissingFiles) > MaxMissingFiles {
+		recorded.MissingFiles = recorded.MissingFiles[:MaxMissingFiles]
+		recorded.MissingFilesTruncated = true
+	}
+	data, err := json.Marshal(recorded)
+	if err != nil {
+		return fmt.Errorf("failed to marshal run: %w", err)
+	}
+
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer f.Close()
+
+	// A write cut short, e.g. by a crash, leaves a line without its newline; start on a new
+	// line so the run isn't lost with it
+	if info, err := f.Stat(); err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := f.ReadAt(last, info.Size()-1); err == nil && last[0] != '\n' {
+			data = append([]byte{'\n'}, data...)
+		}
+	}
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+	return nil
+}
+
+// Prune drops the runs that started before cutoff, and the lines that can't be read, by
+// rewriting the history file. It returns the number of runs dropped.
+func (s *Store) Prune(cutoff time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := os.Stat(s.path); os.IsNotExist(err) {
+		return 0, nil
+	}
+	// Another process appending while the file is rewritten would lose its run
+	unlock, err := lockFile(s.path + ".lock")
+	if err != nil {
+		return 0, fmt.Errorf("failed to lock history file: %w", err)
+	}
+	defer unlock()
+
+	runs, err := s.listLocked()
+	if err != nil {
+		return 0, err
+	}
+
+	kept := Since(runs, cutoff)
+	var buf bytes.Buffer
+	for _, run := range kept {
+		data, err := json.Marshal(run)
+		if err != nil {
+			return 0, fmt.Errorf("failed to marshal run: %w", err)
+		}
+		buf.Write(append(data, '\n'))
+	}
+
+	// Replace the file atomically, so a crash never leaves half a history
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, buf.Bytes(), 0644); err != nil {
+		return 0, fmt.Errorf("failed to write history file: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		os.Remove(tmpPath)
+		return 0, fmt.Errorf("failed to replace history file: %w", err)
+	}
+	return len(runs) - len(kept), nil
+}
+
+// List returns all recorded runs, oldest first. A missing history file yields no runs. Lines
+// that can't be decoded, such as one cut short by a crash, are skipped with a warning.
+func (s *Store) List() ([]Run, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.listLocked()
+}
+
+// listLocked reads the history file for List; the caller holds s.mu
+func (s *Store) listLocked() ([]Run, error) {
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer f.Close()
+
+	var runs []Run
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var run Run
+		if err := json.Unmarshal([]byte(line), &run); err != nil {
+			if s.logger != nil {
+				s.logger.Warn("Skipping damaged line %d of %s: %s", lineNum, s.path, err.Error())
+			}
+			continue
+		}
+		runs = append(runs, run)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[i].StartedAt.Before(runs[j].StartedAt)
+	})
+	return runs, nil
+}
+
+// Get returns a single run by ID
+func (s *Store) Get(id string) (*Run, error) {
+	runs, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range runs {
+		if runs[i].ID == id {
+			return &runs[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrRunNotFound, id)
+}
+
+// Previous returns the most recent run of the same command and service that started before run
+func (s *Store) Previous(run *Run) (*Run, error) {
+	runs, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var previous *Run
+	for i := range runs {
+		candidate := &runs[i]
+		if candidate.ID == run.ID || candidate.Service != run.Service || candidate.Command != run.Command {
+			continue
+		}
+		if candidate.StartedAt.Before(run.StartedAt) {
+			previous = candidate
+		}
+	}
+	return previous, nil
+}
+
+// Unrecovered returns the files runs of the service found missing that no later run has found
+// valid again, each with the entry of the run that first found it missing. truncated is set
+// when one of those runs is truncated, so files past its recorded ones are left out.
+func (s *Store) Unrecovered(service string) (unrecovered []models.MissingFileEntry, truncated bool, err error) {
+	runs, err := s.List()
+	if err != nil {
+		return nil, false, err
+	}
+
+	var keys []string
+	outstanding := make(map[string]models.MissingFileEntry)
+	for _, run := range runs {
+		if run.Service != service {
+			continue
+		}
+		truncated = truncated || run.Truncated()
+		for _, entry := range run.MissingFiles {
+			key := entry.RecoveryKey()
+			if _, seen := outstanding[key]; key == "" || seen {
+				continue
+			}
+			outstanding[key] = entry
+			keys = append(keys, key)
+		}
+		for _, recovered := range run.Recovered {
+			delete(outstanding, recoveryKeyOf(recovered))
+		}
+	}
+
+	for _, key := range keys {
+		if entry, ok := outstanding[key]; ok {
+			unrecovered = append(unrecovered, entry)
+			delete(outstanding, key)
+		}
+	}
+	return unrecovered, truncated, nil
+}
+
+// recoveryKeyOf returns the recovery key of the missing file entry a recovered file came from
+func recoveryKeyOf(recovered models.RecoveredFileEntry) string {
+	return models.MissingFileEntry{
+		MediaType: recovered.MediaType,
+		MediaName: recovered.MediaName,
+		Season:    recovered.Season,
+		Episode:   recovered.Episode,
+		TMDBID:    recovered.TMDBID,
+	}.RecoveryKey()
+}
+
+// Delta describes how the missing files changed between two runs
+type Delta struct {
+	New      []models.MissingFileEntry // Missing now but not in the previous run
+	Resolved []models.MissingFileEntry // Missing in the previous run but not now
+
+	// Incomplete is set when either of two runs is truncated. New and Resolved are then left empty, as
+	// files past the recorded ones would show up as new or resolved when they are neither. See
+	// WithReportedMissingFiles for completing the runs first.
+	Incomplete bool
+}
+
+// ComputeDelta compares the missing files of two runs by file path. A nil previous run
+// treats every missing file as new.
+func ComputeDelta(previous, current *Run) Delta {
+	var delta Delta
+	if previous != nil && (previous.Truncated() || current.Truncated()) {
+		delta.Incomplete = true
+		return delta
+	}
+
+	before := make(map[string]bool)
+	if previous != nil {
+		for _, entry := range previous.MissingFiles {
+			before[entry.FilePath] = true
+		}
+	}
+
+	now := make(map[string]bool)
+	for _, entry := range current.MissingFiles {
+		now[entry.FilePath] = true
+		if !before[entry.FilePath] {
+			delta.New = append(delta.New, entry)
+		}
+	}
+
+	if previous != nil {
+		for _, entry := range previous.MissingFiles {
+			if !now[entry.FilePath] {
+				delta.Resolved = append(delta.Resolved, entry)
+			}
+		}
+	}
+
+	return delta
+}
+
+// WithReportedMissingFiles returns the run with all the missing files its saved report lists,
+// so the delta against it is complete. Only truncated runs need it: those that found more
+// than MaxMissingFiles, or spilled their entries to disk and recorded none. The run is returned
+// as it is when its report is gone or isn't JSON.
+func WithReportedMissingFiles(run *Run) *Run {
+	if run == nil || !run.Truncated() || run.ReportPath == "" {
+		return run
+	}
+	f, err := report.OpenReport(run.ReportPath)
+	if err != nil {
+		return run
+	}
+	defer f.Close()
+
+	var saved struct {
+		MissingFiles []models.MissingFileEntry `json:"missingFiles"`
+	}
+	if err := json.NewDecoder(f).Decode(&saved); err != nil {
+		return run
+	}
+	complete := *run
+	complete.MissingFiles = saved.MissingFiles
+	complete.MissingFilesTruncated = false
+	return &complete
+}
+
+// Since filters runs to those started at or after the cutoff
+func Since(runs []Run, cutoff time.Time) []Run {
+	filtered := make([]Run, 0, len(runs))
+	for _, run := range runs {
+		if !run.StartedAt.Before(cutoff) {
+			filtered = append(filtered, run)
+		}
+	}
+	return filtered
+}
+
+// ServiceSummary aggregates runs for a single service
+type ServiceSummary struct {
+	Runs           int
+	Successful     int
+	Failed         int
+	DryRuns        int
+	MissingFiles   int
+	DeletedRecords int
+	Errors         int
+	TotalDuration  time.Duration
+	Recovered      int           // Recovered files with known times to recovery
+	TotalRecovery  time.Duration // Summed time those files were missing
+}
+
+// MeanTimeToRecovery returns how long recovered files were missing on average, 0 when none were recovered
+func (s *ServiceSummary) MeanTimeToRecovery() time.Duration {
+	if s.Recovered == 0 {
+		return 0
+	}
+	return s.TotalRecovery / time.Duration(s.Recovered)
+}
+
+// Summarize aggregates runs per service
+func Summarize(runs []Run) map[string]*ServiceSummary {
+	summaries := make(map[string]*ServiceSummary)
+	for _, run := range runs {
+		summary, exists := summaries[run.Service]
+		if !exists {
+			summary = &ServiceSummary{}
+			summaries[run.Service] = summary
+		}
+
+		summary.Runs++
+		if run.Success {
+			summary.Successful++
+		} else {
+			summary.Failed++
+		}
+		if run.DryRun {
+			summary.DryRuns++
+		}
+		summary.MissingFiles += run.Stats.MissingFiles
+		summary.DeletedRecords += run.Stats.DeletedRecords
+		summary.Errors += run.Stats.Errors
+		summary.TotalDuration += run.Duration()
+		if mttr, n := models.MeanTimeToRecovery(run.Recovered); n > 0 {
+			summary.Recovered += n
+			summary.TotalRecovery += mttr * time.Duration(n)
+		}
+	}
+	return summaries
+}
+
+// ParseSince parses a lookback window such as "30d", "12h" or "1w"
+func ParseSince(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, fmt.Errorf("empty duration")
+	}
+
+	unit := value[len(value)-1]
+	if unit == 'd' || unit == 'w' {
+		n, err := strconv.Atoi(value[:len(value)-1])
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		days := n
+		if unit == 'w' {
+			days = n * 7
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q: %w", value, err)
+	}
+	return d, nil
+}
+
+// NewRunID returns a sortable, unique run identifier
+func NewRunID() string {
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return fmt.Sprintf("%s-%s", time.Now().UTC().Format("20060102T150405"), hex.EncodeToString(suffix))
+}
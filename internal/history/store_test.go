@@ -0,0 +1,432 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+func TestStore_AppendAndList(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	first := &Run{Command: "cleanup", Service: "sonarr", StartedAt: base, FinishedAt: base.Add(time.Minute), Success: true}
+	second := &Run{Command: "cleanup", Service: "radarr", StartedAt: base.Add(time.Hour), FinishedAt: base.Add(time.Hour + time.Minute)}
+
+	// Append out of order to make sure List sorts by start time
+	if err := store.Append(second); err != nil {
+		t.Fatalf("Append() failed: %v", err)
+	}
+	if err := store.Append(first); err != nil {
+		t.Fatalf("Append() failed: %v", err)
+	}
+
+	if first.ID == "" || second.ID == "" {
+		t.Fatal("Expected Append to assign run IDs")
+	}
+
+	runs, err := store.List()
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if len(runs) != 2 {
+		t.Fatalf("Expected 2 runs, got %d", len(runs))
+	}
+	if runs[0].ID != first.ID || runs[1].ID != second.ID {
+		t.Errorf("Expected runs sorted by start time, got %s then %s", runs[0].ID, runs[1].ID)
+	}
+	if runs[0].Duration() != time.Minute {
+		t.Errorf("Expected duration 1m, got %s", runs[0].Duration())
+	}
+}
+
+func TestStore_ListMissingFile(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	runs, err := store.List()
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if len(runs) != 0 {
+		t.Errorf("Expected no runs, got %d", len(runs))
+	}
+}
+
+// mockLogger counts the warnings it gets
+type mockLogger struct {
+	warnings int
+}
+
+func (m *mockLogger) Debug(msg string, args ...interface{}) {}
+func (m *mockLogger) Info(msg string, args ...interface{})  {}
+func (m *mockLogger) Warn(msg string, args ...interface{})  { m.warnings++ }
+func (m *mockLogger) Error(msg string, args ...interface{}) {}
+
+func TestStore_ListCorruptLine(t *testing.T) {
+	logger := &mockLogger{}
+	store := NewStoreWithLogger(t.TempDir(), logger)
+	if err := store.Append(&Run{Service: "sonarr"}); err != nil {
+		t.Fatalf("Append() failed: %v", err)
+	}
+
+	// A line cut short by a crash, without its newline
+	f, err := os.OpenFile(store.Path(), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("Failed to open history file: %v", err)
+	}
+	f.WriteString(`{"id":"cut-short","serv`)
+	f.Close()
+
+	if err := store.Append(&Run{Service: "radarr"}); err != nil {
+		t.Fatalf("Append() failed: %v", err)
+	}
+
+	runs, err := store.List()
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if len(runs) != 2 || runs[0].Service != "sonarr" || runs[1].Service != "radarr" {
+		t.Errorf("Expected the runs around the damaged line, got %+v", runs)
+	}
+	if logger.warnings != 1 {
+		t.Errorf("Expected 1 warning about the damaged line, got %d", logger.warnings)
+	}
+}
+
+func TestStore_AppendCapsMissingFiles(t *testing.T) {
+	store := NewStore(t.TempDir())
+	run := &Run{Service: "sonarr", Stats: models.CleanupStats{MissingFiles: MaxMissingFiles + 5}}
+	for i := 0; i < MaxMissingFiles+5; i++ {
+		run.MissingFiles = append(run.MissingFiles, models.MissingFileEntry{FilePath: fmt.Sprintf("/tv/show/episode-%d.mkv", i)})
+	}
+	if err := store.Append(run); err != nil {
+		t.Fatalf("Append() failed: %v", err)
+	}
+	if len(run.MissingFiles) != MaxMissingFiles+5 {
+		t.Error("Append should leave the run itself alone")
+	}
+
+	runs, err := store.List()
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if len(runs[0].MissingFiles) != MaxMissingFiles || runs[0].Stats.MissingFiles != MaxMissingFiles+5 {
+		t.Errorf("Expected %d recorded missing files of %d, got %d of %d", MaxMissingFiles, MaxMissingFiles+5, len(runs[0].MissingFiles), runs[0].Stats.MissingFiles)
+	}
+	if !runs[0].MissingFilesTruncated || !runs[0].Truncated() {
+		t.Error("Expected the run to be marked truncated")
+	}
+
+	// The truncated run leaves the delta and recovery incomplete
+	next := &Run{Service: "sonarr", MissingFiles: runs[0].MissingFiles[:1], Stats: models.CleanupStats{MissingFiles: 1}}
+	if delta := ComputeDelta(&runs[0], next); !delta.Incomplete || len(delta.New) != 0 || len(delta.Resolved) != 0 {
+		t.Errorf("Expected an incomplete delta with no changes, got %d new, %d resolved", len(delta.New), len(delta.Resolved))
+	}
+	if _, truncated, err := store.Unrecovered("sonarr"); err != nil || !truncated {
+		t.Errorf("Unrecovered() truncated = %v, %v; want true", truncated, err)
+	}
+}
+
+func TestStore_PruneKeepsConcurrentAppends(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// Separate stores stand in for separate processes: only the file lock keeps them apart
+	appender, pruner := NewStore(dir), NewStore(dir)
+	if err := appender.Append(&Run{Service: "sonarr", StartedAt: base}); err != nil {
+		t.Fatalf("Append() failed: %v", err)
+	}
+
+	const appends = 200
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		defer close(done)
+		for i := 0; i < appends; i++ {
+			if err := appender.Append(&Run{Service: "radarr", StartedAt: base.AddDate(0, 1, 0)}); err != nil {
+				t.Errorf("Append() failed: %v", err)
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			if _, err := pruner.Prune(base.AddDate(0, 0, 1)); err != nil {
+				t.Errorf("Prune() failed: %v", err)
+			}
+		}
+	}()
+	wg.Wait()
+
+	runs, err := appender.List()
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	appended := 0
+	for _, run := range runs {
+		if run.Service == "radarr" {
+			appended++
+		}
+	}
+	if appended != appends {
+		t.Errorf("Expected all %d appended runs to survive pruning, got %d", appends, appended)
+	}
+}
+
+func TestStore_Prune(t *testing.T) {
+	store := NewStore(t.TempDir())
+	if removed, err := store.Prune(time.Now()); err != nil || removed != 0 {
+		t.Fatalf("Prune() without history = %d, %v", removed, err)
+	}
+
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		if err := store.Append(&Run{Service: "sonarr", StartedAt: base.AddDate(0, 0, i*30)}); err != nil {
+			t.Fatalf("Append() failed: %v", err)
+		}
+	}
+	f, err := os.OpenFile(store.Path(), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("Failed to open history file: %v", err)
+	}
+	f.WriteString("not json\n")
+	f.Close()
+
+	removed, err := store.Prune(base.AddDate(0, 0, 30))
+	if err != nil {
+		t.Fatalf("Prune() failed: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("Expected 1 run pruned, got %d", removed)
+	}
+	runs, err := store.List()
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if len(runs) != 2 || !runs[0].StartedAt.Equal(base.AddDate(0, 0, 30)) {
+		t.Errorf("Unexpected runs after pruning: %+v", runs)
+	}
+	data, err := os.ReadFile(store.Path())
+	if err != nil {
+		t.Fatalf("Failed to read history file: %v", err)
+	}
+	if strings.Contains(string(data), "not json") {
+		t.Error("Expected Prune to drop the damaged line")
+	}
+}
+
+func TestStore_GetAndPrevious(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	older := &Run{Command: "cleanup", Service: "sonarr", StartedAt: base}
+	other := &Run{Command: "cleanup", Service: "radarr", StartedAt: base.Add(time.Hour)}
+	latest := &Run{Command: "cleanup", Service: "sonarr", StartedAt: base.Add(2 * time.Hour)}
+	for _, run := range []*Run{older, other, latest} {
+		if err := store.Append(run); err != nil {
+			t.Fatalf("Append() failed: %v", err)
+		}
+	}
+
+	got, err := store.Get(latest.ID)
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+
+	previous, err := store.Previous(got)
+	if err != nil {
+		t.Fatalf("Previous() failed: %v", err)
+	}
+	if previous == nil || previous.ID != older.ID {
+		t.Errorf("Expected previous run %s, got %+v", older.ID, previous)
+	}
+
+	first, err := store.Previous(older)
+	if err != nil {
+		t.Fatalf("Previous() failed: %v", err)
+	}
+	if first != nil {
+		t.Errorf("Expected no previous run for the first sonarr run, got %s", first.ID)
+	}
+
+	if _, err := store.Get("missing"); !errors.Is(err, ErrRunNotFound) {
+		t.Errorf("Expected ErrRunNotFound, got %v", err)
+	}
+}
+
+func TestStore_Unrecovered(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	season, first, second := 1, 1, 2
+	pilot := models.MissingFileEntry{MediaType: "series", MediaName: "Show", Season: &season, Episode: &first, FilePath: "/tv/Show/S01E01.mkv", ProcessedAt: "first"}
+	finale := models.MissingFileEntry{MediaType: "series", MediaName: "Show", Season: &season, Episode: &second, FilePath: "/tv/Show/S01E02.mkv"}
+	movie := models.MissingFileEntry{MediaType: "movie", MediaName: "Movie", TMDBID: 603, FilePath: "/movies/Movie.mkv"}
+
+	runs := []*Run{
+		{Command: "cleanup", Service: "sonarr", StartedAt: base, MissingFiles: []models.MissingFileEntry{pilot}},
+		{Command: "verify", Service: "sonarr", StartedAt: base.Add(time.Hour), MissingFiles: []models.MissingFileEntry{
+			{MediaType: "series", MediaName: "Show", Season: &season, Episode: &first, FilePath: "/tv/Show/S01E01.mkv", ProcessedAt: "again"},
+			finale,
+		}},
+		{Command: "cleanup", Service: "radarr", StartedAt: base.Add(2 * time.Hour), MissingFiles: []models.MissingFileEntry{movie}},
+		{Command: "cleanup", Service: "sonarr", StartedAt: base.Add(3 * time.Hour), Recovered: []models.RecoveredFileEntry{
+			{MediaType: "series", MediaName: "Show", Season: &season, Episode: &second, FilePath: "/tv/Show/S01E02 WEBDL.mkv"},
+		}},
+	}
+	for _, run := range runs {
+		if err := store.Append(run); err != nil {
+			t.Fatalf("Append() failed: %v", err)
+		}
+	}
+
+	unrecovered, truncated, err := store.Unrecovered("sonarr")
+	if err != nil {
+		t.Fatalf("Unrecovered() failed: %v", err)
+	}
+	if truncated {
+		t.Error("Expected no truncated runs")
+	}
+	if len(unrecovered) != 1 || unrecovered[0].FilePath != pilot.FilePath || unrecovered[0].ProcessedAt != "first" {
+		t.Errorf("Expected only the pilot, as first found missing, got %+v", unrecovered)
+	}
+}
+
+func TestComputeDelta(t *testing.T) {
+	previous := &Run{MissingFiles: []models.MissingFileEntry{
+		{FilePath: "/tv/a.mkv"},
+		{FilePath: "/tv/b.mkv"},
+	}}
+	current := &Run{MissingFiles: []models.MissingFileEntry{
+		{FilePath: "/tv/b.mkv"},
+		{FilePath: "/tv/c.mkv"},
+	}}
+
+	delta := ComputeDelta(previous, current)
+	if len(delta.New) != 1 || delta.New[0].FilePath != "/tv/c.mkv" {
+		t.Errorf("Expected /tv/c.mkv to be new, got %+v", delta.New)
+	}
+	if len(delta.Resolved) != 1 || delta.Resolved[0].FilePath != "/tv/a.mkv" {
+		t.Errorf("Expected /tv/a.mkv to be resolved, got %+v", delta.Resolved)
+	}
+
+	delta = ComputeDelta(nil, current)
+	if len(delta.New) != 2 || len(delta.Resolved) != 0 {
+		t.Errorf("Expected all files to be new without a previous run, got %+v", delta)
+	}
+}
+
+func TestComputeDelta_SpilledPreviousRun(t *testing.T) {
+	// A run that spilled its missing files records none of them, only its report lists them
+	reportPath := filepath.Join(t.TempDir(), "report.json")
+	saved := `{"service": "sonarr", "missingFiles": [{"filePath": "/tv/a.mkv"}, {"filePath": "/tv/b.mkv"}]}`
+	if err := os.WriteFile(reportPath, []byte(saved), 0644); err != nil {
+		t.Fatalf("Failed to write report: %v", err)
+	}
+	previous := &Run{ID: "spilled", ReportPath: reportPath, MissingFilesTruncated: true, Stats: models.CleanupStats{MissingFiles: 2}}
+	current := &Run{MissingFiles: []models.MissingFileEntry{
+		{FilePath: "/tv/b.mkv"},
+		{FilePath: "/tv/c.mkv"},
+	}}
+
+	delta := ComputeDelta(WithReportedMissingFiles(previous), WithReportedMissingFiles(current))
+	if delta.Incomplete {
+		t.Fatal("Expected the report to complete the delta")
+	}
+	if len(delta.New) != 1 || delta.New[0].FilePath != "/tv/c.mkv" {
+		t.Errorf("Expected only /tv/c.mkv to be new, got %+v", delta.New)
+	}
+	if len(delta.Resolved) != 1 || delta.Resolved[0].FilePath != "/tv/a.mkv" {
+		t.Errorf("Expected /tv/a.mkv to be resolved, got %+v", delta.Resolved)
+	}
+	if !previous.Truncated() || len(previous.MissingFiles) != 0 {
+		t.Error("Expected the recorded run to be left as it is")
+	}
+
+	// Without the report the delta stays incomplete
+	previous.ReportPath = filepath.Join(t.TempDir(), "gone.json")
+	if delta := ComputeDelta(WithReportedMissingFiles(previous), current); !delta.Incomplete {
+		t.Errorf("Expected an incomplete delta without the report, got %+v", delta)
+	}
+}
+
+func TestSinceAndSummarize(t *testing.T) {
+	now := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	runs := []Run{
+		{Service: "sonarr", StartedAt: now.Add(-40 * 24 * time.Hour), Success: true},
+		{Service: "sonarr", StartedAt: now.Add(-2 * 24 * time.Hour), FinishedAt: now.Add(-2*24*time.Hour + time.Minute), Success: true, Stats: models.CleanupStats{MissingFiles: 3, DeletedRecords: 3}},
+		{Service: "radarr", StartedAt: now.Add(-time.Hour), FinishedAt: now, DryRun: true, Stats: models.CleanupStats{MissingFiles: 1, Errors: 1}, Recovered: []models.RecoveredFileEntry{
+			{MissingSince: "2024-01-30T00:00:00Z", RecoveredAt: "2024-01-31T00:00:00Z"},
+			{MissingSince: "2024-01-28T00:00:00Z", RecoveredAt: "2024-01-31T00:00:00Z"},
+		}},
+	}
+
+	recent := Since(runs, now.Add(-30*24*time.Hour))
+	if len(recent) != 2 {
+		t.Fatalf("Expected 2 recent runs, got %d", len(recent))
+	}
+
+	summaries := Summarize(recent)
+	sonarr := summaries["sonarr"]
+	if sonarr == nil || sonarr.Runs != 1 || sonarr.Successful != 1 || sonarr.DeletedRecords != 3 {
+		t.Errorf("Unexpected sonarr summary: %+v", sonarr)
+	}
+	radarr := summaries["radarr"]
+	if radarr == nil || radarr.Failed != 1 || radarr.DryRuns != 1 || radarr.Errors != 1 || radarr.TotalDuration != time.Hour {
+		t.Errorf("Unexpected radarr summary: %+v", radarr)
+	}
+	if radarr != nil && (radarr.Recovered != 2 || radarr.MeanTimeToRecovery() != 48*time.Hour) {
+		t.Errorf("Expected 2 recoveries after 48h on average, got %d after %s", radarr.Recovered, radarr.MeanTimeToRecovery())
+	}
+	if sonarr != nil && sonarr.MeanTimeToRecovery() != 0 {
+		t.Errorf("Expected no time to recovery without recoveries, got %s", sonarr.MeanTimeToRecovery())
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Duration
+		wantErr  bool
+	}{
+		{"30d", 30 * 24 * time.Hour, false},
+		{"2w", 14 * 24 * time.Hour, false},
+		{"12h", 12 * time.Hour, false},
+		{"90m", 90 * time.Minute, false},
+		{"", 0, true},
+		{"xd", 0, true},
+		{"soon", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseSince(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseSince(%q) expected error", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSince(%q) returned error: %v", tt.input, err)
+			}
+			if got != tt.expected {
+				t.Errorf("ParseSince(%q) = %s, expected %s", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package jobs
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// HandlerOptions configures the HTTP handler of a queue
+type HandlerOptions struct {
+	// DryRun is the mode of requests that don't set dryRun. When it is true, every request is
+	// queued as a dry run, so a server started in dry-run mode never makes changes.
+	DryRun bool
+}
+
+// NewHandler returns an HTTP handler exposing the queue:
+//
+//	GET  /api/jobs       list jobs, newest first
+//	POST /api/jobs       queue a run ({"command":"cleanup","service":"sonarr","dryRun":true}); command may also be "verify"
+//	GET  /api/jobs/{id}  show a single job
+//	POST /api/cleanup    queue a cleanup run ({"service":"sonarr","dryRun":true})
+func NewHandler(q *Queue, opts HandlerOptions) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /api/jobs", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, q.List())
+	})
+
+	mux.HandleFunc("POST /api/jobs", func(w http.ResponseWriter, r *http.Request) {
+		req, ok := decodeRequest(w, r, opts)
+		if !ok {
+			return
+		}
+
+		switch req.Command {
+		case "", "cleanup", "verify":
+		default:
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported command: " + req.Command})
+			return
+		}
+
+		enqueue(w, q, req)
+	})
+
+	mux.HandleFunc("POST /api/cleanup", func(w http.ResponseWriter, r *http.Request) {
+		req, ok := decodeRequest(w, r, opts)
+		if !ok {
+			return
+		}
+
+		if req.Command != "" && req.Command != "cleanup" {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported command: " + req.Command + " (use /api/jobs)"})
+			return
+		}
+		req.Command = "cleanup"
+
+		enqueue(w, q, req)
+	})
+
+	mux.HandleFunc("GET /api/jobs/{id}", func(w http.ResponseWriter, r *http.Request) {
+		job, ok := q.Get(r.PathValue("id"))
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "job not found"})
+			return
+		}
+		writeJSON(w, http.StatusOK, job)
+	})
+
+	return mux
+}
+
+// decodeRequest reads the run request in the body, which may be empty, and applies the dry-run
+// mode of opts. It writes an error response and returns false when the request is invalid.
+func decodeRequest(w http.ResponseWriter, r *http.Request, opts HandlerOptions) (Request, bool) {
+	var req Request
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body: " + err.Error()})
+			return req, false
+		}
+	}
+	if req.DryRun == nil || opts.DryRun {
+		dryRun := opts.DryRun
+		req.DryRun = &dryRun
+	}
+
+	switch req.Service {
+	case "", "auto", "sonarr", "radarr", "readarr":
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unknown service: " + req.Service})
+		return req, false
+	}
+	return req, true
+}
+
+// enqueue queues req and responds with the resulting jobs
+func enqueue(w http.ResponseWriter, q *Queue, req Request) {
+	queued, err := q.Enqueue(req)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusAccepted, queued)
+}
+
+// writeJSON writes v as a JSON response with the given status code
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,401 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Job status values
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// maxFinishedJobs is the number of finished jobs kept in the persisted queue file
+const maxFinishedJobs = 100
+
+// Logger defines the interface for logging operations
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// Request describes a run to be queued
+type Request struct {
+	Command string `json:"command"` // e.g. "cleanup"
+	Service string `json:"service"` // "sonarr", "radarr", "readarr" or "auto"
+	DryRun  *bool  `json:"dryRun"`  // nil leaves it to the handler's default, see HandlerOptions
+}
+
+// isDryRun reports whether the request is for a dry run; one that doesn't say is a real run
+func (r Request) isDryRun() bool {
+	return r.DryRun != nil && *r.DryRun
+}
+
+// Job represents a queued, running or finished run
+type Job struct {
+	ID         string     `json:"id"`
+	Command    string     `json:"command"`
+	Service    string     `json:"service"`
+	DryRun     bool       `json:"dryRun"`
+	Status     string     `json:"status"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// Runner executes a single job. Returning an error marks the job as failed.
+type Runner func(ctx context.Context, job Job) error
+
+// Queue is a persistent job queue that runs at most one job per service at a time
+type Queue struct {
+	path     string
+	runner   Runner
+	logger   Logger
+	services []string // services an "auto" request expands to
+
+	mu      sync.Mutex
+	jobs    []*Job
+	workers map[string]chan struct{} // service -> wake-up signal
+	ctx     context.Context
+	wg      sync.WaitGroup
+}
+
+// NewQueue creates a queue persisted at path, restoring any jobs from a previous process.
+// Jobs that were running when the previous process stopped are queued again.
+// Requests for the "auto" service are split into one job per entry in services.
+func NewQueue(path string, services []string, runner Runner, logger Logger) (*Queue, error) {
+	q := &Queue{
+		path:     path,
+		runner:   runner,
+		logger:   logger,
+		services: services,
+		workers:  make(map[string]chan struct{}),
+	}
+
+	if err := q.load(); err != nil {
+		return nil, err
+	}
+
+	return q, nil
+}
+
+// Start launches workers for all services with pending jobs. Workers stop when ctx is cancelled.
+func (q *Queue) Start(ctx context.Context) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.ctx = ctx
+	for _, job := range q.jobs {
+		if job.Status == StatusQueued {
+			q.signalLocked(job.Service)
+		}
+	}
+}
+
+// Wait blocks until all workers have exited
+func (q *Queue) Wait() {
+	q.wg.Wait()
+}
+
+// Enqueue adds a request to the queue and returns the resulting jobs. If an identical
+// request is already waiting, the existing job is returned instead so bursts of triggers
+// collapse into one run.
+func (q *Queue) Enqueue(req Request) ([]Job, error) {
+	if req.Command == "" {
+		req.Command = "cleanup"
+	}
+	if req.Service == "" {
+		req.Service = "auto"
+	}
+
+	services := []string{req.Service}
+	if req.Service == "auto" && len(q.services) > 0 {
+		services = q.services
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var result []Job
+	var added []*Job
+	for _, service := range services {
+		if existing := q.findQueuedLocked(req.Command, service, req.isDryRun()); existing != nil {
+			q.logger.Debug("Coalescing %s request for %s into queued job %s", req.Command, service, existing.ID)
+			result = append(result, *existing)
+			continue
+		}
+
+		job := &Job{
+			ID:        newJobID(),
+			Command:   req.Command,
+			Service:   service,
+			DryRun:    req.isDryRun(),
+			Status:    StatusQueued,
+			CreatedAt: time.Now().UTC(),
+		}
+		q.jobs = append(q.jobs, job)
+		added = append(added, job)
+		result = append(result, *job)
+	}
+
+	if len(added) == 0 {
+		return result, nil
+	}
+
+	if err := q.saveLocked(); err != nil {
+		q.jobs = q.jobs[:len(q.jobs)-len(added)]
+		return nil, err
+	}
+
+	for _, job := range added {
+		q.logger.Info("Queued %s job %s for %s", job.Command, job.ID, job.Service)
+		q.signalLocked(job.Service)
+	}
+	return result, nil
+}
+
+// findQueuedLocked returns a waiting job matching the request, if any. Caller must hold q.mu.
+func (q *Queue) findQueuedLocked(command, service string, dryRun bool) *Job {
+	for _, job := range q.jobs {
+		if job.Status == StatusQueued && job.Command == command && job.Service == service && job.DryRun == dryRun {
+			return job
+		}
+	}
+	return nil
+}
+
+// Get returns a job by ID
+func (q *Queue) Get(id string) (Job, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, job := range q.jobs {
+		if job.ID == id {
+			return *job, true
+		}
+	}
+	return Job{}, false
+}
+
+// List returns all known jobs, newest first
+func (q *Queue) List() []Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	result := make([]Job, 0, len(q.jobs))
+	for i := len(q.jobs) - 1; i >= 0; i-- {
+		result = append(result, *q.jobs[i])
+	}
+	return result
+}
+
+// signalLocked wakes the worker for a service, starting it if needed. Caller must hold q.mu.
+func (q *Queue) signalLocked(service string) {
+	if q.ctx == nil {
+		// Not started yet - Start will pick the job up
+		return
+	}
+
+	wake, exists := q.workers[service]
+	if !exists {
+		wake = make(chan struct{}, 1)
+		q.workers[service] = wake
+		q.wg.Add(1)
+		go q.work(service, wake)
+	}
+
+	select {
+	case wake <- struct{}{}:
+	default:
+	}
+}
+
+// work processes queued jobs for a single service, one at a time
+func (q *Queue) work(service string, wake chan struct{}) {
+	defer q.wg.Done()
+
+	for {
+		select {
+		case <-q.ctx.Done():
+			return
+		case <-wake:
+		}
+
+		for {
+			job := q.claimNext(service)
+			if job == nil {
+				break
+			}
+
+			q.logger.Info("Starting %s job %s for %s", job.Command, job.ID, job.Service)
+			err := q.runner(q.ctx, *job)
+
+			// Leave interrupted jobs queued so they run again after a restart
+			if q.ctx.Err() != nil {
+				q.requeue(job.ID)
+				return
+			}
+
+			q.finish(job.ID, err)
+		}
+	}
+}
+
+// claimNext marks the oldest queued job for a service as running and returns a copy of it
+func (q *Queue) claimNext(service string) *Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, job := range q.jobs {
+		if job.Service == service && job.Status == StatusQueued {
+			now := time.Now().UTC()
+			job.Status = StatusRunning
+			job.StartedAt = &now
+			if err := q.saveLocked(); err != nil {
+				q.logger.Warn("Failed to persist job queue: %s", err.Error())
+			}
+			claimed := *job
+			return &claimed
+		}
+	}
+	return nil
+}
+
+// requeue puts an interrupted job back into the queued state
+func (q *Queue) requeue(id string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, job := range q.jobs {
+		if job.ID == id {
+			job.Status = StatusQueued
+			job.StartedAt = nil
+		}
+	}
+	if err := q.saveLocked(); err != nil {
+		q.logger.Warn("Failed to persist job queue: %s", err.Error())
+	}
+}
+
+// finish records the outcome of a job
+func (q *Queue) finish(id string, runErr error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, job := range q.jobs {
+		if job.ID != id {
+			continue
+		}
+		now := time.Now().UTC()
+		job.FinishedAt = &now
+		if runErr != nil {
+			job.Status = StatusFailed
+			job.Error = runErr.Error()
+			q.logger.Warn("Job %s failed: %s", job.ID, runErr.Error())
+		} else {
+			job.Status = StatusSucceeded
+			q.logger.Info("Job %s completed", job.ID)
+		}
+	}
+
+	q.pruneLocked()
+	if err := q.saveLocked(); err != nil {
+		q.logger.Warn("Failed to persist job queue: %s", err.Error())
+	}
+}
+
+// pruneLocked drops the oldest finished jobs beyond maxFinishedJobs. Caller must hold q.mu.
+func (q *Queue) pruneLocked() {
+	finished := 0
+	for _, job := range q.jobs {
+		if job.FinishedAt != nil {
+			finished++
+		}
+	}
+
+	if finished <= maxFinishedJobs {
+		return
+	}
+
+	toDrop := finished - maxFinishedJobs
+	kept := q.jobs[:0]
+	for _, job := range q.jobs {
+		if job.FinishedAt != nil && toDrop > 0 {
+			toDrop--
+			continue
+		}
+		kept = append(kept, job)
+	}
+	q.jobs = kept
+}
+
+// load restores jobs from disk
+func (q *Queue) load() error {
+	data, err := os.ReadFile(q.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read job queue %s: %w", q.path, err)
+	}
+
+	var jobs []*Job
+	if err := json.Unmarshal(data, &jobs); err != nil {
+		return fmt.Errorf("failed to decode job queue %s: %w", q.path, err)
+	}
+
+	for _, job := range jobs {
+		if job.Status == StatusRunning {
+			job.Status = StatusQueued
+			job.StartedAt = nil
+		}
+	}
+
+	sort.SliceStable(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.Before(jobs[j].CreatedAt)
+	})
+	q.jobs = jobs
+	return nil
+}
+
+// saveLocked writes the queue to disk atomically. Caller must hold q.mu.
+func (q *Queue) saveLocked() error {
+	if err := os.MkdirAll(filepath.Dir(q.path), 0755); err != nil {
+		return fmt.Errorf("failed to create job queue directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(q.jobs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal job queue: %w", err)
+	}
+
+	tmpPath := q.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write job queue: %w", err)
+	}
+	if err := os.Rename(tmpPath, q.path); err != nil {
+		return fmt.Errorf("failed to replace job queue file: %w", err)
+	}
+	return nil
+}
+
+// newJobID returns a sortable, unique job identifier
+func newJobID() string {
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return fmt.Sprintf("%s-%s", time.Now().UTC().Format("20060102T150405"), hex.EncodeToString(suffix))
+}
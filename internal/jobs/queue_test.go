@@ -0,0 +1,472 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// mockLogger implements Logger for testing
+type mockLogger struct{}
+
+func (m *mockLogger) Debug(msg string, args ...interface{}) {}
+func (m *mockLogger) Info(msg string, args ...interface{})  {}
+func (m *mockLogger) Warn(msg string, args ...interface{})  {}
+func (m *mockLogger) Error(msg string, args ...interface{}) {}
+
+// boolPtr returns a pointer to b, for the optional fields of a Request
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// waitForStatus polls the queue until the job reaches the wanted status
+func waitForStatus(t *testing.T, q *Queue, id, status string) Job {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if job, ok := q.Get(id); ok && job.Status == status {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	job, _ := q.Get(id)
+	t.Fatalf("Job %s did not reach status %s (last status %q)", id, status, job.Status)
+	return Job{}
+}
+
+func TestQueue_RunsJobs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.json")
+
+	var mu sync.Mutex
+	var ran []Job
+	runner := func(ctx context.Context, job Job) error {
+		mu.Lock()
+		defer mu.Unlock()
+		ran = append(ran, job)
+		return nil
+	}
+
+	q, err := NewQueue(path, []string{"sonarr"}, runner, &mockLogger{})
+	if err != nil {
+		t.Fatalf("NewQueue returned error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	q.Start(ctx)
+
+	queued, err := q.Enqueue(Request{Service: "sonarr", DryRun: boolPtr(true)})
+	if err != nil {
+		t.Fatalf("Enqueue returned error: %v", err)
+	}
+	if len(queued) != 1 {
+		t.Fatalf("Expected 1 job, got %d", len(queued))
+	}
+	if queued[0].Command != "cleanup" {
+		t.Errorf("Expected default command 'cleanup', got %q", queued[0].Command)
+	}
+
+	job := waitForStatus(t, q, queued[0].ID, StatusSucceeded)
+	if job.StartedAt == nil || job.FinishedAt == nil {
+		t.Error("Expected StartedAt and FinishedAt to be set")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(ran) != 1 || !ran[0].DryRun || ran[0].Service != "sonarr" {
+		t.Errorf("Unexpected runner invocations: %+v", ran)
+	}
+}
+
+func TestQueue_RecordsFailure(t *testing.T) {
+	runner := func(ctx context.Context, job Job) error {
+		return os.ErrPermission
+	}
+
+	q, err := NewQueue(filepath.Join(t.TempDir(), "jobs.json"), nil, runner, &mockLogger{})
+	if err != nil {
+		t.Fatalf("NewQueue returned error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	q.Start(ctx)
+
+	queued, err := q.Enqueue(Request{Service: "radarr"})
+	if err != nil {
+		t.Fatalf("Enqueue returned error: %v", err)
+	}
+
+	job := waitForStatus(t, q, queued[0].ID, StatusFailed)
+	if job.Error == "" {
+		t.Error("Expected job error to be recorded")
+	}
+}
+
+func TestQueue_CoalescesIdenticalRequests(t *testing.T) {
+	q, err := NewQueue(filepath.Join(t.TempDir(), "jobs.json"), nil, func(ctx context.Context, job Job) error {
+		return nil
+	}, &mockLogger{})
+	if err != nil {
+		t.Fatalf("NewQueue returned error: %v", err)
+	}
+
+	// Not started, so jobs stay queued
+	first, err := q.Enqueue(Request{Service: "sonarr"})
+	if err != nil {
+		t.Fatalf("Enqueue returned error: %v", err)
+	}
+	second, err := q.Enqueue(Request{Service: "sonarr"})
+	if err != nil {
+		t.Fatalf("Enqueue returned error: %v", err)
+	}
+	dryRun, err := q.Enqueue(Request{Service: "sonarr", DryRun: boolPtr(true)})
+	if err != nil {
+		t.Fatalf("Enqueue returned error: %v", err)
+	}
+
+	if first[0].ID != second[0].ID {
+		t.Errorf("Expected identical requests to coalesce, got %s and %s", first[0].ID, second[0].ID)
+	}
+	if dryRun[0].ID == first[0].ID {
+		t.Error("Expected dry-run request to get its own job")
+	}
+	if got := len(q.List()); got != 2 {
+		t.Errorf("Expected 2 jobs, got %d", got)
+	}
+}
+
+func TestQueue_AutoExpandsToServices(t *testing.T) {
+	q, err := NewQueue(filepath.Join(t.TempDir(), "jobs.json"), []string{"sonarr", "radarr"}, func(ctx context.Context, job Job) error {
+		return nil
+	}, &mockLogger{})
+	if err != nil {
+		t.Fatalf("NewQueue returned error: %v", err)
+	}
+
+	queued, err := q.Enqueue(Request{})
+	if err != nil {
+		t.Fatalf("Enqueue returned error: %v", err)
+	}
+
+	if len(queued) != 2 {
+		t.Fatalf("Expected 2 jobs, got %d", len(queued))
+	}
+	if queued[0].Service != "sonarr" || queued[1].Service != "radarr" {
+		t.Errorf("Expected sonarr and radarr jobs, got %s and %s", queued[0].Service, queued[1].Service)
+	}
+
+	// A later single-service request coalesces with the expanded job
+	single, err := q.Enqueue(Request{Service: "radarr"})
+	if err != nil {
+		t.Fatalf("Enqueue returned error: %v", err)
+	}
+	if single[0].ID != queued[1].ID {
+		t.Errorf("Expected radarr request to coalesce with %s, got %s", queued[1].ID, single[0].ID)
+	}
+}
+
+func TestQueue_SerializesPerService(t *testing.T) {
+	var mu sync.Mutex
+	running := 0
+	maxRunning := 0
+	runner := func(ctx context.Context, job Job) error {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return nil
+	}
+
+	q, err := NewQueue(filepath.Join(t.TempDir(), "jobs.json"), nil, runner, &mockLogger{})
+	if err != nil {
+		t.Fatalf("NewQueue returned error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	q.Start(ctx)
+
+	first, _ := q.Enqueue(Request{Service: "sonarr"})
+	second, _ := q.Enqueue(Request{Service: "sonarr", DryRun: boolPtr(true)})
+
+	waitForStatus(t, q, first[0].ID, StatusSucceeded)
+	waitForStatus(t, q, second[0].ID, StatusSucceeded)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if maxRunning != 1 {
+		t.Errorf("Expected at most 1 concurrent job per service, got %d", maxRunning)
+	}
+}
+
+func TestQueue_PersistsAcrossRestarts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "jobs.json")
+
+	started := make(chan struct{})
+	runner := func(ctx context.Context, job Job) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	q, err := NewQueue(path, nil, runner, &mockLogger{})
+	if err != nil {
+		t.Fatalf("NewQueue returned error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	q.Start(ctx)
+
+	queued, err := q.Enqueue(Request{Service: "sonarr"})
+	if err != nil {
+		t.Fatalf("Enqueue returned error: %v", err)
+	}
+
+	<-started
+	cancel()
+	q.Wait()
+
+	// Simulate a crash mid-run by marking the persisted job as running
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read queue file: %v", err)
+	}
+	data = []byte(strings.Replace(string(data), `"status": "queued"`, `"status": "running"`, 1))
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("Failed to write queue file: %v", err)
+	}
+
+	done := make(chan string, 1)
+	restored, err := NewQueue(path, nil, func(ctx context.Context, job Job) error {
+		done <- job.ID
+		return nil
+	}, &mockLogger{})
+	if err != nil {
+		t.Fatalf("NewQueue returned error on reload: %v", err)
+	}
+
+	job, ok := restored.Get(queued[0].ID)
+	if !ok {
+		t.Fatal("Expected job to be restored from disk")
+	}
+	if job.Status != StatusQueued {
+		t.Errorf("Expected interrupted job to be queued again, got %s", job.Status)
+	}
+
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	defer cancel2()
+	restored.Start(ctx2)
+
+	select {
+	case id := <-done:
+		if id != queued[0].ID {
+			t.Errorf("Expected job %s to run, got %s", queued[0].ID, id)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Restored job was not run")
+	}
+	waitForStatus(t, restored, queued[0].ID, StatusSucceeded)
+}
+
+func TestQueue_InvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.json")
+	if err := os.WriteFile(path, []byte("not json"), 0644); err != nil {
+		t.Fatalf("Failed to write queue file: %v", err)
+	}
+
+	if _, err := NewQueue(path, nil, nil, &mockLogger{}); err == nil {
+		t.Error("Expected error for invalid queue file")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	q, err := NewQueue(filepath.Join(t.TempDir(), "jobs.json"), []string{"sonarr"}, func(ctx context.Context, job Job) error {
+		return nil
+	}, &mockLogger{})
+	if err != nil {
+		t.Fatalf("NewQueue returned error: %v", err)
+	}
+
+	server := httptest.NewServer(NewHandler(q, HandlerOptions{}))
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/api/jobs", "application/json", strings.NewReader(`{"service":"sonarr","dryRun":true}`))
+	if err != nil {
+		t.Fatalf("POST failed: %v", err)
+	}
+	var queued []Job
+	if err := json.NewDecoder(resp.Body).Decode(&queued); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted {
+		t.Errorf("Expected status 202, got %d", resp.StatusCode)
+	}
+	if len(queued) != 1 || !queued[0].DryRun {
+		t.Fatalf("Unexpected queued jobs: %+v", queued)
+	}
+
+	resp, err = http.Get(server.URL + "/api/jobs/" + queued[0].ID)
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	resp, err = http.Get(server.URL + "/api/jobs/missing")
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", resp.StatusCode)
+	}
+
+	resp, err = http.Post(server.URL+"/api/jobs", "application/json", strings.NewReader(`{"service":"lidarr"}`))
+	if err != nil {
+		t.Fatalf("POST failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for unknown service, got %d", resp.StatusCode)
+	}
+
+	resp, err = http.Get(server.URL + "/api/jobs")
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	var listed []Job
+	if err := json.NewDecoder(resp.Body).Decode(&listed); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	resp.Body.Close()
+	if len(listed) != 1 {
+		t.Errorf("Expected 1 listed job, got %d", len(listed))
+	}
+}
+
+func TestHandler_Cleanup(t *testing.T) {
+	q, err := NewQueue(filepath.Join(t.TempDir(), "jobs.json"), []string{"sonarr", "radarr"}, func(ctx context.Context, job Job) error {
+		return nil
+	}, &mockLogger{})
+	if err != nil {
+		t.Fatalf("NewQueue returned error: %v", err)
+	}
+
+	server := httptest.NewServer(NewHandler(q, HandlerOptions{}))
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/api/cleanup", "application/json", strings.NewReader(`{"service":"radarr","dryRun":true}`))
+	if err != nil {
+		t.Fatalf("POST failed: %v", err)
+	}
+	var queued []Job
+	if err := json.NewDecoder(resp.Body).Decode(&queued); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted {
+		t.Errorf("Expected status 202, got %d", resp.StatusCode)
+	}
+	if len(queued) != 1 || queued[0].Command != "cleanup" || queued[0].Service != "radarr" || !queued[0].DryRun {
+		t.Fatalf("Unexpected queued jobs: %+v", queued)
+	}
+
+	// An empty body queues a cleanup of every service
+	resp, err = http.Post(server.URL+"/api/cleanup", "application/json", nil)
+	if err != nil {
+		t.Fatalf("POST failed: %v", err)
+	}
+	queued = nil
+	if err := json.NewDecoder(resp.Body).Decode(&queued); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	resp.Body.Close()
+	if len(queued) != 2 {
+		t.Errorf("Expected a job per service, got %+v", queued)
+	}
+
+	resp, err = http.Post(server.URL+"/api/cleanup", "application/json", strings.NewReader(`{"command":"verify"}`))
+	if err != nil {
+		t.Fatalf("POST failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for another command, got %d", resp.StatusCode)
+	}
+}
+
+func TestHandler_DryRunServer(t *testing.T) {
+	q, err := NewQueue(filepath.Join(t.TempDir(), "jobs.json"), []string{"sonarr"}, func(ctx context.Context, job Job) error {
+		return nil
+	}, &mockLogger{})
+	if err != nil {
+		t.Fatalf("NewQueue returned error: %v", err)
+	}
+
+	server := httptest.NewServer(NewHandler(q, HandlerOptions{DryRun: true}))
+	defer server.Close()
+
+	// Neither an empty body nor an explicit real run makes a dry-run server change anything
+	for _, body := range []string{"", `{"service":"sonarr"}`, `{"service":"sonarr","dryRun":false}`} {
+		resp, err := http.Post(server.URL+"/api/cleanup", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("POST failed: %v", err)
+		}
+		var queued []Job
+		if err := json.NewDecoder(resp.Body).Decode(&queued); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		resp.Body.Close()
+		if len(queued) != 1 || !queued[0].DryRun {
+			t.Errorf("Body %q: expected a dry-run job, got %+v", body, queued)
+		}
+	}
+}
+
+func TestHandler_DefaultsToRealRun(t *testing.T) {
+	q, err := NewQueue(filepath.Join(t.TempDir(), "jobs.json"), []string{"sonarr"}, func(ctx context.Context, job Job) error {
+		return nil
+	}, &mockLogger{})
+	if err != nil {
+		t.Fatalf("NewQueue returned error: %v", err)
+	}
+
+	server := httptest.NewServer(NewHandler(q, HandlerOptions{}))
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/api/cleanup", "application/json", nil)
+	if err != nil {
+		t.Fatalf("POST failed: %v", err)
+	}
+	var queued []Job
+	if err := json.NewDecoder(resp.Body).Decode(&queued); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	resp.Body.Close()
+	if len(queued) != 1 || queued[0].DryRun {
+		t.Errorf("Expected a real run, got %+v", queued)
+	}
+}
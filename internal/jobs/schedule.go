@@ -0,0 +1,55 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ParseTimeOfDay parses a daily schedule time in 24-hour HH:MM form
+func ParseTimeOfDay(value string) (hour, minute int, err error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid time of day %q: expected HH:MM", value)
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+// NextDaily returns the first time strictly after now that falls on hour:minute in now's location
+func NextDaily(now time.Time, hour, minute int) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// ScheduleDaily queues req every day at the given local time of day (HH:MM) until ctx is
+// cancelled. Runs missed while the process was down are not made up.
+func (q *Queue) ScheduleDaily(ctx context.Context, at string, req Request) error {
+	hour, minute, err := ParseTimeOfDay(at)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		for {
+			next := NextDaily(time.Now(), hour, minute)
+			q.logger.Info("⏰ Next scheduled %s run at %s", req.Command, next.Format("2006-01-02 15:04"))
+
+			timer := time.NewTimer(time.Until(next))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+
+			if _, err := q.Enqueue(req); err != nil {
+				q.logger.Error("Failed to queue scheduled %s run: %s", req.Command, err.Error())
+			}
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimeOfDay(t *testing.T) {
+	hour, minute, err := ParseTimeOfDay("03:30")
+	if err != nil {
+		t.Fatalf("ParseTimeOfDay returned error: %v", err)
+	}
+	if hour != 3 || minute != 30 {
+		t.Errorf("Expected 3:30, got %d:%d", hour, minute)
+	}
+
+	for _, value := range []string{"", "3am", "25:00", "12:60"} {
+		if _, _, err := ParseTimeOfDay(value); err == nil {
+			t.Errorf("Expected error for %q", value)
+		}
+	}
+}
+
+func TestNextDaily(t *testing.T) {
+	loc := time.UTC
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{"later today", time.Date(2024, 3, 10, 1, 0, 0, 0, loc), time.Date(2024, 3, 10, 3, 30, 0, 0, loc)},
+		{"already passed", time.Date(2024, 3, 10, 4, 0, 0, 0, loc), time.Date(2024, 3, 11, 3, 30, 0, 0, loc)},
+		{"exactly now", time.Date(2024, 3, 10, 3, 30, 0, 0, loc), time.Date(2024, 3, 11, 3, 30, 0, 0, loc)},
+		{"end of month", time.Date(2024, 3, 31, 23, 0, 0, 0, loc), time.Date(2024, 4, 1, 3, 30, 0, 0, loc)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NextDaily(tt.now, 3, 30); !got.Equal(tt.want) {
+				t.Errorf("NextDaily(%v) = %v, expected %v", tt.now, got, tt.want)
+			}
+		})
+	}
+}
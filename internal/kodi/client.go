@@ -0,0 +1,281 @@
+package kodi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hnipps/refresharr/internal/arr"
+	"github.com/hnipps/refresharr/internal/config"
+)
+
+// KodiClient implements a client for the Kodi JSON-RPC API
+type KodiClient struct {
+	baseURL    string
+	username   string
+	password   string
+	httpClient *http.Client
+	logger     arr.Logger
+	requestID  atomic.Int64
+
+	moviesOnce sync.Once
+	movies     []KodiMovie // Full movie listing, fetched once per client
+	moviesErr  error
+}
+
+// UniqueIDs maps an ID provider ("tmdb", "imdb", "tvdb") to the item's ID
+type UniqueIDs map[string]string
+
+// KodiMovie represents a movie in the Kodi video library
+type KodiMovie struct {
+	MovieID    int       `json:"movieid"`
+	Title      string    `json:"title"`
+	Year       int       `json:"year"`
+	File       string    `json:"file"`
+	IMDBNumber string    `json:"imdbnumber"` // Default scraper ID; may be an IMDb or TMDB ID
+	UniqueIDs  UniqueIDs `json:"uniqueid"`
+	Available  bool      `json:"-"` // Computed field
+}
+
+// KodiTVShow represents a TV show in the Kodi video library
+type KodiTVShow struct {
+	TVShowID   int       `json:"tvshowid"`
+	Title      string    `json:"title"`
+	Year       int       `json:"year"`
+	IMDBNumber string    `json:"imdbnumber"`
+	UniqueIDs  UniqueIDs `json:"uniqueid"`
+}
+
+// KodiEpisode represents an episode in the Kodi video library
+type KodiEpisode struct {
+	EpisodeID int    `json:"episodeid"`
+	Title     string `json:"title"`
+	Season    int    `json:"season"`
+	Episode   int    `json:"episode"`
+	File      string `json:"file"`
+}
+
+// rpcRequest is a JSON-RPC 2.0 request
+type rpcRequest struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+	ID      int64       `json:"id"`
+}
+
+// rpcResponse is a JSON-RPC 2.0 response
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *RPCError       `json:"error"`
+}
+
+// RPCError is an error returned by Kodi in a JSON-RPC response
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("Kodi JSON-RPC error %d: %s", e.Code, e.Message)
+}
+
+// NewKodiClient creates a new Kodi client. cfg.Timeout, when set, takes precedence over timeout.
+func NewKodiClient(cfg *config.KodiConfig, timeout time.Duration, logger arr.Logger) *KodiClient {
+	if cfg.Timeout > 0 {
+		timeout = cfg.Timeout
+	}
+
+	baseURL, endpoint := arr.ResolveEndpoint(cfg.URL)
+	return &KodiClient{
+		baseURL:  baseURL,
+		username: cfg.Username,
+		password: cfg.Password,
+		httpClient: &http.Client{
+			Timeout:   timeout,
+			Transport: endpoint,
+		},
+		logger: logger,
+	}
+}
+
+// TestConnection verifies the connection to Kodi
+func (c *KodiClient) TestConnection(ctx context.Context) error {
+	var pong string
+	if err := c.call(ctx, "JSONRPC.Ping", nil, &pong); err != nil {
+		return fmt.Errorf("failed to connect to Kodi: %w", err)
+	}
+	if pong != "pong" {
+		return fmt.Errorf("unexpected Kodi ping response %q", pong)
+	}
+	return nil
+}
+
+// GetMovieByIDs finds a movie by TMDB ID, falling back to the IMDb ID when Kodi's scraper
+// did not record a TMDB ID. The returned movie's Available field reports whether Kodi can
+// still resolve its file.
+func (c *KodiClient) GetMovieByIDs(ctx context.Context, tmdbID int, imdbID string) (*KodiMovie, error) {
+	movies, err := c.allMovies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	match := findMovie(movies, tmdbID, imdbID)
+	if match == nil {
+		return nil, fmt.Errorf("movie with TMDB ID %d not found in Kodi library", tmdbID)
+	}
+
+	movie := *match
+	movie.Available = c.fileExists(ctx, movie.File)
+	return &movie, nil
+}
+
+// GetTVShowByTVDBID finds a TV show by TVDB ID
+func (c *KodiClient) GetTVShowByTVDBID(ctx context.Context, tvdbID int) (*KodiTVShow, error) {
+	var result struct {
+		TVShows []KodiTVShow `json:"tvshows"`
+	}
+	params := map[string]interface{}{
+		"properties": []string{"title", "year", "imdbnumber", "uniqueid"},
+	}
+	if err := c.call(ctx, "VideoLibrary.GetTVShows", params, &result); err != nil {
+		return nil, fmt.Errorf("failed to list Kodi TV shows: %w", err)
+	}
+
+	want := strconv.Itoa(tvdbID)
+	for i := range result.TVShows {
+		show := &result.TVShows[i]
+		if show.UniqueIDs["tvdb"] == want || (show.UniqueIDs == nil && show.IMDBNumber == want) {
+			return show, nil
+		}
+	}
+	return nil, fmt.Errorf("series with TVDB ID %d not found in Kodi library", tvdbID)
+}
+
+// GetEpisodes returns all episodes of a TV show
+func (c *KodiClient) GetEpisodes(ctx context.Context, tvshowID int) ([]KodiEpisode, error) {
+	var result struct {
+		Episodes []KodiEpisode `json:"episodes"`
+	}
+	params := map[string]interface{}{
+		"tvshowid":   tvshowID,
+		"properties": []string{"title", "season", "episode", "file"},
+	}
+	if err := c.call(ctx, "VideoLibrary.GetEpisodes", params, &result); err != nil {
+		return nil, fmt.Errorf("failed to list episodes for Kodi TV show %d: %w", tvshowID, err)
+	}
+	return result.Episodes, nil
+}
+
+// allMovies returns the full movie listing. Kodi cannot filter by unique ID, so the
+// listing is fetched once and reused for every lookup made with this client.
+func (c *KodiClient) allMovies(ctx context.Context) ([]KodiMovie, error) {
+	c.moviesOnce.Do(func() {
+		var result struct {
+			Movies []KodiMovie `json:"movies"`
+		}
+		params := map[string]interface{}{
+			"properties": []string{"title", "year", "file", "imdbnumber", "uniqueid"},
+		}
+		if err := c.call(ctx, "VideoLibrary.GetMovies", params, &result); err != nil {
+			c.moviesErr = fmt.Errorf("failed to list Kodi movies: %w", err)
+			return
+		}
+		c.movies = result.Movies
+	})
+	return c.movies, c.moviesErr
+}
+
+// findMovie returns the movie matching the TMDB ID, or failing that the IMDb ID
+func findMovie(movies []KodiMovie, tmdbID int, imdbID string) *KodiMovie {
+	want := strconv.Itoa(tmdbID)
+	for i := range movies {
+		if tmdbID > 0 && movies[i].UniqueIDs["tmdb"] == want {
+			return &movies[i]
+		}
+	}
+	if imdbID != "" {
+		for i := range movies {
+			if strings.EqualFold(movies[i].UniqueIDs["imdb"], imdbID) || strings.EqualFold(movies[i].IMDBNumber, imdbID) {
+				return &movies[i]
+			}
+		}
+	}
+	return nil
+}
+
+// fileExists asks Kodi whether it can still resolve a library file
+func (c *KodiClient) fileExists(ctx context.Context, file string) bool {
+	if file == "" {
+		return false
+	}
+
+	params := map[string]interface{}{
+		"file":       file,
+		"media":      "video",
+		"properties": []string{"size"},
+	}
+	if err := c.call(ctx, "Files.GetFileDetails", params, nil); err != nil {
+		c.logger.Debug("Kodi could not resolve %s: %v", file, err)
+		return false
+	}
+	return true
+}
+
+// call makes a JSON-RPC call and decodes the result into result (if not nil)
+func (c *KodiClient) call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	body, err := json.Marshal(rpcRequest{
+		JSONRPC: "2.0",
+		Method:  method,
+		Params:  params,
+		ID:      c.requestID.Add(1),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/jsonrpc", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	c.logger.Debug("Calling Kodi method %s", method)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s failed with status %d: %s", method, resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	var rpcResp rpcResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", method, err)
+	}
+	if rpcResp.Error != nil {
+		return rpcResp.Error
+	}
+
+	if result != nil {
+		if err := json.Unmarshal(rpcResp.Result, result); err != nil {
+			return fmt.Errorf("failed to decode %s result: %w", method, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package kodi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hnipps/refresharr/internal/config"
+)
+
+// mockLogger implements arr.Logger for testing
+type mockLogger struct{}
+
+func (m *mockLogger) Debug(format string, args ...interface{}) {}
+func (m *mockLogger) Info(format string, args ...interface{})  {}
+func (m *mockLogger) Warn(format string, args ...interface{})  {}
+func (m *mockLogger) Error(format string, args ...interface{}) {}
+
+// newTestServer serves JSON-RPC results from a method -> result map. Methods mapped to nil
+// return a JSON-RPC error.
+func newTestServer(t *testing.T, results map[string]interface{}, calls map[string]int) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/jsonrpc" || r.Method != "POST" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if user, pass, ok := r.BasicAuth(); ok && (user != "kodi" || pass != "secret") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var req rpcRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+			return
+		}
+		if calls != nil {
+			calls[req.Method]++
+		}
+
+		result, ok := results[req.Method]
+		if !ok || result == nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"jsonrpc": "2.0", "id": req.ID,
+				"error": map[string]interface{}{"code": -32602, "message": "Invalid params."},
+			})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}))
+}
+
+func TestKodiClient_TestConnection(t *testing.T) {
+	server := newTestServer(t, map[string]interface{}{"JSONRPC.Ping": "pong"}, nil)
+	defer server.Close()
+
+	client := NewKodiClient(&config.KodiConfig{URL: server.URL + "/", Username: "kodi", Password: "secret"}, 30*time.Second, &mockLogger{})
+	if err := client.TestConnection(context.Background()); err != nil {
+		t.Errorf("TestConnection returned error: %v", err)
+	}
+
+	client = NewKodiClient(&config.KodiConfig{URL: server.URL, Username: "kodi", Password: "wrong"}, 30*time.Second, &mockLogger{})
+	if err := client.TestConnection(context.Background()); err == nil {
+		t.Error("Expected error for bad credentials")
+	}
+}
+
+func TestNewKodiClient_TimeoutOverride(t *testing.T) {
+	client := NewKodiClient(&config.KodiConfig{URL: "http://kodi.local:8080"}, 30*time.Second, &mockLogger{})
+	if client.httpClient.Timeout != 30*time.Second {
+		t.Errorf("Expected default timeout 30s, got %s", client.httpClient.Timeout)
+	}
+
+	client = NewKodiClient(&config.KodiConfig{URL: "http://kodi.local:8080", Timeout: 5 * time.Second}, 30*time.Second, &mockLogger{})
+	if client.httpClient.Timeout != 5*time.Second {
+		t.Errorf("Expected Kodi timeout 5s to take precedence, got %s", client.httpClient.Timeout)
+	}
+}
+
+func TestKodiClient_GetMovieByIDs(t *testing.T) {
+	calls := make(map[string]int)
+	server := newTestServer(t, map[string]interface{}{
+		"VideoLibrary.GetMovies": map[string]interface{}{
+			"movies": []map[string]interface{}{
+				{"movieid": 1, "title": "The Matrix", "year": 1999, "file": "/movies/matrix.mkv", "uniqueid": map[string]string{"tmdb": "603", "imdb": "tt0133093"}},
+				{"movieid": 2, "title": "IMDb Only", "year": 2001, "file": "/movies/gone.mkv", "imdbnumber": "tt0000001"},
+			},
+		},
+		"Files.GetFileDetails": nil,
+	}, calls)
+	defer server.Close()
+
+	client := NewKodiClient(&config.KodiConfig{URL: server.URL}, 30*time.Second, &mockLogger{})
+
+	movie, err := client.GetMovieByIDs(context.Background(), 603, "")
+	if err != nil {
+		t.Fatalf("Expected TMDB match, got error: %v", err)
+	}
+	if movie.Title != "The Matrix" {
+		t.Errorf("Expected 'The Matrix', got '%s'", movie.Title)
+	}
+	if movie.Available {
+		t.Error("Expected movie to be unavailable when Kodi cannot resolve the file")
+	}
+
+	movie, err = client.GetMovieByIDs(context.Background(), 999, "tt0000001")
+	if err != nil {
+		t.Fatalf("Expected IMDb fallback match, got error: %v", err)
+	}
+	if movie.Title != "IMDb Only" {
+		t.Errorf("Expected 'IMDb Only', got '%s'", movie.Title)
+	}
+
+	if _, err := client.GetMovieByIDs(context.Background(), 999, "tt9999999"); err == nil {
+		t.Error("Expected not found error")
+	}
+
+	if calls["VideoLibrary.GetMovies"] != 1 {
+		t.Errorf("Expected movie listing to be fetched once, got %d", calls["VideoLibrary.GetMovies"])
+	}
+}
+
+func TestKodiClient_MovieAvailable(t *testing.T) {
+	server := newTestServer(t, map[string]interface{}{
+		"VideoLibrary.GetMovies": map[string]interface{}{
+			"movies": []map[string]interface{}{
+				{"movieid": 1, "title": "The Matrix", "file": "/movies/matrix.mkv", "uniqueid": map[string]string{"tmdb": "603"}},
+			},
+		},
+		"Files.GetFileDetails": map[string]interface{}{"filedetails": map[string]interface{}{"file": "/movies/matrix.mkv", "size": 1024}},
+	}, nil)
+	defer server.Close()
+
+	client := NewKodiClient(&config.KodiConfig{URL: server.URL}, 30*time.Second, &mockLogger{})
+
+	movie, err := client.GetMovieByIDs(context.Background(), 603, "")
+	if err != nil {
+		t.Fatalf("GetMovieByIDs returned error: %v", err)
+	}
+	if !movie.Available {
+		t.Error("Expected movie to be available")
+	}
+}
+
+func TestKodiClient_TVShowEpisodes(t *testing.T) {
+	server := newTestServer(t, map[string]interface{}{
+		"VideoLibrary.GetTVShows": map[string]interface{}{
+			"tvshows": []map[string]interface{}{
+				{"tvshowid": 7, "title": "Other", "uniqueid": map[string]string{"tvdb": "1"}},
+				{"tvshowid": 8, "title": "Lost", "uniqueid": map[string]string{"tvdb": "73739"}},
+			},
+		},
+		"VideoLibrary.GetEpisodes": map[string]interface{}{
+			"episodes": []map[string]interface{}{
+				{"episodeid": 1, "title": "Pilot", "season": 1, "episode": 1, "file": "/tv/lost/s01e01.mkv"},
+			},
+		},
+	}, nil)
+	defer server.Close()
+
+	client := NewKodiClient(&config.KodiConfig{URL: server.URL}, 30*time.Second, &mockLogger{})
+
+	show, err := client.GetTVShowByTVDBID(context.Background(), 73739)
+	if err != nil {
+		t.Fatalf("GetTVShowByTVDBID returned error: %v", err)
+	}
+	if show.TVShowID != 8 {
+		t.Errorf("Expected TV show 8, got %d", show.TVShowID)
+	}
+
+	episodes, err := client.GetEpisodes(context.Background(), show.TVShowID)
+	if err != nil {
+		t.Fatalf("GetEpisodes returned error: %v", err)
+	}
+	if len(episodes) != 1 || episodes[0].Season != 1 || episodes[0].Episode != 1 {
+		t.Errorf("Unexpected episodes: %+v", episodes)
+	}
+
+	if _, err := client.GetTVShowByTVDBID(context.Background(), 42); err == nil {
+		t.Error("Expected not found error")
+	}
+}
@@ -0,0 +1,212 @@
+// Package messages holds the user-facing summary and notification text, so it can be translated
+// without touching the code. Debug and operational logs are not part of the catalog and stay in English.
+package messages
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ID names a message in the catalog. Translation files use the IDs as keys.
+type ID string
+
+// Report summary printed to the terminal
+const (
+	ReportTitle          ID = "report.title"
+	ReportGenerated      ID = "report.generated"
+	ReportService        ID = "report.service"
+	ReportRunType        ID = "report.runType"
+	ReportTotalMissing   ID = "report.totalMissing"
+	ReportSearchSkipped  ID = "report.searchSkipped"
+	ReportDuration       ID = "report.duration"
+	ReportAPICalls       ID = "report.apiCalls"
+	ReportOverBudget     ID = "report.overBudget"
+	ReportOutsideWindow  ID = "report.outsideWindow"
+	ReportRecovered      ID = "report.recovered"
+	ReportMTTR           ID = "report.mttr"
+	ReportRecoveredEntry ID = "report.recoveredEntry"
+	ReportRecoveredNow   ID = "report.recoveredNow"
+	ReportOutsideRoot    ID = "report.outsideRoot"
+	ReportOutsideEntry   ID = "report.outsideEntry"
+	ReportMappedTo       ID = "report.mappedTo"
+	ReportNoneMissing    ID = "report.noneMissing"
+	ReportMissingFiles   ID = "report.missingFiles"
+	ReportEpisode        ID = "report.episode"
+	ReportUnknownEpisode ID = "report.unknownEpisode"
+	ReportSizeMismatch   ID = "report.sizeMismatch"
+	ReportSizes          ID = "report.sizes"
+	ReportCorrupt        ID = "report.corrupt"
+	ReportChecksums      ID = "report.checksums"
+	ReportMissingFile    ID = "report.missingFile"
+	ReportAlsoMissing    ID = "report.alsoMissing"
+	ReportReason         ID = "report.reason"
+	ReportQuality        ID = "report.quality"
+	ReportReleaseGroup   ID = "report.releaseGroup"
+	ReportCustomFormats  ID = "report.customFormats"
+	ReportRecordedSize   ID = "report.recordedSize"
+	ReportAddCheckOK     ID = "report.addCheckOk"
+	ReportAddCheckFailed ID = "report.addCheckFailed"
+	ReportTitleMatch     ID = "report.titleMatch"
+	ReportFileID         ID = "report.fileId"
+	ReportProcessed      ID = "report.processed"
+	ReportMostAffected   ID = "report.mostAffected"
+)
+
+// Verify alerts
+const (
+	AlertThreshold ID = "alert.threshold"
+	AlertGrew      ID = "alert.grew"
+	AlertText      ID = "alert.text"
+
+	AlertTest       ID = "alert.test"
+	AlertTestReason ID = "alert.testReason"
+)
+
+// Completion webhook and push notifications
+const (
+	CompletionText   ID = "completion.text"
+	CompletionFailed ID = "completion.failed"
+	CommandFailed    ID = "completion.commandFailed"
+)
+
+// End of run summary per service
+const (
+	SummarySuccess  ID = "summary.success"
+	SummaryErrors   ID = "summary.errors"
+	SummaryWarnings ID = "summary.warnings"
+)
+
+// english is the built-in catalog, and the fallback for messages a translation leaves out
+var english = map[ID]string{
+	ReportTitle:          "📊 MISSING FILES REPORT",
+	ReportGenerated:      "Generated: %s",
+	ReportService:        "Service: %s",
+	ReportRunType:        "Run Type: %s",
+	ReportTotalMissing:   "Total Missing Files: %d",
+	ReportSearchSkipped:  "Missing Search: skipped (%s)",
+	ReportDuration:       "Duration: %dms (fetch %dms, symlink scan %dms, verification %dms, deletion %dms, refresh %dms)",
+	ReportAPICalls:       "API Calls: %d",
+	ReportOverBudget:     "API Budget: %d calls exceeded, later changes were only reported",
+	ReportOutsideWindow:  "Maintenance Window: changes outside it were deferred (next window: %s)",
+	ReportRecovered:      "♻️  Recovered Since Earlier Runs: %d",
+	ReportMTTR:           "   Mean Time To Recovery: %s",
+	ReportRecoveredEntry: "   %s (missing since %s)",
+	ReportRecoveredNow:   "      Now: %s",
+	ReportOutsideRoot:    "📁 Outside Root Folders: %d",
+	ReportOutsideEntry:   "   %s: %s (%s)",
+	ReportMappedTo:       "      Mapped To: %s",
+	ReportNoneMissing:    "🎉 No missing files found!",
+	ReportMissingFiles:   "Missing Files:",
+	ReportEpisode:        "   Episode: S%02dE%02d - %s",
+	ReportUnknownEpisode: "Unknown Episode",
+	ReportSizeMismatch:   "   Size Mismatch: %s",
+	ReportSizes:          "   Expected: %d bytes, Found: %d bytes",
+	ReportCorrupt:        "   Corrupt File: %s",
+	ReportChecksums:      "   Expected SHA-256: %s, Found: %s",
+	ReportMissingFile:    "   Missing File: %s",
+	ReportAlsoMissing:    "   Also Missing: %s",
+	ReportReason:         "   Reason: %s",
+	ReportQuality:        "   Quality: %s",
+	ReportReleaseGroup:   "   Release Group: %s",
+	ReportCustomFormats:  "   Custom Formats: %s",
+	ReportRecordedSize:   "   Recorded Size: %d bytes",
+	ReportAddCheckOK:     "   Add Check: would succeed",
+	ReportAddCheckFailed: "   Add Check: would fail (%s)",
+	ReportTitleMatch:     "   Matched by title lookup (confidence %.0f%%)",
+	ReportFileID:         "   File ID: %d",
+	ReportProcessed:      "   Processed: %s",
+	ReportMostAffected:   "Most Affected:",
+
+	AlertThreshold: "%d missing files exceeds the threshold of %d",
+	AlertGrew:      "missing files grew from %d to %d since the previous verify",
+	AlertText:      "RefreshArr verify (%s): %s",
+
+	AlertTest:       "RefreshArr test notification: alerts will arrive here",
+	AlertTestReason: "sent by refresharr notify test",
+
+	CompletionText:   "RefreshArr %s (%s) finished: %d checked, %d missing, %d deleted, %d errors",
+	CompletionFailed: "RefreshArr %s (%s) failed: %s",
+	CommandFailed:    "RefreshArr %s failed: %s",
+
+	SummarySuccess:  "🎉 %s cleanup completed successfully!",
+	SummaryErrors:   "%s cleanup completed with errors",
+	SummaryWarnings: "%s cleanup completed with %d warning(s)",
+}
+
+// Catalog is a set of message templates. A nil Catalog uses the built-in English text.
+type Catalog struct {
+	messages map[ID]string
+}
+
+// Load reads a translation file, a JSON object mapping message IDs to fmt templates. Messages
+// it leaves out stay in English. An empty path gives the English catalog.
+func Load(path string) (*Catalog, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read messages file: %w", err)
+	}
+	var translations map[ID]string
+	if err := json.Unmarshal(data, &translations); err != nil {
+		return nil, fmt.Errorf("failed to parse messages file %s: %w", path, err)
+	}
+
+	catalog := &Catalog{messages: make(map[ID]string, len(translations))}
+	for id, text := range translations {
+		original, ok := english[id]
+		if !ok {
+			return nil, fmt.Errorf("messages file %s: unknown message %q", path, id)
+		}
+		if err := checkVerbs(original, text); err != nil {
+			return nil, fmt.Errorf("messages file %s: message %q: %w", path, id, err)
+		}
+		catalog.messages[id] = text
+	}
+	return catalog, nil
+}
+
+// verbPattern matches the fmt verbs of a template, with optional explicit argument indexes
+var verbPattern = regexp.MustCompile(`%(\[\d+\])?[-+# 0]*\d*(\.\d+)?[a-zA-Z%]`)
+
+// indexPattern matches the explicit argument index of a verb
+var indexPattern = regexp.MustCompile(`\[\d+\]`)
+
+// checkVerbs makes sure a translation formats the same arguments as the English template. A
+// translation that reorders the arguments has to use explicit indexes such as %[2]d.
+func checkVerbs(original, translation string) error {
+	want := verbPattern.FindAllString(original, -1)
+	got := verbPattern.FindAllString(translation, -1)
+	if indexPattern.MatchString(strings.Join(got, "")) {
+		for i := range got {
+			got[i] = indexPattern.ReplaceAllString(got[i], "")
+		}
+		sort.Strings(got)
+		sort.Strings(want)
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		return fmt.Errorf("expected the verbs %q, got %q", strings.Join(want, " "), strings.Join(got, " "))
+	}
+	return nil
+}
+
+// Sprintf formats a message with the catalog's template for it, or the English one
+func (c *Catalog) Sprintf(id ID, args ...interface{}) string {
+	text, ok := "", false
+	if c != nil {
+		text, ok = c.messages[id]
+	}
+	if !ok {
+		text = english[id]
+	}
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
@@ -0,0 +1,68 @@
+package messages
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeMessages(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "messages.json")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCatalog_Sprintf(t *testing.T) {
+	var english *Catalog
+	if got := english.Sprintf(SummaryWarnings, "sonarr", 2); got != "sonarr cleanup completed with 2 warning(s)" {
+		t.Errorf("Expected the English text from a nil catalog, got %q", got)
+	}
+
+	catalog, err := Load(writeMessages(t, `{
+		"summary.warnings": "%[1]s: %[2]d Warnung(en)",
+		"report.titleMatch": "   Per Titel gefunden (%.0f%% sicher)"
+	}`))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := catalog.Sprintf(SummaryWarnings, "sonarr", 2); got != "sonarr: 2 Warnung(en)" {
+		t.Errorf("Expected the translation, got %q", got)
+	}
+	if got := catalog.Sprintf(ReportTitleMatch, 95.0); got != "   Per Titel gefunden (95% sicher)" {
+		t.Errorf("Expected the translation, got %q", got)
+	}
+	if got := catalog.Sprintf(ReportNoneMissing); got != "🎉 No missing files found!" {
+		t.Errorf("Expected untranslated messages to stay in English, got %q", got)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"not json", `{`, "failed to parse"},
+		{"unknown message", `{"report.nope": "x"}`, "unknown message"},
+		{"missing argument", `{"summary.warnings": "%s hat Warnungen"}`, "expected the verbs"},
+		{"wrong verb", `{"report.totalMissing": "Fehlend: %s"}`, "expected the verbs"},
+		{"reordered without indexes", `{"summary.warnings": "%d Warnungen in %s"}`, "expected the verbs"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeMessages(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	if catalog, err := Load(""); catalog != nil || err != nil {
+		t.Errorf("Expected no catalog without a file, got %v, %v", catalog, err)
+	}
+}
@@ -0,0 +1,205 @@
+// Package metrics keeps counters about finished runs and exposes them in the Prometheus text format
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+// Run types a run's metrics are labelled with
+const (
+	RunTypeReal   = "real"
+	RunTypeDryRun = "dry-run"
+	RunTypeVerify = "verify" // Verify runs are dry runs, but they're kept apart from ordinary ones
+)
+
+// defaultInstance is the instance label of a service's default instance
+const defaultInstance = "default"
+
+// RunTypeOf returns the run type of a run of the verify command or, otherwise, a cleanup run
+func RunTypeOf(dryRun, verify bool) string {
+	switch {
+	case verify:
+		return RunTypeVerify
+	case dryRun:
+		return RunTypeDryRun
+	default:
+		return RunTypeReal
+	}
+}
+
+// Labels identify the runs a set of metrics covers
+type Labels struct {
+	Service  string // "sonarr", "radarr" or "readarr"
+	Instance string // Name of an extra instance, or empty for the default one
+	RunType  string // RunTypeReal, RunTypeDryRun or RunTypeVerify
+}
+
+// runMetrics are the metrics kept for one set of labels
+type runMetrics struct {
+	runs           int64
+	failures       int64
+	itemsChecked   int64
+	missingFiles   int64
+	deletedRecords int64
+	errors         int64
+	warnings       int64
+	apiCalls       int64
+	fileOps        int64
+
+	// recovered counts the recovered files with known times, and recoveryTime how long they were
+	// missing in all
+	recovered    int64
+	recoveryTime time.Duration
+
+	lastDuration     time.Duration
+	lastFinishedAt   time.Time
+	lastMissingFiles int
+}
+
+// Registry keeps the metrics of the runs observed since the process started
+type Registry struct {
+	mu   sync.Mutex
+	runs map[Labels]*runMetrics
+}
+
+// NewRegistry creates an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{runs: make(map[Labels]*runMetrics)}
+}
+
+// metricsFor returns the metrics of labels, adding them when they're new. r.mu must be held.
+func (r *Registry) metricsFor(labels Labels) *runMetrics {
+	if labels.Instance == "" {
+		labels.Instance = defaultInstance
+	}
+	m, ok := r.runs[labels]
+	if !ok {
+		m = &runMetrics{}
+		r.runs[labels] = m
+	}
+	return m
+}
+
+// ObserveRun records a finished run and the files it found recovered
+func (r *Registry) ObserveRun(labels Labels, stats models.CleanupStats, recovered []models.RecoveredFileEntry, success bool, startedAt, finishedAt time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m := r.metricsFor(labels)
+	m.runs++
+	if !success {
+		m.failures++
+	}
+	m.itemsChecked += int64(stats.TotalItemsChecked)
+	m.missingFiles += int64(stats.MissingFiles)
+	m.deletedRecords += int64(stats.DeletedRecords)
+	m.errors += int64(stats.Errors)
+	m.warnings += int64(stats.Warnings)
+	m.apiCalls += int64(stats.APICalls)
+	m.fileOps += stats.FileOps
+	if mttr, n := models.MeanTimeToRecovery(recovered); n > 0 {
+		m.recovered += int64(n)
+		m.recoveryTime += mttr * time.Duration(n)
+	}
+	m.lastDuration = finishedAt.Sub(startedAt)
+	m.lastFinishedAt = finishedAt
+	m.lastMissingFiles = stats.MissingFiles
+}
+
+// ObserveFailure records a run that failed before it could check anything, such as one whose
+// service couldn't be reached. It counts as a failed run; the other metrics are left alone.
+func (r *Registry) ObserveFailure(labels Labels) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m := r.metricsFor(labels)
+	m.runs++
+	m.failures++
+}
+
+// metric describes one exported metric and how to read it from a label set's metrics
+type metric struct {
+	name  string
+	kind  string // "counter" or "gauge"
+	help  string
+	value func(m *runMetrics) float64
+}
+
+var exported = []metric{
+	{"refresharr_runs_total", "counter", "Finished runs.", func(m *runMetrics) float64 { return float64(m.runs) }},
+	{"refresharr_run_failures_total", "counter", "Finished runs that failed.", func(m *runMetrics) float64 { return float64(m.failures) }},
+	{"refresharr_items_checked_total", "counter", "Items whose files were checked.", func(m *runMetrics) float64 { return float64(m.itemsChecked) }},
+	{"refresharr_missing_files_total", "counter", "Missing files found.", func(m *runMetrics) float64 { return float64(m.missingFiles) }},
+	{"refresharr_deleted_records_total", "counter", "File records deleted.", func(m *runMetrics) float64 { return float64(m.deletedRecords) }},
+	{"refresharr_errors_total", "counter", "Errors during runs.", func(m *runMetrics) float64 { return float64(m.errors) }},
+	{"refresharr_warnings_total", "counter", "Warnings during runs.", func(m *runMetrics) float64 { return float64(m.warnings) }},
+	{"refresharr_api_calls_total", "counter", "HTTP requests sent to the service.", func(m *runMetrics) float64 { return float64(m.apiCalls) }},
+	{"refresharr_filesystem_calls_total", "counter", "Filesystem calls made checking files.", func(m *runMetrics) float64 { return float64(m.fileOps) }},
+	{"refresharr_recovered_files_total", "counter", "Missing files found valid again.", func(m *runMetrics) float64 { return float64(m.recovered) }},
+	{"refresharr_recovery_seconds_total", "counter", "Time the recovered files were missing.", func(m *runMetrics) float64 { return m.recoveryTime.Seconds() }},
+	{"refresharr_mean_time_to_recovery_seconds", "gauge", "Mean time the recovered files were missing.", func(m *runMetrics) float64 {
+		if m.recovered == 0 {
+			return 0
+		}
+		return (m.recoveryTime / time.Duration(m.recovered)).Seconds()
+	}},
+	{"refresharr_last_run_duration_seconds", "gauge", "Duration of the last run.", func(m *runMetrics) float64 { return m.lastDuration.Seconds() }},
+	{"refresharr_last_run_timestamp_seconds", "gauge", "Unix time the last run finished.", func(m *runMetrics) float64 {
+		return float64(m.lastFinishedAt.UnixMilli()) / 1000
+	}},
+	{"refresharr_last_run_missing_files", "gauge", "Missing files found by the last run.", func(m *runMetrics) float64 { return float64(m.lastMissingFiles) }},
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format, ordered by labels
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	labels := make([]Labels, 0, len(r.runs))
+	for l := range r.runs {
+		labels = append(labels, l)
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		if labels[i].Service != labels[j].Service {
+			return labels[i].Service < labels[j].Service
+		}
+		if labels[i].Instance != labels[j].Instance {
+			return labels[i].Instance < labels[j].Instance
+		}
+		return labels[i].RunType < labels[j].RunType
+	})
+
+	var b strings.Builder
+	for _, metric := range exported {
+		fmt.Fprintf(&b, "# HELP %s %s\n", metric.name, metric.help)
+		fmt.Fprintf(&b, "# TYPE %s %s\n", metric.name, metric.kind)
+		for _, l := range labels {
+			fmt.Fprintf(&b, "%s{service=\"%s\",instance=\"%s\",run_type=\"%s\"} %s\n",
+				metric.name, labelEscaper.Replace(l.Service), labelEscaper.Replace(l.Instance), labelEscaper.Replace(l.RunType),
+				strconv.FormatFloat(metric.value(r.runs[l]), 'f', -1, 64))
+		}
+	}
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// Handler serves the metrics for Prometheus to scrape
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_, _ = r.WriteTo(w)
+	})
+}
+
+// labelEscaper escapes the characters the text format doesn't allow in label values
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
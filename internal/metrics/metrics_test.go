@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+func TestRunTypeOf(t *testing.T) {
+	tests := []struct {
+		dryRun, verify bool
+		want           string
+	}{
+		{false, false, RunTypeReal},
+		{true, false, RunTypeDryRun},
+		{true, true, RunTypeVerify},
+	}
+	for _, tt := range tests {
+		if got := RunTypeOf(tt.dryRun, tt.verify); got != tt.want {
+			t.Errorf("RunTypeOf(%v, %v) = %q, want %q", tt.dryRun, tt.verify, got, tt.want)
+		}
+	}
+}
+
+func TestRegistry_ObserveRun(t *testing.T) {
+	registry := NewRegistry()
+	finishedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	startedAt := finishedAt.Add(-90 * time.Second)
+
+	radarr := Labels{Service: "radarr", RunType: RunTypeReal}
+	radarr4K := Labels{Service: "radarr", Instance: "4k", RunType: RunTypeDryRun}
+	recovered := []models.RecoveredFileEntry{
+		{MissingSince: "2026-01-01T03:00:00Z", RecoveredAt: "2026-01-01T04:00:00Z"},
+		{MissingSince: "2026-01-01T01:00:00Z", RecoveredAt: "2026-01-01T04:00:00Z"},
+	}
+	registry.ObserveRun(radarr, models.CleanupStats{TotalItemsChecked: 10, MissingFiles: 2, DeletedRecords: 2, APICalls: 14}, recovered, true, startedAt, finishedAt)
+	registry.ObserveRun(radarr, models.CleanupStats{TotalItemsChecked: 10, MissingFiles: 1, Errors: 1}, recovered[:1], false, startedAt, finishedAt)
+	registry.ObserveRun(radarr4K, models.CleanupStats{TotalItemsChecked: 5}, nil, true, startedAt, finishedAt)
+
+	server := httptest.NewServer(registry.Handler())
+	defer server.Close()
+	resp, err := server.Client().Get(server.URL)
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	output := string(body)
+
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Errorf("Unexpected content type %q", resp.Header.Get("Content-Type"))
+	}
+
+	for _, want := range []string{
+		"# TYPE refresharr_runs_total counter\n",
+		`refresharr_runs_total{service="radarr",instance="4k",run_type="dry-run"} 1` + "\n",
+		`refresharr_runs_total{service="radarr",instance="default",run_type="real"} 2` + "\n",
+		`refresharr_run_failures_total{service="radarr",instance="default",run_type="real"} 1` + "\n",
+		`refresharr_missing_files_total{service="radarr",instance="default",run_type="real"} 3` + "\n",
+		`refresharr_api_calls_total{service="radarr",instance="default",run_type="real"} 14` + "\n",
+		`refresharr_last_run_missing_files{service="radarr",instance="default",run_type="real"} 1` + "\n",
+		// Missing 1h, 3h and 1h
+		`refresharr_recovered_files_total{service="radarr",instance="default",run_type="real"} 3` + "\n",
+		`refresharr_recovery_seconds_total{service="radarr",instance="default",run_type="real"} 18000` + "\n",
+		`refresharr_mean_time_to_recovery_seconds{service="radarr",instance="default",run_type="real"} 6000` + "\n",
+		`refresharr_mean_time_to_recovery_seconds{service="radarr",instance="4k",run_type="dry-run"} 0` + "\n",
+		`refresharr_last_run_duration_seconds{service="radarr",instance="4k",run_type="dry-run"} 90` + "\n",
+		`refresharr_last_run_timestamp_seconds{service="radarr",instance="4k",run_type="dry-run"} 1767323045` + "\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestRegistry_ObserveFailure(t *testing.T) {
+	registry := NewRegistry()
+	radarr := Labels{Service: "radarr", RunType: RunTypeReal}
+	registry.ObserveRun(radarr, models.CleanupStats{MissingFiles: 2}, nil, true, time.Time{}, time.Unix(60, 0))
+	registry.ObserveFailure(radarr)
+	registry.ObserveFailure(Labels{Service: "sonarr", RunType: RunTypeVerify})
+
+	var b strings.Builder
+	if _, err := registry.WriteTo(&b); err != nil {
+		t.Fatalf("WriteTo() failed: %v", err)
+	}
+	for _, want := range []string{
+		`refresharr_runs_total{service="radarr",instance="default",run_type="real"} 2` + "\n",
+		`refresharr_run_failures_total{service="radarr",instance="default",run_type="real"} 1` + "\n",
+		`refresharr_run_failures_total{service="sonarr",instance="default",run_type="verify"} 1` + "\n",
+		// A run that never started leaves the last run's gauges alone
+		`refresharr_last_run_missing_files{service="radarr",instance="default",run_type="real"} 2` + "\n",
+		`refresharr_last_run_timestamp_seconds{service="radarr",instance="default",run_type="real"} 60` + "\n",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, b.String())
+		}
+	}
+}
+
+func TestRegistry_EscapesLabels(t *testing.T) {
+	registry := NewRegistry()
+	registry.ObserveRun(Labels{Service: "sonarr", Instance: `a"b\c`, RunType: RunTypeReal}, models.CleanupStats{}, nil, true, time.Time{}, time.Time{})
+
+	var b strings.Builder
+	if _, err := registry.WriteTo(&b); err != nil {
+		t.Fatalf("WriteTo() failed: %v", err)
+	}
+	if want := `instance="a\"b\\c"`; !strings.Contains(b.String(), want) {
+		t.Errorf("Expected output to contain %q, got:\n%s", want, b.String())
+	}
+}
@@ -0,0 +1,695 @@
+// Package mockarr emulates enough of the Sonarr and Radarr v3 APIs to run RefreshArr end to
+// end without a live instance. It serves the same fixtures as --simulate, but over HTTP, so
+// the real clients and their request flows are exercised.
+package mockarr
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hnipps/refresharr/internal/arr"
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+// Command is a command received by POST /api/v3/command
+type Command struct {
+	ID         int             `json:"id"`
+	Name       string          `json:"name"`
+	Body       json.RawMessage `json:"body"`
+	Status     string          `json:"status"`
+	QueuedAt   time.Time       `json:"queued"`
+	ReceivedAs string          `json:"-"` // Service the command was sent to
+}
+
+// Server is an http.Handler emulating a Sonarr or Radarr instance backed by a fixture.
+// Writes change the server's copy of the fixture, and every request is recorded so tests
+// can assert on the request flow.
+type Server struct {
+	service string
+	apiKey  string
+
+	mu       sync.Mutex
+	fixture  arr.SimulationFixture
+	commands []Command
+	imports  []json.RawMessage
+	requests []string
+	nextID   int
+	mux      *http.ServeMux
+}
+
+// NewServer creates a server for service ("sonarr" or "radarr"). Requests must carry apiKey
+// in the X-Api-Key header or apikey query parameter, unless apiKey is empty.
+func NewServer(service string, fixture arr.SimulationFixture, apiKey string) *Server {
+	s := &Server{
+		service: service,
+		apiKey:  apiKey,
+		fixture: fixture,
+		nextID:  1000,
+		mux:     http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("GET /api/v3/system/status", s.handleStatus)
+	s.mux.HandleFunc("GET /api/v3/rootfolder", s.handleRootFolders)
+	s.mux.HandleFunc("GET /api/v3/qualityprofile", s.handleQualityProfiles)
+	s.mux.HandleFunc("POST /api/v3/command", s.handleCommand)
+
+	if service == "radarr" {
+		s.mux.HandleFunc("GET /api/v3/movie", s.handleMovies)
+		s.mux.HandleFunc("POST /api/v3/movie", s.handleAddMovie)
+		s.mux.HandleFunc("GET /api/v3/movie/lookup/tmdb", s.handleMovieLookup)
+		s.mux.HandleFunc("GET /api/v3/movie/lookup/imdb", s.handleMovieLookupIMDB)
+		s.mux.HandleFunc("GET /api/v3/movie/lookup", s.handleMovieLookupTerm)
+		s.mux.HandleFunc("GET /api/v3/movie/{id}", s.handleMovie)
+		s.mux.HandleFunc("PUT /api/v3/movie/{id}", s.handleUpdateMovie)
+		s.mux.HandleFunc("GET /api/v3/moviefile/{id}", s.handleMovieFile)
+		s.mux.HandleFunc("DELETE /api/v3/moviefile/{id}", s.handleDeleteMovieFile)
+	} else {
+		s.mux.HandleFunc("GET /api/v3/series", s.handleSeries)
+		s.mux.HandleFunc("POST /api/v3/series", s.handleAddSeries)
+		s.mux.HandleFunc("GET /api/v3/series/lookup", s.handleSeriesLookup)
+		s.mux.HandleFunc("GET /api/v3/series/{id}", s.handleSeriesByID)
+		s.mux.HandleFunc("GET /api/v3/episode", s.handleEpisodes)
+		s.mux.HandleFunc("GET /api/v3/episode/{id}", s.handleEpisode)
+		s.mux.HandleFunc("PUT /api/v3/episode/monitor", s.handleMonitorEpisodes)
+		s.mux.HandleFunc("GET /api/v3/episodeFile", s.handleEpisodeFiles)
+		s.mux.HandleFunc("DELETE /api/v3/episodeFile/{id}", s.handleDeleteEpisodeFile)
+		s.mux.HandleFunc("GET /api/v3/queue", s.handleQueue)
+		s.mux.HandleFunc("DELETE /api/v3/queue/{id}", s.handleDeleteQueue)
+		s.mux.HandleFunc("GET /api/v3/manualimport", s.handleManualImport)
+		s.mux.HandleFunc("POST /api/v3/manualimport", s.handleExecuteManualImport)
+	}
+
+	return s
+}
+
+// ServeHTTP checks the API key, records the request and routes it
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.RequestURI())
+	s.mu.Unlock()
+
+	if s.apiKey != "" && r.Header.Get("X-Api-Key") != s.apiKey && r.URL.Query().Get("apikey") != s.apiKey {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// Requests returns every request received so far as "METHOD /path?query"
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// Commands returns the commands received so far
+func (s *Server) Commands() []Command {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Command(nil), s.commands...)
+}
+
+// ManualImports returns the bodies of the manual imports received so far
+func (s *Server) ManualImports() []json.RawMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]json.RawMessage(nil), s.imports...)
+}
+
+// Fixture returns the server's current data, including changes made through the API
+func (s *Server) Fixture() arr.SimulationFixture {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fixture
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"appName": strings.ToUpper(s.service[:1]) + s.service[1:],
+		"version": "3.0.0.0",
+	})
+}
+
+func (s *Server) handleRootFolders(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusOK, nonNil(s.fixture.RootFolders))
+}
+
+func (s *Server) handleQualityProfiles(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusOK, nonNil(s.fixture.QualityProfiles))
+}
+
+func (s *Server) handleCommand(w http.ResponseWriter, r *http.Request) {
+	var body json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid command body: "+err.Error())
+		return
+	}
+	var named struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(body, &named); err != nil || named.Name == "" {
+		writeError(w, http.StatusBadRequest, "command name is required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	command := Command{
+		ID:         s.newID(),
+		Name:       named.Name,
+		Body:       body,
+		Status:     "queued",
+		QueuedAt:   time.Now().UTC(),
+		ReceivedAs: s.service,
+	}
+	s.commands = append(s.commands, command)
+	writeJSON(w, http.StatusCreated, command)
+}
+
+// Radarr endpoints
+
+func (s *Server) handleMovies(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusOK, nonNil(s.fixture.Movies))
+}
+
+func (s *Server) handleMovie(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i := s.movieIndex(id); i >= 0 {
+		writeJSON(w, http.StatusOK, s.fixture.Movies[i])
+		return
+	}
+	writeError(w, http.StatusNotFound, "movie not found")
+}
+
+func (s *Server) handleUpdateMovie(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	var movie models.Movie
+	if err := json.NewDecoder(r.Body).Decode(&movie); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid movie: "+err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.movieIndex(id)
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "movie not found")
+		return
+	}
+	movie.ID = id
+	s.fixture.Movies[i] = movie
+	writeJSON(w, http.StatusAccepted, movie)
+}
+
+func (s *Server) handleAddMovie(w http.ResponseWriter, r *http.Request) {
+	var movie models.Movie
+	if err := json.NewDecoder(r.Body).Decode(&movie); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid movie: "+err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.fixture.Movies {
+		if movie.TMDBID != 0 && existing.TMDBID == movie.TMDBID {
+			writeError(w, http.StatusBadRequest, "This movie has already been added")
+			return
+		}
+	}
+	movie.ID = s.newID()
+	movie.AddOptions = nil // Only part of the add request
+	s.fixture.Movies = append(s.fixture.Movies, movie)
+	writeJSON(w, http.StatusCreated, movie)
+}
+
+func (s *Server) handleMovieLookup(w http.ResponseWriter, r *http.Request) {
+	tmdbID, err := strconv.Atoi(r.URL.Query().Get("tmdbId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "tmdbId is required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, lookup := range s.fixture.MovieLookups {
+		if lookup.TMDBID == tmdbID {
+			writeJSON(w, http.StatusOK, lookup)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "movie not found")
+}
+
+func (s *Server) handleMovieLookupIMDB(w http.ResponseWriter, r *http.Request) {
+	imdbID := r.URL.Query().Get("imdbId")
+	if imdbID == "" {
+		writeError(w, http.StatusBadRequest, "imdbId is required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, lookup := range s.fixture.MovieLookups {
+		if lookup.IMDBID == imdbID {
+			writeJSON(w, http.StatusOK, lookup)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "movie not found")
+}
+
+func (s *Server) handleMovieLookupTerm(w http.ResponseWriter, r *http.Request) {
+	term := strings.ToLower(r.URL.Query().Get("term"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	results := []models.MovieLookup{}
+	for _, lookup := range s.fixture.MovieLookups {
+		if term != "" && strings.Contains(strings.ToLower(lookup.Title), term) {
+			results = append(results, lookup)
+		}
+	}
+	writeJSON(w, http.StatusOK, results)
+}
+
+func (s *Server) handleMovieFile(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, file := range s.fixture.MovieFiles {
+		if file.ID == id {
+			writeJSON(w, http.StatusOK, file)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "movie file not found")
+}
+
+func (s *Server) handleDeleteMovieFile(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, file := range s.fixture.MovieFiles {
+		if file.ID != id {
+			continue
+		}
+		s.fixture.MovieFiles = append(s.fixture.MovieFiles[:i], s.fixture.MovieFiles[i+1:]...)
+		// Like Radarr, unlink the file from its movie
+		for j := range s.fixture.Movies {
+			if fileID := s.fixture.Movies[j].MovieFileID; fileID != nil && *fileID == id {
+				s.fixture.Movies[j].HasFile = false
+				s.fixture.Movies[j].MovieFileID = nil
+			}
+		}
+		writeJSON(w, http.StatusOK, struct{}{})
+		return
+	}
+	writeError(w, http.StatusNotFound, "movie file not found")
+}
+
+// Sonarr endpoints
+
+func (s *Server) handleSeries(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	series := nonNil(s.fixture.Series)
+	if tvdbStr := r.URL.Query().Get("tvdbId"); tvdbStr != "" {
+		tvdbID, _ := strconv.Atoi(tvdbStr)
+		series = []models.Series{}
+		for _, item := range s.fixture.Series {
+			if item.TVDBID == tvdbID {
+				series = append(series, item)
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, series)
+}
+
+func (s *Server) handleSeriesByID(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, series := range s.fixture.Series {
+		if series.ID == id {
+			writeJSON(w, http.StatusOK, series)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "series not found")
+}
+
+func (s *Server) handleAddSeries(w http.ResponseWriter, r *http.Request) {
+	var series models.Series
+	if err := json.NewDecoder(r.Body).Decode(&series); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid series: "+err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.fixture.Series {
+		if series.TVDBID != 0 && existing.TVDBID == series.TVDBID {
+			writeError(w, http.StatusBadRequest, "This series has already been added")
+			return
+		}
+	}
+	series.ID = s.newID()
+	s.fixture.Series = append(s.fixture.Series, series)
+	writeJSON(w, http.StatusCreated, series)
+}
+
+func (s *Server) handleSeriesLookup(w http.ResponseWriter, r *http.Request) {
+	term := r.URL.Query().Get("term")
+	idStr := term
+	if i := strings.Index(term, ":"); i >= 0 {
+		idStr = term[i+1:]
+	}
+	tvdbID, _ := strconv.Atoi(strings.TrimSpace(idStr))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	results := []models.SeriesLookup{}
+	for _, lookup := range s.fixture.SeriesLookups {
+		if lookup.TVDBID == tvdbID || (tvdbID == 0 && strings.Contains(strings.ToLower(lookup.Title), strings.ToLower(term))) {
+			results = append(results, lookup)
+		}
+	}
+	writeJSON(w, http.StatusOK, results)
+}
+
+func (s *Server) handleEpisodes(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	seriesID, _ := strconv.Atoi(query.Get("seriesId"))
+	season, seasonErr := strconv.Atoi(query.Get("seasonNumber"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	episodes := []models.Episode{}
+	for _, episode := range s.fixture.Episodes {
+		if seriesID != 0 && episode.SeriesID != seriesID {
+			continue
+		}
+		if seasonErr == nil && episode.SeasonNumber != season {
+			continue
+		}
+		episodes = append(episodes, episode)
+	}
+	writeJSON(w, http.StatusOK, episodes)
+}
+
+func (s *Server) handleEpisode(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, episode := range s.fixture.Episodes {
+		if episode.ID == id {
+			writeJSON(w, http.StatusOK, episode)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "episode not found")
+}
+
+func (s *Server) handleMonitorEpisodes(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		EpisodeIDs []int `json:"episodeIds"`
+		Monitored  bool  `json:"monitored"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid monitor request: "+err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	episodes := []models.Episode{}
+	for _, id := range body.EpisodeIDs {
+		for _, episode := range s.fixture.Episodes {
+			if episode.ID == id {
+				episodes = append(episodes, episode)
+			}
+		}
+	}
+	writeJSON(w, http.StatusAccepted, episodes)
+}
+
+func (s *Server) handleEpisodeFiles(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	wanted := make(map[int]bool)
+	for _, value := range query["episodeFileIds"] {
+		for _, part := range strings.Split(value, ",") {
+			if id, err := strconv.Atoi(strings.TrimSpace(part)); err == nil {
+				wanted[id] = true
+			}
+		}
+	}
+	seriesID, _ := strconv.Atoi(query.Get("seriesId"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seriesFiles := make(map[int]bool)
+	if seriesID != 0 {
+		for _, episode := range s.fixture.Episodes {
+			if episode.SeriesID == seriesID && episode.EpisodeFileID != nil {
+				seriesFiles[*episode.EpisodeFileID] = true
+			}
+		}
+	}
+
+	files := []models.EpisodeFile{}
+	for _, file := range s.fixture.EpisodeFiles {
+		if wanted[file.ID] || seriesFiles[file.ID] {
+			files = append(files, file)
+		}
+	}
+	writeJSON(w, http.StatusOK, files)
+}
+
+func (s *Server) handleDeleteEpisodeFile(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, file := range s.fixture.EpisodeFiles {
+		if file.ID != id {
+			continue
+		}
+		s.fixture.EpisodeFiles = append(s.fixture.EpisodeFiles[:i], s.fixture.EpisodeFiles[i+1:]...)
+		// Like Sonarr, unlink the file from its episodes
+		for j := range s.fixture.Episodes {
+			if fileID := s.fixture.Episodes[j].EpisodeFileID; fileID != nil && *fileID == id {
+				s.fixture.Episodes[j].HasFile = false
+				s.fixture.Episodes[j].EpisodeFileID = nil
+			}
+		}
+		writeJSON(w, http.StatusOK, struct{}{})
+		return
+	}
+	writeError(w, http.StatusNotFound, "episode file not found")
+}
+
+// queueRecord is a queue item in the shape Sonarr's paged queue endpoint returns
+type queueRecord struct {
+	models.QueueItem
+	SeriesID int `json:"seriesId,omitempty"`
+}
+
+func (s *Server) handleQueue(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	page, _ := strconv.Atoi(query.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(query.Get("pageSize"))
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Filter by status like Sonarr v4 does
+	filter := models.QueueOptions{Statuses: query["status"]}
+	var queue []models.QueueItem
+	for _, item := range s.fixture.Queue {
+		if filter.Matches(item) {
+			queue = append(queue, item)
+		}
+	}
+
+	total := len(queue)
+	start := (page - 1) * pageSize
+	if start > total {
+		start = total
+	}
+	end := start + pageSize
+	if end > total {
+		end = total
+	}
+
+	records := make([]queueRecord, 0, end-start)
+	for _, item := range queue[start:end] {
+		record := queueRecord{QueueItem: item}
+		if item.Series != nil {
+			record.SeriesID = item.Series.ID
+		}
+		records = append(records, record)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"page":          page,
+		"pageSize":      pageSize,
+		"sortKey":       query.Get("sortKey"),
+		"sortDirection": "ascending",
+		"totalRecords":  total,
+		"records":       records,
+	})
+}
+
+func (s *Server) handleDeleteQueue(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, item := range s.fixture.Queue {
+		if item.ID == id {
+			s.fixture.Queue = append(s.fixture.Queue[:i], s.fixture.Queue[i+1:]...)
+			writeJSON(w, http.StatusOK, struct{}{})
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "queue item not found")
+}
+
+// handleManualImport returns the matching candidates as an array, as Sonarr does
+func (s *Server) handleManualImport(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	folder := query.Get("folder")
+	downloadID := query.Get("downloadId")
+	seriesID, _ := strconv.Atoi(query.Get("seriesId"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	items := []models.ManualImportItem{}
+	for _, item := range s.fixture.ManualImport {
+		if folder != "" && !strings.HasPrefix(item.Path, folder) {
+			continue
+		}
+		if downloadID != "" && item.DownloadID != downloadID {
+			continue
+		}
+		if seriesID != 0 && (item.Series == nil || item.Series.ID != seriesID) {
+			continue
+		}
+		items = append(items, item)
+	}
+	writeJSON(w, http.StatusOK, items)
+}
+
+func (s *Server) handleExecuteManualImport(w http.ResponseWriter, r *http.Request) {
+	var body json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid manual import: "+err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.imports = append(s.imports, body)
+	writeJSON(w, http.StatusOK, body)
+}
+
+// movieIndex returns the index of a movie in the fixture, or -1. Callers hold mu.
+func (s *Server) movieIndex(id int) int {
+	for i, movie := range s.fixture.Movies {
+		if movie.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// newID returns an ID for a created resource that doesn't collide with fixture IDs. Callers hold mu.
+func (s *Server) newID() int {
+	ids := make([]int, 0, len(s.fixture.Series)+len(s.fixture.Movies))
+	for _, series := range s.fixture.Series {
+		ids = append(ids, series.ID)
+	}
+	for _, movie := range s.fixture.Movies {
+		ids = append(ids, movie.ID)
+	}
+	sort.Ints(ids)
+	if len(ids) > 0 && ids[len(ids)-1] >= s.nextID {
+		s.nextID = ids[len(ids)-1] + 1
+	}
+
+	id := s.nextID
+	s.nextID++
+	return id
+}
+
+// pathID parses the {id} path value, writing a 400 response when it isn't a number
+func pathID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id: "+r.PathValue("id"))
+		return 0, false
+	}
+	return id, true
+}
+
+// nonNil returns an empty slice for nil so lists encode as [] like the real API
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
+
+// writeError writes an error in the shape the *arr APIs use
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"message": message})
+}
+
+// writeJSON writes v as a JSON response with the given status code
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
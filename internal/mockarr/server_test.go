@@ -0,0 +1,218 @@
+package mockarr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hnipps/refresharr/internal/arr"
+	"github.com/hnipps/refresharr/internal/config"
+	"github.com/hnipps/refresharr/internal/filesystem"
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+type mockLogger struct{}
+
+func (m *mockLogger) Debug(msg string, args ...interface{}) {}
+func (m *mockLogger) Info(msg string, args ...interface{})  {}
+func (m *mockLogger) Warn(msg string, args ...interface{})  {}
+func (m *mockLogger) Error(msg string, args ...interface{}) {}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func sonarrFixture() arr.SimulationFixture {
+	return arr.SimulationFixture{
+		Series: []models.Series{{MediaItem: models.MediaItem{ID: 1, Title: "Show"}, TVDBID: 100}},
+		Episodes: []models.Episode{
+			{ID: 11, SeriesID: 1, SeasonNumber: 1, EpisodeNumber: 1, Title: "Pilot", HasFile: true, EpisodeFileID: intPtr(101)},
+			{ID: 12, SeriesID: 1, SeasonNumber: 1, EpisodeNumber: 2, Title: "Second", HasFile: true, EpisodeFileID: intPtr(102)},
+		},
+		EpisodeFiles: []models.EpisodeFile{
+			{ID: 101, Path: "/tv/Show/S01E01.mkv"},
+			{ID: 102, Path: "/tv/Show/S01E02.mkv"},
+		},
+		Queue: []models.QueueItem{
+			{ID: 7, Title: "Show.S01E03", Status: "completed", DownloadID: "abc"},
+			{ID: 8, Title: "Show.S01E04", Status: "downloading", DownloadID: "def"},
+		},
+		ManualImport: []models.ManualImportItem{
+			{Path: "/downloads/Show.S01E03/Show.S01E03.mkv", Name: "Show.S01E03", DownloadID: "abc"},
+			{Path: "/downloads/Other/Other.mkv", Name: "Other", DownloadID: "xyz"},
+		},
+	}
+}
+
+func TestServer_SonarrCleanupRun(t *testing.T) {
+	server := NewServer("sonarr", sonarrFixture(), "secret")
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	client := arr.NewSonarrClient(&config.SonarrConfig{URL: ts.URL, APIKey: "secret"}, 5*time.Second, &mockLogger{})
+	if err := client.TestConnection(context.Background()); err != nil {
+		t.Fatalf("TestConnection() failed: %v", err)
+	}
+
+	fileChecker := filesystem.NewManifestChecker(filesystem.Manifest{
+		Files: []filesystem.ManifestFile{{Path: "/tv/Show/S01E01.mkv"}},
+	})
+	service := arr.NewCleanupServiceWithOptions(client, fileChecker, &mockLogger{}, arr.NewConsoleProgressReporter(&mockLogger{}), arr.CleanupOptions{
+		ConcurrentLimit: 1,
+	})
+
+	result, err := service.CleanupMissingFilesForSeries(context.Background(), []int{1})
+	if err != nil {
+		t.Fatalf("CleanupMissingFilesForSeries() failed: %v", err)
+	}
+	if result.Stats.MissingFiles != 1 || result.Stats.DeletedRecords != 1 {
+		t.Errorf("Expected 1 missing file deleted, got %+v", result.Stats)
+	}
+
+	fixture := server.Fixture()
+	if len(fixture.EpisodeFiles) != 1 || fixture.EpisodeFiles[0].ID != 101 {
+		t.Errorf("Expected only episode file 101 to remain, got %+v", fixture.EpisodeFiles)
+	}
+	if fixture.Episodes[1].HasFile || fixture.Episodes[1].EpisodeFileID != nil {
+		t.Errorf("Expected episode 12 to be unlinked from its file, got %+v", fixture.Episodes[1])
+	}
+
+	deleted := false
+	for _, request := range server.Requests() {
+		if request == "DELETE /api/v3/episodeFile/102" {
+			deleted = true
+		}
+	}
+	if !deleted {
+		t.Errorf("Expected a DELETE for episode file 102, got %v", server.Requests())
+	}
+
+	commands := server.Commands()
+	if len(commands) != 1 || commands[0].Name != "MissingEpisodeSearch" {
+		t.Errorf("Expected one MissingEpisodeSearch command, got %+v", commands)
+	}
+}
+
+func TestServer_SonarrQueue(t *testing.T) {
+	server := NewServer("sonarr", sonarrFixture(), "")
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	client := arr.NewSonarrClient(&config.SonarrConfig{URL: ts.URL, APIKey: "any"}, 5*time.Second, &mockLogger{})
+	ctx := context.Background()
+
+	queue, err := client.GetQueue(ctx)
+	if err != nil {
+		t.Fatalf("GetQueue() failed: %v", err)
+	}
+	if len(queue) != 2 || queue[0].DownloadID != "abc" {
+		t.Fatalf("Expected 2 queue items, got %+v", queue)
+	}
+
+	if err := client.RemoveFromQueue(ctx, 7, false); err != nil {
+		t.Fatalf("RemoveFromQueue() failed: %v", err)
+	}
+	if remaining := server.Fixture().Queue; len(remaining) != 1 || remaining[0].ID != 8 {
+		t.Errorf("Expected only queue item 8 to remain, got %+v", remaining)
+	}
+}
+
+func TestServer_RadarrMovieFile(t *testing.T) {
+	clients := map[string]func(cfg *config.RadarrConfig, timeout time.Duration, logger arr.Logger) arr.Client{
+		"builtin": arr.NewRadarrClient,
+		"starr":   arr.NewStarrRadarrClient,
+	}
+
+	for name, newClient := range clients {
+		t.Run(name, func(t *testing.T) {
+			fixture := arr.SimulationFixture{
+				Movies: []models.Movie{
+					{MediaItem: models.MediaItem{ID: 5, Title: "Film"}, HasFile: true, MovieFileID: intPtr(50), TMDBID: 500},
+				},
+				MovieFiles: []models.MovieFile{{ID: 50, Path: "/movies/Film/Film.mkv", MovieID: 5}},
+			}
+			server := NewServer("radarr", fixture, "secret")
+			ts := httptest.NewServer(server)
+			defer ts.Close()
+
+			client := newClient(&config.RadarrConfig{URL: ts.URL, APIKey: "secret"}, 5*time.Second, &mockLogger{})
+			ctx := context.Background()
+
+			movie, err := client.GetMovieByTMDBID(ctx, 500)
+			if err != nil || movie.ID != 5 || movie.MovieFileID == nil || *movie.MovieFileID != 50 {
+				t.Fatalf("Expected movie 5 with file 50, got %+v (err %v)", movie, err)
+			}
+
+			file, err := client.GetMovieFile(ctx, 50)
+			if err != nil || file.Path != "/movies/Film/Film.mkv" {
+				t.Fatalf("Expected movie file 50, got %+v (err %v)", file, err)
+			}
+			if err := client.DeleteMovieFile(ctx, 50); err != nil {
+				t.Fatalf("DeleteMovieFile() failed: %v", err)
+			}
+			if _, err := client.GetMovieFile(ctx, 50); !errors.Is(err, arr.ErrNotFound) {
+				t.Errorf("Expected ErrNotFound for the deleted movie file, got %v", err)
+			}
+
+			movie, err = client.GetMovie(ctx, 5)
+			if err != nil {
+				t.Fatalf("GetMovie() failed: %v", err)
+			}
+			if movie.HasFile || movie.MovieFileID != nil {
+				t.Errorf("Expected the movie to be unlinked from its file, got %+v", movie)
+			}
+			if err := client.UpdateMovie(ctx, *movie); err != nil {
+				t.Fatalf("UpdateMovie() failed: %v", err)
+			}
+
+			if err := client.TriggerRefresh(ctx); err != nil {
+				t.Fatalf("TriggerRefresh() failed: %v", err)
+			}
+			if commands := server.Commands(); len(commands) != 1 {
+				t.Errorf("Expected one command, got %+v", commands)
+			}
+		})
+	}
+}
+
+func TestServer_RejectsWrongAPIKey(t *testing.T) {
+	ts := httptest.NewServer(NewServer("sonarr", sonarrFixture(), "secret"))
+	defer ts.Close()
+
+	client := arr.NewSonarrClient(&config.SonarrConfig{URL: ts.URL, APIKey: "wrong"}, 5*time.Second, &mockLogger{})
+	if err := client.TestConnection(context.Background()); err == nil {
+		t.Error("Expected TestConnection() to fail with the wrong API key")
+	}
+}
+
+func TestServer_ManualImport(t *testing.T) {
+	server := NewServer("sonarr", sonarrFixture(), "")
+
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v3/manualimport?folder=/downloads/Show.S01E03&downloadId=abc", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var items []models.ManualImportItem
+	if err := json.Unmarshal(rec.Body.Bytes(), &items); err != nil {
+		t.Fatalf("Expected an array of candidates: %v", err)
+	}
+	if len(items) != 1 || items[0].Name != "Show.S01E03" {
+		t.Errorf("Expected only the matching candidate, got %+v", items)
+	}
+
+	rec = httptest.NewRecorder()
+	body := `{"path": "/downloads/Show.S01E03/Show.S01E03.mkv", "seriesId": 1, "episodeIds": [13]}`
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v3/manualimport", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if imports := server.ManualImports(); len(imports) != 1 {
+		t.Errorf("Expected one manual import to be recorded, got %d", len(imports))
+	}
+}
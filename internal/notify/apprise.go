@@ -0,0 +1,300 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hnipps/refresharr/internal/arr"
+)
+
+// SchemeFactory creates the notifier for a notification URL in Apprise syntax, such as
+// ntfy://topic or discord://webhook_id/webhook_token
+type SchemeFactory func(u *url.URL, httpClient *http.Client, logger arr.Logger) (Notifier, error)
+
+// schemes maps the Apprise URL schemes that are understood to the notifiers they create
+var schemes = map[string]SchemeFactory{
+	"json":     newJSONNotifier,
+	"jsons":    newJSONNotifier,
+	"ntfy":     newNtfyNotifier,
+	"ntfys":    newNtfyNotifier,
+	"pover":    newPushoverNotifier,
+	"discord":  newDiscordNotifier,
+	"slack":    newSlackNotifier,
+	"gotify":   newGotifyNotifier,
+	"gotifys":  newGotifyNotifier,
+	"apprise":  newAppriseNotifier,
+	"apprises": newAppriseNotifier,
+}
+
+// RegisterScheme makes notification URLs with the scheme create notifiers with factory
+func RegisterScheme(scheme string, factory SchemeFactory) {
+	schemes[strings.ToLower(scheme)] = factory
+}
+
+// Schemes returns the notification URL schemes that are understood, sorted
+func Schemes() []string {
+	names := make([]string, 0, len(schemes))
+	for name := range schemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseURL creates the notifier for a notification URL. Errors name only the scheme, as the
+// rest of the URL usually holds a token.
+func ParseURL(raw string, timeout time.Duration, logger arr.Logger) (Notifier, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Scheme == "" {
+		return nil, fmt.Errorf("not a URL of the form scheme://...")
+	}
+	factory, ok := schemes[strings.ToLower(u.Scheme)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported scheme %s:// (supported: %s; use apprise:// to reach the rest through an Apprise API server)",
+			u.Scheme, strings.Join(Schemes(), ", "))
+	}
+	notifier, err := factory(u, &http.Client{Timeout: timeout}, logger)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s:// URL: %w", u.Scheme, err)
+	}
+	return notifier, nil
+}
+
+// parsedURL is a notification URL parsed by URLNotifiers: its notifier, or why it can't be used
+type parsedURL struct {
+	notifier Notifier
+	err      error
+}
+
+// parsedURLs holds the notification URLs parsed so far, by URL and timeout
+var parsedURLs sync.Map // parsedURLKey -> parsedURL
+
+// parsedURLKey identifies a parsed notification URL
+type parsedURLKey struct {
+	raw     string
+	timeout time.Duration
+}
+
+// URLNotifiers returns a notifier for each notification URL. URLs that can't be used are
+// logged and skipped, so one bad URL doesn't silence the others. Each URL is parsed, and
+// warned about, only the first time it's seen, so a long-running serve doesn't repeat the
+// warning for every job; its notifier logs to the logger it was first created with.
+func URLNotifiers(urls []string, timeout time.Duration, logger arr.Logger) []Notifier {
+	var notifiers []Notifier
+	for i, raw := range urls {
+		key := parsedURLKey{raw: raw, timeout: timeout}
+		cached, seen := parsedURLs.Load(key)
+		if !seen {
+			notifier, err := ParseURL(raw, timeout, logger)
+			cached, seen = parsedURLs.LoadOrStore(key, parsedURL{notifier: notifier, err: err})
+			if !seen && err != nil {
+				logger.Warn("Skipping notification URL %d: %s", i+1, err.Error())
+			}
+		}
+		if parsed := cached.(parsedURL); parsed.err == nil {
+			notifiers = append(notifiers, parsed.notifier)
+		}
+	}
+	return notifiers
+}
+
+// pathParts returns the non-empty segments of the URL's path
+func pathParts(u *url.URL) []string {
+	var parts []string
+	for _, part := range strings.Split(u.Path, "/") {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+// basePath returns the path the segments before the last one form, "" when there are none
+func basePath(parts []string) string {
+	if len(parts) <= 1 {
+		return ""
+	}
+	return "/" + strings.Join(parts[:len(parts)-1], "/")
+}
+
+// secureScheme returns "https" for the secure variant of a scheme (ending in s), otherwise "http"
+func secureScheme(u *url.URL, plain string) string {
+	if strings.EqualFold(u.Scheme, plain) {
+		return "http"
+	}
+	return "https"
+}
+
+// alertType returns the Apprise notification type of an alert
+func alertType(alert *Alert) string {
+	switch {
+	case alert.Failed:
+		return "failure"
+	case len(alert.Reasons) > 0:
+		return "warning"
+	default:
+		return "info"
+	}
+}
+
+// jsonNotifier posts a JSON body built from each alert to a URL
+type jsonNotifier struct {
+	name       string
+	url        string
+	body       func(alert *Alert) interface{}
+	httpClient *http.Client
+	logger     arr.Logger
+}
+
+// Name returns the URL scheme the notifier was created from
+func (n *jsonNotifier) Name() string {
+	return n.name
+}
+
+// Send posts the alert's body
+func (n *jsonNotifier) Send(ctx context.Context, alert *Alert) error {
+	body, err := json.Marshal(n.body(alert))
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s notification: %w", n.name, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create %s request: %w", n.name, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	n.logger.Debug("Sending alert to %s for %s", n.name, alert.Service)
+	return sendPush(n.httpClient, req, n.name)
+}
+
+// newJSONNotifier handles json://host/path and jsons://host/path, which receive the alert as
+// it is sent to NOTIFY_WEBHOOK_URL
+func newJSONNotifier(u *url.URL, httpClient *http.Client, logger arr.Logger) (Notifier, error) {
+	if u.Host == "" {
+		return nil, fmt.Errorf("missing host")
+	}
+	target := *u
+	target.Scheme = secureScheme(u, "json")
+	return &jsonNotifier{
+		name:       "json",
+		url:        target.String(),
+		body:       func(alert *Alert) interface{} { return alert },
+		httpClient: httpClient,
+		logger:     logger,
+	}, nil
+}
+
+// newNtfyNotifier handles ntfy://topic (on ntfy.sh), ntfy://host/topic and ntfys://host/topic,
+// with optional user:password@ or ?token=
+func newNtfyNotifier(u *url.URL, httpClient *http.Client, logger arr.Logger) (Notifier, error) {
+	parts := pathParts(u)
+	client := &NtfyClient{token: u.Query().Get("token"), httpClient: httpClient, logger: logger}
+	switch {
+	case u.Host != "" && len(parts) == 0:
+		client.url = "https://ntfy.sh/" + u.Host
+	case u.Host != "" && len(parts) == 1:
+		client.url = fmt.Sprintf("%s://%s/%s", secureScheme(u, "ntfy"), u.Host, parts[0])
+	default:
+		return nil, fmt.Errorf("expected ntfy://topic or ntfy://host/topic")
+	}
+	if u.User != nil {
+		client.user = u.User.Username()
+		client.password, _ = u.User.Password()
+	}
+	return client, nil
+}
+
+// newPushoverNotifier handles pover://user_key@app_token
+func newPushoverNotifier(u *url.URL, httpClient *http.Client, logger arr.Logger) (Notifier, error) {
+	if u.User == nil || u.User.Username() == "" || u.Host == "" {
+		return nil, fmt.Errorf("expected pover://user_key@app_token")
+	}
+	return &PushoverClient{token: u.Host, user: u.User.Username(), httpClient: httpClient, logger: logger}, nil
+}
+
+// newDiscordNotifier handles discord://webhook_id/webhook_token
+func newDiscordNotifier(u *url.URL, httpClient *http.Client, logger arr.Logger) (Notifier, error) {
+	parts := pathParts(u)
+	if u.Host == "" || len(parts) != 1 {
+		return nil, fmt.Errorf("expected discord://webhook_id/webhook_token")
+	}
+	return &jsonNotifier{
+		name:       "discord",
+		url:        fmt.Sprintf("https://discord.com/api/webhooks/%s/%s", u.Host, parts[0]),
+		body:       func(alert *Alert) interface{} { return map[string]string{"content": alert.Text} },
+		httpClient: httpClient,
+		logger:     logger,
+	}, nil
+}
+
+// newSlackNotifier handles slack://TokenA/TokenB/TokenC, the parts of an incoming webhook URL
+func newSlackNotifier(u *url.URL, httpClient *http.Client, logger arr.Logger) (Notifier, error) {
+	parts := pathParts(u)
+	if u.Host == "" || len(parts) != 2 {
+		return nil, fmt.Errorf("expected slack://TokenA/TokenB/TokenC")
+	}
+	return &jsonNotifier{
+		name:       "slack",
+		url:        fmt.Sprintf("https://hooks.slack.com/services/%s/%s/%s", u.Host, parts[0], parts[1]),
+		body:       func(alert *Alert) interface{} { return map[string]string{"text": alert.Text} },
+		httpClient: httpClient,
+		logger:     logger,
+	}, nil
+}
+
+// newGotifyNotifier handles gotify://host/token and gotifys://host/token
+func newGotifyNotifier(u *url.URL, httpClient *http.Client, logger arr.Logger) (Notifier, error) {
+	parts := pathParts(u)
+	if u.Host == "" || len(parts) == 0 {
+		return nil, fmt.Errorf("expected gotify://host/token")
+	}
+	// Gotify may be served under a path; the token is the last segment
+	base, token := basePath(parts), parts[len(parts)-1]
+	target := fmt.Sprintf("%s://%s%s/message?token=%s", secureScheme(u, "gotify"), u.Host, base, url.QueryEscape(token))
+	return &jsonNotifier{
+		name: "gotify",
+		url:  target,
+		body: func(alert *Alert) interface{} {
+			priority := 5
+			if alert.Failed {
+				priority = 8
+			}
+			return map[string]interface{}{"title": pushTitle, "message": alert.Text, "priority": priority}
+		},
+		httpClient: httpClient,
+		logger:     logger,
+	}, nil
+}
+
+// newAppriseNotifier handles apprise://host/config_key and apprises://host/config_key. The
+// alert goes to an Apprise API server, which sends it to every service saved under the key.
+func newAppriseNotifier(u *url.URL, httpClient *http.Client, logger arr.Logger) (Notifier, error) {
+	parts := pathParts(u)
+	if u.Host == "" || len(parts) == 0 {
+		return nil, fmt.Errorf("expected apprise://host/config_key")
+	}
+	// Like Gotify, the server may be served under a path, and the key is the last segment
+	target := url.URL{
+		Scheme: secureScheme(u, "apprise"),
+		User:   u.User,
+		Host:   u.Host,
+		Path:   basePath(parts) + "/notify/" + parts[len(parts)-1],
+	}
+	return &jsonNotifier{
+		name: "apprise",
+		url:  target.String(),
+		body: func(alert *Alert) interface{} {
+			return map[string]string{"title": pushTitle, "body": alert.Text, "type": alertType(alert)}
+		},
+		httpClient: httpClient,
+		logger:     logger,
+	}, nil
+}
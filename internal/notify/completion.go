@@ -0,0 +1,149 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hnipps/refresharr/internal/arr"
+	"github.com/hnipps/refresharr/internal/config"
+	"github.com/hnipps/refresharr/internal/messages"
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+// Completion summarizes a finished run for the completion webhook
+type Completion struct {
+	Text       string              `json:"text"` // One-line summary; the field Slack-compatible webhooks display
+	RunID      string              `json:"runId"`
+	Command    string              `json:"command"`
+	Service    string              `json:"service"`
+	DryRun     bool                `json:"dryRun"`
+	Success    bool                `json:"success"`
+	Error      string              `json:"error,omitempty"`
+	StartedAt  time.Time           `json:"startedAt"`
+	FinishedAt time.Time           `json:"finishedAt"`
+	Stats      models.CleanupStats `json:"stats"`
+	ReportPath string              `json:"reportPath,omitempty"`
+}
+
+// CompletionFor returns the completion summary of a run, worded with the catalog's text
+func CompletionFor(run *models.RunResult, catalog *messages.Catalog) *Completion {
+	completion := &Completion{
+		RunID:      run.RunID,
+		Command:    run.Command,
+		Service:    run.Service,
+		DryRun:     run.DryRun,
+		Success:    run.Success,
+		Error:      run.Error,
+		StartedAt:  run.StartedAt,
+		FinishedAt: run.FinishedAt,
+		Stats:      run.Stats,
+		ReportPath: run.ReportPath,
+	}
+
+	if run.Error != "" {
+		completion.Text = catalog.Sprintf(messages.CompletionFailed, run.Command, run.Service, run.Error)
+	} else {
+		completion.Text = catalog.Sprintf(messages.CompletionText, run.Command, run.Service,
+			run.Stats.TotalItemsChecked, run.Stats.MissingFiles, run.Stats.DeletedRecords, run.Stats.Errors)
+	}
+	return completion
+}
+
+// completionRetryDelay is how long to wait before the first retry of a completion webhook. Each
+// further retry waits twice as long as the one before.
+var completionRetryDelay = time.Second
+
+// CompletionClient posts run summaries as JSON to the completion webhook, retrying failed deliveries
+type CompletionClient struct {
+	url        string
+	retries    int
+	httpClient *http.Client
+	logger     arr.Logger
+}
+
+// NewCompletionClient creates a client for the completion webhook the configuration sets, or
+// returns nil when none is set
+func NewCompletionClient(cfg *config.NotifyConfig, timeout time.Duration, logger arr.Logger) *CompletionClient {
+	if cfg.CompletionURL == "" {
+		return nil
+	}
+	return &CompletionClient{
+		url:     cfg.CompletionURL,
+		retries: cfg.CompletionRetries,
+		httpClient: &http.Client{
+			Timeout: timeout,
+		},
+		logger: logger,
+	}
+}
+
+// Send posts the summary to the webhook. Network errors, 429 and 5xx responses are retried with
+// a growing delay, up to the configured number of retries or until ctx is done.
+func (c *CompletionClient) Send(ctx context.Context, completion *Completion) error {
+	body, err := json.Marshal(completion)
+	if err != nil {
+		return fmt.Errorf("failed to marshal completion: %w", err)
+	}
+
+	delay := completionRetryDelay
+	for attempt := 0; ; attempt++ {
+		err = c.post(ctx, body)
+		var permanent *permanentError
+		if err == nil || errors.As(err, &permanent) || attempt >= c.retries {
+			return err
+		}
+
+		c.logger.Debug("Completion webhook for %s failed, retrying in %s: %s", completion.Service, delay, err.Error())
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w (gave up retrying: %v)", err, ctx.Err())
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// permanentError is a webhook response that retrying won't change
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// post sends body to the webhook once
+func (c *CompletionClient) post(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return &permanentError{fmt.Errorf("failed to create completion webhook request: %w", err)}
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send completion webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		err := fmt.Errorf("completion webhook returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+		if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500 {
+			return &permanentError{err}
+		}
+		return err
+	}
+	return nil
+}
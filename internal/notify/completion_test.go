@@ -0,0 +1,104 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hnipps/refresharr/internal/config"
+	"github.com/hnipps/refresharr/internal/history"
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+func TestCompletionFor(t *testing.T) {
+	run := &history.Run{
+		ID:         "run-1",
+		Command:    "cleanup",
+		Service:    "radarr",
+		Success:    true,
+		Stats:      models.CleanupStats{TotalItemsChecked: 120, MissingFiles: 3, DeletedRecords: 3},
+		ReportPath: "reports/radarr-missing-files-report-20260101-030000.json",
+	}
+	completion := CompletionFor(run.Result(), nil)
+	if completion.Text != "RefreshArr cleanup (radarr) finished: 120 checked, 3 missing, 3 deleted, 0 errors" {
+		t.Errorf("Unexpected text %q", completion.Text)
+	}
+	if completion.RunID != "run-1" || completion.ReportPath != run.ReportPath || completion.Stats.MissingFiles != 3 {
+		t.Errorf("Unexpected completion: %+v", completion)
+	}
+
+	run.Success = false
+	run.Error = "connection test failed"
+	if completion := CompletionFor(run.Result(), nil); completion.Text != "RefreshArr cleanup (radarr) failed: connection test failed" {
+		t.Errorf("Unexpected text for a failed run %q", completion.Text)
+	}
+}
+
+func TestNewCompletionClient(t *testing.T) {
+	if client := NewCompletionClient(&config.NotifyConfig{}, time.Second, &mockLogger{}); client != nil {
+		t.Error("Expected no client without a completion webhook URL")
+	}
+}
+
+func TestCompletionClient_Retries(t *testing.T) {
+	completionRetryDelay = time.Millisecond
+	defer func() { completionRetryDelay = time.Second }()
+
+	var attempts atomic.Int32
+	var received Completion
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) < 3 {
+			http.Error(w, "starting up", http.StatusServiceUnavailable)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("Failed to decode completion: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	cfg := &config.NotifyConfig{CompletionURL: server.URL, CompletionRetries: 3}
+	client := NewCompletionClient(cfg, 5*time.Second, &mockLogger{})
+	if err := client.Send(context.Background(), &Completion{RunID: "run-1", Service: "sonarr"}); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if attempts.Load() != 3 || received.RunID != "run-1" {
+		t.Errorf("Expected delivery on the third attempt, got %d attempts and %+v", attempts.Load(), received)
+	}
+
+	// Retries run out: the first attempt and 3 retries all hit the failing server
+	attempts.Store(-10)
+	err := client.Send(context.Background(), &Completion{RunID: "run-2", Service: "sonarr"})
+	if err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("Expected a status error, got %v", err)
+	}
+	if made := attempts.Load() + 10; made != 4 {
+		t.Errorf("Expected 4 attempts, got %d", made)
+	}
+}
+
+func TestCompletionClient_NoRetryOnClientError(t *testing.T) {
+	completionRetryDelay = time.Millisecond
+	defer func() { completionRetryDelay = time.Second }()
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		http.Error(w, "no such hook", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	cfg := &config.NotifyConfig{CompletionURL: server.URL, CompletionRetries: 3}
+	err := NewCompletionClient(cfg, 5*time.Second, &mockLogger{}).Send(context.Background(), &Completion{Service: "sonarr"})
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Expected status error, got %v", err)
+	}
+	if attempts.Load() != 1 {
+		t.Errorf("Expected a 404 not to be retried, got %d attempts", attempts.Load())
+	}
+}
@@ -0,0 +1,110 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hnipps/refresharr/internal/config"
+	"github.com/hnipps/refresharr/internal/history"
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+func TestPushNotifiers(t *testing.T) {
+	if notifiers := PushNotifiers(&config.NotifyConfig{WebhookURL: "http://hooks.local"}, time.Second, &mockLogger{}); len(notifiers) != 0 {
+		t.Errorf("Expected no push notifiers for a plain webhook, got %d", len(notifiers))
+	}
+
+	cfg := &config.NotifyConfig{WebhookURL: "http://hooks.local", NtfyURL: "https://ntfy.sh/alerts", PushoverToken: "app", PushoverUser: "user"}
+	if notifiers := PushNotifiers(cfg, time.Second, &mockLogger{}); len(notifiers) != 2 || notifiers[0].Name() != "ntfy" || notifiers[1].Name() != "pushover" {
+		t.Errorf("Expected ntfy and pushover notifiers, got %v", notifiers)
+	}
+	if notifiers := Notifiers(cfg, time.Second, &mockLogger{}); len(notifiers) != 3 {
+		t.Errorf("Expected alerts to go to the webhook and both push services, got %d notifiers", len(notifiers))
+	}
+}
+
+func TestRunAlert(t *testing.T) {
+	run := &history.Run{ID: "run-1", Command: "cleanup", Service: "sonarr", Stats: models.CleanupStats{TotalItemsChecked: 10}}
+	if alert := RunAlert(run.Result(), nil); alert.Failed || alert.Text != "RefreshArr cleanup (sonarr) finished: 10 checked, 0 missing, 0 deleted, 0 errors" {
+		t.Errorf("Unexpected alert for a finished run: %+v", alert)
+	}
+
+	run.Error = "connection test failed"
+	if alert := RunAlert(run.Result(), nil); !alert.Failed || alert.Text != "RefreshArr cleanup (sonarr) failed: connection test failed" {
+		t.Errorf("Unexpected alert for a failed run: %+v", alert)
+	}
+
+	fixed := &models.ImportFixResult{TotalStuckItems: 3, FixedItems: 2, Errors: []string{"Queue item 7: no files found"}, Success: true}
+	if alert := RunAlert(fixed.RunResult("run-2", time.Now(), time.Now()), nil); alert.Failed || alert.RunID != "run-2" || alert.Text != "RefreshArr fix-imports (sonarr) finished: 3 checked, 0 missing, 0 deleted, 1 errors" {
+		t.Errorf("Unexpected alert for a fix-imports run: %+v", alert)
+	}
+
+	if alert := FailureAlert("fix-imports", errors.New("failed to connect to Sonarr"), nil); !alert.Failed || alert.Text != "RefreshArr fix-imports failed: failed to connect to Sonarr" {
+		t.Errorf("Unexpected alert for a failed command: %+v", alert)
+	}
+}
+
+func TestNtfyClient_Send(t *testing.T) {
+	var body string
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body, headers = string(data), r.Header
+	}))
+	defer server.Close()
+
+	cfg := &config.NotifyConfig{NtfyURL: server.URL + "/alerts", NtfyToken: "tk_secret"}
+	alert := &Alert{Text: "RefreshArr cleanup (sonarr) failed: connection refused", Service: "sonarr", Failed: true}
+	if err := NewNtfyClient(cfg, 5*time.Second, &mockLogger{}).Send(context.Background(), alert); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if body != alert.Text {
+		t.Errorf("Expected the alert text as the message, got %q", body)
+	}
+	if headers.Get("Title") != "RefreshArr" || headers.Get("Priority") != "high" || headers.Get("Authorization") != "Bearer tk_secret" {
+		t.Errorf("Unexpected headers: %v", headers)
+	}
+}
+
+func TestPushoverClient_Send(t *testing.T) {
+	var form map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("Failed to parse form: %v", err)
+		}
+		form = map[string]string{}
+		for key := range r.PostForm {
+			form[key] = r.PostForm.Get(key)
+		}
+		if form["token"] == "bad" {
+			http.Error(w, `{"token":"invalid"}`, http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+	pushoverURL = server.URL
+	defer func() { pushoverURL = "https://api.pushover.net/1/messages.json" }()
+
+	cfg := &config.NotifyConfig{PushoverToken: "app", PushoverUser: "user"}
+	alert := &Alert{Text: "RefreshArr verify (radarr) finished: 5 checked, 0 missing, 0 deleted, 0 errors", Service: "radarr"}
+	if err := NewPushoverClient(cfg, 5*time.Second, &mockLogger{}).Send(context.Background(), alert); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if form["token"] != "app" || form["user"] != "user" || form["message"] != alert.Text || form["title"] != "RefreshArr" {
+		t.Errorf("Unexpected form: %v", form)
+	}
+	if _, ok := form["priority"]; ok {
+		t.Errorf("Expected a finished run to be sent with normal priority, got %q", form["priority"])
+	}
+
+	cfg.PushoverToken = "bad"
+	err := NewPushoverClient(cfg, 5*time.Second, &mockLogger{}).Send(context.Background(), alert)
+	if err == nil || !strings.Contains(err.Error(), "400") {
+		t.Errorf("Expected status error, got %v", err)
+	}
+}
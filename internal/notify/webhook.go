@@ -0,0 +1,148 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hnipps/refresharr/internal/arr"
+	"github.com/hnipps/refresharr/internal/config"
+	"github.com/hnipps/refresharr/internal/history"
+	"github.com/hnipps/refresharr/internal/messages"
+)
+
+// Alert describes a condition worth telling someone about
+type Alert struct {
+	Text         string   `json:"text"` // One-line summary; the field Slack-compatible webhooks display
+	Service      string   `json:"service"`
+	RunID        string   `json:"runId"`
+	MissingFiles int      `json:"missingFiles"`
+	Previous     *int     `json:"previousMissingFiles,omitempty"` // Missing files in the previous verify run, if there was one
+	Threshold    int      `json:"threshold,omitempty"`
+	Reasons      []string `json:"reasons"`
+	ReportPath   string   `json:"reportPath,omitempty"`
+	Failed       bool     `json:"failed,omitempty"` // A run or command failed; push services show it with high priority
+}
+
+// VerifyAlert compares a verify run with the previous verify run for the same service and
+// returns an alert when the missing files exceed threshold (0 disables the check) or grew.
+// It returns nil when there is nothing to report. Failed runs never alert, and a failed
+// previous run is not used for comparison.
+func VerifyAlert(current, previous *history.Run, threshold int) *Alert {
+	return VerifyAlertWithMessages(current, previous, threshold, nil)
+}
+
+// VerifyAlertWithMessages behaves like VerifyAlert and words the alert with the catalog's text
+func VerifyAlertWithMessages(current, previous *history.Run, threshold int, catalog *messages.Catalog) *Alert {
+	if current == nil || current.Error != "" {
+		return nil
+	}
+
+	missing := current.Stats.MissingFiles
+	alert := &Alert{
+		Service:      current.Service,
+		RunID:        current.ID,
+		MissingFiles: missing,
+		Threshold:    threshold,
+		ReportPath:   current.ReportPath,
+	}
+
+	if threshold > 0 && missing > threshold {
+		alert.Reasons = append(alert.Reasons, catalog.Sprintf(messages.AlertThreshold, missing, threshold))
+	}
+
+	if previous != nil && previous.Error == "" {
+		prevMissing := previous.Stats.MissingFiles
+		alert.Previous = &prevMissing
+		if missing > prevMissing {
+			alert.Reasons = append(alert.Reasons, catalog.Sprintf(messages.AlertGrew, prevMissing, missing))
+		}
+	}
+
+	if len(alert.Reasons) == 0 {
+		return nil
+	}
+
+	alert.Text = catalog.Sprintf(messages.AlertText, current.Service, strings.Join(alert.Reasons, "; "))
+	return alert
+}
+
+// TestAlert returns a sample alert for checking that notifications arrive
+func TestAlert(catalog *messages.Catalog) *Alert {
+	return &Alert{
+		Text:    catalog.Sprintf(messages.AlertTest),
+		Service: "refresharr",
+		Reasons: []string{catalog.Sprintf(messages.AlertTestReason)},
+	}
+}
+
+// Notifier sends alerts to one notification backend
+type Notifier interface {
+	// Name identifies the backend in logs and command output
+	Name() string
+	Send(ctx context.Context, alert *Alert) error
+}
+
+// Notifiers returns a notifier for every backend the configuration sets up
+func Notifiers(cfg *config.NotifyConfig, timeout time.Duration, logger arr.Logger) []Notifier {
+	var notifiers []Notifier
+	if cfg.WebhookURL != "" {
+		notifiers = append(notifiers, NewWebhookClient(cfg, timeout, logger))
+	}
+	return append(notifiers, PushNotifiers(cfg, timeout, logger)...)
+}
+
+// WebhookClient posts alerts as JSON to a webhook URL
+type WebhookClient struct {
+	url        string
+	httpClient *http.Client
+	logger     arr.Logger
+}
+
+// NewWebhookClient creates a new webhook client
+func NewWebhookClient(cfg *config.NotifyConfig, timeout time.Duration, logger arr.Logger) *WebhookClient {
+	return &WebhookClient{
+		url: cfg.WebhookURL,
+		httpClient: &http.Client{
+			Timeout: timeout,
+		},
+		logger: logger,
+	}
+}
+
+// Name returns "webhook"
+func (c *WebhookClient) Name() string {
+	return "webhook"
+}
+
+// Send posts the alert to the webhook
+func (c *WebhookClient) Send(ctx context.Context, alert *Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("failed to marshal alert: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	c.logger.Debug("Sending alert to webhook for %s", alert.Service)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("webhook returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hnipps/refresharr/internal/config"
+	"github.com/hnipps/refresharr/internal/history"
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+// mockLogger implements arr.Logger for testing
+type mockLogger struct{}
+
+func (m *mockLogger) Debug(msg string, args ...interface{}) {}
+func (m *mockLogger) Info(msg string, args ...interface{})  {}
+func (m *mockLogger) Warn(msg string, args ...interface{})  {}
+func (m *mockLogger) Error(msg string, args ...interface{}) {}
+
+// verifyRun returns a successful verify run with the given number of missing files
+func verifyRun(id string, missing int) *history.Run {
+	return &history.Run{
+		ID:      id,
+		Command: "verify",
+		Service: "sonarr",
+		Success: true,
+		Stats:   models.CleanupStats{MissingFiles: missing},
+	}
+}
+
+func TestVerifyAlert(t *testing.T) {
+	failed := verifyRun("failed", 0)
+	failed.Error = "connection refused"
+
+	tests := []struct {
+		name      string
+		current   *history.Run
+		previous  *history.Run
+		threshold int
+		reasons   int
+	}{
+		{"first run below threshold", verifyRun("b", 3), nil, 10, 0},
+		{"first run above threshold", verifyRun("b", 12), nil, 10, 1},
+		{"unchanged", verifyRun("b", 3), verifyRun("a", 3), 0, 0},
+		{"shrunk", verifyRun("b", 2), verifyRun("a", 3), 0, 0},
+		{"grew", verifyRun("b", 4), verifyRun("a", 3), 0, 1},
+		{"grew above threshold", verifyRun("b", 12), verifyRun("a", 3), 10, 2},
+		{"previous failed", verifyRun("b", 4), failed, 0, 0},
+		{"current failed", failed, verifyRun("a", 0), 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alert := VerifyAlert(tt.current, tt.previous, tt.threshold)
+			if tt.reasons == 0 {
+				if alert != nil {
+					t.Errorf("Expected no alert, got %q", alert.Text)
+				}
+				return
+			}
+			if alert == nil {
+				t.Fatal("Expected an alert")
+			}
+			if len(alert.Reasons) != tt.reasons {
+				t.Errorf("Expected %d reasons, got %v", tt.reasons, alert.Reasons)
+			}
+			if !strings.Contains(alert.Text, "sonarr") {
+				t.Errorf("Expected alert text to name the service, got %q", alert.Text)
+			}
+		})
+	}
+}
+
+func TestWebhookClient_Send(t *testing.T) {
+	var received Alert
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("Failed to decode alert: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewWebhookClient(&config.NotifyConfig{WebhookURL: server.URL}, 5*time.Second, &mockLogger{})
+	alert := VerifyAlert(verifyRun("b", 5), verifyRun("a", 2), 0)
+	if err := client.Send(context.Background(), alert); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+
+	if received.MissingFiles != 5 || received.Previous == nil || *received.Previous != 2 {
+		t.Errorf("Unexpected alert payload: %+v", received)
+	}
+	if received.Text == "" {
+		t.Error("Expected alert text in payload")
+	}
+}
+
+func TestWebhookClient_SendError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no such hook", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewWebhookClient(&config.NotifyConfig{WebhookURL: server.URL}, 5*time.Second, &mockLogger{})
+	err := client.Send(context.Background(), VerifyAlert(verifyRun("b", 5), nil, 1))
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Expected status error, got %v", err)
+	}
+}
+
+func TestNotifiers(t *testing.T) {
+	if notifiers := Notifiers(&config.NotifyConfig{}, time.Second, &mockLogger{}); len(notifiers) != 0 {
+		t.Errorf("Expected no notifiers without configuration, got %d", len(notifiers))
+	}
+
+	var received Alert
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer server.Close()
+
+	notifiers := Notifiers(&config.NotifyConfig{WebhookURL: server.URL}, 5*time.Second, &mockLogger{})
+	if len(notifiers) != 1 || notifiers[0].Name() != "webhook" {
+		t.Fatalf("Expected the webhook notifier, got %v", notifiers)
+	}
+	if err := notifiers[0].Send(context.Background(), TestAlert(nil)); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if received.Text == "" || received.Service != "refresharr" {
+		t.Errorf("Expected the test alert, got %+v", received)
+	}
+}
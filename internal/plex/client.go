@@ -7,7 +7,10 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/hnipps/refresharr/internal/arr"
@@ -20,6 +23,10 @@ type PlexClient struct {
 	token      string
 	httpClient *http.Client
 	logger     arr.Logger
+	libraries  []string // Library section names or keys to restrict to (empty means all)
+
+	sectionCache   map[string][]PlexMovie // sectionKey -> full listing, used when GUID queries miss
+	sectionCacheMu sync.Mutex
 }
 
 // PlexMovie represents a movie in Plex
@@ -27,9 +34,61 @@ type PlexMovie struct {
 	Key        string      `json:"key"`
 	Title      string      `json:"title"`
 	Year       int         `json:"year"`
-	GUID       string      `json:"guid"`
-	Available  bool        `json:"-"` // Computed field
-	MediaParts []MediaPart `json:"-"` // Media parts for availability check
+	GUID       string      `json:"guid"` // Legacy agent GUID, e.g. com.plexapp.agents.themoviedb://12345?lang=en
+	Guids      []PlexGuid  `json:"Guid"` // External IDs from the modern Plex agent (requires includeGuids=1)
+	Available  bool        `json:"-"`    // Computed field
+	MediaParts []MediaPart `json:"-"`    // Media parts for availability check
+}
+
+// PlexGuid represents an external ID entry such as tmdb://12345 or imdb://tt0133093
+type PlexGuid struct {
+	ID string `json:"id"`
+}
+
+// legacyTMDBPattern matches TMDB IDs in legacy agent GUIDs
+var legacyTMDBPattern = regexp.MustCompile(`(?:tmdb|themoviedb)://(\d+)`)
+
+// legacyIMDBPattern matches IMDb IDs in legacy agent GUIDs
+var legacyIMDBPattern = regexp.MustCompile(`(?:imdb://)(tt\d+)`)
+
+// TMDBID returns the movie's TMDB ID from the Guid array, falling back to the legacy GUID. Returns 0 if unknown.
+func (m *PlexMovie) TMDBID() int {
+	for _, guid := range m.Guids {
+		if id, ok := strings.CutPrefix(guid.ID, "tmdb://"); ok {
+			if tmdbID, err := strconv.Atoi(id); err == nil {
+				return tmdbID
+			}
+		}
+	}
+
+	if matches := legacyTMDBPattern.FindStringSubmatch(m.GUID); len(matches) == 2 {
+		if tmdbID, err := strconv.Atoi(matches[1]); err == nil {
+			return tmdbID
+		}
+	}
+	return 0
+}
+
+// IMDBID returns the movie's IMDb ID from the Guid array, falling back to the legacy GUID. Returns "" if unknown.
+func (m *PlexMovie) IMDBID() string {
+	for _, guid := range m.Guids {
+		if id, ok := strings.CutPrefix(guid.ID, "imdb://"); ok && id != "" {
+			return id
+		}
+	}
+
+	if matches := legacyIMDBPattern.FindStringSubmatch(m.GUID); len(matches) == 2 {
+		return matches[1]
+	}
+	return ""
+}
+
+// matches reports whether the movie has the given TMDB ID or, failing that, the given IMDb ID
+func (m *PlexMovie) matches(tmdbID int, imdbID string) bool {
+	if tmdbID > 0 && m.TMDBID() == tmdbID {
+		return true
+	}
+	return imdbID != "" && strings.EqualFold(m.IMDBID(), imdbID)
 }
 
 // MediaPart represents a media part in Plex
@@ -57,15 +116,22 @@ type PlexMediaResponse struct {
 	} `json:"MediaContainer"`
 }
 
-// NewPlexClient creates a new Plex client
+// NewPlexClient creates a new Plex client. cfg.Timeout, when set, takes precedence over timeout.
 func NewPlexClient(cfg *config.PlexConfig, timeout time.Duration, logger arr.Logger) *PlexClient {
+	if cfg.Timeout > 0 {
+		timeout = cfg.Timeout
+	}
+
+	baseURL, endpoint := arr.ResolveEndpoint(cfg.URL)
 	return &PlexClient{
-		baseURL: strings.TrimRight(cfg.URL, "/"),
+		baseURL: baseURL,
 		token:   cfg.Token,
 		httpClient: &http.Client{
-			Timeout: timeout,
+			Timeout:   timeout,
+			Transport: endpoint,
 		},
-		logger: logger,
+		logger:    logger,
+		libraries: cfg.Libraries,
 	}
 }
 
@@ -87,11 +153,14 @@ func (c *PlexClient) TestConnection(ctx context.Context) error {
 
 // GetMovieByTMDBID searches for a movie by TMDB ID in Plex
 func (c *PlexClient) GetMovieByTMDBID(ctx context.Context, tmdbID int) (*PlexMovie, error) {
-	// Search for the movie using TMDB GUID
-	tmdbGUID := fmt.Sprintf("tmdb://%d", tmdbID)
+	return c.GetMovieByIDs(ctx, tmdbID, "")
+}
 
-	// Get all movies from library sections
-	sections, err := c.getLibrarySections(ctx)
+// GetMovieByIDs searches for a movie by TMDB ID in Plex, falling back to the IMDb ID for items
+// whose metadata has no TMDB entry. imdbID may be empty.
+func (c *PlexClient) GetMovieByIDs(ctx context.Context, tmdbID int, imdbID string) (*PlexMovie, error) {
+	// Get all movies from the configured library sections
+	sections, err := c.getSelectedLibrarySections(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get library sections: %w", err)
 	}
@@ -99,7 +168,7 @@ func (c *PlexClient) GetMovieByTMDBID(ctx context.Context, tmdbID int) (*PlexMov
 	// Search in movie sections
 	for _, section := range sections {
 		if section.Type == "movie" {
-			movie, err := c.searchMovieInSection(ctx, section.Key, tmdbGUID, tmdbID)
+			movie, err := c.searchMovieInSection(ctx, section.Key, tmdbID, imdbID)
 			if err != nil {
 				c.logger.Debug("Error searching in section %s: %v", section.Title, err)
 				continue
@@ -110,6 +179,9 @@ func (c *PlexClient) GetMovieByTMDBID(ctx context.Context, tmdbID int) (*PlexMov
 		}
 	}
 
+	if imdbID != "" {
+		return nil, fmt.Errorf("movie with TMDB ID %d or IMDb ID %s not found in Plex", tmdbID, imdbID)
+	}
 	return nil, fmt.Errorf("movie with TMDB ID %d not found in Plex", tmdbID)
 }
 
@@ -147,10 +219,142 @@ func (c *PlexClient) getLibrarySections(ctx context.Context) ([]LibrarySection,
 	return sectionsResp.MediaContainer.Directory, nil
 }
 
-// searchMovieInSection searches for a movie in a specific library section
-func (c *PlexClient) searchMovieInSection(ctx context.Context, sectionKey, tmdbGUID string, tmdbID int) (*PlexMovie, error) {
-	// First try searching by GUID
-	path := fmt.Sprintf("/library/sections/%s/all", sectionKey)
+// getSelectedLibrarySections returns the library sections allowed by the configured library filter
+func (c *PlexClient) getSelectedLibrarySections(ctx context.Context) ([]LibrarySection, error) {
+	sections, err := c.getLibrarySections(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.filterSections(sections), nil
+}
+
+// filterSections keeps the sections whose title or key matches a configured library
+func (c *PlexClient) filterSections(sections []LibrarySection) []LibrarySection {
+	if len(c.libraries) == 0 {
+		return sections
+	}
+
+	matched := make(map[string]bool)
+	var selected []LibrarySection
+	for _, section := range sections {
+		include := false
+		for _, library := range c.libraries {
+			if strings.EqualFold(section.Title, library) || section.Key == library {
+				matched[library] = true
+				include = true
+			}
+		}
+
+		if include {
+			selected = append(selected, section)
+		} else {
+			c.logger.Debug("Skipping Plex library section %s (%s): not in PLEX_LIBRARIES", section.Title, section.Key)
+		}
+	}
+
+	for _, library := range c.libraries {
+		if !matched[library] {
+			c.logger.Warn("⚠️  Plex library %q from PLEX_LIBRARIES was not found", library)
+		}
+	}
+
+	return selected
+}
+
+// searchMovieInSection searches for a movie in a specific library section. It asks Plex to filter
+// by GUID first and only falls back to the (cached) full section listing when that finds nothing,
+// since items matched by legacy agents are not found by the GUID filter.
+func (c *PlexClient) searchMovieInSection(ctx context.Context, sectionKey string, tmdbID int, imdbID string) (*PlexMovie, error) {
+	guids := []string{fmt.Sprintf("tmdb://%d", tmdbID)}
+	if imdbID != "" {
+		guids = append(guids, "imdb://"+imdbID)
+	}
+
+	var match *PlexMovie
+	for _, guid := range guids {
+		movies, err := c.listSection(ctx, sectionKey, url.Values{"guid": {guid}})
+		if err != nil {
+			c.logger.Debug("GUID query for %s in section %s failed, falling back to full listing: %v", guid, sectionKey, err)
+			break
+		}
+		if match = findMovie(movies, tmdbID, imdbID); match != nil {
+			break
+		}
+	}
+
+	if match == nil {
+		movies, err := c.sectionMovies(ctx, sectionKey)
+		if err != nil {
+			return nil, err
+		}
+		match = findMovie(movies, tmdbID, imdbID)
+	}
+
+	if match == nil {
+		return nil, nil // Not found in this section
+	}
+
+	// Get media details to check availability
+	movie := *match
+	available, err := c.checkMovieAvailability(ctx, movie.Key)
+	if err != nil {
+		c.logger.Warn("Failed to check availability for movie %s: %v", movie.Title, err)
+		available = false // Assume not available if we can't check
+	}
+	movie.Available = available
+	return &movie, nil
+}
+
+// findMovie returns the movie matching the TMDB ID, or failing that the IMDb ID
+func findMovie(movies []PlexMovie, tmdbID int, imdbID string) *PlexMovie {
+	for i := range movies {
+		if movies[i].matches(tmdbID, "") {
+			return &movies[i]
+		}
+	}
+	if imdbID != "" {
+		for i := range movies {
+			if movies[i].matches(0, imdbID) {
+				return &movies[i]
+			}
+		}
+	}
+	return nil
+}
+
+// sectionMovies returns the full listing of a section, fetching it once per client
+func (c *PlexClient) sectionMovies(ctx context.Context, sectionKey string) ([]PlexMovie, error) {
+	c.sectionCacheMu.Lock()
+	defer c.sectionCacheMu.Unlock()
+
+	if movies, ok := c.sectionCache[sectionKey]; ok {
+		return movies, nil
+	}
+
+	c.logger.Debug("Fetching full listing of Plex library section %s", sectionKey)
+	movies, err := c.listSection(ctx, sectionKey, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.sectionCache == nil {
+		c.sectionCache = make(map[string][]PlexMovie)
+	}
+	c.sectionCache[sectionKey] = movies
+	return movies, nil
+}
+
+// listSection lists the items of a library section, optionally filtered by query parameters
+func (c *PlexClient) listSection(ctx context.Context, sectionKey string, filter url.Values) ([]PlexMovie, error) {
+	query := url.Values{}
+	for key, values := range filter {
+		query[key] = values
+	}
+	// includeGuids=1 makes Plex return the Guid array used by the modern agents
+	query.Set("includeGuids", "1")
+
+	path := fmt.Sprintf("/library/sections/%s/all?%s", sectionKey, query.Encode())
 	resp, err := c.makeRequest(ctx, "GET", path, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search section %s: %w", sectionKey, err)
@@ -166,21 +370,7 @@ func (c *PlexClient) searchMovieInSection(ctx context.Context, sectionKey, tmdbG
 		return nil, fmt.Errorf("failed to decode search response: %w", err)
 	}
 
-	// Look for movie with matching TMDB GUID
-	for _, movie := range plexResp.MediaContainer.Metadata {
-		if strings.Contains(movie.GUID, fmt.Sprintf("tmdb://%d", tmdbID)) {
-			// Get media details to check availability
-			available, err := c.checkMovieAvailability(ctx, movie.Key)
-			if err != nil {
-				c.logger.Warn("Failed to check availability for movie %s: %v", movie.Title, err)
-				available = false // Assume not available if we can't check
-			}
-			movie.Available = available
-			return &movie, nil
-		}
-	}
-
-	return nil, nil // Not found in this section
+	return plexResp.MediaContainer.Metadata, nil
 }
 
 // checkMovieAvailability checks if a movie's media files are available
@@ -47,7 +47,8 @@ func newTestPlexClient(cfg *config.PlexConfig, timeout time.Duration, logger Log
 		httpClient: &http.Client{
 			Timeout: timeout,
 		},
-		logger: &loggerAdapter{logger},
+		logger:    &loggerAdapter{logger},
+		libraries: cfg.Libraries,
 	}
 }
 
@@ -78,6 +79,226 @@ func TestNewPlexClient(t *testing.T) {
 	}
 }
 
+func TestNewPlexClient_TimeoutOverride(t *testing.T) {
+	logger := &loggerAdapter{&mockLogger{}}
+
+	client := NewPlexClient(&config.PlexConfig{URL: "http://localhost:32400/", Token: "t"}, 30*time.Second, logger)
+	if client.httpClient.Timeout != 30*time.Second {
+		t.Errorf("Expected default timeout 30s, got %s", client.httpClient.Timeout)
+	}
+	if client.baseURL != "http://localhost:32400" {
+		t.Errorf("Expected trailing slash to be trimmed, got '%s'", client.baseURL)
+	}
+
+	client = NewPlexClient(&config.PlexConfig{URL: "http://localhost:32400", Token: "t", Timeout: 5 * time.Second}, 30*time.Second, logger)
+	if client.httpClient.Timeout != 5*time.Second {
+		t.Errorf("Expected Plex timeout 5s to take precedence, got %s", client.httpClient.Timeout)
+	}
+}
+
+func TestPlexClient_LibraryFilter(t *testing.T) {
+	searched := make(map[string]bool)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/library/sections":
+			w.Write([]byte(`{"MediaContainer": {"Directory": [
+				{"key": "1", "title": "Movies", "type": "movie"},
+				{"key": "2", "title": "Home Videos", "type": "movie"},
+				{"key": "3", "title": "4K", "type": "movie"}
+			]}}`))
+		case "/library/sections/1/all", "/library/sections/2/all", "/library/sections/3/all":
+			searched[r.URL.Path] = true
+			w.Write([]byte(`{"MediaContainer": {"Metadata": []}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	cfg := &config.PlexConfig{
+		URL:       server.URL,
+		Token:     "test-token",
+		Libraries: []string{"movies", "3", "Missing Library"},
+	}
+	logger := &mockLogger{}
+	client := newTestPlexClient(cfg, 30*time.Second, logger)
+
+	if _, err := client.GetMovieByTMDBID(context.Background(), 12345); err == nil {
+		t.Error("Expected not found error")
+	}
+
+	if len(searched) != 2 || !searched["/library/sections/1/all"] || !searched["/library/sections/3/all"] {
+		t.Errorf("Expected only sections 1 and 3 to be searched, got %v", searched)
+	}
+
+	warned := false
+	for _, log := range logger.logs {
+		if log == "WARN" {
+			warned = true
+		}
+	}
+	if !warned {
+		t.Error("Expected a warning for the unmatched library")
+	}
+}
+
+func TestPlexMovie_ExternalIDs(t *testing.T) {
+	tests := []struct {
+		name         string
+		movie        PlexMovie
+		expectedTMDB int
+		expectedIMDB string
+	}{
+		{
+			name:         "modern Guid array",
+			movie:        PlexMovie{GUID: "plex://movie/5d776b59ad5437001f79c6f8", Guids: []PlexGuid{{ID: "imdb://tt0133093"}, {ID: "tmdb://603"}, {ID: "tvdb://169"}}},
+			expectedTMDB: 603,
+			expectedIMDB: "tt0133093",
+		},
+		{
+			name:         "legacy themoviedb agent",
+			movie:        PlexMovie{GUID: "com.plexapp.agents.themoviedb://603?lang=en"},
+			expectedTMDB: 603,
+		},
+		{
+			name:         "legacy imdb agent",
+			movie:        PlexMovie{GUID: "com.plexapp.agents.imdb://tt0133093?lang=en"},
+			expectedIMDB: "tt0133093",
+		},
+		{
+			name:  "no external IDs",
+			movie: PlexMovie{GUID: "plex://movie/5d776b59ad5437001f79c6f8"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.movie.TMDBID(); got != tt.expectedTMDB {
+				t.Errorf("TMDBID() = %d, expected %d", got, tt.expectedTMDB)
+			}
+			if got := tt.movie.IMDBID(); got != tt.expectedIMDB {
+				t.Errorf("IMDBID() = %q, expected %q", got, tt.expectedIMDB)
+			}
+		})
+	}
+}
+
+func TestPlexClient_GetMovieByIDs(t *testing.T) {
+	var includeGuids string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/library/sections":
+			w.Write([]byte(`{"MediaContainer": {"Directory": [{"key": "1", "title": "Movies", "type": "movie"}]}}`))
+		case "/library/sections/1/all":
+			includeGuids = r.URL.Query().Get("includeGuids")
+			w.Write([]byte(`{"MediaContainer": {"Metadata": [
+				{"key": "/library/metadata/1", "title": "Modern", "guid": "plex://movie/abc", "Guid": [{"id": "tmdb://603"}, {"id": "imdb://tt0133093"}]},
+				{"key": "/library/metadata/2", "title": "IMDb Only", "guid": "com.plexapp.agents.imdb://tt0000001?lang=en"}
+			]}}`))
+		case "/library/metadata/1", "/library/metadata/2":
+			w.Write([]byte(`{"MediaContainer": {"Metadata": [{"Media": [{"Part": [{"key": "1", "file": "/movies/a.mkv"}]}]}]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := newTestPlexClient(&config.PlexConfig{URL: server.URL, Token: "test-token"}, 30*time.Second, &mockLogger{})
+
+	movie, err := client.GetMovieByIDs(context.Background(), 603, "")
+	if err != nil {
+		t.Fatalf("Expected match from Guid array, got error: %v", err)
+	}
+	if movie.Title != "Modern" || !movie.Available {
+		t.Errorf("Unexpected movie: %+v", movie)
+	}
+	if includeGuids != "1" {
+		t.Errorf("Expected includeGuids=1 on section listing, got %q", includeGuids)
+	}
+
+	movie, err = client.GetMovieByIDs(context.Background(), 999, "tt0000001")
+	if err != nil {
+		t.Fatalf("Expected IMDb fallback match, got error: %v", err)
+	}
+	if movie.Title != "IMDb Only" {
+		t.Errorf("Expected 'IMDb Only', got '%s'", movie.Title)
+	}
+
+	if _, err := client.GetMovieByIDs(context.Background(), 999, "tt9999999"); err == nil {
+		t.Error("Expected not found error")
+	}
+}
+
+func TestPlexClient_GUIDQuery(t *testing.T) {
+	var guidQueries []string
+	fullListings := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/library/sections":
+			w.Write([]byte(`{"MediaContainer": {"Directory": [{"key": "1", "title": "Movies", "type": "movie"}]}}`))
+		case "/library/sections/1/all":
+			guid := r.URL.Query().Get("guid")
+			if guid == "" {
+				fullListings++
+				w.Write([]byte(`{"MediaContainer": {"Metadata": [
+					{"key": "/library/metadata/2", "title": "Legacy", "guid": "com.plexapp.agents.themoviedb://550?lang=en"}
+				]}}`))
+				return
+			}
+			guidQueries = append(guidQueries, guid)
+			if guid == "tmdb://603" {
+				w.Write([]byte(`{"MediaContainer": {"Metadata": [
+					{"key": "/library/metadata/1", "title": "Modern", "guid": "plex://movie/abc", "Guid": [{"id": "tmdb://603"}]}
+				]}}`))
+				return
+			}
+			w.Write([]byte(`{"MediaContainer": {"Metadata": []}}`))
+		case "/library/metadata/1", "/library/metadata/2":
+			w.Write([]byte(`{"MediaContainer": {"Metadata": [{"Media": [{"Part": [{"key": "1", "file": "/movies/a.mkv"}]}]}]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := newTestPlexClient(&config.PlexConfig{URL: server.URL, Token: "test-token"}, 30*time.Second, &mockLogger{})
+
+	movie, err := client.GetMovieByIDs(context.Background(), 603, "tt0133093")
+	if err != nil {
+		t.Fatalf("Expected match from GUID query, got error: %v", err)
+	}
+	if movie.Title != "Modern" {
+		t.Errorf("Expected 'Modern', got '%s'", movie.Title)
+	}
+	if fullListings != 0 {
+		t.Errorf("Expected no full listing when the GUID query matches, got %d", fullListings)
+	}
+
+	// Legacy agent items are only found through the full listing, which is fetched once
+	for i := 0; i < 2; i++ {
+		movie, err = client.GetMovieByIDs(context.Background(), 550, "")
+		if err != nil {
+			t.Fatalf("Expected legacy match from full listing, got error: %v", err)
+		}
+		if movie.Title != "Legacy" {
+			t.Errorf("Expected 'Legacy', got '%s'", movie.Title)
+		}
+	}
+	if fullListings != 1 {
+		t.Errorf("Expected full listing to be fetched once, got %d", fullListings)
+	}
+
+	want := []string{"tmdb://603", "tmdb://550", "tmdb://550"}
+	if len(guidQueries) != len(want) {
+		t.Fatalf("Expected GUID queries %v, got %v", want, guidQueries)
+	}
+	for i := range want {
+		if guidQueries[i] != want[i] {
+			t.Errorf("Expected GUID query %q, got %q", want[i], guidQueries[i])
+		}
+	}
+}
+
 func TestPlexClient_TestConnection(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -0,0 +1,155 @@
+package prowlarr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hnipps/refresharr/internal/arr"
+	"github.com/hnipps/refresharr/internal/config"
+)
+
+// Client implements a client for the Prowlarr API
+type Client struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+	logger     arr.Logger
+	now        func() time.Time
+}
+
+// Indexer represents an indexer configured in Prowlarr
+type Indexer struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Enable bool   `json:"enable"`
+}
+
+// IndexerStatus represents Prowlarr's failure tracking for an indexer
+type IndexerStatus struct {
+	IndexerID         int        `json:"indexerId"`
+	DisabledTill      *time.Time `json:"disabledTill,omitempty"`
+	MostRecentFailure *time.Time `json:"mostRecentFailure,omitempty"`
+}
+
+// Health summarizes the state of the enabled indexers
+type Health struct {
+	Enabled     int      // Indexers enabled in Prowlarr
+	Available   int      // Enabled indexers that are not currently disabled
+	Unavailable []string // Names of enabled indexers Prowlarr has disabled, with the time they return
+}
+
+// NewClient creates a new Prowlarr client
+func NewClient(cfg *config.ProwlarrConfig, timeout time.Duration, logger arr.Logger) *Client {
+	baseURL, endpoint := arr.ResolveEndpoint(cfg.URL)
+	return &Client{
+		baseURL: baseURL,
+		apiKey:  cfg.APIKey,
+		httpClient: &http.Client{
+			Timeout:   timeout,
+			Transport: endpoint,
+		},
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// TestConnection verifies the connection to Prowlarr
+func (c *Client) TestConnection(ctx context.Context) error {
+	if err := c.get(ctx, "/api/v1/system/status", nil); err != nil {
+		return fmt.Errorf("failed to connect to Prowlarr: %w", err)
+	}
+	return nil
+}
+
+// IndexerHealth fetches the indexers and their status and reports how many can be searched
+func (c *Client) IndexerHealth(ctx context.Context) (*Health, error) {
+	var indexers []Indexer
+	if err := c.get(ctx, "/api/v1/indexer", &indexers); err != nil {
+		return nil, fmt.Errorf("failed to get Prowlarr indexers: %w", err)
+	}
+
+	var statuses []IndexerStatus
+	if err := c.get(ctx, "/api/v1/indexerstatus", &statuses); err != nil {
+		return nil, fmt.Errorf("failed to get Prowlarr indexer status: %w", err)
+	}
+
+	disabledTill := make(map[int]time.Time)
+	now := c.now()
+	for _, status := range statuses {
+		if status.DisabledTill != nil && status.DisabledTill.After(now) {
+			disabledTill[status.IndexerID] = *status.DisabledTill
+		}
+	}
+
+	health := &Health{}
+	for _, indexer := range indexers {
+		if !indexer.Enable {
+			continue
+		}
+		health.Enabled++
+		if until, disabled := disabledTill[indexer.ID]; disabled {
+			health.Unavailable = append(health.Unavailable, fmt.Sprintf("%s (until %s)", indexer.Name, until.Local().Format(time.RFC3339)))
+			continue
+		}
+		health.Available++
+	}
+
+	return health, nil
+}
+
+// SearchAllowed implements arr.SearchGate: searches are allowed while at least one enabled
+// indexer is available
+func (c *Client) SearchAllowed(ctx context.Context) (bool, string, error) {
+	health, err := c.IndexerHealth(ctx)
+	if err != nil {
+		return false, "", err
+	}
+
+	switch {
+	case health.Enabled == 0:
+		return false, "no indexers are enabled in Prowlarr", nil
+	case health.Available == 0:
+		return false, fmt.Sprintf("all %d Prowlarr indexers are down or rate-limited: %s", health.Enabled, strings.Join(health.Unavailable, ", ")), nil
+	}
+
+	if len(health.Unavailable) > 0 {
+		c.logger.Info("📡 %d of %d Prowlarr indexers available (unavailable: %s)", health.Available, health.Enabled, strings.Join(health.Unavailable, ", "))
+	}
+	return true, "", nil
+}
+
+// get makes a GET request to the Prowlarr API and decodes the response into result (if not nil)
+func (c *Client) get(ctx context.Context, path string, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("X-Api-Key", c.apiKey)
+	req.Header.Set("Accept", "application/json")
+
+	c.logger.Debug("Making GET request to %s", c.baseURL+path)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("request to %s failed with status %d: %s", path, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode response from %s: %w", path, err)
+	}
+	return nil
+}
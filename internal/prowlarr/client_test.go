@@ -0,0 +1,136 @@
+package prowlarr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hnipps/refresharr/internal/config"
+)
+
+// mockLogger implements arr.Logger for testing
+type mockLogger struct{}
+
+func (m *mockLogger) Debug(format string, args ...interface{}) {}
+func (m *mockLogger) Info(format string, args ...interface{})  {}
+func (m *mockLogger) Warn(format string, args ...interface{})  {}
+func (m *mockLogger) Error(format string, args ...interface{}) {}
+
+// newTestClient serves the given indexer and indexer status JSON
+func newTestClient(t *testing.T, indexers, statuses string) (*Client, func()) {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "test-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v1/system/status":
+			w.Write([]byte(`{"version": "1.0.0"}`))
+		case "/api/v1/indexer":
+			w.Write([]byte(indexers))
+		case "/api/v1/indexerstatus":
+			w.Write([]byte(statuses))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	client := NewClient(&config.ProwlarrConfig{URL: server.URL, APIKey: "test-key"}, 30*time.Second, &mockLogger{})
+	client.now = func() time.Time { return time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC) }
+	return client, server.Close
+}
+
+func TestClient_TestConnection(t *testing.T) {
+	client, closeServer := newTestClient(t, `[]`, `[]`)
+	defer closeServer()
+
+	if err := client.TestConnection(context.Background()); err != nil {
+		t.Errorf("TestConnection returned error: %v", err)
+	}
+
+	client.apiKey = "wrong"
+	if err := client.TestConnection(context.Background()); err == nil {
+		t.Error("Expected error for invalid API key")
+	}
+}
+
+func TestClient_SearchAllowed(t *testing.T) {
+	indexers := `[
+		{"id": 1, "name": "Alpha", "enable": true},
+		{"id": 2, "name": "Beta", "enable": true},
+		{"id": 3, "name": "Disabled", "enable": false}
+	]`
+
+	tests := []struct {
+		name       string
+		indexers   string
+		statuses   string
+		allowed    bool
+		reasonPart string
+	}{
+		{
+			name:     "all available",
+			indexers: indexers,
+			statuses: `[]`,
+			allowed:  true,
+		},
+		{
+			name:     "one down",
+			indexers: indexers,
+			statuses: `[{"indexerId": 1, "disabledTill": "2024-01-01T13:00:00Z"}]`,
+			allowed:  true,
+		},
+		{
+			name:     "expired back-off counts as available",
+			indexers: indexers,
+			statuses: `[{"indexerId": 1, "disabledTill": "2024-01-01T11:00:00Z"}, {"indexerId": 2, "disabledTill": "2024-01-01T13:00:00Z"}]`,
+			allowed:  true,
+		},
+		{
+			name:       "all down",
+			indexers:   indexers,
+			statuses:   `[{"indexerId": 1, "disabledTill": "2024-01-01T13:00:00Z"}, {"indexerId": 2, "disabledTill": "2024-01-02T00:00:00Z"}]`,
+			allowed:    false,
+			reasonPart: "down or rate-limited",
+		},
+		{
+			name:       "none enabled",
+			indexers:   `[{"id": 3, "name": "Disabled", "enable": false}]`,
+			statuses:   `[]`,
+			allowed:    false,
+			reasonPart: "no indexers are enabled",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, closeServer := newTestClient(t, tt.indexers, tt.statuses)
+			defer closeServer()
+
+			allowed, reason, err := client.SearchAllowed(context.Background())
+			if err != nil {
+				t.Fatalf("SearchAllowed returned error: %v", err)
+			}
+			if allowed != tt.allowed {
+				t.Errorf("Expected allowed=%t, got %t (reason %q)", tt.allowed, allowed, reason)
+			}
+			if !strings.Contains(reason, tt.reasonPart) {
+				t.Errorf("Expected reason to contain %q, got %q", tt.reasonPart, reason)
+			}
+		})
+	}
+}
+
+func TestClient_SearchAllowedError(t *testing.T) {
+	client, closeServer := newTestClient(t, `not json`, `[]`)
+	defer closeServer()
+
+	if _, _, err := client.SearchAllowed(context.Background()); err == nil {
+		t.Error("Expected error for invalid indexer response")
+	}
+}
@@ -0,0 +1,170 @@
+// Package recording records the API traffic and file checks of a run to a debug bundle, and
+// replays a bundle so the run can be reproduced without access to the user's instances or disks.
+package recording
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hnipps/refresharr/internal/filesystem"
+)
+
+// redacted replaces secrets in recorded data
+const redacted = "REDACTED"
+
+// Bundle is everything a run saw: the responses the *arr instances sent and the files it checked
+type Bundle struct {
+	Version    string              `json:"version"` // RefreshArr version that recorded the bundle
+	RecordedAt time.Time           `json:"recordedAt"`
+	Args       []string            `json:"args,omitempty"` // Command line, with secret flag values redacted
+	Services   []Service           `json:"services"`
+	Exchanges  []Exchange          `json:"exchanges"`
+	Files      filesystem.Manifest `json:"files"` // State of every file the run checked
+}
+
+// Service is a service the run talked to
+type Service struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// Exchange is a recorded request and its response
+type Exchange struct {
+	Service         string        `json:"service"`
+	Method          string        `json:"method"`
+	URL             string        `json:"url"`
+	RequestHeaders  http.Header   `json:"requestHeaders,omitempty"`
+	RequestBody     string        `json:"requestBody,omitempty"`
+	Status          int           `json:"status,omitempty"`
+	ResponseHeaders http.Header   `json:"responseHeaders,omitempty"`
+	ResponseBody    string        `json:"responseBody,omitempty"`
+	Duration        time.Duration `json:"duration"`
+	Error           string        `json:"error,omitempty"` // Transport error, when no response was received
+}
+
+// LoadBundle reads a bundle written by a recorded run
+func LoadBundle(path string) (*Bundle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle: %w", err)
+	}
+
+	var bundle Bundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("failed to parse bundle %s: %w", path, err)
+	}
+	return &bundle, nil
+}
+
+// Save writes the bundle to path
+func (b *Bundle) Save(path string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal bundle: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return nil
+}
+
+// secretHeaders are request headers whose values are never recorded
+var secretHeaders = []string{"X-Api-Key", "Authorization", "Cookie", "X-Plex-Token"}
+
+// secretParams are query parameters whose values are never recorded
+var secretParams = []string{"apikey", "apiKey", "X-Plex-Token"}
+
+// redactor removes API keys and other secrets from recorded data
+type redactor struct {
+	secrets []string
+}
+
+// newRedactor creates a redactor that also replaces the given secret values wherever they appear
+func newRedactor(secrets []string) *redactor {
+	r := &redactor{}
+	for _, secret := range secrets {
+		if secret != "" {
+			r.secrets = append(r.secrets, secret)
+		}
+	}
+	return r
+}
+
+// text replaces every known secret in s
+func (r *redactor) text(s string) string {
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, redacted)
+	}
+	return s
+}
+
+// url redacts secret query parameters and known secrets in a URL
+func (r *redactor) url(u *url.URL) string {
+	copied := *u
+	copied.User = nil
+	query := copied.Query()
+	changed := false
+	for _, param := range secretParams {
+		if query.Has(param) {
+			query.Set(param, redacted)
+			changed = true
+		}
+	}
+	if changed {
+		copied.RawQuery = query.Encode()
+	}
+	return r.text(copied.String())
+}
+
+// headers copies h with secret headers redacted
+func (r *redactor) headers(h http.Header) http.Header {
+	if len(h) == 0 {
+		return nil
+	}
+	copied := make(http.Header, len(h))
+	for name, values := range h {
+		for _, value := range values {
+			copied.Add(name, r.text(value))
+		}
+	}
+	for _, name := range secretHeaders {
+		if copied.Get(name) != "" {
+			copied.Set(name, redacted)
+		}
+	}
+	return copied
+}
+
+// args redacts the values of secret flags (API keys, tokens and passwords) in a command line
+func (r *redactor) args(args []string) []string {
+	redactedArgs := make([]string, len(args))
+	redactNext := false
+	for i, arg := range args {
+		switch {
+		case redactNext:
+			redactedArgs[i] = redacted
+			redactNext = false
+		case strings.HasPrefix(arg, "-") && isSecretFlag(arg):
+			if name, _, hasValue := strings.Cut(arg, "="); hasValue {
+				redactedArgs[i] = name + "=" + redacted
+			} else {
+				redactedArgs[i] = arg
+				redactNext = true
+			}
+		default:
+			redactedArgs[i] = r.text(arg)
+		}
+	}
+	return redactedArgs
+}
+
+// isSecretFlag reports whether a flag carries a secret value
+func isSecretFlag(flag string) bool {
+	name, _, _ := strings.Cut(strings.ToLower(flag), "=")
+	return strings.Contains(name, "api-key") || strings.Contains(name, "token") || strings.Contains(name, "password")
+}
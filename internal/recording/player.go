@@ -0,0 +1,132 @@
+package recording
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/hnipps/refresharr/internal/arr"
+	"github.com/hnipps/refresharr/internal/filesystem"
+)
+
+// Player serves the responses recorded in a bundle instead of sending requests
+type Player struct {
+	bundle *Bundle
+
+	mu     sync.Mutex
+	queues map[string][]Exchange // Recorded exchanges by service and request, in recorded order
+	served map[string]int        // How many exchanges of each queue have been served
+}
+
+// NewPlayer creates a player for a bundle
+func NewPlayer(bundle *Bundle) *Player {
+	p := &Player{
+		bundle: bundle,
+		queues: make(map[string][]Exchange),
+		served: make(map[string]int),
+	}
+	for _, exchange := range bundle.Exchanges {
+		key, err := exchangeKey(exchange.Service, exchange.Method, exchange.URL)
+		if err != nil {
+			continue
+		}
+		p.queues[key] = append(p.queues[key], exchange)
+	}
+	return p
+}
+
+// Services returns the services the bundle recorded
+func (p *Player) Services() []Service {
+	return p.bundle.Services
+}
+
+// FileChecker returns a file checker answering from the recorded file checks. Files the
+// run never checked don't exist.
+func (p *Player) FileChecker() arr.FileChecker {
+	return filesystem.NewManifestChecker(p.bundle.Files)
+}
+
+// Transport returns a wrapper that answers a service's requests from the bundle. Nothing is
+// sent over the network.
+func (p *Player) Transport(service string) arr.TransportWrapper {
+	return func(http.RoundTripper) http.RoundTripper {
+		return &replayTransport{player: p, service: service}
+	}
+}
+
+// next returns the recorded exchange for a request. Repeated requests get the recorded
+// responses in order; once those run out, the last one is served again.
+func (p *Player) next(service string, req *http.Request) (Exchange, error) {
+	key, err := exchangeKey(service, req.Method, req.URL.String())
+	if err != nil {
+		return Exchange{}, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	queue := p.queues[key]
+	if len(queue) == 0 {
+		return Exchange{}, fmt.Errorf("no recorded %s response for %s %s", service, req.Method, req.URL.RequestURI())
+	}
+	i := p.served[key]
+	if i >= len(queue) {
+		i = len(queue) - 1
+	}
+	p.served[key]++
+	return queue[i], nil
+}
+
+// replayTransport serves recorded responses for one service
+type replayTransport struct {
+	player  *Player
+	service string
+}
+
+// RoundTrip answers the request with its recorded response
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	exchange, err := t.player.next(t.service, req)
+	if err != nil {
+		return nil, err
+	}
+	if exchange.Status == 0 {
+		return nil, errors.New(exchange.Error)
+	}
+
+	header := exchange.ResponseHeaders.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", exchange.Status, http.StatusText(exchange.Status)),
+		StatusCode:    exchange.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(exchange.ResponseBody)),
+		ContentLength: int64(len(exchange.ResponseBody)),
+		Request:       req,
+	}, nil
+}
+
+// exchangeKey identifies a request by service, method, path and query, ignoring the host and
+// the values of secret parameters, which were redacted when recording
+func exchangeKey(service, method, rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL %q: %w", rawURL, err)
+	}
+	query := u.Query()
+	for _, param := range secretParams {
+		query.Del(param)
+	}
+	return service + " " + method + " " + u.Path + "?" + query.Encode(), nil
+}
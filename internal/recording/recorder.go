@@ -0,0 +1,242 @@
+package recording
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hnipps/refresharr/internal/arr"
+	"github.com/hnipps/refresharr/internal/filesystem"
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+// Recorder collects the API traffic and file checks of a run into a bundle
+type Recorder struct {
+	redactor *redactor
+
+	mu       sync.Mutex
+	bundle   Bundle
+	files    map[string]*fileObservation
+	services map[string]bool
+}
+
+// fileObservation is what the run learned about a path
+type fileObservation struct {
+	exists     bool
+	unreadable bool
+	symlink    bool
+	size       int64
+	sha256     string
+}
+
+// NewRecorder creates a recorder. secrets (API keys, tokens) are redacted wherever they appear
+// in the recorded data, in addition to the API key headers and query parameters.
+func NewRecorder(version string, args []string, secrets []string) *Recorder {
+	r := &Recorder{
+		redactor: newRedactor(secrets),
+		files:    make(map[string]*fileObservation),
+		services: make(map[string]bool),
+	}
+	r.bundle = Bundle{
+		Version:    version,
+		RecordedAt: time.Now().UTC(),
+		Args:       r.redactor.args(args),
+		Exchanges:  []Exchange{},
+	}
+	return r
+}
+
+// Transport returns a wrapper that records the requests a service's client sends
+func (r *Recorder) Transport(service, baseURL string) arr.TransportWrapper {
+	r.mu.Lock()
+	if !r.services[service] {
+		r.services[service] = true
+		r.bundle.Services = append(r.bundle.Services, Service{Name: service, URL: r.redactor.text(baseURL)})
+	}
+	r.mu.Unlock()
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return &recordingTransport{recorder: r, service: service, next: next}
+	}
+}
+
+// FileChecker wraps a file checker so the results of its checks are recorded
+func (r *Recorder) FileChecker(next arr.FileChecker) arr.FileChecker {
+	return &recordingChecker{recorder: r, next: next}
+}
+
+// Save writes everything recorded so far to path
+func (r *Recorder) Save(path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	manifest := filesystem.Manifest{Files: []filesystem.ManifestFile{}}
+	for path, file := range r.files {
+		switch {
+		case file.exists:
+			manifest.Files = append(manifest.Files, filesystem.ManifestFile{
+				Path:       path,
+				Size:       file.size,
+				Unreadable: file.unreadable,
+				Symlink:    file.symlink,
+				SHA256:     file.sha256,
+			})
+		case file.symlink:
+			manifest.BrokenSymlinks = append(manifest.BrokenSymlinks, path)
+		}
+	}
+	sort.Slice(manifest.Files, func(i, j int) bool { return manifest.Files[i].Path < manifest.Files[j].Path })
+	sort.Strings(manifest.BrokenSymlinks)
+
+	bundle := r.bundle
+	bundle.Files = manifest
+	return bundle.Save(path)
+}
+
+// observe updates what is known about a path
+func (r *Recorder) observe(path string, update func(*fileObservation)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	file, exists := r.files[path]
+	if !exists {
+		file = &fileObservation{}
+		r.files[path] = file
+	}
+	update(file)
+}
+
+// recordingTransport records each request and response passing through it
+type recordingTransport struct {
+	recorder *Recorder
+	service  string
+	next     http.RoundTripper
+}
+
+// RoundTrip sends the request and records it along with the response
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	redactor := t.recorder.redactor
+	exchange := Exchange{
+		Service:        t.service,
+		Method:         req.Method,
+		URL:            redactor.url(req.URL),
+		RequestHeaders: redactor.headers(req.Header),
+	}
+
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		exchange.RequestBody = redactor.text(string(body))
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		exchange.Duration = time.Since(start)
+		exchange.Error = redactor.text(err.Error())
+		t.record(exchange)
+		return nil, err
+	}
+
+	body, readErr := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	exchange.Duration = time.Since(start)
+	exchange.Status = resp.StatusCode
+	exchange.ResponseHeaders = redactor.headers(resp.Header)
+	exchange.ResponseBody = redactor.text(string(body))
+	if readErr != nil {
+		exchange.Error = redactor.text(readErr.Error())
+	}
+	t.record(exchange)
+	return resp, readErr
+}
+
+func (t *recordingTransport) record(exchange Exchange) {
+	t.recorder.mu.Lock()
+	defer t.recorder.mu.Unlock()
+	t.recorder.bundle.Exchanges = append(t.recorder.bundle.Exchanges, exchange)
+}
+
+// recordingChecker records the results of the file checks it passes on
+type recordingChecker struct {
+	recorder *Recorder
+	next     arr.FileChecker
+}
+
+func (c *recordingChecker) FileExists(path string) bool {
+	exists := c.next.FileExists(path)
+	c.recorder.observe(path, func(f *fileObservation) { f.exists = exists })
+	return exists
+}
+
+func (c *recordingChecker) IsReadable(path string) bool {
+	readable := c.next.IsReadable(path)
+	c.recorder.observe(path, func(f *fileObservation) {
+		f.unreadable = !readable
+		f.exists = f.exists || readable
+	})
+	return readable
+}
+
+func (c *recordingChecker) FileSize(path string) (int64, error) {
+	size, err := c.next.FileSize(path)
+	if err == nil {
+		c.recorder.observe(path, func(f *fileObservation) {
+			f.size = size
+			f.exists = true
+		})
+	}
+	return size, err
+}
+
+func (c *recordingChecker) IsSymlink(path string) bool {
+	symlink := c.next.IsSymlink(path)
+	c.recorder.observe(path, func(f *fileObservation) { f.symlink = symlink })
+	return symlink
+}
+
+// MissingReason passes the question on to the wrapped checker, if it can answer it
+func (c *recordingChecker) MissingReason(path string) string {
+	if classifier, ok := c.next.(arr.MissingReasonClassifier); ok {
+		return classifier.MissingReason(path)
+	}
+	return models.ReasonNotFound
+}
+
+// FileSHA256 passes the question on to the wrapped checker, if it can answer it
+func (c *recordingChecker) FileSHA256(path string) (string, error) {
+	hasher, ok := c.next.(arr.FileHasher)
+	if !ok {
+		return "", fmt.Errorf("file checker can't compute checksums")
+	}
+	sum, err := hasher.FileSHA256(path)
+	if err == nil {
+		c.recorder.observe(path, func(f *fileObservation) {
+			f.sha256 = sum
+			f.exists = true
+		})
+	}
+	return sum, err
+}
+
+func (c *recordingChecker) FindBrokenSymlinks(rootDir string, extensions []string) ([]string, error) {
+	brokenSymlinks, err := c.next.FindBrokenSymlinks(rootDir, extensions)
+	for _, path := range brokenSymlinks {
+		c.recorder.observe(path, func(f *fileObservation) { f.symlink = true })
+	}
+	return brokenSymlinks, err
+}
+
+// DeleteSymlink deletes the symlink but keeps it in the recording, so a replay sees it as the run did
+func (c *recordingChecker) DeleteSymlink(path string) error {
+	return c.next.DeleteSymlink(path)
+}
@@ -0,0 +1,137 @@
+package recording
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hnipps/refresharr/internal/arr"
+	"github.com/hnipps/refresharr/internal/config"
+	"github.com/hnipps/refresharr/internal/filesystem"
+	"github.com/hnipps/refresharr/internal/mockarr"
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+type mockLogger struct{}
+
+func (m *mockLogger) Debug(msg string, args ...interface{}) {}
+func (m *mockLogger) Info(msg string, args ...interface{})  {}
+func (m *mockLogger) Warn(msg string, args ...interface{})  {}
+func (m *mockLogger) Error(msg string, args ...interface{}) {}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+const apiKey = "0123456789abcdef"
+
+func sonarrFixture() arr.SimulationFixture {
+	return arr.SimulationFixture{
+		Series: []models.Series{{MediaItem: models.MediaItem{ID: 1, Title: "Show"}, TVDBID: 100}},
+		Episodes: []models.Episode{
+			{ID: 11, SeriesID: 1, SeasonNumber: 1, EpisodeNumber: 1, HasFile: true, EpisodeFileID: intPtr(101)},
+			{ID: 12, SeriesID: 1, SeasonNumber: 1, EpisodeNumber: 2, HasFile: true, EpisodeFileID: intPtr(102)},
+		},
+		EpisodeFiles: []models.EpisodeFile{
+			{ID: 101, Path: "/tv/Show/S01E01.mkv"},
+			{ID: 102, Path: "/tv/Show/S01E02.mkv"},
+		},
+	}
+}
+
+// runCleanup runs a cleanup of series 1 and returns its stats without the timings
+func runCleanup(t *testing.T, client arr.Client, fileChecker arr.FileChecker) models.CleanupStats {
+	t.Helper()
+	service := arr.NewCleanupServiceWithOptions(client, fileChecker, &mockLogger{}, arr.NewConsoleProgressReporter(&mockLogger{}), arr.CleanupOptions{
+		ConcurrentLimit: 1,
+	})
+	result, err := service.CleanupMissingFilesForSeries(context.Background(), []int{1})
+	if err != nil {
+		t.Fatalf("CleanupMissingFilesForSeries() failed: %v", err)
+	}
+	stats := result.Stats
+	stats.Duration = 0
+	stats.Phases = models.PhaseTimings{}
+	return stats
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	server := mockarr.NewServer("sonarr", sonarrFixture(), apiKey)
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	recorder := NewRecorder("test", []string{"--sonarr-api-key", apiKey, "--dry-run"}, []string{apiKey})
+	client := arr.NewSonarrClientWithTransport(&config.SonarrConfig{URL: ts.URL, APIKey: apiKey}, 5*time.Second, recorder.Transport("sonarr", ts.URL), &mockLogger{})
+	fileChecker := recorder.FileChecker(filesystem.NewManifestChecker(filesystem.Manifest{
+		Files: []filesystem.ManifestFile{{Path: "/tv/Show/S01E01.mkv", Size: 1024}},
+	}))
+
+	recorded := runCleanup(t, client, fileChecker)
+	if recorded.MissingFiles != 1 || recorded.DeletedRecords != 1 {
+		t.Fatalf("Expected 1 missing file deleted while recording, got %+v", recorded)
+	}
+
+	path := filepath.Join(t.TempDir(), "bundle.json")
+	if err := recorder.Save(path); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read bundle: %v", err)
+	}
+	if strings.Contains(string(data), apiKey) {
+		t.Error("Expected the API key to be redacted from the bundle")
+	}
+
+	bundle, err := LoadBundle(path)
+	if err != nil {
+		t.Fatalf("LoadBundle() failed: %v", err)
+	}
+	if !reflect.DeepEqual(bundle.Args, []string{"--sonarr-api-key", redacted, "--dry-run"}) {
+		t.Errorf("Expected the API key flag value to be redacted, got %v", bundle.Args)
+	}
+	if len(bundle.Files.Files) != 1 || bundle.Files.Files[0].Path != "/tv/Show/S01E01.mkv" {
+		t.Errorf("Expected only the existing file in the manifest, got %+v", bundle.Files)
+	}
+
+	// The replay sees the same library and files as the recorded run, without a server
+	ts.Close()
+	player := NewPlayer(bundle)
+	replayClient := arr.NewSonarrClientWithTransport(&config.SonarrConfig{URL: bundle.Services[0].URL, APIKey: "replay"}, 5*time.Second, player.Transport("sonarr"), &mockLogger{})
+
+	replayed := runCleanup(t, replayClient, player.FileChecker())
+	if !reflect.DeepEqual(replayed, recorded) {
+		t.Errorf("Expected the replay to match the recorded run\nrecorded: %+v\nreplayed: %+v", recorded, replayed)
+	}
+}
+
+func TestPlayer_UnrecordedRequest(t *testing.T) {
+	player := NewPlayer(&Bundle{Services: []Service{{Name: "radarr", URL: "http://radarr:7878"}}})
+	client := arr.NewRadarrClientWithTransport(&config.RadarrConfig{URL: "http://radarr:7878", APIKey: "replay"}, time.Second, player.Transport("radarr"), &mockLogger{})
+
+	_, err := client.GetMovie(context.Background(), 5)
+	if err == nil || !strings.Contains(err.Error(), "no recorded radarr response for GET /api/v3/movie/5") {
+		t.Errorf("Expected an unrecorded request error, got %v", err)
+	}
+}
+
+func TestRedactor(t *testing.T) {
+	r := newRedactor([]string{"secret-token"})
+
+	args := r.args([]string{"--plex-token=secret-token", "--radarr-api-key", "abc", "--path", "/tv/secret-token"})
+	expected := []string{"--plex-token=" + redacted, "--radarr-api-key", redacted, "--path", "/tv/" + redacted}
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("Expected %v, got %v", expected, args)
+	}
+
+	headers := r.headers(map[string][]string{"X-Api-Key": {"abc"}, "Accept": {"application/json"}})
+	if headers.Get("X-Api-Key") != redacted || headers.Get("Accept") != "application/json" {
+		t.Errorf("Expected only the API key header to be redacted, got %v", headers)
+	}
+}
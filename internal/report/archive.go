@@ -0,0 +1,302 @@
+package report
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ArchiveOptions configures ArchiveReports
+type ArchiveOptions struct {
+	CompressAfter time.Duration // Age after which files are gzipped (0 disables)
+	Monthly       bool          // Bundle the files of each month that is over into one archive
+	Now           time.Time     // Time ages are measured from (zero means now)
+}
+
+// ArchiveResult counts what ArchiveReports did
+type ArchiveResult struct {
+	Compressed int      // Files gzipped
+	Archived   int      // Files moved into monthly archives
+	Archives   []string // Paths of the monthly archives written
+}
+
+// archivePrefix starts the names of monthly archives, e.g. archive-2026-09.tar.gz
+const archivePrefix = "archive-"
+
+// archivable reports whether a file in the reports directory is one ArchiveReports manages:
+// a report, actions file or snapshot, gzipped or not
+func archivable(name string) bool {
+	if strings.HasPrefix(name, archivePrefix) {
+		return false
+	}
+	switch filepath.Ext(strings.TrimSuffix(name, ".gz")) {
+	case ".json", ".csv", ".txt":
+		return true
+	}
+	return false
+}
+
+// ArchiveReports keeps a reports directory small: files older than CompressAfter are gzipped,
+// and with Monthly the files of each month that is over (and old enough to be compressed) are
+// moved into <dir>/archive-YYYY-MM.tar.gz. Months are those of the files' modification times.
+// Subdirectories, such as those of tenants, are left alone.
+func ArchiveReports(dir string, opts ArchiveOptions) (ArchiveResult, error) {
+	var result ArchiveResult
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	currentMonth := now.Format("2006-01")
+
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return result, nil
+	}
+	if err != nil {
+		return result, fmt.Errorf("failed to read reports directory: %w", err)
+	}
+
+	months := make(map[string][]string)
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || !archivable(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		modTime := info.ModTime().In(now.Location())
+		old := opts.CompressAfter > 0 && now.Sub(modTime) >= opts.CompressAfter
+
+		if opts.Monthly && (old || opts.CompressAfter == 0) {
+			if month := modTime.Format("2006-01"); month < currentMonth {
+				months[month] = append(months[month], path)
+				continue
+			}
+		}
+		if old && !strings.HasSuffix(path, ".gz") {
+			if err := gzipFile(path, info.ModTime()); err != nil {
+				return result, err
+			}
+			result.Compressed++
+		}
+	}
+
+	sorted := make([]string, 0, len(months))
+	for month := range months {
+		sorted = append(sorted, month)
+	}
+	sort.Strings(sorted)
+	for _, month := range sorted {
+		archive := filepath.Join(dir, archivePrefix+month+".tar.gz")
+		if err := appendToArchive(archive, months[month]); err != nil {
+			return result, err
+		}
+		result.Archived += len(months[month])
+		result.Archives = append(result.Archives, archive)
+	}
+	return result, nil
+}
+
+// gzipFile replaces the file with a gzipped copy named <path>.gz that keeps its modification time
+func gzipFile(path string, modTime time.Time) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to compress %s: %w", path, err)
+	}
+	defer src.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".compress-*")
+	if err != nil {
+		return fmt.Errorf("failed to compress %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	zw := gzip.NewWriter(tmp)
+	zw.Name = filepath.Base(path)
+	zw.ModTime = modTime
+	if _, err := io.Copy(zw, src); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to compress %s: %w", path, err)
+	}
+	if err := zw.Close(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to compress %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to compress %s: %w", path, err)
+	}
+	src.Close()
+
+	if err := os.Rename(tmp.Name(), path+".gz"); err != nil {
+		return fmt.Errorf("failed to compress %s: %w", path, err)
+	}
+	os.Chtimes(path+".gz", modTime, modTime)
+	return os.Remove(path)
+}
+
+// appendToArchive moves the files into the tar.gz archive, creating it or adding to what it
+// already holds. Gzipped files are stored decompressed under their original names, as the
+// archive is compressed as a whole.
+func appendToArchive(archive string, paths []string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(archive), ".archive-*")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", archive, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	zw := gzip.NewWriter(tmp)
+	tw := tar.NewWriter(zw)
+	if err := copyArchive(tw, archive); err != nil {
+		tmp.Close()
+		return err
+	}
+	for _, path := range paths {
+		if err := addToArchive(tw, path); err != nil {
+			tmp.Close()
+			return fmt.Errorf("failed to archive %s: %w", path, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", archive, err)
+	}
+	if err := zw.Close(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", archive, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", archive, err)
+	}
+	if err := os.Rename(tmp.Name(), archive); err != nil {
+		return fmt.Errorf("failed to write %s: %w", archive, err)
+	}
+
+	// The files are only removed once the archive holding them is in place
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("failed to remove archived %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// copyArchive copies the entries of an existing archive into tw; a missing archive has none
+func copyArchive(tw *tar.Writer, archive string) error {
+	f, err := os.Open(archive)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", archive, err)
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", archive, err)
+	}
+	tr := tar.NewReader(zr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", archive, err)
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to copy %s: %w", archive, err)
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			return fmt.Errorf("failed to copy %s: %w", archive, err)
+		}
+	}
+}
+
+// addToArchive writes the file into tw, decompressing it first if it's gzipped
+func addToArchive(tw *tar.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	name := filepath.Base(path)
+	var content io.Reader = f
+	size := info.Size()
+	if strings.HasSuffix(name, ".gz") {
+		// The decompressed size must be known before the header is written
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		if size, err = io.Copy(io.Discard, zr); err != nil {
+			return err
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		if zr, err = gzip.NewReader(f); err != nil {
+			return err
+		}
+		name = strings.TrimSuffix(name, ".gz")
+		content = zr
+	}
+
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    size,
+		ModTime: info.ModTime(),
+		Format:  tar.FormatPAX,
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, content)
+	return err
+}
+
+// OpenReport opens a saved report, falling back to its gzipped copy once ArchiveReports has
+// compressed it. The returned reader yields the report as it was written.
+func OpenReport(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err == nil || !os.IsNotExist(err) {
+		return f, err
+	}
+	gz, gzErr := os.Open(path + ".gz")
+	if gzErr != nil {
+		// The error is about the original path, which is what callers asked for
+		return nil, err
+	}
+	zr, gzErr := gzip.NewReader(gz)
+	if gzErr != nil {
+		gz.Close()
+		return nil, fmt.Errorf("failed to decompress %s.gz: %w", path, gzErr)
+	}
+	return &gzipFileReader{Reader: zr, file: gz}, nil
+}
+
+// gzipFileReader reads a gzipped file, closing the file with the reader
+type gzipFileReader struct {
+	*gzip.Reader
+	file *os.File
+}
+
+// Close closes the gzip reader and the file
+func (r *gzipFileReader) Close() error {
+	r.Reader.Close()
+	return r.file.Close()
+}
@@ -0,0 +1,153 @@
+package report
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// writeAged writes a file with the given content and modification time
+func writeAged(t *testing.T, path, content string, modTime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// readArchive returns the contents of a tar.gz archive by entry name
+func readArchive(t *testing.T, path string) map[string]string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := make(map[string]string)
+	tr := tar.NewReader(zr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return entries
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(tr)
+		entries[header.Name] = string(data)
+	}
+}
+
+// listDir returns the names in dir, sorted
+func listDir(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestArchiveReports_Compress(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	old := now.AddDate(0, 0, -10)
+	writeAged(t, filepath.Join(dir, "sonarr-missing-files-report-old.json"), `{"old":true}`, old)
+	writeAged(t, filepath.Join(dir, "sonarr-missing-files-report-new.json"), `{}`, now.AddDate(0, 0, -2))
+	writeAged(t, filepath.Join(dir, "notes.md"), "not a report", old)
+	if err := os.Mkdir(filepath.Join(dir, "alice"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ArchiveReports(dir, ArchiveOptions{CompressAfter: 7 * 24 * time.Hour, Now: now})
+	if err != nil {
+		t.Fatalf("ArchiveReports() failed: %v", err)
+	}
+	if result.Compressed != 1 || result.Archived != 0 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	want := []string{"alice", "notes.md", "sonarr-missing-files-report-new.json", "sonarr-missing-files-report-old.json.gz"}
+	if got := listDir(t, dir); !reflect.DeepEqual(got, want) {
+		t.Errorf("Directory holds %v, want %v", got, want)
+	}
+
+	// The report is still readable at its original path
+	r, err := OpenReport(filepath.Join(dir, "sonarr-missing-files-report-old.json"))
+	if err != nil {
+		t.Fatalf("OpenReport() failed: %v", err)
+	}
+	defer r.Close()
+	if data, _ := io.ReadAll(r); string(data) != `{"old":true}` {
+		t.Errorf("OpenReport() read %q", data)
+	}
+	info, _ := os.Stat(filepath.Join(dir, "sonarr-missing-files-report-old.json.gz"))
+	if !info.ModTime().Equal(old) {
+		t.Errorf("Expected the compressed file to keep its modification time, got %v", info.ModTime())
+	}
+
+	if _, err := OpenReport(filepath.Join(dir, "missing.json")); !os.IsNotExist(err) {
+		t.Errorf("Expected a not-exist error for a missing report, got %v", err)
+	}
+
+	// A second pass has nothing left to do
+	result, err = ArchiveReports(dir, ArchiveOptions{CompressAfter: 7 * 24 * time.Hour, Now: now})
+	if err != nil || result.Compressed != 0 {
+		t.Errorf("Expected nothing to compress again, got %+v (%v)", result, err)
+	}
+}
+
+func TestArchiveReports_Monthly(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	september := time.Date(2026, 9, 20, 3, 0, 0, 0, time.UTC)
+	writeAged(t, filepath.Join(dir, "radarr-a.json"), "a", september)
+	writeAged(t, filepath.Join(dir, "radarr-b.csv"), "b", september.AddDate(0, 0, 1))
+	writeAged(t, filepath.Join(dir, "radarr-c.json"), "c", time.Date(2026, 8, 2, 3, 0, 0, 0, time.UTC))
+	writeAged(t, filepath.Join(dir, "radarr-d.json"), "d", time.Date(2026, 10, 1, 3, 0, 0, 0, time.UTC))
+	if err := gzipFile(filepath.Join(dir, "radarr-b.csv"), september.AddDate(0, 0, 1)); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ArchiveReports(dir, ArchiveOptions{CompressAfter: 7 * 24 * time.Hour, Monthly: true, Now: now})
+	if err != nil {
+		t.Fatalf("ArchiveReports() failed: %v", err)
+	}
+	if result.Archived != 3 || result.Compressed != 1 || len(result.Archives) != 2 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	want := []string{"archive-2026-08.tar.gz", "archive-2026-09.tar.gz", "radarr-d.json.gz"}
+	if got := listDir(t, dir); !reflect.DeepEqual(got, want) {
+		t.Errorf("Directory holds %v, want %v", got, want)
+	}
+	september2026 := readArchive(t, filepath.Join(dir, "archive-2026-09.tar.gz"))
+	if len(september2026) != 2 || september2026["radarr-a.json"] != "a" || september2026["radarr-b.csv"] != "b" {
+		t.Errorf("Unexpected September archive: %v", september2026)
+	}
+
+	// Files of a month found later are added to its archive
+	writeAged(t, filepath.Join(dir, "radarr-e.json"), "e", september.AddDate(0, 0, 5))
+	if _, err := ArchiveReports(dir, ArchiveOptions{Monthly: true, Now: now}); err != nil {
+		t.Fatalf("ArchiveReports() failed: %v", err)
+	}
+	september2026 = readArchive(t, filepath.Join(dir, "archive-2026-09.tar.gz"))
+	if len(september2026) != 3 || september2026["radarr-e.json"] != "e" {
+		t.Errorf("Expected the late file to join the September archive, got %v", september2026)
+	}
+}
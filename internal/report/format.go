@@ -0,0 +1,218 @@
+package report
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/hnipps/refresharr/internal/messages"
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+// Format is how a sink encodes the missing files report
+type Format string
+
+const (
+	FormatJSON Format = "json" // The full report, as saved to the reports directory
+	FormatCSV  Format = "csv"  // One row per missing or damaged file
+	FormatText Format = "text" // The summary printed to the terminal
+)
+
+// ParseFormat parses a format name, case-insensitively
+func ParseFormat(name string) (Format, error) {
+	switch format := Format(strings.ToLower(strings.TrimSpace(name))); format {
+	case FormatJSON, FormatCSV, FormatText:
+		return format, nil
+	case "txt":
+		return FormatText, nil
+	default:
+		return "", fmt.Errorf("unknown report format %q (expected json, csv or text)", name)
+	}
+}
+
+// Extension returns the file extension of reports in the format, without the dot
+func (f Format) Extension() string {
+	if f == FormatText {
+		return "txt"
+	}
+	return string(f)
+}
+
+// ContentType returns the MIME type of reports in the format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatText:
+		return "text/plain; charset=utf-8"
+	default:
+		return "application/json"
+	}
+}
+
+// Encode writes the report to w in the format. The text format is written in the catalog's
+// language (nil means English).
+func (f Format) Encode(w io.Writer, report *models.MissingFilesReport, catalog *messages.Catalog) error {
+	switch f {
+	case FormatCSV:
+		return writeReportCSV(w, report)
+	case FormatText:
+		text := &textLogger{w: w}
+		(&Generator{logger: text, messages: catalog}).printReportToTerminal(report)
+		if text.err != nil {
+			return fmt.Errorf("failed to write report: %w", text.err)
+		}
+		return nil
+	default:
+		return writeReportJSON(w, report)
+	}
+}
+
+// writeReportJSON writes the report as indented JSON. The entries of a report that were spilled
+// to disk are streamed in one at a time; the output matches what MarshalIndent produces for the
+// same report.
+func writeReportJSON(out io.Writer, report *models.MissingFilesReport) error {
+	if report.Spilled == nil {
+		jsonData, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal report to JSON: %w", err)
+		}
+		if _, err := out.Write(jsonData); err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+		return nil
+	}
+
+	// Marshal everything but the entries, then stream the entries into the empty list
+	header := *report
+	header.MissingFiles = []models.MissingFileEntry{}
+	jsonData, err := json.MarshalIndent(&header, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal report to JSON: %w", err)
+	}
+	placeholder := []byte(`"missingFiles": []`)
+	split := bytes.Index(jsonData, placeholder)
+	if split < 0 {
+		return fmt.Errorf("failed to marshal report to JSON: missing files list not found")
+	}
+
+	w := bufio.NewWriter(out)
+	w.Write(jsonData[:split])
+	w.WriteString(`"missingFiles": [`)
+
+	count := 0
+	err = report.EachMissingFile(func(entry models.MissingFileEntry) error {
+		entryData, err := json.MarshalIndent(entry, "    ", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal missing file entry: %w", err)
+		}
+		if count > 0 {
+			w.WriteString(",")
+		}
+		w.WriteString("\n    ")
+		_, err = w.Write(entryData)
+		count++
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	if count > 0 {
+		w.WriteString("\n  ")
+	}
+	w.WriteString("]")
+	w.Write(jsonData[split+len(placeholder):])
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+// reportCSVHeader names the columns writeReportCSV writes
+var reportCSVHeader = []string{
+	"service", "mediaType", "mediaName", "season", "episode", "episodeName", "filePath",
+	"issue", "reason", "fileId", "tmdbId", "tvdbId", "goodreadsId", "processedAt",
+	"quality", "releaseGroup", "customFormats", "size",
+}
+
+// writeReportCSV writes a row for each missing or damaged file of the report
+func writeReportCSV(w io.Writer, report *models.MissingFilesReport) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(reportCSVHeader); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
+	optional := func(value *int) string {
+		if value == nil {
+			return ""
+		}
+		return strconv.Itoa(*value)
+	}
+	id := func(value int) string {
+		if value == 0 {
+			return ""
+		}
+		return strconv.Itoa(value)
+	}
+	size := func(value int64) string {
+		if value == 0 {
+			return ""
+		}
+		return strconv.FormatInt(value, 10)
+	}
+	err := report.EachMissingFile(func(entry models.MissingFileEntry) error {
+		return writer.Write([]string{
+			report.Label(),
+			entry.MediaType,
+			entry.MediaName,
+			optional(entry.Season),
+			optional(entry.Episode),
+			entry.EpisodeName,
+			entry.FilePath,
+			entry.Issue,
+			entry.Reason,
+			strconv.Itoa(entry.FileID),
+			id(entry.TMDBID),
+			id(entry.TVDBID),
+			id(entry.GoodreadsID),
+			entry.ProcessedAt,
+			entry.Quality,
+			entry.ReleaseGroup,
+			strings.Join(entry.CustomFormats, "|"),
+			size(entry.Size),
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+// textLogger is the Logger the text format prints the terminal summary through, writing each
+// line to w. The first write error is kept in err.
+type textLogger struct {
+	w   io.Writer
+	err error
+}
+
+func (l *textLogger) line(msg string, args ...interface{}) {
+	if l.err == nil {
+		_, l.err = fmt.Fprintf(l.w, msg+"\n", args...)
+	}
+}
+
+func (l *textLogger) Debug(msg string, args ...interface{}) {}
+func (l *textLogger) Info(msg string, args ...interface{})  { l.line(msg, args...) }
+func (l *textLogger) Warn(msg string, args ...interface{})  { l.line(msg, args...) }
+func (l *textLogger) Error(msg string, args ...interface{}) { l.line(msg, args...) }
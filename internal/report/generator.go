@@ -1,18 +1,34 @@
 package report
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/hnipps/refresharr/internal/messages"
 	"github.com/hnipps/refresharr/pkg/models"
 )
 
 // Generator handles the generation and output of missing files reports
 type Generator struct {
-	logger Logger
+	logger   Logger
+	messages *messages.Catalog // Text of the terminal summary (nil means English)
+	dir      string            // Directory reports are saved to (empty means defaultReportsDir)
+	sinks    []Sink            // Where missing files reports are written (empty means a JSON file sink in dir)
+}
+
+// defaultReportsDir is where reports are saved unless the generator is given a directory
+const defaultReportsDir = "reports"
+
+// GeneratorOptions configures a report generator
+type GeneratorOptions struct {
+	Messages *messages.Catalog // Text of the terminal summary (nil means English)
+	Dir      string            // Directory reports are saved to (empty means reports)
+	Sinks    []Sink            // Where missing files reports are written (empty means JSON files in Dir)
 }
 
 // Logger defines the interface for logging operations
@@ -30,15 +46,49 @@ func NewGenerator(logger Logger) *Generator {
 	}
 }
 
+// NewGeneratorWithMessages creates a report generator that prints the terminal summary with
+// the catalog's text
+func NewGeneratorWithMessages(logger Logger, catalog *messages.Catalog) *Generator {
+	return &Generator{
+		logger:   logger,
+		messages: catalog,
+	}
+}
+
+// NewGeneratorWithOptions creates a report generator configured by opts
+func NewGeneratorWithOptions(logger Logger, opts GeneratorOptions) *Generator {
+	return &Generator{
+		logger:   logger,
+		messages: opts.Messages,
+		dir:      opts.Dir,
+		sinks:    opts.Sinks,
+	}
+}
+
+// reportsDir returns the directory reports are saved to
+func (g *Generator) reportsDir() string {
+	if g.dir == "" {
+		return defaultReportsDir
+	}
+	return g.dir
+}
+
 // GenerateReport creates a missing files report and optionally saves it to disk and prints it
 func (g *Generator) GenerateReport(report *models.MissingFilesReport, printToTerminal bool) error {
+	_, err := g.GenerateReportWithPath(report, printToTerminal)
+	return err
+}
+
+// GenerateReportWithPath behaves like GenerateReport and also returns the path of the saved report
+func (g *Generator) GenerateReportWithPath(report *models.MissingFilesReport, printToTerminal bool) (string, error) {
 	if report == nil {
-		return fmt.Errorf("report is nil")
+		return "", fmt.Errorf("report is nil")
 	}
 
 	// Always save report to disk
-	if err := g.saveReportToDisk(report); err != nil {
-		return fmt.Errorf("failed to save report to disk: %w", err)
+	path, err := g.saveReportToDisk(report)
+	if err != nil {
+		return "", fmt.Errorf("failed to save report to disk: %w", err)
 	}
 
 	// Print to terminal if requested
@@ -46,79 +96,263 @@ func (g *Generator) GenerateReport(report *models.MissingFilesReport, printToTer
 		g.printReportToTerminal(report)
 	}
 
-	return nil
+	return path, nil
 }
 
-// saveReportToDisk saves the report as JSON to the reports directory
-func (g *Generator) saveReportToDisk(report *models.MissingFilesReport) error {
-	// Create reports directory if it doesn't exist
-	reportsDir := "reports"
+// SaveReport saves the report to the reports directory and returns its path
+func (g *Generator) SaveReport(report *models.MissingFilesReport) (string, error) {
+	if report == nil {
+		return "", fmt.Errorf("report is nil")
+	}
+	path, err := g.saveReportToDisk(report)
+	if err != nil {
+		return "", fmt.Errorf("failed to save report to disk: %w", err)
+	}
+	return path, nil
+}
+
+// PrintReport prints the report's summary to the terminal
+func (g *Generator) PrintReport(report *models.MissingFilesReport) {
+	g.printReportToTerminal(report)
+}
+
+// saveReportToDisk saves the report as JSON to the reports directory and returns its path
+func (g *Generator) saveReportToDisk(report *models.MissingFilesReport) (string, error) {
+	file := &FileSink{Dir: g.reportsDir(), Format: FormatJSON}
+	path, err := file.Write(context.Background(), report)
+	if err != nil {
+		return "", err
+	}
+	g.logger.Info("📄 Report saved to: %s", path)
+	return path, nil
+}
+
+// Sinks returns the sinks reports are written to: the configured ones, or a JSON file sink
+// in the reports directory
+func (g *Generator) Sinks() []Sink {
+	if len(g.sinks) > 0 {
+		return g.sinks
+	}
+	return []Sink{&FileSink{Dir: g.reportsDir(), Format: FormatJSON}}
+}
+
+// WriteReport writes the report to one of the sinks and returns where it went
+func (g *Generator) WriteReport(ctx context.Context, sink Sink, report *models.MissingFilesReport) (string, error) {
+	if report == nil {
+		return "", fmt.Errorf("report is nil")
+	}
+	location, err := sink.Write(ctx, report)
+	if err != nil {
+		return "", fmt.Errorf("failed to write report to %s sink: %w", sink.Name(), err)
+	}
+	g.logger.Info("📄 Report saved to: %s", location)
+	return location, nil
+}
+
+// SaveActions writes a dry-run actions file to the reports directory and returns its path
+func (g *Generator) SaveActions(actions *models.ActionsFile) (string, error) {
+	if actions == nil {
+		return "", fmt.Errorf("actions file is nil")
+	}
+
+	reportsDir := g.reportsDir()
 	if err := os.MkdirAll(reportsDir, 0755); err != nil {
-		return fmt.Errorf("failed to create reports directory: %w", err)
+		return "", fmt.Errorf("failed to create reports directory: %w", err)
 	}
 
-	// Generate filename with timestamp
+	if actions.Actions == nil {
+		actions.Actions = []models.PlannedAction{}
+	}
+	actions.TotalActions = len(actions.Actions)
+
 	timestamp := time.Now().Format("20060102-150405")
-	filename := fmt.Sprintf("%s-missing-files-report-%s.json", report.ServiceType, timestamp)
-	if report.RunType == "dry-run" {
-		filename = fmt.Sprintf("%s-missing-files-report-dryrun-%s.json", report.ServiceType, timestamp)
+	filename := fmt.Sprintf("%s-%s-actions-dryrun-%s.json", models.ServiceLabel(actions.ServiceType, actions.Instance), actions.Command, timestamp)
+	path := filepath.Join(reportsDir, filename)
+
+	jsonData, err := json.MarshalIndent(actions, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal actions to JSON: %w", err)
+	}
+
+	if err := os.WriteFile(path, jsonData, 0644); err != nil {
+		return "", fmt.Errorf("failed to write actions file: %w", err)
 	}
 
-	filepath := filepath.Join(reportsDir, filename)
+	g.logger.Info("📝 Dry-run actions saved to: %s (%d actions)", path, actions.TotalActions)
+	return path, nil
+}
+
+// SaveMigrationReport writes a migrate-paths report to the reports directory and returns its path
+func (g *Generator) SaveMigrationReport(migration *models.PathMigrationReport) (string, error) {
+	if migration == nil {
+		return "", fmt.Errorf("migration report is nil")
+	}
+
+	reportsDir := g.reportsDir()
+	if err := os.MkdirAll(reportsDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create reports directory: %w", err)
+	}
+
+	timestamp := time.Now().Format("20060102-150405")
+	filename := fmt.Sprintf("%s-migrate-paths-report-%s.json", models.ServiceLabel(migration.ServiceType, migration.Instance), timestamp)
+	if migration.RunType == "dry-run" {
+		filename = fmt.Sprintf("%s-migrate-paths-report-dryrun-%s.json", models.ServiceLabel(migration.ServiceType, migration.Instance), timestamp)
+	}
+	path := filepath.Join(reportsDir, filename)
 
-	// Marshal report to JSON with pretty printing
-	jsonData, err := json.MarshalIndent(report, "", "  ")
+	jsonData, err := json.MarshalIndent(migration, "", "  ")
 	if err != nil {
-		return fmt.Errorf("failed to marshal report to JSON: %w", err)
+		return "", fmt.Errorf("failed to marshal migration report to JSON: %w", err)
 	}
 
-	// Write to file
-	if err := os.WriteFile(filepath, jsonData, 0644); err != nil {
-		return fmt.Errorf("failed to write report file: %w", err)
+	if err := os.WriteFile(path, jsonData, 0644); err != nil {
+		return "", fmt.Errorf("failed to write migration report file: %w", err)
 	}
 
-	g.logger.Info("📄 Report saved to: %s", filepath)
-	return nil
+	g.logger.Info("📄 Migration report saved to: %s", path)
+	return path, nil
 }
 
 // printReportToTerminal prints the report in human-readable format to the terminal
 func (g *Generator) printReportToTerminal(report *models.MissingFilesReport) {
 	g.logger.Info("")
-	g.logger.Info("📊 MISSING FILES REPORT")
+	g.say(messages.ReportTitle)
 	g.logger.Info("==========================================")
-	g.logger.Info("Generated: %s", report.GeneratedAt)
-	g.logger.Info("Service: %s", report.ServiceType)
-	g.logger.Info("Run Type: %s", report.RunType)
-	g.logger.Info("Total Missing Files: %d", report.TotalMissing)
+	g.say(messages.ReportGenerated, report.GeneratedAt)
+	g.say(messages.ReportService, report.Label())
+	g.say(messages.ReportRunType, report.RunType)
+	g.say(messages.ReportTotalMissing, report.TotalMissing)
+	if report.SearchSkipped != "" {
+		g.say(messages.ReportSearchSkipped, report.SearchSkipped)
+	}
+	if t := report.Timing; t != nil {
+		g.say(messages.ReportDuration,
+			t.DurationMs, t.FetchMs, t.SymlinkScanMs, t.VerificationMs, t.DeletionMs, t.RefreshMs)
+		g.say(messages.ReportAPICalls, t.APICalls)
+	}
+	if report.OverBudget {
+		g.say(messages.ReportOverBudget, report.APIBudget)
+	}
+	if report.OutsideWindow {
+		g.say(messages.ReportOutsideWindow, report.NextWindow)
+	}
 	g.logger.Info("")
 
+	if len(report.Recovered) > 0 {
+		g.say(messages.ReportRecovered, len(report.Recovered))
+		if report.MeanTimeToRecoveryMs > 0 {
+			mttr := time.Duration(report.MeanTimeToRecoveryMs) * time.Millisecond
+			g.say(messages.ReportMTTR, mttr.Round(time.Minute))
+		}
+		for _, entry := range report.Recovered {
+			name := entry.MediaName
+			if entry.Season != nil && entry.Episode != nil {
+				name = fmt.Sprintf("%s S%02dE%02d", name, *entry.Season, *entry.Episode)
+			}
+			g.say(messages.ReportRecoveredEntry, name, entry.MissingSince)
+			g.say(messages.ReportRecoveredNow, entry.FilePath)
+		}
+		g.logger.Info("")
+	}
+
+	if len(report.OutsideRootFolders) > 0 {
+		g.say(messages.ReportOutsideRoot, len(report.OutsideRootFolders))
+		for _, entry := range report.OutsideRootFolders {
+			name := entry.MediaName
+			if entry.Season != nil && entry.Episode != nil {
+				name = fmt.Sprintf("%s S%02dE%02d", name, *entry.Season, *entry.Episode)
+			}
+			g.say(messages.ReportOutsideEntry, name, entry.FilePath, entry.Outcome)
+			if entry.MappedPath != "" {
+				g.say(messages.ReportMappedTo, entry.MappedPath)
+			}
+		}
+		g.logger.Info("")
+	}
+
 	if report.TotalMissing == 0 {
-		g.logger.Info("🎉 No missing files found!")
+		g.say(messages.ReportNoneMissing)
 		return
 	}
 
-	g.logger.Info("Missing Files:")
+	g.say(messages.ReportMissingFiles)
 	g.logger.Info("==========================================")
 
-	for i, entry := range report.MissingFiles {
-		g.logger.Info("%d. %s", i+1, entry.MediaName)
+	i := 0
+	err := report.EachMissingFile(func(entry models.MissingFileEntry) error {
+		if i > 0 {
+			g.logger.Info("")
+		}
+		i++
+		g.logger.Info("%d. %s", i, entry.MediaName)
 
 		if entry.MediaType == "series" && entry.Season != nil && entry.Episode != nil {
 			episodeName := entry.EpisodeName
 			if episodeName == "" {
-				episodeName = "Unknown Episode"
+				episodeName = g.messages.Sprintf(messages.ReportUnknownEpisode)
 			}
-			g.logger.Info("   Episode: S%02dE%02d - %s", *entry.Season, *entry.Episode, episodeName)
+			g.say(messages.ReportEpisode, *entry.Season, *entry.Episode, episodeName)
 		}
 
-		g.logger.Info("   Missing File: %s", entry.FilePath)
-		g.logger.Info("   File ID: %d", entry.FileID)
-		g.logger.Info("   Processed: %s", entry.ProcessedAt)
+		switch entry.Issue {
+		case models.IssueSizeMismatch:
+			g.say(messages.ReportSizeMismatch, entry.FilePath)
+			g.say(messages.ReportSizes, entry.ExpectedSize, entry.ActualSize)
+		case models.IssueChecksumMismatch:
+			g.say(messages.ReportCorrupt, entry.FilePath)
+			g.say(messages.ReportChecksums, entry.ExpectedSHA256, entry.ActualSHA256)
+		default:
+			g.say(messages.ReportMissingFile, entry.FilePath)
+		}
+		for _, path := range entry.FilePaths {
+			if path != entry.FilePath {
+				g.say(messages.ReportAlsoMissing, path)
+			}
+		}
+		if entry.Reason != "" {
+			g.say(messages.ReportReason, entry.Reason)
+		}
+		if entry.Quality != "" {
+			g.say(messages.ReportQuality, entry.Quality)
+		}
+		if entry.ReleaseGroup != "" {
+			g.say(messages.ReportReleaseGroup, entry.ReleaseGroup)
+		}
+		if len(entry.CustomFormats) > 0 {
+			g.say(messages.ReportCustomFormats, strings.Join(entry.CustomFormats, ", "))
+		}
+		// Size mismatches already show the recorded size as the expected one
+		if entry.Size > 0 && entry.Issue != models.IssueSizeMismatch {
+			g.say(messages.ReportRecordedSize, entry.Size)
+		}
+		if entry.AddCheck == models.AddCheckOK {
+			g.say(messages.ReportAddCheckOK)
+		} else if entry.AddCheck != "" {
+			g.say(messages.ReportAddCheckFailed, entry.AddCheck)
+		}
+		if entry.MatchConfidence > 0 {
+			g.say(messages.ReportTitleMatch, entry.MatchConfidence*100)
+		}
+		g.say(messages.ReportFileID, entry.FileID)
+		g.say(messages.ReportProcessed, entry.ProcessedAt)
+		return nil
+	})
+	if err != nil {
+		g.logger.Warn("Failed to read missing files: %s", err.Error())
+	}
 
-		if i < len(report.MissingFiles)-1 {
-			g.logger.Info("")
+	if len(report.MostAffected) > 0 {
+		g.logger.Info("==========================================")
+		g.say(messages.ReportMostAffected)
+		for _, item := range report.MostAffected {
+			g.logger.Info("   %4d  %s", item.Missing, item.MediaName)
 		}
 	}
 
 	g.logger.Info("==========================================")
 }
+
+// say prints a line of the terminal summary in the generator's language
+func (g *Generator) say(id messages.ID, args ...interface{}) {
+	g.logger.Info("%s", g.messages.Sprintf(id, args...))
+}
@@ -135,6 +135,41 @@ func TestGenerateReport_EmptyReport(t *testing.T) {
 	}
 }
 
+func TestGenerateReport_Instance(t *testing.T) {
+	tempDir := t.TempDir()
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	os.Chdir(tempDir)
+
+	report := &models.MissingFilesReport{
+		GeneratedAt:  "2023-12-01T10:00:00Z",
+		RunType:      "real-run",
+		ServiceType:  "radarr",
+		Instance:     "4k",
+		MissingFiles: []models.MissingFileEntry{},
+	}
+	path, err := NewGenerator(&mockLogger{}).GenerateReportWithPath(report, false)
+	if err != nil {
+		t.Fatalf("GenerateReportWithPath() failed: %v", err)
+	}
+
+	// Extra instances get their own report files, named after the instance
+	if !strings.HasPrefix(filepath.Base(path), "radarr-4k-missing-files-report-") {
+		t.Errorf("Expected a radarr-4k report file, got %s", path)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read report file: %v", err)
+	}
+	var savedReport models.MissingFilesReport
+	if err := json.Unmarshal(content, &savedReport); err != nil {
+		t.Fatalf("Failed to unmarshal report: %v", err)
+	}
+	if savedReport.ServiceType != "radarr" || savedReport.Instance != "4k" {
+		t.Errorf("Expected the radarr 4k instance in the report, got %q %q", savedReport.ServiceType, savedReport.Instance)
+	}
+}
+
 func TestGenerateReport_WithMissingFiles(t *testing.T) {
 	// Create temporary directory for test
 	tempDir := t.TempDir()
@@ -254,6 +289,119 @@ func TestGenerateReport_WithMissingFiles(t *testing.T) {
 	}
 }
 
+func TestGenerateReport_MostAffected(t *testing.T) {
+	tempDir := t.TempDir()
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	os.Chdir(tempDir)
+
+	logger := &mockLogger{}
+	report := &models.MissingFilesReport{
+		GeneratedAt:  "2023-12-01T10:00:00Z",
+		RunType:      "real-run",
+		ServiceType:  "sonarr",
+		TotalMissing: 3,
+		MissingFiles: []models.MissingFileEntry{
+			{MediaType: "series", MediaName: "Vanished Show", FilePath: "/tv/a/1.mkv", FileID: 1},
+			{MediaType: "series", MediaName: "Vanished Show", FilePath: "/tv/a/2.mkv", FileID: 2},
+			{MediaType: "series", MediaName: "Other Show", FilePath: "/tv/b/1.mkv", FileID: 3},
+		},
+		MostAffected: []models.AffectedItem{
+			{MediaType: "series", MediaName: "Vanished Show", Missing: 2},
+			{MediaType: "series", MediaName: "Other Show", Missing: 1},
+		},
+	}
+
+	if err := NewGenerator(logger).GenerateReport(report, true); err != nil {
+		t.Fatalf("GenerateReport() failed: %v", err)
+	}
+
+	summary := -1
+	for i, log := range logger.logs {
+		if log == "INFO: Most Affected:" {
+			summary = i
+		}
+	}
+	if summary < 0 || summary+2 >= len(logger.logs) {
+		t.Fatalf("Expected a most affected summary, got %v", logger.logs)
+	}
+	if !strings.Contains(logger.logs[summary+1], "2  Vanished Show") || !strings.Contains(logger.logs[summary+2], "1  Other Show") {
+		t.Errorf("Expected the most affected series first, got %q and %q", logger.logs[summary+1], logger.logs[summary+2])
+	}
+}
+
+// sliceSource is a MissingFileSource backed by a slice
+type sliceSource struct {
+	entries []models.MissingFileEntry
+	closed  bool
+}
+
+func (s *sliceSource) Each(fn func(models.MissingFileEntry) error) error {
+	for _, entry := range s.entries {
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sliceSource) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestGenerateReport_SpilledEntries(t *testing.T) {
+	tempDir := t.TempDir()
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	os.Chdir(tempDir)
+
+	logger := &mockLogger{}
+	generator := NewGenerator(logger)
+
+	entries := []models.MissingFileEntry{
+		{MediaType: "movie", MediaName: "Movie One", FilePath: "/media/movies/one.mkv", FileID: 1, ProcessedAt: "2023-12-01T10:00:00Z"},
+		{MediaType: "movie", MediaName: "Movie Two", FilePath: "/media/movies/two.mkv", FileID: 2, ProcessedAt: "2023-12-01T10:00:01Z"},
+	}
+	source := &sliceSource{entries: entries}
+	report := &models.MissingFilesReport{
+		GeneratedAt:   "2023-12-01T10:00:00Z",
+		RunType:       "real-run",
+		ServiceType:   "radarr",
+		TotalMissing:  len(entries),
+		SearchSkipped: "indexers unhealthy",
+		Spilled:       source,
+	}
+
+	path, err := generator.GenerateReportWithPath(report, true)
+	if err != nil {
+		t.Fatalf("GenerateReportWithPath() failed: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read report file: %v", err)
+	}
+
+	// The streamed file must match what an in-memory report marshals to
+	inMemory := *report
+	inMemory.Spilled = nil
+	inMemory.MissingFiles = entries
+	expected, _ := json.MarshalIndent(&inMemory, "", "  ")
+	if string(content) != string(expected) {
+		t.Errorf("Streamed report differs from in-memory report:\n%s\nwant:\n%s", content, expected)
+	}
+
+	output := strings.Join(logger.logs, "\n")
+	if !strings.Contains(output, "2. Movie Two") {
+		t.Errorf("Expected spilled entries in terminal output, got:\n%s", output)
+	}
+
+	if err := report.Close(); err != nil || !source.closed {
+		t.Errorf("Expected Close() to release the spilled entries, err=%v", err)
+	}
+}
+
 func TestGenerateReport_NoTerminalOutput(t *testing.T) {
 	// Create temporary directory for test
 	tempDir := t.TempDir()
@@ -312,3 +460,196 @@ func TestGenerateReport_NoTerminalOutput(t *testing.T) {
 		t.Error("Expected file save message even with no terminal output")
 	}
 }
+
+func TestGenerator_Dir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "alice", "reports")
+	generator := NewGeneratorWithOptions(&mockLogger{}, GeneratorOptions{Dir: dir})
+
+	report := &models.MissingFilesReport{ServiceType: "radarr", RunType: "verify"}
+	path, err := generator.SaveReport(report)
+	if err != nil {
+		t.Fatalf("SaveReport() failed: %v", err)
+	}
+	if filepath.Dir(path) != dir {
+		t.Errorf("Report saved to %s, expected a file in %s", path, dir)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Report file not written: %v", err)
+	}
+}
+
+func TestSaveActions(t *testing.T) {
+	// Create temporary directory for test
+	tempDir := t.TempDir()
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	os.Chdir(tempDir)
+
+	logger := &mockLogger{}
+	generator := NewGenerator(logger)
+
+	actions := &models.ActionsFile{
+		GeneratedAt: "2023-12-01T10:00:00Z",
+		Command:     "cleanup",
+		ServiceType: "radarr",
+		Actions: []models.PlannedAction{
+			{Action: models.ActionDeleteMovieFile, MediaType: "movie", MovieID: 5, FileID: 50, Path: "/movies/Foo (2020)/Foo.mkv"},
+		},
+	}
+
+	path, err := generator.SaveActions(actions)
+	if err != nil {
+		t.Fatalf("SaveActions() failed: %v", err)
+	}
+
+	if matched, _ := filepath.Match("reports/radarr-cleanup-actions-dryrun-*.json", path); !matched {
+		t.Errorf("Unexpected actions file path: %s", path)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read actions file: %v", err)
+	}
+
+	var saved models.ActionsFile
+	if err := json.Unmarshal(content, &saved); err != nil {
+		t.Fatalf("Failed to unmarshal actions file: %v", err)
+	}
+
+	if saved.TotalActions != 1 || len(saved.Actions) != 1 {
+		t.Fatalf("Expected 1 action, got total=%d len=%d", saved.TotalActions, len(saved.Actions))
+	}
+	if saved.Actions[0].FileID != 50 {
+		t.Errorf("Expected FileID 50, got %d", saved.Actions[0].FileID)
+	}
+}
+
+func TestSaveActions_Nil(t *testing.T) {
+	generator := NewGenerator(&mockLogger{})
+
+	if _, err := generator.SaveActions(nil); err == nil {
+		t.Error("Expected error for nil actions file")
+	}
+}
+
+func TestSaveMigrationReport(t *testing.T) {
+	tempDir := t.TempDir()
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	os.Chdir(tempDir)
+
+	generator := NewGenerator(&mockLogger{})
+
+	migration := &models.PathMigrationReport{
+		GeneratedAt: "2023-12-01T10:00:00Z",
+		RunType:     "dry-run",
+		ServiceType: "sonarr",
+		Mappings:    []models.PathMapping{{From: "/mnt/old/tv", To: "/data/tv"}},
+		Checked:     2,
+		Items: []models.PathMigrationEntry{
+			{MediaType: "series", MediaName: "Foo", ID: 1, FromPath: "/mnt/old/tv/Foo", ToPath: "/data/tv/Foo", Outcome: models.MigrationPlanned},
+		},
+	}
+
+	path, err := generator.SaveMigrationReport(migration)
+	if err != nil {
+		t.Fatalf("SaveMigrationReport() failed: %v", err)
+	}
+
+	if matched, _ := filepath.Match("reports/sonarr-migrate-paths-report-dryrun-*.json", path); !matched {
+		t.Errorf("Unexpected migration report path: %s", path)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read migration report: %v", err)
+	}
+
+	var saved models.PathMigrationReport
+	if err := json.Unmarshal(content, &saved); err != nil {
+		t.Fatalf("Failed to unmarshal migration report: %v", err)
+	}
+
+	if len(saved.Mappings) != 1 || saved.Mappings[0].To != "/data/tv" {
+		t.Errorf("Expected the mappings to be saved, got %+v", saved.Mappings)
+	}
+	if saved.Count(models.MigrationPlanned) != 1 {
+		t.Errorf("Expected 1 planned move, got %+v", saved.Items)
+	}
+}
+
+func TestSaveSnapshot(t *testing.T) {
+	tempDir := t.TempDir()
+	generator := NewGenerator(&mockLogger{})
+
+	snapshot := &models.LibrarySnapshot{
+		GeneratedAt: "2023-12-01T10:00:00Z",
+		Services:    []string{"sonarr", "radarr"},
+		TotalFiles:  2,
+		Files: []models.SnapshotFile{
+			{Service: "sonarr", MediaType: "episode", MediaID: 1, MediaName: "Foo, the Show", Season: 1, Episodes: []int{1, 2}, FileID: 100, Path: "/tv/Foo/S01E01-E02.mkv", Size: 2048, Quality: "HDTV-720p"},
+			{Service: "radarr", MediaType: "movie", MediaID: 5, MediaName: "Bar", FileID: 50, Path: "/movies/Bar/Bar.mkv", Size: 4096},
+		},
+	}
+
+	jsonPath, err := generator.SaveSnapshot(snapshot, filepath.Join(tempDir, "snapshot.json"))
+	if err != nil {
+		t.Fatalf("SaveSnapshot() failed: %v", err)
+	}
+	content, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatalf("Failed to read snapshot: %v", err)
+	}
+	var saved models.LibrarySnapshot
+	if err := json.Unmarshal(content, &saved); err != nil {
+		t.Fatalf("Failed to unmarshal snapshot: %v", err)
+	}
+	if saved.TotalFiles != 2 || len(saved.Files) != 2 || saved.Files[0].Episodes[1] != 2 {
+		t.Errorf("Unexpected snapshot %+v", saved)
+	}
+
+	csvPath, err := generator.SaveSnapshot(snapshot, filepath.Join(tempDir, "snapshot.csv"))
+	if err != nil {
+		t.Fatalf("SaveSnapshot() failed: %v", err)
+	}
+	content, err = os.ReadFile(csvPath)
+	if err != nil {
+		t.Fatalf("Failed to read snapshot: %v", err)
+	}
+	want := `service,mediaType,mediaId,mediaName,season,episodes,fileId,path,size,quality
+sonarr,episode,1,"Foo, the Show",1,1;2,100,/tv/Foo/S01E01-E02.mkv,2048,HDTV-720p
+radarr,movie,5,Bar,,,50,/movies/Bar/Bar.mkv,4096,
+`
+	if string(content) != want {
+		t.Errorf("Unexpected CSV snapshot:\n%s", content)
+	}
+}
+
+func TestLoadSnapshot(t *testing.T) {
+	tempDir := t.TempDir()
+	generator := NewGenerator(&mockLogger{})
+
+	snapshot := &models.LibrarySnapshot{
+		GeneratedAt: "2023-12-01T10:00:00Z",
+		Services:    []string{"radarr"},
+		TotalFiles:  1,
+		Files:       []models.SnapshotFile{{Service: "radarr", MediaType: "movie", MediaID: 5, FileID: 50, Path: "/movies/Bar/Bar.mkv"}},
+	}
+	path, err := generator.SaveSnapshot(snapshot, filepath.Join(tempDir, "snapshot.json"))
+	if err != nil {
+		t.Fatalf("SaveSnapshot() failed: %v", err)
+	}
+
+	loaded, err := LoadSnapshot(path)
+	if err != nil {
+		t.Fatalf("LoadSnapshot() failed: %v", err)
+	}
+	if loaded.GeneratedAt != snapshot.GeneratedAt || len(loaded.Files) != 1 || loaded.Files[0].Path != "/movies/Bar/Bar.mkv" {
+		t.Errorf("Unexpected snapshot %+v", loaded)
+	}
+
+	csvPath, _ := generator.SaveSnapshot(snapshot, filepath.Join(tempDir, "snapshot.csv"))
+	if _, err := LoadSnapshot(csvPath); err == nil {
+		t.Error("Expected error loading a CSV snapshot")
+	}
+}
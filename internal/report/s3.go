@@ -0,0 +1,174 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/hnipps/refresharr/internal/messages"
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+// s3Sink uploads each report to an S3 bucket, or any store speaking the S3 API such as MinIO.
+// Requests are signed with AWS Signature Version 4.
+type s3Sink struct {
+	endpoint     *url.URL // Store the bucket is addressed on, path-style
+	bucket       string
+	prefix       string // Key prefix, without leading or trailing slashes
+	region       string
+	accessKey    string
+	secretKey    string
+	sessionToken string
+	format       Format
+	messages     *messages.Catalog
+	httpClient   *http.Client
+	now          func() time.Time
+}
+
+// newS3Sink handles s3://bucket/prefix, with optional ?region= (default AWS_REGION, then
+// us-east-1) and ?endpoint= for stores other than AWS. Credentials come from the URL's
+// access_key:secret_key@ or from AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN.
+func newS3Sink(u *url.URL, format Format, opts SinkOptions) (Sink, error) {
+	if u.Host == "" {
+		return nil, fmt.Errorf("expected s3://bucket or s3://bucket/prefix")
+	}
+	if format == "" {
+		format = FormatJSON
+	}
+	query := u.Query()
+	sink := &s3Sink{
+		bucket:       u.Host,
+		prefix:       strings.Trim(u.Path, "/"),
+		region:       query.Get("region"),
+		accessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		sessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+		format:       format,
+		messages:     opts.Messages,
+		httpClient:   &http.Client{Timeout: opts.Timeout},
+		now:          time.Now,
+	}
+	if sink.region == "" {
+		sink.region = os.Getenv("AWS_REGION")
+	}
+	if sink.region == "" {
+		sink.region = "us-east-1"
+	}
+	if u.User != nil {
+		sink.accessKey = u.User.Username()
+		sink.secretKey, _ = u.User.Password()
+		sink.sessionToken = ""
+	}
+	if sink.accessKey == "" || sink.secretKey == "" {
+		return nil, fmt.Errorf("missing credentials: set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+
+	endpoint := query.Get("endpoint")
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", sink.region)
+	}
+	var err error
+	sink.endpoint, err = url.Parse(endpoint)
+	if err != nil || (sink.endpoint.Scheme != "http" && sink.endpoint.Scheme != "https") || sink.endpoint.Host == "" {
+		return nil, fmt.Errorf("endpoint must be an http:// or https:// URL")
+	}
+	return sink, nil
+}
+
+// Name returns "s3"
+func (s *s3Sink) Name() string {
+	return "s3"
+}
+
+// Write uploads the report and returns its s3:// location
+func (s *s3Sink) Write(ctx context.Context, report *models.MissingFilesReport) (string, error) {
+	body, err := encodeReport(report, s.format, s.messages)
+	if err != nil {
+		return "", err
+	}
+	key := path.Join(s.prefix, reportFilename(report, s.format))
+
+	target := *s.endpoint
+	target.Path = path.Join("/", target.Path, s.bucket, key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create S3 request: %w", err)
+	}
+	req.Header.Set("Content-Type", s.format.ContentType())
+	s.sign(req, body)
+
+	if err := sendRequest(s.httpClient, req, "S3"); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("s3://%s/%s", s.bucket, key), nil
+}
+
+// sign adds the AWS Signature Version 4 headers of the request to it
+func (s *s3Sink) sign(req *http.Request, body []byte) {
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if s.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.sessionToken)
+	}
+
+	// The signed headers are the host, the content type and the headers set above, in sorted order
+	signedHeaders := []string{"content-type", "host", "x-amz-content-sha256", "x-amz-date"}
+	if s.sessionToken != "" {
+		signedHeaders = append(signedHeaders, "x-amz-security-token")
+	}
+	var canonicalHeaders strings.Builder
+	for _, name := range signedHeaders {
+		value := req.Header.Get(name)
+		if name == "host" {
+			value = req.URL.Host
+		}
+		fmt.Fprintf(&canonicalHeaders, "%s:%s\n", name, strings.TrimSpace(value))
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		strings.Join(signedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+	scope := fmt.Sprintf("%s/%s/s3/aws4_request", date, s.region)
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), date)
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKey, scope, strings.Join(signedHeaders, ";"), signature))
+}
+
+// sha256Hex returns the hex-encoded SHA-256 of data
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data with key
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
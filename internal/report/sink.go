@@ -0,0 +1,322 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hnipps/refresharr/internal/messages"
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+// Sink is a destination missing files reports are written to, such as the reports directory,
+// an S3 bucket or an email inbox. Several sinks can be active at once, each in its own format.
+type Sink interface {
+	// Name identifies the kind of sink in logs and delivery failures, e.g. "file" or "s3"
+	Name() string
+	// Write delivers the report and returns where it went, e.g. the path of the saved file
+	Write(ctx context.Context, report *models.MissingFilesReport) (string, error)
+}
+
+// SinkOptions holds what sinks created from URLs share
+type SinkOptions struct {
+	Dir      string            // Directory of file:// sinks without a path (empty means reports)
+	Messages *messages.Catalog // Language of the text format (nil means English)
+	Timeout  time.Duration     // Timeout of webhook and S3 requests
+	Stdout   io.Writer         // Where stdout:// sinks write (nil means os.Stdout)
+}
+
+// SinkFactory creates the sink for a report sink URL. The format is the one the URL's format
+// parameter chose, or "" for the sink's default.
+type SinkFactory func(u *url.URL, format Format, opts SinkOptions) (Sink, error)
+
+// sinkSchemes maps the report sink URL schemes that are understood to the sinks they create
+var sinkSchemes = map[string]SinkFactory{
+	"file":   newFileSinkFromURL,
+	"stdout": newStdoutSink,
+	"http":   newWebhookSink,
+	"https":  newWebhookSink,
+	"s3":     newS3Sink,
+	"smtp":   newEmailSink,
+	"smtps":  newEmailSink,
+}
+
+// RegisterSinkScheme makes report sink URLs with the scheme create sinks with factory
+func RegisterSinkScheme(scheme string, factory SinkFactory) {
+	sinkSchemes[strings.ToLower(scheme)] = factory
+}
+
+// SinkSchemes returns the report sink URL schemes that are understood, sorted
+func SinkSchemes() []string {
+	names := make([]string, 0, len(sinkSchemes))
+	for name := range sinkSchemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseSink creates the sink for a report sink URL, such as file:///srv/reports?format=csv or
+// s3://bucket/prefix. Errors name only the scheme, as the rest of the URL may hold a password.
+func ParseSink(raw string, opts SinkOptions) (Sink, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Scheme == "" {
+		return nil, fmt.Errorf("not a URL of the form scheme://...")
+	}
+	factory, ok := sinkSchemes[strings.ToLower(u.Scheme)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported scheme %s:// (supported: %s)", u.Scheme, strings.Join(SinkSchemes(), ", "))
+	}
+
+	// The format parameter is the sink's own; the rest of the query is left to the factory
+	var format Format
+	query := u.Query()
+	if name := query.Get("format"); name != "" {
+		if format, err = ParseFormat(name); err != nil {
+			return nil, fmt.Errorf("invalid %s:// URL: %w", u.Scheme, err)
+		}
+		query.Del("format")
+		u.RawQuery = query.Encode()
+	}
+
+	sink, err := factory(u, format, opts)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s:// URL: %w", u.Scheme, err)
+	}
+	return sink, nil
+}
+
+// ParseSinks creates a sink for each report sink URL. Without URLs reports are saved as JSON
+// to the options' directory, as they always have been.
+func ParseSinks(urls []string, opts SinkOptions) ([]Sink, error) {
+	if len(urls) == 0 {
+		return []Sink{&FileSink{Dir: opts.Dir, Format: FormatJSON}}, nil
+	}
+	sinks := make([]Sink, 0, len(urls))
+	for i, raw := range urls {
+		sink, err := ParseSink(raw, opts)
+		if err != nil {
+			return nil, fmt.Errorf("invalid REPORT_SINKS entry %d: %w", i+1, err)
+		}
+		sinks = append(sinks, sink)
+	}
+	return sinks, nil
+}
+
+// PrimarySink returns the sink whose copy of each report is the run's report, as the history
+// API and later runs read it: the first sink saving JSON files. It returns nil when no sink does.
+func PrimarySink(sinks []Sink) Sink {
+	for _, sink := range sinks {
+		if file, ok := sink.(*FileSink); ok && file.Format == FormatJSON {
+			return sink
+		}
+	}
+	return nil
+}
+
+// reportFilename returns the name a report is saved under, e.g.
+// radarr-missing-files-report-dryrun-20240102-150405.json
+func reportFilename(report *models.MissingFilesReport, format Format) string {
+	timestamp := time.Now().Format("20060102-150405")
+	kind := ""
+	switch report.RunType {
+	case "dry-run":
+		kind = "dryrun-"
+	case "verify":
+		kind = "verify-"
+	}
+	return fmt.Sprintf("%s-missing-files-report-%s%s.%s", report.Label(), kind, timestamp, format.Extension())
+}
+
+// encodeReport returns the report encoded in the format
+func encodeReport(report *models.MissingFilesReport, format Format, catalog *messages.Catalog) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := format.Encode(&buf, report, catalog); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// FileSink saves each report to a file of its own in a directory
+type FileSink struct {
+	Dir      string            // Directory reports are saved to (empty means reports)
+	Format   Format            // Format of the files (empty means JSON)
+	Messages *messages.Catalog // Language of the text format (nil means English)
+}
+
+// newFileSinkFromURL handles file:///path/to/dir and file://relative/dir; file:// alone saves to
+// the reports directory
+func newFileSinkFromURL(u *url.URL, format Format, opts SinkOptions) (Sink, error) {
+	dir := u.Host + u.Path
+	if u.Opaque != "" {
+		dir = u.Opaque
+	}
+	if dir == "" {
+		dir = opts.Dir
+	}
+	if format == "" {
+		format = FormatJSON
+	}
+	return &FileSink{Dir: dir, Format: format, Messages: opts.Messages}, nil
+}
+
+// Name returns "file"
+func (s *FileSink) Name() string {
+	return "file"
+}
+
+// Write saves the report to the directory and returns the file's path
+func (s *FileSink) Write(ctx context.Context, report *models.MissingFilesReport) (string, error) {
+	dir := s.Dir
+	if dir == "" {
+		dir = defaultReportsDir
+	}
+	format := s.Format
+	if format == "" {
+		format = FormatJSON
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create reports directory: %w", err)
+	}
+
+	path := filepath.Join(dir, reportFilename(report, format))
+	file, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to write report file: %w", err)
+	}
+	if err := format.Encode(file, report, s.Messages); err != nil {
+		file.Close()
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("failed to write report file: %w", err)
+	}
+	return path, nil
+}
+
+// writerSink writes each report to a stream, such as standard output
+type writerSink struct {
+	name     string
+	w        io.Writer
+	format   Format
+	messages *messages.Catalog
+}
+
+// newStdoutSink handles stdout://, which prints each report to standard output (as text unless
+// another format is chosen)
+func newStdoutSink(u *url.URL, format Format, opts SinkOptions) (Sink, error) {
+	if u.Host != "" || strings.Trim(u.Path, "/") != "" {
+		return nil, fmt.Errorf("expected stdout:// without a host or path")
+	}
+	if format == "" {
+		format = FormatText
+	}
+	w := opts.Stdout
+	if w == nil {
+		w = os.Stdout
+	}
+	return &writerSink{name: "stdout", w: w, format: format, messages: opts.Messages}, nil
+}
+
+// Name returns the stream's name
+func (s *writerSink) Name() string {
+	return s.name
+}
+
+// Write writes the whole report to the stream at once, so reports of services saved side by side
+// don't interleave
+func (s *writerSink) Write(ctx context.Context, report *models.MissingFilesReport) (string, error) {
+	data, err := encodeReport(report, s.format, s.messages)
+	if err != nil {
+		return "", err
+	}
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		data = append(data, '\n')
+	}
+	if _, err := s.w.Write(data); err != nil {
+		return "", fmt.Errorf("failed to write report to %s: %w", s.name, err)
+	}
+	return s.name, nil
+}
+
+// webhookSink posts each report to a URL
+type webhookSink struct {
+	url        string
+	location   string // The URL without credentials or query, as logged
+	format     Format
+	messages   *messages.Catalog
+	httpClient *http.Client
+}
+
+// newWebhookSink handles http:// and https:// URLs, which receive each report in a POST request
+// (as JSON unless another format is chosen)
+func newWebhookSink(u *url.URL, format Format, opts SinkOptions) (Sink, error) {
+	if u.Host == "" {
+		return nil, fmt.Errorf("missing host")
+	}
+	if format == "" {
+		format = FormatJSON
+	}
+	location := url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path}
+	return &webhookSink{
+		url:        u.String(),
+		location:   location.String(),
+		format:     format,
+		messages:   opts.Messages,
+		httpClient: &http.Client{Timeout: opts.Timeout},
+	}, nil
+}
+
+// Name returns "webhook"
+func (s *webhookSink) Name() string {
+	return "webhook"
+}
+
+// Write posts the report and returns the URL it was posted to
+func (s *webhookSink) Write(ctx context.Context, report *models.MissingFilesReport) (string, error) {
+	body, err := encodeReport(report, s.format, s.messages)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create report webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", s.format.ContentType())
+	req.Header.Set("X-Refresharr-Service", report.Label())
+	req.Header.Set("X-Refresharr-Run-Type", report.RunType)
+
+	if err := sendRequest(s.httpClient, req, "report webhook"); err != nil {
+		return "", err
+	}
+	return s.location, nil
+}
+
+// sendRequest sends the request and fails unless the response has a 2xx status
+func sendRequest(client *http.Client, req *http.Request, name string) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		// The request URL may hold credentials, so it is left out of the error
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("failed to send report to %s: %w", name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s returned status %d: %s", name, resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
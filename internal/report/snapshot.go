@@ -0,0 +1,153 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+// snapshotCSVHeader is the header row of CSV snapshots
+var snapshotCSVHeader = []string{"service", "mediaType", "mediaId", "mediaName", "season", "episodes", "fileId", "path", "size", "quality"}
+
+// SaveSnapshot writes a library snapshot to path, as CSV when it ends in .csv and as JSON
+// otherwise. An empty path writes library-snapshot-<timestamp>.json to the reports directory.
+// It returns the path written.
+func (g *Generator) SaveSnapshot(snapshot *models.LibrarySnapshot, path string) (string, error) {
+	if snapshot == nil {
+		return "", fmt.Errorf("snapshot is nil")
+	}
+
+	if path == "" {
+		reportsDir := g.reportsDir()
+		if err := os.MkdirAll(reportsDir, 0755); err != nil {
+			return "", fmt.Errorf("failed to create reports directory: %w", err)
+		}
+		timestamp := time.Now().Format("20060102-150405")
+		path = filepath.Join(reportsDir, fmt.Sprintf("library-snapshot-%s.json", timestamp))
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create snapshot file: %w", err)
+	}
+	defer file.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		err = WriteSnapshotCSV(file, snapshot)
+	} else {
+		err = WriteSnapshotJSON(file, snapshot)
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("failed to write snapshot file: %w", err)
+	}
+
+	g.logger.Info("📄 Snapshot of %d file(s) saved to: %s", snapshot.TotalFiles, path)
+	return path, nil
+}
+
+// LoadSnapshot reads a library snapshot written as JSON by SaveSnapshot
+func LoadSnapshot(path string) (*models.LibrarySnapshot, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	var snapshot models.LibrarySnapshot
+	if err := json.Unmarshal(content, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot %s (only JSON snapshots can be loaded): %w", path, err)
+	}
+	return &snapshot, nil
+}
+
+// SaveSnapshotDiff writes the comparison of a snapshot with the current file records to the
+// reports directory and returns its path
+func (g *Generator) SaveSnapshotDiff(diff *models.SnapshotDiff) (string, error) {
+	if diff == nil {
+		return "", fmt.Errorf("snapshot diff is nil")
+	}
+
+	reportsDir := g.reportsDir()
+	if err := os.MkdirAll(reportsDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create reports directory: %w", err)
+	}
+
+	timestamp := time.Now().Format("20060102-150405")
+	path := filepath.Join(reportsDir, fmt.Sprintf("snapshot-diff-%s.json", timestamp))
+
+	jsonData, err := json.MarshalIndent(diff, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal snapshot diff to JSON: %w", err)
+	}
+
+	if err := os.WriteFile(path, jsonData, 0644); err != nil {
+		return "", fmt.Errorf("failed to write snapshot diff file: %w", err)
+	}
+
+	g.logger.Info("📄 Snapshot comparison saved to: %s", path)
+	return path, nil
+}
+
+// WriteSnapshotJSON writes a library snapshot to w as indented JSON
+func WriteSnapshotJSON(w io.Writer, snapshot *models.LibrarySnapshot) error {
+	jsonData, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot to JSON: %w", err)
+	}
+	if _, err := w.Write(append(jsonData, '\n')); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return nil
+}
+
+// WriteSnapshotCSV writes a library snapshot to w as CSV, one file record per row. The episode
+// numbers of multi-episode files are separated by semicolons.
+func WriteSnapshotCSV(w io.Writer, snapshot *models.LibrarySnapshot) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(snapshotCSVHeader); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	for _, file := range snapshot.Files {
+		episodes := make([]string, len(file.Episodes))
+		for i, episode := range file.Episodes {
+			episodes[i] = strconv.Itoa(episode)
+		}
+		season := ""
+		if file.MediaType == "episode" {
+			season = strconv.Itoa(file.Season)
+		}
+
+		row := []string{
+			file.Service,
+			file.MediaType,
+			strconv.Itoa(file.MediaID),
+			file.MediaName,
+			season,
+			strings.Join(episodes, ";"),
+			strconv.Itoa(file.FileID),
+			file.Path,
+			strconv.FormatInt(file.Size, 10),
+			file.Quality,
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("failed to write snapshot: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return nil
+}
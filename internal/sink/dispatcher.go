@@ -0,0 +1,164 @@
+// Package sink delivers reports and notifications in the background, so a slow disk or a dead
+// endpoint can't hold up processing
+package sink
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+// Default limits of a Dispatcher
+const (
+	DefaultWorkers   = 4
+	DefaultQueueSize = 100
+	DefaultTimeout   = 30 * time.Second
+)
+
+// Deliver writes one report or notification to a sink. It should give up when ctx is done;
+// deliveries that don't are abandoned once their timeout passes.
+type Deliver func(ctx context.Context) error
+
+// delivery is a queued write to a sink on behalf of a run
+type delivery struct {
+	sink    string
+	runID   string
+	deliver Deliver
+}
+
+// Dispatcher runs deliveries on a few workers fed by a bounded queue. Every delivery has a
+// timeout, and deliveries that fail, time out or don't fit in the queue are kept as failures
+// of their run instead of being lost silently.
+type Dispatcher struct {
+	timeout time.Duration
+	queue   chan delivery
+	workers sync.WaitGroup
+	pending sync.WaitGroup
+
+	mu       sync.Mutex
+	failures map[string][]models.SinkFailure // run ID -> failures
+	closed   bool
+}
+
+// NewDispatcher starts a dispatcher with workers workers, room for queueSize waiting deliveries
+// and a timeout for each delivery. Values below 1 select the defaults.
+func NewDispatcher(workers, queueSize int, timeout time.Duration) *Dispatcher {
+	if workers < 1 {
+		workers = DefaultWorkers
+	}
+	if queueSize < 1 {
+		queueSize = DefaultQueueSize
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	d := &Dispatcher{
+		timeout:  timeout,
+		queue:    make(chan delivery, queueSize),
+		failures: make(map[string][]models.SinkFailure),
+	}
+	for i := 0; i < workers; i++ {
+		d.workers.Add(1)
+		go func() {
+			defer d.workers.Done()
+			for del := range d.queue {
+				d.run(del)
+				d.pending.Done()
+			}
+		}()
+	}
+	return d
+}
+
+// Submit queues a delivery to sink for the run with the given ID. When the queue is full it
+// waits up to the delivery timeout for room, then records the delivery as failed rather than
+// holding up the caller any longer. It reports whether the delivery was queued: when it
+// wasn't, deliver is never called.
+func (d *Dispatcher) Submit(sink, runID string, deliver Deliver) bool {
+	d.mu.Lock()
+	closed := d.closed
+	if !closed {
+		d.pending.Add(1)
+	}
+	d.mu.Unlock()
+	if closed {
+		d.fail(sink, runID, fmt.Errorf("dispatcher closed"))
+		return false
+	}
+
+	del := delivery{sink: sink, runID: runID, deliver: deliver}
+	select {
+	case d.queue <- del:
+		return true
+	default:
+	}
+
+	timer := time.NewTimer(d.timeout)
+	defer timer.Stop()
+	select {
+	case d.queue <- del:
+		return true
+	case <-timer.C:
+		d.pending.Done()
+		d.fail(sink, runID, fmt.Errorf("queue full for %s", d.timeout))
+		return false
+	}
+}
+
+// run delivers del, giving up on it after the timeout
+func (d *Dispatcher) run(del delivery) {
+	ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- del.deliver(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			d.fail(del.sink, del.runID, err)
+		}
+	case <-ctx.Done():
+		d.fail(del.sink, del.runID, fmt.Errorf("timed out after %s", d.timeout))
+	}
+}
+
+// fail records a failed delivery
+func (d *Dispatcher) fail(sink, runID string, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.failures[runID] = append(d.failures[runID], models.SinkFailure{Sink: sink, Error: err.Error()})
+}
+
+// Wait blocks until every delivery submitted so far has finished, failed or timed out, and
+// returns the failures recorded since the last Wait by run ID
+func (d *Dispatcher) Wait() map[string][]models.SinkFailure {
+	d.pending.Wait()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	failures := d.failures
+	d.failures = make(map[string][]models.SinkFailure)
+	return failures
+}
+
+// Close waits for the queued deliveries and stops the workers. Later submissions fail.
+func (d *Dispatcher) Close() {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return
+	}
+	d.closed = true
+	d.mu.Unlock()
+
+	d.pending.Wait()
+	close(d.queue)
+	d.workers.Wait()
+}
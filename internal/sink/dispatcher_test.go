@@ -0,0 +1,136 @@
+package sink
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDispatcher_Wait(t *testing.T) {
+	d := NewDispatcher(2, 10, time.Second)
+	defer d.Close()
+
+	var delivered atomic.Int32
+	d.Submit("report", "run-1", func(ctx context.Context) error {
+		delivered.Add(1)
+		return nil
+	})
+	d.Submit("notify:webhook", "run-1", func(ctx context.Context) error {
+		return errors.New("webhook returned status 500")
+	})
+	d.Submit("report", "run-2", func(ctx context.Context) error {
+		delivered.Add(1)
+		return nil
+	})
+
+	failures := d.Wait()
+	if delivered.Load() != 2 {
+		t.Errorf("Expected 2 deliveries, got %d", delivered.Load())
+	}
+	if len(failures) != 1 || len(failures["run-1"]) != 1 {
+		t.Fatalf("Expected one failure for run-1, got %+v", failures)
+	}
+	if failure := failures["run-1"][0]; failure.Sink != "notify:webhook" || failure.Error != "webhook returned status 500" {
+		t.Errorf("Unexpected failure: %+v", failure)
+	}
+
+	// Failures are only returned once
+	if failures := d.Wait(); len(failures) != 0 {
+		t.Errorf("Expected no new failures, got %+v", failures)
+	}
+}
+
+func TestDispatcher_Timeout(t *testing.T) {
+	d := NewDispatcher(1, 10, 20*time.Millisecond)
+	defer d.Close()
+
+	// A sink that ignores its context is abandoned after the timeout
+	block := make(chan struct{})
+	defer close(block)
+	d.Submit("report", "run-1", func(ctx context.Context) error {
+		<-block
+		return nil
+	})
+
+	start := time.Now()
+	failures := d.Wait()
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected Wait to give up on the blocked sink, took %s", elapsed)
+	}
+	if len(failures["run-1"]) != 1 || !strings.Contains(failures["run-1"][0].Error, "timed out") {
+		t.Errorf("Expected a timeout failure, got %+v", failures)
+	}
+}
+
+func TestDispatcher_QueueFull(t *testing.T) {
+	d := NewDispatcher(1, 1, 50*time.Millisecond)
+	defer d.Close()
+
+	started := make(chan struct{})
+	slow := func(ctx context.Context) error {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	// The first delivery occupies the worker and the second the queue. Of the next two, at most
+	// one gets the queue's slot when the first times out; the other runs out of time waiting.
+	d.Submit("notify:webhook", "run-1", slow)
+	<-started
+	d.Submit("notify:webhook", "run-1", slow)
+	var wg sync.WaitGroup
+	var rejected atomic.Int32
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if !d.Submit("notify:webhook", "run-1", slow) {
+				rejected.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	failures := d.Wait()["run-1"]
+	if len(failures) != 4 {
+		t.Fatalf("Expected 4 failures, got %+v", failures)
+	}
+	queueFull := 0
+	for _, failure := range failures {
+		if strings.HasPrefix(failure.Error, "queue full") {
+			queueFull++
+		}
+	}
+	if queueFull == 0 || int(rejected.Load()) != queueFull {
+		t.Errorf("Expected a delivery to find the queue full and be reported as not queued, got %+v and %d rejected", failures, rejected.Load())
+	}
+}
+
+func TestDispatcher_Close(t *testing.T) {
+	d := NewDispatcher(0, 0, 0)
+
+	var delivered atomic.Int32
+	d.Submit("report", "run-1", func(ctx context.Context) error {
+		delivered.Add(1)
+		return nil
+	})
+	d.Close()
+	if delivered.Load() != 1 {
+		t.Error("Expected Close to wait for queued deliveries")
+	}
+
+	if d.Submit("report", "run-2", func(ctx context.Context) error { return nil }) {
+		t.Error("Expected a submission after Close not to be queued")
+	}
+	if failures := d.Wait(); len(failures["run-2"]) != 1 {
+		t.Errorf("Expected a submission after Close to fail, got %+v", failures)
+	}
+	d.Close()
+}
@@ -0,0 +1,34 @@
+// Package webui serves the dashboard of the serve command: the latest runs, their missing files,
+// and buttons that queue runs. It is a single page built on the server's JSON endpoints.
+package webui
+
+import (
+	"bytes"
+	_ "embed"
+	"html/template"
+	"net/http"
+)
+
+//go:embed index.html
+var indexHTML string
+
+var index = template.Must(template.New("index").Parse(indexHTML))
+
+// page holds what the dashboard is rendered with
+type page struct {
+	Version  string
+	Services []string // Services runs can be queued for
+}
+
+// NewHandler returns a handler serving the dashboard, with run buttons for services
+func NewHandler(version string, services []string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+		if err := index.Execute(&buf, page{Version: version, Services: services}); err != nil {
+			http.Error(w, "failed to render dashboard: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = buf.WriteTo(w)
+	})
+}
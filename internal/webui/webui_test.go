@@ -0,0 +1,31 @@
+package webui
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewHandler("v1.2.3", []string{"sonarr", `radarr"><script>`}).ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+	if rec.Code != 200 {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+		t.Errorf("Expected an HTML page, got %q", ct)
+	}
+
+	body, _ := io.ReadAll(rec.Body)
+	page := string(body)
+	for _, want := range []string{"v1.2.3", `data-service="sonarr" data-dry-run="true"`, `data-service="sonarr" data-dry-run="false"`} {
+		if !strings.Contains(page, want) {
+			t.Errorf("Expected the page to contain %q", want)
+		}
+	}
+	if strings.Contains(page, `radarr"><script>`) {
+		t.Error("Expected service names to be escaped")
+	}
+}
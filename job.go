@@ -0,0 +1,214 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hnipps/refresharr/internal/arr"
+	"github.com/hnipps/refresharr/internal/config"
+	"github.com/hnipps/refresharr/internal/history"
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+// errNothingProcessed fails a job whose targeting or configuration left every service out, so a
+// CronJob doesn't report success for a run that did nothing
+var errNothingProcessed = errors.New("no service was processed")
+
+// jobSummary is the one-line JSON printed on stdout at the end of a run in job mode
+type jobSummary struct {
+	Command    string              `json:"command"`
+	Status     string              `json:"status"` // success, warnings or failed
+	ExitCode   int                 `json:"exitCode"`
+	DryRun     bool                `json:"dryRun"`
+	StartedAt  time.Time           `json:"startedAt"`
+	FinishedAt time.Time           `json:"finishedAt"`
+	DurationMs int64               `json:"durationMs"`
+	Services   []jobServiceSummary `json:"services"`
+	Error      string              `json:"error,omitempty"`
+}
+
+// jobServiceSummary is a service's run in the job summary
+type jobServiceSummary struct {
+	Service        string `json:"service"`
+	RunID          string `json:"runId"`
+	DryRun         bool   `json:"dryRun"`
+	Success        bool   `json:"success"`
+	ItemsChecked   int    `json:"itemsChecked"`
+	MissingFiles   int    `json:"missingFiles"`
+	DeletedRecords int    `json:"deletedRecords"`
+	Recovered      int    `json:"recovered"`
+	Errors         int    `json:"errors"`
+	Warnings       int    `json:"warnings"`
+	APICalls       int    `json:"apiCalls"`
+	ReportPath     string `json:"reportPath,omitempty"`
+	CrashReport    string `json:"crashReport,omitempty"`
+	Error          string `json:"error,omitempty"`
+}
+
+// Exit codes, documented in the README so scripts and schedulers can tell failures apart
+const (
+	exitSuccess    = 0 // Every service's run succeeded
+	exitErrors     = 1 // Some operations failed or were left for a later run
+	exitConfig     = 2 // The configuration or command line is invalid
+	exitConnection = 3 // No service could be reached
+	exitCancelled  = 4 // The run was interrupted, e.g. by SIGINT or SIGTERM
+	exitCrashed    = 5 // The command panicked; a crash report was written
+)
+
+// exitError is an error that ends the command with a specific exit code
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+// withExitCode makes err end the command with code instead of exitErrors
+func withExitCode(code int, err error) error {
+	return &exitError{code: code, err: err}
+}
+
+// exitCode returns the process exit code for runCleanup's error: exitSuccess on success,
+// exitCrashed after a panic, the code err was given with withExitCode, and exitErrors for any
+// other failure, including a partial success. Interrupted runs are given exitCancelled by the
+// command that saw its signal context end; a request cancelled for another reason is a failure.
+func exitCode(err error) int {
+	var exitErr *exitError
+	switch {
+	case err == nil:
+		return exitSuccess
+	case errors.Is(err, errCrashed):
+		return exitCrashed
+	case errors.As(err, &exitErr):
+		return exitErr.code
+	default:
+		return exitErrors
+	}
+}
+
+// exitStatus returns the status of a command that ended with err: success, warnings or failed
+func exitStatus(err error) string {
+	switch {
+	case err == nil:
+		return "success"
+	case errors.Is(err, errCompletedWithWarnings):
+		return "warnings"
+	default:
+		return "failed"
+	}
+}
+
+// newJobSummary summarizes the runs of a command started at startedAt that ended with err
+func newJobSummary(command string, cfg *config.Config, startedAt time.Time, runs []*history.Run, err error) jobSummary {
+	finishedAt := time.Now().UTC()
+	summary := jobSummary{
+		Command:    command,
+		Status:     exitStatus(err),
+		ExitCode:   exitCode(err),
+		DryRun:     cfg.DryRun,
+		StartedAt:  startedAt,
+		FinishedAt: finishedAt,
+		DurationMs: finishedAt.Sub(startedAt).Milliseconds(),
+		Services:   make([]jobServiceSummary, 0, len(runs)),
+	}
+	if summary.Status == "failed" {
+		summary.Error = err.Error()
+	}
+
+	for _, run := range runs {
+		summary.Services = append(summary.Services, jobServiceSummary{
+			Service:        run.Service,
+			RunID:          run.ID,
+			DryRun:         run.DryRun,
+			Success:        run.Success,
+			ItemsChecked:   run.Stats.TotalItemsChecked,
+			MissingFiles:   run.Stats.MissingFiles,
+			DeletedRecords: run.Stats.DeletedRecords,
+			Recovered:      len(run.Recovered),
+			Errors:         run.Stats.Errors,
+			Warnings:       run.Stats.Warnings,
+			APICalls:       run.Stats.APICalls,
+			ReportPath:     run.ReportPath,
+			CrashReport:    run.CrashReport,
+			Error:          run.Error,
+		})
+	}
+	return summary
+}
+
+// finishJob prints the job summary as the last line on stdout, writes it to the summary file if
+// one is configured, and returns the exit code. Logs go to stderr, so stdout only holds the summary.
+func finishJob(command string, cfg *config.Config, startedAt time.Time, runs []*history.Run, err error, logger arr.Logger) int {
+	if err == nil && len(runs) == 0 {
+		err = errNothingProcessed
+	}
+	summary := newJobSummary(command, cfg, startedAt, runs, err)
+	if summary.Error != "" {
+		logger.Error("%s", summary.Error)
+	}
+
+	data, marshalErr := json.Marshal(summary)
+	if marshalErr != nil {
+		logger.Error("Failed to marshal job summary: %s", marshalErr.Error())
+		return exitErrors
+	}
+	fmt.Println(string(data))
+
+	code, status := summary.ExitCode, summary.Status
+	if cfg.SummaryFile != "" {
+		if writeErr := writeSummaryFile(cfg.SummaryFile, data); writeErr != nil {
+			// Downstream steps rely on the file, so a missing one fails the job
+			logger.Error("%s", writeErr.Error())
+			code, status = exitErrors, "failed"
+		}
+	}
+	printExitSummary(cfg, os.Stderr, status, runs, summary.FinishedAt.Sub(startedAt), logger)
+	return code
+}
+
+// exitSummaryLine sums up the runs in one line of key=value pairs that grep and awk can pick
+// apart, e.g. "result=success checked=1234 missing=12 deleted=12 errors=0 duration=312s". The
+// keys and their order are kept stable for log scrapers.
+func exitSummaryLine(result string, runs []*history.Run, duration time.Duration) string {
+	var stats models.CleanupStats
+	for _, run := range runs {
+		stats.TotalItemsChecked += run.Stats.TotalItemsChecked
+		stats.MissingFiles += run.Stats.MissingFiles
+		stats.DeletedRecords += run.Stats.DeletedRecords
+		stats.Errors += run.Stats.Errors
+	}
+	return fmt.Sprintf("result=%s checked=%d missing=%d deleted=%d errors=%d duration=%ds",
+		result, stats.TotalItemsChecked, stats.MissingFiles, stats.DeletedRecords, stats.Errors, int64(duration.Round(time.Second).Seconds()))
+}
+
+// printExitSummary writes the exit summary line as the last line on stderr (w), whatever the log
+// format or output mode. Logs sent to syslog or the journal get the line too, as stderr may go
+// nowhere there.
+func printExitSummary(cfg *config.Config, w io.Writer, result string, runs []*history.Run, duration time.Duration, logger arr.Logger) {
+	line := exitSummaryLine(result, runs, duration)
+	if cfg.LogTarget != "stderr" {
+		logger.Info("%s", line)
+	}
+	fmt.Fprintln(w, line)
+}
+
+// writeSummaryFile replaces the summary file atomically, so a downstream step never reads half of it
+func writeSummaryFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create summary directory: %w", err)
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write job summary: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to replace job summary file: %w", err)
+	}
+	return nil
+}
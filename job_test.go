@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hnipps/refresharr/internal/arr"
+	"github.com/hnipps/refresharr/internal/config"
+	"github.com/hnipps/refresharr/internal/history"
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "success", err: nil, want: exitSuccess},
+		{name: "partial success", err: errCompletedWithWarnings, want: exitErrors},
+		{name: "failure", err: errors.New("some cleanup operations completed with errors"), want: exitErrors},
+		{name: "config", err: withExitCode(exitConfig, errors.New("no services configured or available")), want: exitConfig},
+		{name: "connection", err: withExitCode(exitConnection, errors.New("could not connect to any service")), want: exitConnection},
+		{name: "cancelled", err: withExitCode(exitCancelled, fmt.Errorf("cleanup interrupted: %w", context.Canceled)), want: exitCancelled},
+		{name: "crashed", err: fmt.Errorf("radarr: %w", errCrashed), want: exitCrashed},
+		{name: "wrapped exit error", err: fmt.Errorf("job failed: %w", withExitCode(exitConnection, errors.New("refused"))), want: exitConnection},
+		// Only an interrupted run is cancelled; a request some timeout cancelled is a failure
+		{name: "internal cancel", err: fmt.Errorf("failed to get series: %w", context.Canceled), want: exitErrors},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.want {
+				t.Errorf("exitCode(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunCleanup_ExitsWithConnectionCodeWhenNoServiceConnects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	t.Setenv("REPORT_DIR", filepath.Join(dir, "reports"))
+	t.Setenv("STATE_DIR", filepath.Join(dir, "state"))
+	t.Setenv("SONARR_URL", server.URL)
+	t.Setenv("SONARR_API_KEY", "wrong")
+	t.Setenv("RADARR_URL", server.URL)
+	t.Setenv("RADARR_API_KEY", "wrong")
+
+	flags := config.NewFlagSet()
+	if err := flags.Parse([]string{"--no-report"}); err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	cfg, err := config.LoadConfigFromFlags(flags, nil)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	logger := arr.NewSlogLogger(slog.DiscardHandler, arr.LoggerOptions{})
+	runs, err := runCleanup(context.Background(), cfg, logger, nil)
+	if len(runs) != 2 {
+		t.Fatalf("Expected a failed run for each service, got %d", len(runs))
+	}
+	if code := exitCode(err); code != exitConnection {
+		t.Errorf("Expected exit code %d, got %d (%v)", exitConnection, code, err)
+	}
+}
+
+func TestRunCleanup_ExitsWithCancelledCodeWhenInterrupted(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("REPORT_DIR", filepath.Join(dir, "reports"))
+	t.Setenv("STATE_DIR", filepath.Join(dir, "state"))
+	t.Setenv("SONARR_URL", "http://127.0.0.1:1")
+	t.Setenv("SONARR_API_KEY", "key")
+
+	flags := config.NewFlagSet()
+	if err := flags.Parse([]string{"--no-report", "--service", "sonarr"}); err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	cfg, err := config.LoadConfigFromFlags(flags, nil)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	logger := arr.NewSlogLogger(slog.DiscardHandler, arr.LoggerOptions{})
+	_, err = runCleanup(ctx, cfg, logger, nil)
+	if code := exitCode(err); code != exitCancelled {
+		t.Errorf("Expected exit code %d, got %d (%v)", exitCancelled, code, err)
+	}
+}
+
+func TestExitSummaryLine(t *testing.T) {
+	sonarr := &history.Run{Service: "sonarr", Success: true, Stats: models.CleanupStats{TotalItemsChecked: 1000, MissingFiles: 10, DeletedRecords: 9, Errors: 1, Warnings: 3}}
+	radarr := &history.Run{Service: "radarr", Success: true, Stats: models.CleanupStats{TotalItemsChecked: 234, MissingFiles: 2, DeletedRecords: 2}}
+	failed := &history.Run{Service: "radarr", Error: "failed to connect to Radarr"}
+
+	tests := []struct {
+		name     string
+		result   string
+		runs     []*history.Run
+		duration time.Duration
+		want     string
+	}{
+		{name: "success", result: "success", runs: []*history.Run{radarr}, duration: 312 * time.Second,
+			want: "result=success checked=234 missing=2 deleted=2 errors=0 duration=312s"},
+		{name: "runs are summed", result: "warnings", runs: []*history.Run{sonarr, radarr}, duration: 1500 * time.Millisecond,
+			want: "result=warnings checked=1234 missing=12 deleted=11 errors=1 duration=2s"},
+		{name: "failed run", result: "failed", runs: []*history.Run{failed}, duration: 400 * time.Millisecond,
+			want: "result=failed checked=0 missing=0 deleted=0 errors=0 duration=0s"},
+		{name: "no runs", result: "failed", duration: 3 * time.Second,
+			want: "result=failed checked=0 missing=0 deleted=0 errors=0 duration=3s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitSummaryLine(tt.result, tt.runs, tt.duration); got != tt.want {
+				t.Errorf("exitSummaryLine() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintExitSummary(t *testing.T) {
+	runs := []*history.Run{{Service: "radarr", Stats: models.CleanupStats{TotalItemsChecked: 5, MissingFiles: 1}}}
+	want := "result=failed checked=5 missing=1 deleted=0 errors=0 duration=7s"
+
+	tests := []struct {
+		name      string
+		logTarget string
+		logged    bool
+	}{
+		// Logged on stderr, the line would show up twice
+		{name: "stderr", logTarget: "stderr", logged: false},
+		{name: "syslog", logTarget: "syslog", logged: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs, stderr bytes.Buffer
+			logger := arr.NewSlogLogger(slog.NewTextHandler(&logs, nil), arr.LoggerOptions{})
+
+			printExitSummary(&config.Config{LogTarget: tt.logTarget}, &stderr, "failed", runs, 7*time.Second, logger)
+
+			// The line is the whole of what's written to stderr, ending it
+			if stderr.String() != want+"\n" {
+				t.Errorf("Expected stderr %q, got %q", want+"\n", stderr.String())
+			}
+			if logged := strings.Contains(logs.String(), want); logged != tt.logged {
+				t.Errorf("Expected logged = %v, got logs %q", tt.logged, logs.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"log"
+	"log/slog"
+	"os"
+
+	"github.com/hnipps/refresharr/internal/arr"
+	"github.com/hnipps/refresharr/internal/config"
+)
+
+// newLogger creates the logger for the configured log target and format. A syslog server or
+// journal that can't be reached is a configuration error, so the command exits with exitConfig.
+func newLogger(cfg *config.Config) arr.Logger {
+	opts := arr.LoggerOptions{Level: cfg.LogLevel, ModuleLevels: cfg.LogModuleLevels}
+	var handler slog.Handler
+	var err error
+	switch cfg.LogTarget {
+	case "syslog":
+		handler, err = arr.NewSyslogHandler(cfg.SyslogAddr, cfg.SyslogFacility)
+	case "journald":
+		handler, err = arr.NewJournalHandler("")
+	default:
+		if cfg.Output == config.OutputJSON {
+			// The output document is all stdout holds, and stderr just the exit summary line, so
+			// scripts needn't filter logs. Only cleanup and verify get here, see checkOutput.
+			return arr.NewSlogLogger(slog.DiscardHandler, opts)
+		}
+		return arr.NewLogger(cfg.LogFormat, opts)
+	}
+	if err != nil {
+		log.Printf("Failed to set up %s logging: %v", cfg.LogTarget, err)
+		os.Exit(exitConfig)
+	}
+	return arr.NewSlogLogger(handler, opts)
+}
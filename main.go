@@ -2,17 +2,27 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
 	"os"
+	"os/signal"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
 
 	"github.com/hnipps/refresharr/internal/arr"
 	"github.com/hnipps/refresharr/internal/config"
 	"github.com/hnipps/refresharr/internal/filesystem"
+	"github.com/hnipps/refresharr/internal/history"
+	"github.com/hnipps/refresharr/internal/messages"
+	"github.com/hnipps/refresharr/internal/notify"
 	"github.com/hnipps/refresharr/internal/plex"
+	"github.com/hnipps/refresharr/internal/prowlarr"
 	"github.com/hnipps/refresharr/internal/report"
+	"github.com/hnipps/refresharr/internal/sink"
 	"github.com/hnipps/refresharr/pkg/models"
 )
 
@@ -20,66 +30,27 @@ import (
 var version = "dev"
 
 func main() {
-	ctx := context.Background()
-
-	// Determine command - check if first argument is a known command
-	args := os.Args[1:]
-	var command string
-	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
-		// First arg doesn't start with "-", could be a command
-		switch args[0] {
-		case "fix-imports":
-			command = "fix-imports"
-			// Remove command from args for flag parsing
-			os.Args = append([]string{os.Args[0]}, args[1:]...)
-		case "compare-plex":
-			command = "compare-plex"
-			// Remove command from args for flag parsing
-			os.Args = append([]string{os.Args[0]}, args[1:]...)
-		default:
-			command = "cleanup" // Default command
-		}
-	} else {
-		command = "cleanup" // Default command
-	}
+	// Commands stop at the next safe point on SIGINT or SIGTERM and exit with exitCancelled
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	// Load configuration
-	cfg, err := config.LoadConfig()
-	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
-	}
-
-	// Handle version flag
-	if cfg.ShowVersion {
-		fmt.Printf("RefreshArr version %s\n", version)
-		fmt.Println("Missing File Cleanup Service for Sonarr and Radarr")
-		os.Exit(0)
-	}
-
-	// Route to appropriate command handler
-	switch command {
-	case "fix-imports":
-		runFixImportsCommand(ctx, cfg)
-	case "compare-plex":
-		runComparePlexCommand(ctx, cfg)
-	case "cleanup":
-		runCleanupCommand(ctx, cfg)
-	default:
-		log.Fatalf("Unknown command: %s", command)
+	if code := execute(newRootCommand(ctx), os.Args[1:]); code != exitSuccess {
+		stop()
+		os.Exit(code)
 	}
 }
 
 // runFixImportsCommand handles the fix-imports command
 func runFixImportsCommand(ctx context.Context, cfg *config.Config) {
 	// Create logger
-	logger := arr.NewStandardLogger(cfg.LogLevel)
+	logger := newLogger(cfg)
 	logger.Info("Starting RefreshArr %s - Sonarr Import Fixer", version)
 
 	// Only Sonarr is supported for import fixing
 	if cfg.Sonarr.URL == "" || cfg.Sonarr.APIKey == "" {
 		logger.Error("Sonarr must be configured to use the fix-imports command")
 		logger.Error("Please set SONARR_URL and SONARR_API_KEY environment variables or use CLI flags")
-		os.Exit(1)
+		os.Exit(exitConfig)
 	}
 
 	// Create Sonarr client
@@ -88,27 +59,55 @@ func runFixImportsCommand(ctx context.Context, cfg *config.Config) {
 	// Test connection
 	if err := client.TestConnection(ctx); err != nil {
 		logger.Error("Failed to connect to Sonarr: %s", err.Error())
-		os.Exit(1)
+		publishRunOutcome(cfg, "fix-imports", nil, fmt.Errorf("failed to connect to Sonarr: %w", err), logger)
+		os.Exit(exitConnection)
+	}
+
+	// Restrict to a reviewed dry-run artifact if requested
+	scope, err := loadScope(cfg, logger)
+	if err != nil {
+		logger.Error("%s", err.Error())
+		os.Exit(exitConfig)
 	}
 
 	// Create import fixer
-	importFixer := arr.NewImportFixer(client, logger, cfg.DryRun)
+	var importFixer arr.ImportFixService = arr.NewImportFixerWithOptions(client, logger, arr.ImportFixerOptions{
+		DryRun:         cfg.DryRunFor("sonarr") || outsideMaintenanceWindow(cfg, logger),
+		Scope:          scope,
+		DeleteRejected: cfg.DeleteRejectedImports,
+		Requeue:        cfg.RequeueFailedImports,
+		Attempts:       loadImportAttempts(cfg, logger),
+	})
 
 	// Run the import fixer
+	startedAt := time.Now().UTC()
 	result, err := importFixer.FixImports(ctx, true) // removeFromClient = true by default
 	if err != nil {
 		logger.Error("Import fixer failed: %s", err.Error())
-		os.Exit(1)
+		if ctx.Err() != nil {
+			err = withExitCode(exitCancelled, err)
+		}
+		publishRunOutcome(cfg, "fix-imports", nil, err, logger)
+		os.Exit(exitCode(err))
+	}
+	outcome := result.RunResult(history.NewRunID(), startedAt, time.Now().UTC())
+	publishRunOutcome(cfg, "fix-imports", []*models.RunResult{outcome}, nil, logger)
+
+	if result.DryRun {
+		saveDryRunActions(cfg, logger, "fix-imports", "sonarr", "", result.Actions)
 	}
 
 	// Report results
 	if result.DryRun && result.TotalStuckItems > 0 {
 		logger.Info("🔍 Found %d stuck import(s) that would be fixed", result.TotalStuckItems)
 		logger.Info("Run without --dry-run to actually fix these imports")
-	} else if result.FixedItems > 0 {
+	} else if result.FixedItems > 0 || result.RequeuedItems > 0 {
 		logger.Info("🎉 Successfully imported %d out of %d stuck imports!", result.FixedItems, result.TotalStuckItems)
+		if result.RequeuedItems > 0 {
+			logger.Info("🔁 %d items couldn't be imported and were blocklisted; Sonarr is searching for them again", result.RequeuedItems)
+		}
 		if len(result.Errors) > 0 {
-			failedCount := result.TotalStuckItems - result.FixedItems
+			failedCount := result.TotalStuckItems - result.FixedItems - result.RequeuedItems
 			logger.Info("📝 %d items failed to import and were left in queue for manual resolution:", failedCount)
 			for _, errMsg := range result.Errors {
 				logger.Info("  %s", errMsg)
@@ -118,117 +117,720 @@ func runFixImportsCommand(ctx context.Context, cfg *config.Config) {
 	} else if result.TotalStuckItems > 0 {
 		logger.Info("⚠️  No items were successfully imported - all %d items remain in queue for manual resolution", result.TotalStuckItems)
 		logger.Info("Please check these items in Sonarr's Activity → Queue tab and resolve manually.")
+	} else if result.CoolingDown > 0 {
+		logger.Info("⏳ %d stuck import(s) failed recently and will be retried once IMPORT_COOLDOWN (%s) has passed", result.CoolingDown, cfg.ImportCooldown)
 	} else if result.TotalStuckItems == 0 {
 		logger.Info("✨ No stuck imports found - your queue is clean!")
 	}
 }
 
+// outsideMaintenanceWindow reports whether it's outside every maintenance window, and logs when
+// the next one opens. Commands that make their changes in one go then run as dry runs, and
+// their dry-run actions can be applied with --only-from in the window.
+func outsideMaintenanceWindow(cfg *config.Config, logger arr.Logger) bool {
+	now := time.Now()
+	if models.InMaintenanceWindow(cfg.MaintenanceWindows, now) {
+		return false
+	}
+	if next, ok := models.NextMaintenanceWindow(cfg.MaintenanceWindows, now); ok {
+		logger.Warn("🕒 Outside the maintenance windows: changes are deferred and only reported (next window: %s)", next.Format("Mon 2006-01-02 15:04"))
+	}
+	return true
+}
+
+// loadImportAttempts returns the failed fix-imports attempts, or nil when there is no cooldown
+func loadImportAttempts(cfg *config.Config, logger arr.Logger) *arr.ImportAttempts {
+	if cfg.ImportCooldown <= 0 {
+		return nil
+	}
+	attempts, err := arr.LoadImportAttempts(arr.ImportAttemptsPath(cfg.StateDir), cfg.ImportCooldown)
+	if err != nil {
+		logger.Warn("Ignoring saved import attempts: %s", err.Error())
+	}
+	return attempts
+}
+
 // runCleanupCommand handles the default cleanup command
 func runCleanupCommand(ctx context.Context, cfg *config.Config) {
 	// Create logger
-	logger := arr.NewStandardLogger(cfg.LogLevel)
+	logger := newLogger(cfg)
 	logger.Info("Starting RefreshArr %s - Missing File Cleanup Service", version)
 
-	// Create file system checker
-	fileChecker := filesystem.NewFileSystemChecker()
+	startedAt := time.Now().UTC()
+	runs, err := runCleanup(ctx, cfg, logger, nil)
+	// An interrupted run's outcome is still reported
+	ctx = context.WithoutCancel(ctx)
+	reportFailures(ctx, cfg, "cleanup", runs, err, logger)
+	// The runs' own pushes went out with their reports
+	if len(runs) == 0 {
+		publishRunOutcome(cfg, "cleanup", nil, err, logger)
+	}
+	if cfg.Output == config.OutputJSON {
+		os.Exit(finishOutput("cleanup", cfg, startedAt, runs, err, os.Stdout, os.Stderr, logger))
+	}
+	if cfg.JobMode {
+		os.Exit(finishJob("cleanup", cfg, startedAt, runs, err, logger))
+	}
+	if errors.Is(err, errCompletedWithWarnings) {
+		logger.Warn("%s", err.Error())
+	} else if err != nil {
+		logger.Error("%s", err.Error())
+	} else {
+		logger.Info("🎉 All cleanup operations completed successfully!")
+	}
+	printExitSummary(cfg, os.Stderr, exitStatus(err), runs, time.Since(startedAt), logger)
+	if err != nil {
+		os.Exit(exitCode(err))
+	}
+}
+
+// errCompletedWithWarnings is returned by runCleanup when every service's run was a partial
+// success: nothing failed outright, but some items were skipped or hit transient errors
+var errCompletedWithWarnings = errors.New("cleanup completed with warnings; a later run may handle the rest")
+
+// runCleanup runs the cleanup for all configured services, following their progress on status
+// when it isn't nil. It returns the services' runs, and an error if any of them failed.
+func runCleanup(ctx context.Context, cfg *config.Config, logger arr.Logger, status *arr.StatusTracker) ([]*history.Run, error) {
+	// Create file system checker and determine which service(s) to run based on configuration
+	fileChecker := filesystem.NewPacedFileSystemChecker(cfg.IOOpsPerSecond)
+	// Simulated and replayed runs replace the checker, so they count no filesystem calls
+	fileOps, _ := fileChecker.(arr.FileOpCounter)
+	var services []ServiceInfo
+	switch {
+	case cfg.Simulate != "":
+		var err error
+		fileChecker, services, err = loadSimulation(cfg, logger)
+		if err != nil {
+			return nil, withExitCode(exitConfig, fmt.Errorf("failed to load simulation: %w", err))
+		}
+	case cfg.Replay != "":
+		var err error
+		fileChecker, services, err = loadReplay(cfg, logger)
+		if err != nil {
+			return nil, withExitCode(exitConfig, fmt.Errorf("failed to load replay bundle: %w", err))
+		}
+	case cfg.Record != "":
+		recorder := startRecording(cfg, logger)
+		defer saveRecording(recorder, cfg.Record, logger)
+		fileChecker = recorder.FileChecker(fileChecker)
+		services = determineServicesWithTransport(cfg, recorder.Transport, logger)
+	default:
+		services = determineServices(cfg, logger)
+	}
+	if len(services) == 0 {
+		return nil, withExitCode(exitConfig, fmt.Errorf("no services configured or available"))
+	}
+
+	// Go easy on disks other programs are streaming from
+	if cfg.Simulate == "" && cfg.Replay == "" {
+		if cfg.IONice != "" {
+			if err := filesystem.SetIOPriority(cfg.IONice); err != nil {
+				logger.Warn("⚠️  Could not set the IO priority: %s", err.Error())
+			} else {
+				logger.Info("🐢 IO priority lowered to %s", cfg.IONice)
+			}
+		}
+		if cfg.IOOpsPerSecond > 0 {
+			logger.Info("🐢 File checks limited to %d operations per second", cfg.IOOpsPerSecond)
+		}
+	}
 
 	// Create progress reporter
 	progressReporter := arr.NewConsoleProgressReporter(logger)
 
-	// Determine which service(s) to run based on configuration
-	services := determineServices(cfg, logger)
-	if len(services) == 0 {
-		logger.Error("No services configured or available")
-		os.Exit(1)
+	// Restrict to a reviewed dry-run artifact if requested
+	scope, err := loadScope(cfg, logger)
+	if err != nil {
+		return nil, withExitCode(exitConfig, err)
+	}
+
+	// Restrict to the IDs listed in a file if requested
+	var targets *arr.TargetList
+	if cfg.IDsFile != "" {
+		targets, err = arr.LoadTargetList(cfg.IDsFile)
+		if err != nil {
+			return nil, withExitCode(exitConfig, err)
+		}
+		logger.Info("📋 Loaded %d IDs from %s", targets.Size(), cfg.IDsFile)
+	}
+
+	// Parse broken symlink paths with the configured naming schemes as well as the default tags
+	pathParser, err := models.NewPathParser(cfg.PathPatterns)
+	if err != nil {
+		return nil, withExitCode(exitConfig, err)
+	}
+	if len(cfg.PathPatterns) > 0 {
+		logger.Info("🧩 Parsing media paths with %d custom pattern(s)", len(cfg.PathPatterns))
+	}
+
+	// Check file contents against a checksum manifest in verify runs
+	var checksums map[string]string
+	if cfg.ChecksumManifest != "" {
+		if cfg.Verify {
+			checksums, err = filesystem.LoadChecksumManifest(cfg.ChecksumManifest)
+			if err != nil {
+				return nil, withExitCode(exitConfig, err)
+			}
+			logger.Info("🔐 Checking %d file(s) against the checksums in %s", len(checksums), cfg.ChecksumManifest)
+		} else {
+			logger.Warn("⚠️  Checksum manifest ignored: checksums are only checked by the verify command")
+		}
+	}
+
+	command := "cleanup"
+	if cfg.Verify {
+		command = "verify"
+	}
+
+	// Check Prowlarr indexer health before triggering missing searches
+	var searchGate arr.SearchGate
+	if cfg.Prowlarr.Configured() && !cfg.Verify && cfg.Simulate == "" && cfg.Replay == "" {
+		logger.Info("📡 Missing searches will be skipped when all Prowlarr indexers are unavailable")
+		searchGate = prowlarr.NewClient(&cfg.Prowlarr, cfg.RequestTimeout, logger)
 	}
 
 	allSuccessful := true
-	allResults := make([]*models.CleanupResult, 0, len(services))
+	anyWarnings := false
+	connectionFailures := 0
+	var crashErr error
+	targetResolved := false
+	runs := make([]*history.Run, 0, len(services))
+	// A run is done once its cleanup returns; this catches the one a panic leaves tracked
+	var tracked []string
+	defer func() {
+		for _, runID := range tracked {
+			status.Done(runID)
+		}
+	}()
+
+	// Reports are saved, and alerts sent, in the background so a slow sink doesn't hold up the
+	// next service. Their failures are recorded with the runs.
+	reportSinks, err := report.ParseSinks(cfg.ReportSinks, report.SinkOptions{Dir: cfg.ReportDir, Messages: cfg.Messages, Timeout: cfg.RequestTimeout})
+	if err != nil {
+		return nil, withExitCode(exitConfig, err)
+	}
+	reportGenerator := report.NewGeneratorWithOptions(logger, report.GeneratorOptions{Messages: cfg.Messages, Dir: cfg.ReportDir, Sinks: reportSinks})
+	sinks := sink.NewDispatcher(sink.DefaultWorkers, cfg.SinkQueueSize, cfg.SinkTimeout)
+	defer sinks.Close()
+	var reportPaths sync.Map // run ID -> saved report path
+
+	// Simulated and replayed runs say nothing about the current library, so they are left out
+	// of history, alerting and the saved reports
+	recordRuns := cfg.Simulate == "" && cfg.Replay == ""
 
 	// Process each configured service
 	for _, serviceInfo := range services {
-		logger.Info("Processing %s service...", serviceInfo.Name)
+		// Leave the remaining services alone once the run is interrupted
+		if ctx.Err() != nil {
+			break
+		}
+
+		if scope != nil && scope.Service != "" && (scope.Service != serviceInfo.Name || scope.Instance != serviceInfo.Instance) {
+			logger.Info("Skipping %s service: %s was generated for %s", serviceInfo.Label(), scope.Source, models.ServiceLabel(scope.Service, scope.Instance))
+			continue
+		}
+
+		if len(cfg.EpisodeIDs) > 0 && serviceInfo.Name != "sonarr" {
+			logger.Info("Skipping %s service: --episode-ids only applies to Sonarr", serviceInfo.Label())
+			continue
+		}
+		// IDs of one service mean nothing to another, and without this the other
+		// services would run an untargeted cleanup of their whole library
+		if len(cfg.SeriesIDs) > 0 && serviceInfo.Name != "sonarr" {
+			logger.Info("Skipping %s service: --series-ids only applies to Sonarr", serviceInfo.Label())
+			continue
+		}
+		if len(cfg.MovieIDs) > 0 && serviceInfo.Name != "radarr" {
+			logger.Info("Skipping %s service: --movie-ids only applies to Radarr", serviceInfo.Label())
+			continue
+		}
+		if targets != nil && serviceInfo.Name == "readarr" {
+			logger.Info("Skipping %s service: --ids-file only lists series and movies", serviceInfo.Label())
+			continue
+		}
+
+		logger.Info("Processing %s service...", serviceInfo.Label())
+		dryRun := cfg.DryRunFor(serviceInfo.Label())
+		run := &history.Run{
+			ID:        history.NewRunID(),
+			Command:   command,
+			Service:   serviceInfo.Label(),
+			DryRun:    dryRun,
+			StartedAt: time.Now().UTC(),
+		}
+		runs = append(runs, run)
+
+		// Every line of the run carries its ID and service, so log pipelines can pick out runs
+		runLogger := arr.WithItem(logger, arr.ItemFields{RunID: run.ID, Service: serviceInfo.Label()})
+
+		// Sample the resources the service's run uses
+		monitor := arr.StartResourceMonitor(100 * time.Millisecond)
+		var opsBefore int64
+		if fileOps != nil {
+			opsBefore = fileOps.FileOps()
+		}
 
 		// Create cleanup service with concurrency support
-		cleanupService := arr.NewCleanupServiceWithConcurrency(
+		tracked = append(tracked, run.ID)
+		missingBefore, missingBeforeTruncated := previouslyMissing(cfg, serviceInfo.Label(), runLogger)
+		cleanupService := arr.NewCleanupServiceWithOptions(
 			serviceInfo.Client,
 			fileChecker,
-			logger,
-			progressReporter,
-			cfg.RequestDelay,
-			cfg.ConcurrentLimit,
-			cfg.DryRun,
-			cfg.QualityProfileID,
-			cfg.AddMissingMovies,
+			arr.ForModule(runLogger, arr.ModuleCleanup),
+			status.Track(run.ID, command, serviceInfo.Label(), dryRun, progressReporter),
+			arr.CleanupOptions{
+				RequestDelay:         cfg.RequestDelay,
+				ConcurrentLimit:      cfg.ConcurrentLimit,
+				EpisodeConcurrency:   cfg.EpisodeConcurrency,
+				MovieConcurrency:     cfg.MovieConcurrency,
+				DryRun:               dryRun,
+				QualityProfileID:     cfg.QualityProfileID,
+				AddMissingMovies:     cfg.AddMissingMovies,
+				Scope:                scope,
+				SearchGate:           searchGate,
+				Seasons:              cfg.Seasons,
+				VerifyOnly:           cfg.Verify,
+				APIBudget:            cfg.APIBudget,
+				SpillAfter:           cfg.SpillAfter,
+				MediaCache:           openMediaCache(cfg, serviceInfo.Label(), runLogger),
+				SkipSpecials:         cfg.SkipSpecials,
+				PathParser:           pathParser,
+				TitleMatchConfidence: cfg.TitleMatchConfidence,
+				MonitorCollections:   cfg.MonitorCollections,
+				ValidateAdds:         cfg.ValidateAdds,
+				DeleteEmptyPaths:     cfg.EmptyPathPolicy == config.EmptyPathDelete,
+				OutsideRootPolicy:    cfg.OutsideRootPolicy,
+				PathMappings:         cfg.PathMappings,
+				Checksums:            checksums,
+				PreviouslyMissing:    missingBefore,
+				PreviouslyTruncated:  missingBeforeTruncated,
+				Instance:             serviceInfo.Instance,
+				MaintenanceWindows:   cfg.MaintenanceWindows,
+				Tag:                  cfg.CleanupTag,
+			},
 		)
 
-		// Run cleanup (with series filtering if applicable)
+		// Run cleanup (with series, episode or path targeting if applicable)
 		var result *models.CleanupResult
 		var err error
-		if serviceInfo.Name == "sonarr" && len(cfg.SeriesIDs) > 0 {
+		switch {
+		case cfg.TargetPath != "":
+			// Handle just the record that owns the file
+			result, err = cleanupService.CleanupMissingFileAtPath(ctx, cfg.TargetPath)
+			if errors.Is(err, arr.ErrNoOwningRecord) {
+				runLogger.Info("%s: %s", serviceInfo.Label(), err.Error())
+				monitor.Stop()
+				runs = runs[:len(runs)-1]
+				continue
+			}
+			targetResolved = targetResolved || err == nil
+		case targets != nil:
+			// Process only the series or movies listed in the IDs file
+			var ids []int
+			ids, err = targets.Resolve(ctx, serviceInfo.Client, runLogger)
+			if err == nil && len(ids) == 0 {
+				runLogger.Info("No %s items listed in %s", serviceInfo.Label(), cfg.IDsFile)
+				monitor.Stop()
+				runs = runs[:len(runs)-1]
+				continue
+			}
+			if err == nil && serviceInfo.Name == "sonarr" {
+				result, err = cleanupService.CleanupMissingFilesForSeries(ctx, ids)
+			} else if err == nil {
+				result, err = cleanupService.CleanupMissingFilesForMovies(ctx, ids)
+			}
+		case serviceInfo.Name == "sonarr" && len(cfg.EpisodeIDs) > 0:
+			// Filter to specific episodes for Sonarr
+			result, err = cleanupService.CleanupMissingFilesForEpisodes(ctx, cfg.EpisodeIDs)
+		case serviceInfo.Name == "sonarr" && len(cfg.SeriesIDs) > 0:
 			// Filter to specific series for Sonarr
 			result, err = cleanupService.CleanupMissingFilesForSeries(ctx, cfg.SeriesIDs)
-		} else {
+		case serviceInfo.Name == "radarr" && len(cfg.MovieIDs) > 0:
+			// Filter to specific movies for Radarr
+			result, err = cleanupService.CleanupMissingFilesForMovies(ctx, cfg.MovieIDs)
+		default:
 			// Clean all missing files
 			result, err = cleanupService.CleanupMissingFiles(ctx)
 		}
 
+		run.FinishedAt = time.Now().UTC()
+		status.Done(run.ID)
+		peakMemory, peakGoroutines := monitor.Stop()
+		if result != nil {
+			result.Stats.PeakMemoryBytes = peakMemory
+			result.Stats.PeakGoroutines = peakGoroutines
+			if fileOps != nil {
+				result.Stats.FileOps = fileOps.FileOps() - opsBefore
+			}
+			if result.Report != nil {
+				result.Report.Resources = result.Stats.Resources()
+			}
+			run.Stats = result.Stats
+			run.Success = result.Success
+			if result.Report != nil {
+				// Spilled entries are only kept in the report file, not in history
+				run.MissingFiles = result.Report.MissingFiles
+				run.MissingFilesTruncated = result.Report.Spilled != nil
+				run.Recovered = result.Report.Recovered
+			}
+		}
+
 		if err != nil {
-			logger.Error("Cleanup failed for %s: %s", serviceInfo.Name, err.Error())
+			if result != nil && result.Report != nil {
+				result.Report.Close()
+			}
+			runLogger.Error("Cleanup failed for %s: %s", serviceInfo.Label(), err.Error())
+			run.Success = false
+			run.Error = err.Error()
 			allSuccessful = false
+			if errors.Is(err, arr.ErrConnectionFailed) {
+				connectionFailures++
+			}
+
+			var panicErr *arr.PanicError
+			if errors.As(err, &panicErr) {
+				crashErr = handleRunCrash(cfg, run, panicErr, runLogger)
+				break
+			}
 			continue
 		}
 
-		allResults = append(allResults, result)
+		// The report's summary is printed before it's handed to the sinks, which close it once
+		// it's written, so a spilled report's temporary file doesn't outlive the service's run
+		if !cfg.NoReport && result.Report != nil {
+			runLogger.Info("Report for %s:", result.Report.Label())
+			reportGenerator.PrintReport(result.Report)
+		}
+		if !cfg.NoReport && recordRuns && result.Report != nil {
+			submitReport(sinks, reportGenerator, run, result.Report, &reportPaths)
+		} else if result.Report != nil {
+			result.Report.Close()
+		}
+
+		runLogger.Info("📡 %s API calls this run: %d", serviceInfo.Label(), result.Stats.APICalls)
+		runLogger.Info("📈 %s resources: peak memory %.1f MiB, peak goroutines %d, %d API calls, %d filesystem calls",
+			serviceInfo.Label(), float64(result.Stats.PeakMemoryBytes)/(1<<20), result.Stats.PeakGoroutines, result.Stats.APICalls, result.Stats.FileOps)
+		overBudget := result.Report != nil && result.Report.OverBudget
+		if overBudget && !dryRun {
+			runLogger.Warn("%s API budget of %d calls was exceeded; remaining changes were only reported", serviceInfo.Label(), cfg.APIBudget)
+		}
+		deferred := result.Report != nil && result.Report.OutsideWindow
+		if deferred && !dryRun {
+			runLogger.Warn("%s changes outside the maintenance windows were deferred; apply them in one with --only-from", serviceInfo.Label())
+		}
+
+		// Save what a dry run, or the report-only part of an over-budget or deferred run, would
+		// have changed
+		if (dryRun && !cfg.Verify) || ((overBudget || deferred) && !dryRun) {
+			saveDryRunActions(cfg, runLogger, "cleanup", serviceInfo.Name, serviceInfo.Instance, result.Actions)
+		}
 
-		if !result.Success {
-			logger.Warn("%s cleanup completed with errors", serviceInfo.Name)
+		switch result.Severity() {
+		case models.SeverityError:
+			runLogger.Warn("%s", cfg.Messages.Sprintf(messages.SummaryErrors, serviceInfo.Label()))
 			for _, msg := range result.Messages {
-				logger.Warn("  %s", msg)
+				runLogger.Warn("  %s", msg)
 			}
 			allSuccessful = false
-		} else {
-			logger.Info("🎉 %s cleanup completed successfully!", serviceInfo.Name)
+		case models.SeverityWarning:
+			runLogger.Warn("%s", cfg.Messages.Sprintf(messages.SummaryWarnings, serviceInfo.Label(), result.Stats.Warnings))
+			for _, msg := range result.Messages {
+				runLogger.Warn("  %s", msg)
+			}
+			anyWarnings = true
+		default:
+			runLogger.Info("%s", cfg.Messages.Sprintf(messages.SummarySuccess, serviceInfo.Label()))
 		}
 	}
 
-	// Generate combined report if we have results and reports are enabled
-	if len(allResults) > 0 && !cfg.NoReport {
-		reportGenerator := report.NewGenerator(logger)
+	// Wait for the reports, which alerts link to
+	failures := sinks.Wait()
+	for _, run := range runs {
+		if path, ok := reportPaths.Load(run.ID); ok {
+			run.ReportPath = path.(string)
+		}
+	}
 
-		for i, result := range allResults {
-			if result.Report != nil {
-				serviceName := services[i].Name
-				logger.Info("Report for %s:", serviceName)
-				if err := reportGenerator.GenerateReport(result.Report, true); err != nil {
-					logger.Warn("Failed to generate report for %s: %s", serviceName, err.Error())
-				}
+	historyStore := history.NewStoreWithLogger(cfg.StateDir, logger)
+
+	// Alert when a verify sweep finds too many, or more, missing files, and tell the completion
+	// webhook how each run went
+	if recordRuns {
+		if cfg.Verify {
+			checkVerifyAlerts(cfg, historyStore, runs, sinks, logger)
+		}
+		results := runResults(runs)
+		submitCompletions(cfg, results, sinks, logger)
+		submitPushes(cfg, command, results, nil, sinks, logger)
+		for runID, runFailures := range sinks.Wait() {
+			failures[runID] = append(failures[runID], runFailures...)
+		}
+	}
+
+	// Report and alert failures don't fail the run, but are kept with it
+	for _, run := range runs {
+		run.SinkFailures = failures[run.ID]
+		for _, failure := range run.SinkFailures {
+			logger.Warn("📮 %s %s was not delivered: %s", run.Service, failure.Sink, failure.Error)
+		}
+	}
+
+	// Record the runs so they can be queried with the history command
+	if recordRuns {
+		for _, run := range runs {
+			if err := historyStore.Append(run); err != nil {
+				logger.Warn("Failed to record %s run in history: %s", run.Service, err.Error())
 			}
 		}
+		pruneHistory(cfg, historyStore, logger)
+	}
+	archiveReports(cfg, logger)
+
+	if crashErr != nil {
+		return runs, crashErr
+	}
+	if ctx.Err() != nil {
+		return runs, withExitCode(exitCancelled, fmt.Errorf("cleanup interrupted: %w", ctx.Err()))
+	}
+	if connectionFailures > 0 && connectionFailures == len(runs) {
+		return runs, withExitCode(exitConnection, fmt.Errorf("could not connect to any service"))
+	}
+
+	if cfg.TargetPath != "" && !targetResolved && allSuccessful {
+		return runs, fmt.Errorf("no episode or movie record owns %s", cfg.TargetPath)
 	}
 
 	if !allSuccessful {
-		logger.Warn("Some cleanup operations completed with errors")
-		os.Exit(1)
+		return runs, fmt.Errorf("some cleanup operations completed with errors")
+	}
+	if anyWarnings {
+		return runs, errCompletedWithWarnings
+	}
+
+	return runs, nil
+}
+
+// submitReport queues the run's report to be written to each report sink, storing the path the
+// primary sink saved it to in paths by run ID. The report is closed once every sink is done
+// with it.
+func submitReport(sinks *sink.Dispatcher, generator *report.Generator, run *history.Run, r *models.MissingFilesReport, paths *sync.Map) {
+	reportSinks := generator.Sinks()
+	primary := report.PrimarySink(reportSinks)
+	// A hold for each sink's write, and one until they're all queued
+	var holds atomic.Int32
+	holds.Store(int32(len(reportSinks)) + 1)
+	release := func() {
+		if holds.Add(-1) == 0 {
+			r.Close()
+		}
+	}
+	defer release()
+
+	for _, reportSink := range reportSinks {
+		queued := sinks.Submit("report:"+reportSink.Name(), run.ID, func(ctx context.Context) error {
+			defer release()
+			location, err := generator.WriteReport(ctx, reportSink, r)
+			if err != nil {
+				return err
+			}
+			if reportSink == primary {
+				paths.Store(run.ID, location)
+			}
+			return nil
+		})
+		if !queued {
+			release()
+		}
+	}
+}
+
+// submitCompletions queues a summary of each run for the completion webhook, if one is
+// configured. Simulated and replayed runs say nothing about the live instances and aren't sent.
+func submitCompletions(cfg *config.Config, runs []*models.RunResult, sinks *sink.Dispatcher, logger arr.Logger) {
+	if cfg.Simulate != "" || cfg.Replay != "" {
+		return
+	}
+	client := notify.NewCompletionClient(&cfg.Notify, cfg.RequestTimeout, arr.ForModule(logger, arr.ModuleNotify))
+	if client == nil {
+		return
+	}
+
+	for _, run := range runs {
+		completion := notify.CompletionFor(run, cfg.Messages)
+		service := run.Service
+		sinks.Submit("notify:completion", run.RunID, func(ctx context.Context) error {
+			if err := client.Send(ctx, completion); err != nil {
+				logger.Error("Failed to send %s completion webhook: %s", service, err.Error())
+				return err
+			}
+			logger.Debug("Completion webhook sent for %s", service)
+			return nil
+		})
+	}
+}
+
+// pruneHistory drops the runs older than HISTORY_RETENTION_DAYS from the history, so it doesn't
+// grow without bound
+func pruneHistory(cfg *config.Config, store *history.Store, logger arr.Logger) {
+	if cfg.HistoryRetentionDays == 0 {
+		return
+	}
+	removed, err := store.Prune(time.Now().AddDate(0, 0, -cfg.HistoryRetentionDays))
+	if err != nil {
+		logger.Warn("Failed to prune the run history: %s", err.Error())
+		return
+	}
+	if removed > 0 {
+		logger.Info("🧹 Removed %d run(s) older than %d days from the history", removed, cfg.HistoryRetentionDays)
+	}
+}
+
+// previouslyMissing returns the files earlier runs of the service found missing that haven't
+// been found valid again, so the run can report them once they are, and whether the history
+// left some out. Simulated and replayed runs aren't compared with history.
+func previouslyMissing(cfg *config.Config, service string, logger arr.Logger) ([]models.MissingFileEntry, bool) {
+	if cfg.Simulate != "" || cfg.Replay != "" {
+		return nil, false
+	}
+	missing, truncated, err := history.NewStoreWithLogger(cfg.StateDir, logger).Unrecovered(service)
+	if err != nil {
+		logger.Warn("Failed to read %s history, recovered files won't be reported: %s", service, err.Error())
+		return nil, true
+	}
+	if truncated {
+		logger.Info("Some %s runs in the history recorded only part of their missing files; those past it won't be reported as recovered", service)
+	}
+	return missing, truncated
+}
+
+// openMediaCache returns the service's library cache, or nil when caching is disabled. Simulated,
+// replayed and recorded runs don't use the cache, so they see the same requests every time.
+// With --refresh-cache the saved library is ignored and replaced by a fresh fetch.
+func openMediaCache(cfg *config.Config, service string, logger arr.Logger) *arr.MediaCache {
+	if cfg.MediaCacheTTL <= 0 || cfg.Simulate != "" || cfg.Replay != "" || cfg.Record != "" {
+		return nil
 	}
 
-	logger.Info("🎉 All cleanup operations completed successfully!")
+	path := arr.MediaCachePath(cfg.StateDir, service)
+	if cfg.RefreshCache {
+		return arr.NewMediaCache(path, cfg.MediaCacheTTL)
+	}
+	cache, err := arr.LoadMediaCache(path, cfg.MediaCacheTTL)
+	if err != nil {
+		logger.Warn("Ignoring %s media cache: %s", service, err.Error())
+	}
+	return cache
+}
+
+// loadScope loads the --only-from artifact, if one was given
+func loadScope(cfg *config.Config, logger arr.Logger) (*arr.ActionScope, error) {
+	if cfg.OnlyFrom == "" {
+		return nil, nil
+	}
+
+	scope, err := arr.LoadActionScope(cfg.OnlyFrom)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load --only-from file: %w", err)
+	}
+
+	logger.Info("📋 Restricting run to %d item(s) listed in %s", scope.Size(), scope.Source)
+	return scope, nil
+}
+
+// archiveReports compresses and bundles old files of the reports directory, as configured
+func archiveReports(cfg *config.Config, logger arr.Logger) {
+	if cfg.ReportCompressAfterDays == 0 && !cfg.ReportArchiveMonthly {
+		return
+	}
+	result, err := report.ArchiveReports(cfg.ReportDir, report.ArchiveOptions{
+		CompressAfter: time.Duration(cfg.ReportCompressAfterDays) * 24 * time.Hour,
+		Monthly:       cfg.ReportArchiveMonthly,
+	})
+	if err != nil {
+		logger.Warn("Failed to archive old reports: %s", err.Error())
+	}
+	if result.Compressed > 0 || result.Archived > 0 {
+		logger.Info("🗜️  Compressed %d and archived %d old files in %s", result.Compressed, result.Archived, cfg.ReportDir)
+	}
+}
+
+// saveDryRunActions writes the actions a dry run would have taken to the reports directory.
+// instance names an extra instance of the service (empty for the default one).
+func saveDryRunActions(cfg *config.Config, logger arr.Logger, command, service, instance string, actions []models.PlannedAction) {
+	// Actions planned against fixtures or a bundle can't be applied to the live instances
+	if cfg.Simulate != "" || cfg.Replay != "" {
+		return
+	}
+	actionsFile := &models.ActionsFile{
+		GeneratedAt: time.Now().Format(time.RFC3339),
+		Command:     command,
+		ServiceType: service,
+		Instance:    instance,
+		Actions:     actions,
+	}
+
+	if _, err := report.NewGeneratorWithOptions(logger, report.GeneratorOptions{Dir: cfg.ReportDir}).SaveActions(actionsFile); err != nil {
+		logger.Warn("Failed to save dry-run actions for %s: %s", models.ServiceLabel(service, instance), err.Error())
+	}
 }
 
 // ServiceInfo holds information about a configured service
 type ServiceInfo struct {
-	Name   string
-	Client arr.Client
+	Name     string // "sonarr", "radarr" or "readarr"
+	Instance string // Name of an extra instance of the service (empty for the default one)
+	Client   arr.Client
+}
+
+// Label names the service instance in logs, reports and history, e.g. "radarr" or "radarr-4k"
+func (s ServiceInfo) Label() string {
+	return models.ServiceLabel(s.Name, s.Instance)
+}
+
+// newRadarrClient creates the Radarr client implementation cfg.APIClient selects, with its
+// requests going through wrap (nil sends them directly)
+func newRadarrClient(cfg *config.Config, radarrCfg *config.RadarrConfig, wrap arr.TransportWrapper, logger arr.Logger) arr.Client {
+	if cfg.APIClient == config.APIClientStarr {
+		return arr.NewStarrRadarrClientWithTransport(radarrCfg, cfg.RequestTimeout, wrap, logger)
+	}
+	return arr.NewRadarrClientWithTransport(radarrCfg, cfg.RequestTimeout, wrap, logger)
 }
 
 // determineServices decides which services to run based on configuration
 func determineServices(cfg *config.Config, logger arr.Logger) []ServiceInfo {
+	return determineServicesWithTransport(cfg, nil, logger)
+}
+
+// determineServicesWithTransport is determineServices with the clients' requests going through
+// the wrapper transport returns for each service (nil sends them directly)
+func determineServicesWithTransport(cfg *config.Config, transport func(service, baseURL string) arr.TransportWrapper, logger arr.Logger) []ServiceInfo {
 	var services []ServiceInfo
+	apiLogger := arr.ForModule(logger, arr.ModuleAPI)
+	newSonarrClient := func() arr.Client {
+		var wrap arr.TransportWrapper
+		if transport != nil {
+			wrap = transport("sonarr", cfg.Sonarr.URL)
+		}
+		return arr.NewSonarrClientWithTransport(&cfg.Sonarr, cfg.RequestTimeout, wrap, apiLogger)
+	}
+	newRadarrClient := func() arr.Client {
+		var wrap arr.TransportWrapper
+		if transport != nil {
+			wrap = transport("radarr", cfg.Radarr.URL)
+		}
+		return newRadarrClient(cfg, &cfg.Radarr, wrap, apiLogger)
+	}
+	newReadarrClient := func() arr.Client {
+		var wrap arr.TransportWrapper
+		if transport != nil {
+			wrap = transport("readarr", cfg.Readarr.URL)
+		}
+		return arr.NewReadarrClientWithTransport(&cfg.Readarr, cfg.RequestTimeout, wrap, apiLogger)
+	}
 
 	switch cfg.Service {
 	case "sonarr":
 		if cfg.Sonarr.URL != "" && cfg.Sonarr.APIKey != "" {
-			client := arr.NewSonarrClient(&cfg.Sonarr, cfg.RequestTimeout, logger)
+			client := newSonarrClient()
 			services = append(services, ServiceInfo{Name: "sonarr", Client: client})
 		} else {
 			logger.Error("Sonarr service requested but not properly configured")
@@ -236,43 +838,82 @@ func determineServices(cfg *config.Config, logger arr.Logger) []ServiceInfo {
 
 	case "radarr":
 		if cfg.Radarr.URL != "" && cfg.Radarr.APIKey != "" {
-			client := arr.NewRadarrClient(&cfg.Radarr, cfg.RequestTimeout, logger)
+			client := newRadarrClient()
 			services = append(services, ServiceInfo{Name: "radarr", Client: client})
 		} else {
 			logger.Error("Radarr service requested but not properly configured")
 		}
 
+	case "readarr":
+		if cfg.Readarr.URL != "" && cfg.Readarr.APIKey != "" {
+			client := newReadarrClient()
+			services = append(services, ServiceInfo{Name: "readarr", Client: client})
+		} else {
+			logger.Error("Readarr service requested but not properly configured")
+		}
+
 	case "auto":
 		// Add Sonarr if configured
 		if cfg.Sonarr.URL != "" && cfg.Sonarr.APIKey != "" {
-			client := arr.NewSonarrClient(&cfg.Sonarr, cfg.RequestTimeout, logger)
+			client := newSonarrClient()
 			services = append(services, ServiceInfo{Name: "sonarr", Client: client})
 		}
 
 		// Add Radarr if configured
 		if cfg.Radarr.URL != "" && cfg.Radarr.APIKey != "" {
-			client := arr.NewRadarrClient(&cfg.Radarr, cfg.RequestTimeout, logger)
+			client := newRadarrClient()
 			services = append(services, ServiceInfo{Name: "radarr", Client: client})
 		}
+
+		// Add Readarr if configured
+		if cfg.Readarr.URL != "" && cfg.Readarr.APIKey != "" {
+			client := newReadarrClient()
+			services = append(services, ServiceInfo{Name: "readarr", Client: client})
+		}
+	}
+
+	// Add the extra instances of the requested services. Naming one, e.g. radarr-4k, runs just it.
+	for _, instance := range cfg.Instances {
+		if cfg.Service != "auto" && cfg.Service != instance.Service && cfg.Service != instance.Label() {
+			continue
+		}
+
+		var wrap arr.TransportWrapper
+		if transport != nil {
+			wrap = transport(instance.Label(), instance.URL)
+		}
+		client := newInstanceClient(cfg, instance, wrap, apiLogger)
+		services = append(services, ServiceInfo{Name: instance.Service, Instance: instance.Name, Client: client})
 	}
 
 	return services
 }
 
+// newInstanceClient creates the client of an extra instance, with its requests going through
+// wrap (nil sends them directly)
+func newInstanceClient(cfg *config.Config, instance config.InstanceConfig, wrap arr.TransportWrapper, logger arr.Logger) arr.Client {
+	switch instance.Service {
+	case "sonarr":
+		return arr.NewSonarrClientWithTransport(&config.SonarrConfig{URL: instance.URL, APIKey: instance.APIKey}, cfg.RequestTimeout, wrap, logger)
+	case "readarr":
+		return arr.NewReadarrClientWithTransport(&config.ReadarrConfig{URL: instance.URL, APIKey: instance.APIKey}, cfg.RequestTimeout, wrap, logger)
+	}
+	return newRadarrClient(cfg, &config.RadarrConfig{URL: instance.URL, APIKey: instance.APIKey}, wrap, logger)
+}
+
 // runComparePlexCommand handles the compare-plex command
 func runComparePlexCommand(ctx context.Context, cfg *config.Config) {
 	// Create logger
-	logger := arr.NewStandardLogger(cfg.LogLevel)
+	logger := newLogger(cfg)
 	logger.Info("Starting RefreshArr %s - Plex Comparison Tool", version)
 
 	// Check if TMDB ID is provided as argument
-	// Since we removed the command from os.Args, the TMDB ID should be at position 0
-	args := os.Args[1:]
+	args := cfg.Args
 	if len(args) < 1 {
 		logger.Error("TMDB ID is required as argument")
 		logger.Error("Usage: refresharr compare-plex <tmdb-id>")
 		logger.Error("Example: refresharr compare-plex 12345")
-		os.Exit(1)
+		os.Exit(exitConfig)
 	}
 
 	// Parse TMDB ID
@@ -280,39 +921,46 @@ func runComparePlexCommand(ctx context.Context, cfg *config.Config) {
 	tmdbID, err := strconv.Atoi(tmdbIDStr)
 	if err != nil {
 		logger.Error("Invalid TMDB ID '%s': must be a number", tmdbIDStr)
-		os.Exit(1)
+		os.Exit(exitConfig)
 	}
 
 	// Validate Radarr configuration
 	if cfg.Radarr.URL == "" || cfg.Radarr.APIKey == "" {
 		logger.Error("Radarr must be configured to use the compare-plex command")
 		logger.Error("Please set RADARR_URL and RADARR_API_KEY environment variables")
-		os.Exit(1)
+		os.Exit(exitConfig)
 	}
 
 	// Validate Plex configuration
-	if cfg.Plex.URL == "" || cfg.Plex.Token == "" {
+	if !cfg.Plex.Configured() {
 		logger.Error("Plex must be configured to use the compare-plex command")
-		logger.Error("Please set PLEX_URL and PLEX_TOKEN environment variables")
-		os.Exit(1)
+		logger.Error("Please set PLEX_URL and PLEX_TOKEN environment variables or use --plex-url and --plex-token")
+		os.Exit(exitConfig)
+	}
+	if err := cfg.Plex.Validate(); err != nil {
+		logger.Error("Invalid Plex configuration: %s", err.Error())
+		os.Exit(exitConfig)
 	}
 
 	// Create Radarr client
-	radarrClient := arr.NewRadarrClient(&cfg.Radarr, cfg.RequestTimeout, logger)
+	radarrClient := newRadarrClient(cfg, &cfg.Radarr, nil, logger)
 
 	// Test Radarr connection
 	if err := radarrClient.TestConnection(ctx); err != nil {
 		logger.Error("Failed to connect to Radarr: %s", err.Error())
-		os.Exit(1)
+		os.Exit(exitConnection)
 	}
 
 	// Create Plex client
 	plexClient := plex.NewPlexClient(&cfg.Plex, cfg.RequestTimeout, logger)
+	if len(cfg.Plex.Libraries) > 0 {
+		logger.Info("📚 Limiting Plex lookups to libraries: %s", strings.Join(cfg.Plex.Libraries, ", "))
+	}
 
 	// Test Plex connection
 	if err := plexClient.TestConnection(ctx); err != nil {
 		logger.Error("Failed to connect to Plex: %s", err.Error())
-		os.Exit(1)
+		os.Exit(exitConnection)
 	}
 
 	// Get movie from Radarr by TMDB ID
@@ -345,7 +993,7 @@ func runComparePlexCommand(ctx context.Context, cfg *config.Config) {
 
 	// Get movie from Plex by TMDB ID
 	logger.Info("🔍 Looking up movie with TMDB ID %d in Plex...", tmdbID)
-	plexMovie, err := plexClient.GetMovieByTMDBID(ctx, tmdbID)
+	plexMovie, err := plexClient.GetMovieByIDs(ctx, tmdbID, radarrMovie.IMDBID)
 	if err != nil {
 		logger.Warn("⚠️  Movie with TMDB ID %d not found in Plex: %s", tmdbID, err.Error())
 
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hnipps/refresharr/internal/arr"
+	"github.com/hnipps/refresharr/internal/config"
+	"github.com/hnipps/refresharr/internal/history"
+	"github.com/hnipps/refresharr/internal/notify"
+	"github.com/hnipps/refresharr/internal/report"
+	"github.com/hnipps/refresharr/internal/sink"
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+const routingSonarrFixture = `{
+  "series": [{"id": 1, "title": "Show", "tvdbId": 100}, {"id": 2, "title": "Other Show", "tvdbId": 200}],
+  "episodes": [
+    {"id": 11, "seriesId": 1, "seasonNumber": 1, "episodeNumber": 1, "title": "Pilot", "hasFile": true, "episodeFileId": 101},
+    {"id": 21, "seriesId": 2, "seasonNumber": 1, "episodeNumber": 1, "title": "Pilot", "hasFile": true, "episodeFileId": 201}
+  ],
+  "episodeFiles": [
+    {"id": 101, "path": "/tv/Show/S01E01.mkv"},
+    {"id": 201, "path": "/tv/Other Show/S01E01.mkv"}
+  ]
+}`
+
+const routingRadarrFixture = `{
+  "movies": [
+    {"id": 1, "title": "Foo", "year": 2020, "hasFile": true, "movieFileId": 10, "path": "/movies/Foo (2020)", "tmdbId": 5},
+    {"id": 2, "title": "Bar", "year": 2021, "hasFile": true, "movieFileId": 20, "path": "/movies/Bar (2021)", "tmdbId": 6}
+  ],
+  "movieFiles": [
+    {"id": 10, "movieId": 1, "path": "/movies/Foo (2020)/Foo.mkv", "relativePath": "Foo.mkv", "size": 100},
+    {"id": 20, "movieId": 2, "path": "/movies/Bar (2021)/Bar.mkv", "relativePath": "Bar.mkv", "size": 100}
+  ],
+  "rootFolders": [{"id": 1, "path": "/movies"}]
+}`
+
+func TestRunCleanup_RoutesTargetIDsToTheirService(t *testing.T) {
+	dir := t.TempDir()
+	fixtures := map[string]string{
+		"files.json":  `{"files": []}`,
+		"sonarr.json": routingSonarrFixture,
+		"radarr.json": routingRadarrFixture,
+	}
+	for name, content := range fixtures {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	t.Setenv("REPORT_DIR", filepath.Join(dir, "reports"))
+	t.Setenv("STATE_DIR", filepath.Join(dir, "state"))
+
+	tests := []struct {
+		name    string
+		args    []string
+		service string
+	}{
+		{name: "movie IDs", args: []string{"--movie-ids", "2"}, service: "radarr"},
+		{name: "series IDs", args: []string{"--series-ids", "1"}, service: "sonarr"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags := config.NewFlagSet()
+			args := append([]string{"--simulate", dir, "--dry-run", "--no-report"}, tt.args...)
+			if err := flags.Parse(args); err != nil {
+				t.Fatalf("Failed to parse flags: %v", err)
+			}
+			cfg, err := config.LoadConfigFromFlags(flags, nil)
+			if err != nil {
+				t.Fatalf("Failed to load config: %v", err)
+			}
+
+			logger := arr.NewSlogLogger(slog.DiscardHandler, arr.LoggerOptions{})
+			runs, err := runCleanup(context.Background(), cfg, logger, nil)
+			if err != nil {
+				t.Fatalf("Cleanup failed: %v", err)
+			}
+
+			// The other service is skipped, not cleaned in full
+			if len(runs) != 1 || runs[0].Service != tt.service {
+				t.Fatalf("Expected a single %s run, got %+v", tt.service, runs)
+			}
+			// Only the targeted item is checked
+			if runs[0].Stats.TotalItemsChecked != 1 || runs[0].Stats.MissingFiles != 1 {
+				t.Errorf("Expected one item checked with one missing file, got %+v", runs[0].Stats)
+			}
+		})
+	}
+}
+
+func TestRunCleanup_SimulatedRunsSaveNothing(t *testing.T) {
+	dir := t.TempDir()
+	fixtures := map[string]string{
+		"files.json":  `{"files": []}`,
+		"radarr.json": routingRadarrFixture,
+	}
+	for name, content := range fixtures {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	reportDir := filepath.Join(dir, "reports")
+	t.Setenv("REPORT_DIR", reportDir)
+	t.Setenv("STATE_DIR", filepath.Join(dir, "state"))
+
+	flags := config.NewFlagSet()
+	if err := flags.Parse([]string{"--simulate", dir, "--dry-run", "--service", "radarr"}); err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	cfg, err := config.LoadConfigFromFlags(flags, nil)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	logger := arr.NewSlogLogger(slog.DiscardHandler, arr.LoggerOptions{})
+	runs, err := runCleanup(context.Background(), cfg, logger, nil)
+	if err != nil {
+		t.Fatalf("Cleanup failed: %v", err)
+	}
+	if len(runs) != 1 || runs[0].Stats.MissingFiles != 2 {
+		t.Fatalf("Expected a radarr run with two missing files, got %+v", runs)
+	}
+
+	// Neither the report nor the dry-run actions of a simulated run are saved
+	entries, err := os.ReadDir(reportDir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("Failed to read %s: %v", reportDir, err)
+	}
+	if len(entries) != 0 || runs[0].ReportPath != "" {
+		t.Errorf("Expected nothing saved, got %d files in %s and report path %q", len(entries), reportDir, runs[0].ReportPath)
+	}
+}
+
+func TestPublishRunOutcome_GivesUpAfterSinkTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	cfg := &config.Config{
+		RequestTimeout: time.Minute,
+		SinkTimeout:    50 * time.Millisecond,
+		Notify:         config.NotifyConfig{NtfyURL: server.URL + "/alerts"},
+	}
+	logger := arr.NewSlogLogger(slog.DiscardHandler, arr.LoggerOptions{})
+
+	start := time.Now()
+	publishRunOutcome(cfg, "cleanup", nil, errors.New("no service could be reached"), logger)
+
+	// A dead endpoint holds up the command only as long as SINK_TIMEOUT, not the request timeout
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the push to be given up after the sink timeout, took %s", elapsed)
+	}
+}
+
+func TestPublishRunOutcome_SendsCompletionOfEveryCommand(t *testing.T) {
+	completions := make(chan notify.Completion, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var completion notify.Completion
+		if err := json.NewDecoder(r.Body).Decode(&completion); err != nil {
+			t.Errorf("Failed to decode completion: %v", err)
+		}
+		completions <- completion
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{Notify: config.NotifyConfig{CompletionURL: server.URL}}
+	logger := arr.NewSlogLogger(slog.DiscardHandler, arr.LoggerOptions{})
+	started := time.Date(2026, 1, 1, 3, 0, 0, 0, time.UTC)
+	result := &models.ImportFixResult{Success: true, TotalStuckItems: 4, Errors: []string{"queue item 7 left"}}
+
+	publishRunOutcome(cfg, "fix-imports", []*models.RunResult{result.RunResult("run-1", started, started.Add(time.Minute))}, nil, logger)
+
+	select {
+	case completion := <-completions:
+		if completion.Command != "fix-imports" || completion.RunID != "run-1" || completion.Service != "sonarr" ||
+			completion.Stats.TotalItemsChecked != 4 || completion.Stats.Errors != 1 {
+			t.Errorf("Unexpected completion: %+v", completion)
+		}
+	default:
+		t.Fatal("Expected a completion webhook for the fix-imports run")
+	}
+}
+
+// closeRecordingSource is a spilled report's entries, recording whether they're read after
+// being closed
+type closeRecordingSource struct {
+	entries        []models.MissingFileEntry
+	closed         atomic.Bool
+	readAfterClose atomic.Bool
+}
+
+func (s *closeRecordingSource) Each(fn func(models.MissingFileEntry) error) error {
+	if s.closed.Load() {
+		s.readAfterClose.Store(true)
+	}
+	for _, entry := range s.entries {
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *closeRecordingSource) Close() error {
+	s.closed.Store(true)
+	return nil
+}
+
+func TestSubmitReport_ClosesReportOnceWritten(t *testing.T) {
+	dir := t.TempDir()
+	reportSinks, err := report.ParseSinks([]string{"file://" + dir, "file://" + filepath.Join(dir, "copy")}, report.SinkOptions{Dir: dir})
+	if err != nil {
+		t.Fatalf("Failed to parse sinks: %v", err)
+	}
+	logger := arr.NewSlogLogger(slog.DiscardHandler, arr.LoggerOptions{})
+	generator := report.NewGeneratorWithOptions(logger, report.GeneratorOptions{Dir: dir, Sinks: reportSinks})
+	sinks := sink.NewDispatcher(1, 0, 0)
+	defer sinks.Close()
+
+	spilled := &closeRecordingSource{entries: []models.MissingFileEntry{{FilePath: "/tv/a.mkv"}}}
+	r := &models.MissingFilesReport{ServiceType: "sonarr", RunType: "real-run", TotalMissing: 1, Spilled: spilled}
+	run := &history.Run{ID: "run-1"}
+	var paths sync.Map
+	submitReport(sinks, generator, run, r, &paths)
+
+	if failures := sinks.Wait(); len(failures) != 0 {
+		t.Fatalf("Expected the report to be written, got %+v", failures)
+	}
+	if !spilled.closed.Load() || spilled.readAfterClose.Load() {
+		t.Errorf("Expected the report to be closed after both writes, got closed %v, read after close %v", spilled.closed.Load(), spilled.readAfterClose.Load())
+	}
+	if _, ok := paths.Load(run.ID); !ok {
+		t.Error("Expected the primary sink's path to be stored")
+	}
+}
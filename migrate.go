@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/hnipps/refresharr/internal/arr"
+	"github.com/hnipps/refresharr/internal/config"
+	"github.com/hnipps/refresharr/internal/report"
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+// runMigratePathsCommand handles the migrate-paths command, which points series and movies at
+// their new folders after a storage migration, following the PATH_MAPPINGS rules
+func runMigratePathsCommand(ctx context.Context, cfg *config.Config) {
+	// Create logger
+	logger := newLogger(cfg)
+	logger.Info("Starting RefreshArr %s - Path Migration", version)
+
+	if len(cfg.PathMappings) == 0 {
+		logger.Error("Path mappings are required to use the migrate-paths command")
+		logger.Error("Please set the PATH_MAPPINGS environment variable or use --path-mappings (e.g. /mnt/old/tv=/data/tv)")
+		os.Exit(exitConfig)
+	}
+
+	services := determineServices(cfg, logger)
+	if len(services) == 0 {
+		logger.Error("No services configured. Please set SONARR_URL/SONARR_API_KEY, RADARR_URL/RADARR_API_KEY or READARR_URL/READARR_API_KEY")
+		os.Exit(exitConfig)
+	}
+
+	// Dry run can be set per service, so only services that would write wait for a window
+	live := false
+	for _, service := range services {
+		if !cfg.DryRunFor(service.Label()) {
+			live = true
+		}
+	}
+	if !live {
+		logger.Info("🏃 DRY RUN MODE: No changes will be made")
+	}
+	deferred := live && outsideMaintenanceWindow(cfg, logger)
+
+	generator := report.NewGeneratorWithOptions(logger, report.GeneratorOptions{Dir: cfg.ReportDir})
+	failed := false
+	var dryRunLabels []string
+	for _, service := range services {
+		if _, ok := service.Client.(arr.PathUpdater); !ok {
+			logger.Info("⏭️  Skipping %s: its paths can't be migrated", service.Label())
+			continue
+		}
+		if cfg.DryRunFor(service.Label()) {
+			if live {
+				logger.Info("🏃 DRY RUN MODE for %s: No changes will be made", service.Label())
+			}
+			dryRunLabels = append(dryRunLabels, service.Label())
+		}
+		if err := service.Client.TestConnection(ctx); err != nil {
+			logger.Error("Failed to connect to %s: %s", service.Label(), err.Error())
+			failed = true
+			continue
+		}
+
+		dryRun := cfg.DryRunFor(service.Label()) || deferred
+		migration, err := arr.MigratePaths(ctx, service.Client, arr.MigrateOptions{
+			Mappings:     cfg.PathMappings,
+			DryRun:       dryRun,
+			FolderExists: folderExists,
+		}, logger)
+		if err != nil {
+			logger.Error("%s path migration failed: %s", service.Label(), err.Error())
+			failed = true
+			continue
+		}
+		migration.Instance = service.Instance
+		if _, err := generator.SaveMigrationReport(migration); err != nil {
+			logger.Warn("Failed to save %s migration report: %s", service.Label(), err.Error())
+		}
+
+		logger.Info("📊 %s: %d item(s) checked, %d matched a mapping", service.Label(), migration.Checked, len(migration.Items))
+		if dryRun {
+			logger.Info("   Would move: %d", migration.Count(models.MigrationPlanned))
+		} else {
+			logger.Info("   Moved: %d", migration.Count(models.MigrationMoved))
+		}
+		if missing := migration.Count(models.MigrationTargetMissing); missing > 0 {
+			logger.Warn("   Skipped, new folder missing: %d", missing)
+		}
+		if errs := migration.Count(models.MigrationFailed); errs > 0 {
+			logger.Error("   Failed: %d", errs)
+			failed = true
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+	switch {
+	case !live && cfg.DryRun:
+		logger.Info("Run without --dry-run to apply these path changes")
+	case len(dryRunLabels) > 0:
+		logger.Info("Turn off dry run for %s to apply their path changes", strings.Join(dryRunLabels, ", "))
+	}
+}
+
+// folderExists reports whether path is a directory
+func folderExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hnipps/refresharr/internal/arr"
+	"github.com/hnipps/refresharr/internal/config"
+	"github.com/hnipps/refresharr/internal/history"
+	"github.com/hnipps/refresharr/internal/messages"
+	"github.com/hnipps/refresharr/internal/notify"
+	"github.com/hnipps/refresharr/internal/sink"
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+// runNotifyCommand handles the notify command: test
+func runNotifyCommand(ctx context.Context, cfg *config.Config) {
+	logger := newLogger(cfg)
+	notifiers := notify.Notifiers(&cfg.Notify, cfg.RequestTimeout, arr.ForModule(logger, arr.ModuleNotify))
+	if err := notifyCommand(ctx, cfg.Args, notifiers, cfg.Messages, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// notifyCommand runs a notify subcommand with the configured notifiers and writes the output to w
+func notifyCommand(ctx context.Context, args []string, notifiers []notify.Notifier, catalog *messages.Catalog, w io.Writer) error {
+	if len(args) == 0 || args[0] != "test" {
+		return fmt.Errorf("usage: refresharr notify test")
+	}
+	if len(notifiers) == 0 {
+		return fmt.Errorf("no notifiers configured (set NOTIFY_WEBHOOK_URL, NTFY_URL, PUSHOVER_TOKEN or NOTIFY_URLS)")
+	}
+
+	// Send to every backend, even after one fails, so all problems show up at once
+	alert := notify.TestAlert(catalog)
+	failed := 0
+	for _, notifier := range notifiers {
+		if err := notifier.Send(ctx, alert); err != nil {
+			fmt.Fprintf(w, "❌ %s: %v\n", notifier.Name(), err)
+			failed++
+			continue
+		}
+		fmt.Fprintf(w, "✅ %s: test notification sent\n", notifier.Name())
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d notifiers failed", failed, len(notifiers))
+	}
+	return nil
+}
+
+// runResults returns the results of recorded runs
+func runResults(runs []*history.Run) []*models.RunResult {
+	results := make([]*models.RunResult, 0, len(runs))
+	for _, run := range runs {
+		results = append(results, run.Result())
+	}
+	return results
+}
+
+// publishRunOutcome sends the completion webhook and push notifications of a command that
+// doesn't run through runCleanup, or the push of one that failed before any run could. They go
+// through a dispatcher of their own, so a dead endpoint holds up the exit no longer than
+// SINK_TIMEOUT; failures are logged.
+func publishRunOutcome(cfg *config.Config, command string, runs []*models.RunResult, err error, logger arr.Logger) {
+	sinks := sink.NewDispatcher(sink.DefaultWorkers, cfg.SinkQueueSize, cfg.SinkTimeout)
+	defer sinks.Close()
+
+	submitCompletions(cfg, runs, sinks, logger)
+	submitPushes(cfg, command, runs, err, sinks, logger)
+	for _, failures := range sinks.Wait() {
+		for _, failure := range failures {
+			logger.Warn("📮 %s %s was not delivered: %s", command, failure.Sink, failure.Error)
+		}
+	}
+}
+
+// submitPushes queues the push notifications of a command on sinks: one for each run, or one for
+// the command when it failed before any run could. With PUSH_FAILURES_ONLY only failures are
+// pushed. Simulated and replayed runs say nothing about the live instances and aren't pushed.
+func submitPushes(cfg *config.Config, command string, runs []*models.RunResult, err error, sinks *sink.Dispatcher, logger arr.Logger) {
+	if cfg.Simulate != "" || cfg.Replay != "" {
+		return
+	}
+	logger = arr.ForModule(logger, arr.ModuleNotify)
+	notifiers := notify.PushNotifiers(&cfg.Notify, cfg.RequestTimeout, logger)
+	if len(notifiers) == 0 {
+		return
+	}
+
+	// Failures are kept with the run the alert is about; a command-level alert has none
+	type pushedAlert struct {
+		runID string
+		alert *notify.Alert
+	}
+	var alerts []pushedAlert
+	for _, run := range runs {
+		if alert := notify.RunAlert(run, cfg.Messages); alert.Failed || !cfg.Notify.PushFailuresOnly {
+			alerts = append(alerts, pushedAlert{runID: run.RunID, alert: alert})
+		}
+	}
+	if len(runs) == 0 && err != nil && !errors.Is(err, errCompletedWithWarnings) {
+		alerts = append(alerts, pushedAlert{alert: notify.FailureAlert(command, err, cfg.Messages)})
+	}
+
+	for _, pushed := range alerts {
+		alert := pushed.alert
+		for _, notifier := range notifiers {
+			sinks.Submit("push:"+notifier.Name(), pushed.runID, func(ctx context.Context) error {
+				if err := notifier.Send(ctx, alert); err != nil {
+					logger.Warn("Failed to push %s notification via %s: %s", alert.Service, notifier.Name(), err.Error())
+					return err
+				}
+				logger.Debug("Pushed %s notification via %s", alert.Service, notifier.Name())
+				return nil
+			})
+		}
+	}
+}
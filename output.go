@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/hnipps/refresharr/internal/arr"
+	"github.com/hnipps/refresharr/internal/config"
+	"github.com/hnipps/refresharr/internal/history"
+	"github.com/hnipps/refresharr/internal/report"
+)
+
+// outputDocument is the JSON document --output json prints on stdout at the end of cleanup or
+// verify. It has what the logs and the terminal report would have shown, for scripts.
+type outputDocument struct {
+	Command    string          `json:"command"`
+	Status     string          `json:"status"` // success, warnings or failed
+	ExitCode   int             `json:"exitCode"`
+	DryRun     bool            `json:"dryRun"`
+	StartedAt  time.Time       `json:"startedAt"`
+	FinishedAt time.Time       `json:"finishedAt"`
+	DurationMs int64           `json:"durationMs"`
+	Stats      outputStats     `json:"stats"` // Totals of the services
+	Services   []outputService `json:"services"`
+	Errors     []string        `json:"errors"`
+}
+
+// outputStats are the totals of the services' runs
+type outputStats struct {
+	ItemsChecked   int `json:"itemsChecked"`
+	MissingFiles   int `json:"missingFiles"`
+	DeletedRecords int `json:"deletedRecords"`
+	Recovered      int `json:"recovered"`
+	Errors         int `json:"errors"`
+	Warnings       int `json:"warnings"`
+	APICalls       int `json:"apiCalls"`
+}
+
+// outputService is a service's run in the output document
+type outputService struct {
+	jobServiceSummary
+	Report json.RawMessage `json:"report,omitempty"` // The run's JSON report, as saved
+}
+
+// newOutputDocument builds the output document of the runs of a command started at startedAt that
+// ended with err. Reports are read back from where they were saved, so spilled reports are complete.
+func newOutputDocument(command string, cfg *config.Config, startedAt time.Time, runs []*history.Run, err error) outputDocument {
+	summary := newJobSummary(command, cfg, startedAt, runs, err)
+	doc := outputDocument{
+		Command:    summary.Command,
+		Status:     summary.Status,
+		ExitCode:   summary.ExitCode,
+		DryRun:     summary.DryRun,
+		StartedAt:  summary.StartedAt,
+		FinishedAt: summary.FinishedAt,
+		DurationMs: summary.DurationMs,
+		Services:   make([]outputService, 0, len(runs)),
+		Errors:     []string{},
+	}
+	if err != nil {
+		doc.Errors = append(doc.Errors, err.Error())
+	}
+
+	for i, run := range runs {
+		service := outputService{jobServiceSummary: summary.Services[i]}
+		doc.Stats.ItemsChecked += service.ItemsChecked
+		doc.Stats.MissingFiles += service.MissingFiles
+		doc.Stats.DeletedRecords += service.DeletedRecords
+		doc.Stats.Recovered += service.Recovered
+		doc.Stats.Errors += service.Errors
+		doc.Stats.Warnings += service.Warnings
+		doc.Stats.APICalls += service.APICalls
+
+		if run.Error != "" {
+			doc.Errors = append(doc.Errors, fmt.Sprintf("%s: %s", run.Service, run.Error))
+		}
+		for _, failure := range run.SinkFailures {
+			doc.Errors = append(doc.Errors, fmt.Sprintf("%s: %s failed: %s", run.Service, failure.Sink, failure.Error))
+		}
+		if run.ReportPath != "" {
+			data, readErr := readReport(run.ReportPath)
+			if readErr != nil {
+				doc.Errors = append(doc.Errors, fmt.Sprintf("%s: %s", run.Service, readErr.Error()))
+			} else {
+				service.Report = data
+			}
+		}
+		doc.Services = append(doc.Services, service)
+	}
+	return doc
+}
+
+// readReport reads a saved JSON report
+func readReport(path string) (json.RawMessage, error) {
+	f, err := report.OpenReport(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report: %w", err)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report: %w", err)
+	}
+	if !json.Valid(data) {
+		return nil, fmt.Errorf("report %s is not valid JSON", path)
+	}
+	return data, nil
+}
+
+// checkOutput keeps --output json to cleanup and verify, the commands that print an output
+// document. The others log as usual: OUTPUT_FORMAT from the environment is ignored for them, so
+// one .env can serve every command, while passing the flag to them is an error.
+func checkOutput(command string, cfg *config.Config, flagSet bool) error {
+	if cfg.Output != config.OutputJSON || command == "cleanup" || command == "verify" {
+		return nil
+	}
+	if flagSet {
+		return fmt.Errorf("--output json only applies to cleanup and verify, not %s", command)
+	}
+	cfg.Output = config.OutputText
+	return nil
+}
+
+// finishOutput prints the output document on stdout (w) and the exit summary line on stderr, and
+// returns the exit code. With --job, the job's rules apply too: nothing processed fails and the
+// job summary goes to the summary file.
+func finishOutput(command string, cfg *config.Config, startedAt time.Time, runs []*history.Run, err error, w, stderr io.Writer, logger arr.Logger) int {
+	if cfg.JobMode && err == nil && len(runs) == 0 {
+		err = errNothingProcessed
+	}
+	doc := newOutputDocument(command, cfg, startedAt, runs, err)
+
+	if cfg.JobMode && cfg.SummaryFile != "" {
+		data, _ := json.Marshal(newJobSummary(command, cfg, startedAt, runs, err))
+		if writeErr := writeSummaryFile(cfg.SummaryFile, data); writeErr != nil {
+			// Downstream steps rely on the file, so a missing one fails the job
+			doc.Errors = append(doc.Errors, writeErr.Error())
+			doc.Status, doc.ExitCode = "failed", exitErrors
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if encodeErr := encoder.Encode(doc); encodeErr != nil {
+		doc.Status, doc.ExitCode = "failed", exitErrors
+	}
+	printExitSummary(cfg, stderr, doc.Status, runs, doc.FinishedAt.Sub(startedAt), logger)
+	return doc.ExitCode
+}
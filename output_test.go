@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hnipps/refresharr/internal/arr"
+	"github.com/hnipps/refresharr/internal/config"
+	"github.com/hnipps/refresharr/internal/history"
+	"github.com/hnipps/refresharr/pkg/models"
+)
+
+func TestFinishOutput_PrintsExitSummaryLine(t *testing.T) {
+	cfg := &config.Config{Output: config.OutputJSON, LogTarget: "stderr"}
+	logger := arr.NewSlogLogger(slog.DiscardHandler, arr.LoggerOptions{})
+	runs := []*history.Run{{
+		ID:      "run-1",
+		Command: "cleanup",
+		Service: "radarr",
+		Success: true,
+		Stats:   models.CleanupStats{TotalItemsChecked: 12, MissingFiles: 2, DeletedRecords: 2},
+	}}
+
+	var stdout, stderr bytes.Buffer
+	code := finishOutput("cleanup", cfg, time.Now().UTC(), runs, nil, &stdout, &stderr, logger)
+	if code != exitSuccess {
+		t.Errorf("Expected exit code %d, got %d", exitSuccess, code)
+	}
+
+	// Stdout holds just the document
+	var doc outputDocument
+	if err := json.Unmarshal(stdout.Bytes(), &doc); err != nil {
+		t.Fatalf("Stdout is not one JSON document: %v\n%s", err, stdout.String())
+	}
+	if doc.Status != "success" || doc.Stats.ItemsChecked != 12 {
+		t.Errorf("Unexpected document: %+v", doc)
+	}
+
+	// Stderr holds just the exit summary line
+	line := strings.TrimSuffix(stderr.String(), "\n")
+	if !strings.HasPrefix(line, "result=success checked=12 missing=2 deleted=2 errors=0 duration=") || strings.Contains(line, "\n") {
+		t.Errorf("Unexpected stderr: %q", stderr.String())
+	}
+
+	// A run with warnings says so in the line too
+	stdout.Reset()
+	stderr.Reset()
+	code = finishOutput("cleanup", cfg, time.Now().UTC(), runs, errCompletedWithWarnings, &stdout, &stderr, logger)
+	if code != exitErrors || !strings.HasPrefix(stderr.String(), "result=warnings ") {
+		t.Errorf("Expected exit code %d and a warnings line, got %d and %q", exitErrors, code, stderr.String())
+	}
+}
+
+func TestCheckOutput(t *testing.T) {
+	tests := []struct {
+		name       string
+		command    string
+		flagSet    bool
+		wantErr    bool
+		wantOutput string
+	}{
+		{name: "cleanup keeps json", command: "cleanup", flagSet: true, wantOutput: config.OutputJSON},
+		{name: "verify keeps json", command: "verify", wantOutput: config.OutputJSON},
+		{name: "serve ignores the environment", command: "serve", wantOutput: config.OutputText},
+		{name: "serve rejects the flag", command: "serve", flagSet: true, wantErr: true},
+		{name: "fix-imports rejects the flag", command: "fix-imports", flagSet: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Output: config.OutputJSON}
+			err := checkOutput(tt.command, cfg, tt.flagSet)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkOutput() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && cfg.Output != tt.wantOutput {
+				t.Errorf("Expected output %q, got %q", tt.wantOutput, cfg.Output)
+			}
+		})
+	}
+}
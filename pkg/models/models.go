@@ -1,9 +1,13 @@
 package models
 
 import (
+	"encoding/json"
 	"fmt"
+	"path/filepath"
 	"regexp"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // MediaItem represents a base media item (can be extended for TV shows or movies)
@@ -11,6 +15,13 @@ type MediaItem struct {
 	ID    int    `json:"id"`
 	Title string `json:"title"`
 	Path  string `json:"path,omitempty"`
+	Tags  []int  `json:"tags,omitempty"` // IDs of the item's tags, see Tag
+}
+
+// Tag is a label Sonarr and Radarr attach to series and movies
+type Tag struct {
+	ID    int    `json:"id"`
+	Label string `json:"label"`
 }
 
 // Series represents a TV series in Sonarr
@@ -22,6 +33,8 @@ type Series struct {
 	Monitored        bool   `json:"monitored"`
 	QualityProfileID int    `json:"qualityProfileId,omitempty"`
 	RootFolderPath   string `json:"rootFolderPath,omitempty"`
+	// Episode files Sonarr has for the series, from its statistics (nil when not reported)
+	EpisodeFileCount *int `json:"episodeFileCount,omitempty"`
 }
 
 // Movie represents a movie in Radarr
@@ -32,9 +45,30 @@ type Movie struct {
 	MovieFileID *int `json:"movieFileId,omitempty"`
 	// Extended fields for TMDB and monitoring
 	TMDBID           int    `json:"tmdbId,omitempty"`
+	IMDBID           string `json:"imdbId,omitempty"`
 	Monitored        bool   `json:"monitored"`
 	QualityProfileID int    `json:"qualityProfileId,omitempty"`
 	RootFolderPath   string `json:"rootFolderPath,omitempty"`
+	// Options only sent when adding the movie (nil leaves Radarr's defaults)
+	AddOptions *MovieAddOptions `json:"addOptions,omitempty"`
+}
+
+// MovieAddOptions are Radarr's options for adding a movie
+type MovieAddOptions struct {
+	SearchForMovie bool   `json:"searchForMovie"`
+	Monitor        string `json:"monitor,omitempty"` // One of the Monitor constants
+}
+
+// What Radarr monitors when adding a movie, see MovieAddOptions.Monitor
+const (
+	MonitorMovieOnly          = "movieOnly"
+	MonitorMovieAndCollection = "movieAndCollection" // Also monitor the movie's collection, so Radarr adds the rest of it
+)
+
+// MovieCollection is the collection (franchise) a movie belongs to
+type MovieCollection struct {
+	TMDBID int    `json:"tmdbId"`
+	Title  string `json:"title,omitempty"`
 }
 
 // Episode represents a TV episode
@@ -46,26 +80,92 @@ type Episode struct {
 	Title         string `json:"title"`
 	HasFile       bool   `json:"hasFile"`
 	EpisodeFileID *int   `json:"episodeFileId,omitempty"`
+	// Absolute number of anime episodes, counted across seasons (0 when the series has none)
+	AbsoluteEpisodeNumber int `json:"absoluteEpisodeNumber,omitempty"`
 }
 
 // EpisodeFile represents a file associated with an episode
 type EpisodeFile struct {
-	ID   int    `json:"id"`
-	Path string `json:"path"`
+	ID            int            `json:"id"`
+	Path          string         `json:"path"`
+	Size          int64          `json:"size,omitempty"` // Size recorded by Sonarr in bytes
+	Quality       *FileQuality   `json:"quality,omitempty"`
+	ReleaseGroup  string         `json:"releaseGroup,omitempty"`
+	CustomFormats []CustomFormat `json:"customFormats,omitempty"` // Custom formats the file matched (Sonarr v4)
 }
 
 // MovieFile represents a file associated with a movie (for future Radarr support)
 type MovieFile struct {
-	ID      int    `json:"id"`
-	Path    string `json:"path"`
-	MovieID int    `json:"movieId"`
+	ID            int            `json:"id"`
+	Path          string         `json:"path"`
+	MovieID       int            `json:"movieId"`
+	Size          int64          `json:"size,omitempty"` // Size recorded by Radarr in bytes
+	Quality       *FileQuality   `json:"quality,omitempty"`
+	ReleaseGroup  string         `json:"releaseGroup,omitempty"`
+	CustomFormats []CustomFormat `json:"customFormats,omitempty"` // Custom formats the file matched
+}
+
+// Book represents a book in Readarr
+type Book struct {
+	MediaItem
+	AuthorName    string `json:"authorName,omitempty"`
+	ForeignBookID string `json:"foreignBookId,omitempty"` // Goodreads ID of the book with Readarr's default metadata source
+	Monitored     bool   `json:"monitored"`
+	BookFileCount *int   `json:"bookFileCount,omitempty"` // Number of book files, nil when Readarr didn't report statistics
+}
+
+// GoodreadsID returns the book's Goodreads ID, or 0 when its foreign ID isn't one
+func (b Book) GoodreadsID() int {
+	id, err := strconv.Atoi(b.ForeignBookID)
+	if err != nil {
+		return 0
+	}
+	return id
+}
+
+// BookFile represents a file of a book in Readarr. Audiobooks can have a file per part.
+type BookFile struct {
+	ID      int          `json:"id"`
+	Path    string       `json:"path"`
+	BookID  int          `json:"bookId"`
+	Size    int64        `json:"size,omitempty"` // Size recorded by Readarr in bytes
+	Quality *FileQuality `json:"quality,omitempty"`
+}
+
+// FileQuality is the quality recorded for an episode or movie file, shaped like the *arr APIs'
+type FileQuality struct {
+	Quality Quality `json:"quality"`
+}
+
+// Name returns the name of the quality, or "" when none was recorded
+func (q *FileQuality) Name() string {
+	if q == nil {
+		return ""
+	}
+	return q.Quality.Name
+}
+
+// CustomFormat is a custom format a file matched, shaped like the *arr APIs'
+type CustomFormat struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// CustomFormatNames returns the names of the custom formats, or nil when there are none
+func CustomFormatNames(formats []CustomFormat) []string {
+	var names []string
+	for _, format := range formats {
+		names = append(names, format.Name)
+	}
+	return names
 }
 
 // RootFolder represents a Radarr root folder configuration
 type RootFolder struct {
-	ID   int    `json:"id"`
-	Path string `json:"path"`
-	Name string `json:"name,omitempty"`
+	ID         int    `json:"id"`
+	Path       string `json:"path"`
+	Name       string `json:"name,omitempty"`
+	Accessible *bool  `json:"accessible,omitempty"` // Whether the service can reach the folder (nil when unknown)
 }
 
 // QualityProfile represents a Radarr quality profile
@@ -82,11 +182,13 @@ type Quality struct {
 
 // MovieLookup represents a movie lookup result from TMDB
 type MovieLookup struct {
-	TMDBID   int    `json:"tmdbId"`
-	Title    string `json:"title"`
-	Year     int    `json:"year"`
-	Overview string `json:"overview,omitempty"`
-	Images   []struct {
+	TMDBID     int              `json:"tmdbId"`
+	IMDBID     string           `json:"imdbId,omitempty"`
+	Title      string           `json:"title"`
+	Year       int              `json:"year"`
+	Overview   string           `json:"overview,omitempty"`
+	Collection *MovieCollection `json:"collection,omitempty"` // nil when the movie isn't part of a collection
+	Images     []struct {
 		CoverType string `json:"coverType"`
 		URL       string `json:"url"`
 	} `json:"images,omitempty"`
@@ -109,31 +211,425 @@ type CleanupStats struct {
 	TotalItemsChecked int
 	MissingFiles      int
 	DeletedRecords    int
-	Errors            int
+	Errors            int // Hard failures, e.g. a file record that couldn't be deleted
+	Warnings          int // Items skipped or hit by transient errors, which a later run may handle
+	Skipped           int // Items left alone because they were not in the --only-from scope
+	SizeMismatches    int // Files whose size on disk differs from the recorded size (verify only)
+	Corrupt           int // Files whose checksum differs from the checksum manifest (verify only)
+	EmptyPaths        int // File records without a path, deleted under the delete empty path policy and otherwise skipped as warnings
+	OutsideRoot       int // File records whose path is under none of the service's root folders
+
+	Duration time.Duration // Wall-clock time of the run
+	Phases   PhaseTimings  // Time spent in each phase of the run
+	APICalls int           // Requests sent to the *arr API
+
+	// Resource usage of the run, for capacity planning
+	PeakMemoryBytes uint64 // Most memory the process held, sampled
+	PeakGoroutines  int    // Most goroutines running at once, sampled
+	FileOps         int64  // stat, open and readdir calls made on the filesystem
+}
+
+// PhaseTimings records the time spent in each phase of a run. Times are summed across
+// concurrent workers, so together they can exceed the run's wall-clock duration.
+type PhaseTimings struct {
+	Fetch        time.Duration // Fetching series, episodes, movies and file records
+	SymlinkScan  time.Duration // Scanning for and handling broken symlinks
+	Verification time.Duration // Checking files on disk
+	Deletion     time.Duration // Deleting file records
+	Refresh      time.Duration // Triggering the missing search
+}
+
+// RunTiming is the timing section of a report, in milliseconds so runs of different versions
+// can be compared
+type RunTiming struct {
+	DurationMs     int64 `json:"durationMs"`
+	FetchMs        int64 `json:"fetchMs"`
+	SymlinkScanMs  int64 `json:"symlinkScanMs"`
+	VerificationMs int64 `json:"verificationMs"`
+	DeletionMs     int64 `json:"deletionMs"`
+	RefreshMs      int64 `json:"refreshMs"`
+	APICalls       int   `json:"apiCalls"`
+}
+
+// Timing returns the report timing section for the stats
+func (s CleanupStats) Timing() *RunTiming {
+	return &RunTiming{
+		DurationMs:     s.Duration.Milliseconds(),
+		FetchMs:        s.Phases.Fetch.Milliseconds(),
+		SymlinkScanMs:  s.Phases.SymlinkScan.Milliseconds(),
+		VerificationMs: s.Phases.Verification.Milliseconds(),
+		DeletionMs:     s.Phases.Deletion.Milliseconds(),
+		RefreshMs:      s.Phases.Refresh.Milliseconds(),
+		APICalls:       s.APICalls,
+	}
+}
+
+// ResourceUsage is the resource section of a report
+type ResourceUsage struct {
+	PeakMemoryBytes uint64 `json:"peakMemoryBytes"`
+	PeakGoroutines  int    `json:"peakGoroutines"`
+	APICalls        int    `json:"apiCalls"`
+	FileOps         int64  `json:"fileOps"`
+}
+
+// Resources returns the report resource section for the stats
+func (s CleanupStats) Resources() *ResourceUsage {
+	return &ResourceUsage{
+		PeakMemoryBytes: s.PeakMemoryBytes,
+		PeakGoroutines:  s.PeakGoroutines,
+		APICalls:        s.APICalls,
+		FileOps:         s.FileOps,
+	}
 }
 
 // MissingFileEntry represents a single missing file entry in the report
 type MissingFileEntry struct {
-	MediaType         string `json:"mediaType"`                   // "movie" or "series"
-	MediaName         string `json:"mediaName"`                   // Movie title or series title
-	EpisodeName       string `json:"episodeName,omitempty"`       // Episode name (only for series)
-	Season            *int   `json:"season,omitempty"`            // Season number (only for series)
-	Episode           *int   `json:"episode,omitempty"`           // Episode number (only for series)
-	FilePath          string `json:"filePath"`                    // Path to the missing file
-	FileID            int    `json:"fileId"`                      // File ID in the database
-	ProcessedAt       string `json:"processedAt"`                 // Timestamp when processed
-	AddedToCollection bool   `json:"addedToCollection,omitempty"` // Whether the movie/series was added to the collection
-	TMDBID            int    `json:"tmdbId,omitempty"`            // TMDB ID for movies
-	TVDBID            int    `json:"tvdbId,omitempty"`            // TVDB ID for series
+	MediaType         string   `json:"mediaType"`                   // "movie", "series" or "book"
+	MediaName         string   `json:"mediaName"`                   // Movie, series or book title
+	EpisodeName       string   `json:"episodeName,omitempty"`       // Episode name (only for series)
+	Season            *int     `json:"season,omitempty"`            // Season number (only for series)
+	Episode           *int     `json:"episode,omitempty"`           // Episode number (only for series)
+	FilePath          string   `json:"filePath"`                    // Path to the missing file
+	FilePaths         []string `json:"filePaths,omitempty"`         // Every broken symlink of the movie/series, when it had several (FilePath is the first)
+	FileID            int      `json:"fileId"`                      // File ID in the database
+	ProcessedAt       string   `json:"processedAt"`                 // Timestamp when processed
+	AddedToCollection bool     `json:"addedToCollection,omitempty"` // Whether the movie/series was added to the collection
+	TMDBID            int      `json:"tmdbId,omitempty"`            // TMDB ID for movies
+	TVDBID            int      `json:"tvdbId,omitempty"`            // TVDB ID for series
+	GoodreadsID       int      `json:"goodreadsId,omitempty"`       // Goodreads ID for books
+	Issue             string   `json:"issue,omitempty"`             // IssueSizeMismatch for files that exist but don't match; empty for missing files
+	ExpectedSize      int64    `json:"expectedSize,omitempty"`      // Size recorded by the service (size mismatches only)
+	ActualSize        int64    `json:"actualSize,omitempty"`        // Size on disk (size mismatches only)
+	ExpectedSHA256    string   `json:"expectedSha256,omitempty"`    // Checksum listed in the checksum manifest (checksum mismatches only)
+	ActualSHA256      string   `json:"actualSha256,omitempty"`      // Checksum of the file on disk (checksum mismatches only)
+	Reason            string   `json:"reason,omitempty"`            // Why the file is missing or damaged, one of the Reason constants
+	MatchConfidence   float64  `json:"matchConfidence,omitempty"`   // Confidence (0-1) of the title lookup that identified the item, when its path had no ID
+	AddCheck          string   `json:"addCheck,omitempty"`          // AddCheckOK, or why adding the movie/series would fail (dry runs with add validation only)
+
+	// What the service recorded about the file, so a replacement of the same quality can be found
+	Quality       string   `json:"quality,omitempty"`       // Quality name, e.g. "Bluray-1080p"
+	ReleaseGroup  string   `json:"releaseGroup,omitempty"`  // Release group of the file
+	CustomFormats []string `json:"customFormats,omitempty"` // Names of the custom formats the file matched
+	Size          int64    `json:"size,omitempty"`          // Size in bytes
+}
+
+// RecoveryKey identifies the episode or movie of an entry across runs, whatever its file is
+// called now, so a later run can tell it has a valid file again. It is empty for entries that
+// can't be traced, such as broken symlinks of unknown episodes.
+func (e MissingFileEntry) RecoveryKey() string {
+	switch {
+	case e.MediaType == "movie" && e.TMDBID > 0:
+		return fmt.Sprintf("movie-tmdb-%d", e.TMDBID)
+	case e.MediaType == "movie" && e.MediaName != "":
+		return "movie-name-" + e.MediaName
+	case e.MediaType == "series" && e.MediaName != "" && e.Season != nil && e.Episode != nil:
+		return fmt.Sprintf("series-%s-S%02dE%02d", e.MediaName, *e.Season, *e.Episode)
+	case e.MediaType == "book" && e.GoodreadsID > 0:
+		return fmt.Sprintf("book-goodreads-%d", e.GoodreadsID)
+	case e.MediaType == "book" && e.MediaName != "":
+		return "book-name-" + e.MediaName
+	}
+	return ""
+}
+
+// SinkFailure is a report or notification of a run that couldn't be delivered
+type SinkFailure struct {
+	Sink  string `json:"sink"` // e.g. "report:file" or "notify:webhook"
+	Error string `json:"error"`
+}
+
+// RecoveredFileEntry is an episode or movie an earlier run found missing that has a valid file again
+type RecoveredFileEntry struct {
+	MediaType    string `json:"mediaType"` // "movie" or "series"
+	MediaName    string `json:"mediaName"`
+	EpisodeName  string `json:"episodeName,omitempty"`
+	Season       *int   `json:"season,omitempty"`
+	Episode      *int   `json:"episode,omitempty"`
+	TMDBID       int    `json:"tmdbId,omitempty"`
+	FilePath     string `json:"filePath"`     // The valid file it has now
+	MissingPath  string `json:"missingPath"`  // The file that was missing
+	MissingSince string `json:"missingSince"` // When a run first found it missing
+	RecoveredAt  string `json:"recoveredAt"`
 }
 
+// TimeToRecovery returns how long the file was missing, from the run that first found it missing
+// to the run that found it valid again, and false when either time is unknown
+func (e RecoveredFileEntry) TimeToRecovery() (time.Duration, bool) {
+	missingSince, err := time.Parse(time.RFC3339, e.MissingSince)
+	if err != nil {
+		return 0, false
+	}
+	recoveredAt, err := time.Parse(time.RFC3339, e.RecoveredAt)
+	if err != nil || recoveredAt.Before(missingSince) {
+		return 0, false
+	}
+	return recoveredAt.Sub(missingSince), true
+}
+
+// MeanTimeToRecovery returns the average time the recovered files were missing and how many
+// of them it is based on, leaving out entries without known times
+func MeanTimeToRecovery(entries []RecoveredFileEntry) (time.Duration, int) {
+	var total time.Duration
+	count := 0
+	for _, entry := range entries {
+		if d, ok := entry.TimeToRecovery(); ok {
+			total += d
+			count++
+		}
+	}
+	if count == 0 {
+		return 0, 0
+	}
+	return total / time.Duration(count), count
+}
+
+// Issues of report entries whose file exists, see MissingFileEntry.Issue
+const (
+	IssueSizeMismatch     = "size-mismatch"     // The file differs in size from the recorded size
+	IssueChecksumMismatch = "checksum-mismatch" // The file's checksum differs from the checksum manifest
+)
+
+// Reasons a report entry's file is missing or damaged, see MissingFileEntry.Reason
+const (
+	ReasonNotFound         = "not-found"         // Nothing at the path, though the folder around it is there
+	ReasonBrokenSymlink    = "broken-symlink"    // A symlink whose target is missing
+	ReasonZeroByte         = "zero-byte"         // The file is there but empty
+	ReasonSizeMismatch     = "size-mismatch"     // The file is there but its size differs from the recorded size
+	ReasonPermissionDenied = "permission-denied" // The file or a folder above it can't be accessed
+	ReasonMountUnavailable = "mount-unavailable" // The storage the file lives on looks unmounted
+	ReasonEmptyPath        = "empty-path"        // The file record has no path, usually a corrupt database row
+	ReasonOutsideRoot      = "outside-root"      // The path is under none of the root folders, deleted under the delete outside root policy
+	ReasonCorrupt          = "corrupt"           // The file is there but its contents don't match the checksum manifest
+)
+
 // MissingFilesReport represents a complete missing files report
 type MissingFilesReport struct {
-	GeneratedAt  string             `json:"generatedAt"`
-	RunType      string             `json:"runType"`     // "dry-run" or "real-run"
-	ServiceType  string             `json:"serviceType"` // "sonarr" or "radarr"
-	TotalMissing int                `json:"totalMissing"`
-	MissingFiles []MissingFileEntry `json:"missingFiles"`
+	GeneratedAt   string             `json:"generatedAt"`
+	RunType       string             `json:"runType"`            // "dry-run", "real-run" or "verify"
+	ServiceType   string             `json:"serviceType"`        // "sonarr", "radarr" or "readarr"
+	Instance      string             `json:"instance,omitempty"` // Name of an extra instance of the service (empty for the default one)
+	TotalMissing  int                `json:"totalMissing"`
+	MissingFiles  []MissingFileEntry `json:"missingFiles"`
+	SearchSkipped string             `json:"searchSkipped,omitempty"` // Why the missing search was not triggered
+	Timing        *RunTiming         `json:"timing,omitempty"`        // Run duration, phase timings and API calls
+	Resources     *ResourceUsage     `json:"resources,omitempty"`     // Peak memory and goroutines, API calls and filesystem calls
+	APIBudget     int                `json:"apiBudget,omitempty"`     // API calls allowed for the run (0 means unlimited)
+	OverBudget    bool               `json:"overBudget,omitempty"`    // The budget ran out; later changes were only reported
+	OutsideWindow bool               `json:"outsideWindow,omitempty"` // Changes came up outside the maintenance windows and were deferred
+	NextWindow    string             `json:"nextWindow,omitempty"`    // When the next maintenance window opens (RFC 3339), for deferred changes
+	MostAffected  []AffectedItem     `json:"mostAffected,omitempty"`  // Series and movies with the most missing files, most first
+
+	// Recovered lists the files earlier runs found missing that have a valid file again, and
+	// MeanTimeToRecoveryMs how long they were missing on average
+	Recovered            []RecoveredFileEntry `json:"recovered,omitempty"`
+	MeanTimeToRecoveryMs int64                `json:"meanTimeToRecoveryMs,omitempty"`
+
+	// OutsideRootFolders lists the file records whose path is under none of the service's root
+	// folders, usually left behind by a library migration, and what the run did about them
+	OutsideRootFolders []OutsideRootEntry `json:"outsideRootFolders,omitempty"`
+
+	// Spilled holds the entries instead of MissingFiles when there were too many to keep in memory
+	Spilled MissingFileSource `json:"-"`
+}
+
+// OutsideRootEntry is a file record whose path is under none of the service's root folders
+type OutsideRootEntry struct {
+	MediaType   string `json:"mediaType"` // "movie" or "series"
+	MediaName   string `json:"mediaName"`
+	EpisodeName string `json:"episodeName,omitempty"`
+	Season      *int   `json:"season,omitempty"`
+	Episode     *int   `json:"episode,omitempty"`
+	FilePath    string `json:"filePath"`
+	FileID      int    `json:"fileId"`
+	Policy      string `json:"policy"`               // The outside root folder policy applied: skip, delete or rewrite
+	MappedPath  string `json:"mappedPath,omitempty"` // Where the path mapping rules put the file (rewrite policy only)
+	Outcome     string `json:"outcome"`              // What happened to the record, one of the Outside* constants
+}
+
+// Outcomes of a file record outside the root folders, see OutsideRootEntry.Outcome
+const (
+	OutsideReported  = "reported"  // Left alone
+	OutsideDeleted   = "deleted"   // Handled like a missing file: deleted, or planned for deletion in a dry run
+	OutsideRewritten = "rewritten" // The series/movie path was changed to the mapped location
+	OutsidePlanned   = "planned"   // A dry run would have changed the series/movie path
+	OutsideUnmapped  = "unmapped"  // No mapping rule matched, or nothing exists at the mapped path
+	OutsideFailed    = "failed"    // Changing the series/movie path failed
+)
+
+// AffectedItem is a series or movie and how many of its files a report lists
+type AffectedItem struct {
+	MediaType string `json:"mediaType"` // "movie" or "series"
+	MediaName string `json:"mediaName"`
+	Missing   int    `json:"missing"`
+}
+
+// MissingFileSource streams missing file entries kept outside memory
+type MissingFileSource interface {
+	// Each calls fn for every entry in order, stopping at the first error
+	Each(fn func(MissingFileEntry) error) error
+	// Close releases the storage behind the entries
+	Close() error
+}
+
+// ServiceLabel names an instance of a service in logs, reports and history: the service alone
+// for its default instance, e.g. "radarr", or with the instance name, e.g. "radarr-4k"
+func ServiceLabel(service, instance string) string {
+	if instance == "" {
+		return service
+	}
+	return service + "-" + instance
+}
+
+// Label names the service instance the report is for, see ServiceLabel
+func (r *MissingFilesReport) Label() string {
+	return ServiceLabel(r.ServiceType, r.Instance)
+}
+
+// EachMissingFile calls fn for every missing file in the report, wherever the entries are kept
+func (r *MissingFilesReport) EachMissingFile(fn func(MissingFileEntry) error) error {
+	if r.Spilled != nil {
+		return r.Spilled.Each(fn)
+	}
+	for _, entry := range r.MissingFiles {
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close releases spilled entries. The report's missing files can't be read afterwards.
+func (r *MissingFilesReport) Close() error {
+	if r.Spilled == nil {
+		return nil
+	}
+	err := r.Spilled.Close()
+	r.Spilled = nil
+	return err
+}
+
+// Planned action types recorded during dry runs
+const (
+	ActionDeleteEpisodeFile  = "delete-episode-file"
+	ActionDeleteMovieFile    = "delete-movie-file"
+	ActionDeleteBookFile     = "delete-book-file"
+	ActionDeleteSymlink      = "delete-symlink"
+	ActionAddMovie           = "add-movie"
+	ActionAddSeries          = "add-series"
+	ActionImportQueueItem    = "import-queue-item"
+	ActionUpdatePath         = "update-path"          // Point a series or movie at a new folder without moving files
+	ActionDeleteRejectedFile = "delete-rejected-file" // Delete a download Sonarr rejects as a sample or for its extension
+)
+
+// PlannedAction represents a single change a dry run would have made
+type PlannedAction struct {
+	Action      string   `json:"action"`                // One of the Action* constants
+	MediaType   string   `json:"mediaType,omitempty"`   // "movie", "series" or "book"
+	MediaName   string   `json:"mediaName,omitempty"`   // Movie, series or book title
+	SeriesID    int      `json:"seriesId,omitempty"`    // Series ID (only for series)
+	EpisodeID   int      `json:"episodeId,omitempty"`   // Episode ID (only for episode files)
+	MovieID     int      `json:"movieId,omitempty"`     // Movie ID (only for movies)
+	BookID      int      `json:"bookId,omitempty"`      // Book ID (only for books)
+	FileID      int      `json:"fileId,omitempty"`      // Episode, movie or book file ID
+	QueueItemID int      `json:"queueItemId,omitempty"` // Download queue item ID
+	Path        string   `json:"path,omitempty"`        // File, symlink or download path affected; the new folder for path updates
+	FromPath    string   `json:"fromPath,omitempty"`    // Folder a path update moves the series or movie away from
+	TMDBID      int      `json:"tmdbId,omitempty"`      // TMDB ID for movies
+	TVDBID      int      `json:"tvdbId,omitempty"`      // TVDB ID for series
+	GoodreadsID int      `json:"goodreadsId,omitempty"` // Goodreads ID for books
+	AddCheck    string   `json:"addCheck,omitempty"`    // AddCheckOK, or why an add would fail (dry runs with add validation only)
+	APICall     *APICall `json:"apiCall,omitempty"`     // Request a real run would send (nil when the change isn't a single request)
+}
+
+// APICall is a request to a service's API
+type APICall struct {
+	Method   string          `json:"method"`
+	Endpoint string          `json:"endpoint"` // Path and query, relative to the service's URL
+	Body     json.RawMessage `json:"body,omitempty"`
+}
+
+// String formats the call like "POST /api/v3/movie {...}"
+func (c APICall) String() string {
+	if len(c.Body) == 0 {
+		return c.Method + " " + c.Endpoint
+	}
+	return c.Method + " " + c.Endpoint + " " + string(c.Body)
+}
+
+// AddCheckOK marks an add that dry-run validation found would succeed, see PlannedAction.AddCheck
+const AddCheckOK = "ok"
+
+// ActionsFile represents the machine-readable list of actions from a dry run
+type ActionsFile struct {
+	GeneratedAt  string          `json:"generatedAt"`
+	Command      string          `json:"command"`            // "cleanup" or "fix-imports"
+	ServiceType  string          `json:"serviceType"`        // "sonarr", "radarr" or "readarr"
+	Instance     string          `json:"instance,omitempty"` // Name of an extra instance of the service (empty for the default one)
+	TotalActions int             `json:"totalActions"`
+	Actions      []PlannedAction `json:"actions"`
+}
+
+// PathMigrationReport is the result of the migrate-paths command for one service
+type PathMigrationReport struct {
+	GeneratedAt string               `json:"generatedAt"`
+	RunType     string               `json:"runType"`            // "dry-run" or "real-run"
+	ServiceType string               `json:"serviceType"`        // "sonarr" or "radarr"
+	Instance    string               `json:"instance,omitempty"` // Name of an extra instance of the service (empty for the default one)
+	Mappings    []PathMapping        `json:"mappings"`
+	Checked     int                  `json:"checked"` // Series or movies looked at
+	Items       []PathMigrationEntry `json:"items"`   // Series or movies a mapping matched
+}
+
+// PathMigrationEntry is a series or movie whose folder a path mapping matched
+type PathMigrationEntry struct {
+	MediaType string `json:"mediaType"` // "movie" or "series"
+	MediaName string `json:"mediaName"`
+	ID        int    `json:"id"`
+	FromPath  string `json:"fromPath"`
+	ToPath    string `json:"toPath"`
+	Outcome   string `json:"outcome"`        // One of the Migration* constants
+	Note      string `json:"note,omitempty"` // Why the item was skipped or failed, or a warning about the new folder
+}
+
+// Outcomes of a path migration entry, see PathMigrationEntry.Outcome
+const (
+	MigrationMoved         = "moved"          // The folder was updated
+	MigrationPlanned       = "planned"        // A dry run would have updated the folder
+	MigrationTargetMissing = "target-missing" // Skipped: nothing exists at the new folder
+	MigrationFailed        = "failed"         // Updating the folder failed
+)
+
+// Count returns how many entries have the outcome
+func (r *PathMigrationReport) Count(outcome string) int {
+	count := 0
+	for _, item := range r.Items {
+		if item.Outcome == outcome {
+			count++
+		}
+	}
+	return count
+}
+
+// LibrarySnapshot is a point-in-time export of the file records of one or more services
+type LibrarySnapshot struct {
+	GeneratedAt string         `json:"generatedAt"`
+	Services    []string       `json:"services"`
+	TotalFiles  int            `json:"totalFiles"`
+	Files       []SnapshotFile `json:"files"`
+}
+
+// SnapshotFile is an episode file or movie file record in a library snapshot
+type SnapshotFile struct {
+	Service   string `json:"service"`
+	MediaType string `json:"mediaType"` // "episode", "movie" or "book"
+	MediaID   int    `json:"mediaId"`   // ID of the series, movie or book
+	MediaName string `json:"mediaName"`
+	Season    int    `json:"season,omitempty"`
+	Episodes  []int  `json:"episodes,omitempty"` // Numbers of the episodes in the file
+	FileID    int    `json:"fileId"`
+	Path      string `json:"path"`
+	Size      int64  `json:"size"`
+	Quality   string `json:"quality,omitempty"`
 }
 
 // CleanupResult represents the result of a cleanup operation
@@ -141,26 +637,36 @@ type CleanupResult struct {
 	Stats    CleanupStats
 	Messages []string
 	Success  bool
-	Report   *MissingFilesReport `json:"report,omitempty"` // Optional report data
+	Report   *MissingFilesReport `json:"report,omitempty"`  // Optional report data
+	Actions  []PlannedAction     `json:"actions,omitempty"` // Actions that would have been taken (dry run only)
 }
 
-// ParseTMDBIDFromPath extracts TMDB ID from a file path
-// Expected format: ...path.../Movie Title (Year) [tmdb-12345]/...
-func ParseTMDBIDFromPath(filePath string) (int, error) {
-	// Use regex to find tmdb-### pattern
-	re := regexp.MustCompile(`\[tmdb-(\d+)\]`)
-	matches := re.FindStringSubmatch(filePath)
+// Severity is how well a run went, see CleanupResult.Severity
+type Severity string
 
-	if len(matches) < 2 {
-		return 0, fmt.Errorf("TMDB ID not found in path: %s", filePath)
-	}
+const (
+	SeverityOK      Severity = "ok"      // Everything was handled
+	SeverityWarning Severity = "warning" // Partial success: some items were skipped or hit transient errors
+	SeverityError   Severity = "error"   // Something failed that a later run won't fix by itself
+)
 
-	tmdbID, err := strconv.Atoi(matches[1])
-	if err != nil {
-		return 0, fmt.Errorf("invalid TMDB ID format in path %s: %w", filePath, err)
+// Severity tells a run that handled everything from one with warnings, which a later run may
+// clear up, and one with hard failures
+func (r *CleanupResult) Severity() Severity {
+	switch {
+	case !r.Success || r.Stats.Errors > 0:
+		return SeverityError
+	case r.Stats.Warnings > 0:
+		return SeverityWarning
 	}
+	return SeverityOK
+}
 
-	return tmdbID, nil
+// ParseTMDBIDFromPath extracts TMDB ID from a file path
+// Expected format: ...path.../Movie Title (Year) [tmdb-12345]/...
+// Other naming schemes need a PathParser with configured patterns.
+func ParseTMDBIDFromPath(filePath string) (int, error) {
+	return defaultPathParser.TMDBID(filePath)
 }
 
 // QueueItem represents an item in the download queue
@@ -177,6 +683,47 @@ type QueueItem struct {
 	OutputPath     string `json:"outputPath,omitempty"`
 	Protocol       string `json:"protocol,omitempty"`
 	DownloadClient string `json:"downloadClient,omitempty"`
+	EpisodeID      int    `json:"episodeId,omitempty"`
+	EpisodeHasFile *bool  `json:"episodeHasFile,omitempty"` // Sonarr v4 only; nil when the server doesn't say
+	// Download progress and origin
+	SizeLeft             int64      `json:"sizeleft,omitempty"`
+	TimeLeft             string     `json:"timeleft,omitempty"` // Estimated time remaining, e.g. "00:12:30"
+	Added                *time.Time `json:"added,omitempty"`
+	Indexer              string     `json:"indexer,omitempty"`
+	TrackedDownloadState string     `json:"trackedDownloadState,omitempty"` // e.g. downloading, importPending, importing
+}
+
+// Progress returns how much of the download has finished, from 0 to 1. Items with an unknown
+// size report 0.
+func (q QueueItem) Progress() float64 {
+	if q.Size <= 0 {
+		return 0
+	}
+	return float64(q.Size-q.SizeLeft) / float64(q.Size)
+}
+
+// QueueOptions narrows and orders a queue fetch on the server, so callers interested in a few
+// items don't page through the whole queue
+type QueueOptions struct {
+	Statuses                  []string // Only items with one of these statuses, e.g. "completed" (empty means all)
+	IncludeUnknownSeriesItems bool     // Include downloads that aren't matched to a series
+	IncludeSeries             bool     // Include each item's series, so titles don't need separate lookups
+	SortKey                   string   // Field to sort by, e.g. "added" (empty means "timeleft")
+	SortDirection             string   // "ascending" or "descending" (empty means the server's default)
+}
+
+// Matches reports whether an item passes the status filter. Servers that don't filter by status
+// return every item, so results are checked again after fetching.
+func (o QueueOptions) Matches(item QueueItem) bool {
+	if len(o.Statuses) == 0 {
+		return true
+	}
+	for _, status := range o.Statuses {
+		if strings.EqualFold(status, item.Status) {
+			return true
+		}
+	}
+	return false
 }
 
 // StatusMessage represents a status message in the queue
@@ -194,9 +741,12 @@ type QueueResponse struct {
 type ImportFixResult struct {
 	TotalStuckItems int
 	FixedItems      int
+	RequeuedItems   int // Items blocklisted and searched for again after failing to import
+	CoolingDown     int // Stuck items skipped because their import failed within the cooldown
 	Errors          []string
 	Success         bool
 	DryRun          bool
+	Actions         []PlannedAction // Queue items that would have been imported (dry run only)
 }
 
 // ManualImportItem represents a file available for manual import
@@ -214,24 +764,104 @@ type ManualImportItem struct {
 	QualityWeight int       `json:"qualityWeight,omitempty"`
 	DownloadID    string    `json:"downloadId,omitempty"`
 	Rejections    []string  `json:"rejections,omitempty"`
+	ReleaseType   string    `json:"releaseType,omitempty"` // Sonarr v4 only: singleEpisode, multiEpisode, seasonPack or unknown
 }
 
+// ParseTVDBIDFromPath extracts TVDB ID from a file path
 // Expected format: ...path.../Series Title (Year) [tvdb-12345]/...
+// Other naming schemes need a PathParser with configured patterns.
 func ParseTVDBIDFromPath(filePath string) (int, error) {
-	// Use regex to find tvdb-### pattern
-	re := regexp.MustCompile(`\[tvdb-(\d+)\]`)
-	matches := re.FindStringSubmatch(filePath)
+	return defaultPathParser.TVDBID(filePath)
+}
+
+// EpisodeRef identifies the episode(s) a file name refers to, either by season and episode
+// numbers or, for anime, by absolute episode numbers
+type EpisodeRef struct {
+	Season   int   // Season number; only meaningful when Episodes is set
+	Episodes []int // Episode numbers within the season
+	Absolute []int // Absolute episode numbers, for anime named without a season
+}
 
-	if len(matches) < 2 {
-		return 0, fmt.Errorf("TVDB ID not found in path: %s", filePath)
+// Matches reports whether the episode is one the reference points at
+func (r EpisodeRef) Matches(episode Episode) bool {
+	if len(r.Episodes) > 0 {
+		return episode.SeasonNumber == r.Season && containsInt(r.Episodes, episode.EpisodeNumber)
 	}
+	return episode.AbsoluteEpisodeNumber > 0 && containsInt(r.Absolute, episode.AbsoluteEpisodeNumber)
+}
 
-	tvdbID, err := strconv.Atoi(matches[1])
-	if err != nil {
-		return 0, fmt.Errorf("invalid TVDB ID format in path %s: %w", filePath, err)
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
 	}
+	return false
+}
+
+var (
+	// S01E02, S01E02E03, S01E02-E03, S01E02-03
+	seasonEpisodePattern = regexp.MustCompile(`(?i)\bS(\d{1,4})[ ._]?E(\d{1,4})((?:[ ._-]?E\d{1,4}|-\d{1,4})*)`)
+	// 1x02, 1x02-03
+	crossEpisodePattern = regexp.MustCompile(`(?i)\b(\d{1,2})x(\d{2,3})(?:-(\d{2,3}))?\b`)
+	// Release groups, resolutions, CRCs and years in brackets, which look like episode numbers
+	bracketedPattern = regexp.MustCompile(`\[[^\]]*\]|\([^)]*\)|\{[^}]*\}`)
+	// Anime: "Show - 012", "Show - 012v2", "Show - 012-013", "Show E012", "Show Ep 12", "Show Episode 12"
+	absoluteEpisodePattern = regexp.MustCompile(`(?i)(?:^|[ ._])(?:-[ ._]*|ep(?:isode)?[ ._]*|e)(\d{1,4})(?:v\d)?(?:-(\d{1,4})(?:v\d)?)?(?:[ ._-]|$)`)
+	// Any number in a list of episode numbers
+	numberPattern = regexp.MustCompile(`\d+`)
+)
 
-	return tvdbID, nil
+// ParseEpisodeFromPath works out which episode(s) a file is from its name. Standard
+// S01E02 and 1x02 names give season and episode numbers; anime names such as
+// "[Group] Show - 123 [1080p].mkv" give absolute episode numbers.
+func ParseEpisodeFromPath(filePath string) (EpisodeRef, error) {
+	name := filepath.Base(filePath)
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+
+	if m := seasonEpisodePattern.FindStringSubmatch(name); m != nil {
+		season, _ := strconv.Atoi(m[1])
+		first, _ := strconv.Atoi(m[2])
+		last := first
+		for _, n := range numberPattern.FindAllString(m[3], -1) {
+			last, _ = strconv.Atoi(n)
+		}
+		return EpisodeRef{Season: season, Episodes: episodeRange(first, last)}, nil
+	}
+
+	if m := crossEpisodePattern.FindStringSubmatch(name); m != nil {
+		season, _ := strconv.Atoi(m[1])
+		first, _ := strconv.Atoi(m[2])
+		last := first
+		if m[3] != "" {
+			last, _ = strconv.Atoi(m[3])
+		}
+		return EpisodeRef{Season: season, Episodes: episodeRange(first, last)}, nil
+	}
+
+	stripped := bracketedPattern.ReplaceAllString(name, " ")
+	if m := absoluteEpisodePattern.FindStringSubmatch(stripped); m != nil {
+		first, _ := strconv.Atoi(m[1])
+		last := first
+		if m[2] != "" {
+			last, _ = strconv.Atoi(m[2])
+		}
+		if first > 0 {
+			return EpisodeRef{Absolute: episodeRange(first, last)}, nil
+		}
+	}
+
+	return EpisodeRef{}, fmt.Errorf("episode number not found in path: %s", filePath)
 }
 
-// ParseTVDBIDFromPath extracts TVDB ID from a file path
+// episodeRange returns the numbers first to last, or just first when last doesn't follow it
+func episodeRange(first, last int) []int {
+	if last <= first {
+		return []int{first}
+	}
+	numbers := make([]int, 0, last-first+1)
+	for n := first; n <= last; n++ {
+		numbers = append(numbers, n)
+	}
+	return numbers
+}
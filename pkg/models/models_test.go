@@ -1,7 +1,9 @@
 package models
 
 import (
+	"fmt"
 	"testing"
+	"time"
 )
 
 func TestMediaItem(t *testing.T) {
@@ -244,3 +246,72 @@ func TestZeroValues(t *testing.T) {
 		t.Error("Expected zero values for CleanupResult")
 	}
 }
+
+func TestParseEpisodeFromPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		season   int
+		episodes []int
+		absolute []int
+	}{
+		{path: "/tv/Show (2020) [tvdb-1]/Season 01/Show - S01E02 - Title.mkv", season: 1, episodes: []int{2}},
+		{path: "Show.S01E02E03.mkv", season: 1, episodes: []int{2, 3}},
+		{path: "Show - S02E02-E04.mkv", season: 2, episodes: []int{2, 3, 4}},
+		{path: "Show 1x05.mkv", season: 1, episodes: []int{5}},
+		{path: "Show - S01E01 - 001 - Title.mkv", season: 1, episodes: []int{1}},
+		{path: "[SubsPlease] One Piece - 1071 (1080p) [ABCD1234].mkv", absolute: []int{1071}},
+		{path: "[Group] Show - 012v2 [720p].mkv", absolute: []int{12}},
+		{path: "Show - 012-013 [1080p].mkv", absolute: []int{12, 13}},
+		{path: "Show E123.mkv", absolute: []int{123}},
+		{path: "Show Ep.12.mkv", absolute: []int{12}},
+	}
+
+	for _, tt := range tests {
+		ref, err := ParseEpisodeFromPath(tt.path)
+		if err != nil {
+			t.Errorf("ParseEpisodeFromPath(%q) error = %v", tt.path, err)
+			continue
+		}
+		if fmt.Sprint(ref.Episodes) != fmt.Sprint(tt.episodes) || fmt.Sprint(ref.Absolute) != fmt.Sprint(tt.absolute) ||
+			(tt.episodes != nil && ref.Season != tt.season) {
+			t.Errorf("ParseEpisodeFromPath(%q) = %+v", tt.path, ref)
+		}
+	}
+
+	if _, err := ParseEpisodeFromPath("/tv/Show (2020)/Show (2020) 1080p.mkv"); err == nil {
+		t.Error("Expected an error for a name without an episode number")
+	}
+}
+
+func TestEpisodeRef_Matches(t *testing.T) {
+	episode := Episode{SeasonNumber: 3, EpisodeNumber: 4, AbsoluteEpisodeNumber: 52}
+
+	if !(EpisodeRef{Season: 3, Episodes: []int{4, 5}}).Matches(episode) {
+		t.Error("Expected S03E04 to match")
+	}
+	if (EpisodeRef{Season: 1, Episodes: []int{4}}).Matches(episode) {
+		t.Error("Expected S01E04 not to match")
+	}
+	if !(EpisodeRef{Absolute: []int{52}}).Matches(episode) {
+		t.Error("Expected absolute episode 52 to match")
+	}
+	if (EpisodeRef{Absolute: []int{4}}).Matches(Episode{SeasonNumber: 1, EpisodeNumber: 4}) {
+		t.Error("Expected an episode without an absolute number not to match")
+	}
+}
+
+func TestMeanTimeToRecovery(t *testing.T) {
+	entries := []RecoveredFileEntry{
+		{MissingSince: "2024-01-01T00:00:00Z", RecoveredAt: "2024-01-02T00:00:00Z"},
+		{MissingSince: "2024-01-01T00:00:00+02:00", RecoveredAt: "2024-01-03T22:00:00Z"},
+		{MissingSince: "unknown", RecoveredAt: "2024-01-03T00:00:00Z"},
+	}
+
+	mttr, n := MeanTimeToRecovery(entries)
+	if n != 2 || mttr != 48*time.Hour {
+		t.Errorf("Expected 48h over 2 entries, got %s over %d", mttr, n)
+	}
+	if _, n := MeanTimeToRecovery(entries[2:]); n != 0 {
+		t.Errorf("Expected entries without known times to be left out, got %d", n)
+	}
+}
@@ -0,0 +1,211 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// PathInfo is what a PathParser found in a media file's path
+type PathInfo struct {
+	TMDBID int
+	TVDBID int
+	IMDBID string
+	Title  string
+	Year   int
+
+	GoodreadsID int
+	ISBN        string
+}
+
+// Named groups a path pattern can capture
+const (
+	PathGroupTMDB  = "tmdb"
+	PathGroupTVDB  = "tvdb"
+	PathGroupIMDB  = "imdb"
+	PathGroupTitle = "title"
+	PathGroupYear  = "year"
+
+	PathGroupGoodreads = "goodreads"
+	PathGroupISBN      = "isbn"
+)
+
+var pathGroups = map[string]bool{
+	PathGroupTMDB:      true,
+	PathGroupTVDB:      true,
+	PathGroupIMDB:      true,
+	PathGroupTitle:     true,
+	PathGroupYear:      true,
+	PathGroupGoodreads: true,
+	PathGroupISBN:      true,
+}
+
+// DefaultPathPatterns are the naming schemes every PathParser understands, tried after any
+// configured patterns: Movie Title (Year) [tmdb-12345], Series Title (Year) [tvdb-12345],
+// Movie Title (Year) [imdb-tt1234567] or [imdbid-tt1234567], Book Title [goodreads-12345] or
+// [isbn-9780000000000], and the Title (Year) of a folder or file for paths without any ID
+var DefaultPathPatterns = []string{
+	`\[tmdb-(?P<tmdb>\d+)\]`,
+	`\[tvdb-(?P<tvdb>\d+)\]`,
+	`\[imdb(?:id)?-(?P<imdb>tt\d+)\]`,
+	`\[goodreads-(?P<goodreads>\d+)\]`,
+	`\[isbn-(?P<isbn>\d{13}|\d{9}[\dXx])\]`,
+	`(?:^|/)(?P<title>[^/]+?) \((?P<year>(?:19|20)\d{2})\)`,
+}
+
+// PathParser extracts provider IDs, titles and years from media file paths using regexes with
+// named groups (tmdb, tvdb, imdb, goodreads, isbn, title, year). Patterns are tried in order;
+// each field comes from the first pattern that captures it.
+type PathParser struct {
+	patterns []*regexp.Regexp
+}
+
+var defaultPathParser, _ = NewPathParser(nil)
+
+// NewPathParser creates a parser trying the given patterns before the default ones. Every
+// pattern must compile and capture at least one named group, and only known group names.
+func NewPathParser(patterns []string) (*PathParser, error) {
+	parser := &PathParser{}
+	for _, pattern := range append(append([]string{}, patterns...), DefaultPathPatterns...) {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path pattern %q: %w", pattern, err)
+		}
+
+		named := 0
+		for _, name := range re.SubexpNames() {
+			if name == "" {
+				continue
+			}
+			if !pathGroups[name] {
+				return nil, fmt.Errorf("invalid path pattern %q: unknown group %q (use tmdb, tvdb, imdb, goodreads, isbn, title or year)", pattern, name)
+			}
+			named++
+		}
+		if named == 0 {
+			return nil, fmt.Errorf("invalid path pattern %q: no named group such as (?P<tmdb>...)", pattern)
+		}
+		parser.patterns = append(parser.patterns, re)
+	}
+	return parser, nil
+}
+
+// Parse returns everything the patterns find in the path. A nil parser uses the default patterns.
+func (p *PathParser) Parse(filePath string) PathInfo {
+	if p == nil {
+		p = defaultPathParser
+	}
+
+	var info PathInfo
+	for _, re := range p.patterns {
+		matches := re.FindStringSubmatch(filePath)
+		if matches == nil {
+			continue
+		}
+		for i, name := range re.SubexpNames() {
+			value := strings.TrimSpace(matches[i])
+			if name == "" || value == "" {
+				continue
+			}
+			switch name {
+			case PathGroupTMDB:
+				if info.TMDBID == 0 {
+					info.TMDBID, _ = strconv.Atoi(value)
+				}
+			case PathGroupTVDB:
+				if info.TVDBID == 0 {
+					info.TVDBID, _ = strconv.Atoi(value)
+				}
+			case PathGroupIMDB:
+				if info.IMDBID == "" {
+					info.IMDBID = value
+				}
+			case PathGroupTitle:
+				if info.Title == "" {
+					// Scene-style names separate words with dots or underscores
+					info.Title = strings.Join(strings.FieldsFunc(value, func(r rune) bool {
+						return r == '.' || r == '_' || r == ' '
+					}), " ")
+				}
+			case PathGroupYear:
+				if info.Year == 0 {
+					info.Year, _ = strconv.Atoi(value)
+				}
+			case PathGroupGoodreads:
+				if info.GoodreadsID == 0 {
+					info.GoodreadsID, _ = strconv.Atoi(value)
+				}
+			case PathGroupISBN:
+				if info.ISBN == "" {
+					info.ISBN = strings.ToUpper(value)
+				}
+			}
+		}
+	}
+	return info
+}
+
+// TMDBID returns the TMDB ID in the path, or an error when no pattern finds one
+func (p *PathParser) TMDBID(filePath string) (int, error) {
+	if id := p.Parse(filePath).TMDBID; id > 0 {
+		return id, nil
+	}
+	return 0, fmt.Errorf("TMDB ID not found in path: %s", filePath)
+}
+
+// TVDBID returns the TVDB ID in the path, or an error when no pattern finds one
+func (p *PathParser) TVDBID(filePath string) (int, error) {
+	if id := p.Parse(filePath).TVDBID; id > 0 {
+		return id, nil
+	}
+	return 0, fmt.Errorf("TVDB ID not found in path: %s", filePath)
+}
+
+// PathMapping moves paths under From to the same place under To, e.g. from the mount media
+// was on before a migration to the one it is on now
+type PathMapping struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// ParsePathMappings parses mapping rules of the form from=to, separated by commas
+func ParsePathMappings(rules string) ([]PathMapping, error) {
+	var mappings []PathMapping
+	for _, rule := range strings.Split(rules, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		from, to, ok := strings.Cut(rule, "=")
+		from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+		if !ok || from == "" || to == "" {
+			return nil, fmt.Errorf("invalid path mapping %q: must be from=to", rule)
+		}
+		mappings = append(mappings, PathMapping{From: trimSeparator(from), To: trimSeparator(to)})
+	}
+	return mappings, nil
+}
+
+// MapPath applies the first mapping whose From folder contains the path, and returns false
+// when none does
+func MapPath(mappings []PathMapping, filePath string) (string, bool) {
+	if filePath == "" {
+		return "", false
+	}
+	for _, mapping := range mappings {
+		from := trimSeparator(mapping.From)
+		if filePath == from {
+			return trimSeparator(mapping.To), true
+		}
+		if rest, ok := strings.CutPrefix(filePath, from); ok && (rest[0] == '/' || rest[0] == '\\') {
+			return trimSeparator(mapping.To) + rest, true
+		}
+	}
+	return "", false
+}
+
+// trimSeparator drops trailing path separators, so a root folder "/" becomes ""
+func trimSeparator(dir string) string {
+	return strings.TrimRight(dir, `/\`)
+}
@@ -0,0 +1,116 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPathParser_DefaultPatterns(t *testing.T) {
+	var parser *PathParser // nil uses the defaults
+
+	info := parser.Parse("/movies/Foo (2020) [tmdb-123]/Foo.mkv")
+	if info.TMDBID != 123 || info.TVDBID != 0 {
+		t.Errorf("Expected TMDB ID 123, got %+v", info)
+	}
+	if id, err := parser.TVDBID("/tv/Show (2019) [tvdb-456]/Season 1/Show - S01E01.mkv"); err != nil || id != 456 {
+		t.Errorf("TVDBID() = %d, %v; want 456", id, err)
+	}
+	if info := parser.Parse("/movies/Foo (2020) [imdbid-tt0000042]/Foo.mkv"); info.IMDBID != "tt0000042" || info.TMDBID != 0 {
+		t.Errorf("Expected IMDb ID tt0000042, got %+v", info)
+	}
+	if _, err := parser.TMDBID("/movies/Foo (2020)/Foo.mkv"); err == nil {
+		t.Error("Expected an error for a path without a TMDB tag")
+	}
+	if info := parser.Parse("/tv/Some Show (2019)/Season 1/Some Show (2019) - S01E01.mkv"); info.Title != "Some Show" || info.Year != 2019 {
+		t.Errorf("Expected the folder's title and year, got %+v", info)
+	}
+	if info := parser.Parse("/books/Some Author/Some Book [goodreads-12345]/Some Book.epub"); info.GoodreadsID != 12345 {
+		t.Errorf("Expected Goodreads ID 12345, got %+v", info)
+	}
+	if info := parser.Parse("/books/Some Author/Some Book [isbn-080442957x]/Some Book.epub"); info.ISBN != "080442957X" {
+		t.Errorf("Expected ISBN 080442957X, got %+v", info)
+	}
+}
+
+func TestPathParser_CustomPatterns(t *testing.T) {
+	parser, err := NewPathParser([]string{
+		`\{tmdb-(?P<tmdb>\d+)\}`,
+		`\[imdbid-(?P<imdb>tt\d+)\]`,
+		`/(?P<title>[^/]+?)[. ]\(?(?P<year>(?:19|20)\d{2})\)?[^/]*/[^/]+$`,
+	})
+	if err != nil {
+		t.Fatalf("NewPathParser() error = %v", err)
+	}
+
+	info := parser.Parse("/movies/The.Big.Movie.2021/The.Big.Movie.2021.1080p.mkv")
+	if info.Title != "The Big Movie" || info.Year != 2021 || info.TMDBID != 0 {
+		t.Errorf("Expected title and year from a plain scene name, got %+v", info)
+	}
+
+	info = parser.Parse("/movies/Foo (2020) {tmdb-42} [imdbid-tt0000042]/Foo.mkv")
+	if info.TMDBID != 42 || info.IMDBID != "tt0000042" || info.Title != "Foo" || info.Year != 2020 {
+		t.Errorf("Expected every field from one path, got %+v", info)
+	}
+
+	// The default tags are still understood, after the custom patterns
+	if id, err := parser.TMDBID("/movies/Bar (2001) [tmdb-7]/Bar.mkv"); err != nil || id != 7 {
+		t.Errorf("TMDBID() = %d, %v; want 7", id, err)
+	}
+}
+
+func TestNewPathParser_InvalidPatterns(t *testing.T) {
+	for pattern, want := range map[string]string{
+		`[tmdb-(?P<tmdb>\d+)`:    "invalid path pattern",
+		`\[tmdb-(\d+)\]`:         "no named group",
+		`\[tmdb-(?P<tmbd>\d+)\]`: "unknown group",
+		`(?P<title>.+) (?P<yr>)`: "unknown group",
+	} {
+		if _, err := NewPathParser([]string{pattern}); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("NewPathParser(%q) error = %v, want it to mention %q", pattern, err, want)
+		}
+	}
+}
+
+func TestParsePathMappings(t *testing.T) {
+	mappings, err := ParsePathMappings(" /mnt/old/tv/ = /data/tv , D:\\Movies=E:\\Media\\Movies")
+	if err != nil {
+		t.Fatalf("ParsePathMappings() error = %v", err)
+	}
+	want := []PathMapping{{From: "/mnt/old/tv", To: "/data/tv"}, {From: `D:\Movies`, To: `E:\Media\Movies`}}
+	if len(mappings) != len(want) || mappings[0] != want[0] || mappings[1] != want[1] {
+		t.Errorf("ParsePathMappings() = %+v, want %+v", mappings, want)
+	}
+
+	for _, rules := range []string{"/mnt/old", "=/data", "/mnt/old="} {
+		if _, err := ParsePathMappings(rules); err == nil {
+			t.Errorf("Expected an error for %q", rules)
+		}
+	}
+}
+
+func TestMapPath(t *testing.T) {
+	mappings := []PathMapping{
+		{From: "/mnt/old/tv", To: "/data/tv"},
+		{From: `D:\Movies`, To: `E:\Media\Movies`},
+	}
+
+	tests := []struct {
+		path   string
+		want   string
+		mapped bool
+	}{
+		{path: "/mnt/old/tv/Show/Season 1/Show - S01E01.mkv", want: "/data/tv/Show/Season 1/Show - S01E01.mkv", mapped: true},
+		{path: "/mnt/old/tv", want: "/data/tv", mapped: true},
+		{path: `D:\Movies\Foo (2020)\Foo.mkv`, want: `E:\Media\Movies\Foo (2020)\Foo.mkv`, mapped: true},
+		{path: "/mnt/old/tvshows/Show/Show - S01E01.mkv"},
+		{path: "/data/tv/Show/Show - S01E01.mkv"},
+		{path: ""},
+	}
+
+	for _, tt := range tests {
+		got, mapped := MapPath(mappings, tt.path)
+		if got != tt.want || mapped != tt.mapped {
+			t.Errorf("MapPath(%q) = %q, %v; want %q, %v", tt.path, got, mapped, tt.want, tt.mapped)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// RunResult is the outcome of one command against one service, in the same shape whatever the
+// command, so the completion webhook and push notifications handle the output of every command
+// alike. Report sinks and the runs API keep shapes of their own: they deal in missing file
+// reports and the run history, which only cleanup and verify runs have.
+type RunResult struct {
+	Command    string       `json:"command"` // e.g. "cleanup" or "fix-imports"
+	RunID      string       `json:"runId"`
+	Service    string       `json:"service"`
+	DryRun     bool         `json:"dryRun"`
+	Success    bool         `json:"success"`
+	Error      string       `json:"error,omitempty"`  // Why the run failed
+	Errors     []string     `json:"errors,omitempty"` // Items the run couldn't handle, which didn't fail it
+	StartedAt  time.Time    `json:"startedAt"`
+	FinishedAt time.Time    `json:"finishedAt"`
+	Stats      CleanupStats `json:"stats"`
+	ReportPath string       `json:"reportPath,omitempty"`
+
+	// Output is the command's own result, e.g. an *ImportFixResult, for consumers that need
+	// more than the common fields
+	Output interface{} `json:"output,omitempty"`
+}
+
+// Duration returns how long the run took
+func (r *RunResult) Duration() time.Duration {
+	return r.FinishedAt.Sub(r.StartedAt)
+}
+
+// RunResult wraps the result of a fix-imports run. Stuck items count as checked, and items left
+// in the queue as errors.
+func (r *ImportFixResult) RunResult(runID string, startedAt, finishedAt time.Time) *RunResult {
+	return &RunResult{
+		Command:    "fix-imports",
+		RunID:      runID,
+		Service:    "sonarr",
+		DryRun:     r.DryRun,
+		Success:    r.Success,
+		Errors:     r.Errors,
+		StartedAt:  startedAt,
+		FinishedAt: finishedAt,
+		Stats: CleanupStats{
+			TotalItemsChecked: r.TotalStuckItems,
+			Errors:            len(r.Errors),
+			Skipped:           r.CoolingDown,
+			Duration:          finishedAt.Sub(startedAt),
+		},
+		Output: r,
+	}
+}
+
+// RunResult wraps a snapshot comparison saved at reportPath. Compared records count as checked,
+// and records that disappeared as missing files.
+func (d *SnapshotDiff) RunResult(runID string, startedAt, finishedAt time.Time, reportPath string) *RunResult {
+	return &RunResult{
+		Command:    "compare-snapshot",
+		RunID:      runID,
+		Service:    strings.Join(d.Services, ","),
+		DryRun:     true, // Comparing changes nothing
+		Success:    true,
+		StartedAt:  startedAt,
+		FinishedAt: finishedAt,
+		Stats: CleanupStats{
+			TotalItemsChecked: d.Compared,
+			MissingFiles:      len(d.Disappeared),
+			Duration:          finishedAt.Sub(startedAt),
+		},
+		ReportPath: reportPath,
+		Output:     d,
+	}
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestImportFixResult_RunResult(t *testing.T) {
+	startedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	fix := &ImportFixResult{TotalStuckItems: 4, FixedItems: 2, CoolingDown: 1, Errors: []string{"Queue item 7: no files found"}, Success: true}
+
+	result := fix.RunResult("run-1", startedAt, startedAt.Add(time.Minute))
+	if result.Command != "fix-imports" || result.Service != "sonarr" || result.RunID != "run-1" || !result.Success {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if result.Stats.TotalItemsChecked != 4 || result.Stats.Errors != 1 || result.Stats.Skipped != 1 || len(result.Errors) != 1 {
+		t.Errorf("Unexpected stats: %+v", result.Stats)
+	}
+	if result.Duration() != time.Minute || result.Output != fix {
+		t.Errorf("Expected the run's duration and the fix-imports result, got %s and %v", result.Duration(), result.Output)
+	}
+}
+
+func TestSnapshotDiff_RunResult(t *testing.T) {
+	diff := &SnapshotDiff{Services: []string{"sonarr", "radarr"}, Compared: 10, Disappeared: []SnapshotFile{{FileID: 1}}}
+
+	result := diff.RunResult("run-1", time.Now(), time.Now(), "reports/snapshot-diff.json")
+	if result.Command != "compare-snapshot" || result.Service != "sonarr,radarr" || !result.DryRun || result.ReportPath != "reports/snapshot-diff.json" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if result.Stats.TotalItemsChecked != 10 || result.Stats.MissingFiles != 1 {
+		t.Errorf("Unexpected stats: %+v", result.Stats)
+	}
+}
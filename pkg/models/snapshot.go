@@ -0,0 +1,108 @@
+package models
+
+import "fmt"
+
+// SnapshotDiff compares the file records of an earlier library snapshot with the current ones
+type SnapshotDiff struct {
+	GeneratedAt string               `json:"generatedAt"`
+	SnapshotAt  string               `json:"snapshotAt"` // When the earlier snapshot was taken
+	Services    []string             `json:"services"`
+	Compared    int                  `json:"compared"`    // File records of the snapshot that were compared
+	Disappeared []SnapshotFile       `json:"disappeared"` // Snapshot records with an episode or movie that has no file now
+	PathChanged []SnapshotPathChange `json:"pathChanged"` // Episodes and movies whose file is at another path now
+	Added       []SnapshotFile       `json:"added"`       // Current records of episodes and movies the snapshot had no file for
+}
+
+// SnapshotPathChange is an episode or movie file that moved since the snapshot
+type SnapshotPathChange struct {
+	Old SnapshotFile `json:"old"`
+	New SnapshotFile `json:"new"`
+}
+
+// HasChanges reports whether anything disappeared, moved or was added
+func (d *SnapshotDiff) HasChanges() bool {
+	return len(d.Disappeared) > 0 || len(d.PathChanged) > 0 || len(d.Added) > 0
+}
+
+// DiffSnapshots compares the records of an earlier snapshot with current ones. Records are
+// matched by the episodes or movie they hold rather than by file ID, as upgrades replace the
+// record. Only services in current are compared.
+func DiffSnapshots(earlier, current *LibrarySnapshot) *SnapshotDiff {
+	diff := &SnapshotDiff{
+		SnapshotAt:  earlier.GeneratedAt,
+		Services:    current.Services,
+		Disappeared: []SnapshotFile{},
+		PathChanged: []SnapshotPathChange{},
+		Added:       []SnapshotFile{},
+	}
+
+	compared := make(map[string]bool)
+	for _, service := range current.Services {
+		compared[service] = true
+	}
+
+	currentByUnit := make(map[string]SnapshotFile)
+	for _, file := range current.Files {
+		for _, unit := range file.units() {
+			currentByUnit[unit] = file
+		}
+	}
+
+	earlierUnits := make(map[string]bool)
+	seenChanges := make(map[string]bool)
+	for _, file := range earlier.Files {
+		if !compared[file.Service] {
+			continue
+		}
+		diff.Compared++
+
+		disappeared := false
+		for _, unit := range file.units() {
+			earlierUnits[unit] = true
+			now, ok := currentByUnit[unit]
+			if !ok {
+				disappeared = true
+				continue
+			}
+			// A multi-episode file has one unit per episode, but moved only once
+			key := fmt.Sprintf("%s-%d-%d", file.Service, file.FileID, now.FileID)
+			if now.Path != file.Path && !seenChanges[key] {
+				seenChanges[key] = true
+				diff.PathChanged = append(diff.PathChanged, SnapshotPathChange{Old: file, New: now})
+			}
+		}
+		if disappeared {
+			diff.Disappeared = append(diff.Disappeared, file)
+		}
+	}
+
+	for _, file := range current.Files {
+		for _, unit := range file.units() {
+			if !earlierUnits[unit] {
+				diff.Added = append(diff.Added, file)
+				break
+			}
+		}
+	}
+
+	return diff
+}
+
+// units returns keys for the movie or each episode the record holds. A book can have a file
+// per format, so its records are keyed by file.
+func (f SnapshotFile) units() []string {
+	if f.MediaType == "movie" {
+		return []string{fmt.Sprintf("%s/movie/%d", f.Service, f.MediaID)}
+	}
+	if f.MediaType == "book" {
+		return []string{fmt.Sprintf("%s/book/%d/file-%d", f.Service, f.MediaID, f.FileID)}
+	}
+	if len(f.Episodes) == 0 {
+		return []string{fmt.Sprintf("%s/episode/%d/%d/file-%d", f.Service, f.MediaID, f.Season, f.FileID)}
+	}
+	units := make([]string, len(f.Episodes))
+	for i, episode := range f.Episodes {
+		units[i] = fmt.Sprintf("%s/episode/%d/%d/%d", f.Service, f.MediaID, f.Season, episode)
+	}
+	return units
+}
@@ -0,0 +1,67 @@
+package models
+
+import "testing"
+
+func TestDiffSnapshots(t *testing.T) {
+	earlier := &LibrarySnapshot{
+		GeneratedAt: "2024-01-01T12:00:00Z",
+		Services:    []string{"sonarr", "radarr"},
+		Files: []SnapshotFile{
+			// Split into two files at new paths
+			{Service: "sonarr", MediaType: "episode", MediaID: 1, MediaName: "Foo", Season: 1, Episodes: []int{1, 2}, FileID: 100, Path: "/tv/Foo/S01E01-E02.mkv"},
+			// Gone
+			{Service: "sonarr", MediaType: "episode", MediaID: 1, MediaName: "Foo", Season: 1, Episodes: []int{3}, FileID: 101, Path: "/tv/Foo/S01E03.mkv"},
+			// Upgraded in place
+			{Service: "sonarr", MediaType: "episode", MediaID: 2, MediaName: "Bar", Season: 2, Episodes: []int{1}, FileID: 200, Path: "/tv/Bar/S02E01.mkv"},
+			// Moved
+			{Service: "radarr", MediaType: "movie", MediaID: 5, MediaName: "Baz", FileID: 50, Path: "/old/Baz/Baz.mkv"},
+			// Not compared: Lidarr isn't in the current snapshot
+			{Service: "lidarr", MediaType: "track", MediaID: 9, FileID: 90, Path: "/music/a.flac"},
+		},
+	}
+	current := &LibrarySnapshot{
+		Services: []string{"sonarr", "radarr"},
+		Files: []SnapshotFile{
+			{Service: "sonarr", MediaType: "episode", MediaID: 1, MediaName: "Foo", Season: 1, Episodes: []int{1}, FileID: 110, Path: "/tv/Foo/S01E01.mkv"},
+			{Service: "sonarr", MediaType: "episode", MediaID: 1, MediaName: "Foo", Season: 1, Episodes: []int{2}, FileID: 111, Path: "/tv/Foo/S01E02.mkv"},
+			{Service: "sonarr", MediaType: "episode", MediaID: 2, MediaName: "Bar", Season: 2, Episodes: []int{1}, FileID: 201, Path: "/tv/Bar/S02E01.mkv"},
+			{Service: "sonarr", MediaType: "episode", MediaID: 2, MediaName: "Bar", Season: 2, Episodes: []int{2}, FileID: 202, Path: "/tv/Bar/S02E02.mkv"},
+			{Service: "radarr", MediaType: "movie", MediaID: 5, MediaName: "Baz", FileID: 50, Path: "/new/Baz/Baz.mkv"},
+		},
+	}
+
+	diff := DiffSnapshots(earlier, current)
+
+	if diff.Compared != 4 || diff.SnapshotAt != "2024-01-01T12:00:00Z" {
+		t.Errorf("Expected 4 records compared, got %+v", diff)
+	}
+	if len(diff.Disappeared) != 1 || diff.Disappeared[0].FileID != 101 {
+		t.Errorf("Expected file 101 to have disappeared, got %+v", diff.Disappeared)
+	}
+	if len(diff.PathChanged) != 3 {
+		t.Fatalf("Expected 3 path changes, got %+v", diff.PathChanged)
+	}
+	if change := diff.PathChanged[0]; change.Old.FileID != 100 || change.New.FileID != 110 {
+		t.Errorf("Unexpected first change %+v", change)
+	}
+	if change := diff.PathChanged[2]; change.Old.Path != "/old/Baz/Baz.mkv" || change.New.Path != "/new/Baz/Baz.mkv" {
+		t.Errorf("Unexpected movie change %+v", change)
+	}
+	if len(diff.Added) != 1 || diff.Added[0].FileID != 202 {
+		t.Errorf("Expected file 202 to be added, got %+v", diff.Added)
+	}
+	if !diff.HasChanges() {
+		t.Error("Expected HasChanges() to be true")
+	}
+}
+
+func TestDiffSnapshots_NoChanges(t *testing.T) {
+	snapshot := &LibrarySnapshot{
+		Services: []string{"radarr"},
+		Files:    []SnapshotFile{{Service: "radarr", MediaType: "movie", MediaID: 5, FileID: 50, Path: "/movies/Baz.mkv"}},
+	}
+
+	if diff := DiffSnapshots(snapshot, snapshot); diff.HasChanges() || diff.Compared != 1 {
+		t.Errorf("Expected no changes, got %+v", diff)
+	}
+}
@@ -0,0 +1,190 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaintenanceWindow is a weekly time range in which runs may change the *arr instances, written
+// like a cron day-of-week field followed by a time range: "sat,sun 02:00-06:00", "mon-fri
+// 22:00-04:00" or "* 03:00-05:00". A range that ends before it starts crosses midnight and
+// belongs to the day it starts on.
+type MaintenanceWindow struct {
+	Days  [7]bool // Days the window opens on, indexed by time.Weekday
+	Start int     // Minutes after midnight the window opens
+	End   int     // Minutes after midnight the window closes, up to 24:00
+	Spec  string  // The window as it was written
+}
+
+// weekdayNames are the day names a window can use, indexed by time.Weekday
+var weekdayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// ParseMaintenanceWindows parses windows separated by semicolons, e.g.
+// "sat,sun 02:00-08:00; mon-fri 03:00-05:00". Days may be names or cron numbers (0 or 7 for
+// Sunday), and are left out or * for every day.
+func ParseMaintenanceWindows(spec string) ([]MaintenanceWindow, error) {
+	var windows []MaintenanceWindow
+	for _, part := range strings.Split(spec, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		window, err := parseMaintenanceWindow(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid maintenance window %q: %w", part, err)
+		}
+		windows = append(windows, window)
+	}
+	return windows, nil
+}
+
+// parseMaintenanceWindow parses a single window: optional days, then a time range
+func parseMaintenanceWindow(spec string) (MaintenanceWindow, error) {
+	window := MaintenanceWindow{Spec: spec}
+	fields := strings.Fields(spec)
+	days := "*"
+	switch len(fields) {
+	case 1:
+	case 2:
+		days = fields[0]
+	default:
+		return window, fmt.Errorf("must be [days] HH:MM-HH:MM")
+	}
+
+	if err := parseWindowDays(days, &window.Days); err != nil {
+		return window, err
+	}
+
+	from, to, ok := strings.Cut(fields[len(fields)-1], "-")
+	if !ok {
+		return window, fmt.Errorf("time range must be HH:MM-HH:MM")
+	}
+	var err error
+	if window.Start, err = parseClock(from); err != nil {
+		return window, err
+	}
+	if window.End, err = parseClock(to); err != nil {
+		return window, err
+	}
+	if window.Start == window.End {
+		return window, fmt.Errorf("window opens and closes at the same time")
+	}
+	if window.Start == 24*60 {
+		return window, fmt.Errorf("window can't open at 24:00")
+	}
+	return window, nil
+}
+
+// parseWindowDays marks the days of a cron-like day-of-week field in days
+func parseWindowDays(field string, days *[7]bool) error {
+	if field == "*" {
+		for i := range days {
+			days[i] = true
+		}
+		return nil
+	}
+	for _, item := range strings.Split(field, ",") {
+		first, last, isRange := strings.Cut(item, "-")
+		start, err := parseWeekday(first)
+		if err != nil {
+			return err
+		}
+		end := start
+		if isRange {
+			if end, err = parseWeekday(last); err != nil {
+				return err
+			}
+			if start == 0 && end == 0 {
+				// 0-7 is Sunday to Sunday, the whole week
+				end = 6
+			}
+		}
+		// Ranges may wrap around the week, e.g. fri-mon
+		for day := start; ; day = (day + 1) % 7 {
+			days[day] = true
+			if day == end {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// parseWeekday parses a day name (sun, monday) or cron day number (0-7, with 0 and 7 Sunday)
+func parseWeekday(value string) (int, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if n, err := strconv.Atoi(value); err == nil {
+		if n < 0 || n > 7 {
+			return 0, fmt.Errorf("day %d out of range 0-7", n)
+		}
+		return n % 7, nil
+	}
+	if len(value) >= 3 {
+		for i, name := range weekdayNames {
+			if strings.HasPrefix(value, name) {
+				return i, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("unknown day %q", value)
+}
+
+// parseClock parses HH:MM into minutes after midnight; 24:00 is the end of the day
+func parseClock(value string) (int, error) {
+	if value == "24:00" {
+		return 24 * 60, nil
+	}
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q: expected HH:MM", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Contains reports whether t, in its own location, is inside the window
+func (w MaintenanceWindow) Contains(t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+	day := int(t.Weekday())
+	if w.Start < w.End {
+		return w.Days[day] && minute >= w.Start && minute < w.End
+	}
+	// The window crosses midnight: it's either the evening it opened or the morning after
+	return (w.Days[day] && minute >= w.Start) || (w.Days[(day+6)%7] && minute < w.End)
+}
+
+// InMaintenanceWindow reports whether changes may be made at t: without windows they always may
+func InMaintenanceWindow(windows []MaintenanceWindow, t time.Time) bool {
+	if len(windows) == 0 {
+		return true
+	}
+	for _, window := range windows {
+		if window.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextMaintenanceWindow returns when the next of the windows opens after t, and false when
+// there are none
+func NextMaintenanceWindow(windows []MaintenanceWindow, t time.Time) (time.Time, bool) {
+	var next time.Time
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	for offset := 0; offset <= 7; offset++ {
+		day := midnight.AddDate(0, 0, offset)
+		for _, window := range windows {
+			if !window.Days[day.Weekday()] {
+				continue
+			}
+			// Set on the day's clock, not counted from midnight, which is off on the days the
+			// clocks change
+			opens := time.Date(day.Year(), day.Month(), day.Day(), window.Start/60, window.Start%60, 0, 0, t.Location())
+			if opens.After(t) && (next.IsZero() || opens.Before(next)) {
+				next = opens
+			}
+		}
+	}
+	return next, !next.IsZero()
+}
@@ -0,0 +1,138 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseMaintenanceWindows(t *testing.T) {
+	windows, err := ParseMaintenanceWindows("sat,sun 02:00-06:00; mon-fri 22:00-04:00 ;03:00-03:30")
+	if err != nil {
+		t.Fatalf("ParseMaintenanceWindows() failed: %v", err)
+	}
+	if len(windows) != 3 {
+		t.Fatalf("Expected 3 windows, got %d", len(windows))
+	}
+	if !windows[0].Days[time.Saturday] || !windows[0].Days[time.Sunday] || windows[0].Days[time.Monday] {
+		t.Errorf("Unexpected days for the weekend window: %v", windows[0].Days)
+	}
+	if windows[0].Start != 120 || windows[0].End != 360 || windows[0].Spec != "sat,sun 02:00-06:00" {
+		t.Errorf("Unexpected weekend window: %+v", windows[0])
+	}
+	if windows[1].Days != [7]bool{false, true, true, true, true, true, false} {
+		t.Errorf("Unexpected days for mon-fri: %v", windows[1].Days)
+	}
+	if windows[2].Days != [7]bool{true, true, true, true, true, true, true} {
+		t.Errorf("Expected a window without days to be open every day, got %v", windows[2].Days)
+	}
+
+	days := map[string][7]bool{
+		"1-5":     {false, true, true, true, true, true, false},
+		"0-7":     {true, true, true, true, true, true, true},
+		"5-7":     {true, false, false, false, false, true, true},
+		"fri-mon": {true, true, false, false, false, true, true},
+		"Tuesday": {false, false, true, false, false, false, false},
+		"*":       {true, true, true, true, true, true, true},
+	}
+	for field, want := range days {
+		windows, err := ParseMaintenanceWindows(field + " 01:00-02:00")
+		if err != nil {
+			t.Errorf("ParseMaintenanceWindows(%q) failed: %v", field, err)
+			continue
+		}
+		if windows[0].Days != want {
+			t.Errorf("Days of %q = %v, want %v", field, windows[0].Days, want)
+		}
+	}
+
+	for _, spec := range []string{
+		"sat 02:00",
+		"sat 02:00-02:00",
+		"sat 25:00-26:00",
+		"funday 01:00-02:00",
+		"8 01:00-02:00",
+		"sat sun 01:00-02:00",
+		"24:00-01:00",
+	} {
+		if _, err := ParseMaintenanceWindows(spec); err == nil {
+			t.Errorf("Expected an error for %q", spec)
+		}
+	}
+}
+
+func TestInMaintenanceWindow(t *testing.T) {
+	windows, err := ParseMaintenanceWindows("sat 02:00-06:00; mon-fri 22:00-04:00; sun 20:00-24:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 2026-10-12 is a Monday
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 10, 12+day, hour, minute, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name string
+		t    time.Time
+		want bool
+	}{
+		{"monday noon", at(0, 12, 0), false},
+		{"monday night", at(0, 23, 0), true},
+		{"tuesday morning after monday night", at(1, 3, 59), true},
+		{"tuesday at the close", at(1, 4, 0), false},
+		{"saturday morning after friday night", at(5, 3, 0), true},
+		{"sunday morning", at(6, 3, 0), false},
+		{"sunday until midnight", at(6, 23, 59), true},
+		{"monday morning after sunday's window", at(7, 1, 0), false},
+	}
+	for _, tt := range tests {
+		if got := InMaintenanceWindow(windows, tt.t); got != tt.want {
+			t.Errorf("%s: InMaintenanceWindow(%s) = %v, want %v", tt.name, tt.t.Format("Mon 15:04"), got, tt.want)
+		}
+	}
+
+	if !InMaintenanceWindow(nil, at(0, 12, 0)) {
+		t.Error("Expected changes to be allowed any time without windows")
+	}
+}
+
+func TestNextMaintenanceWindow(t *testing.T) {
+	windows, err := ParseMaintenanceWindows("sat,sun 02:00-06:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	monday := time.Date(2026, 10, 12, 12, 0, 0, 0, time.UTC)
+	next, ok := NextMaintenanceWindow(windows, monday)
+	if !ok || !next.Equal(time.Date(2026, 10, 17, 2, 0, 0, 0, time.UTC)) {
+		t.Errorf("NextMaintenanceWindow() = %v, %v, want Saturday 02:00", next, ok)
+	}
+
+	// Inside Saturday's window the next one is Sunday's
+	saturday := time.Date(2026, 10, 17, 3, 0, 0, 0, time.UTC)
+	next, ok = NextMaintenanceWindow(windows, saturday)
+	if !ok || !next.Equal(time.Date(2026, 10, 18, 2, 0, 0, 0, time.UTC)) {
+		t.Errorf("NextMaintenanceWindow() = %v, %v, want Sunday 02:00", next, ok)
+	}
+
+	if _, ok := NextMaintenanceWindow(nil, monday); ok {
+		t.Error("Expected no next window without windows")
+	}
+}
+
+func TestNextMaintenanceWindow_ClocksChange(t *testing.T) {
+	london, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Skipf("No time zone database: %v", err)
+	}
+	windows, err := ParseMaintenanceWindows("sun 06:00-08:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The clocks go back an hour early on Sunday, making it 25 hours long
+	saturday := time.Date(2026, 10, 24, 12, 0, 0, 0, london)
+	next, ok := NextMaintenanceWindow(windows, saturday)
+	if want := time.Date(2026, 10, 25, 6, 0, 0, 0, london); !ok || !next.Equal(want) {
+		t.Errorf("NextMaintenanceWindow() = %v, %v, want %v", next, ok, want)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hnipps/refresharr/internal/arr"
+	"github.com/hnipps/refresharr/internal/config"
+	"github.com/hnipps/refresharr/internal/recording"
+)
+
+// startRecording creates the recorder for a --record run. The configured API keys and tokens
+// are redacted wherever they appear in the recorded traffic.
+func startRecording(cfg *config.Config, logger arr.Logger) *recording.Recorder {
+	secrets := []string{cfg.Sonarr.APIKey, cfg.Radarr.APIKey, cfg.Readarr.APIKey, cfg.Prowlarr.APIKey, cfg.Plex.Token}
+	for _, instance := range cfg.Instances {
+		secrets = append(secrets, instance.APIKey)
+	}
+	logger.Info("🎙️  Recording API traffic and file checks to %s (API keys are redacted)", cfg.Record)
+	return recording.NewRecorder(version, os.Args[1:], secrets)
+}
+
+// saveRecording writes the recorded bundle
+func saveRecording(recorder *recording.Recorder, path string, logger arr.Logger) {
+	if err := recorder.Save(path); err != nil {
+		logger.Warn("Failed to save recording: %s", err.Error())
+		return
+	}
+	logger.Info("🎙️  Saved debug bundle to %s - attach it to bug reports to reproduce this run", path)
+}
+
+// loadReplay builds the file checker and services for a --replay run. The services are the ones
+// the bundle recorded (restricted by --service), and their clients answer from the bundle.
+func loadReplay(cfg *config.Config, logger arr.Logger) (arr.FileChecker, []ServiceInfo, error) {
+	bundle, err := recording.LoadBundle(cfg.Replay)
+	if err != nil {
+		return nil, nil, err
+	}
+	player := recording.NewPlayer(bundle)
+
+	var services []ServiceInfo
+	for _, service := range player.Services() {
+		// Extra instances are recorded under their label, e.g. radarr-4k
+		name, instance, _ := strings.Cut(service.Name, "-")
+		if cfg.Service != "auto" && cfg.Service != name && cfg.Service != service.Name {
+			continue
+		}
+		var client arr.Client
+		switch name {
+		case "sonarr":
+			client = arr.NewSonarrClientWithTransport(&config.SonarrConfig{URL: service.URL, APIKey: "replay"}, cfg.RequestTimeout, player.Transport(service.Name), logger)
+		case "radarr":
+			client = newRadarrClient(cfg, &config.RadarrConfig{URL: service.URL, APIKey: "replay"}, player.Transport(service.Name), logger)
+		case "readarr":
+			client = arr.NewReadarrClientWithTransport(&config.ReadarrConfig{URL: service.URL, APIKey: "replay"}, cfg.RequestTimeout, player.Transport(service.Name), logger)
+		default:
+			continue
+		}
+		services = append(services, ServiceInfo{Name: name, Instance: instance, Client: client})
+	}
+
+	if len(services) == 0 {
+		return nil, nil, fmt.Errorf("no %s traffic recorded in %s", cfg.Service, cfg.Replay)
+	}
+
+	logger.Info("⏪ Replaying bundle %s recorded by RefreshArr %s at %s", cfg.Replay, bundle.Version, bundle.RecordedAt.Format("2006-01-02 15:04:05"))
+	return player.FileChecker(), services, nil
+}
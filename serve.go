@@ -0,0 +1,274 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/hnipps/refresharr/internal/arr"
+	"github.com/hnipps/refresharr/internal/config"
+	"github.com/hnipps/refresharr/internal/history"
+	"github.com/hnipps/refresharr/internal/jobs"
+	"github.com/hnipps/refresharr/internal/metrics"
+	"github.com/hnipps/refresharr/internal/webui"
+)
+
+// runServeCommand handles the serve command, which queues runs triggered over HTTP
+func runServeCommand(ctx context.Context, cfg *config.Config) {
+	// Create logger
+	logger := newLogger(cfg)
+	logger.Info("Starting RefreshArr %s - API Server", version)
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// In multi-tenant mode every tenant has an API of their own, chosen by the request's API key
+	var servers []*apiServer
+	var handler http.Handler
+	if len(cfg.Tenants) == 0 {
+		// Queued runs delete file records, so the API is never open to anyone who can reach it
+		if cfg.APIKey == "" {
+			logger.Error("SERVE_API_KEY or --serve-api-key is required: requests must carry it in the X-Api-Key header")
+			os.Exit(exitConfig)
+		}
+		server, err := newAPIServer(cfg, "", logger)
+		if err != nil {
+			logger.Error("%s", err.Error())
+			os.Exit(1)
+		}
+		servers = append(servers, server)
+		handler = apiKeyHandler(map[string]http.Handler{cfg.APIKey: server.handler})
+	} else {
+		byKey := make(map[string]http.Handler, len(cfg.Tenants))
+		for _, tenant := range cfg.Tenants {
+			server, err := newAPIServer(cfg.ForTenant(tenant), tenant.Name, logger)
+			if err != nil {
+				logger.Error("Tenant %s: %s", tenant.Name, err.Error())
+				os.Exit(1)
+			}
+			servers = append(servers, server)
+			byKey[tenant.APIKey] = server.handler
+			logger.Info("👥 Tenant %s: %s (job queue: %s)", tenant.Name, strings.Join(server.services, ", "), server.queuePath)
+		}
+		handler = apiKeyHandler(byKey)
+	}
+
+	for _, server := range servers {
+		if err := server.start(ctx); err != nil {
+			logger.Error("%s", err.Error())
+			os.Exit(1)
+		}
+	}
+	if cfg.WebUI {
+		logger.Info("🖥️  Web dashboard enabled at /")
+	}
+
+	httpServer := &http.Server{
+		Addr:              cfg.ListenAddr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		if len(cfg.Tenants) == 0 {
+			logger.Info("🌐 Listening on %s (job queue: %s)", cfg.ListenAddr, servers[0].queuePath)
+		} else {
+			logger.Info("🌐 Listening on %s for %d tenant(s)", cfg.ListenAddr, len(cfg.Tenants))
+		}
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- fmt.Errorf("server failed: %w", err)
+		}
+		close(serverErr)
+	}()
+
+	select {
+	case <-ctx.Done():
+		logger.Info("Shutting down - queued jobs will resume on next start")
+	case err := <-serverErr:
+		if err != nil {
+			logger.Error("%s", err.Error())
+			stop()
+			for _, server := range servers {
+				server.queue.Wait()
+			}
+			os.Exit(1)
+		}
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		logger.Warn("Failed to shut down server cleanly: %s", err.Error())
+	}
+
+	for _, server := range servers {
+		server.queue.Wait()
+	}
+}
+
+// apiServer is the job queue and HTTP API of a set of services: the configured ones, or a
+// tenant's in multi-tenant mode
+type apiServer struct {
+	cfg       *config.Config
+	services  []string // Services "auto" requests run for
+	queue     *jobs.Queue
+	queuePath string
+	handler   http.Handler
+}
+
+// newAPIServer loads the job queue of cfg's services, kept in its state directory, and sets up
+// the HTTP API serving it. tenant names the tenant the services belong to, if any.
+func newAPIServer(cfg *config.Config, tenant string, logger arr.Logger) (*apiServer, error) {
+	// "auto" requests run once per configured service; a service's job covers all its instances
+	var services []string
+	for _, serviceInfo := range determineServices(cfg, logger) {
+		if !slices.Contains(services, serviceInfo.Name) {
+			services = append(services, serviceInfo.Name)
+		}
+	}
+	if len(services) == 0 {
+		return nil, errors.New("no services configured or available")
+	}
+
+	// Follow the progress of the run in flight for the status endpoint, and count finished runs
+	// for the metrics endpoint
+	status := arr.NewStatusTracker()
+	registry := metrics.NewRegistry()
+
+	queuePath := filepath.Join(cfg.StateDir, "jobs.json")
+	queue, err := jobs.NewQueue(queuePath, services, func(ctx context.Context, job jobs.Job) (err error) {
+		// Jobs run on the queue's goroutine, out of reach of main's panic recovery
+		defer recoverJob(cfg, job.Command, &err, logger)
+		if tenant != "" {
+			logger.Info("👥 Job %s belongs to tenant %s", job.ID, tenant)
+		}
+
+		jobCfg := *cfg
+		jobCfg.Service = job.Service
+		// A server started in dry-run mode makes no changes, even for jobs queued before it was
+		jobCfg.DryRun = job.DryRun || cfg.DryRun
+		if jobCfg.DryRun {
+			// A dry-run job leaves every service alone, whatever its override
+			jobCfg.ServiceDryRun = nil
+		}
+		if job.Command == "verify" {
+			jobCfg.Verify = true
+			jobCfg.DryRun = true
+		}
+		runs, err := runCleanup(ctx, &jobCfg, logger, status)
+		for _, run := range runs {
+			observeRun(registry, run)
+		}
+		if len(runs) == 0 && err != nil {
+			// The job failed before any run started, e.g. on a bad setting or an unreachable service
+			observeFailedJob(registry, job, jobCfg.DryRun)
+		}
+		reportFailures(ctx, &jobCfg, job.Command, runs, err, logger)
+		if len(runs) == 0 {
+			publishRunOutcome(&jobCfg, job.Command, nil, err, logger)
+		}
+		if errors.Is(err, errCompletedWithWarnings) {
+			// A partial success; the warnings were logged with the run
+			return nil
+		}
+		return err
+	}, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load job queue: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	runs := history.NewHandler(history.NewStoreWithLogger(cfg.StateDir, logger))
+	mux.Handle("/api/runs", runs)
+	mux.Handle("/api/runs/", runs)
+	mux.Handle("/api/", jobs.NewHandler(queue, jobs.HandlerOptions{DryRun: cfg.DryRun}))
+	mux.Handle("GET /status", statusHandler(status))
+	mux.Handle("GET /metrics", registry.Handler())
+	if cfg.WebUI {
+		mux.Handle("GET /{$}", webui.NewHandler(version, services))
+	}
+
+	return &apiServer{cfg: cfg, services: services, queue: queue, queuePath: queuePath, handler: mux}, nil
+}
+
+// start runs the queued jobs and schedules the nightly verify sweep if requested
+func (s *apiServer) start(ctx context.Context) error {
+	s.queue.Start(ctx)
+	if s.cfg.VerifyAt != "" {
+		dryRun := true
+		if err := s.queue.ScheduleDaily(ctx, s.cfg.VerifyAt, jobs.Request{Command: "verify", Service: "auto", DryRun: &dryRun}); err != nil {
+			return fmt.Errorf("failed to schedule verify runs: %w", err)
+		}
+	}
+	return nil
+}
+
+// apiKeyHandler serves each request with the API of the key it carries, in the X-Api-Key header
+// or the apikey query parameter like the *arr apps take it. Requests without a known key are
+// refused, so tenants only see their own jobs, runs and reports.
+func apiKeyHandler(byKey map[string]http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("X-Api-Key")
+		if key == "" {
+			key = r.URL.Query().Get("apikey")
+		}
+		for tenantKey, handler := range byKey {
+			if subtle.ConstantTimeCompare([]byte(key), []byte(tenantKey)) == 1 {
+				handler.ServeHTTP(w, r)
+				return
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "missing or unknown API key"})
+	})
+}
+
+// statusResponse is what the status endpoint serves: every run in flight, one per service
+type statusResponse struct {
+	Running bool            `json:"running"`
+	Runs    []arr.RunStatus `json:"runs"`
+}
+
+// statusHandler serves a snapshot of the runs in flight as JSON
+func statusHandler(status *arr.StatusTracker) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		runs := status.Status()
+		if runs == nil {
+			runs = []arr.RunStatus{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(statusResponse{Running: len(runs) > 0, Runs: runs})
+	})
+}
+
+// observeRun counts a finished run in the metrics, labelled with its service, instance and run type
+func observeRun(registry *metrics.Registry, run *history.Run) {
+	service, instance, _ := strings.Cut(run.Service, "-")
+	labels := metrics.Labels{
+		Service:  service,
+		Instance: instance,
+		RunType:  metrics.RunTypeOf(run.DryRun, run.Command == "verify"),
+	}
+	registry.ObserveRun(labels, run.Stats, run.Recovered, run.Success, run.StartedAt, run.FinishedAt)
+}
+
+// observeFailedJob counts a job that failed before it got to run as a failed run of its service.
+// A job covers every instance of its service, so it's labelled with the default instance.
+func observeFailedJob(registry *metrics.Registry, job jobs.Job, dryRun bool) {
+	registry.ObserveFailure(metrics.Labels{
+		Service: job.Service,
+		RunType: metrics.RunTypeOf(dryRun, job.Command == "verify"),
+	})
+}
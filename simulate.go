@@ -0,0 +1,49 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hnipps/refresharr/internal/arr"
+	"github.com/hnipps/refresharr/internal/config"
+	"github.com/hnipps/refresharr/internal/filesystem"
+)
+
+// loadSimulation builds the file checker and services for a --simulate run from the fixtures
+// directory: files.json is the file manifest and sonarr.json/radarr.json hold the canned data
+// for each service. Services without a fixture are skipped.
+func loadSimulation(cfg *config.Config, logger arr.Logger) (arr.FileChecker, []ServiceInfo, error) {
+	fileChecker, err := filesystem.LoadManifestChecker(filepath.Join(cfg.Simulate, "files.json"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var names []string
+	switch cfg.Service {
+	case "sonarr", "radarr":
+		names = []string{cfg.Service}
+	default:
+		names = []string{"sonarr", "radarr"}
+	}
+
+	var services []ServiceInfo
+	for _, name := range names {
+		client, err := arr.LoadSimulatedClient(cfg.Simulate, name, cfg.SimulateLatency, logger)
+		if errors.Is(err, os.ErrNotExist) && cfg.Service == "auto" {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		services = append(services, ServiceInfo{Name: name, Client: client})
+	}
+
+	if len(services) == 0 {
+		return nil, nil, fmt.Errorf("no sonarr.json or radarr.json fixture found in %s", cfg.Simulate)
+	}
+
+	logger.Info("🧪 Simulating against fixtures in %s (latency %s per call)", cfg.Simulate, cfg.SimulateLatency)
+	return fileChecker, services, nil
+}
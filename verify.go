@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/hnipps/refresharr/internal/arr"
+	"github.com/hnipps/refresharr/internal/config"
+	"github.com/hnipps/refresharr/internal/history"
+	"github.com/hnipps/refresharr/internal/notify"
+	"github.com/hnipps/refresharr/internal/sink"
+)
+
+// runVerifyCommand handles the verify command, a cleanup pass that never writes to the *arr APIs
+func runVerifyCommand(ctx context.Context, cfg *config.Config) {
+	// Create logger
+	logger := newLogger(cfg)
+	logger.Info("Starting RefreshArr %s - Read-Only Verify", version)
+
+	cfg.Verify = true
+	cfg.DryRun = true
+
+	startedAt := time.Now().UTC()
+	runs, err := runCleanup(ctx, cfg, logger, nil)
+	// An interrupted run's outcome is still reported
+	ctx = context.WithoutCancel(ctx)
+	reportFailures(ctx, cfg, "verify", runs, err, logger)
+	// The runs' own pushes went out with their reports
+	if len(runs) == 0 {
+		publishRunOutcome(cfg, "verify", nil, err, logger)
+	}
+	if cfg.Output == config.OutputJSON {
+		os.Exit(finishOutput("verify", cfg, startedAt, runs, err, os.Stdout, os.Stderr, logger))
+	}
+	if cfg.JobMode {
+		os.Exit(finishJob("verify", cfg, startedAt, runs, err, logger))
+	}
+	if errors.Is(err, errCompletedWithWarnings) {
+		logger.Warn("%s", err.Error())
+	} else if err != nil {
+		logger.Error("%s", err.Error())
+	} else {
+		logger.Info("🎉 Verification completed - no changes were made")
+	}
+	printExitSummary(cfg, os.Stderr, exitStatus(err), runs, time.Since(startedAt), logger)
+	if err != nil {
+		os.Exit(exitCode(err))
+	}
+}
+
+// checkVerifyAlerts compares each verify run with the previous one for its service and queues
+// an alert on sinks when the missing files exceed the threshold or grew
+func checkVerifyAlerts(cfg *config.Config, store *history.Store, runs []*history.Run, sinks *sink.Dispatcher, logger arr.Logger) {
+	notifiers := notify.Notifiers(&cfg.Notify, cfg.RequestTimeout, arr.ForModule(logger, arr.ModuleNotify))
+
+	for _, run := range runs {
+		previous, err := store.Previous(run)
+		if err != nil {
+			logger.Warn("Failed to load previous %s verify run: %s", run.Service, err.Error())
+		}
+
+		alert := notify.VerifyAlertWithMessages(run, previous, cfg.AlertThreshold, cfg.Messages)
+		if alert == nil {
+			continue
+		}
+
+		logger.Warn("🚨 %s", alert.Text)
+		for _, notifier := range notifiers {
+			service := run.Service
+			sinks.Submit("notify:"+notifier.Name(), run.ID, func(ctx context.Context) error {
+				if err := notifier.Send(ctx, alert); err != nil {
+					logger.Error("Failed to send %s alert via %s: %s", service, notifier.Name(), err.Error())
+					return err
+				}
+				logger.Info("📣 Alert sent for %s via %s", service, notifier.Name())
+				return nil
+			})
+		}
+	}
+}